@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// AccountingCmd represents the accounting command for footprint reporting
+// Estimates energy and CO2 of storing and moving design files for sustainability reports
+var AccountingCmd = &cobra.Command{
+	Use:   "accounting",
+	Short: "Estimate energy use and CO2 of repository activity",
+	Long: `Record bytes stored, restored and transferred, plus compute time, for each
+commit, restore, delivery, bundle and share download, and turn them into
+monthly energy and CO2 estimates.
+
+Accounting is off by default. Estimation factors (grid intensity, storage and
+network energy, machine power) can be tuned in the "accounting" section of
+.dgit/config; the defaults are broad global averages.
+
+Examples:
+  dgit accounting enable
+  dgit accounting report                          # Current month
+  dgit accounting report --month 2026-09 --format csv -o footprint-2026-09.csv`,
+	Run: runAccountingStatus,
+}
+
+// accountingEnableCmd turns accounting on
+var accountingEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Start recording usage events",
+	Args:  cobra.NoArgs,
+	Run:   func(cmd *cobra.Command, args []string) { setAccounting(true) },
+}
+
+// accountingDisableCmd turns accounting off
+var accountingDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop recording usage events",
+	Args:  cobra.NoArgs,
+	Run:   func(cmd *cobra.Command, args []string) { setAccounting(false) },
+}
+
+// accountingReportCmd prints a monthly footprint report
+var accountingReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show the estimated footprint of a month",
+	Args:  cobra.NoArgs,
+	Run:   runAccountingReport,
+}
+
+// init sets up accounting subcommands and flags
+func init() {
+	accountingReportCmd.Flags().String("month", "", "Month to report as YYYY-MM (default: current month)")
+	accountingReportCmd.Flags().String("format", "text", "Output format: text, csv, json")
+	accountingReportCmd.Flags().StringP("output", "o", "", "Write the report to a file")
+
+	AccountingCmd.AddCommand(accountingEnableCmd)
+	AccountingCmd.AddCommand(accountingDisableCmd)
+	AccountingCmd.AddCommand(accountingReportCmd)
+}
+
+// runAccountingStatus shows whether accounting is on and which months have data
+func runAccountingStatus(cmd *cobra.Command, args []string) {
+	manager := accounting.NewAccountingManager(checkDgitRepository())
+
+	if manager.Enabled() {
+		fmt.Printf("Accounting: %s\n", green("enabled"))
+	} else {
+		fmt.Printf("Accounting: %s\n", yellow("disabled"))
+	}
+	months, err := manager.Months()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading usage log: %v", err), "")
+	}
+	if len(months) > 0 {
+		fmt.Printf("Months with data: %v\n", months)
+	} else if !manager.Enabled() {
+		printInfo("Run 'dgit accounting enable' to start recording")
+	}
+}
+
+// setAccounting turns event recording on or off
+func setAccounting(enabled bool) {
+	manager := accounting.NewAccountingManager(checkDgitRepository())
+	if err := manager.SetEnabled(enabled); err != nil {
+		exitWithError(fmt.Sprintf("updating config: %v", err), "")
+	}
+	if enabled {
+		printSuccess("Accounting enabled - commits, restores, deliveries, bundles and downloads are now recorded")
+	} else {
+		printSuccess("Accounting disabled - recorded events are kept")
+	}
+}
+
+// runAccountingReport builds and writes a monthly report
+func runAccountingReport(cmd *cobra.Command, args []string) {
+	manager := accounting.NewAccountingManager(checkDgitRepository())
+
+	month := time.Now()
+	if value, _ := cmd.Flags().GetString("month"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01", value, time.Local)
+		if err != nil {
+			exitWithError(fmt.Sprintf("invalid month %q", value), "Use the form YYYY-MM, e.g. 2026-09")
+		}
+		month = parsed
+	}
+
+	report, err := manager.MonthlyReport(month)
+	if err != nil {
+		exitWithError(fmt.Sprintf("building report: %v", err), "")
+	}
+
+	var out io.Writer = os.Stdout
+	if path, _ := cmd.Flags().GetString("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			exitWithError(fmt.Sprintf("creating %s: %v", path, err), "")
+		}
+		defer f.Close()
+		out = f
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case "csv":
+		err = writeAccountingCSV(out, report)
+	case "text":
+		writeAccountingText(out, report)
+	default:
+		exitWithError(fmt.Sprintf("unknown format %q", format), "Use text, csv or json")
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("writing report: %v", err), "")
+	}
+}
+
+// writeAccountingText prints a human-readable report
+func writeAccountingText(out io.Writer, report *accounting.Report) {
+	fmt.Fprintf(out, "Footprint estimate for %s\n\n", report.Month)
+	fmt.Fprintf(out, "  %-8s %6s %10s %10s %12s %10s\n", "", "count", "stored", "restored", "transferred", "compute")
+	for _, op := range report.Operations {
+		fmt.Fprintf(out, "  %-8s %6d %10s %10s %12s %9.1fs\n", op.Operation, op.Count, util.FormatBytes(op.BytesStored),
+			util.FormatBytes(op.BytesRead), util.FormatBytes(op.BytesTransferred), op.ComputeSeconds)
+	}
+	fmt.Fprintf(out, "\n  Storage   %8.4f kWh  (%.3f GB-months)\n", report.StorageKWh, report.StoredGBMonths)
+	fmt.Fprintf(out, "  Transfer  %8.4f kWh\n", report.TransferKWh)
+	fmt.Fprintf(out, "  Compute   %8.4f kWh\n", report.ComputeKWh)
+	fmt.Fprintf(out, "  Total     %8.4f kWh  ≈ %.1f g CO2e at %.0f g/kWh\n", report.TotalKWh, report.CO2Grams, report.GridIntensity)
+	if report.EventsIncomplete {
+		fmt.Fprintln(out, "\n  Note: accounting was not enabled for the whole month; transfer and compute are partial")
+	}
+}
+
+// writeAccountingCSV writes one row per operation plus a total row
+func writeAccountingCSV(out io.Writer, report *accounting.Report) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"month", "operation", "count", "bytes_stored", "bytes_restored", "bytes_transferred",
+		"compute_seconds", "energy_kwh", "co2_grams"})
+	for _, op := range report.Operations {
+		w.Write([]string{report.Month, op.Operation, strconv.Itoa(op.Count),
+			strconv.FormatInt(op.BytesStored, 10), strconv.FormatInt(op.BytesRead, 10),
+			strconv.FormatInt(op.BytesTransferred, 10), fmt.Sprintf("%.3f", op.ComputeSeconds),
+			fmt.Sprintf("%.6f", op.EnergyKWh), fmt.Sprintf("%.3f", op.EnergyKWh*report.GridIntensity)})
+	}
+	w.Write([]string{report.Month, "storage", "", "", "", "", "",
+		fmt.Sprintf("%.6f", report.StorageKWh), fmt.Sprintf("%.3f", report.StorageKWh*report.GridIntensity)})
+	w.Write([]string{report.Month, "total", "", "", "", "", "",
+		fmt.Sprintf("%.6f", report.TotalKWh), fmt.Sprintf("%.3f", report.CO2Grams)})
+	w.Flush()
+	return w.Error()
+}
@@ -5,7 +5,10 @@ import (
 	"os"
 	"strings"
 	
-	"dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/lock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
 	"github.com/spf13/cobra"
 )
 
@@ -21,12 +24,27 @@ Examples:
   dgit add .                      # Add all design files in current directory
   dgit add *.psd                  # Add all PSD files
   dgit add designs/ icons/        # Add multiple directories
+  dgit add "**/*.psd" --exclude "drafts/**"   # All PSDs except drafts
+  dgit add --force exports/a.psd  # Add a file listed in .dgitignore
+
+Quote patterns containing "**" so the shell passes them through; "**"
+matches any number of folders.
+
+Paths matched by .dgitignore files (gitignore syntax, one per folder) are
+skipped unless --force is given. --exclude patterns use the same syntax,
+relative to the current folder, and apply even with --force.
 
 Supported file types: .ai, .psd, .sketch, .fig, .xd, .afdesign, .afphoto`,
 	Args: cobra.MinimumNArgs(1),  // Require at least one file/pattern argument
 	Run:  runAdd,
 }
 
+// init sets up command flags for add command
+func init() {
+	AddCmd.Flags().BoolP("force", "f", false, "Add files even if .dgitignore excludes them")
+	AddCmd.Flags().StringArrayP("exclude", "x", nil, "Skip paths matching this pattern (repeatable)")
+}
+
 // runAdd executes the add command functionality
 // It stages files for commit by adding them to the staging area
 func runAdd(cmd *cobra.Command, args []string) {
@@ -39,7 +57,17 @@ func runAdd(cmd *cobra.Command, args []string) {
 
 	// Get the .dgit directory path
 	dgitDir := findDgitDirectory()
+	repoLock := lockRepository(dgitDir, "add")
+	defer repoLock.Release()
 	stagingArea := staging.NewStagingArea(dgitDir)
+	stagingArea.Lock = repoLock
+	if force, _ := cmd.Flags().GetBool("force"); force {
+		stagingArea.Ignore = nil
+	}
+	if excludes, _ := cmd.Flags().GetStringArray("exclude"); len(excludes) > 0 {
+		cwd, _ := os.Getwd()
+		stagingArea.Exclude = scanner.NewPatternMatcher(cwd, excludes)
+	}
 	
 	// Load existing staging area state from disk
 	if err := stagingArea.LoadStaging(); err != nil {
@@ -50,6 +78,10 @@ func runAdd(cmd *cobra.Command, args []string) {
 	// Track results across all add operations
 	var allAddedFiles []string
 	var allFailedFiles = make(map[string]error)
+	var skippedResidue []string
+	var skippedIgnored []string
+	var skippedExcluded []string
+	reused := make(map[string]bool)
 
 	// Process each file pattern or path argument
 	for _, arg := range args {
@@ -62,6 +94,12 @@ func runAdd(cmd *cobra.Command, args []string) {
 
 		// Collect successfully added files
 		allAddedFiles = append(allAddedFiles, result.AddedFiles...)
+		skippedResidue = append(skippedResidue, result.SkippedResidue...)
+		skippedIgnored = append(skippedIgnored, result.SkippedIgnored...)
+		skippedExcluded = append(skippedExcluded, result.SkippedExcluded...)
+		for _, file := range result.ReusedFiles {
+			reused[file] = true
+		}
 		
 		// Display warnings for files that failed to add
 		for file, fileErr := range result.FailedFiles {
@@ -76,10 +114,36 @@ func runAdd(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if len(skippedResidue) > 0 {
+		printInfo(fmt.Sprintf("Skipped %d autosave/temp file(s); run 'dgit clean' to review them", len(skippedResidue)))
+	}
+	if len(skippedIgnored) > 0 {
+		printInfo(fmt.Sprintf("Skipped %d file(s) listed in .dgitignore; use --force to add them anyway", len(skippedIgnored)))
+	}
+	if len(skippedExcluded) > 0 {
+		printInfo(fmt.Sprintf("Skipped %d file(s) matching --exclude", len(skippedExcluded)))
+	}
+
+	// Locked files can be staged, but commit will refuse them
+	owner, email, _ := commit.NewCommitManager(dgitDir).Identity()
+	if locked, _ := lock.NewLockManager(dgitDir).Conflicts(allAddedFiles, owner, email); len(locked) > 0 {
+		for _, l := range locked {
+			printWarning(l.String() + "; commit will refuse it until the lock is released")
+		}
+	}
+
 	// Display results to user
 	if len(allAddedFiles) > 0 {
 		printSuccess(fmt.Sprintf("Added %d file(s) to staging area:", len(allAddedFiles)))
-		for _, file := range allAddedFiles {
+		sequences, singles := staging.DetectSequences(allAddedFiles)
+		for _, seq := range sequences {
+			fmt.Printf("  + %s (%d frames, %s)\n", seq.Pattern, seq.Frames, seq.FrameRange())
+		}
+		for _, file := range singles {
+			if reused[file] {
+				fmt.Printf("  + %s (reused cached copy)\n", file)
+				continue
+			}
 			fmt.Printf("  + %s\n", file)
 		}
 		fmt.Println()
@@ -100,11 +164,39 @@ func printStagingStatus(stagingArea *staging.StagingArea) {
 	}
 
 	fmt.Printf("Files staged for commit (%d):\n", len(stagedFiles))
-	for _, file := range stagedFiles {
+	sequences, singles := stagingArea.GetSequences()
+	for _, seq := range sequences {
+		var size int64
+		for _, file := range stagedFiles {
+			if seq.Matches(file.Path) {
+				size += file.Size
+			}
+		}
+		fmt.Printf("  %s (%s sequence, %d frames %s, %.2f KB)\n",
+			seq.Pattern, strings.ToUpper(strings.TrimPrefix(seq.Suffix, ".")), seq.Frames, seq.FrameRange(),
+			float64(size)/1024)
+		if len(seq.Missing) > 0 {
+			printWarning(fmt.Sprintf("%s is missing %d frame(s): %s", seq.Pattern, len(seq.Missing), formatFrameList(seq.Missing)))
+		}
+	}
+	for _, file := range singles {
 		// Display file with type and size information
 		fmt.Printf("  %s (%s, %.2f KB)\n", 
 			file.Path, 
 			strings.ToUpper(file.FileType), 
 			float64(file.Size)/1024)  // Convert bytes to KB
 	}
-}
\ No newline at end of file
+}
+// formatFrameList prints missing frame numbers, shortening long lists
+func formatFrameList(frames []int) string {
+	const shown = 10
+	var parts []string
+	for i, n := range frames {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("... (%d more)", len(frames)-shown))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%d", n))
+	}
+	return strings.Join(parts, ", ")
+}
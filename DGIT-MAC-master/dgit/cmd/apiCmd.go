@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/api"
+
+	"github.com/spf13/cobra"
+)
+
+// APICmd represents the api command for machine-readable repository state
+// Meant for DAM systems and editor plugins rather than people
+var APICmd = &cobra.Command{
+	Use:   "api",
+	Short: "Machine-readable repository state for integrations",
+}
+
+// apiDumpCmd prints the state document
+var apiDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print tracked files, latest versions, previews and pins as JSON",
+	Long: `Print the repository state as one JSON document: HEAD with its approval
+state, and for every tracked file its latest version, content hash, working
+copy state (unchanged, modified, missing, pinned) and newest preview.
+
+The document is built from commit metadata and file stats without reading
+file contents, so it is cheap to poll. The same document is served at
+GET /api/state by 'dgit serve' (with the webhook token, see 'dgit serve
+webhook'), which answers 304 when the If-None-Match ETag still matches.
+
+The "schema" field only changes for incompatible changes; new fields may
+appear at any time.
+
+Examples:
+  dgit api dump
+  dgit api dump --pretty -o state.json`,
+	Args: cobra.NoArgs,
+	Run:  runAPIDump,
+}
+
+// init sets up api subcommands and flags
+func init() {
+	apiDumpCmd.Flags().Bool("pretty", false, "Indent the JSON")
+	apiDumpCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+
+	APICmd.AddCommand(apiDumpCmd)
+}
+
+// runAPIDump prints the state document
+func runAPIDump(cmd *cobra.Command, args []string) {
+	pretty, _ := cmd.Flags().GetBool("pretty")
+	output, _ := cmd.Flags().GetString("output")
+
+	doc, err := api.NewAPIManager(checkDgitRepository()).Dump()
+	if err != nil {
+		exitWithError(fmt.Sprintf("building state: %v", err), "")
+	}
+
+	var data []byte
+	if pretty {
+		data, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		data, err = json.Marshal(doc)
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("encoding state: %v", err), "")
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		exitWithError(fmt.Sprintf("writing %s: %v", output, err), "")
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// ApproveCmd represents the approve command for moving commits through the approval workflow
+// Unique to DGit - tracks draft → review → approved → delivered states for client work
+var ApproveCmd = &cobra.Command{
+	Use:   "approve <version_or_hash>",
+	Short: "Set or check the approval state of a commit",
+	Long: `Record a signed approval note on a commit.
+
+Commits move through the states draft → review → approved → delivered.
+Every change is stored as a signed note under .dgit/notes, so the state
+history of each version is auditable. Commits without notes are drafts.
+
+Examples:
+  dgit approve v9 --by "AD"                       # Mark v9 as approved by AD
+  dgit approve v9 --state review --by "Jamie"     # Send v9 to review
+  dgit approve v9 --verify approved               # Exit non-zero unless v9 is approved
+  dgit approve v9 --history                       # Show all state changes of v9
+
+The --verify form is meant for hooks and scripts that must only act on
+approved versions.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runApprove,
+}
+
+// init sets up command flags for approve command
+func init() {
+	ApproveCmd.Flags().String("by", "", "Name of the person approving (default: repository author)")
+	ApproveCmd.Flags().StringP("state", "s", approval.StateApproved, "State to set: draft, review, approved, delivered")
+	ApproveCmd.Flags().StringP("message", "m", "", "Optional note explaining the state change")
+	ApproveCmd.Flags().String("verify", "", "Only check that the commit has reached this state")
+	ApproveCmd.Flags().Bool("history", false, "Show the state history of the commit")
+}
+
+// runApprove executes the approve command functionality
+func runApprove(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	logManager := log.NewLogManager(dgitDir)
+	approvalManager := approval.NewApprovalManager(dgitDir)
+
+	targetCommit, err := findTargetCommit(logManager, args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to find commit: %v", err), "")
+	}
+
+	// Check-only mode for hooks and scripts
+	if required, _ := cmd.Flags().GetString("verify"); required != "" {
+		if !approval.IsValidState(required) {
+			exitWithError(fmt.Sprintf("unknown state %q", required), "Valid states: "+strings.Join(approval.States, ", "))
+		}
+		if err := approvalManager.RequireState(targetCommit, required); err != nil {
+			exitWithError(err.Error(), "")
+		}
+		printSuccess(fmt.Sprintf("v%d has reached %s", targetCommit.Version, required))
+		return
+	}
+
+	if history, _ := cmd.Flags().GetBool("history"); history {
+		printApprovalHistory(approvalManager, targetCommit)
+		return
+	}
+
+	state, _ := cmd.Flags().GetString("state")
+	message, _ := cmd.Flags().GetString("message")
+	note, err := approvalManager.SetState(targetCommit, state, messageAuthor(cmd, dgitDir), message)
+	if err != nil {
+		printError(fmt.Sprintf("setting approval state: %v", err))
+		os.Exit(1)
+	}
+
+	printSuccess(fmt.Sprintf("v%d (%s) is now %s", targetCommit.Version, targetCommit.Hash[:8], bold(note.State)))
+	fmt.Printf("By: %s\n", note.By)
+	if note.Message != "" {
+		fmt.Printf("Note: %s\n", note.Message)
+	}
+}
+
+// printApprovalHistory displays every state change recorded for a commit
+func printApprovalHistory(approvalManager *approval.ApprovalManager, c *log.Commit) {
+	notes, err := approvalManager.GetHistory(c.Version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading approval history: %v", err), "")
+	}
+
+	current, _ := approvalManager.GetState(c)
+	fmt.Printf("Approval history for v%d (%s) - currently %s\n\n", c.Version, c.Hash[:8], bold(current.State))
+
+	if len(notes) == 0 {
+		fmt.Println("No state changes recorded (draft).")
+		return
+	}
+	for _, note := range notes {
+		fmt.Printf("  %s  %-9s by %s", note.Timestamp.Format("2006-01-02 15:04"), note.State, note.By)
+		if note.Message != "" {
+			fmt.Printf(" - %s", note.Message)
+		}
+		fmt.Println()
+	}
+	if current.Unverified > 0 {
+		printWarning(fmt.Sprintf("%d note(s) failed signature verification and were ignored", current.Unverified))
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/storage"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// ArchiveCmd represents the archive command for moving versions to the cold tier
+// on-archive and on-recall hooks let tape and HSM systems take the blobs off this machine
+var ArchiveCmd = &cobra.Command{
+	Use:   "archive <version>...",
+	Short: "Move versions to cold storage",
+	Long: `Recompress versions into the cold tier (.dgit/cache/cold) and drop their hot
+and warm copies. Each archived blob is then handed to the on-archive hook, which
+may move it off to tape or an HSM. When a restore needs an archived version
+that is no longer on disk, the on-recall hook is run and DGit waits, showing
+progress, until the blob is back at the same path.
+
+Hooks are executables at .dgit/hooks/<hook> or in .dgit/hooks/<hook>.d/ and get
+DGIT_BLOB, DGIT_VERSION and DGIT_HASH in their environment. The wait is bounded
+by archive.recall_timeout_minutes in the repository config (default 240).
+
+Blobs are written at compression.archive_stage.compression_level (default 22)
+and checked to decode to the version's content before the hot and warm copies
+are deleted; a blob over archive_stage.max_archive_size is refused. Archived
+versions are listed with their files in .dgit/cache/cold/index/archives.json.
+
+Versions in the object store (compression.object_store "content", the default)
+pack only the blobs no online version still references, and only those blobs
+leave .dgit/objects; content shared with newer versions stays where it is.
+
+With a storage backend configured (see 'dgit storage'), archived blobs are
+uploaded there instead and fetched back directly, without hooks.
+
+Examples:
+  dgit archive v3 v4 v5        # Archive three versions
+  dgit archive --expired       # Archive every version older than archive_after_days
+  dgit archive list            # Show archived versions and whether they are online
+  dgit archive recall v3       # Bring v3 back ahead of a restore`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if expired, _ := cmd.Flags().GetBool("expired"); expired {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: runArchive,
+}
+
+// archiveListCmd lists archived versions
+var archiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List archived versions",
+	Args:  cobra.NoArgs,
+	Run:   runArchiveList,
+}
+
+// archiveRecallCmd brings an offline version back
+var archiveRecallCmd = &cobra.Command{
+	Use:   "recall <version>",
+	Short: "Run the on-recall hook and wait for a version to come back",
+	Args:  cobra.ExactArgs(1),
+	Run:   runArchiveRecall,
+}
+
+// init sets up archive subcommands
+func init() {
+	ArchiveCmd.AddCommand(archiveListCmd)
+	ArchiveCmd.AddCommand(archiveRecallCmd)
+	ArchiveCmd.Flags().Bool("expired", false, "Archive the versions older than compression.archive_stage.archive_after_days")
+}
+
+// runArchive archives each given version
+func runArchive(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := archive.NewArchiveManager(dgitDir)
+	if backend, _ := storage.Open(dgitDir); backend != nil {
+		printInfo(fmt.Sprintf("Archived blobs are uploaded to %s", backend.Describe()))
+	} else if len(hooks.Scripts(dgitDir, archive.HookOnArchive)) == 0 {
+		printInfo(fmt.Sprintf("No %s hook installed; blobs stay in the local cold tier", archive.HookOnArchive))
+	}
+
+	var versions []int
+	if expired, _ := cmd.Flags().GetBool("expired"); expired {
+		due, err := manager.Expired(time.Now())
+		if err != nil {
+			exitWithError(err.Error(), "")
+		}
+		if len(due) == 0 {
+			printInfo("No versions are older than archive_after_days")
+			return
+		}
+		versions = due
+	}
+	for _, arg := range args {
+		version, err := parseVersionArg(arg)
+		if err != nil {
+			exitWithError(err.Error(), "Use versions such as v5")
+		}
+		versions = append(versions, version)
+	}
+
+	failed := 0
+	for _, version := range versions {
+		record, err := manager.Archive(version)
+		if record == nil {
+			printError(err.Error())
+			failed++
+			continue
+		}
+		location := "kept in the cold tier"
+		if record.Offline && record.Storage != "" {
+			location = "uploaded to " + record.Storage + " storage"
+		} else if record.Offline {
+			location = "moved off by the on-archive hook"
+		}
+		printSuccess(fmt.Sprintf("Archived v%d (%s, %s)", version, util.FormatBytes(record.Size), location))
+		if err != nil {
+			printWarning(err.Error())
+			failed++
+		}
+	}
+	if failed > 0 {
+		exitWithError(fmt.Sprintf("%d of %d version(s) could not be archived", failed, len(versions)), "")
+	}
+}
+
+// runArchiveList prints the archive state of every archived version
+func runArchiveList(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	records, err := archive.NewArchiveManager(dgitDir).List()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if len(records) == 0 {
+		printInfo("No archived versions")
+		return
+	}
+	for _, record := range records {
+		state := green("online")
+		if record.Offline && record.Storage != "" {
+			state = yellow("in " + record.Storage)
+		} else if record.Offline {
+			state = yellow("offline")
+		}
+		fmt.Printf("  v%-4d %-18s %10s  archived %s\n", record.Version, state, util.FormatBytes(record.Size),
+			record.ArchivedAt.Format("2006-01-02 15:04"))
+	}
+}
+
+// runArchiveRecall brings one version back through the on-recall hook
+func runArchiveRecall(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	version, err := parseVersionArg(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Use a version such as v5")
+	}
+	started := time.Now()
+	_, err = archive.NewArchiveManager(dgitDir).Recall(version, func(p archive.RecallProgress) {
+		fmt.Printf("\r  waiting %s: %s of %s back", p.Waited.Round(time.Second), util.FormatBytes(p.Arrived), util.FormatBytes(p.Size))
+	})
+	fmt.Println()
+	if err != nil {
+		exitWithError(fmt.Sprintf("recall failed: %v", err), "Check the on-recall hook in .dgit/hooks")
+	}
+	printSuccess(fmt.Sprintf("v%d is online (%s)", version, time.Since(started).Round(time.Second)))
+}
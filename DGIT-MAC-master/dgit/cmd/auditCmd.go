@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/audit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+
+	"github.com/spf13/cobra"
+)
+
+// AuditCmd represents the audit command for contrast checks on previews
+// Findings are stored in the commit metadata so history shows when contrast regressed
+var AuditCmd = &cobra.Command{
+	Use:   "audit [version]",
+	Short: "Check previews for WCAG text contrast",
+	Long: `Run a WCAG 2 contrast check on the image previews of a version and record
+the findings in its commit metadata. When enabled, the check runs after every
+commit's previews are generated; 'dgit log --audit' shows which version
+introduced failing contrast.
+
+The check looks for regions made of two dominant flat colors (text on a
+background) and measures their contrast ratio. Photographs and gradients are
+skipped, so it is meant for graphics such as banners, social cards and slides.
+
+Examples:
+  dgit audit enable --pattern "marketing/*"   # Audit marketing assets on commit
+  dgit audit enable --min-contrast 3          # WCAG AA for large text
+  dgit audit v12                              # Audit (or re-audit) v12 now
+  dgit audit disable`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runAudit,
+}
+
+// auditEnableCmd turns on the audit step
+var auditEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Audit previews after every commit",
+	Args:  cobra.NoArgs,
+	Run:   runAuditEnable,
+}
+
+// auditDisableCmd turns off the audit step
+var auditDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop auditing previews after commits",
+	Args:  cobra.NoArgs,
+	Run:   runAuditDisable,
+}
+
+// init sets up audit subcommands and flags
+func init() {
+	auditEnableCmd.Flags().Float64("min-contrast", audit.DefaultMinContrast, "Required contrast ratio (4.5 body text, 3 large text)")
+	auditEnableCmd.Flags().StringSlice("pattern", nil, "Only audit matching repository paths (default: all)")
+
+	AuditCmd.AddCommand(auditEnableCmd)
+	AuditCmd.AddCommand(auditDisableCmd)
+}
+
+// runAudit audits one version, rendering previews that do not exist yet
+func runAudit(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	logManager := log.NewLogManager(dgitDir)
+
+	version := logManager.GetCurrentVersion()
+	if len(args) == 1 {
+		v, err := parseVersionArg(args[0])
+		if err != nil {
+			exitWithError(err.Error(), "Use a version such as v5")
+		}
+		version = v
+	}
+	commit, err := logManager.GetCommit(version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("v%d not found: %v", version, err), "Run 'dgit log' to list versions")
+	}
+
+	manager := audit.NewAuditManager(dgitDir)
+	config, err := manager.GetConfig()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	previewManager := preview.NewPreviewManager(dgitDir)
+	var previews []*preview.Preview
+	for path := range commit.Metadata {
+		if !audit.Matches(config, path) {
+			continue
+		}
+		var composite *preview.Preview
+		withQuietStdout(func() { composite, err = previewManager.Composite(commit, path) })
+		if err != nil {
+			printWarning(err.Error())
+			continue
+		}
+		previews = append(previews, composite)
+	}
+
+	record, err := manager.AuditVersion(version, previews)
+	if err != nil {
+		exitWithError(fmt.Sprintf("auditing v%d: %v", version, err), "")
+	}
+	if record == nil {
+		fmt.Printf("No image previews to audit in v%d.\n", version)
+		return
+	}
+	printAuditRecord(record)
+}
+
+// runAuditEnable turns on the audit step
+func runAuditEnable(cmd *cobra.Command, args []string) {
+	manager := audit.NewAuditManager(checkDgitRepository())
+	config, err := manager.GetConfig()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	config.Enabled = true
+	config.MinContrast, _ = cmd.Flags().GetFloat64("min-contrast")
+	if cmd.Flags().Changed("pattern") {
+		config.Patterns, _ = cmd.Flags().GetStringSlice("pattern")
+	}
+	if err := manager.SetConfig(config); err != nil {
+		exitWithError(fmt.Sprintf("enabling audit: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Previews will be audited for a contrast ratio of at least %.1f:1", config.MinContrast))
+}
+
+// runAuditDisable turns off the audit step
+func runAuditDisable(cmd *cobra.Command, args []string) {
+	manager := audit.NewAuditManager(checkDgitRepository())
+	config, err := manager.GetConfig()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	config.Enabled = false
+	if err := manager.SetConfig(config); err != nil {
+		exitWithError(fmt.Sprintf("disabling audit: %v", err), "")
+	}
+	printSuccess("Previews will no longer be audited")
+}
+
+// auditCommitPreviews audits freshly generated previews when the audit step is enabled
+func auditCommitPreviews(dgitDir string, version int, results []*preview.Result) {
+	manager := audit.NewAuditManager(dgitDir)
+	if config, err := manager.GetConfig(); err != nil || !config.Enabled {
+		return
+	}
+	var previews []*preview.Preview
+	for _, result := range results {
+		if result.Preview != nil {
+			previews = append(previews, result.Preview)
+		}
+	}
+	record, err := manager.AuditVersion(version, previews)
+	if err != nil {
+		printWarning(fmt.Sprintf("contrast audit skipped: %v", err))
+		return
+	}
+	if record == nil {
+		return
+	}
+	for _, finding := range record.Files {
+		if finding.Failed() {
+			printWarning(fmt.Sprintf("%s: %d region(s) below %.1f:1 contrast (worst %.2f:1)",
+				finding.Source, finding.Failing, record.MinContrast, finding.WorstRatio))
+		}
+	}
+}
+
+// printAuditRecord lists each audited file's result
+func printAuditRecord(record *log.AuditRecord) {
+	for _, finding := range record.Files {
+		switch {
+		case finding.Error != "":
+			fmt.Printf("  %s  %s\n", finding.Source, yellow(finding.Error))
+		case finding.TextRegions == 0:
+			fmt.Printf("  %s  no text-like regions found\n", finding.Source)
+		case finding.Failed():
+			fmt.Printf("  %s  %s  %d of %d region(s) below %.1f:1, worst %.2f:1 (%s on %s at %d,%d)\n",
+				finding.Source, red("FAIL"), finding.Failing, finding.TextRegions, record.MinContrast,
+				finding.WorstRatio, finding.Foreground, finding.Background, finding.X, finding.Y)
+		default:
+			fmt.Printf("  %s  %s  %d region(s), lowest %.2f:1\n", finding.Source, green("pass"), finding.TextRegions, finding.WorstRatio)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/backup"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd represents the backup command for copying the repository to another volume
+// Sets are layered: an incremental set copies only what changed and points at earlier sets for the rest
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the repository to another volume",
+	Long: `Copy the whole repository (.dgit) into a backup set on another volume.
+With --incremental only objects and metadata that are new or changed since the
+latest set on that destination are copied; the new set records where every
+other file lives, so any set can be restored on its own.
+
+Examples:
+  dgit backup --to /Volumes/Backup                  # Full backup
+  dgit backup --to /Volumes/Backup --incremental    # Copy only what changed
+  dgit backup list /Volumes/Backup
+  dgit backup restore /Volumes/Backup ~/Restored    # Latest set into ~/Restored/.dgit`,
+	Args: cobra.NoArgs,
+	Run:  runBackup,
+}
+
+// backupListCmd lists the sets on a destination
+var backupListCmd = &cobra.Command{
+	Use:   "list <destination>",
+	Short: "List backup sets",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBackupList,
+}
+
+// backupRestoreCmd rebuilds a repository from a set
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <destination> <target-dir>",
+	Short: "Rebuild a repository from a backup set",
+	Args:  cobra.ExactArgs(2),
+	Run:   runBackupRestore,
+}
+
+// init sets up backup subcommands and flags
+func init() {
+	BackupCmd.Flags().String("to", "", "Destination directory, e.g. a mounted backup volume")
+	BackupCmd.Flags().Bool("incremental", false, "Copy only files new or changed since the latest set")
+	backupRestoreCmd.Flags().String("set", "", "Set to restore (default: latest); a prefix is enough")
+
+	BackupCmd.AddCommand(backupListCmd)
+	BackupCmd.AddCommand(backupRestoreCmd)
+}
+
+// runBackup writes a new backup set
+func runBackup(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	destination, _ := cmd.Flags().GetString("to")
+	if destination == "" {
+		manager := backup.NewBackupManager(dgitDir)
+		if last, err := manager.LastBackups(); err == nil && len(last) > 0 {
+			printInfo("Previous backups:")
+			for dest, b := range last {
+				fmt.Printf("  %s  set %s (%s)\n", dest, b.Set, b.Time.Format("2006-01-02 15:04"))
+			}
+		}
+		exitWithError("no destination given", "Use 'dgit backup --to /Volumes/Backup'")
+	}
+	incremental, _ := cmd.Flags().GetBool("incremental")
+
+	result, err := backup.NewBackupManager(dgitDir).Backup(destination, incremental)
+	if err != nil {
+		exitWithError(fmt.Sprintf("backup failed: %v", err), "Check that the destination is mounted and writable")
+	}
+	m := result.Manifest
+	kind := "Full"
+	if m.Incremental {
+		kind = "Incremental"
+	} else if incremental {
+		printInfo("No earlier set on this destination; writing a full backup")
+	}
+	printSuccess(fmt.Sprintf("%s backup %s written to %s", kind, m.Set, result.Dir))
+	fmt.Printf("  Copied:  %d file(s), %s\n", m.Copied, util.FormatBytes(m.CopiedBytes))
+	if m.Incremental {
+		fmt.Printf("  Reused:  %d unchanged file(s) from earlier sets\n", result.Reused)
+		if result.Removed > 0 {
+			fmt.Printf("  Removed: %d file(s) no longer in the repository\n", result.Removed)
+		}
+	}
+	fmt.Printf("  Total:   %d file(s), %s in %s\n", len(m.Files), util.FormatBytes(m.TotalBytes), result.Elapsed.Round(time.Millisecond))
+}
+
+// runBackupList prints the sets of a backup destination
+func runBackupList(cmd *cobra.Command, args []string) {
+	dir, err := backup.FindBackupDir(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Pass the directory given to 'dgit backup --to'")
+	}
+	sets, err := backup.ListSets(dir)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if len(sets) == 0 {
+		printInfo(fmt.Sprintf("No complete backup sets in %s", dir))
+		return
+	}
+	fmt.Printf("%s (%s)\n", bold(filepath.Base(dir)), sets[0].Repository)
+	for _, m := range sets {
+		kind := "full"
+		if m.Incremental {
+			kind = "incremental"
+		}
+		fmt.Printf("  %s  %-11s  %d file(s), %s copied of %s\n",
+			m.Set, kind, len(m.Files), util.FormatBytes(m.CopiedBytes), util.FormatBytes(m.TotalBytes))
+	}
+}
+
+// runBackupRestore rebuilds a repository's .dgit directory from a set
+func runBackupRestore(cmd *cobra.Command, args []string) {
+	dir, err := backup.FindBackupDir(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Pass the directory given to 'dgit backup --to'")
+	}
+	set, _ := cmd.Flags().GetString("set")
+	manifest, err := backup.Restore(dir, set, args[1])
+	if err != nil {
+		exitWithError(fmt.Sprintf("restore failed: %v", err), "Run 'dgit backup list' to see the available sets")
+	}
+	printSuccess(fmt.Sprintf("Restored set %s (%d file(s)) into %s", manifest.Set, len(manifest.Files), filepath.Join(args[1], ".dgit")))
+	printSuggestion(fmt.Sprintf("Run 'dgit log' in %s, then 'dgit restore' to get the design files back", args[1]))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/branch"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// BranchCmd represents the branch command for diverging design iterations
+// Each branch is a ref under .dgit/refs/heads; HEAD names the branch new commits go to
+var BranchCmd = &cobra.Command{
+	Use:   "branch [name]",
+	Short: "List, create or delete branches",
+	Long: `List branches, or create one so a design direction can diverge without
+touching the others. Commits go to the branch HEAD points to; switch with
+'dgit checkout <branch>'.
+
+Examples:
+  dgit branch                          # List branches, * marks the current one
+  dgit branch client-feedback          # Branch from HEAD
+  dgit branch print-version --from v4  # Branch from an older version
+  dgit branch -d client-feedback       # Delete a branch whose work is on another branch`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runBranch,
+}
+
+// CheckoutCmd switches the current branch
+var CheckoutCmd = &cobra.Command{
+	Use:   "checkout <branch>",
+	Short: "Switch to a branch and restore its files",
+	Long: `Point HEAD at a branch and write the files as committed on that branch into
+the working directory. Files only committed on the previous branch are left in
+place. Uncommitted edits that would be overwritten stop the switch unless
+--force is given.
+
+Examples:
+  dgit checkout print-version
+  dgit checkout -b client-feedback     # Create the branch from HEAD and switch to it
+  dgit checkout main --force           # Discard uncommitted edits to files that differ`,
+	Args: cobra.ExactArgs(1),
+	Run:  runCheckout,
+}
+
+// init sets up command flags for branch and checkout commands
+func init() {
+	BranchCmd.Flags().String("from", "", "Version or commit hash to start the branch at (default: HEAD)")
+	BranchCmd.Flags().BoolP("delete", "d", false, "Delete the branch")
+	BranchCmd.Flags().Bool("force", false, "Delete even if its commits are on no other branch")
+
+	CheckoutCmd.Flags().BoolP("create", "b", false, "Create the branch from HEAD before switching")
+	CheckoutCmd.Flags().Bool("force", false, "Overwrite uncommitted edits")
+}
+
+// runBranch lists, creates or deletes branches
+func runBranch(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := branch.NewBranchManager(dgitDir)
+
+	if len(args) == 0 {
+		branches, err := manager.List()
+		if err != nil {
+			exitWithError(fmt.Sprintf("listing branches: %v", err), "")
+		}
+		for _, b := range branches {
+			marker := " "
+			name := b.Name
+			if b.Current {
+				marker, name = "*", green(b.Name)
+			}
+			if b.Hash == "" {
+				fmt.Printf("%s %s (no commits yet)\n", marker, name)
+				continue
+			}
+			fmt.Printf("%s %s  v%d %s\n", marker, name, b.Version, b.Message)
+		}
+		return
+	}
+
+	name := args[0]
+	if remove, _ := cmd.Flags().GetBool("delete"); remove {
+		force, _ := cmd.Flags().GetBool("force")
+		if err := manager.Delete(name, force); err != nil {
+			exitWithError(err.Error(), "")
+		}
+		printSuccess(fmt.Sprintf("Deleted branch %s", name))
+		return
+	}
+
+	var start *log.Commit
+	if from, _ := cmd.Flags().GetString("from"); from != "" {
+		c, err := findTargetCommit(log.NewLogManager(dgitDir), from)
+		if err != nil {
+			exitWithError(fmt.Sprintf("finding %s: %v", from, err), "Run 'dgit log' to list versions")
+		}
+		start = c
+	}
+	b, err := manager.Create(name, start)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	printSuccess(fmt.Sprintf("Created branch %s at v%d", b.Name, b.Version))
+	printSuggestion(fmt.Sprintf("Run 'dgit checkout %s' to commit on it", b.Name))
+}
+
+// runCheckout switches branches
+func runCheckout(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := branch.NewBranchManager(dgitDir)
+	name := args[0]
+
+	if create, _ := cmd.Flags().GetBool("create"); create {
+		if _, err := manager.Create(name, nil); err != nil {
+			exitWithError(err.Error(), "")
+		}
+	}
+	force, _ := cmd.Flags().GetBool("force")
+
+	var result *branch.CheckoutResult
+	var err error
+	withQuietStdout(func() { result, err = manager.Checkout(name, force) })
+	if err != nil {
+		exitWithError(err.Error(), "Run 'dgit branch' to list branches")
+	}
+
+	printSuccess(fmt.Sprintf("Switched to branch %s (v%d)", result.Branch, result.Version))
+	for _, path := range result.Restored {
+		fmt.Printf("  restored %s\n", path)
+	}
+	if len(result.Kept) > 0 {
+		printInfo(fmt.Sprintf("%d file(s) not on %s were left in place", len(result.Kept), result.Branch))
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/bundle"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// BundleCmd represents the bundle command for offline history transport
+// Moves versions between machines as a single file, without a live remote
+var BundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Package history into a single file for offline transfer",
+	Long: `Package a range of versions, including their snapshot data, into one file.
+
+Bundles can be copied over USB drives or email and applied on another machine
+with 'dgit unbundle'. The receiving repository must already have the commit
+the bundle builds on.
+
+Examples:
+  dgit bundle create out.bundle v10..v20      # Versions 10 through 20
+  dgit bundle create out.bundle v10..         # Version 10 up to the latest
+  dgit bundle create full.bundle              # Entire history
+  dgit bundle create client.bundle --redact   # Strip/hash layer names, emails (see "redaction" in config)
+  dgit bundle verify out.bundle               # Check it applies to this repository
+  dgit bundle list out.bundle                 # Show the bundled commits`,
+}
+
+// bundleCreateCmd writes a bundle file
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <file> [range]",
+	Short: "Create a bundle from a version range",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runBundleCreate,
+}
+
+// bundleVerifyCmd checks a bundle against the repository
+var bundleVerifyCmd = &cobra.Command{
+	Use:   "verify <file>",
+	Short: "Check that a bundle can be applied to this repository",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBundleVerify,
+}
+
+// bundleListCmd lists the commits in a bundle
+var bundleListCmd = &cobra.Command{
+	Use:   "list <file>",
+	Short: "List the commits in a bundle",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBundleList,
+}
+
+// init sets up bundle subcommands
+func init() {
+	bundleCreateCmd.Flags().Bool("redact", false, "Strip or hash sensitive metadata per the repository redaction policy")
+
+	BundleCmd.AddCommand(bundleCreateCmd)
+	BundleCmd.AddCommand(bundleVerifyCmd)
+	BundleCmd.AddCommand(bundleListCmd)
+}
+
+// runBundleCreate creates a bundle file from a version range
+func runBundleCreate(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	latest := log.NewLogManager(dgitDir).GetCurrentVersion()
+	if latest == 0 {
+		exitWithError("no commits to bundle", "Create a commit first with 'dgit commit'")
+	}
+
+	rangeSpec := ""
+	if len(args) > 1 {
+		rangeSpec = args[1]
+	}
+	from, to, err := bundle.ParseRange(rangeSpec, latest)
+	if err != nil {
+		exitWithError(err.Error(), "Use a range like v10..v20")
+	}
+
+	manager := bundle.NewBundleManager(dgitDir)
+	if redactFlag, _ := cmd.Flags().GetBool("redact"); redactFlag {
+		policy, err := redact.LoadPolicy(dgitDir)
+		if err != nil {
+			exitWithError(fmt.Sprintf("loading redaction policy: %v", err), "Check the \"redaction\" section of .dgit/config")
+		}
+		manager.Redaction = policy
+	}
+
+	started := time.Now()
+	header, err := manager.Create(args[0], from, to)
+	if err != nil {
+		exitWithError(fmt.Sprintf("creating bundle: %v", err), "")
+	}
+	usage := accounting.Event{Operation: accounting.OpBundle, Version: header.ToVersion,
+		DurationMs: float64(time.Since(started).Microseconds()) / 1000}
+	if info, err := os.Stat(args[0]); err == nil {
+		usage.BytesTransferred = info.Size()
+	}
+	accounting.NewAccountingManager(dgitDir).Record(usage)
+
+	printSuccess(fmt.Sprintf("Bundled v%d..v%d (%d commits) into %s", header.FromVersion, header.ToVersion,
+		len(header.Commits), args[0]))
+	if header.Prerequisite != "" {
+		fmt.Printf("Requires commit %s on the receiving side\n", header.Prerequisite[:8])
+	}
+	if len(header.Redacted) > 0 {
+		printInfo(fmt.Sprintf("Redacted %d metadata value(s): %s", header.RedactedValues, strings.Join(header.Redacted, ", ")))
+	}
+}
+
+// runBundleVerify checks that a bundle applies to this repository
+func runBundleVerify(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	header, err := bundle.NewBundleManager(dgitDir).Verify(args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("bundle cannot be applied: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("%s is valid: v%d..v%d from %s", args[0], header.FromVersion, header.ToVersion,
+		header.Repository))
+}
+
+// runBundleList prints the commits contained in a bundle
+func runBundleList(cmd *cobra.Command, args []string) {
+	header, err := bundle.ReadHeader(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+
+	fmt.Printf("Bundle from %s, created %s\n\n", header.Repository, header.CreatedAt.Format("2006-01-02 15:04"))
+	for _, c := range header.Commits {
+		fmt.Printf("%s (v%d) %s\n", c.Hash[:8], c.Version, c.Message)
+	}
+	if header.Prerequisite != "" {
+		fmt.Printf("\nRequires: %s\n", header.Prerequisite[:8])
+	}
+	if len(header.Redacted) > 0 {
+		fmt.Printf("Redacted: %s\n", strings.Join(header.Redacted, ", "))
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cache"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// CacheCmd represents the cache command for managing the hot, warm and cold tiers
+var CacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and maintain the hot, warm and cold caches",
+	Long: `Show and maintain the 3-tier cache under .dgit/cache.
+
+The hot tier holds LZ4 snapshots written at commit time, the warm tier Zstd
+copies made in the background, and the cold tier archived versions. Clearing a
+tier only removes copies another tier still holds, after checking that copy
+decodes; the cold tier is managed with 'dgit archive'.
+
+Tier budgets and the eviction policy come from compression.cache in the
+repository config: hot_cache_size and warm_cache_size (MB), eviction_policy
+(LRU, LFU or FIFO) and access_threshold, the number of restores after which a
+version is promoted back to hot. Budgets are enforced after every commit and
+promotion: hot snapshots are demoted to warm, and the newest version stays hot.
+
+Examples:
+  dgit cache status            # Tier sizes against the configured limits
+  dgit cache clear warm        # Drop warm copies of versions that are still hot
+  dgit cache clear hot         # Free the hot tier, keeping versions with no other copy
+  dgit cache rebuild -n 20     # Rebuild hot copies of the 20 most recent versions
+  dgit cache warm v12          # Make v12 instant to restore before a restore session
+  dgit cache evict             # Apply the eviction policy now`,
+	Run: runCacheStatus,
+}
+
+// cacheStatusCmd shows tier sizes
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show each tier's size against its configured limit",
+	Args:  cobra.NoArgs,
+	Run:   runCacheStatus,
+}
+
+// cacheClearCmd removes redundant copies from one tier
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear <hot|warm>",
+	Short: "Remove a tier's copies of versions another tier also holds",
+	Args:  cobra.ExactArgs(1),
+	Run:   runCacheClear,
+}
+
+// cacheRebuildCmd restores hot copies of recent versions
+var cacheRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the hot copies of the most recent versions",
+	Args:  cobra.NoArgs,
+	Run:   runCacheRebuild,
+}
+
+// cacheWarmCmd restores the hot copy of one version
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm <version>",
+	Short: "Rebuild one version's hot copy from the warm or cold tier",
+	Args:  cobra.ExactArgs(1),
+	Run:   runCacheWarm,
+}
+
+// cacheEvictCmd applies the eviction policy
+var cacheEvictCmd = &cobra.Command{
+	Use:   "evict",
+	Short: "Bring the hot and warm tiers under their budgets using the eviction policy",
+	Args:  cobra.NoArgs,
+	Run:   runCacheEvict,
+}
+
+// init sets up cache subcommands and flags
+func init() {
+	CacheCmd.AddCommand(cacheStatusCmd)
+	CacheCmd.AddCommand(cacheClearCmd)
+	CacheCmd.AddCommand(cacheRebuildCmd)
+	CacheCmd.AddCommand(cacheWarmCmd)
+	CacheCmd.AddCommand(cacheEvictCmd)
+	CacheCmd.Flags().Bool("json", false, "Print the status as JSON")
+	cacheStatusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	cacheRebuildCmd.Flags().IntP("number", "n", 10, "How many recent versions to rebuild")
+}
+
+// runCacheStatus prints each tier's snapshot size against its limit, and its versions
+func runCacheStatus(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := cache.NewCacheManager(dgitDir)
+	statuses, err := manager.Status()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading cache: %v", err), "")
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Sprintf("encoding status: %v", err), "")
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	policy := manager.LoadPolicy()
+	promotion := "never promoted"
+	if policy.AccessThreshold > 0 {
+		promotion = fmt.Sprintf("promoted to hot after %d restore(s)", policy.AccessThreshold)
+	}
+	fmt.Printf("Eviction: %s, %s\n", policy.Eviction, promotion)
+	for _, status := range statuses {
+		line := fmt.Sprintf("  %-5s %10s", status.Name, util.FormatBytes(status.Snapshots))
+		if status.Limit > 0 {
+			usage := fmt.Sprintf(" of %s (%.0f%%)", util.FormatBytes(status.Limit), float64(status.Snapshots)*100/float64(status.Limit))
+			if status.Snapshots > status.Limit {
+				usage = red(usage)
+			}
+			line += usage
+		}
+		fmt.Println(line)
+		if len(status.Versions) > 0 {
+			fmt.Printf("        %d version(s): %s\n", len(status.Versions), formatVersionList(status.Versions))
+		}
+		if len(status.Only) > 0 && status.Name != cache.Cold {
+			fmt.Printf("        only copy of %s\n", formatVersionList(status.Only))
+		}
+	}
+}
+
+// runCacheClear removes the redundant copies in one tier
+func runCacheClear(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	tier := strings.ToLower(args[0])
+	result, err := cache.NewCacheManager(dgitDir).Clear(tier)
+	if err != nil {
+		if result == nil {
+			exitWithError(err.Error(), "")
+		}
+		printError(err.Error())
+	}
+
+	if len(result.Removed) == 0 {
+		printInfo(fmt.Sprintf("No %s copies to remove", tier))
+	} else {
+		printSuccess(fmt.Sprintf("Removed %d %s copy(ies): %s", len(result.Removed), tier, formatVersionList(result.Removed)))
+	}
+	if result.Freed > 0 {
+		fmt.Printf("Freed %s\n", util.FormatBytes(result.Freed))
+	}
+	if len(result.Kept) > 0 {
+		printWarning(fmt.Sprintf("Kept %s: no other tier holds a readable copy", formatVersionList(result.Kept)))
+	}
+	if err != nil {
+		exitWithError("clear stopped early", "")
+	}
+}
+
+// runCacheRebuild rebuilds hot copies of the most recent versions
+func runCacheRebuild(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	number, _ := cmd.Flags().GetInt("number")
+	results, failed, err := cache.NewCacheManager(dgitDir).Rebuild(number)
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading history: %v", err), "")
+	}
+
+	rebuilt := 0
+	for _, result := range results {
+		if result.Source != "" {
+			rebuilt++
+			fmt.Printf("  v%-4d rebuilt from %s (%s)\n", result.Version, result.Source, util.FormatBytes(result.Size))
+		}
+	}
+	versions := make([]int, 0, len(failed))
+	for version := range failed {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	for _, version := range versions {
+		printWarning(failed[version].Error())
+	}
+
+	switch {
+	case rebuilt > 0:
+		printSuccess(fmt.Sprintf("Rebuilt %d hot copy(ies); %d already hot", rebuilt, len(results)-rebuilt))
+	case len(results) > 0:
+		printInfo(fmt.Sprintf("All %d version(s) are already hot", len(results)))
+	case len(failed) == 0:
+		printInfo("No cached versions to rebuild")
+	}
+}
+
+// runCacheWarm rebuilds the hot copy of one version
+func runCacheWarm(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	version, err := parseVersionArg(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Use a version such as v5")
+	}
+	result, err := cache.NewCacheManager(dgitDir).Warm(version)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+
+	subject := fmt.Sprintf("v%d", version)
+	if result.Version != version {
+		subject = fmt.Sprintf("v%d (delta base of v%d)", result.Version, version)
+	}
+	if result.Source == "" {
+		printInfo(fmt.Sprintf("%s is already in the hot cache", subject))
+		return
+	}
+	printSuccess(fmt.Sprintf("Warmed %s from the %s tier (%s)", subject, result.Source, util.FormatBytes(result.Size)))
+}
+
+// runCacheEvict enforces the tier budgets and reports what moved
+func runCacheEvict(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	results, err := cache.NewCacheManager(dgitDir).Enforce()
+	for _, result := range results {
+		if result.Limit == 0 {
+			fmt.Printf("  %-5s %10s, no budget\n", result.Tier, util.FormatBytes(result.Size))
+			continue
+		}
+		fmt.Printf("  %-5s %10s -> %s of %s (%s)\n", result.Tier, util.FormatBytes(result.Size), util.FormatBytes(result.After),
+			util.FormatBytes(result.Limit), result.Policy)
+		if len(result.Evicted) > 0 {
+			fmt.Printf("        evicted %s\n", formatVersionList(sortedVersions(result.Evicted)))
+		}
+		if len(result.Demoted) > 0 {
+			fmt.Printf("        demoted to warm: %s\n", formatVersionList(sortedVersions(result.Demoted)))
+		}
+		if result.Over() {
+			printWarning(fmt.Sprintf("The %s tier is still over budget; the rest is the only copy of its versions", result.Tier))
+		}
+	}
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+}
+
+// sortedVersions returns a sorted copy of a version list
+func sortedVersions(versions []int) []int {
+	sorted := append([]int(nil), versions...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// formatVersionList formats versions as "v1-v3, v5", collapsing consecutive runs
+func formatVersionList(versions []int) string {
+	var parts []string
+	for i := 0; i < len(versions); {
+		j := i
+		for j+1 < len(versions) && versions[j+1] == versions[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("v%d-v%d", versions[i], versions[j]))
+		} else {
+			parts = append(parts, fmt.Sprintf("v%d", versions[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cclib"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// CCLibCmd represents the cclib command for the Creative Cloud Libraries connector
+// Commits pin the library elements their files link to, so old versions keep their graphics
+var CCLibCmd = &cobra.Command{
+	Use:   "cclib [version]",
+	Short: "Pin Creative Cloud Library elements linked from committed files",
+	Long: `Adobe apps link graphics from Creative Cloud Libraries by reference, so a
+file restored months later shows whatever the library holds today. When the
+connector is enabled, every commit copies the library elements its files link
+to from the local Creative Cloud cache into .dgit/cclib, and 'dgit restore'
+exports them next to the restored version.
+
+Elements must be synced by the Creative Cloud app at commit time; links to
+elements that are not available locally are recorded with a warning.
+
+Examples:
+  dgit cclib enable                      # Snapshot linked elements on commit
+  dgit cclib enable --dir /path/to/LIBS  # Use a non-default library cache
+  dgit cclib                             # Elements pinned by HEAD
+  dgit cclib v12                         # Elements pinned by v12
+  dgit cclib export v12 ./brand-v12      # Write v12's elements to a folder
+  dgit cclib disable`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runCCLibList,
+}
+
+// cclibEnableCmd turns the connector on
+var cclibEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Snapshot linked CC Library elements with every commit",
+	Args:  cobra.NoArgs,
+	Run:   runCCLibEnable,
+}
+
+// cclibDisableCmd turns the connector off
+var cclibDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop snapshotting CC Library elements (pinned ones stay)",
+	Args:  cobra.NoArgs,
+	Run:   runCCLibDisable,
+}
+
+// cclibExportCmd writes a version's pinned elements to a folder
+var cclibExportCmd = &cobra.Command{
+	Use:   "export <version> <dir> [file...]",
+	Short: "Export the CC Library elements pinned by a version",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   runCCLibExport,
+}
+
+// init sets up cclib subcommands and flags
+func init() {
+	cclibEnableCmd.Flags().String("dir", "", "Creative Cloud library cache (default: the app's LIBS folder)")
+
+	CCLibCmd.AddCommand(cclibEnableCmd)
+	CCLibCmd.AddCommand(cclibDisableCmd)
+	CCLibCmd.AddCommand(cclibExportCmd)
+}
+
+// runCCLibList shows the elements pinned by a version
+func runCCLibList(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := cclib.NewCCLibraryManager(dgitDir)
+	if manager.Enabled() {
+		printInfo(fmt.Sprintf("CC Libraries connector enabled (%s)", manager.LibrariesDir))
+	} else {
+		printInfo("CC Libraries connector disabled; enable it with 'dgit cclib enable'")
+	}
+
+	logManager := log.NewLogManager(dgitDir)
+	ref := ""
+	if len(args) == 1 {
+		ref = args[0]
+	} else if head := logManager.GetHeadVersion(); head > 0 {
+		ref = fmt.Sprintf("v%d", head)
+	} else {
+		return
+	}
+	commit, err := findTargetCommit(logManager, ref)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if len(commit.CCLibraryAssets) == 0 {
+		fmt.Printf("v%d pins no CC Library elements.\n", commit.Version)
+		return
+	}
+
+	fmt.Printf("v%d pins %d CC Library element(s):\n", commit.Version, len(commit.CCLibraryAssets))
+	for _, asset := range commit.CCLibraryAssets {
+		name, library := asset.Name, asset.LibraryName
+		if name == "" {
+			name = asset.ElementID
+		}
+		if library == "" {
+			library = asset.LibraryID
+		}
+		var size int64
+		for _, component := range asset.Components {
+			size += component.Size
+		}
+		fmt.Printf("  %s / %s  %d file(s), %s\n", library, bold(name), len(asset.Components), util.FormatBytes(size))
+		for _, path := range asset.ReferencedBy {
+			fmt.Printf("      used by %s\n", path)
+		}
+		if asset.Error != "" {
+			fmt.Printf("      %s\n", red(asset.Error))
+		}
+	}
+}
+
+// runCCLibEnable turns the connector on
+func runCCLibEnable(cmd *cobra.Command, args []string) {
+	manager := cclib.NewCCLibraryManager(checkDgitRepository())
+	dir, _ := cmd.Flags().GetString("dir")
+	if err := manager.SetEnabled(true, dir); err != nil {
+		exitWithError(fmt.Sprintf("enabling CC Libraries: %v", err), "")
+	}
+	printSuccess("CC Library elements will be pinned with every commit")
+	printInfo(fmt.Sprintf("Library cache: %s", manager.LibrariesDir))
+}
+
+// runCCLibDisable turns the connector off
+func runCCLibDisable(cmd *cobra.Command, args []string) {
+	manager := cclib.NewCCLibraryManager(checkDgitRepository())
+	if err := manager.SetEnabled(false, ""); err != nil {
+		exitWithError(fmt.Sprintf("disabling CC Libraries: %v", err), "")
+	}
+	printSuccess("CC Library elements will no longer be pinned")
+}
+
+// runCCLibExport writes a version's pinned elements to a folder
+func runCCLibExport(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	commit, err := findTargetCommit(log.NewLogManager(dgitDir), args[0])
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if len(commit.CCLibraryAssets) == 0 {
+		exitWithError(fmt.Sprintf("v%d pins no CC Library elements", commit.Version), "")
+	}
+	exportCCLibraryAssets(dgitDir, commit, args[2:], args[1])
+}
+
+// exportCCLibraryAssets writes the elements pinned by a commit and reports failures
+// An empty dir exports to .dgit/cclib/restored/v<N>
+func exportCCLibraryAssets(dgitDir string, commit *log.Commit, paths []string, dir string) {
+	manager := cclib.NewCCLibraryManager(dgitDir)
+	if dir == "" {
+		dir = manager.VersionDir(commit.Version)
+	}
+	written, failures := manager.ExportCommit(commit.CCLibraryAssets, paths, dir)
+	for name, err := range failures {
+		printWarning(fmt.Sprintf("CC Library element %s: %v", name, err))
+	}
+	if written > 0 {
+		printSuccess(fmt.Sprintf("Exported %d CC Library file(s) pinned by v%d to %s", written, commit.Version, dir))
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/clean"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// CleanCmd represents the clean command for removing application residue
+// Design tools leave autosave, lock and temp files next to the sources they edit
+var CleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove untracked residue: autosaves, temp files, throwaway exports",
+	Long: `Find residue in the work tree and remove it:
+
+- autosaves, temp saves, lock files and recovery folders left by Photoshop,
+  Illustrator, XD, Sketch, Affinity, Blender and the operating system
+- untracked files matching the "clean.patterns" rules in .dgit/config
+  (by default *.tmp, *.bak, renders/tmp/ and exports/tmp/)
+
+Tracked and staged files, design source files and paths matching the
+"clean.protected" patterns are never touched. Without -f nothing is removed;
+removed files go to the trash and can be brought back with 'dgit trash restore'.
+
+Examples:
+  dgit clean -n             # Preview what would be removed
+  dgit clean -f             # Move it to the trash`,
+	Args: cobra.NoArgs,
+	Run:  runClean,
+}
+
+// init sets up command flags for clean command
+func init() {
+	CleanCmd.Flags().BoolP("dry-run", "n", false, "Only list what would be removed")
+	CleanCmd.Flags().BoolP("force", "f", false, "Move residue to the trash")
+}
+
+// runClean executes the clean command functionality
+func runClean(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	remove, _ := cmd.Flags().GetBool("force")
+	if dryRun {
+		remove = false
+	}
+
+	manager := clean.NewCleanManager(dgitDir)
+	items, err := manager.Find()
+	if err != nil {
+		exitWithError(fmt.Sprintf("finding residue: %v", err), "")
+	}
+	if len(items) == 0 {
+		printSuccess("Nothing to clean")
+		return
+	}
+
+	var total int64
+	for _, item := range items {
+		path := item.Path
+		if item.Dir {
+			path += "/"
+		}
+		fmt.Printf("  [%s] %s (%s, %s)\n", item.App, path, item.Reason, util.FormatBytes(item.Size))
+		total += item.Size
+	}
+	fmt.Println()
+
+	if !remove {
+		fmt.Printf("Would remove %d item(s), %s\n", len(items), util.FormatBytes(total))
+		if !dryRun {
+			printSuggestion("Run 'dgit clean -f' to move them to the trash")
+		}
+		return
+	}
+	if err := manager.Remove(items); err != nil {
+		exitWithError(fmt.Sprintf("removing residue: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Moved %d item(s), %s, to the trash", len(items), util.FormatBytes(total)))
+	printInfo("Use 'dgit trash' to review or restore them")
+}
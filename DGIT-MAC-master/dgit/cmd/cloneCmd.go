@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/clone"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// CloneCmd represents the clone command for copying an existing repository
+// Sources are a repository path or a 'dgit serve' URL; the latest version is checked out
+var CloneCmd = &cobra.Command{
+	Use:   "clone <source> [directory]",
+	Short: "Copy a repository into a new directory",
+	Long: `Create a new working directory from an existing repository: its history,
+snapshot data and shared state (reviews, notes, previews) are copied and the
+latest version is checked out. Tokens, keys and hooks are not copied.
+
+The source is a repository on disk or the URL of a machine running
+'dgit serve'; a URL clone adds it as the remote 'origin' so 'dgit pull' and
+'dgit push' work straight away.
+
+Examples:
+  dgit clone /Volumes/NAS/brand-refresh
+  dgit clone /Volumes/NAS/brand-refresh ~/work/brand --cache-tiers warm,cold
+  dgit clone http://nas.studio.local:8080 brand --token 3f9c...`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runClone,
+}
+
+// init sets up command flags for clone command
+func init() {
+	CloneCmd.Flags().StringSlice("cache-tiers", nil, "Cache tiers to copy from a local source (hot, warm, cold; default all)")
+	CloneCmd.Flags().String("token", "", "Access token for a URL source (default $DGIT_REMOTE_TOKEN)")
+	CloneCmd.Flags().Bool("no-checkout", false, "Copy the repository without restoring any files")
+}
+
+// runClone copies a repository and checks out its latest version
+func runClone(cmd *cobra.Command, args []string) {
+	source := args[0]
+	target := cloneTarget(source)
+	if len(args) == 2 {
+		target = args[1]
+	}
+
+	manager := clone.NewCloneManager(target)
+	manager.Token, _ = cmd.Flags().GetString("token")
+	if names, _ := cmd.Flags().GetStringSlice("cache-tiers"); len(names) > 0 {
+		if clone.IsRemote(source) {
+			exitWithError("--cache-tiers only applies to local sources", "A URL clone receives each version once, as its bundle")
+		}
+		tiers, err := clone.ParseTiers(names)
+		if err != nil {
+			exitWithError(err.Error(), "Example: --cache-tiers warm,cold")
+		}
+		manager.Tiers = tiers
+	}
+	manager.Progress = printCloneProgress
+
+	fmt.Printf("Cloning %s into %s...\n", source, target)
+	result, err := manager.Clone(source)
+	fmt.Print("\r\033[K")
+	if err != nil {
+		exitWithError(fmt.Sprintf("clone failed: %v", err), "")
+	}
+	summary := fmt.Sprintf("Cloned %d version(s) (%s)", result.Versions, util.FormatBytes(result.Bytes))
+	if result.Skipped > 0 {
+		summary += fmt.Sprintf(", %d cache copies left out", result.Skipped)
+	}
+	printSuccess(summary)
+
+	noCheckout, _ := cmd.Flags().GetBool("no-checkout")
+	if result.Head == nil || noCheckout {
+		return
+	}
+	restoreManager := restore.NewRestoreManager(manager.DgitDir)
+	restoreManager.WorkDir = target
+	var restoreErr error
+	withQuietStdout(func() {
+		restoreErr = restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", result.Head.Version), nil, result.Head)
+	})
+	if restoreErr != nil {
+		exitWithError(fmt.Sprintf("checking out v%d: %v", result.Head.Version, restoreErr),
+			fmt.Sprintf("The history was cloned; run 'dgit restore v%d' inside %s", result.Head.Version, target))
+	}
+	printSuccess(fmt.Sprintf("Checked out v%d: %s", result.Head.Version, result.Head.Message))
+}
+
+// cloneTarget derives the directory name from the source, as in 'dgit clone /path/brand' → brand
+func cloneTarget(source string) string {
+	name := strings.TrimRight(source, "/")
+	if clone.IsRemote(name) {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "http://"), "https://")
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		name = strings.Split(name, ":")[0]
+	} else {
+		if filepath.Base(name) == ".dgit" {
+			name = filepath.Dir(name)
+		}
+		name = filepath.Base(name)
+	}
+	if name == "" || name == "." || name == string(os.PathSeparator) {
+		return "clone"
+	}
+	return name
+}
+
+// printCloneProgress redraws a one-line progress bar
+func printCloneProgress(p clone.Progress) {
+	const width = 30
+	label := p.File
+	if label == "" {
+		label = "downloading"
+	}
+	if len(label) > 40 {
+		label = "..." + label[len(label)-37:]
+	}
+	if p.Total <= 0 {
+		fmt.Printf("\r\033[K  %s %s", util.FormatBytes(p.Done), label)
+		return
+	}
+	filled := int(p.Done * width / p.Total)
+	fmt.Printf("\r\033[K  [%s%s] %3d%% %s / %s %s", strings.Repeat("#", filled), strings.Repeat(" ", width-filled),
+		p.Done*100/p.Total, util.FormatBytes(p.Done), util.FormatBytes(p.Total), label)
+}
@@ -2,12 +2,23 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 	
-	"dgit/internal/commit"
-	"dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/search"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -22,12 +33,14 @@ Examples:
   dgit commit "Logo design completed"
   dgit commit -m "Updated color scheme to brand guidelines"
   dgit commit                       # Opens editor for commit message
+  dgit commit --all -F msg.txt --json   # Scripted: stage changes, commit, print JSON
+  dgit commit --only hero.psd -m "hero only"   # Commit one file, keep the rest staged
 
 The commit will:
 - Create a snapshot (ZIP) of all staged files
 - Extract and store metadata for each design file  
 - Generate a unique commit hash
-- Clear the staging area`,
+- Clear the staging area (with --only, just the committed files)`,
 	Args: cobra.MaximumNArgs(1),  // Optional commit message as argument
 	Run:  runCommit,
 }
@@ -36,6 +49,14 @@ The commit will:
 func init() {
 	// Add -m flag for commit message (similar to git)
 	CommitCmd.Flags().StringP("message", "m", "", "Commit message")
+	CommitCmd.Flags().Bool("no-preview", false, "Skip the post-commit preview generation step")
+	CommitCmd.Flags().String("author", "", "Commit as this author instead of the configured one (or set DGIT_AUTHOR)")
+	CommitCmd.Flags().String("email", "", "Author email for this commit (or set DGIT_EMAIL)")
+	CommitCmd.Flags().BoolP("all", "a", false, "Stage all modified and untracked design files before committing")
+	CommitCmd.Flags().StringP("message-from-file", "F", "", "Read the commit message from a file ('-' for stdin)")
+	CommitCmd.Flags().Bool("json", false, "Print the created commit as JSON on stdout (progress goes to stderr)")
+	CommitCmd.Flags().StringSlice("only", nil, "Commit only the staged files matching these paths, folders or globs; the rest stay staged")
+	CommitCmd.Flags().Bool("deterministic", false, "Reproducible output: timestamps from file mtimes, sorted inputs (see commit.deterministic)")
 }
 
 // runCommit executes the commit command functionality
@@ -50,7 +71,18 @@ func runCommit(cmd *cobra.Command, args []string) {
 
 	// Get repository and staging area
 	dgitDir := findDgitDirectory()
+	repoLock := lockRepository(dgitDir, "commit")
+	defer repoLock.Release()
 	stagingArea := staging.NewStagingArea(dgitDir)
+	stagingArea.Lock = repoLock
+
+	// Scripted mode: progress goes to stderr so stdout carries only the commit object
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	resultOut := os.Stdout
+	if jsonOutput {
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+	}
 	
 	// Load current staging area state
 	if err := stagingArea.LoadStaging(); err != nil {
@@ -58,41 +90,45 @@ func runCommit(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Check if there are any files to commit
-	if stagingArea.IsEmpty() {
-		fmt.Println("No files staged for commit.")
-		fmt.Println("   Use 'dgit add <files>' to stage files for commit.")
+	// Resolve the message before touching the staging area so scripts fail early
+	message, err := commitMessage(cmd, args, jsonOutput)
+	if err != nil {
+		printError(err.Error())
 		os.Exit(1)
 	}
 
-	// Get commit message from various sources (args, flag, or interactive input)
-	var message string
-	if len(args) > 0 {
-		// Message provided as argument
-		message = args[0]
-	} else if msgFlag, _ := cmd.Flags().GetString("message"); msgFlag != "" {
-		// Message provided via -m flag
-		message = msgFlag
-	} else {
-		// Interactive input for commit message
-		fmt.Print("Enter commit message: ")
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
+	// --all stages every modified and untracked design file first
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		added, err := stageAllChanges(dgitDir, stagingArea)
 		if err != nil {
-			printError(fmt.Sprintf("reading commit message: %v", err))
+			printError(fmt.Sprintf("staging changes: %v", err))
 			os.Exit(1)
 		}
-		message = strings.TrimSpace(input)
-
-		// Ensure message is not empty
-		if message == "" {
-			printError("commit message cannot be empty")
+		if err := stagingArea.SaveStaging(); err != nil {
+			printError(fmt.Sprintf("saving staging area: %v", err))
 			os.Exit(1)
 		}
+		fmt.Printf("Staged %d changed file(s)\n", added)
 	}
 
-	// Get staged files for processing
+	// Check if there are any files to commit
+	if stagingArea.IsEmpty() {
+		fmt.Println("No files staged for commit.")
+		fmt.Println("   Use 'dgit add <files>' to stage files for commit.")
+		os.Exit(1)
+	}
+
+	// Get staged files for processing; --only narrows them to a subset
 	stagedFiles := stagingArea.GetStagedFiles()
+	only, _ := cmd.Flags().GetStringSlice("only")
+	if len(only) > 0 {
+		stagedFiles, err = selectStagedFiles(stagingArea, only)
+		if err != nil {
+			printError(err.Error())
+			printSuggestion("Use 'dgit status' to see what is staged")
+			os.Exit(1)
+		}
+	}
 	
 	// Display DGit-style commit progress messages
 	fmt.Printf("Creating commit with %d design files...\n", len(stagedFiles))
@@ -101,22 +137,44 @@ func runCommit(cmd *cobra.Command, args []string) {
 	
 	// Create the actual commit with metadata and snapshot
 	commitManager := commit.NewCommitManager(dgitDir)
+	commitManager.Lock = repoLock
+	if deterministic, _ := cmd.Flags().GetBool("deterministic"); deterministic {
+		commitManager.Deterministic = true
+	}
+	commitManager.Author, _ = cmd.Flags().GetString("author")
+	commitManager.Email, _ = cmd.Flags().GetString("email")
+	started := time.Now()
 	newCommit, err := commitManager.CreateCommit(message, stagedFiles)
 	if err != nil {
 		printError(fmt.Sprintf("creating commit: %v", err))
 		os.Exit(1)
 	}
+	usage := accounting.Event{Operation: accounting.OpCommit, Version: newCommit.Version,
+		DurationMs: float64(time.Since(started).Microseconds()) / 1000}
+	if newCommit.CompressionInfo != nil {
+		usage.BytesStored = newCommit.CompressionInfo.CompressedSize
+	}
+	accounting.NewAccountingManager(dgitDir).Record(usage)
 
-	// Clear staging area after successful commit
-	if err := stagingArea.ClearStaging(); err != nil {
+	// Clear staging area after successful commit; --only keeps the files it left out
+	if len(only) > 0 {
+		if err := unstageCommitted(stagingArea, stagedFiles); err != nil {
+			printWarning(fmt.Sprintf("failed to update staging area: %v", err))
+		}
+	} else if err := stagingArea.ClearStaging(); err != nil {
 		printWarning(fmt.Sprintf("failed to clear staging area: %v", err))
 	}
 
+	// Keep the search index current (incremental - only the new commit is read)
+	if _, err := search.NewSearchIndex(dgitDir).Update(); err != nil {
+		printWarning(fmt.Sprintf("failed to update search index: %v", err))
+	}
+
 	// Display DGit-style success message with commit details
 	fmt.Printf("\n")
 	printGreen(fmt.Sprintf("Created commit %s", newCommit.Hash[:8]))
-	fmt.Printf("%s\n", message)
-	printCyan(fmt.Sprintf("Author: %s", newCommit.Author))
+	fmt.Printf("%s\n", newCommit.Message)
+	printCyan(fmt.Sprintf("Author: %s", formatCommitAuthor(newCommit.Author, newCommit.Email, newCommit.AuthorSource)))
 	
 	// Show design-specific file details (unique to DGit!)
 	printBlue(fmt.Sprintf("Design files (%d):", newCommit.FilesCount))
@@ -155,8 +213,140 @@ func runCommit(cmd *cobra.Command, args []string) {
 		}
 	}
 	
-	printGreen(fmt.Sprintf("Snapshot: %s", newCommit.SnapshotZip))
+	if len(newCommit.Blobs) > 0 {
+		reused := 0
+		if newCommit.CompressionInfo != nil {
+			reused = newCommit.CompressionInfo.ReusedFiles
+		}
+		printGreen(fmt.Sprintf("Blobs: %d new, %d reused", len(newCommit.Blobs)-reused, reused))
+	} else if newCommit.SnapshotZip != "" {
+		printGreen(fmt.Sprintf("Snapshot: %s", newCommit.SnapshotZip))
+	}
+
+	// Post-commit pipeline: proxies for teammates without the authoring apps
+	if noPreview, _ := cmd.Flags().GetBool("no-preview"); !noPreview {
+		committed := make(map[string]string, len(stagedFiles))
+		for _, file := range stagedFiles {
+			committed[file.Path] = file.AbsolutePath
+		}
+		generateCommitPreviews(dgitDir, newCommit.Version, committed)
+	}
+	if len(only) > 0 && !stagingArea.IsEmpty() {
+		printYellow(fmt.Sprintf("%d file(s) still staged", stagingArea.GetFileCount()))
+	}
 	printBold("Ready for collaboration!")
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(newCommit, "", "  ")
+		if err != nil {
+			printError(fmt.Sprintf("encoding commit: %v", err))
+			os.Exit(1)
+		}
+		fmt.Fprintln(resultOut, string(data))
+	}
+}
+
+// commitMessage takes the message from the argument, -m, --message-from-file, or an interactive prompt
+// Prompting is refused in --json mode, which must never wait for input
+func commitMessage(cmd *cobra.Command, args []string, nonInteractive bool) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if msgFlag, _ := cmd.Flags().GetString("message"); msgFlag != "" {
+		return msgFlag, nil
+	}
+	if file, _ := cmd.Flags().GetString("message-from-file"); file != "" {
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading commit message: %w", err)
+		}
+		message := strings.TrimSpace(string(data))
+		if message == "" {
+			return "", fmt.Errorf("commit message file %s is empty", file)
+		}
+		return message, nil
+	}
+	if nonInteractive {
+		return "", fmt.Errorf("a commit message is required (use -m or --message-from-file)")
+	}
+
+	// Interactive input for commit message
+	fmt.Print("Enter commit message: ")
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("reading commit message: %w", err)
+	}
+	message := strings.TrimSpace(input)
+	if message == "" {
+		return "", fmt.Errorf("commit message cannot be empty")
+	}
+	return message, nil
+}
+
+// selectStagedFiles returns the staged files matching any of the --only patterns, each file once
+// A pattern that matches nothing staged is an error so a typo never commits less than intended
+func selectStagedFiles(stagingArea *staging.StagingArea, patterns []string) ([]*staging.StagedFile, error) {
+	var selected []*staging.StagedFile
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		files, err := stagingArea.SelectPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !seen[file.AbsolutePath] {
+				seen[file.AbsolutePath] = true
+				selected = append(selected, file)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// unstageCommitted drops the files of a partial commit from the staging area and saves it
+func unstageCommitted(stagingArea *staging.StagingArea, committed []*staging.StagedFile) error {
+	for _, file := range committed {
+		if err := stagingArea.RemoveFile(file.AbsolutePath); err != nil {
+			return err
+		}
+	}
+	return stagingArea.SaveStaging()
+}
+
+// stageAllChanges stages the modified and untracked design files of the working tree
+// Pinned files and untracked files listed in .dgitignore are left out; returns the number of files staged
+func stageAllChanges(dgitDir string, stagingArea *staging.StagingArea) (int, error) {
+	currentWorkDir := filepath.Dir(dgitDir)
+	logManager := log.NewLogManager(dgitDir)
+	head, _ := logManager.GetCommit(logManager.GetCurrentVersion())
+	currentFiles := scanCurrentDirectory(currentWorkDir, submodule.NewSubmoduleManager(dgitDir).ModuleDirs(),
+		scanner.NewIgnoreMatcher(currentWorkDir), trackedPaths(head, currentWorkDir))
+	result, err := status.NewStatusManager(dgitDir).CompareWithCommit(logManager.GetCurrentVersion(), currentFiles)
+	if err != nil {
+		return 0, err
+	}
+
+	changed, _ := splitPinnedFiles(append(result.ModifiedFiles, result.UntrackedFiles...),
+		loadStatusPins(dgitDir, currentWorkDir), nil)
+	added := 0
+	for _, file := range changed {
+		path := filepath.Join(currentWorkDir, filepath.FromSlash(file.Path))
+		if stagingArea.HasFile(path) {
+			continue
+		}
+		if err := stagingArea.AddFile(path); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
 }
 
 // getFileType returns file type string based on file extension
@@ -172,8 +362,12 @@ func getFileType(fileName string) string {
 		return "SKETCH" // Sketch
 	} else if strings.HasSuffix(lowerName, ".fig") {
 		return "FIG"  // Figma
+	} else if strings.HasSuffix(lowerName, ".figma") {
+		return "FIGMA" // Figma cloud document
 	} else if strings.HasSuffix(lowerName, ".xd") {
 		return "XD"   // Adobe XD
+	} else if strings.HasSuffix(lowerName, ".blend") {
+		return "BLEND" // Blender
 	}
 	return "FILE"  // Generic file
 }
\ No newline at end of file
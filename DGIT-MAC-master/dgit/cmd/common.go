@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+
 	"github.com/fatih/color"
+	"github.com/spf13/cobra"
 )
 
 // Color functions using fatih/color library for better compatibility
@@ -66,6 +71,17 @@ func checkDgitRepository() string {
 	return findDgitDirectory()
 }
 
+// lockRepository takes the repository lock for a command's whole read-modify-write, exiting if another
+// dgit process keeps it past the wait
+func lockRepository(dgitDir, operation string) *repolock.Lock {
+	lock, err := repolock.Acquire(dgitDir, operation, repolock.DefaultWait)
+	if err != nil {
+		exitWithError(err.Error(), fmt.Sprintf("Wait for it to finish; if no dgit process is running, delete %s",
+			filepath.Join(dgitDir, repolock.File)))
+	}
+	return lock
+}
+
 // exitWithError prints error messages and exits with status code 1
 // Provides consistent error handling across all commands
 func exitWithError(message string, suggestion string) {
@@ -107,5 +123,16 @@ func printInfo(message string) {
 	fmt.Println(message)
 }
 
+// messageAuthor returns the --by flag value, falling back to the repository author
+func messageAuthor(cmd *cobra.Command, dgitDir string) string {
+	by, _ := cmd.Flags().GetString("by")
+	if by == "" {
+		if config, err := initializer.GetRepositoryConfig(dgitDir); err == nil {
+			by = config.Author
+		}
+	}
+	return by
+}
+
 // Helper functions for colored output using fatih/color library
 // These functions provide convenient access to colored printing
\ No newline at end of file
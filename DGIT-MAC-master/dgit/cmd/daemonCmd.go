@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/recompress"
+
+	"github.com/spf13/cobra"
+)
+
+// DaemonCmd represents the daemon command for background repository work
+// It processes the watch-folder ingest rules, syncs Figma files, recompresses deprecated objects and optimizes the cache tiers on an interval
+var DaemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run background processing (watch-folder ingest, Figma sync, recompression, optimization)",
+	Long: `Run in the foreground and process the repository's ingest rules every
+interval until interrupted. See 'dgit ingest' for configuring rules.
+
+Each pass also rewrites a few storage objects still in a deprecated format
+(see 'dgit recompress'), so old repositories converge on their own. This runs
+in the idle IO class: it is rate limited (io.idle_mbps in the config) and
+pauses while a commit or restore is running on the repository. Once every
+compression.zstd_stage.optimize_interval it also runs 'dgit optimize'.
+
+Figma files added with 'dgit figma add' are synced every --figma-interval,
+which is kept longer than the ingest interval to respect API rate limits.
+
+Examples:
+  dgit daemon                  # Check watch folders every 30 seconds
+  dgit daemon --interval 5m    # Check every five minutes
+  dgit daemon --once           # Single pass, e.g. from cron
+  dgit daemon --figma-interval 0  # Never call the Figma API`,
+	Args: cobra.NoArgs,
+	Run:  runDaemon,
+}
+
+// init sets up command flags for daemon command
+func init() {
+	DaemonCmd.Flags().Duration("interval", 30*time.Second, "Time between passes")
+	DaemonCmd.Flags().Bool("once", false, "Run a single pass and exit")
+	DaemonCmd.Flags().Int("recompress-batch", 10, "Deprecated objects to rewrite per pass (0 = off)")
+	DaemonCmd.Flags().Duration("figma-interval", 15*time.Minute, "Time between Figma syncs (0 = off)")
+	DaemonCmd.Flags().String("io-class", string(iosched.Idle), "IO scheduling class for recompression and optimization: foreground, background or idle")
+}
+
+// runDaemon processes ingest rules until interrupted
+func runDaemon(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	interval, _ := cmd.Flags().GetDuration("interval")
+	once, _ := cmd.Flags().GetBool("once")
+	batch, _ := cmd.Flags().GetInt("recompress-batch")
+	figmaInterval, _ := cmd.Flags().GetDuration("figma-interval")
+	sched := ioSchedulerFlag(cmd, dgitDir)
+	if interval <= 0 {
+		exitWithError("interval must be positive", "e.g. --interval 30s")
+	}
+
+	if once {
+		if err := ingestOnce(dgitDir, false, false); err != nil {
+			exitWithError(fmt.Sprintf("ingesting: %v", err), "")
+		}
+		if figmaInterval > 0 {
+			if err := figmaSyncOnce(dgitDir, false); err != nil {
+				printWarning(fmt.Sprintf("figma sync failed: %v", err))
+			}
+		}
+		recompressBatch(dgitDir, batch, sched)
+		optimizeDue(dgitDir, sched)
+		return
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	printInfo(fmt.Sprintf("DGit daemon running, checking watch folders every %s (Ctrl+C to stop)", interval))
+	var lastFigma time.Time
+	for {
+		if err := ingestOnce(dgitDir, false, false); err != nil {
+			printWarning(fmt.Sprintf("ingest pass failed: %v", err))
+		}
+		if figmaInterval > 0 && time.Since(lastFigma) >= figmaInterval {
+			if err := figmaSyncOnce(dgitDir, false); err != nil {
+				printWarning(fmt.Sprintf("figma sync failed: %v", err))
+			}
+			lastFigma = time.Now()
+		}
+		recompressBatch(dgitDir, batch, sched)
+		optimizeDue(dgitDir, sched)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			fmt.Println("DGit daemon stopped.")
+			return
+		}
+	}
+}
+
+// recompressRescanInterval is how often the daemon looks for new deprecated objects once none are pending
+const recompressRescanInterval = time.Hour
+
+// recompressBatch rewrites up to batch deprecated objects, rescanning only when nothing is pending
+func recompressBatch(dgitDir string, batch int, sched *iosched.Scheduler) {
+	if batch <= 0 {
+		return
+	}
+	manager := recompress.NewRecompressManager(dgitDir)
+	manager.IO = sched
+	state, err := manager.LoadState()
+	if err != nil {
+		printWarning(fmt.Sprintf("recompress: %v", err))
+		return
+	}
+	if state.Summarize().Pending == 0 {
+		if time.Since(state.ScannedAt) < recompressRescanInterval {
+			return
+		}
+		if state, err = manager.Scan(); err != nil {
+			printWarning(fmt.Sprintf("recompress scan failed: %v", err))
+			return
+		}
+		if state.Summarize().Pending == 0 {
+			return
+		}
+	}
+	state, rewritten, err := manager.Run(batch)
+	if err != nil {
+		printWarning(fmt.Sprintf("recompress pass failed: %v", err))
+	}
+	if len(rewritten) > 0 {
+		progress := state.Summarize()
+		printInfo(fmt.Sprintf("Recompressed %d object(s), %d pending", len(rewritten), progress.Pending))
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/deliver"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// DeliverCmd represents the deliver command for packaging final assets for clients
+// Unique to DGit - produces verifiable provenance for what was handed over
+var DeliverCmd = &cobra.Command{
+	Use:   "deliver [version_or_hash]",
+	Short: "Export a version for client delivery",
+	Long: `Package every file of a version into a zip archive for delivery.
+
+With --manifest, a signed manifest is written next to the archive listing
+each file's checksum, dimensions and color mode, the commit hash, and who
+approved the version. Clients can verify the manifest with the embedded
+public key. The repository approval policy applies (approved by default),
+and delivered versions are marked as delivered.
+
+With --checksums, every file in the archive gets a .sha256 sidecar, the
+archive gets a SHA256SUMS list of all files, and <archive>.sha256 is
+written next to it. Print vendors can check what they received with
+standard tools ('sha256sum -c'), without DGit.
+
+Examples:
+  dgit deliver v9 --manifest                    # Write <repo>-v9.zip and <repo>-v9.manifest.json
+  dgit deliver v9 --manifest -o ~/Deliveries    # Choose the output directory
+  dgit deliver v9 --checksums                   # Add .sha256 sidecars and SHA256SUMS for vendors
+  dgit deliver --verify client-v9.manifest.json # Check signature and archive checksum
+  dgit deliver --public-key                     # Print the key clients verify against`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDeliver,
+}
+
+// init sets up command flags for deliver command
+func init() {
+	DeliverCmd.Flags().Bool("manifest", false, "Write a signed delivery manifest alongside the archive")
+	DeliverCmd.Flags().Bool("checksums", false, "Add .sha256 sidecars and a SHA256SUMS list for each delivered file")
+	DeliverCmd.Flags().StringP("output", "o", ".", "Directory to write the delivery into")
+	DeliverCmd.Flags().String("by", "", "Name of the person delivering (default: repository author)")
+	DeliverCmd.Flags().String("verify", "", "Verify a delivery manifest instead of delivering")
+	DeliverCmd.Flags().String("key", "", "Expected public key when verifying (default: key embedded in manifest)")
+	DeliverCmd.Flags().Bool("public-key", false, "Print the repository delivery public key")
+}
+
+// runDeliver executes the deliver command functionality
+func runDeliver(cmd *cobra.Command, args []string) {
+	// Verification works outside a repository so clients can check deliveries
+	if manifestPath, _ := cmd.Flags().GetString("verify"); manifestPath != "" {
+		expectedKey, _ := cmd.Flags().GetString("key")
+		manifest, err := deliver.VerifyManifest(manifestPath, expectedKey)
+		if err != nil {
+			exitWithError(fmt.Sprintf("manifest verification failed: %v", err), "")
+		}
+		printSuccess(fmt.Sprintf("Manifest valid: %s v%d (%s), %d files", manifest.Repository,
+			manifest.Version, manifest.CommitHash, len(manifest.Files)))
+		if manifest.Approver != "" {
+			fmt.Printf("Approved by: %s\n", manifest.Approver)
+		}
+		return
+	}
+
+	dgitDir := checkDgitRepository()
+	manager := deliver.NewDeliveryManager(dgitDir)
+
+	if showKey, _ := cmd.Flags().GetBool("public-key"); showKey {
+		key, err := manager.PublicKey()
+		if err != nil {
+			exitWithError(fmt.Sprintf("loading delivery key: %v", err), "")
+		}
+		fmt.Println(key)
+		return
+	}
+
+	if len(args) == 0 {
+		exitWithError("no version specified", "Usage: dgit deliver <version_or_hash> [--manifest]")
+	}
+
+	targetCommit, err := findTargetCommit(log.NewLogManager(dgitDir), args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to find commit: %v", err), "")
+	}
+
+	outputDir, _ := cmd.Flags().GetString("output")
+	withManifest, _ := cmd.Flags().GetBool("manifest")
+	manager.Checksums, _ = cmd.Flags().GetBool("checksums")
+
+	started := time.Now()
+	result, err := manager.Deliver(targetCommit, outputDir, withManifest, messageAuthor(cmd, dgitDir))
+	if err != nil {
+		exitWithError(fmt.Sprintf("delivering v%d: %v", targetCommit.Version, err),
+			fmt.Sprintf("Use 'dgit approve v%d' once the version is signed off", targetCommit.Version))
+	}
+	usage := accounting.Event{Operation: accounting.OpDeliver, Version: targetCommit.Version,
+		DurationMs: float64(time.Since(started).Microseconds()) / 1000}
+	if info, err := os.Stat(result.ArchivePath); err == nil {
+		usage.BytesTransferred = info.Size()
+	}
+	accounting.NewAccountingManager(dgitDir).Record(usage)
+
+	fmt.Println()
+	printSuccess(fmt.Sprintf("Delivered v%d (%s)", targetCommit.Version, targetCommit.Hash[:8]))
+	fmt.Printf("Archive:  %s\n", result.ArchivePath)
+	if result.ChecksumPath != "" {
+		fmt.Printf("Checksum: %s (%d file sidecars, %s)\n", result.ChecksumPath, result.Files, deliver.ChecksumsName)
+	}
+	if result.Manifest != nil {
+		fmt.Printf("Manifest: %s\n", result.ManifestPath)
+		fmt.Printf("Files:    %d\n", len(result.Manifest.Files))
+		if result.Manifest.Approver != "" {
+			fmt.Printf("Approver: %s\n", result.Manifest.Approver)
+		}
+	}
+}
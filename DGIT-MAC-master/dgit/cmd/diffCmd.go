@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/diff"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// DiffCmd represents the diff command for design-aware version comparison
+// Compares the metadata recorded at commit time rather than the binary content
+var DiffCmd = &cobra.Command{
+	Use:   "diff [from] [to] [file]",
+	Short: "Compare design changes between versions",
+	Long: `Show what changed in design terms between two versions, or between a version
+and the working tree: files added and removed, layer count and layer names,
+dimensions, color mode, artboards and the change in file size.
+
+With no arguments the working tree is compared with HEAD; with one version
+the working tree is compared with that version.
+
+With --visual, the embedded thumbnails of one file in two versions are
+compared pixel by pixel: the percentage of perceptibly changed pixels is
+printed and a side-by-side or blended PNG is written to a temporary folder
+(or --output-dir). In iTerm2, WezTerm and Kitty the image is shown inline.
+
+Examples:
+  dgit diff                         # Working tree vs HEAD
+  dgit diff v3                      # Working tree vs v3
+  dgit diff v3 v5                   # v3 vs v5
+  dgit diff v3 v5 --path poster.psd # Only poster.psd
+  dgit diff --visual v3 v5 poster.psd
+  dgit diff --visual v3 v5 poster.psd --mode blend --output-dir review/`,
+	Args: cobra.MaximumNArgs(3),
+	Run:  runDiff,
+}
+
+// init sets up command flags for diff command
+func init() {
+	DiffCmd.Flags().StringSlice("path", nil, "Only compare these files, directories or glob patterns")
+	DiffCmd.Flags().Bool("visual", false, "Compare the thumbnails of one file in two versions as an image")
+	DiffCmd.Flags().String("mode", diff.ModeSideBySide, "Visual comparison layout: side-by-side or blend")
+	DiffCmd.Flags().Float64("threshold", diff.DefaultThreshold, "Color distance (0-1) below which pixels count as unchanged")
+	DiffCmd.Flags().String("output-dir", "", "Folder for the visual comparison (default: a new temporary folder)")
+}
+
+// runDiff compares two versions or a version and the working tree
+func runDiff(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	logManager := log.NewLogManager(dgitDir)
+	paths, _ := cmd.Flags().GetStringSlice("path")
+	if visual, _ := cmd.Flags().GetBool("visual"); visual {
+		runVisualDiff(cmd, dgitDir, logManager, args)
+		return
+	}
+	if len(args) > 2 {
+		exitWithError("a file argument needs --visual", "Use --path to limit a metadata diff to some files")
+	}
+
+	var from *log.Commit
+	if len(args) == 0 {
+		hash := logManager.HeadHash()
+		if hash == "" {
+			exitWithError("no commits yet", "Commit a version before comparing")
+		}
+		c, err := logManager.GetCommitByHash(hash)
+		if err != nil {
+			exitWithError(fmt.Sprintf("loading HEAD: %v", err), "")
+		}
+		from = c
+	} else {
+		from = findDiffCommit(logManager, args[0])
+	}
+
+	manager := diff.NewDiffManager(dgitDir)
+	var result *diff.Result
+	var err error
+	if len(args) == 2 {
+		result, err = manager.Versions(from, findDiffCommit(logManager, args[1]), paths)
+	} else {
+		result, err = manager.WorkingTree(from, paths)
+	}
+	if err != nil {
+		exitWithError(fmt.Sprintf("comparing versions: %v", err), "")
+	}
+	manager.Render(os.Stdout, result)
+}
+
+// findDiffCommit resolves a version or hash argument, exiting when it does not exist
+func findDiffCommit(logManager *log.LogManager, ref string) *log.Commit {
+	c, err := findTargetCommit(logManager, ref)
+	if err != nil {
+		exitWithError(fmt.Sprintf("finding %s: %v", ref, err), "Run 'dgit log' to list versions")
+	}
+	return c
+}
+
+// runVisualDiff renders the before/after comparison of one file's thumbnails
+func runVisualDiff(cmd *cobra.Command, dgitDir string, logManager *log.LogManager, args []string) {
+	if len(args) != 3 {
+		exitWithError("--visual needs two versions and a file", "dgit diff --visual v3 v5 poster.psd")
+	}
+	var opts diff.VisualOptions
+	opts.Mode, _ = cmd.Flags().GetString("mode")
+	opts.Threshold, _ = cmd.Flags().GetFloat64("threshold")
+	opts.OutputDir, _ = cmd.Flags().GetString("output-dir")
+
+	from, to := findDiffCommit(logManager, args[0]), findDiffCommit(logManager, args[1])
+	var result *diff.VisualResult
+	var err error
+	// Versions without stored thumbnails are read back from storage, quietly
+	withQuietStdout(func() { result, err = diff.NewDiffManager(dgitDir).Visual(from, to, args[2], opts) })
+	if err != nil {
+		exitWithError(err.Error(), "Visual diffs need an embedded thumbnail (PSD, AI, Sketch, Figma, XD, Blender)")
+	}
+
+	fmt.Printf("%s: %s -> %s\n", result.Path, result.From, result.To)
+	fmt.Printf("   %.2f%% of pixels changed (%d of %d at %dx%d)\n", result.Difference, result.ChangedPixels,
+		result.Width*result.Height, result.Width, result.Height)
+	if result.Resized {
+		printInfo(fmt.Sprintf("The %s thumbnail was scaled to %dx%d to match %s", result.From, result.Width, result.Height, result.To))
+	}
+	printInlineImage(result.Output)
+	fmt.Printf("   %s\n", result.Output)
+}
+
+// printInlineImage shows a PNG in terminals with an image protocol (iTerm2/WezTerm, Kitty)
+// Other terminals and redirected output get nothing; the caller prints the file path
+func printInlineImage(path string) {
+	if !isTerminal(os.Stdout) {
+		return
+	}
+	kitty := os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("TERM") == "xterm-kitty"
+	iterm := os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("TERM_PROGRAM") == "WezTerm"
+	if !kitty && !iterm {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if iterm {
+		fmt.Printf("\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n", len(data), encoded)
+		return
+	}
+	// Kitty takes the PNG in base64 chunks of at most 4096 bytes
+	for first := true; len(encoded) > 0; first = false {
+		chunk := encoded[:min(len(encoded), 4096)]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if len(encoded) > 0 {
+			more = 1
+		}
+		control := fmt.Sprintf("m=%d", more)
+		if first {
+			control = "a=T,f=100," + control
+		}
+		fmt.Printf("\x1b_G%s;%s\x1b\\", control, chunk)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/export"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd represents the export command for mirroring history into other tools
+// Each DGit version becomes a Git commit with its files and a metadata sidecar
+var ExportCmd = &cobra.Command{
+	Use:   "export --git <path>",
+	Short: "Mirror DGit history into a Git repository",
+	Long: `Replay every DGit version as a Git commit, so design history can be browsed
+and pushed with existing Git tooling.
+
+Each Git commit keeps the version's message, author and timestamp and holds
+the files committed in that version plus ` + export.SidecarName + `, which
+carries the version number, DGit hash and per-file design metadata. The
+mainline goes to the branch given by --branch; DGit branches keep their names.
+
+Running the export again adds only the versions committed since the last run.
+Git must be installed.
+
+Examples:
+  dgit export --git ../brand-git                 # Create or update a Git mirror
+  dgit export --git ../brand-git --branch master # Put the mainline on master`,
+	Args: cobra.NoArgs,
+	Run:  runExport,
+}
+
+// init sets up export flags
+func init() {
+	ExportCmd.Flags().String("git", "", "Git repository to write to (created when missing)")
+	ExportCmd.Flags().String("branch", export.DefaultGitBranch, "Git branch that receives the mainline")
+	ExportCmd.Flags().BoolP("verbose", "v", false, "Print each exported version")
+}
+
+// runExport writes the repository history to a Git repository
+func runExport(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	target, _ := cmd.Flags().GetString("git")
+	if target == "" {
+		exitWithError("an export target is required", "Example: dgit export --git ../brand-git")
+	}
+	branch, _ := cmd.Flags().GetString("branch")
+
+	manager := export.NewExportManager(dgitDir)
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		manager.Progress = os.Stdout
+	}
+	result, err := manager.ExportGit(target, branch)
+	if err != nil {
+		exitWithError(fmt.Sprintf("export failed: %v", err), "")
+	}
+
+	if result.Commits == 0 {
+		printInfo(fmt.Sprintf("%s is up to date", result.Target))
+		return
+	}
+	printSuccess(fmt.Sprintf("Exported v%d..v%d as %d Git commit(s) to %s", result.From, result.To, result.Commits, result.Target))
+	fmt.Printf("Files: %d (%s)\n", result.Files, util.FormatBytes(result.Bytes))
+	fmt.Printf("Branches: %s\n", strings.Join(result.Branches, ", "))
+	if len(result.Skipped) > 0 {
+		printWarning(fmt.Sprintf("Skipped %d file(s) recorded outside the repository: %s", len(result.Skipped), strings.Join(result.Skipped, ", ")))
+		printSuggestion("Run 'dgit metadata normalize-paths' and export again")
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/figma"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/search"
+
+	"github.com/spf13/cobra"
+)
+
+// FigmaCmd represents the figma command for syncing Figma cloud files
+// Each named version in Figma becomes a commit of the file's document
+var FigmaCmd = &cobra.Command{
+	Use:   "figma",
+	Short: "Sync named versions of Figma cloud files",
+	Long: `Pull named versions of Figma files through the Figma REST API and commit
+them. Each version's document is stored as a .figma file (the API's JSON
+export; the REST API cannot export .fig binaries), its first frame is
+rendered as the commit's preview, and the version label and description
+become the commit message. Autosaves without a name are skipped.
+
+The API token is read from FIGMA_TOKEN or .dgit/keys/figma.token.
+'dgit daemon' syncs on a schedule, and 'dgit serve' can trigger a sync
+through the "figma" webhook action.
+
+Examples:
+  dgit figma                                          # List synced files
+  dgit figma token                                    # Store a token (read from stdin)
+  dgit figma add https://www.figma.com/design/AbC123/App design/app
+  dgit figma sync --dry-run                           # Show versions not yet synced
+  dgit figma sync
+  dgit figma remove AbC123`,
+	Args: cobra.NoArgs,
+	Run:  runFigmaList,
+}
+
+// figmaAddCmd adds or replaces a synced file
+var figmaAddCmd = &cobra.Command{
+	Use:   "add <key|url> <path>",
+	Short: "Sync a Figma file into a repository path",
+	Args:  cobra.ExactArgs(2),
+	Run:   runFigmaAdd,
+}
+
+// figmaRemoveCmd stops syncing a file
+var figmaRemoveCmd = &cobra.Command{
+	Use:   "remove <key|url>",
+	Short: "Stop syncing a Figma file (its history stays)",
+	Args:  cobra.ExactArgs(1),
+	Run:   runFigmaRemove,
+}
+
+// figmaSyncCmd pulls new named versions
+var figmaSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Commit named versions created since the last sync",
+	Args:  cobra.NoArgs,
+	Run:   runFigmaSync,
+}
+
+// figmaTokenCmd stores the API token
+var figmaTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Store a Figma personal access token for this repository",
+	Args:  cobra.NoArgs,
+	Run:   runFigmaToken,
+}
+
+// init sets up figma subcommands and flags
+func init() {
+	figmaSyncCmd.Flags().BoolP("dry-run", "n", false, "Only list the versions that would be committed")
+
+	FigmaCmd.AddCommand(figmaAddCmd)
+	FigmaCmd.AddCommand(figmaRemoveCmd)
+	FigmaCmd.AddCommand(figmaSyncCmd)
+	FigmaCmd.AddCommand(figmaTokenCmd)
+}
+
+// runFigmaList prints the synced files and how far each has been synced
+func runFigmaList(cmd *cobra.Command, args []string) {
+	manager := figma.NewFigmaManager(checkDgitRepository())
+	files, err := manager.GetFiles()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading config: %v", err), "")
+	}
+	if len(files) == 0 {
+		fmt.Println("No Figma files are synced.")
+		printSuggestion("Add one with 'dgit figma add <url> <path>'")
+		return
+	}
+	state, err := manager.LoadState()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	for _, file := range files {
+		synced := "not synced yet"
+		if fileState := state[file.Key]; fileState != nil && len(fileState.Versions) > 0 {
+			synced = fmt.Sprintf("%d version(s), last %s", len(fileState.Versions),
+				fileState.LastCreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("  %s  → %s  (%s)\n", bold(file.Key), file.Path, synced)
+	}
+	if _, err := manager.Token(); err != nil {
+		printWarning(err.Error())
+	}
+}
+
+// runFigmaAdd adds a file to the sync configuration
+func runFigmaAdd(cmd *cobra.Command, args []string) {
+	manager := figma.NewFigmaManager(checkDgitRepository())
+	key, err := figma.ParseKey(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Use the file URL or the key from figma.com/design/<key>/...")
+	}
+	if err := manager.AddFile(initializer.FigmaFile{Key: key, Path: args[1]}); err != nil {
+		exitWithError(fmt.Sprintf("adding Figma file: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Syncing Figma file %s", key))
+	printInfo("Run 'dgit figma sync' to pull its named versions")
+}
+
+// runFigmaRemove removes a file from the sync configuration
+func runFigmaRemove(cmd *cobra.Command, args []string) {
+	manager := figma.NewFigmaManager(checkDgitRepository())
+	key, err := figma.ParseKey(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if err := manager.RemoveFile(key); err != nil {
+		exitWithError(err.Error(), "Run 'dgit figma' to list synced files")
+	}
+	printSuccess(fmt.Sprintf("Stopped syncing %s", key))
+}
+
+// runFigmaSync pulls new named versions once
+func runFigmaSync(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if err := figmaSyncOnce(checkDgitRepository(), dryRun); err != nil {
+		exitWithError(fmt.Sprintf("syncing Figma: %v", err), "")
+	}
+}
+
+// runFigmaToken reads a token from stdin so it never lands in shell history
+func runFigmaToken(cmd *cobra.Command, args []string) {
+	manager := figma.NewFigmaManager(checkDgitRepository())
+	fmt.Fprint(os.Stderr, "Figma personal access token: ")
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	token := strings.TrimSpace(line)
+	if token == "" {
+		exitWithError("no token given", "Create one under Figma → Settings → Personal access tokens")
+	}
+	if err := manager.SaveToken(token); err != nil {
+		exitWithError(err.Error(), "")
+	}
+	printSuccess("Stored the Figma token in .dgit/keys/figma.token")
+}
+
+// figmaSyncOnce runs one sync pass from the repository root and reports the results
+// Shared by 'dgit figma sync', 'dgit daemon' and the webhook endpoint
+func figmaSyncOnce(dgitDir string, dryRun bool) error {
+	manager := figma.NewFigmaManager(dgitDir)
+	if err := os.Chdir(manager.RootDir); err != nil {
+		return fmt.Errorf("failed to enter repository root: %w", err)
+	}
+
+	results, err := manager.Sync(dryRun)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	for _, result := range results {
+		for _, version := range result.Pending {
+			fmt.Printf("%s would commit %q (%s)\n", bold(result.Path), version.Label,
+				version.CreatedAt.Local().Format("2006-01-02 15:04"))
+		}
+		if len(result.Versions) > 0 {
+			printSuccess(fmt.Sprintf("%s: committed %d Figma version(s)", result.Path, len(result.Versions)))
+			for _, version := range result.Versions {
+				accounting.NewAccountingManager(dgitDir).Record(accounting.Event{Operation: accounting.OpCommit, Version: version})
+			}
+			committed = true
+		}
+		if result.Err != nil {
+			printWarning(fmt.Sprintf("%s: %v", result.Path, result.Err))
+		}
+	}
+
+	if committed {
+		if _, err := search.NewSearchIndex(dgitDir).Update(); err != nil {
+			printWarning(fmt.Sprintf("failed to update search index: %v", err))
+		}
+	}
+	return nil
+}
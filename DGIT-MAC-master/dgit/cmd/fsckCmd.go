@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/verify"
+
+	"github.com/spf13/cobra"
+)
+
+// FsckCmd represents the fsck command for a full repository integrity check
+// Unlike verify it also checks refs, parents and delta chains, and finds storage nothing references
+var FsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the whole repository for missing, corrupt or orphaned data",
+	Long: `Walk every commit and check that:
+- HEAD, branch refs and parent hashes point at existing commits
+- each version's storage object exists in some cache tier
+- every object decodes and its stored checksums match the content
+- every delta chain reaches a full snapshot through intact bases
+
+Storage files no commit references, and temporary files left by interrupted
+operations, are reported as warnings. Each issue comes with a repair hint.
+With --deep every version is also restored and compared with its committed
+SHA256 hashes.
+
+Exits with status 1 when errors are found.
+
+Examples:
+  dgit fsck               # Full structural and checksum check
+  dgit fsck --deep        # ... plus a byte-for-byte restore of every version
+  dgit fsck --json        # Machine-readable report`,
+	Run: runFsck,
+}
+
+// init sets up command flags for fsck command
+func init() {
+	FsckCmd.Flags().Bool("deep", false, "Also restore every version and compare file hashes")
+	FsckCmd.Flags().Bool("json", false, "Print the report as JSON")
+}
+
+// runFsck checks the repository and prints the repair report
+func runFsck(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	deep, _ := cmd.Flags().GetBool("deep")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	var report *verify.FsckReport
+	var err error
+	withQuietStdout(func() { report, err = verify.NewVerifyManager(dgitDir).Fsck(deep) })
+	if err != nil {
+		exitWithError(fmt.Sprintf("checking repository: %v", err), "")
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Sprintf("encoding report: %v", err), "")
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Checked %d version(s)\n", report.Versions)
+		for _, issue := range report.Issues {
+			label := red("error")
+			if issue.Severity == verify.FsckWarning {
+				label = yellow("warn ")
+			}
+			subject := issue.Object
+			if issue.Version > 0 {
+				subject = fmt.Sprintf("v%d %s", issue.Version, issue.Object)
+			}
+			fmt.Printf("  %s %s: %s\n", label, subject, issue.Problem)
+			fmt.Printf("        → %s\n", issue.Repair)
+		}
+		switch {
+		case len(report.Issues) == 0:
+			printSuccess("Repository is consistent")
+		case report.Errors() == 0:
+			printWarning(fmt.Sprintf("%d warning(s), no errors", report.Warnings()))
+		default:
+			printError(fmt.Sprintf("%d error(s), %d warning(s)", report.Errors(), report.Warnings()))
+		}
+	}
+	if report.Errors() > 0 {
+		os.Exit(1)
+	}
+}
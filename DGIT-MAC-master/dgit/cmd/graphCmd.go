@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// GraphCmd represents the graph command for exporting the version graph
+// Output is plain text meant to be pasted into docs and wikis
+var GraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the version graph as Mermaid or Graphviz DOT",
+	Long: `Print the commit graph built from parent links. Imported branches are
+drawn as groups, and each version is labelled with HEAD and its approval
+state (review, approved, delivered).
+
+Examples:
+  dgit graph                          # Mermaid flowchart
+  dgit graph --format dot | dot -Tsvg > graph.svg
+  dgit graph --files -o docs/graph.md # Include committed files per version`,
+	Args: cobra.NoArgs,
+	Run:  runGraph,
+}
+
+// init sets up command flags for graph command
+func init() {
+	GraphCmd.Flags().String("format", log.GraphMermaid, "Output format: mermaid or dot")
+	GraphCmd.Flags().Bool("files", false, "Annotate each version with its committed files")
+	GraphCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+}
+
+// runGraph renders the version graph
+func runGraph(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	format, _ := cmd.Flags().GetString("format")
+	withFiles, _ := cmd.Flags().GetBool("files")
+	output, _ := cmd.Flags().GetString("output")
+
+	logManager := log.NewLogManager(dgitDir)
+	commits, err := logManager.GetCommitHistory()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading history: %v", err), "")
+	}
+	if len(commits) == 0 {
+		exitWithError("no commits yet", "Create one with 'dgit commit'")
+	}
+
+	nodes := log.BuildGraph(commits, withFiles)
+	labelGraph(dgitDir, logManager.GetHeadVersion(), commits, nodes)
+
+	text, err := log.RenderGraph(nodes, format)
+	if err != nil {
+		exitWithError(err.Error(), "Use --format mermaid or --format dot")
+	}
+	if format == log.GraphMermaid && output != "" && isMarkdown(output) {
+		text = "```mermaid\n" + text + "```\n"
+	}
+
+	if output == "" {
+		fmt.Print(text)
+		return
+	}
+	if err := os.WriteFile(output, []byte(text), 0644); err != nil {
+		exitWithError(fmt.Sprintf("writing %s: %v", output, err), "")
+	}
+	printSuccess(fmt.Sprintf("Wrote %d version(s) to %s", len(nodes), output))
+}
+
+// labelGraph marks HEAD, branch tips and every version that moved past draft
+func labelGraph(dgitDir string, head int, commits []*log.Commit, nodes []*log.GraphNode) {
+	byVersion := make(map[int]*log.Commit, len(commits))
+	for _, c := range commits {
+		byVersion[c.Version] = c
+	}
+	tips := make(map[string][]string)
+	if refs, err := log.NewLogManager(dgitDir).ListRefs(); err == nil {
+		for name, hash := range refs {
+			tips[hash] = append(tips[hash], name)
+		}
+	}
+	approvals := approval.NewApprovalManager(dgitDir)
+	for _, node := range nodes {
+		if node.Version == head {
+			node.Labels = append(node.Labels, "HEAD")
+		}
+		sort.Strings(tips[node.Hash])
+		node.Labels = append(node.Labels, tips[node.Hash]...)
+		if state, err := approvals.GetState(byVersion[node.Version]); err == nil && state.State != approval.StateDraft {
+			node.Labels = append(node.Labels, state.State)
+		}
+	}
+}
+
+// isMarkdown reports whether a path is a Markdown file, where Mermaid needs a fenced block
+func isMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/group"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// GroupCmd represents the group command for managing logical assets
+// A group names several files that make up one deliverable, e.g. a brand kit
+var GroupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage logical assets that span multiple files",
+	Long: `Define asset groups: named sets of files and folders that belong together,
+such as a brand kit made of logo.ai, palette.ase and a fonts/ folder.
+
+Status summarizes changes per group, the log shows which groups a commit
+touched, and restore accepts a group name in place of file paths.
+
+Examples:
+  dgit group                                         # List groups
+  dgit group add brand-kit logo.ai palette.ase fonts/
+  dgit group remove brand-kit palette.ase            # Drop one member
+  dgit group remove brand-kit                        # Delete the group
+  dgit restore 5 brand-kit                           # Restore the whole kit from v5`,
+	Run: runGroupList,
+}
+
+// groupAddCmd defines a group or extends it
+var groupAddCmd = &cobra.Command{
+	Use:   "add <name> <path...>",
+	Short: "Create an asset group or add members to it",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   runGroupAdd,
+}
+
+// groupRemoveCmd removes a group or some of its members
+var groupRemoveCmd = &cobra.Command{
+	Use:   "remove <name> [path...]",
+	Short: "Delete an asset group or remove members from it",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runGroupRemove,
+}
+
+// init sets up group subcommands and flags
+func init() {
+	groupAddCmd.Flags().StringP("description", "d", "", "Describe the asset group")
+
+	GroupCmd.AddCommand(groupAddCmd)
+	GroupCmd.AddCommand(groupRemoveCmd)
+}
+
+// runGroupAdd creates or extends an asset group
+func runGroupAdd(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	description, _ := cmd.Flags().GetString("description")
+
+	g, err := group.NewGroupManager(dgitDir).Define(args[0], args[1:], description)
+	if err != nil {
+		exitWithError(fmt.Sprintf("defining group: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Asset group %s has %d member(s)", g.Name, len(g.Members)))
+}
+
+// runGroupRemove deletes an asset group or some of its members
+func runGroupRemove(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	if err := group.NewGroupManager(dgitDir).Remove(args[0], args[1:]); err != nil {
+		exitWithError(fmt.Sprintf("removing from group: %v", err), "Run 'dgit group' to see defined groups")
+	}
+	if len(args) == 1 {
+		printSuccess(fmt.Sprintf("Deleted asset group %s", args[0]))
+	} else {
+		printSuccess(fmt.Sprintf("Removed %d member(s) from %s", len(args)-1, args[0]))
+	}
+}
+
+// runGroupList lists asset groups with their members and how many are in the latest commit
+func runGroupList(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	groups, err := group.NewGroupManager(dgitDir).GetGroups()
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading groups: %v", err), "")
+	}
+	if len(groups) == 0 {
+		fmt.Println("No asset groups.")
+		printInfo("Use 'dgit group add <name> <path...>' to define one")
+		return
+	}
+
+	logManager := log.NewLogManager(dgitDir)
+	var committed []string
+	if head := logManager.GetHeadVersion(); head > 0 {
+		if c, err := logManager.GetCommit(head); err == nil {
+			for path := range c.Metadata {
+				committed = append(committed, path)
+			}
+		}
+	}
+
+	for _, g := range groups {
+		fmt.Printf("%s (%d file(s) in current version)\n", bold(g.Name), len(g.Filter(committed)))
+		if g.Description != "" {
+			fmt.Printf("  %s\n", g.Description)
+		}
+		for _, member := range g.Members {
+			fmt.Printf("  %s\n", member)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+
+	"github.com/spf13/cobra"
+)
+
+// HooksCmd represents the hooks command for installing vetted hook presets
+// Studios get policy enforcement without writing shell scripts themselves
+var HooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List, install and remove hook presets",
+	Long: `Install vetted hook scripts and policies shipped with DGit. Script presets
+are written to .dgit/hooks/<hook>.d/<preset> with their parameters filled in;
+policy presets update the repository configuration.
+
+Examples:
+  dgit hooks                                          # List installed presets
+  dgit hooks presets                                  # Show available presets and parameters
+  dgit hooks install size-guard --set max_mb=200      # Reject files over 200 MB
+  dgit hooks install notify-slack --set webhook_url=https://hooks.slack.com/services/...
+  dgit hooks install require-approval --set action=export
+  dgit hooks uninstall size-guard`,
+	Args: cobra.NoArgs,
+	Run:  runHooksList,
+}
+
+// hooksPresetsCmd lists the presets shipped with DGit
+var hooksPresetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "List available hook presets and their parameters",
+	Args:  cobra.NoArgs,
+	Run:   runHooksPresets,
+}
+
+// hooksInstallCmd installs or reinstalls a preset
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install <preset> [--set name=value...]",
+	Short: "Install a hook preset",
+	Args:  cobra.ExactArgs(1),
+	Run:   runHooksInstall,
+}
+
+// hooksUninstallCmd removes an installed preset
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall <preset>",
+	Short: "Remove an installed hook preset",
+	Args:  cobra.ExactArgs(1),
+	Run:   runHooksUninstall,
+}
+
+// init sets up hooks subcommands and flags
+func init() {
+	hooksInstallCmd.Flags().StringArray("set", nil, "Preset parameter as name=value (repeatable)")
+
+	HooksCmd.AddCommand(hooksPresetsCmd)
+	HooksCmd.AddCommand(hooksInstallCmd)
+	HooksCmd.AddCommand(hooksUninstallCmd)
+}
+
+// runHooksList shows installed presets and their parameters
+func runHooksList(cmd *cobra.Command, args []string) {
+	installed, err := hooks.NewHookManager(checkDgitRepository()).GetInstalled()
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading hooks: %v", err), "")
+	}
+	if len(installed) == 0 {
+		fmt.Println("No hook presets installed.")
+		printSuggestion("dgit hooks presets")
+		return
+	}
+	for _, record := range installed {
+		where := record.Hook
+		if where == "" {
+			where = "policy"
+		}
+		fmt.Printf("  %-18s %-12s %s\n", record.Name, where, formatPresetParams(record.Params))
+	}
+}
+
+// runHooksPresets describes every available preset
+func runHooksPresets(cmd *cobra.Command, args []string) {
+	for _, preset := range hooks.Presets {
+		where := preset.Hook
+		if preset.Policy {
+			where = "policy"
+		}
+		fmt.Printf("%s (%s)\n", bold(preset.Name), where)
+		fmt.Printf("  %s\n", preset.Description)
+		for _, param := range preset.Params {
+			detail := param.Description
+			if param.Required {
+				detail += " (required)"
+			} else if param.Default != "" {
+				detail += fmt.Sprintf(" (default: %s)", param.Default)
+			}
+			fmt.Printf("    %-12s %s\n", param.Name, detail)
+		}
+		fmt.Println()
+	}
+}
+
+// runHooksInstall installs a preset with the given parameters
+func runHooksInstall(cmd *cobra.Command, args []string) {
+	manager := hooks.NewHookManager(checkDgitRepository())
+
+	values := make(map[string]string)
+	sets, _ := cmd.Flags().GetStringArray("set")
+	for _, set := range sets {
+		name, value, ok := strings.Cut(set, "=")
+		if !ok || name == "" {
+			exitWithError(fmt.Sprintf("invalid --set %q", set), "Use --set name=value")
+		}
+		values[name] = value
+	}
+
+	record, err := manager.Install(args[0], values)
+	if err != nil {
+		exitWithError(fmt.Sprintf("installing %s: %v", args[0], err), "Run 'dgit hooks presets' to see presets and parameters")
+	}
+	if len(record.Files) > 0 {
+		printSuccess(fmt.Sprintf("Installed %s as a %s hook (%s)", record.Name, record.Hook, record.Files[0]))
+	} else {
+		printSuccess(fmt.Sprintf("Installed %s: %s requires %s", record.Name, record.Params["action"], record.Params["state"]))
+	}
+	if params := formatPresetParams(record.Params); params != "" {
+		printInfo("Parameters: " + params)
+	}
+}
+
+// runHooksUninstall removes an installed preset
+func runHooksUninstall(cmd *cobra.Command, args []string) {
+	if err := hooks.NewHookManager(checkDgitRepository()).Uninstall(args[0]); err != nil {
+		exitWithError(fmt.Sprintf("uninstalling: %v", err), "Run 'dgit hooks' to see installed presets")
+	}
+	printSuccess(fmt.Sprintf("Uninstalled %s", args[0]))
+}
+
+// formatPresetParams renders set parameters as name=value pairs in name order
+func formatPresetParams(params map[string]string) string {
+	var pairs []string
+	for name, value := range params {
+		if value != "" {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/autocommit"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/ingest"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/search"
+
+	"github.com/spf13/cobra"
+)
+
+// IngestCmd represents the ingest command for versioning pipeline outputs
+// Rules map a watch folder (e.g. a render farm output) to a path in the repository
+var IngestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Manage watch-folder ingest rules for export pipelines",
+	Long: `Ingest rules copy finished files from a watch folder into the repository
+and commit them automatically. 'dgit daemon' processes the rules continuously;
+'dgit ingest run' processes them once.
+
+A file is ingested once it has been unchanged for the rule's settle time, so
+exports still being written are left alone. Message templates may use {rule},
+{count}, {files} and {date}.
+
+An auto-commit policy ('dgit ingest policy') limits how often files are
+committed: a minimum interval per file, quiet hours, a daily maximum, and
+waiting until no application has the file open. Held files stay pending and
+are committed with their latest content once the policy allows.
+
+Examples:
+  dgit ingest                                              # List rules
+  dgit ingest add farm --watch /mnt/farm/out --target renders --pattern "*.exr"
+  dgit ingest add psd --watch ~/Exports --target exports --message "Export {date}: {files}" --move
+  dgit ingest run --dry-run                                # Show what would be ingested
+  dgit ingest policy --min-interval 15m --quiet-hours 22:00-07:00 --max-per-day 50
+  dgit ingest remove farm`,
+	Args: cobra.NoArgs,
+	Run:  runIngestList,
+}
+
+// ingestAddCmd adds or replaces a rule
+var ingestAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace an ingest rule",
+	Args:  cobra.ExactArgs(1),
+	Run:   runIngestAdd,
+}
+
+// ingestRemoveCmd deletes a rule
+var ingestRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an ingest rule",
+	Args:  cobra.ExactArgs(1),
+	Run:   runIngestRemove,
+}
+
+// ingestRunCmd processes all rules once
+var ingestRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Process all ingest rules once",
+	Args:  cobra.NoArgs,
+	Run:   runIngestRun,
+}
+
+// ingestPolicyCmd shows or changes the auto-commit policy
+var ingestPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show or change the auto-commit policy",
+	Long: `Show or change the limits on automatic commits made by ingest rules,
+'dgit daemon' and the ingest webhook action. Only the flags given are changed.
+
+  --min-interval    At most one auto-commit per file per interval (0 = off)
+  --quiet-hours     Local time window without auto-commits, e.g. 22:00-07:00
+                    ("" = off)
+  --max-per-day     Auto-commits per calendar day (0 = unlimited)
+  --require-closed  Wait until no application has the file open (uses lsof;
+                    skipped where lsof is unavailable)
+
+Examples:
+  dgit ingest policy
+  dgit ingest policy --min-interval 10m --require-closed
+  dgit ingest policy --quiet-hours ""`,
+	Args: cobra.NoArgs,
+	Run:  runIngestPolicy,
+}
+
+// init sets up ingest subcommands and flags
+func init() {
+	ingestAddCmd.Flags().String("watch", "", "Folder the pipeline writes finished files to")
+	ingestAddCmd.Flags().String("target", "", "Repository folder to copy files into")
+	ingestAddCmd.Flags().StringSlice("pattern", nil, "File name patterns to ingest (default: design files)")
+	ingestAddCmd.Flags().String("message", "", "Commit message template (default: \""+ingest.DefaultMessage+"\")")
+	ingestAddCmd.Flags().Int("settle", ingest.DefaultSettleSeconds, "Seconds a file must be unchanged before it is ingested")
+	ingestAddCmd.Flags().Bool("move", false, "Remove files from the watch folder once committed")
+	ingestAddCmd.MarkFlagRequired("watch")
+	ingestRunCmd.Flags().BoolP("dry-run", "n", false, "Only show which files would be ingested")
+	ingestRunCmd.Flags().Bool("ignore-policy", false, "Commit settled files regardless of the auto-commit policy")
+	ingestPolicyCmd.Flags().Duration("min-interval", 0, "Minimum time between auto-commits of a file")
+	ingestPolicyCmd.Flags().String("quiet-hours", "", "Local HH:MM-HH:MM window without auto-commits")
+	ingestPolicyCmd.Flags().Int("max-per-day", 0, "Maximum auto-commits per day")
+	ingestPolicyCmd.Flags().Bool("require-closed", false, "Wait until no application has the file open")
+
+	IngestCmd.AddCommand(ingestAddCmd)
+	IngestCmd.AddCommand(ingestRemoveCmd)
+	IngestCmd.AddCommand(ingestRunCmd)
+	IngestCmd.AddCommand(ingestPolicyCmd)
+}
+
+// runIngestList prints the configured rules
+func runIngestList(cmd *cobra.Command, args []string) {
+	rules, err := ingest.NewIngestManager(checkDgitRepository()).GetRules()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading ingest rules: %v", err), "")
+	}
+	if len(rules) == 0 {
+		fmt.Println("No ingest rules configured.")
+		printSuggestion("dgit ingest add <name> --watch <folder> --target <repo folder>")
+		return
+	}
+	for _, rule := range rules {
+		target := rule.Target
+		if target == "" {
+			target = "."
+		}
+		fmt.Printf("  %s: %s -> %s\n", bold(rule.Name), rule.Watch, target)
+		if len(rule.Patterns) > 0 {
+			fmt.Printf("      patterns: %s\n", strings.Join(rule.Patterns, ", "))
+		}
+		if rule.Message != "" {
+			fmt.Printf("      message:  %s\n", rule.Message)
+		}
+		if rule.Move {
+			fmt.Printf("      moves files out of the watch folder\n")
+		}
+	}
+}
+
+// runIngestAdd stores a rule in the repository config
+func runIngestAdd(cmd *cobra.Command, args []string) {
+	manager := ingest.NewIngestManager(checkDgitRepository())
+
+	watch, _ := cmd.Flags().GetString("watch")
+	target, _ := cmd.Flags().GetString("target")
+	patterns, _ := cmd.Flags().GetStringSlice("pattern")
+	message, _ := cmd.Flags().GetString("message")
+	settle, _ := cmd.Flags().GetInt("settle")
+	move, _ := cmd.Flags().GetBool("move")
+
+	absWatch, err := filepath.Abs(watch)
+	if err != nil {
+		exitWithError(fmt.Sprintf("resolving watch folder: %v", err), "")
+	}
+	if rel, err := filepath.Rel(manager.RootDir, absWatch); err == nil && !strings.HasPrefix(rel, "..") {
+		exitWithError("watch folder must be outside the repository", "Point --watch at the pipeline's output folder")
+	}
+
+	rule := initializer.IngestRule{
+		Name:          args[0],
+		Watch:         absWatch,
+		Target:        filepath.ToSlash(filepath.Clean(target)),
+		Patterns:      patterns,
+		Message:       message,
+		SettleSeconds: settle,
+		Move:          move,
+	}
+	if rule.Target == "." {
+		rule.Target = ""
+	}
+	if err := manager.AddRule(rule); err != nil {
+		exitWithError(fmt.Sprintf("adding ingest rule: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Ingest rule %s: %s -> %s", rule.Name, rule.Watch, target))
+	printInfo("Run 'dgit daemon' to ingest continuously, or 'dgit ingest run' once")
+}
+
+// runIngestRemove deletes a rule
+func runIngestRemove(cmd *cobra.Command, args []string) {
+	if err := ingest.NewIngestManager(checkDgitRepository()).RemoveRule(args[0]); err != nil {
+		exitWithError(fmt.Sprintf("removing ingest rule: %v", err), "Run 'dgit ingest' to list rules")
+	}
+	printSuccess(fmt.Sprintf("Removed ingest rule %s", args[0]))
+}
+
+// runIngestRun processes every rule once
+func runIngestRun(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	ignorePolicy, _ := cmd.Flags().GetBool("ignore-policy")
+	if err := ingestOnce(checkDgitRepository(), dryRun, ignorePolicy); err != nil {
+		exitWithError(fmt.Sprintf("ingesting: %v", err), "")
+	}
+}
+
+// ingestOnce runs one pass over the ingest rules from the repository root and reports the results
+// Shared by 'dgit ingest run' and 'dgit daemon'
+func ingestOnce(dgitDir string, dryRun, ignorePolicy bool) error {
+	manager := ingest.NewIngestManager(dgitDir)
+	manager.IgnorePolicy = ignorePolicy
+	if err := os.Chdir(manager.RootDir); err != nil {
+		return fmt.Errorf("failed to enter repository root: %w", err)
+	}
+
+	results, err := manager.Process(dryRun)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			printWarning(fmt.Sprintf("%s: %v", result.Rule, result.Err))
+		case dryRun && len(result.Files) > 0:
+			fmt.Printf("%s would ingest %d file(s):\n", bold(result.Rule), len(result.Files))
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+		case result.Version > 0:
+			printSuccess(fmt.Sprintf("%s: committed %d file(s) as v%d", result.Rule, len(result.Files), result.Version))
+			accounting.NewAccountingManager(dgitDir).Record(accounting.Event{Operation: accounting.OpCommit, Version: result.Version})
+			generateCommitPreviews(dgitDir, result.Version, rootRelativeFiles(dgitDir, result.Files))
+			committed = true
+		}
+		if result.Pending > 0 {
+			printInfo(fmt.Sprintf("%s: %d file(s) still being written", result.Rule, result.Pending))
+		}
+		for _, held := range result.Held {
+			printInfo(fmt.Sprintf("%s: held by auto-commit policy: %s", result.Rule, held))
+		}
+	}
+
+	if committed {
+		if _, err := search.NewSearchIndex(dgitDir).Update(); err != nil {
+			printWarning(fmt.Sprintf("failed to update search index: %v", err))
+		}
+	}
+	return nil
+}
+
+// runIngestPolicy shows the auto-commit policy, changing the settings given as flags
+func runIngestPolicy(cmd *cobra.Command, args []string) {
+	manager := autocommit.NewPolicyManager(checkDgitRepository())
+	policy, err := manager.GetConfig()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading auto-commit policy: %v", err), "")
+	}
+
+	flags := cmd.Flags()
+	if flags.NFlag() > 0 {
+		if flags.Changed("min-interval") {
+			interval, _ := flags.GetDuration("min-interval")
+			policy.MinIntervalSeconds = int(interval / time.Second)
+		}
+		if flags.Changed("quiet-hours") {
+			policy.QuietHours, _ = flags.GetString("quiet-hours")
+		}
+		if flags.Changed("max-per-day") {
+			policy.MaxPerDay, _ = flags.GetInt("max-per-day")
+		}
+		if flags.Changed("require-closed") {
+			policy.RequireClosed, _ = flags.GetBool("require-closed")
+		}
+		if err := manager.SetConfig(policy); err != nil {
+			exitWithError(fmt.Sprintf("updating auto-commit policy: %v", err), "")
+		}
+		printSuccess("Auto-commit policy updated")
+	}
+
+	off := func(set bool, value string) string {
+		if !set {
+			return "off"
+		}
+		return value
+	}
+	fmt.Printf("  minimum interval per file: %s\n", off(policy.MinIntervalSeconds > 0, (time.Duration(policy.MinIntervalSeconds)*time.Second).String()))
+	fmt.Printf("  quiet hours:               %s\n", off(policy.QuietHours != "", policy.QuietHours))
+	fmt.Printf("  maximum per day:           %s\n", off(policy.MaxPerDay > 0, fmt.Sprintf("%d", policy.MaxPerDay)))
+	fmt.Printf("  wait for file to close:    %s\n", off(policy.RequireClosed, "on"))
+	if count, err := manager.CountToday(time.Now()); err == nil && count > 0 {
+		fmt.Printf("  auto-commits today:        %d\n", count)
+	}
+}
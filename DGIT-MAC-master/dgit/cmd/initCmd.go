@@ -5,7 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	
-	initializer "dgit/internal/init"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
 	"github.com/spf13/cobra"
 )
 
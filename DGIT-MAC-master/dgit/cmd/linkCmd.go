@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+
+	"github.com/spf13/cobra"
+)
+
+// LinkCmd represents the link command for managing linked library assets
+// Unique to DGit - records that a file is a specific version of a file in a shared library
+var LinkCmd = &cobra.Command{
+	Use:   "link",
+	Short: "Manage assets linked from library repositories",
+	Long: `Link files from a shared asset library repository into this repository.
+
+A linked asset records that a working tree file is version N of a file in
+a library repository. Every commit stores its links, so restoring a version
+fetches the exact library file it used.
+
+Examples:
+  dgit link                                          # List linked assets
+  dgit link add ../brand-kit logo.ai                 # Link latest logo.ai from brand-kit
+  dgit link add ../brand-kit logo.ai -v 12 --as assets/logo.ai
+  dgit link fetch                                    # Re-fetch all linked assets
+  dgit link remove assets/logo.ai                    # Stop tracking the link`,
+	Run: runLinkList,
+}
+
+// linkAddCmd links a library file into the working tree
+var linkAddCmd = &cobra.Command{
+	Use:   "add <library> <file>",
+	Short: "Link a file from a library repository",
+	Args:  cobra.ExactArgs(2),
+	Run:   runLinkAdd,
+}
+
+// linkFetchCmd re-fetches linked assets from their libraries
+var linkFetchCmd = &cobra.Command{
+	Use:   "fetch [path...]",
+	Short: "Fetch linked assets from their library repositories",
+	Run:   runLinkFetch,
+}
+
+// linkRemoveCmd removes a link without deleting the file
+var linkRemoveCmd = &cobra.Command{
+	Use:   "remove <path>",
+	Short: "Stop tracking a linked asset",
+	Args:  cobra.ExactArgs(1),
+	Run:   runLinkRemove,
+}
+
+// init sets up link subcommands and flags
+func init() {
+	linkAddCmd.Flags().StringP("version", "v", "", "Library version to link (default: latest containing the file)")
+	linkAddCmd.Flags().String("as", "", "Local path for the linked file (default: same path as in the library)")
+
+	LinkCmd.AddCommand(linkAddCmd)
+	LinkCmd.AddCommand(linkFetchCmd)
+	LinkCmd.AddCommand(linkRemoveCmd)
+}
+
+// runLinkAdd registers and fetches a linked asset
+func runLinkAdd(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := linked.NewLinkManager(dgitDir)
+
+	version := 0
+	if versionFlag, _ := cmd.Flags().GetString("version"); versionFlag != "" {
+		v, err := parseVersionArg(versionFlag)
+		if err != nil {
+			exitWithError(err.Error(), "")
+		}
+		version = v
+	}
+	localPath, _ := cmd.Flags().GetString("as")
+
+	link, err := manager.AddLink(args[0], args[1], version, localPath)
+	if err != nil {
+		exitWithError(fmt.Sprintf("linking asset: %v", err), "")
+	}
+
+	printSuccess(fmt.Sprintf("Linked %s → %s@v%d (%s)", link.Path, link.SourcePath, link.Version, link.Library))
+	printInfo("The link will be recorded with your next commit")
+}
+
+// runLinkFetch fetches linked assets into the working tree
+func runLinkFetch(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	failures, err := linked.NewLinkManager(dgitDir).FetchAll(args)
+	if err != nil {
+		exitWithError(fmt.Sprintf("fetching linked assets: %v", err), "")
+	}
+	reportLinkFailures(failures)
+}
+
+// runLinkRemove removes a linked asset from the registry
+func runLinkRemove(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	if err := linked.NewLinkManager(dgitDir).RemoveLink(args[0]); err != nil {
+		exitWithError(fmt.Sprintf("removing link: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Removed link %s", args[0]))
+}
+
+// runLinkList lists linked assets and available library updates
+func runLinkList(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := linked.NewLinkManager(dgitDir)
+
+	links, err := manager.GetLinks()
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading linked assets: %v", err), "")
+	}
+	if len(links) == 0 {
+		fmt.Println("No linked assets.")
+		return
+	}
+
+	updates, _ := manager.CheckUpdates()
+	printLinkedAssets(links, updates)
+}
+
+// printLinkedAssets displays links with a warning for those whose library has moved on
+// Shared by 'dgit link' and 'dgit status'
+func printLinkedAssets(links []*linked.Link, updates []*linked.LinkUpdate) {
+	latest := make(map[string]int)
+	for _, update := range updates {
+		latest[update.Link.Path] = update.LatestVersion
+	}
+
+	for _, link := range links {
+		fmt.Printf("  %s → %s@v%d", link.Path, link.SourcePath, link.Version)
+		if v, ok := latest[link.Path]; ok {
+			fmt.Printf(" %s", yellow(fmt.Sprintf("(newer approved v%d available)", v)))
+		}
+		fmt.Println()
+	}
+}
+
+// reportLinkFailures prints fetch failures and exits non-zero if any occurred
+func reportLinkFailures(failures map[string]error) {
+	if len(failures) == 0 {
+		printSuccess("Linked assets up to date")
+		return
+	}
+	for path, err := range failures {
+		printError(fmt.Sprintf("%s: %v", path, err))
+	}
+	os.Exit(1)
+}
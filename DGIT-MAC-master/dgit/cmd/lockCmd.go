@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/lock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+// LockCmd represents the lock command for claiming binary design files
+// Design files cannot be merged, so whoever edits one locks it first
+var LockCmd = &cobra.Command{
+	Use:   "lock [files...]",
+	Short: "Lock design files so nobody else commits them",
+	Long: `Lock files you are about to edit. Design files cannot be merged, so a lock
+tells the team a file is taken: 'dgit add' warns and 'dgit commit' refuses
+files locked by someone else until the lock is released.
+
+When the repository has a remote the lock is taken there, so it is shared
+by everyone syncing with that server (origin, or the first remote, unless
+--remote is given); otherwise it is recorded in this repository only.
+Locks are advisory: they stop commits, not edits.
+
+Examples:
+  dgit lock                       # List locks
+  dgit lock hero.psd              # Lock a file
+  dgit unlock hero.psd            # Release it
+  dgit unlock hero.psd --force    # Break someone else's lock`,
+	Run: runLock,
+}
+
+// UnlockCmd releases locks
+var UnlockCmd = &cobra.Command{
+	Use:   "unlock <files...>",
+	Short: "Release file locks",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runUnlock,
+}
+
+// init sets up command flags for lock and unlock commands
+func init() {
+	LockCmd.Flags().String("remote", "", "Remote that holds the locks")
+	UnlockCmd.Flags().String("remote", "", "Remote that holds the locks")
+	UnlockCmd.Flags().BoolP("force", "f", false, "Release locks held by someone else")
+}
+
+// runLock locks files or lists locks
+func runLock(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := lock.NewLockManager(dgitDir)
+	name := lockRemote(cmd, dgitDir)
+	owner, email, _ := commit.NewCommitManager(dgitDir).Identity()
+
+	if len(args) == 0 {
+		refreshLocks(manager, name)
+		printLocks(manager, owner, email)
+		return
+	}
+
+	failed := false
+	for _, path := range args {
+		rel, err := manager.RelativePath(path)
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			printError(fmt.Sprintf("locking %s: %v", path, err))
+			failed = true
+			continue
+		}
+
+		request := &lock.Lock{Path: rel, Owner: owner, Email: email}
+		var acquired *lock.Lock
+		if name != "" {
+			acquired, err = remote.NewRemoteManager(dgitDir).Lock(name, request)
+		} else {
+			acquired, err = manager.Acquire(request)
+		}
+		if err != nil {
+			printError(fmt.Sprintf("locking %s: %v", rel, err))
+			failed = true
+			continue
+		}
+		printSuccess(fmt.Sprintf("Locked %s", acquired.Path))
+	}
+	refreshLocks(manager, name)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runUnlock releases locks
+func runUnlock(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := lock.NewLockManager(dgitDir)
+	name := lockRemote(cmd, dgitDir)
+	owner, email, _ := commit.NewCommitManager(dgitDir).Identity()
+	force, _ := cmd.Flags().GetBool("force")
+
+	failed := false
+	for _, path := range args {
+		rel, err := manager.RelativePath(path)
+		if err != nil {
+			printError(fmt.Sprintf("unlocking %s: %v", path, err))
+			failed = true
+			continue
+		}
+
+		request := &lock.UnlockRequest{Path: rel, Owner: owner, Email: email, Force: force}
+		var released *lock.Lock
+		if name != "" {
+			released, err = remote.NewRemoteManager(dgitDir).Unlock(name, request)
+		} else {
+			released, err = manager.Release(request)
+		}
+		if err != nil {
+			printError(fmt.Sprintf("unlocking %s: %v", rel, err))
+			failed = true
+			continue
+		}
+		if released.HeldBy(owner, email) {
+			printSuccess(fmt.Sprintf("Unlocked %s", released.Path))
+		} else {
+			printWarning(fmt.Sprintf("Broke the lock %s held on %s", released.Owner, released.Path))
+		}
+	}
+	refreshLocks(manager, name)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// lockRemote returns the remote that holds locks: --remote, origin, or the first remote; "" when there are none
+func lockRemote(cmd *cobra.Command, dgitDir string) string {
+	if name, _ := cmd.Flags().GetString("remote"); name != "" {
+		return name
+	}
+	remotes, err := remote.NewRemoteManager(dgitDir).List()
+	if err != nil || len(remotes) == 0 {
+		return ""
+	}
+	for _, r := range remotes {
+		if r.Name == remote.DefaultRemote {
+			return r.Name
+		}
+	}
+	return remotes[0].Name
+}
+
+// refreshLocks mirrors the remote's locks into the local registry that add and commit check
+func refreshLocks(manager *lock.LockManager, name string) {
+	if name == "" {
+		return
+	}
+	locks, err := remote.NewRemoteManager(manager.DgitDir).Locks(name)
+	if err != nil {
+		printWarning(fmt.Sprintf("Could not refresh locks from %s, showing the last known ones: %v", name, err))
+		return
+	}
+	if err := manager.Replace(locks); err != nil {
+		printWarning(fmt.Sprintf("Failed to save locks: %v", err))
+	}
+}
+
+// printLocks lists locks, marking the ones held by the current identity
+func printLocks(manager *lock.LockManager, owner, email string) {
+	locks, err := manager.GetLocks()
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading locks: %v", err), "")
+	}
+	if len(locks) == 0 {
+		fmt.Println("No locked files.")
+		return
+	}
+	for _, l := range locks {
+		mine := ""
+		if l.HeldBy(owner, email) {
+			mine = green(" (you)")
+		}
+		fmt.Printf("  %s  %s%s  since %s\n", l.Path, l.Owner, mine, l.LockedAt.Format("2006-01-02 15:04"))
+	}
+}
@@ -3,8 +3,16 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
-	"dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/group"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
 	
 	"github.com/spf13/cobra"
 )
@@ -19,10 +27,24 @@ var LogCmd = &cobra.Command{
 - Author and timestamp information
 - File counts and metadata summaries
 
+Only commits reachable from the current branch are shown; use --branch
+to read another branch's history or --all for every commit.
+
 Examples:
-  dgit log                    # Show all commits
+  dgit log                    # Show the current branch's history
+  dgit log --branch feature   # Show the history of another branch
+  dgit log --all              # Show every commit on every branch
   dgit log --oneline          # Show compact format
-  dgit log -n 5               # Show last 5 commits`,
+  dgit log -n 5               # Show last 5 commits
+  dgit log -n 5 --skip 5      # Show the 5 commits before those
+  dgit log --stat             # Show per-file size changes to spot bloat
+  dgit log --state approved   # Show only approved (or delivered) commits
+  dgit log --sessions         # Collapse commits into working sessions
+  dgit log --session 20261013-1402  # Expand one session to full detail
+  dgit log --audit            # Show contrast audit results and regressions
+  dgit log --follow hero.psd  # Versions where hero.psd changed, with layer and size changes
+  dgit log --author mina --since "2 weeks ago"
+  dgit log --since 2026-09-01 --until 2026-09-30 --grep "client feedback"`,
 	Run: runLog,
 }
 
@@ -31,6 +53,19 @@ func init() {
 	// Add flags for different log display options
 	LogCmd.Flags().BoolP("oneline", "o", false, "Show commits in compact one-line format")
 	LogCmd.Flags().IntP("number", "n", 0, "Limit the number of commits to show")
+	LogCmd.Flags().Int("skip", 0, "Skip this many commits before showing any")
+	LogCmd.Flags().Bool("stat", false, "Show each file's size change versus its previous version")
+	LogCmd.Flags().String("state", "", "Only show commits that reached this approval state (draft, review, approved, delivered)")
+	LogCmd.Flags().Bool("sessions", false, "Show one line per working session instead of per commit")
+	LogCmd.Flags().String("session", "", "Only show the commits of this session (ID or prefix from --sessions)")
+	LogCmd.Flags().Bool("audit", false, "Show contrast audit results and the versions that introduced failures")
+	LogCmd.Flags().String("follow", "", "Show the history of one file, following renames")
+	LogCmd.Flags().String("author", "", "Only show commits whose author name contains this text")
+	LogCmd.Flags().String("since", "", "Only show commits made on or after this date (2026-09-01, \"3 days ago\", 2w)")
+	LogCmd.Flags().String("until", "", "Only show commits made up to this date; a plain date includes that whole day")
+	LogCmd.Flags().String("grep", "", "Only show commits whose message contains this text")
+	LogCmd.Flags().String("branch", "", "Show the history of this branch instead of the current one")
+	LogCmd.Flags().Bool("all", false, "Show commits from every branch")
 }
 
 // runLog executes the log command functionality
@@ -40,8 +75,45 @@ func runLog(cmd *cobra.Command, args []string) {
 	dgitDir := checkDgitRepository()
 	logManager := log.NewLogManager(dgitDir)
 	
-	// Load commit history from repository
-	commits, err := logManager.GetCommitHistory()
+	// Parse command line flags
+	oneline, _ := cmd.Flags().GetBool("oneline")
+	number, _ := cmd.Flags().GetInt("number")
+	skip, _ := cmd.Flags().GetInt("skip")
+	showStat, _ := cmd.Flags().GetBool("stat")
+	stateFilter, _ := cmd.Flags().GetString("state")
+	if stateFilter != "" && !approval.IsValidState(stateFilter) {
+		printError(fmt.Sprintf("unknown state %q", stateFilter))
+		os.Exit(1)
+	}
+	if sessions, _ := cmd.Flags().GetBool("sessions"); sessions {
+		printSessions(dgitDir, logManager, skip, number)
+		return
+	}
+	if audit, _ := cmd.Flags().GetBool("audit"); audit {
+		printAuditHistory(logManager, skip, number)
+		return
+	}
+	if follow, _ := cmd.Flags().GetString("follow"); follow != "" {
+		printFileHistory(logManager, follow, skip, number)
+		return
+	}
+	filter := logFilter(cmd)
+	filtered := filter != log.CommitFilter{}
+	branchName, tip := logLineage(cmd, logManager)
+
+	// Load only the page of history being shown
+	approvalManager := approval.NewApprovalManager(dgitDir)
+	states := make(map[string]*approval.CommitState)
+	var commits []*log.Commit
+	var err error
+	if sessionID, _ := cmd.Flags().GetString("session"); sessionID != "" {
+		commits, err = loadSessionCommits(dgitDir, logManager, approvalManager, states, sessionID)
+		if err != nil {
+			exitWithError(err.Error(), "Run 'dgit log --sessions' to list sessions")
+		}
+	} else {
+		commits, err = loadLogPage(logManager, approvalManager, states, tip, filter, stateFilter, skip, number)
+	}
 	if err != nil {
 		printError(fmt.Sprintf("loading commit history: %v", err))
 		os.Exit(1)
@@ -49,33 +121,69 @@ func runLog(cmd *cobra.Command, args []string) {
 
 	// Handle case where no commits exist yet
 	if len(commits) == 0 {
-		fmt.Println("No commits yet.")
-		printInfo("Use 'dgit add' and 'dgit commit' to create your first commit.")
+		switch {
+		case stateFilter != "":
+			fmt.Printf("No commits in state %s.\n", stateFilter)
+		case filtered:
+			fmt.Println("No commits match the filters.")
+		case skip > 0:
+			fmt.Println("No more commits.")
+		default:
+			fmt.Println("No commits yet.")
+			printInfo("Use 'dgit add' and 'dgit commit' to create your first commit.")
+		}
 		return
 	}
 
-	// Parse command line flags
-	oneline, _ := cmd.Flags().GetBool("oneline")
-	number, _ := cmd.Flags().GetInt("number")
+	// Asset groups are shown by name in the detailed format
+	groups, _ := group.NewGroupManager(dgitDir).GetGroups()
 
-	// Limit number of commits to display if specified
-	if number > 0 && number < len(commits) {
-		commits = commits[:number]
+	// History is ordered by version; dates that contradict it point at a wrong clock
+	skewed := make(map[int]bool)
+	for _, anomaly := range log.FindTimestampAnomalies(commits, time.Now()) {
+		skewed[anomaly.Version] = true
 	}
 
 	// Display header
-	fmt.Printf("Commit History (%d commits)\n\n", len(commits))
+	if branchName != "" {
+		fmt.Printf("Commit History on %s (%d commits)\n\n", branchName, len(commits))
+	} else {
+		fmt.Printf("Commit History (%d commits)\n\n", len(commits))
+	}
 
 	// Display each commit with appropriate formatting
 	for i, c := range commits {
 		if oneline {
 			// Compact one-line format
-			fmt.Printf("%s (v%d) %s\n", c.Hash[:8], c.Version, c.Message)
+			stateTag := ""
+			if state, ok := states[c.Hash]; ok && state.State != approval.StateDraft {
+				stateTag = fmt.Sprintf(" [%s]", state.State)
+			}
+			if c.Branch != "" {
+				stateTag = fmt.Sprintf(" (%s)%s", c.Branch, stateTag)
+			}
+			fmt.Printf("%s (v%d)%s %s\n", c.Hash[:8], c.Version, stateTag, c.Message)
+			if showStat {
+				printFileStats(logManager, c)
+			}
 		} else {
 			// Full detailed format
 			fmt.Printf("commit %s (v%d)\n", c.Hash[:12], c.Version)
-			fmt.Printf("Author: %s\n", c.Author)
-			fmt.Printf("Date: %s\n", c.Timestamp.Format("Mon Jan 2 15:04:05 2006"))
+			if c.Branch != "" {
+				fmt.Printf("Branch: %s\n", c.Branch)
+			}
+			if c.ImportedFrom != nil {
+				fmt.Printf("Imported: v%d of %s\n", c.ImportedFrom.Version, c.ImportedFrom.Repository)
+			}
+			fmt.Printf("Author: %s\n", formatCommitAuthor(c.Author, c.Email, c.AuthorSource))
+			if skewed[c.Version] {
+				fmt.Printf("Date: %s %s\n", c.Timestamp.Format("Mon Jan 2 15:04:05 2006"), yellow("(clock skew? see 'dgit verify')"))
+			} else {
+				fmt.Printf("Date: %s\n", c.Timestamp.Format("Mon Jan 2 15:04:05 2006"))
+			}
+			if state, ok := states[c.Hash]; ok && state.Note != nil {
+				fmt.Printf("State: %s (by %s)\n", state.State, state.Note.By)
+			}
 			fmt.Printf("\n    %s\n", c.Message)
 			
 			// Show design file information if available
@@ -85,6 +193,14 @@ func runLog(cmd *cobra.Command, args []string) {
 					fmt.Printf(" (snapshot: %s)", c.SnapshotZip)
 				}
 				fmt.Println()
+				for _, seq := range c.Sequences {
+					fmt.Printf("    Sequence: %s (%d frames, %s)\n", seq.Pattern, seq.Frames,
+						fmt.Sprintf("%0*d-%0*d", seq.Padding, seq.First, seq.Padding, seq.Last))
+				}
+				for _, to := range sortedKeys(c.Renames) {
+					fmt.Printf("    Renamed: %s -> %s\n", c.Renames[to], to)
+				}
+				printCommitGroups(groups, c)
 
 				// Generate and display metadata insights
 				summary := logManager.GenerateCommitSummary(c)
@@ -92,6 +208,10 @@ func runLog(cmd *cobra.Command, args []string) {
 					fmt.Printf("    %s\n", summary)
 				}
 			}
+			if showStat {
+				fmt.Println()
+				printFileStats(logManager, c)
+			}
 			
 			// Add separator between commits (except for last one)
 			if i < len(commits)-1 {
@@ -102,4 +222,429 @@ func runLog(cmd *cobra.Command, args []string) {
 
 	// Display summary
 	fmt.Printf("\nTotal: %d commits in history\n", len(commits))
-}
\ No newline at end of file
+	if number > 0 && len(commits) == number {
+		printInfo(fmt.Sprintf("Use --skip %d to see older commits", skip+number))
+	}
+}
+
+// logFilter builds the history filter from --author, --since, --until and --grep
+func logFilter(cmd *cobra.Command) log.CommitFilter {
+	var filter log.CommitFilter
+	filter.AuthorMatch, _ = cmd.Flags().GetString("author")
+	filter.Message, _ = cmd.Flags().GetString("grep")
+	now := time.Now()
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, _, err := log.ParseDate(since, now)
+		if err != nil {
+			exitWithError(fmt.Sprintf("--since: %v", err), "")
+		}
+		filter.Since = t
+	}
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		t, wholeDay, err := log.ParseDate(until, now)
+		if err != nil {
+			exitWithError(fmt.Sprintf("--until: %v", err), "")
+		}
+		if wholeDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.Until = t
+	}
+	return filter
+}
+
+// logLineage resolves --branch and --all to the branch shown and the commit its history is walked from
+// The tip is empty when every commit is listed: with --all, or when the repository has a single branch
+func logLineage(cmd *cobra.Command, logManager *log.LogManager) (string, string) {
+	branchName, _ := cmd.Flags().GetString("branch")
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		if branchName != "" {
+			exitWithError("--branch and --all cannot be combined", "")
+		}
+		return "", ""
+	}
+	if branchName == "" {
+		branchName = logManager.CurrentBranch()
+		if refs, err := logManager.ListRefs(); err == nil && len(refs) <= 1 {
+			return branchName, ""
+		}
+		return branchName, logManager.HeadHash()
+	}
+	tip, err := logManager.ReadRef(branchName)
+	if err != nil {
+		exitWithError(err.Error(), "Run 'dgit branch' to list branches")
+	}
+	return branchName, tip
+}
+
+// printCommitGroups lists the asset groups that have files in a commit
+func printCommitGroups(groups []*group.Group, c *log.Commit) {
+	if len(groups) == 0 {
+		return
+	}
+	var paths []string
+	for path := range c.Metadata {
+		paths = append(paths, path)
+	}
+	for _, g := range groups {
+		if members := g.Filter(paths); len(members) > 0 {
+			fmt.Printf("    Asset: %s (%d file(s))\n", g.Name, len(members))
+		}
+	}
+}
+
+// printFileStats prints each file of a commit with its size change versus the previous version
+func printFileStats(logManager *log.LogManager, c *log.Commit) {
+	stats, err := logManager.GetFileStats(c)
+	if err != nil {
+		printWarning(fmt.Sprintf("computing file sizes for v%d: %v", c.Version, err))
+		return
+	}
+
+	width := 0
+	for _, stat := range stats {
+		if len(stat.Path) > width {
+			width = len(stat.Path)
+		}
+	}
+
+	var total int64
+	for _, stat := range stats {
+		total += stat.Delta()
+		change := formatSizeDelta(stat.Delta())
+		if stat.PreviousVersion == 0 {
+			change = green("new")
+		}
+		fmt.Printf("    %-*s  %10s  %s\n", width, stat.Path, util.FormatBytes(stat.Size), change)
+	}
+	if len(stats) > 1 {
+		fmt.Printf("    %d files changed, %s\n", len(stats), formatSizeDelta(total))
+	}
+}
+
+// formatSizeDelta formats a size change as "+14.2 MB" (growth in red) or "-3.1 MB" (savings in green)
+func formatSizeDelta(delta int64) string {
+	switch {
+	case delta > 0:
+		return red("+" + util.FormatBytes(delta))
+	case delta < 0:
+		return green("-" + util.FormatBytes(delta))
+	}
+	return "±0 B"
+}
+
+// loadLogPage reads the commits to display, newest version first, recording approval states as it goes
+// Without a state filter or branch tip the storage layer applies skip and limit, so only shown commits are read;
+// a branch is walked parent by parent from its tip and the walk stops once the page is full
+func loadLogPage(logManager *log.LogManager, approvalManager *approval.ApprovalManager, states map[string]*approval.CommitState,
+	tip string, filter log.CommitFilter, stateFilter string, skip, limit int) ([]*log.Commit, error) {
+	if stateFilter == "" && tip == "" {
+		commits, err := logManager.GetCommitPage(filter, skip, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			if state, err := approvalManager.GetState(c); err == nil {
+				states[c.Hash] = state
+			}
+		}
+		return commits, nil
+	}
+
+	// Approval state and branch lineage live outside the store's filter, so stream history until enough commits match
+	var matched []*log.Commit
+	collect := func(c *log.Commit) bool {
+		if !filter.Matches(c) {
+			return true
+		}
+		state, err := approvalManager.GetState(c)
+		if stateFilter != "" && (err != nil || !approval.AtLeast(state.State, stateFilter)) {
+			return true
+		}
+		if skip > 0 {
+			skip--
+			return true
+		}
+		if err == nil {
+			states[c.Hash] = state
+		}
+		matched = append(matched, c)
+		return limit <= 0 || len(matched) < limit
+	}
+	if tip != "" {
+		return matched, logManager.WalkLineage(tip, collect)
+	}
+
+	it, err := logManager.Iterate(filter)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	for it.Next() {
+		if !collect(it.Commit()) {
+			break
+		}
+	}
+	return matched, it.Err()
+}
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatCommitAuthor shows the author with email, marking identities that did not come from the config
+func formatCommitAuthor(author, email, source string) string {
+	if email != "" {
+		author = fmt.Sprintf("%s <%s>", author, email)
+	}
+	switch source {
+	case "flag":
+		author += " (via --author)"
+	case "env":
+		author += " (via DGIT_AUTHOR)"
+	}
+	return author
+}
+
+// sessionGap returns the configured idle gap that separates working sessions
+func sessionGap(dgitDir string) time.Duration {
+	if config, err := initializer.GetRepositoryConfig(dgitDir); err == nil && config.Commit.SessionGapMinutes > 0 {
+		return time.Duration(config.Commit.SessionGapMinutes) * time.Minute
+	}
+	return log.DefaultSessionGap
+}
+
+// printSessions shows history collapsed into working sessions, newest first
+func printSessions(dgitDir string, logManager *log.LogManager, skip, limit int) {
+	commits, err := logManager.GetCommitHistory()
+	if err != nil {
+		printError(fmt.Sprintf("loading commit history: %v", err))
+		os.Exit(1)
+	}
+	if len(commits) == 0 {
+		fmt.Println("No commits yet.")
+		return
+	}
+
+	sessions := log.GroupSessions(commits, sessionGap(dgitDir))
+	total := len(sessions)
+	if skip >= len(sessions) {
+		fmt.Println("No more sessions.")
+		return
+	}
+	sessions = sessions[skip:]
+	if limit > 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+
+	fmt.Printf("Working Sessions (%d of %d)\n\n", len(sessions), total)
+	for _, session := range sessions {
+		first, last := session.Commits[0], session.Commits[len(session.Commits)-1]
+		versions := fmt.Sprintf("v%d", first.Version)
+		if last.Version != first.Version {
+			versions = fmt.Sprintf("v%d–v%d", first.Version, last.Version)
+		}
+		line := fmt.Sprintf("%s – %d commit(s) (%s)", bold(session.Label()), len(session.Commits), versions)
+		if main := session.MainFile(); main != nil {
+			line += fmt.Sprintf(", %s %s", main.Path, formatSizeRange(main.FirstSize, main.LastSize))
+			if others := len(session.Files) - 1; others > 0 {
+				line += fmt.Sprintf(" +%d other file(s)", others)
+			}
+		}
+		fmt.Println(line)
+		fmt.Printf("    %s–%s · %s · session %s\n", session.Start.Format("15:04"), session.End.Format("15:04"),
+			session.Author, strings.TrimPrefix(session.ID, "~"))
+	}
+	fmt.Println()
+	printInfo("Use 'dgit log --session <id>' to expand a session")
+	if limit > 0 && skip+len(sessions) < total {
+		printInfo(fmt.Sprintf("Use --skip %d to see older sessions", skip+limit))
+	}
+}
+
+// printAuditHistory lists audited versions newest first, marking where each file started or stopped failing
+func printAuditHistory(logManager *log.LogManager, skip, limit int) {
+	commits, err := logManager.GetCommitHistory()
+	if err != nil {
+		printError(fmt.Sprintf("loading commit history: %v", err))
+		os.Exit(1)
+	}
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Version < commits[j].Version })
+
+	// Walk oldest to newest so each finding can be compared with the file's previous audit
+	type auditEntry struct {
+		commit *log.Commit
+		lines  []string
+	}
+	var entries []auditEntry
+	failing := make(map[string]bool)
+	for _, c := range commits {
+		if c.Audit == nil {
+			continue
+		}
+		entry := auditEntry{commit: c}
+		for _, finding := range c.Audit.Files {
+			if finding.Error != "" {
+				entry.lines = append(entry.lines, fmt.Sprintf("%s  %s", finding.Source, yellow(finding.Error)))
+				continue
+			}
+			was, seen := failing[finding.Source]
+			failing[finding.Source] = finding.Failed()
+			switch {
+			case finding.Failed() && !was:
+				note := "introduced failing contrast"
+				if !seen {
+					note = "fails contrast"
+				}
+				entry.lines = append(entry.lines, fmt.Sprintf("%s  %s %s, worst %.2f:1 (%s on %s)", finding.Source,
+					red("✗"), note, finding.WorstRatio, finding.Foreground, finding.Background))
+			case finding.Failed():
+				entry.lines = append(entry.lines, fmt.Sprintf("%s  %s still failing, worst %.2f:1", finding.Source, red("✗"), finding.WorstRatio))
+			case was:
+				entry.lines = append(entry.lines, fmt.Sprintf("%s  %s fixed, lowest %.2f:1", finding.Source, green("✓"), finding.WorstRatio))
+			default:
+				entry.lines = append(entry.lines, fmt.Sprintf("%s  %s pass", finding.Source, green("✓")))
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No audited commits. Enable the contrast audit with 'dgit audit enable'.")
+		return
+	}
+
+	total := len(entries)
+	if skip >= total {
+		fmt.Println("No more audited commits.")
+		return
+	}
+	fmt.Printf("Contrast Audit (%d audited commit(s))\n\n", total)
+	shown := 0
+	for i := total - 1 - skip; i >= 0 && (limit <= 0 || shown < limit); i-- {
+		c := entries[i].commit
+		fmt.Printf("%s  %s  (min %.1f:1)\n", bold(fmt.Sprintf("v%d", c.Version)), c.Message, c.Audit.MinContrast)
+		for _, line := range entries[i].lines {
+			fmt.Printf("    %s\n", line)
+		}
+		shown++
+	}
+	if limit > 0 && skip+shown < total {
+		fmt.Println()
+		printInfo(fmt.Sprintf("Use --skip %d to see older audits", skip+limit))
+	}
+}
+
+// formatSizeRange shows how a file's size moved over a session, e.g. "1.1→1.9 GB"
+func formatSizeRange(first, last int64) string {
+	from, to := util.FormatBytes(first), util.FormatBytes(last)
+	if from == to {
+		return to
+	}
+	if fromValue, fromUnit, ok := strings.Cut(from, " "); ok && strings.HasSuffix(to, " "+fromUnit) {
+		return fromValue + "→" + to
+	}
+	return from + "→" + to
+}
+
+// loadSessionCommits returns the commits of one session, newest version first
+func loadSessionCommits(dgitDir string, logManager *log.LogManager, approvalManager *approval.ApprovalManager,
+	states map[string]*approval.CommitState, sessionID string) ([]*log.Commit, error) {
+	commits, err := logManager.GetCommitHistory()
+	if err != nil {
+		return nil, err
+	}
+	session, err := log.FindSession(log.GroupSessions(commits, sessionGap(dgitDir)), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var selected []*log.Commit
+	for i := len(session.Commits) - 1; i >= 0; i-- {
+		c := session.Commits[i]
+		if state, err := approvalManager.GetState(c); err == nil {
+			states[c.Hash] = state
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// printFileHistory lists the versions where one file changed, newest first, with its design changes
+func printFileHistory(logManager *log.LogManager, file string, skip, limit int) {
+	// Paths are taken relative to the current directory, like other file arguments
+	path := file
+	if abs, err := filepath.Abs(file); err == nil {
+		path = abs
+	}
+	history, err := logManager.FileHistory(path)
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading history of %s: %v", file, err), "")
+	}
+	if len(history) == 0 {
+		fmt.Printf("No history for %s on this branch.\n", file)
+		printSuggestion("Use a path relative to the current folder, e.g. 'dgit log --follow assets/hero.psd'")
+		return
+	}
+
+	total := len(history)
+	newestFirst := make([]*log.FileVersion, 0, total)
+	for i := total - 1; i >= 0; i-- {
+		newestFirst = append(newestFirst, history[i])
+	}
+	if skip >= len(newestFirst) {
+		fmt.Println("No more versions.")
+		return
+	}
+	newestFirst = newestFirst[skip:]
+	if limit > 0 && len(newestFirst) > limit {
+		newestFirst = newestFirst[:limit]
+	}
+
+	fmt.Printf("History of %s (%d versions)\n\n", newestFirst[0].Path, total)
+	for _, version := range newestFirst {
+		c := version.Commit
+		fmt.Printf("%s (v%d) %s  %s\n", c.Hash[:8], c.Version, c.Timestamp.Format("2006-01-02 15:04"), c.Author)
+		fmt.Printf("    %s\n", c.Message)
+
+		var details []string
+		switch {
+		case version.Added:
+			details = append(details, green("added"))
+		case version.RenamedFrom != "":
+			details = append(details, fmt.Sprintf("renamed from %s", version.RenamedFrom))
+		}
+		if size, ok := version.Metadata["size"].(float64); ok {
+			details = append(details, util.FormatBytes(int64(size)))
+		}
+		if version.Added {
+			if dimensions, _ := version.Metadata["dimensions"].(string); dimensions != "" && dimensions != "Unknown" {
+				details = append(details, dimensions)
+			}
+			if layers, _ := version.Metadata["layers"].(float64); layers > 0 {
+				details = append(details, fmt.Sprintf("%.0f layers", layers))
+			}
+		}
+		for _, change := range version.Changes {
+			details = append(details, change.String())
+		}
+		if !version.Added && version.ContentChanged && len(version.Changes) == 0 {
+			details = append(details, "content changed")
+		}
+		fmt.Printf("    %s\n", strings.Join(details, " • "))
+		if len(version.LayersAdded) > 0 {
+			fmt.Printf("    %s %s\n", green("+ layers:"), strings.Join(version.LayersAdded, ", "))
+		}
+		if len(version.LayersRemoved) > 0 {
+			fmt.Printf("    %s %s\n", red("- layers:"), strings.Join(version.LayersRemoved, ", "))
+		}
+		fmt.Println()
+	}
+	if limit > 0 && skip+len(newestFirst) < total {
+		printInfo(fmt.Sprintf("Use --skip %d to see older versions", skip+limit))
+	}
+}
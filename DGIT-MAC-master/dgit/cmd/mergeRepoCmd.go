@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repomerge"
+
+	"github.com/spf13/cobra"
+)
+
+// MergeRepoCmd represents the merge-repo command for consolidating repositories
+// Unique to DGit - freelancers often send back their own .dgit repositories of the same assets
+var MergeRepoCmd = &cobra.Command{
+	Use:   "merge-repo <path>",
+	Short: "Import history from another DGit repository",
+	Long: `Import the commits of another DGit repository into this one.
+
+Commits whose snapshot content already exists here are mapped to the existing
+versions. New commits are copied in, renumbered after the current latest
+version, and placed on an import branch (import/<folder> by default) so they
+never replace your own history. Files edited differently on both sides since
+the shared history are reported as conflicts.
+
+Examples:
+  dgit merge-repo ../freelancer-alex              # Import onto import/freelancer-alex
+  dgit merge-repo ../alex --branch alex-round2    # Choose the branch name
+  dgit merge-repo ../alex --dry-run               # Preview mappings and conflicts`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMergeRepo,
+}
+
+// init sets up command flags for merge-repo command
+func init() {
+	MergeRepoCmd.Flags().String("branch", "", "Branch name for imported commits (default: import/<folder>)")
+	MergeRepoCmd.Flags().Bool("dry-run", false, "Show what would be imported without changing the repository")
+}
+
+// runMergeRepo executes the merge-repo command functionality
+func runMergeRepo(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	branch, _ := cmd.Flags().GetString("branch")
+	if branch == "" {
+		branch = repomerge.DefaultBranchName(args[0])
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	result, err := repomerge.NewRepoMergeManager(dgitDir).MergeRepo(args[0], branch, dryRun)
+	if err != nil {
+		exitWithError(fmt.Sprintf("merging repository: %v", err), "")
+	}
+
+	if result.CommonVersion > 0 {
+		fmt.Printf("Shared history up to v%d\n\n", result.CommonVersion)
+	} else {
+		printWarning("No shared history found - all source commits are treated as new")
+		fmt.Println()
+	}
+
+	if len(result.Mapped) > 0 {
+		fmt.Println("Already present:")
+		for _, m := range result.Mapped {
+			fmt.Printf("  source v%-4d = v%-4d %s\n", m.SourceVersion, m.LocalVersion, m.Message)
+		}
+		fmt.Println()
+	}
+
+	if len(result.Imported) > 0 {
+		verb := "Imported"
+		if dryRun {
+			verb = "Would import"
+		}
+		fmt.Printf("%s onto %s:\n", verb, bold(result.Branch))
+		for _, m := range result.Imported {
+			fmt.Printf("  source v%-4d → v%-4d %s\n", m.SourceVersion, m.LocalVersion, m.Message)
+		}
+		fmt.Println()
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Println(red("Conflicts (edited on both sides):"))
+		for _, c := range result.Conflicts {
+			fmt.Printf("  %s: local v%d vs source v%d\n", c.Path, c.LocalVersion, c.SourceVersion)
+		}
+		fmt.Println()
+		printSuggestion("Compare the versions with 'dgit restore' and commit the file you want to keep")
+	}
+
+	switch {
+	case dryRun:
+		printInfo("Dry run - no changes made")
+	case len(result.Imported) == 0:
+		printSuccess("Nothing to import - repository already contains this history")
+	default:
+		printSuccess(fmt.Sprintf("Imported %d commits onto %s", len(result.Imported), result.Branch))
+	}
+}
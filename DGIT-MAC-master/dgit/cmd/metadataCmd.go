@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+
+	"github.com/spf13/cobra"
+)
+
+// MetadataCmd represents the metadata command for choosing the commit metadata backend
+// Large histories can move from one JSON file per version to a single SQLite database
+var MetadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Show or change how commit metadata is stored",
+	Long: `Show which backend holds commit metadata, or migrate between backends.
+
+By default every version is stored as .dgit/objects/vN.json. With thousands of
+versions, history queries have to scan all of those files; the SQLite backend
+keeps the same commits in .dgit/metadata.db with indexes on hash, time and
+author. Snapshot data is not affected by either backend.
+
+Examples:
+  dgit metadata                     # Show the current backend
+  dgit metadata migrate sqlite      # Move metadata into .dgit/metadata.db
+  dgit metadata migrate json        # Export back to one JSON file per version
+  dgit metadata normalize-paths     # Rewrite stored file paths as repo-relative`,
+	Run: runMetadata,
+}
+
+// metadataMigrateCmd moves metadata between backends
+var metadataMigrateCmd = &cobra.Command{
+	Use:       "migrate <sqlite|json>",
+	Short:     "Move commit metadata to another backend",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{log.BackendSQLite, log.BackendJSON},
+	Run:       runMetadataMigrate,
+}
+
+// metadataNormalizePathsCmd rewrites stored file paths to repo-relative form
+var metadataNormalizePathsCmd = &cobra.Command{
+	Use:   "normalize-paths",
+	Short: "Rewrite absolute or cwd-relative file paths in history as repo-relative",
+	Long: `Older commits may record files by absolute path or relative to the directory
+'dgit add' ran in, so restores on another machine cannot match them. This
+rewrites the paths in every commit's metadata relative to the working tree
+root. Snapshot data is left as it is; restore maps its paths the same way.`,
+	Args: cobra.NoArgs,
+	Run:  runMetadataNormalizePaths,
+}
+
+// init sets up metadata subcommands
+func init() {
+	metadataNormalizePathsCmd.Flags().Bool("dry-run", false, "Show what would change without writing")
+	MetadataCmd.AddCommand(metadataMigrateCmd)
+	MetadataCmd.AddCommand(metadataNormalizePathsCmd)
+}
+
+// runMetadata prints the active metadata backend
+func runMetadata(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	logManager := log.NewLogManager(dgitDir)
+
+	backend := logManager.GetMetadataBackend()
+	fmt.Printf("Metadata backend: %s\n", bold(backend))
+	fmt.Printf("Commits:          %d (latest v%d)\n", countCommits(logManager), logManager.GetCurrentVersion())
+	if backend == log.BackendSQLite {
+		if info, err := os.Stat(filepath.Join(dgitDir, log.MetadataDBFile)); err == nil {
+			fmt.Printf("Database:         %s (%.1f KB)\n", log.MetadataDBFile, float64(info.Size())/1024)
+		}
+	}
+}
+
+// runMetadataMigrate migrates commit metadata to the requested backend
+func runMetadataMigrate(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	count, err := log.MigrateMetadata(dgitDir, args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("migrating metadata: %v", err), "Run 'dgit metadata' to see the current backend")
+	}
+	printSuccess(fmt.Sprintf("Moved %d commits to the %s metadata backend", count, args[0]))
+}
+
+// runMetadataNormalizePaths rewrites stored paths and lists what changed
+func runMetadataNormalizePaths(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	migration, err := log.NormalizeCommitPaths(dgitDir, dryRun)
+	if err != nil {
+		exitWithError(fmt.Sprintf("normalizing paths: %v", err), "")
+	}
+	stored := make([]string, 0, len(migration.Rewritten))
+	for path := range migration.Rewritten {
+		stored = append(stored, path)
+	}
+	sort.Strings(stored)
+	for _, path := range stored {
+		fmt.Printf("  %s → %s\n", path, migration.Rewritten[path])
+	}
+	for _, path := range migration.Unresolved {
+		printWarning(fmt.Sprintf("%s is outside the repository; left unchanged", path))
+	}
+
+	switch {
+	case migration.Commits == 0:
+		printSuccess("No stored paths needed rewriting")
+	case dryRun:
+		printInfo(fmt.Sprintf("Would rewrite %d path(s) in %d commit(s)", len(migration.Rewritten), migration.Commits))
+	default:
+		printSuccess(fmt.Sprintf("Rewrote %d path(s) in %d commit(s)", len(migration.Rewritten), migration.Commits))
+	}
+}
+
+// countCommits returns the number of stored commits
+func countCommits(logManager *log.LogManager) int {
+	stamps, err := logManager.GetCommitStamps()
+	if err != nil {
+		return 0
+	}
+	return len(stamps)
+}
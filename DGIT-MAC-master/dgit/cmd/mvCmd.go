@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/move"
+
+	"github.com/spf13/cobra"
+)
+
+// MvCmd represents the mv command for renaming tracked design files
+// The rename is staged so the next commit records it and history follows the file
+var MvCmd = &cobra.Command{
+	Use:   "mv <source> <destination>",
+	Short: "Move or rename a tracked file, keeping its history",
+	Long: `Move a tracked file in the working tree and stage the rename. The next
+commit records the previous path, and asset groups, pins and linked assets
+that pointed at the old path are updated.
+
+Examples:
+  dgit mv hero.psd hero_final.psd          # Rename
+  dgit mv old/name.psd new/name.psd        # Move to another folder
+  dgit mv hero.psd archive/                # Move into an existing folder`,
+	Args: cobra.ExactArgs(2),
+	Run:  runMv,
+}
+
+// runMv moves a file and reports which references followed it
+func runMv(cmd *cobra.Command, args []string) {
+	result, err := move.NewMoveManager(checkDgitRepository()).Move(args[0], args[1])
+	if err != nil && result == nil {
+		exitWithError(fmt.Sprintf("moving: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Renamed %s -> %s (staged)", result.From, result.To))
+	if len(result.Groups) > 0 {
+		printInfo(fmt.Sprintf("Updated asset group(s): %s", strings.Join(result.Groups, ", ")))
+	}
+	if result.Pinned {
+		printInfo("The pin moved with the file")
+	}
+	if result.Linked {
+		printInfo("The linked asset registration moved with the file")
+	}
+	if err != nil {
+		printWarning(err.Error())
+	}
+	printSuggestion("dgit commit -m \"Rename " + result.To + "\"")
+}
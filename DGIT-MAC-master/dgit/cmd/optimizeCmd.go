@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/optimize"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// OptimizeCmd represents the optimize command for moving committed versions down the cache tiers
+var OptimizeCmd = &cobra.Command{
+	Use:   "optimize",
+	Short: "Recompress idle snapshots and blobs with Zstd and age old versions into the cold tier",
+	Long: `Run the background optimization that commits leave to later.
+
+Each pass writes a Zstd warm copy of every hot LZ4 snapshot that has been idle
+for compression.zstd_stage.min_idle_time, at zstd_stage.compression_level, and
+archives versions committed more than compression.archive_stage.archive_after_days
+ago to the cold tier (see 'dgit archive'). The newest version always stays
+online, and versions other commits are deltas against are left in place. Tier
+budgets are enforced afterwards (see 'dgit cache').
+
+In the object store (compression.object_store "content", the default), idle
+LZ4 blobs are recompressed to Zstd in place at the same level, except those the
+newest version of a branch references, which stay LZ4 for fast checkouts.
+
+A pass does nothing while a commit or restore is running, or within
+min_idle_time of the last commit. With --daemon it repeats every
+zstd_stage.optimize_interval minutes until interrupted; 'dgit daemon' also runs
+a pass whenever the interval has elapsed.
+
+Examples:
+  dgit optimize              # One pass now
+  dgit optimize --dry-run    # Show what a pass would convert and archive
+  dgit optimize --daemon     # Keep optimizing every optimize_interval`,
+	Args: cobra.NoArgs,
+	Run:  runOptimize,
+}
+
+// init sets up command flags for optimize command
+func init() {
+	OptimizeCmd.Flags().Bool("daemon", false, "Repeat every optimize_interval until interrupted")
+	OptimizeCmd.Flags().Bool("dry-run", false, "Report what would be converted and archived without changing anything")
+	OptimizeCmd.Flags().String("io-class", string(iosched.Background), "IO scheduling class for conversion: foreground, background or idle")
+}
+
+// runOptimize runs one optimization pass, or one per interval with --daemon
+func runOptimize(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	daemon, _ := cmd.Flags().GetBool("daemon")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	manager := optimize.NewOptimizeManager(dgitDir)
+	manager.IO = ioSchedulerFlag(cmd, dgitDir)
+
+	if !daemon {
+		report, err := manager.Run(dryRun)
+		if report != nil {
+			printOptimizeReport(report, true)
+		}
+		if err != nil {
+			exitWithError(fmt.Sprintf("optimizing: %v", err), "")
+		}
+		return
+	}
+	if dryRun {
+		exitWithError("--dry-run cannot be combined with --daemon", "Run 'dgit optimize --dry-run' for a single preview")
+	}
+
+	settings, err := manager.LoadSettings()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(settings.Interval)
+	defer ticker.Stop()
+
+	printInfo(fmt.Sprintf("DGit optimizer running every %s (Ctrl+C to stop)", settings.Interval))
+	for {
+		report, err := manager.Run(false)
+		if report != nil {
+			printOptimizeReport(report, false)
+		}
+		if err != nil {
+			printWarning(fmt.Sprintf("optimize pass failed: %v", err))
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			fmt.Println("DGit optimizer stopped.")
+			return
+		}
+	}
+}
+
+// optimizeDue runs an optimization pass from 'dgit daemon' once optimize_interval has elapsed
+func optimizeDue(dgitDir string, sched *iosched.Scheduler) {
+	manager := optimize.NewOptimizeManager(dgitDir)
+	manager.IO = sched
+	settings, err := manager.LoadSettings()
+	if err != nil || !manager.Due(settings, time.Now()) {
+		return
+	}
+	report, err := manager.Run(false)
+	if report != nil {
+		printOptimizeReport(report, false)
+	}
+	if err != nil {
+		printWarning(fmt.Sprintf("optimize pass failed: %v", err))
+	}
+}
+
+// printOptimizeReport prints what a pass converted and archived
+// Quiet passes (verbose false) print nothing when there was nothing to do
+func printOptimizeReport(report *optimize.Report, verbose bool) {
+	if report.Busy != "" {
+		if verbose {
+			printInfo(fmt.Sprintf("Skipped: %s", report.Busy))
+		}
+		return
+	}
+
+	warmed, archived := "Warmed", "Archived"
+	if report.DryRun {
+		warmed, archived = "Would warm", "Would archive"
+	}
+	for _, c := range report.Warmed {
+		if report.DryRun {
+			fmt.Printf("  %s v%d (%s hot)\n", warmed, c.Version, util.FormatBytes(c.HotSize))
+		} else {
+			fmt.Printf("  %s v%d: %s hot -> %s warm\n", warmed, c.Version, util.FormatBytes(c.HotSize), util.FormatBytes(c.WarmSize))
+		}
+	}
+	if n := len(report.Recompressed); n > 0 {
+		var lz4Size, zstdSize int64
+		for _, blob := range report.Recompressed {
+			lz4Size += blob.LZ4Size
+			zstdSize += blob.ZstdSize
+		}
+		if report.DryRun {
+			fmt.Printf("  Would recompress %d blob(s) (%s LZ4)\n", n, util.FormatBytes(lz4Size))
+		} else {
+			fmt.Printf("  Recompressed %d blob(s): %s LZ4 -> %s Zstd\n", n, util.FormatBytes(lz4Size), util.FormatBytes(zstdSize))
+		}
+	}
+	if len(report.Archived) > 0 {
+		fmt.Printf("  %s %s\n", archived, formatVersionList(sortedVersions(report.Archived)))
+	}
+	skipped := make([]int, 0, len(report.Skipped))
+	for version := range report.Skipped {
+		skipped = append(skipped, version)
+	}
+	for _, version := range sortedVersions(skipped) {
+		fmt.Printf("  Kept v%d online: %s\n", version, report.Skipped[version])
+	}
+	failed := make([]int, 0, len(report.Failed))
+	for version := range report.Failed {
+		failed = append(failed, version)
+	}
+	for _, version := range sortedVersions(failed) {
+		printWarning(fmt.Sprintf("v%d: %v", version, report.Failed[version]))
+	}
+	hashes := make([]string, 0, len(report.FailedBlobs))
+	for hash := range report.FailedBlobs {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	for _, hash := range hashes {
+		printWarning(fmt.Sprintf("blob %s: %v", hash[:12], report.FailedBlobs[hash]))
+	}
+	for _, result := range report.Evictions {
+		if len(result.Evicted) > 0 || len(result.Demoted) > 0 {
+			fmt.Printf("  Enforced %s budget: %s -> %s\n", result.Tier, util.FormatBytes(result.Size), util.FormatBytes(result.After))
+		}
+	}
+
+	switch {
+	case len(report.Warmed) > 0 || len(report.Recompressed) > 0 || len(report.Archived) > 0:
+		if !report.DryRun {
+			printSuccess(fmt.Sprintf("Optimized: %d warmed, %d blob(s) recompressed, %d archived",
+				len(report.Warmed), len(report.Recompressed), len(report.Archived)))
+		}
+	case verbose:
+		printInfo("Nothing to optimize")
+	}
+}
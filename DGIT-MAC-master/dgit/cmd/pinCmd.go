@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/pin"
+
+	"github.com/spf13/cobra"
+)
+
+// PinCmd represents the pin command for holding files at an older version
+// Useful while two directions are being compared: the old one stays in the work tree on purpose
+var PinCmd = &cobra.Command{
+	Use:   "pin [file@version]",
+	Short: "Hold a working tree file at an older version",
+	Long: `Restore a file from an older version and record that it is held there on
+purpose. Status reports pinned files as "pinned (vN)" instead of modified,
+and commit refuses to include them until they are unpinned.
+
+Examples:
+  dgit pin                      # List pinned files
+  dgit pin hero.psd@v5          # Restore hero.psd from v5 and pin it
+  dgit pin hero.psd@v5 --keep   # Pin the current working copy as v5 without restoring
+  dgit unpin hero.psd           # Release the pin`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPin,
+}
+
+// UnpinCmd releases pinned files
+var UnpinCmd = &cobra.Command{
+	Use:   "unpin <file...>",
+	Short: "Release pinned files so they can be committed again",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runUnpin,
+}
+
+// init sets up command flags for pin command
+func init() {
+	PinCmd.Flags().Bool("keep", false, "Pin the current working copy without restoring the version")
+}
+
+// runPin pins a file or lists pins
+func runPin(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := pin.NewPinManager(dgitDir)
+
+	if len(args) == 0 {
+		pins, err := manager.GetPins()
+		if err != nil {
+			exitWithError(fmt.Sprintf("loading pins: %v", err), "")
+		}
+		if len(pins) == 0 {
+			fmt.Println("No pinned files.")
+			return
+		}
+		for _, p := range pins {
+			fmt.Printf("  %s@v%d (since %s)\n", p.Path, p.Version, p.PinnedAt.Format("2006-01-02 15:04"))
+		}
+		return
+	}
+
+	at := strings.LastIndex(args[0], "@")
+	if at <= 0 {
+		exitWithError(fmt.Sprintf("missing version in %q", args[0]), "Use file@version, e.g. hero.psd@v5")
+	}
+	version, err := parseVersionArg(args[0][at+1:])
+	if err != nil {
+		exitWithError(err.Error(), "Use file@version, e.g. hero.psd@v5")
+	}
+	keep, _ := cmd.Flags().GetBool("keep")
+
+	p, err := manager.Pin(args[0][:at], version, keep)
+	if err != nil {
+		exitWithError(fmt.Sprintf("pinning: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Pinned %s at v%d", p.Path, p.Version))
+	printInfo(fmt.Sprintf("Commit will refuse it until 'dgit unpin %s'", p.Path))
+}
+
+// runUnpin releases pins
+func runUnpin(cmd *cobra.Command, args []string) {
+	manager := pin.NewPinManager(checkDgitRepository())
+	for _, path := range args {
+		p, err := manager.Unpin(path)
+		if err != nil {
+			exitWithError(fmt.Sprintf("unpinning: %v", err), "Run 'dgit pin' to list pinned files")
+		}
+		printSuccess(fmt.Sprintf("Unpinned %s (was held at v%d)", p.Path, p.Version))
+	}
+}
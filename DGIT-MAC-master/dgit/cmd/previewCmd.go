@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/clipboard"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// PreviewCmd represents the preview command for lightweight proxies of design files
+// Proxies let teammates without Photoshop, Illustrator or Blender look at a version
+var PreviewCmd = &cobra.Command{
+	Use:   "preview [version] [file]",
+	Short: "List or generate viewable proxies (PNG/JPEG/GLB) of versions",
+	Long: `After each commit, DGit runs the external converters configured under
+"preview.converters" (e.g. ImageMagick, Blender headless) and stores the
+proxies in .dgit/previews/v<N>/. Converters whose tool is not installed are
+skipped.
+
+Examples:
+  dgit preview                    # List all stored previews
+  dgit preview v5                 # List the previews of v5
+  dgit preview v7 hero.psd --copy # Put v7's flattened hero.psd on the clipboard
+  dgit preview generate v3        # Backfill previews for an older version
+  dgit preview converters         # Show configured converters
+  dgit preview add jpeg --ext .tga --output jpg -- convert {input} {output}`,
+	Args: cobra.MaximumNArgs(2),
+	Run:  runPreviewList,
+}
+
+// previewGenerateCmd creates proxies for an existing version
+var previewGenerateCmd = &cobra.Command{
+	Use:   "generate [version]",
+	Short: "Generate previews for a version (default: latest)",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runPreviewGenerate,
+}
+
+// previewConvertersCmd lists converters and whether their tools are installed
+var previewConvertersCmd = &cobra.Command{
+	Use:   "converters",
+	Short: "List configured preview converters",
+	Args:  cobra.NoArgs,
+	Run:   runPreviewConverters,
+}
+
+// previewAddCmd adds or replaces a converter
+var previewAddCmd = &cobra.Command{
+	Use:   "add <name> --ext <.ext> --output <format> -- <command...>",
+	Short: "Add or replace a preview converter",
+	Args:  cobra.MinimumNArgs(2),
+	Run:   runPreviewAdd,
+}
+
+// previewRemoveCmd deletes a converter
+var previewRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a preview converter",
+	Args:  cobra.ExactArgs(1),
+	Run:   runPreviewRemove,
+}
+
+// init sets up preview subcommands and flags
+func init() {
+	PreviewCmd.Flags().Bool("copy", false, "Copy the file's flattened preview to the clipboard (needs a version and file)")
+	previewAddCmd.Flags().StringSlice("ext", nil, "Source file extensions handled by the converter")
+	previewAddCmd.Flags().String("output", "png", "Proxy format extension")
+	previewAddCmd.MarkFlagRequired("ext")
+
+	PreviewCmd.AddCommand(previewGenerateCmd)
+	PreviewCmd.AddCommand(previewConvertersCmd)
+	PreviewCmd.AddCommand(previewAddCmd)
+	PreviewCmd.AddCommand(previewRemoveCmd)
+}
+
+// runPreviewList lists stored proxies
+func runPreviewList(cmd *cobra.Command, args []string) {
+	manager := preview.NewPreviewManager(checkDgitRepository())
+
+	version := 0
+	if len(args) >= 1 {
+		v, err := parseVersionArg(args[0])
+		if err != nil {
+			exitWithError(err.Error(), "Use a version such as v5")
+		}
+		version = v
+	}
+
+	if copyImage, _ := cmd.Flags().GetBool("copy"); copyImage || len(args) == 2 {
+		if len(args) != 2 || !copyImage {
+			exitWithError("--copy needs a version and a file", "dgit preview v7 hero.psd --copy")
+		}
+		copyPreview(manager, version, args[1])
+		return
+	}
+
+	previews, err := manager.List(version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("listing previews: %v", err), "")
+	}
+	if len(previews) == 0 {
+		fmt.Println("No previews stored.")
+		printSuggestion("dgit preview converters")
+		return
+	}
+	for _, p := range previews {
+		fmt.Printf("  v%-4d %-40s %10s  %s\n", p.Version, p.Source, util.FormatBytes(p.Size), p.Path)
+	}
+}
+
+// copyPreview puts the flattened preview of one file in a version on the clipboard
+func copyPreview(manager *preview.PreviewManager, version int, file string) {
+	commit, err := log.NewLogManager(manager.DgitDir).GetCommit(version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("v%d not found: %v", version, err), "Run 'dgit log' to list versions")
+	}
+	// Rendering may restore the file first; its progress output is not useful here
+	var composite *preview.Preview
+	withQuietStdout(func() { composite, err = manager.Composite(commit, file) })
+	if err != nil {
+		exitWithError(err.Error(), "Configure a converter with 'dgit preview add' for this file type")
+	}
+	if err := clipboard.CopyImage(composite.Path); err != nil {
+		exitWithError(fmt.Sprintf("copying to clipboard: %v", err), fmt.Sprintf("The preview is at %s", composite.Path))
+	}
+	printSuccess(fmt.Sprintf("Copied the v%d preview of %s to the clipboard (%s)", version, composite.Source, util.FormatBytes(composite.Size)))
+}
+
+// runPreviewGenerate backfills proxies for a committed version
+func runPreviewGenerate(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	logManager := log.NewLogManager(dgitDir)
+
+	version := logManager.GetCurrentVersion()
+	if len(args) == 1 {
+		v, err := parseVersionArg(args[0])
+		if err != nil {
+			exitWithError(err.Error(), "Use a version such as v5")
+		}
+		version = v
+	}
+	commit, err := logManager.GetCommit(version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("version not found: %v", err), "Run 'dgit log' to see versions")
+	}
+
+	fmt.Printf("Generating previews for v%d...\n", commit.Version)
+	results, err := preview.NewPreviewManager(dgitDir).GenerateForCommit(commit)
+	if err != nil {
+		exitWithError(fmt.Sprintf("generating previews: %v", err), "")
+	}
+	printPreviewResults(results, true)
+}
+
+// runPreviewConverters lists the configured converters
+func runPreviewConverters(cmd *cobra.Command, args []string) {
+	converters, err := preview.NewPreviewManager(checkDgitRepository()).GetConverters()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading config: %v", err), "")
+	}
+	if len(converters) == 0 {
+		fmt.Println("No preview converters configured.")
+		return
+	}
+	for _, converter := range converters {
+		state := green("installed")
+		if !preview.Installed(converter) {
+			state = yellow("not installed")
+		}
+		fmt.Printf("  %s (%s) %s -> %s\n", bold(converter.Name), state,
+			strings.Join(converter.Extensions, " "), converter.Output)
+		fmt.Printf("      %s\n", strings.Join(converter.Command, " "))
+	}
+}
+
+// runPreviewAdd stores a converter in the repository config
+func runPreviewAdd(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	extensions, _ := cmd.Flags().GetStringSlice("ext")
+	output, _ := cmd.Flags().GetString("output")
+
+	command := args[1:]
+	joined := strings.Join(command, " ")
+	if !strings.Contains(joined, "{input}") || !strings.Contains(joined, "{output}") {
+		exitWithError("converter command must use {input} and {output}", "e.g. -- magick {input}[0] {output}")
+	}
+	for i, ext := range extensions {
+		if !strings.HasPrefix(ext, ".") {
+			extensions[i] = "." + ext
+		}
+	}
+
+	config, err := initializer.GetRepositoryConfig(dgitDir)
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading config: %v", err), "")
+	}
+	converter := initializer.PreviewConverter{Name: args[0], Extensions: extensions, Command: command,
+		Output: strings.TrimPrefix(output, ".")}
+	converters := config.Preview.Converters[:0]
+	for _, existing := range config.Preview.Converters {
+		if existing.Name != converter.Name {
+			converters = append(converters, existing)
+		}
+	}
+	config.Preview.Converters = append(converters, converter)
+	if err := initializer.UpdateRepositoryConfig(dgitDir, config); err != nil {
+		exitWithError(fmt.Sprintf("saving config: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Preview converter %s: %s -> %s", converter.Name,
+		strings.Join(extensions, " "), converter.Output))
+	if !preview.Installed(converter) {
+		printWarning(fmt.Sprintf("%s was not found in PATH; files will be skipped until it is installed", command[0]))
+	}
+}
+
+// runPreviewRemove deletes a converter from the repository config
+func runPreviewRemove(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	config, err := initializer.GetRepositoryConfig(dgitDir)
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading config: %v", err), "")
+	}
+	for i, converter := range config.Preview.Converters {
+		if converter.Name == args[0] {
+			config.Preview.Converters = append(config.Preview.Converters[:i], config.Preview.Converters[i+1:]...)
+			if err := initializer.UpdateRepositoryConfig(dgitDir, config); err != nil {
+				exitWithError(fmt.Sprintf("saving config: %v", err), "")
+			}
+			printSuccess(fmt.Sprintf("Removed preview converter %s", args[0]))
+			return
+		}
+	}
+	exitWithError(fmt.Sprintf("no preview converter named %s", args[0]), "Run 'dgit preview converters' to list them")
+}
+
+// generateCommitPreviews is the post-commit pipeline step: proxies are made from the committed working copies
+// files maps repository paths to absolute paths; failures are reported but never fail the commit
+func generateCommitPreviews(dgitDir string, version int, files map[string]string) {
+	results, err := preview.NewPreviewManager(dgitDir).Generate(version, files)
+	if err != nil {
+		printWarning(fmt.Sprintf("preview generation skipped: %v", err))
+		return
+	}
+	printPreviewResults(results, false)
+	auditCommitPreviews(dgitDir, version, results)
+}
+
+// rootRelativeFiles maps root-relative repository paths to their absolute working tree paths
+func rootRelativeFiles(dgitDir string, paths []string) map[string]string {
+	root := filepath.Dir(dgitDir)
+	files := make(map[string]string, len(paths))
+	for _, path := range paths {
+		files[path] = filepath.Join(root, filepath.FromSlash(path))
+	}
+	return files
+}
+
+// printPreviewResults summarizes a generation run; skipped files are only listed when verbose
+func printPreviewResults(results []*preview.Result, verbose bool) {
+	generated := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			printWarning(fmt.Sprintf("preview of %s: %v", result.Source, result.Err))
+		case result.Preview != nil:
+			generated++
+			if verbose {
+				fmt.Printf("  %s -> %s\n", result.Source, result.Preview.Path)
+			}
+		case verbose:
+			fmt.Printf("  %s skipped (%s)\n", result.Source, result.Skipped)
+		}
+	}
+	if generated > 0 {
+		printInfo(fmt.Sprintf("Generated %d preview(s) (dgit preview)", generated))
+	} else if verbose {
+		printInfo("No previews generated")
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/recompress"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// RecompressCmd represents the recompress command for converging old storage objects
+// The daemon runs the same task in small batches, so most repositories never need it by hand
+var RecompressCmd = &cobra.Command{
+	Use:   "recompress",
+	Short: "Rewrite storage objects written in deprecated formats",
+	Long: `Find cache objects written in a deprecated format (such as the legacy
+text-framed LZ4/Zstd streams) and rewrite them into the current format.
+Each object is verified before it replaces the old one, and progress is
+saved after every object, so an interrupted run simply resumes.
+
+'dgit daemon' runs this task in the background a few objects per pass.
+Both pace their disk IO and pause while a commit or restore is running;
+use --io-class foreground to rewrite at full speed.
+
+Examples:
+  dgit recompress              # Rewrite everything pending
+  dgit recompress --limit 20   # Rewrite at most 20 objects
+  dgit recompress --status     # Show progress without rewriting
+  dgit recompress --io-class foreground  # Full speed, e.g. overnight`,
+	Args: cobra.NoArgs,
+	Run:  runRecompress,
+}
+
+// init sets up command flags for recompress command
+func init() {
+	RecompressCmd.Flags().Int("limit", 0, "Rewrite at most this many objects (0 = all)")
+	RecompressCmd.Flags().Bool("status", false, "Show progress and pending objects only")
+	RecompressCmd.Flags().String("io-class", string(iosched.Background), "IO scheduling class: foreground (full speed), background or idle")
+}
+
+// runRecompress scans for deprecated objects and rewrites them
+func runRecompress(cmd *cobra.Command, args []string) {
+	manager := recompress.NewRecompressManager(checkDgitRepository())
+	limit, _ := cmd.Flags().GetInt("limit")
+	statusOnly, _ := cmd.Flags().GetBool("status")
+	manager.IO = ioSchedulerFlag(cmd, manager.DgitDir)
+
+	if statusOnly {
+		state, err := manager.LoadState()
+		if err != nil {
+			exitWithError(fmt.Sprintf("reading recompress state: %v", err), "")
+		}
+		printRecompressStatus(state)
+		return
+	}
+
+	if _, err := manager.Scan(); err != nil {
+		exitWithError(fmt.Sprintf("scanning storage: %v", err), "")
+	}
+	state, rewritten, err := manager.Run(limit)
+	if err != nil {
+		exitWithError(fmt.Sprintf("recompressing: %v", err), "")
+	}
+	for _, rel := range rewritten {
+		object := state.Objects[rel]
+		fmt.Printf("  rewrote %-32s %10s → %s\n", rel, util.FormatBytes(object.OriginalSize), util.FormatBytes(object.NewSize))
+	}
+	if manager.IO.Paused > 0 {
+		printInfo(fmt.Sprintf("Paused %s for commits and restores", manager.IO.Paused.Round(time.Second)))
+	}
+	printRecompressStatus(state)
+}
+
+// ioSchedulerFlag builds the scheduler selected by --io-class
+func ioSchedulerFlag(cmd *cobra.Command, dgitDir string) *iosched.Scheduler {
+	name, _ := cmd.Flags().GetString("io-class")
+	class, err := iosched.ParseClass(name)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	return iosched.NewScheduler(dgitDir, class)
+}
+
+// printRecompressStatus prints task progress and any failures
+func printRecompressStatus(state *recompress.State) {
+	progress := state.Summarize()
+	if progress.Total == 0 {
+		fmt.Println("No objects in deprecated formats.")
+		for _, d := range codec.Deprecations() {
+			fmt.Printf("  checked for: %s\n", d.Description)
+		}
+		return
+	}
+
+	fmt.Printf("%d of %d object(s) rewritten, %d pending, %d failed (%s saved)\n",
+		progress.Done, progress.Total, progress.Pending, progress.Failed, util.FormatBytes(progress.Saved))
+
+	var failed []string
+	for rel, object := range state.Objects {
+		if object.Status == recompress.StatusFailed {
+			failed = append(failed, rel)
+		}
+	}
+	sort.Strings(failed)
+	for _, rel := range failed {
+		printWarning(fmt.Sprintf("%s: %s", rel, state.Objects[rel].Error))
+	}
+	if progress.Pending > 0 {
+		printSuggestion("Run 'dgit recompress' to continue")
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/remote"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// RemoteCmd represents the remote command for managing shared servers
+// A remote is another repository exposed with 'dgit serve', reached with its webhook token
+var RemoteCmd = &cobra.Command{
+	Use:   "remote",
+	Short: "List, add and remove remote repositories",
+	Long: `Manage the shared servers 'dgit push' and 'dgit pull' sync with. A remote
+is a repository on another machine running 'dgit serve'; its token is the one
+shown by 'dgit serve webhook' on that machine.
+
+Examples:
+  dgit remote                                          # List remotes
+  dgit remote add origin http://nas.studio.local:8080
+  dgit remote token origin 3f9c...                     # Store the server's token
+  dgit remote remove origin`,
+	Args: cobra.NoArgs,
+	Run:  runRemoteList,
+}
+
+// remoteAddCmd adds a remote
+var remoteAddCmd = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add or replace a remote",
+	Args:  cobra.ExactArgs(2),
+	Run:   runRemoteAdd,
+}
+
+// remoteRemoveCmd removes a remote
+var remoteRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a remote and its stored token",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRemoteRemove,
+}
+
+// remoteTokenCmd stores a remote's token
+var remoteTokenCmd = &cobra.Command{
+	Use:   "token <name> <token>",
+	Short: "Store the access token for a remote",
+	Args:  cobra.ExactArgs(2),
+	Run:   runRemoteToken,
+}
+
+// PushCmd uploads local versions to a remote
+var PushCmd = &cobra.Command{
+	Use:   "push [remote]",
+	Short: "Send new versions to a remote",
+	Long: `Upload the versions the remote does not have yet. The remote must not have
+versions this repository lacks; pull first when it does.
+
+Examples:
+  dgit push             # Push to origin
+  dgit push backup-nas`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPush,
+}
+
+// PullCmd downloads versions from a remote
+var PullCmd = &cobra.Command{
+	Use:   "pull [remote]",
+	Short: "Fetch new versions from a remote",
+	Long: `Download the versions this repository does not have yet and add them to the
+history. If local history has diverged, the remote's versions are imported
+onto the branch remote/<name> instead, the same way as 'dgit unbundle'.
+
+Examples:
+  dgit pull             # Pull from origin
+  dgit pull backup-nas`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPull,
+}
+
+// init sets up remote subcommands
+func init() {
+	RemoteCmd.AddCommand(remoteAddCmd)
+	RemoteCmd.AddCommand(remoteRemoveCmd)
+	RemoteCmd.AddCommand(remoteTokenCmd)
+}
+
+// runRemoteList prints the configured remotes
+func runRemoteList(cmd *cobra.Command, args []string) {
+	manager := remote.NewRemoteManager(checkDgitRepository())
+	remotes, err := manager.List()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if len(remotes) == 0 {
+		printInfo("No remotes configured")
+		printSuggestion("Add one with 'dgit remote add origin <url>'")
+		return
+	}
+	for _, r := range remotes {
+		token := green("token set")
+		if _, err := manager.Token(r.Name); err != nil {
+			token = yellow("no token")
+		}
+		fmt.Printf("  %-12s %s (%s)\n", r.Name, r.URL, token)
+	}
+}
+
+// runRemoteAdd adds a remote
+func runRemoteAdd(cmd *cobra.Command, args []string) {
+	manager := remote.NewRemoteManager(checkDgitRepository())
+	if err := manager.Add(args[0], args[1]); err != nil {
+		exitWithError(err.Error(), "")
+	}
+	printSuccess(fmt.Sprintf("Added remote %s (%s)", args[0], args[1]))
+	if _, err := manager.Token(args[0]); err != nil {
+		printSuggestion(fmt.Sprintf("Store the server's token with 'dgit remote token %s <token>'", args[0]))
+	}
+}
+
+// runRemoteRemove removes a remote
+func runRemoteRemove(cmd *cobra.Command, args []string) {
+	if err := remote.NewRemoteManager(checkDgitRepository()).Remove(args[0]); err != nil {
+		exitWithError(err.Error(), "")
+	}
+	printSuccess(fmt.Sprintf("Removed remote %s", args[0]))
+}
+
+// runRemoteToken stores a remote's token
+func runRemoteToken(cmd *cobra.Command, args []string) {
+	if err := remote.NewRemoteManager(checkDgitRepository()).SaveToken(args[0], args[1]); err != nil {
+		exitWithError(err.Error(), "Run 'dgit remote' to list remotes")
+	}
+	printSuccess(fmt.Sprintf("Stored token for %s", args[0]))
+}
+
+// runPush sends versions the remote is missing
+func runPush(cmd *cobra.Command, args []string) {
+	manager := remote.NewRemoteManager(checkDgitRepository())
+	name := remoteArg(args)
+
+	result, err := manager.Push(name)
+	if err != nil {
+		if errors.Is(err, remote.ErrBehind) {
+			exitWithError(err.Error(), fmt.Sprintf("Run 'dgit pull %s', then push again", name))
+		}
+		exitWithError(fmt.Sprintf("push failed: %v", err), "")
+	}
+	if len(result.Added) == 0 {
+		printSuccess(fmt.Sprintf("%s is up to date", name))
+		return
+	}
+	for _, c := range result.Added {
+		fmt.Printf("  %s (v%d) %s\n", c.Hash[:8], c.Version, c.Message)
+	}
+	printSuccess(fmt.Sprintf("Pushed %d version(s) to %s (%s)", len(result.Added), name, util.FormatBytes(result.Bytes)))
+}
+
+// runPull fetches versions this repository is missing
+func runPull(cmd *cobra.Command, args []string) {
+	manager := remote.NewRemoteManager(checkDgitRepository())
+	name := remoteArg(args)
+
+	result, err := manager.Pull(name)
+	if err != nil {
+		exitWithError(fmt.Sprintf("pull failed: %v", err), "")
+	}
+	if result.Unbundle == nil {
+		printSuccess("Already up to date")
+		return
+	}
+	printInfo(fmt.Sprintf("Received %s from %s", util.FormatBytes(result.Bytes), name))
+	printUnbundleResult(result.Unbundle, name)
+}
+
+// remoteArg returns the remote named on the command line, or the default
+func remoteArg(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return remote.DefaultRemote
+}
@@ -1,13 +1,26 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"dgit/internal/log"
-	"dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/group"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/stats"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
 	
 	"github.com/spf13/cobra"
 )
@@ -25,6 +38,26 @@ Examples:
   dgit restore c3a5f7b8           # Restore all files from commit with short hash c3a5f7b8
   dgit restore 2 my_design.psd    # Restore 'my_design.psd' from version 2
   dgit restore 2 designs/         # Restore all files in 'designs/' from version 2
+  dgit restore 5 "shot_####.psd"  # Restore a whole frame sequence from version 5
+  dgit restore 5 "shot_*"         # Restore every file matching a wildcard
+  dgit restore 3 --recurse-submodules  # Restore version 3 and its pinned submodules
+  dgit restore --resume           # Continue a restore that was interrupted
+  dgit restore 4 --read-order warm,hot,smart  # Prefer warm cache, never read cold storage
+  dgit restore 6 --type psd,ai --max-size 500MB  # Only lightweight sources
+  dgit restore 2 --yes            # Overwrite newer working copies without asking
+  dgit restore 3 --dry-run        # List what would be written, change nothing
+  dgit restore 3 --backup         # Save uncommitted working files to .dgit/temp first
+
+The storage tier probe order defaults to hot, warm, smart, cold, legacy and
+can be set per repository in compression.cache.read_order.
+
+Restore refuses to overwrite working files with uncommitted changes unless
+--force is given, or --backup saves copies of them to .dgit/temp first. A file
+whose content matches any committed version, such as one left by restoring an
+older version, is not an uncommitted change.
+Before working files edited since the target version was committed are
+overwritten, their current metadata is compared with the target's and the
+restore asks for confirmation.
 
 Smart file matching:
 - Exact path matching
@@ -32,6 +65,12 @@ Smart file matching:
 - Directory matching
 - Partial path matching`,
 	Args: func(cmd *cobra.Command, args []string) error {
+		if resume, _ := cmd.Flags().GetBool("resume"); resume {
+			if len(args) > 0 {
+				return fmt.Errorf("--resume takes no arguments; it continues the interrupted restore")
+			}
+			return nil
+		}
 		if len(args) < 1 {
 			return fmt.Errorf("requires at least one argument: <version_or_hash>")
 		}
@@ -40,6 +79,20 @@ Smart file matching:
 	Run: runRestore,
 }
 
+// init sets up command flags for restore command
+func init() {
+	RestoreCmd.Flags().Bool("recurse-submodules", false, "Also check out the pinned version of every submodule")
+	RestoreCmd.Flags().Bool("resume", false, "Continue an interrupted restore, verifying files already written")
+	RestoreCmd.Flags().String("cc-libraries", "", "Folder for the pinned CC Library elements (default .dgit/cclib/restored/v<N>)")
+	RestoreCmd.Flags().StringSlice("read-order", nil, "Storage tiers to probe, in order (hot, warm, smart, cold, legacy)")
+	RestoreCmd.Flags().StringSlice("type", nil, "Restore only these file types, e.g. psd,ai")
+	RestoreCmd.Flags().String("max-size", "", "Skip files larger than this committed size, e.g. 500MB")
+	RestoreCmd.Flags().BoolP("yes", "y", false, "Overwrite newer working copies without asking")
+	RestoreCmd.Flags().Bool("dry-run", false, "List the files that would be restored without writing them")
+	RestoreCmd.Flags().BoolP("force", "f", false, "Overwrite working files with uncommitted changes")
+	RestoreCmd.Flags().Bool("backup", false, "Copy working files with uncommitted changes to .dgit/temp before overwriting them")
+}
+
 // runRestore executes the restore command functionality
 // Restores files from a specific commit to the working directory
 func runRestore(cmd *cobra.Command, args []string) {
@@ -48,7 +101,33 @@ func runRestore(cmd *cobra.Command, args []string) {
 	
 	// Initialize managers for restore and log operations
 	restoreManager := restore.NewRestoreManager(dgitDir)
+	restoreManager.RunHooks = true
+	restoreManager.Progress = os.Stdout
 	logManager := log.NewLogManager(dgitDir)
+	if order, _ := cmd.Flags().GetStringSlice("read-order"); len(order) > 0 {
+		parsed, err := restore.ParseReadOrder(order)
+		if err != nil {
+			exitWithError(err.Error(), "Example: --read-order warm,hot,smart")
+		}
+		restoreManager.ReadOrder = parsed
+	}
+
+	types, _ := cmd.Flags().GetStringSlice("type")
+	if maxSize, _ := cmd.Flags().GetString("max-size"); len(types) > 0 || maxSize != "" {
+		restoreManager.Filter = &restore.FileFilter{Types: types}
+		if maxSize != "" {
+			size, err := stats.ParseSize(maxSize)
+			if err != nil {
+				exitWithError(err.Error(), "Example: --max-size 500MB")
+			}
+			restoreManager.Filter.MaxSize = size
+		}
+	}
+
+	if resume, _ := cmd.Flags().GetBool("resume"); resume {
+		runRestoreResume(restoreManager, logManager)
+		return
+	}
 
 	commitRef := args[0]           // First argument is version or hash
 	filesToRestore := []string{}   // Specific files to restore (optional)
@@ -65,6 +144,25 @@ func runRestore(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Expand sequence names and wildcards (shot_####.psd, shot_*) into the committed frames
+	if len(filesToRestore) > 0 {
+		filesToRestore, err = expandRestoreTargets(dgitDir, targetCommit, filesToRestore)
+		if err != nil {
+			exitWithError(err.Error(), fmt.Sprintf("Use 'dgit log' to see the files in v%d", targetCommit.Version))
+		}
+	}
+
+	// Type and size filters: the journal and usage record cover only the files that pass
+	plannedFiles := filesToRestore
+	if restoreManager.Filter != nil {
+		kept, skipped := restoreManager.FilterTargets(targetCommit, filesToRestore)
+		if len(kept) == 0 {
+			exitWithError(fmt.Sprintf("no files in v%d match --type/--max-size", targetCommit.Version), "")
+		}
+		fmt.Printf("Filters select %d file(s); %d skipped\n", len(kept), len(skipped))
+		plannedFiles = kept
+	}
+
 	// Display information about what will be restored
 	if len(filesToRestore) == 0 {
 		// Restoring all files from the commit
@@ -78,12 +176,336 @@ func runRestore(cmd *cobra.Command, args []string) {
 		fmt.Printf("Target files: %v\n\n", filesToRestore)
 	}
 
+	plan, err := restoreManager.PlanRestore(targetCommit, plannedFiles)
+	if err != nil {
+		exitWithError(fmt.Sprintf("planning restore: %v", err), "")
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printRestorePlan(plan, targetCommit)
+		return
+	}
+
+	// Uncommitted work is never clobbered silently
+	force, _ := cmd.Flags().GetBool("force")
+	var dirty []string
+	for _, write := range plan {
+		if write.Dirty {
+			dirty = append(dirty, write.Path)
+		}
+	}
+	if backup, _ := cmd.Flags().GetBool("backup"); backup && len(dirty) > 0 {
+		backupDir, err := restoreManager.BackupFiles(dirty)
+		if err != nil {
+			exitWithError(err.Error(), "")
+		}
+		printInfo(fmt.Sprintf("Backed up %d file(s) with uncommitted changes to %s", len(dirty), backupDir))
+	} else if len(dirty) > 0 && !force {
+		fmt.Printf("%d working file(s) have uncommitted changes:\n", len(dirty))
+		for _, path := range dirty {
+			fmt.Printf("  %s\n", path)
+		}
+		exitWithError("restore would overwrite uncommitted changes",
+			"Commit them first, use --backup to save copies to .dgit/temp, or --force to discard them")
+	}
+
+	// Newer working copies are only overwritten after the user has seen what changes
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes && !force {
+		confirmRestoreOverwrite(dgitDir, targetCommit, plan)
+	}
+
+	// Journal the restore so it can be resumed if interrupted
+	if previous, _ := restoreManager.LoadJournal(); previous != nil {
+		printWarning(fmt.Sprintf("Discarding the journal of an interrupted restore of v%d (%d of %d files done)",
+			previous.Version, len(previous.Completed), len(previous.Planned)))
+	}
+	if _, err := restoreManager.StartJournal(targetCommit, plannedFiles); err != nil {
+		printWarning(fmt.Sprintf("Restore will not be resumable: %v", err))
+	}
+
 	// Perform the actual file restoration
+	started := time.Now()
 	err = performRestore(restoreManager, targetCommit, filesToRestore)
 	if err != nil {
-		printError(fmt.Sprintf("Restore failed: %v", err))
+		exitWithError(fmt.Sprintf("Restore failed: %v", err), "Run 'dgit restore --resume' to retry the files that were not restored")
+	}
+	finishRestoreJournal(restoreManager)
+	var written []string
+	for _, write := range plan {
+		if write.Action != restore.WriteUnchanged {
+			written = append(written, write.Path)
+		}
+	}
+	refreshStagingAfterRestore(dgitDir, restoreManager, written)
+	accounting.NewAccountingManager(dgitDir).Record(accounting.Event{
+		Operation:  accounting.OpRestore,
+		Version:    targetCommit.Version,
+		BytesRead:  restoredSize(targetCommit, plannedFiles),
+		DurationMs: float64(time.Since(started).Microseconds()) / 1000,
+	})
+
+	// Fetch linked library assets at the versions recorded in the commit
+	if len(targetCommit.LinkedAssets) > 0 {
+		failures := linked.NewLinkManager(dgitDir).FetchCommitLinks(targetCommit.LinkedAssets, filesToRestore)
+		for path, err := range failures {
+			printWarning(fmt.Sprintf("failed to fetch linked asset %s: %v", path, err))
+		}
+	}
+
+	// Export the Creative Cloud Library elements the restored files were linked to
+	if len(targetCommit.CCLibraryAssets) > 0 {
+		dir, _ := cmd.Flags().GetString("cc-libraries")
+		exportCCLibraryAssets(dgitDir, targetCommit, filesToRestore, dir)
+	}
+
+	// Restore nested repositories at their pinned versions if requested
+	if recurse, _ := cmd.Flags().GetBool("recurse-submodules"); recurse {
+		fmt.Println("\nUpdating submodules...")
+		failures, err := submodule.NewSubmoduleManager(dgitDir).UpdateAll()
+		if err != nil {
+			printError(fmt.Sprintf("updating submodules: %v", err))
+			os.Exit(1)
+		}
+		reportSubmoduleFailures(failures)
+	}
+}
+
+// confirmRestoreOverwrite lists working files that were changed after the target version was committed,
+// comparing their metadata with the target's, and exits unless the user confirms the overwrite
+func confirmRestoreOverwrite(dgitDir string, targetCommit *log.Commit, plan []*restore.PlannedWrite) {
+	rootDir := filepath.Dir(dgitDir)
+	var newer []string
+	for _, write := range plan {
+		if write.Action != restore.WriteOverwrite {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(write.Path)))
+		if err == nil && info.ModTime().After(targetCommit.Timestamp) {
+			newer = append(newer, write.Path)
+		}
+	}
+	if len(newer) == 0 {
+		return
+	}
+
+	fmt.Printf("%d working file(s) are newer than v%d:\n", len(newer), targetCommit.Version)
+	for _, path := range newer {
+		current := "unreadable"
+		if m, err := scanDesignMetadata(dgitDir, filepath.Join(rootDir, filepath.FromSlash(path))); err == nil {
+			current = m.String()
+		}
+		target := "no recorded metadata"
+		if m, ok := committedDesignMetadata(targetCommit.Metadata[path]); ok {
+			target = m.String()
+		}
+		fmt.Printf("  %s\n    current: %s / target v%d: %s\n", path, current, targetCommit.Version, target)
+	}
+	fmt.Println()
+
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		exitWithError("refusing to overwrite newer working files without confirmation", "Re-run with --yes to overwrite them")
+	}
+	fmt.Printf("Overwrite them with v%d? [y/N] ", targetCommit.Version)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		fmt.Println("Restore cancelled.")
 		os.Exit(1)
 	}
+	fmt.Println()
+}
+
+// printRestorePlan lists what a restore would write, for --dry-run
+func printRestorePlan(plan []*restore.PlannedWrite, targetCommit *log.Commit) {
+	counts := make(map[string]int)
+	dirty := 0
+	for _, write := range plan {
+		counts[write.Action]++
+		note := ""
+		if write.Dirty {
+			note = yellow(" (uncommitted changes would be lost)")
+			dirty++
+		}
+		fmt.Printf("  %-9s %s (%s)%s\n", write.Action, write.Path, util.FormatBytes(write.Size), note)
+	}
+	fmt.Printf("\nDry run: v%d would create %d, overwrite %d and leave %d file(s) unchanged\n",
+		targetCommit.Version, counts[restore.WriteCreate], counts[restore.WriteOverwrite], counts[restore.WriteUnchanged])
+	if dirty > 0 {
+		printWarning(fmt.Sprintf("%d file(s) have uncommitted changes; the restore needs --backup or --force", dirty))
+	}
+}
+
+// runRestoreResume continues the restore recorded in the journal
+// Files already written are verified by checksum; only missing or damaged files are restored again
+func runRestoreResume(restoreManager *restore.RestoreManager, logManager *log.LogManager) {
+	journal, err := restoreManager.LoadJournal()
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if journal == nil {
+		exitWithError("no interrupted restore to resume", "")
+	}
+	targetCommit, err := logManager.GetCommit(journal.Version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("v%d of the interrupted restore is not available: %v", journal.Version, err), "")
+	}
+
+	restoreManager.WorkDir = journal.WorkDir
+	restoreManager.Journal = journal
+	verified, remaining := restoreManager.VerifyJournal(journal)
+	fmt.Printf("Resuming restore of v%d started %s\n", journal.Version, journal.StartedAt.Format("2006-01-02 15:04"))
+	fmt.Printf("Verified %d file(s) already restored, %d remaining\n\n", len(verified), len(remaining))
+
+	if len(remaining) > 0 {
+		if err := performRestore(restoreManager, targetCommit, remaining); err != nil {
+			exitWithError(fmt.Sprintf("Restore failed: %v", err), "Run 'dgit restore --resume' again to retry")
+		}
+	}
+	refreshStagingAfterRestore(restoreManager.DgitDir, restoreManager, remaining)
+	if finishRestoreJournal(restoreManager) {
+		printSuccess(fmt.Sprintf("Restore of v%d complete", journal.Version))
+	}
+}
+
+// refreshStagingAfterRestore re-stages or unstages the staged files a restore overwrote
+// Warm and cold staging entries link to the working file, so a stale entry would commit the restored content
+// under the old hash; files restored back to their HEAD content are simply unstaged
+func refreshStagingAfterRestore(dgitDir string, restoreManager *restore.RestoreManager, written []string) {
+	lock, err := repolock.Acquire(dgitDir, "restore", repolock.DefaultWait)
+	if err != nil {
+		printWarning(fmt.Sprintf("staging area not refreshed: %v", err))
+		return
+	}
+	defer lock.Release()
+	stagingArea := staging.NewStagingArea(dgitDir)
+	stagingArea.Lock = lock
+	if err := stagingArea.LoadStaging(); err != nil || stagingArea.IsEmpty() {
+		return
+	}
+	workDir := restoreManager.WorkDir
+	if workDir == "" {
+		workDir = filepath.Dir(dgitDir)
+	}
+
+	var paths []string
+	for _, relPath := range written {
+		path := filepath.Join(workDir, filepath.FromSlash(relPath))
+		if stagingArea.HasFile(path) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	logManager := log.NewLogManager(dgitDir)
+	committed, err := status.NewStatusManager(dgitDir).GetSnapshotFileHashes(logManager.GetHeadVersion())
+	if err != nil {
+		committed = nil
+	}
+	restaged, dropped, err := stagingArea.RefreshFiles(paths, committed)
+	if saveErr := stagingArea.SaveStaging(); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		printWarning(fmt.Sprintf("failed to refresh the staging area: %v", err))
+		printSuggestion("Run 'dgit status' and re-add the restored files")
+		return
+	}
+	if len(restaged) > 0 {
+		printInfo(fmt.Sprintf("Re-staged %d restored file(s) with their new content: %v", len(restaged), restaged))
+	}
+	if len(dropped) > 0 {
+		printInfo(fmt.Sprintf("Unstaged %d restored file(s) that match HEAD again: %v", len(dropped), dropped))
+	}
+}
+
+// finishRestoreJournal drops the journal when every planned file was written, otherwise keeps it for --resume
+func finishRestoreJournal(restoreManager *restore.RestoreManager) bool {
+	if restoreManager.Journal == nil {
+		return true
+	}
+	if pending := restoreManager.Journal.Pending(); len(pending) > 0 {
+		printWarning(fmt.Sprintf("%d file(s) were not restored: %v", len(pending), pending))
+		printSuggestion("Run 'dgit restore --resume' to retry them")
+		return false
+	}
+	restoreManager.FinishJournal()
+	return true
+}
+
+// expandRestoreTargets replaces asset groups, sequence patterns and wildcards with the matching paths of a commit
+// Other arguments are passed through unchanged for the restore manager's own matching
+func expandRestoreTargets(dgitDir string, targetCommit *log.Commit, targets []string) ([]string, error) {
+	var committed []string
+	for path := range targetCommit.Metadata {
+		committed = append(committed, path)
+	}
+
+	var expanded []string
+	for _, target := range targets {
+		var matches []string
+		if g, err := group.NewGroupManager(dgitDir).GetGroup(target); err != nil {
+			return nil, err
+		} else if g != nil {
+			if matches = g.Filter(committed); len(matches) == 0 {
+				return nil, fmt.Errorf("v%d contains no files of asset group %s", targetCommit.Version, target)
+			}
+		}
+		for _, seq := range targetCommit.Sequences {
+			if target != seq.Pattern && target != filepath.Base(seq.Pattern) {
+				continue
+			}
+			frames := &staging.Sequence{Prefix: seq.Prefix, Suffix: seq.Suffix, Padding: seq.Padding}
+			for path := range targetCommit.Metadata {
+				if frames.Matches(path) {
+					matches = append(matches, path)
+				}
+			}
+		}
+		if len(matches) == 0 && strings.ContainsAny(target, "*?[") {
+			for path := range targetCommit.Metadata {
+				full, _ := filepath.Match(target, path)
+				base, _ := filepath.Match(target, filepath.Base(path))
+				if full || base {
+					matches = append(matches, path)
+				}
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files in v%d match %s", targetCommit.Version, target)
+			}
+		}
+		if len(matches) == 0 {
+			expanded = append(expanded, target)
+			continue
+		}
+		sort.Strings(matches)
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// restoredSize estimates the bytes written back by a restore from the sizes recorded in the commit
+func restoredSize(targetCommit *log.Commit, filesToRestore []string) int64 {
+	var total int64
+	for path, metadata := range targetCommit.Metadata {
+		if len(filesToRestore) > 0 {
+			wanted := false
+			for _, f := range filesToRestore {
+				if f == path || filepath.Base(f) == filepath.Base(path) {
+					wanted = true
+					break
+				}
+			}
+			if !wanted {
+				continue
+			}
+		}
+		if metaMap, ok := metadata.(map[string]interface{}); ok {
+			if size, ok := metaMap["size"].(float64); ok {
+				total += int64(size)
+			}
+		}
+	}
+	return total
 }
 
 // findTargetCommit finds a commit by hash or version number
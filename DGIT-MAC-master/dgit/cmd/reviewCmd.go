@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/review"
+
+	"github.com/spf13/cobra"
+)
+
+// ReviewCmd represents the review command for in-repository feedback threads
+// Unique to DGit - keeps design feedback with the versions it refers to instead of in chat
+var ReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Open, comment on, and close design reviews",
+	Long: `Manage lightweight review threads stored inside the repository.
+
+Each review is attached to a commit version and persisted under .dgit/reviews,
+so feedback travels with the repository.
+
+Examples:
+  dgit review                                        # List open reviews
+  dgit review open v9 -m "please check type kerning" # Request a review of v9
+  dgit review comment 3 -m "kerning fixed in header" # Reply to review #3
+  dgit review comment 3 -m "logo too small" --file hero.psd
+  dgit review show 3                                 # Show the full thread
+  dgit review close 3 -m "looks good"                # Resolve review #3`,
+	Run: runReviewList,
+}
+
+// reviewOpenCmd opens a new review on a commit
+var reviewOpenCmd = &cobra.Command{
+	Use:   "open <version_or_hash>",
+	Short: "Open a review on a commit",
+	Args:  cobra.ExactArgs(1),
+	Run:   runReviewOpen,
+}
+
+// reviewCommentCmd adds a comment to a review
+var reviewCommentCmd = &cobra.Command{
+	Use:   "comment <review_id>",
+	Short: "Comment on an open review",
+	Args:  cobra.ExactArgs(1),
+	Run:   runReviewComment,
+}
+
+// reviewCloseCmd closes a review
+var reviewCloseCmd = &cobra.Command{
+	Use:   "close <review_id>",
+	Short: "Close a review",
+	Args:  cobra.ExactArgs(1),
+	Run:   runReviewClose,
+}
+
+// reviewShowCmd displays a review thread
+var reviewShowCmd = &cobra.Command{
+	Use:   "show <review_id>",
+	Short: "Show a review thread",
+	Args:  cobra.ExactArgs(1),
+	Run:   runReviewShow,
+}
+
+// init sets up review subcommands and flags
+func init() {
+	ReviewCmd.Flags().Bool("all", false, "Include closed reviews")
+
+	for _, c := range []*cobra.Command{reviewOpenCmd, reviewCommentCmd, reviewCloseCmd} {
+		c.Flags().StringP("message", "m", "", "Review message")
+		c.Flags().String("by", "", "Author of the message (default: repository author)")
+	}
+	reviewCommentCmd.Flags().String("file", "", "Design file the comment refers to")
+
+	ReviewCmd.AddCommand(reviewOpenCmd)
+	ReviewCmd.AddCommand(reviewCommentCmd)
+	ReviewCmd.AddCommand(reviewCloseCmd)
+	ReviewCmd.AddCommand(reviewShowCmd)
+}
+
+// runReviewOpen opens a review on the given commit
+func runReviewOpen(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	targetCommit, err := findTargetCommit(log.NewLogManager(dgitDir), args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to find commit: %v", err), "")
+	}
+
+	message, _ := cmd.Flags().GetString("message")
+	r, err := review.NewReviewManager(dgitDir).Open(targetCommit, message, messageAuthor(cmd, dgitDir))
+	if err != nil {
+		exitWithError(fmt.Sprintf("opening review: %v", err), "Use -m to describe what should be reviewed")
+	}
+
+	printSuccess(fmt.Sprintf("Opened review #%d on v%d (%s)", r.ID, r.Version, r.CommitHash[:8]))
+	fmt.Printf("\"%s\"\n", r.Title)
+}
+
+// runReviewComment appends a comment to a review
+func runReviewComment(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	id := parseReviewID(args[0])
+
+	message, _ := cmd.Flags().GetString("message")
+	file, _ := cmd.Flags().GetString("file")
+	r, err := review.NewReviewManager(dgitDir).Comment(id, messageAuthor(cmd, dgitDir), message, file)
+	if err != nil {
+		exitWithError(fmt.Sprintf("commenting on review: %v", err), "")
+	}
+
+	printSuccess(fmt.Sprintf("Added comment to review #%d (%d comments)", r.ID, len(r.Comments)))
+}
+
+// runReviewClose closes a review
+func runReviewClose(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	id := parseReviewID(args[0])
+
+	message, _ := cmd.Flags().GetString("message")
+	r, err := review.NewReviewManager(dgitDir).Close(id, messageAuthor(cmd, dgitDir), message)
+	if err != nil {
+		exitWithError(fmt.Sprintf("closing review: %v", err), "")
+	}
+
+	printSuccess(fmt.Sprintf("Closed review #%d on v%d", r.ID, r.Version))
+}
+
+// runReviewShow prints a full review thread
+func runReviewShow(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	id := parseReviewID(args[0])
+
+	r, err := review.NewReviewManager(dgitDir).Get(id)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+
+	status := green(r.Status)
+	if r.Status == review.StatusClosed {
+		status = cyan(r.Status)
+	}
+	fmt.Printf("Review #%d on v%d (%s) [%s]\n", r.ID, r.Version, r.CommitHash[:8], status)
+	fmt.Printf("Opened by %s on %s\n\n", r.Author, r.CreatedAt.Format("Mon Jan 2 15:04:05 2006"))
+	fmt.Printf("    %s\n", r.Title)
+
+	for _, c := range r.Comments {
+		fmt.Println()
+		fmt.Printf("  %s - %s", bold(c.Author), c.Timestamp.Format("2006-01-02 15:04"))
+		if c.File != "" {
+			fmt.Printf(" on %s", c.File)
+		}
+		fmt.Println()
+		for _, line := range strings.Split(c.Message, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+// runReviewList lists reviews
+func runReviewList(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	includeClosed, _ := cmd.Flags().GetBool("all")
+
+	reviews, err := review.NewReviewManager(dgitDir).List(includeClosed)
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading reviews: %v", err), "")
+	}
+	if len(reviews) == 0 {
+		fmt.Println("No open reviews.")
+		return
+	}
+
+	for _, r := range reviews {
+		fmt.Printf("#%-4d v%-4d %-7s %s (%d comments, by %s)\n",
+			r.ID, r.Version, r.Status, r.Title, len(r.Comments), r.Author)
+	}
+}
+
+// parseReviewID parses a review ID argument such as "3" or "#3"
+func parseReviewID(arg string) int {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || id <= 0 {
+		exitWithError(fmt.Sprintf("invalid review id: %s", arg), "")
+	}
+	return id
+}
@@ -5,7 +5,7 @@ import (
 	"os"
 	"strings"
 
-	"dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
 	"github.com/spf13/cobra"
 )
 
@@ -46,7 +46,7 @@ func runScan(cmd *cobra.Command, args []string) {
 	fmt.Printf("Scanning design files in: %s\n", targetDir)
 
 	// Perform the actual directory scan
-	fileScanner := scanner.NewFileScanner()
+	fileScanner := scanner.NewCachedFileScanner(findDgitDirectory())
 	result, err := fileScanner.ScanDirectory(targetDir)
 	if err != nil {
 		printError(fmt.Sprintf("%v", err))
@@ -112,19 +112,30 @@ func printDesignFileInfo(file *scanner.DesignFile) {
 
 	// Display layer/artboard/object counts if available
 	// Build the details string dynamically based on what data is available
+	// Blender files carry scenes and collections in the artboard and layer fields
+	layerLabel, artboardLabel, namesLabel := "layers", "artboards", "Artboards"
+	if file.Type == "blend" {
+		layerLabel, artboardLabel, namesLabel = "collections", "scenes", "Scenes"
+	}
 	if file.Layers > 0 || file.Artboards > 0 || file.Objects > 0 {
 		var details []string
 		if file.Layers > 0 {
-			details = append(details, fmt.Sprintf("%d layers", file.Layers))
+			details = append(details, fmt.Sprintf("%d %s", file.Layers, layerLabel))
+		}
+		if file.Pages > 0 {
+			details = append(details, fmt.Sprintf("%d pages", file.Pages))
 		}
 		if file.Artboards > 0 {
-			details = append(details, fmt.Sprintf("%d artboards", file.Artboards))
+			details = append(details, fmt.Sprintf("%d %s", file.Artboards, artboardLabel))
 		}
 		if file.Objects > 0 {
 			details = append(details, fmt.Sprintf("%d objects", file.Objects))
 		}
 		fmt.Printf("   %s\n", strings.Join(details, " • "))
 	}
+	if len(file.ArtboardNames) > 0 {
+		fmt.Printf("   %s: %s\n", namesLabel, strings.Join(file.ArtboardNames, ", "))
+	}
 }
 
 // getFileTypeDisplay returns display string for file types
@@ -141,6 +152,8 @@ func getFileTypeDisplay(fileType string) string {
 		return "FIG"     // Figma
 	case "xd":
 		return "XD"      // Adobe XD
+	case "blend":
+		return "BLEND"   // Blender
 	case "afdesign", "afphoto":
 		return "AFFINITY" // Affinity Designer/Photo
 	default:
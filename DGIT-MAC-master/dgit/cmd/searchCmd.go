@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/search"
+
+	"github.com/spf13/cobra"
+)
+
+// SearchCmd represents the search command for full-text search across history
+// Unique to DGit - searches layer names and review notes, not just commit messages
+var SearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search commit messages, file names, layer names, and notes",
+	Long: `Search the repository history using the metadata index.
+
+Indexed: commit messages, file names, layer names, approval notes, and
+review threads. The index is a SQLite FTS5 table in .dgit/metadata.db (or
+.dgit/index/search.db on the JSON metadata backend), is refreshed after
+every commit, and only re-reads versions that changed.
+
+All words must match; the last word may be a prefix. Wrap the query in
+quotes (escaped for your shell) to require the exact phrase.
+
+Examples:
+  dgit search spring campaign              # Any history mentioning both words
+  dgit search '"spring campaign"'          # Exact phrase
+  dgit search hero --in layer              # Only layer names
+  dgit search kerning --in review,approval # Only review and approval notes
+  dgit search --reindex                    # Rebuild the index from scratch`,
+	Run: runSearch,
+}
+
+// init sets up command flags for search command
+func init() {
+	SearchCmd.Flags().StringSlice("in", nil, "Limit to fields: message, file, layer, approval, review")
+	SearchCmd.Flags().IntP("number", "n", 50, "Maximum number of results")
+	SearchCmd.Flags().Bool("reindex", false, "Rebuild the search index")
+}
+
+// runSearch executes the search command functionality
+func runSearch(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	index := search.NewSearchIndex(dgitDir)
+
+	if reindex, _ := cmd.Flags().GetBool("reindex"); reindex {
+		count, err := index.Rebuild()
+		if err != nil {
+			exitWithError(fmt.Sprintf("rebuilding search index: %v", err), "")
+		}
+		printSuccess(fmt.Sprintf("Indexed %d sources", count))
+		if len(args) == 0 {
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		exitWithError("no search query given", "Usage: dgit search <query>")
+	}
+
+	fields, _ := cmd.Flags().GetStringSlice("in")
+	hits, err := index.Search(strings.Join(args, " "), fields)
+	if err != nil {
+		exitWithError(fmt.Sprintf("searching: %v", err), "")
+	}
+	if len(hits) == 0 {
+		fmt.Println("No matches.")
+		return
+	}
+
+	limit, _ := cmd.Flags().GetInt("number")
+	for i, hit := range hits {
+		if limit > 0 && i == limit {
+			printInfo(fmt.Sprintf("... %d more matches (use -n to show more)", len(hits)-limit))
+			break
+		}
+		location := ""
+		if hit.Entry.Context != "" {
+			location = fmt.Sprintf(" (%s)", hit.Entry.Context)
+		}
+		fmt.Printf("v%-4d %-8s %s%s\n", hit.Version, hit.Entry.Field, hit.Entry.Text, location)
+	}
+}
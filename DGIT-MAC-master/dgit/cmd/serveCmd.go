@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/accounting"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/api"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/remote"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/share"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/verify"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServeURL is the base URL used for share links when none is given
+const defaultServeURL = "http://localhost:8080"
+
+// ServeCmd represents the serve command for exposing the repository over HTTP
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve share links, the remote, webhooks and the API over HTTP",
+	Long: `Start an HTTP server for the repository. It hosts:
+
+  Share links    Files behind valid, unexpired links created with 'dgit share';
+                 a missing, tampered, or expired signature is rejected
+  Remote         'dgit push', 'dgit pull' and 'dgit clone' from other machines
+                 (see 'dgit remote')
+  Webhooks       POST /webhook/<action> runs an allowlisted action
+                 (see 'dgit serve webhook')
+  API            GET /api/state reads the repository state (see 'dgit api dump')
+                 and GET /api/file/v<N>/<path> streams a committed file
+                 without restoring it
+
+Everything except share links requires the repository's webhook token.
+
+Examples:
+  dgit serve                   # Listen on :8080
+  dgit serve --addr :9000      # Listen on a different port`,
+	Args: cobra.NoArgs,
+	Run:  runServe,
+}
+
+// serveWebhookCmd configures the inbound webhook endpoint
+var serveWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Show or change the webhook token and allowed actions",
+	Long: `Show the token and allowlist for POST /webhook/<action>.
+
+Actions:
+  ingest   Process the watch-folder ingest rules and commit settled files
+  verify   Check the storage of every version
+  sync     Fetch linked assets and update submodules
+  figma    Commit new named versions of the synced Figma files
+
+Examples:
+  dgit serve webhook                        # Show token and allowed actions
+  dgit serve webhook --allow ingest,verify  # Allow actions
+  dgit serve webhook --deny ingest          # Disallow an action
+  dgit serve webhook --rotate-token         # Invalidate the current token`,
+	Args: cobra.NoArgs,
+	Run:  runServeWebhook,
+}
+
+// init sets up command flags for serve command
+func init() {
+	ServeCmd.Flags().String("addr", ":8080", "Address to listen on")
+
+	serveWebhookCmd.Flags().StringSlice("allow", nil, "Add actions to the allowlist")
+	serveWebhookCmd.Flags().StringSlice("deny", nil, "Remove actions from the allowlist")
+	serveWebhookCmd.Flags().Bool("rotate-token", false, "Generate a new token")
+	ServeCmd.AddCommand(serveWebhookCmd)
+}
+
+// runServe executes the serve command functionality
+func runServe(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	addr, _ := cmd.Flags().GetString("addr")
+
+	mux := http.NewServeMux()
+	mux.Handle("/share/", &shareHandler{
+		manager: share.NewShareManager(dgitDir),
+		usage:   accounting.NewAccountingManager(dgitDir),
+	})
+	mux.Handle("/webhook/", &webhookHandler{
+		dgitDir: dgitDir,
+		manager: webhook.NewWebhookManager(dgitDir),
+	})
+	mux.Handle("/api/state", &stateHandler{
+		manager: api.NewAPIManager(dgitDir),
+		tokens:  webhook.NewWebhookManager(dgitDir),
+	})
+	mux.Handle("/api/file/", &fileHandler{
+		manager: api.NewAPIManager(dgitDir),
+		tokens:  webhook.NewWebhookManager(dgitDir),
+	})
+	mux.Handle("/remote/v1/", remote.NewHandler(dgitDir))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	printSuccess(fmt.Sprintf("Serving %s on %s", filepath.Dir(dgitDir), addr))
+	if allowed, _ := webhook.NewWebhookManager(dgitDir).GetAllowed(); len(allowed) > 0 {
+		printInfo(fmt.Sprintf("Webhook actions allowed: %s", strings.Join(allowed, ", ")))
+	}
+	if err := server.ListenAndServe(); err != nil {
+		printError(fmt.Sprintf("server stopped: %v", err))
+		os.Exit(1)
+	}
+}
+
+// shareHandler serves files behind presigned share links
+type shareHandler struct {
+	manager *share.ShareManager
+	usage   *accounting.AccountingManager
+}
+
+// countingWriter counts the body bytes sent to the client
+type countingWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+// Write forwards to the response and counts the bytes
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.ResponseWriter.Write(p)
+	cw.written += int64(n)
+	return n, err
+}
+
+// ServeHTTP handles /share/v<N>/<file>?expires=...&mode=...&sig=...
+func (h *shareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/share/")
+	versionPart, file, ok := strings.Cut(rest, "/")
+	version, err := strconv.Atoi(strings.TrimPrefix(versionPart, "v"))
+	if !ok || err != nil || file == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	link, err := h.manager.Verify(version, file, query.Get("mode"), query.Get("expires"), query.Get("sig"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	scratchDir, filePath, err := h.manager.Extract(link)
+	if err != nil {
+		printError(fmt.Sprintf("share v%d/%s: %v", link.Version, link.File, err))
+		http.Error(w, "file unavailable", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(scratchDir)
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "file unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	disposition := "attachment"
+	if link.Mode == share.ModePreview {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{
+		"filename": filepath.Base(link.File),
+	}))
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	started := time.Now()
+	counter := &countingWriter{ResponseWriter: w}
+	http.ServeContent(counter, r, filepath.Base(link.File), info.ModTime(), f)
+	if counter.written > 0 {
+		h.usage.Record(accounting.Event{
+			Operation:        accounting.OpServe,
+			Version:          link.Version,
+			BytesTransferred: counter.written,
+			DurationMs:       float64(time.Since(started).Microseconds()) / 1000,
+		})
+	}
+}
+
+// runServeWebhook updates and shows the webhook configuration
+func runServeWebhook(cmd *cobra.Command, args []string) {
+	manager := webhook.NewWebhookManager(checkDgitRepository())
+	allow, _ := cmd.Flags().GetStringSlice("allow")
+	deny, _ := cmd.Flags().GetStringSlice("deny")
+	rotate, _ := cmd.Flags().GetBool("rotate-token")
+
+	if len(allow) > 0 {
+		if err := manager.SetAllowed(allow, true); err != nil {
+			exitWithError(err.Error(), "")
+		}
+	}
+	if len(deny) > 0 {
+		if err := manager.SetAllowed(deny, false); err != nil {
+			exitWithError(err.Error(), "")
+		}
+	}
+
+	var token string
+	var err error
+	if rotate {
+		token, err = manager.RotateToken()
+	} else {
+		token, err = manager.Token()
+	}
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if rotate {
+		printSuccess("Generated a new webhook token; the old one no longer works")
+	}
+
+	allowed, err := manager.GetAllowed()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading config: %v", err), "")
+	}
+	fmt.Printf("Token:   %s\n", token)
+	if len(allowed) == 0 {
+		fmt.Println("Allowed: (none)")
+		printSuggestion("Allow actions with 'dgit serve webhook --allow " + strings.Join(webhook.Actions, ",") + "'")
+		return
+	}
+	fmt.Printf("Allowed: %s\n\n", strings.Join(allowed, ", "))
+	fmt.Printf("  curl -X POST -H 'Authorization: Bearer %s' %s/webhook/%s\n", token, defaultServeURL, allowed[0])
+}
+
+// webhookHandler runs allowlisted actions for external systems: POST /webhook/<action>
+type webhookHandler struct {
+	dgitDir string
+	manager *webhook.WebhookManager
+	running sync.Mutex // One action at a time; ingest changes the working directory
+}
+
+// webhookResponse is the JSON body returned to the caller
+type webhookResponse struct {
+	Action string `json:"action"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServeHTTP authenticates the caller, checks the allowlist and runs the action synchronously
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.manager.CheckToken(requestToken(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	action := strings.TrimPrefix(r.URL.Path, "/webhook/")
+	if !webhook.IsAction(action) {
+		http.NotFound(w, r)
+		return
+	}
+	if allowed, err := h.manager.IsAllowed(action); err != nil || !allowed {
+		http.Error(w, fmt.Sprintf("action %s is not allowed", action), http.StatusForbidden)
+		return
+	}
+
+	if !h.running.TryLock() {
+		http.Error(w, "another action is running", http.StatusConflict)
+		return
+	}
+	defer h.running.Unlock()
+
+	printInfo(fmt.Sprintf("webhook %s from %s", action, r.RemoteAddr))
+	response := webhookResponse{Action: action}
+	status := http.StatusOK
+	detail, err := runWebhookAction(h.dgitDir, action)
+	response.Detail = detail
+	if err != nil {
+		response.Error = err.Error()
+		status = http.StatusInternalServerError
+		printWarning(fmt.Sprintf("webhook %s: %v", action, err))
+	} else {
+		response.OK = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// runWebhookAction performs one action and summarizes the outcome
+func runWebhookAction(dgitDir, action string) (string, error) {
+	switch action {
+	case webhook.ActionIngest:
+		if err := ingestOnce(dgitDir, false, false); err != nil {
+			return "", err
+		}
+		return "ingest pass complete", nil
+	case webhook.ActionVerify:
+		commits, err := log.NewLogManager(dgitDir).GetCommitHistory()
+		if err != nil {
+			return "", err
+		}
+		manager := verify.NewVerifyManager(dgitDir)
+		var results []*verify.VersionResult
+		failed := 0
+		for _, commit := range verify.Sample(commits, 0, 0) {
+			result := manager.CheckStorage(commit)
+			if !result.OK() {
+				failed++
+			}
+			results = append(results, result)
+		}
+		manager.RecordRun(results, false)
+		detail := fmt.Sprintf("%d version(s) checked, %d failed", len(results), failed)
+		if failed > 0 {
+			return detail, fmt.Errorf("verification failed")
+		}
+		return detail, nil
+	case webhook.ActionSync:
+		linkFailures, err := linked.NewLinkManager(dgitDir).FetchAll(nil)
+		if err != nil {
+			return "", err
+		}
+		moduleFailures, err := submodule.NewSubmoduleManager(dgitDir).UpdateAll()
+		if err != nil {
+			return "", err
+		}
+		failures := len(linkFailures) + len(moduleFailures)
+		detail := fmt.Sprintf("linked assets and submodules updated, %d failure(s)", failures)
+		if failures > 0 {
+			return detail, fmt.Errorf("some assets could not be updated")
+		}
+		return detail, nil
+	case webhook.ActionFigma:
+		if err := figmaSyncOnce(dgitDir, false); err != nil {
+			return "", err
+		}
+		return "figma sync complete", nil
+	}
+	return "", fmt.Errorf("unknown action %s", action)
+}
+
+// requestToken reads the token from "Authorization: Bearer" or X-DGit-Token
+func requestToken(r *http.Request) string {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.Header.Get("X-DGit-Token")
+	}
+	return token
+}
+
+// stateHandler serves the 'dgit api dump' document to polling integrations
+type stateHandler struct {
+	manager *api.APIManager
+	tokens  *webhook.WebhookManager
+}
+
+// ServeHTTP handles GET /api/state, answering 304 while the ETag still matches
+func (h *stateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.tokens.CheckToken(requestToken(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	doc, err := h.manager.Dump()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	etag := api.ETag(doc)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	json.NewEncoder(w).Encode(doc)
+}
+
+// fileHandler streams committed file content to preview servers and converters
+type fileHandler struct {
+	manager *api.APIManager
+	tokens  *webhook.WebhookManager
+}
+
+// ServeHTTP handles GET /api/file/v<N>/<path>, decompressing only what the file needs
+func (h *fileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.tokens.CheckToken(requestToken(r)); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/api/file/")
+	versionPart, file, ok := strings.Cut(rest, "/")
+	version, err := strconv.Atoi(strings.TrimPrefix(versionPart, "v"))
+	if !ok || err != nil || file == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	reader, err := h.manager.OpenFile(version, file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer reader.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err := io.Copy(w, reader); err != nil {
+		printError(fmt.Sprintf("file v%d/%s: %v", version, file, err))
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/share"
+
+	"github.com/spf13/cobra"
+)
+
+// ShareCmd represents the share command for creating presigned download links
+// Unique to DGit - lets clients download exactly one version of one file without repository access
+var ShareCmd = &cobra.Command{
+	Use:   "share <version_or_hash> <file>",
+	Short: "Create a time-limited link to a file version",
+	Long: `Create a signed, time-limited URL for a single file of a single version.
+
+The link is served by 'dgit serve' and stops working once it expires.
+Recipients can only download the shared file - nothing else in the repository.
+
+Examples:
+  dgit share v9 hero.psd --expires 72h             # Download link valid for 3 days
+  dgit share v9 hero.psd --preview                 # Open inline in the browser
+  dgit share v9 hero.psd --base-url https://files.studio.example
+  dgit share --list                                # Show links that are still valid`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Run: runShare,
+}
+
+// init sets up command flags for share command
+func init() {
+	ShareCmd.Flags().Duration("expires", 72*time.Hour, "How long the link stays valid (e.g. 24h, 72h)")
+	ShareCmd.Flags().Bool("preview", false, "Serve the file inline for viewing instead of as a download")
+	ShareCmd.Flags().String("base-url", defaultServeURL, "Public URL of the 'dgit serve' server")
+	ShareCmd.Flags().Bool("list", false, "List share links that have not expired")
+}
+
+// runShare executes the share command functionality
+func runShare(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := share.NewShareManager(dgitDir)
+	baseURL, _ := cmd.Flags().GetString("base-url")
+
+	if list, _ := cmd.Flags().GetBool("list"); list {
+		links, err := manager.GetShares(true)
+		if err != nil {
+			exitWithError(fmt.Sprintf("loading share links: %v", err), "")
+		}
+		if len(links) == 0 {
+			fmt.Println("No active share links.")
+			return
+		}
+		for _, link := range links {
+			fmt.Printf("v%-4d %-30s %-8s expires %s\n", link.Version, link.File, link.Mode,
+				link.ExpiresAt.Format("2006-01-02 15:04"))
+		}
+		return
+	}
+
+	targetCommit, err := findTargetCommit(log.NewLogManager(dgitDir), args[0])
+	if err != nil {
+		exitWithError(fmt.Sprintf("Failed to find commit: %v", err), "")
+	}
+
+	expires, _ := cmd.Flags().GetDuration("expires")
+	mode := share.ModeDownload
+	if preview, _ := cmd.Flags().GetBool("preview"); preview {
+		mode = share.ModePreview
+	}
+
+	link, err := manager.Create(targetCommit, args[1], expires, mode)
+	if err != nil {
+		exitWithError(fmt.Sprintf("creating share link: %v", err), "Use 'dgit log' to check which versions exist")
+	}
+
+	printSuccess(fmt.Sprintf("Shared %s from v%d (expires %s)", link.File, link.Version,
+		link.ExpiresAt.Format("2006-01-02 15:04")))
+	fmt.Println(manager.URL(baseURL, link))
+	printInfo("Run 'dgit serve' so the link can be opened")
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/thumbnail"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// ShowCmd represents the show command for looking at one version without restoring it
+var ShowCmd = &cobra.Command{
+	Use:   "show <version> [file]",
+	Short: "Show the full details of a version or the thumbnail of one of its files",
+	Long: `Show everything recorded for a committed version: message, author, how it
+was stored (strategy, sizes, compression time, cache tier) and each file's
+design metadata (dimensions, color mode, layers, artboards, size).
+
+With --thumbnail, show the preview the authoring application embedded in the
+file (PSD, AI, Sketch, Figma, XD, Blender). Thumbnails are extracted at commit
+time into .dgit/objects/previews/v<N>/; older versions are extracted on first use.
+When output is redirected, the image itself is written.
+
+Examples:
+  dgit show v5                               # Full details of v5
+  dgit show v5 --thumbnail hero.psd          # Where v5's thumbnail of hero.psd is stored
+  dgit show v5 --thumbnail hero.psd -o v5.jpg
+  dgit show v5 --thumbnail hero.psd > v5.jpg`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runShow,
+}
+
+// init sets up show command flags
+func init() {
+	ShowCmd.Flags().Bool("thumbnail", false, "Show the embedded thumbnail of the file instead of the version details")
+	ShowCmd.Flags().StringP("output", "o", "", "Write the thumbnail to this file")
+}
+
+// runShow prints a version or one file's thumbnail
+func runShow(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	version, err := parseVersionArg(args[0])
+	if err != nil {
+		exitWithError(err.Error(), "Use a version such as v5")
+	}
+	commit, err := log.NewLogManager(dgitDir).GetCommit(version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("v%d not found: %v", version, err), "Run 'dgit log' to list versions")
+	}
+
+	if showThumbnail, _ := cmd.Flags().GetBool("thumbnail"); showThumbnail {
+		if len(args) != 2 {
+			exitWithError("--thumbnail needs a version and a file", "dgit show v5 --thumbnail hero.psd")
+		}
+		output, _ := cmd.Flags().GetString("output")
+		showCommitThumbnail(dgitDir, commit, args[1], output)
+		return
+	}
+	printShowCommit(dgitDir, commit)
+}
+
+// showCommitThumbnail writes or locates the thumbnail of one file in a version
+func showCommitThumbnail(dgitDir string, commit *log.Commit, file, output string) {
+	source, err := preview.CommittedPath(commit, file)
+	if err != nil {
+		exitWithError(err.Error(), fmt.Sprintf("Run 'dgit show v%d' to list its files", commit.Version))
+	}
+	// Versions committed before thumbnails were kept are read back from storage, quietly
+	var thumb *thumbnail.Thumbnail
+	withQuietStdout(func() { thumb, err = thumbnail.NewThumbnailManager(dgitDir).ForVersion(commit.Version, source) })
+	if err != nil {
+		exitWithError(err.Error(), "Configure a converter with 'dgit preview add' to render this file type")
+	}
+
+	switch {
+	case output != "":
+		if err := copyThumbnail(thumb.Path, output); err != nil {
+			exitWithError(fmt.Sprintf("writing %s: %v", output, err), "")
+		}
+		printSuccess(fmt.Sprintf("Wrote the v%d thumbnail of %s to %s (%s)", commit.Version, source, output, util.FormatBytes(thumb.Size)))
+	case isTerminal(os.Stdout):
+		fmt.Printf("v%d %s\n", commit.Version, source)
+		fmt.Printf("   %s • %s\n", thumb.Path, util.FormatBytes(thumb.Size))
+		printSuggestion(fmt.Sprintf("open %q", thumb.Path))
+	default:
+		if err := copyThumbnail(thumb.Path, ""); err != nil {
+			exitWithError(fmt.Sprintf("writing thumbnail: %v", err), "")
+		}
+	}
+}
+
+// copyThumbnail copies a stored thumbnail to a file, or to stdout when output is empty
+func copyThumbnail(path, output string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if output == "" {
+		_, err = io.Copy(os.Stdout, in)
+		return err
+	}
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// printShowCommit prints everything recorded for a version: header, storage and compression, and each file's metadata
+func printShowCommit(dgitDir string, c *log.Commit) {
+	logManager := log.NewLogManager(dgitDir)
+	fmt.Printf("commit %s (v%d)\n", c.Hash[:12], c.Version)
+	if c.ParentHash != "" {
+		fmt.Printf("Parent: %s\n", c.ParentHash[:min(12, len(c.ParentHash))])
+	}
+	if c.Branch != "" {
+		fmt.Printf("Branch: %s\n", c.Branch)
+	}
+	if c.ImportedFrom != nil {
+		fmt.Printf("Imported: v%d of %s\n", c.ImportedFrom.Version, c.ImportedFrom.Repository)
+	}
+	fmt.Printf("Author: %s\n", formatCommitAuthor(c.Author, c.Email, c.AuthorSource))
+	fmt.Printf("Date: %s\n", c.Timestamp.Format("Mon Jan 2 15:04:05 2006"))
+	if c.Session != "" {
+		fmt.Printf("Session: %s\n", c.Session)
+	}
+	fmt.Printf("\n    %s\n\n", c.Message)
+
+	printShowStorage(logManager, c)
+
+	paths := make([]string, 0, len(c.Metadata))
+	for path := range c.Metadata {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Printf("Files (%d):\n", len(paths))
+	thumbnails := thumbnail.NewThumbnailManager(dgitDir)
+	for _, path := range paths {
+		line := fmt.Sprintf("   [%s] %s", getFileType(path), path)
+		if from, ok := c.Renames[path]; ok && from != path {
+			line += " (renamed from " + from + ")"
+		}
+		if _, err := thumbnails.Get(c.Version, path); err == nil {
+			line += " • thumbnail"
+		}
+		fmt.Println(line)
+		if meta, ok := c.Metadata[path].(map[string]interface{}); ok {
+			if details := showFileDetails(meta); details != "" {
+				fmt.Printf("       %s\n", details)
+			}
+		}
+		if ratio, ok := compressionRatio(c, path); ok {
+			fmt.Printf("       Stored at %.1f%% of its size\n", ratio*100)
+		}
+	}
+	for _, seq := range c.Sequences {
+		fmt.Printf("   Sequence: %s (%d frames, %0*d-%0*d)\n", seq.Pattern, seq.Frames, seq.Padding, seq.First, seq.Padding, seq.Last)
+	}
+	for _, asset := range c.LinkedAssets {
+		fmt.Printf("   Linked: %s is v%d of %s in library %s\n", asset.Path, asset.Version, asset.SourcePath, asset.Library)
+	}
+}
+
+// printShowStorage prints how a version was stored: strategy, sizes, timing and cache tier
+func printShowStorage(logManager *log.LogManager, c *log.Commit) {
+	fmt.Println("Storage:")
+	fmt.Printf("   %s\n", logManager.GetCommitStorageInfo(c))
+	info := c.CompressionInfo
+	if info == nil {
+		fmt.Println()
+		return
+	}
+	fmt.Printf("   Strategy: %s\n", info.Strategy)
+	if info.OriginalSize > 0 {
+		fmt.Printf("   Size: %s → %s (%s)\n", util.FormatBytes(info.OriginalSize), util.FormatBytes(info.CompressedSize),
+			logManager.GetCommitEfficiency(c))
+	}
+	if info.BaseVersion > 0 {
+		fmt.Printf("   Delta base: v%d\n", info.BaseVersion)
+	}
+	if info.ReusedFiles > 0 {
+		fmt.Printf("   Reused: %d file(s) already stored\n", info.ReusedFiles)
+	}
+	if info.CompressionTime > 0 {
+		timing := fmt.Sprintf("%.1fms", info.CompressionTime)
+		if info.SpeedImprovement > 0 {
+			timing += fmt.Sprintf(" (%.1fx faster)", info.SpeedImprovement)
+		}
+		fmt.Printf("   Time: %s\n", timing)
+	}
+	if info.CacheLevel != "" {
+		fmt.Printf("   Cache tier: %s\n", info.CacheLevel)
+	}
+	if info.SkipOptimization {
+		fmt.Println("   Stored without compression (content is incompressible)")
+	}
+	fmt.Println()
+}
+
+// showFileDetails summarizes a file's design metadata: dimensions, color mode, layers, artboards and size
+func showFileDetails(meta map[string]interface{}) string {
+	var details []string
+	if dimensions, _ := meta["dimensions"].(string); dimensions != "" && dimensions != "Unknown" {
+		details = append(details, dimensions)
+	}
+	if mode, _ := meta["color_mode"].(string); mode != "" && mode != "Unknown" {
+		details = append(details, mode)
+	}
+	for _, field := range []string{"layers", "artboards", "pages", "objects"} {
+		if count, _ := meta[field].(float64); count > 0 {
+			details = append(details, fmt.Sprintf("%.0f %s", count, field))
+		}
+	}
+	if version, _ := meta["version"].(string); version != "" && version != "Unknown" {
+		details = append(details, version)
+	}
+	if size, _ := meta["size"].(float64); size > 0 {
+		details = append(details, util.FormatBytes(int64(size)))
+	}
+	return strings.Join(details, " • ")
+}
+
+// compressionRatio returns the compressed/original ratio recorded for a file at commit time
+func compressionRatio(c *log.Commit, path string) (float64, bool) {
+	if c.CompressionInfo == nil {
+		return 0, false
+	}
+	ratio, ok := c.CompressionInfo.FileRatios[path]
+	return ratio, ok
+}
+
+// isTerminal reports whether a file is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
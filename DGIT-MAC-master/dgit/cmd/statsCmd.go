@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/stats"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// StatsCmd represents the stats command for repository storage statistics
+// Helps studios plan disk usage for large design histories
+var StatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show repository storage statistics",
+	Long: `Show how much space the repository uses and how it is likely to grow.
+
+The default report covers storage by category, space saved by compression,
+which strategies and cache tiers commits used, and average compression time.
+
+Examples:
+  dgit stats                             # Storage breakdown and compression analytics
+  dgit stats --json                      # The same report as JSON
+  dgit stats --forecast                  # Projected size in 30/90/365 days
+  dgit stats --forecast --budget 50GB    # ...and settings to stay within 50 GB
+  dgit stats --top                       # Largest objects, busiest files, worst compression
+  dgit stats --top -n 20                 # Longer lists`,
+	Run: runStats,
+}
+
+// init sets up command flags for stats command
+func init() {
+	StatsCmd.Flags().Bool("forecast", false, "Project repository size from the recent commit cadence")
+	StatsCmd.Flags().String("budget", "", "Storage budget for --forecast (e.g. 500MB, 50GB)")
+	StatsCmd.Flags().Bool("top", false, "List the largest objects, most modified files, and worst-compressing files")
+	StatsCmd.Flags().IntP("number", "n", 10, "Entries per list for --top")
+	StatsCmd.Flags().Bool("json", false, "Print the storage and compression report as JSON")
+}
+
+// statsReport is the default stats report, as printed by --json
+type statsReport struct {
+	LatestVersion int                                 `json:"latest_version"`
+	Size          *log.SizeBreakdown                  `json:"size"`
+	Compression   *log.UltraFastCompressionStatistics `json:"compression"`
+	Cache         *log.CacheUtilization               `json:"cache"`
+}
+
+// runStats executes the stats command functionality
+func runStats(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	if forecast, _ := cmd.Flags().GetBool("forecast"); forecast {
+		runStatsForecast(cmd, dgitDir)
+		return
+	}
+	if top, _ := cmd.Flags().GetBool("top"); top {
+		runStatsTop(cmd, dgitDir)
+		return
+	}
+
+	logManager := log.NewLogManager(dgitDir)
+	breakdown, err := logManager.GetRepositorySizeBreakdown()
+	if err != nil {
+		exitWithError(fmt.Sprintf("measuring repository: %v", err), "")
+	}
+	compression, err := logManager.GetUltraFastCompressionStatistics()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading history: %v", err), "")
+	}
+	cache, err := logManager.GetCacheUtilization()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading history: %v", err), "")
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		report := statsReport{LatestVersion: logManager.GetCurrentVersion(), Size: breakdown, Compression: compression, Cache: cache}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Sprintf("encoding report: %v", err), "")
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("Commits:     %d (latest v%d)\n", compression.TotalCommits, logManager.GetCurrentVersion())
+	fmt.Printf("Total size:  %s\n\n", bold(util.FormatBytes(breakdown.Total)))
+	fmt.Printf("  Metadata     %10s\n", util.FormatBytes(breakdown.Metadata))
+	fmt.Printf("  Snapshots    %10s\n", util.FormatBytes(breakdown.ZipFiles))
+	fmt.Printf("  Deltas       %10s\n", util.FormatBytes(breakdown.DeltaFiles))
+	fmt.Printf("  Object store %10s\n", util.FormatBytes(breakdown.ObjectBlobs))
+	fmt.Printf("  Hot cache    %10s  %4d commit(s)\n", util.FormatBytes(breakdown.HotCache), cache.HotCacheFiles)
+	fmt.Printf("  Warm cache   %10s  %4d commit(s)\n", util.FormatBytes(breakdown.WarmCache), cache.WarmCacheFiles)
+	fmt.Printf("  Cold cache   %10s  %4d commit(s)\n", util.FormatBytes(breakdown.ColdCache), cache.ColdCacheFiles)
+	if compression.TotalSavedSpace > 0 {
+		fmt.Printf("\nCompression saved %s\n", util.FormatBytes(compression.TotalSavedSpace))
+	}
+	printStrategyStats(compression)
+}
+
+// printStrategyStats prints how many commits used each storage strategy and how fast compression was
+func printStrategyStats(compression *log.UltraFastCompressionStatistics) {
+	if compression.UltraFastCommits == 0 {
+		return
+	}
+	strategies := make([]string, 0, len(compression.StrategyStats))
+	for strategy := range compression.StrategyStats {
+		strategies = append(strategies, strategy)
+	}
+	// Most used first, then by name
+	sort.Slice(strategies, func(i, j int) bool {
+		a, b := compression.StrategyStats[strategies[i]], compression.StrategyStats[strategies[j]]
+		if a != b {
+			return a > b
+		}
+		return strategies[i] < strategies[j]
+	})
+
+	fmt.Println()
+	fmt.Println(bold("Strategies"))
+	for _, strategy := range strategies {
+		count := compression.StrategyStats[strategy]
+		share := float64(count) * 100 / float64(compression.UltraFastCommits)
+		fmt.Printf("  %-20s %4d commit(s)  %5.1f%%\n", strategy, count, share)
+	}
+	if compression.LegacyCommits > 0 {
+		fmt.Printf("  %-20s %4d commit(s)\n", "legacy (no metrics)", compression.LegacyCommits)
+	}
+
+	fmt.Printf("\nAverage compression time: %.1fms\n", compression.AvgCompressionTime)
+	if compression.TotalSpeedImprovement > 0 {
+		fmt.Printf("Average speed-up:         %.1fx\n", compression.TotalSpeedImprovement)
+	}
+}
+
+// runStatsForecast prints projected repository growth
+func runStatsForecast(cmd *cobra.Command, dgitDir string) {
+	var budget int64
+	if value, _ := cmd.Flags().GetString("budget"); value != "" {
+		parsed, err := stats.ParseSize(value)
+		if err != nil {
+			exitWithError(err.Error(), "")
+		}
+		budget = parsed
+	}
+
+	forecast, err := stats.NewStatsManager(dgitDir).Forecast(stats.ForecastHorizons, budget)
+	if err != nil {
+		exitWithError(fmt.Sprintf("forecasting storage: %v", err), "")
+	}
+
+	fmt.Printf("Current size: %s\n", bold(util.FormatBytes(forecast.CurrentSize)))
+	if forecast.WindowCommits == 0 {
+		printInfo("No commits in the last 90 days - size is not expected to grow")
+		return
+	}
+	fmt.Printf("Cadence:      %.1f commits/day, %s per commit (%d commits since %s)\n\n",
+		forecast.CommitsPerDay, util.FormatBytes(forecast.BytesPerCommit), forecast.WindowCommits,
+		forecast.WindowStart.Format("2006-01-02"))
+
+	for _, p := range forecast.Projections {
+		line := fmt.Sprintf("  in %3d days  %10s", p.Days, util.FormatBytes(p.Size))
+		if budget > 0 && p.Size > budget {
+			line += "  " + red("over budget")
+		}
+		fmt.Println(line)
+	}
+
+	if budget == 0 {
+		return
+	}
+	fmt.Printf("\nBudget: %s", util.FormatBytes(budget))
+	switch forecast.DaysUntilBudget {
+	case -1:
+		fmt.Println(" (not reached at this cadence)")
+	case 0:
+		fmt.Println(" " + red("(already exceeded)"))
+	default:
+		fmt.Printf(" (reached in about %d days)\n", forecast.DaysUntilBudget)
+	}
+	for _, suggestion := range forecast.Suggestions {
+		printSuggestion(suggestion)
+	}
+}
+
+// runStatsTop prints the largest-object and hot-file report
+func runStatsTop(cmd *cobra.Command, dgitDir string) {
+	number, _ := cmd.Flags().GetInt("number")
+	report, err := stats.NewStatsManager(dgitDir).Top(number)
+	if err != nil {
+		exitWithError(fmt.Sprintf("building report: %v", err), "")
+	}
+
+	fmt.Println(bold("Largest objects"))
+	for _, o := range report.LargestObjects {
+		version := ""
+		if o.Version > 0 {
+			version = fmt.Sprintf("v%d", o.Version)
+		}
+		fmt.Printf("  %10s  %-5s %s\n", util.FormatBytes(o.Size), version, o.Path)
+	}
+
+	fmt.Println()
+	fmt.Println(bold("Most frequently modified files"))
+	for _, a := range report.MostModified {
+		fmt.Printf("  %4d commits  %10s  %s (last v%d)\n", a.Commits, util.FormatBytes(a.LatestSize), a.Path, a.LastVersion)
+	}
+
+	fmt.Println()
+	fmt.Println(bold("Worst compression ratios"))
+	if len(report.WorstCompressed) == 0 {
+		fmt.Println("  No compression data recorded")
+	}
+	for _, fc := range report.WorstCompressed {
+		saved := (1 - fc.Ratio()) * 100
+		fmt.Printf("  %5.1f%% saved  %10s  %s (%d commits)\n", saved, util.FormatBytes(fc.OriginalSize), fc.Path, fc.Commits)
+	}
+	if len(report.WorstCompressed) > 0 && report.WorstCompressed[0].Ratio() >= 0.95 {
+		printSuggestion("Files that barely compress are already compressed formats; consider chunking or offloading them")
+	}
+}
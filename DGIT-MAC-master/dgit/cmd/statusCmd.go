@@ -5,11 +5,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"dgit/internal/log"
-	"dgit/internal/scanner"
-	"dgit/internal/staging"
-	"dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/group"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/health"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/pin"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
 	
 	"github.com/spf13/cobra"
 )
@@ -20,10 +26,15 @@ var StatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show the working tree status",
 	Long: `Display the current status of the repository including:
+- A health line: cache tiers within their limits, when 'dgit verify'
+  last ran and whether it passed, and space held by trash and
+  unreferenced storage objects
 - Files staged for commit
 - Modified files not yet staged  
 - Untracked design files
 - Deleted files
+- With --verbose, staging cache statistics: adds served from the
+  hot/warm/cold cache versus newly cached files
 
 DGit shows metadata changes for design files:
 - Layer count changes
@@ -33,6 +44,11 @@ DGit shows metadata changes for design files:
 	Run: runStatus,
 }
 
+// init sets up command flags for status command
+func init() {
+	StatusCmd.Flags().BoolP("verbose", "v", false, "Show staging cache statistics")
+}
+
 // runStatus executes the status command functionality
 // Shows comprehensive status including design file metadata changes
 func runStatus(cmd *cobra.Command, args []string) {
@@ -51,32 +67,29 @@ func runStatus(cmd *cobra.Command, args []string) {
 	}
 
 	// Get current version info and display branch-like status
-	currentVersion := logManager.GetCurrentVersion()
-	fmt.Printf("On version %d\n\n", currentVersion+1) // Next version number
+	currentVersion := logManager.GetHeadVersion()
+	fmt.Printf("On branch %s\n", logManager.CurrentBranch())
+	fmt.Printf("On version %d\n", logManager.GetCurrentVersion()+1) // Next version number
+	printHealthLine(health.NewHealthManager(dgitDir).Check(time.Now()))
+	fmt.Println()
 	
 	// Display staged files if any exist
 	if !stagingArea.IsEmpty() {
 		fmt.Println("Changes to be committed:")
+		fmt.Println("  (use 'dgit unstage <file>' to unstage)")
 		printStatusStagingStatus(stagingArea)
 		fmt.Println()
 	} else {
 		fmt.Println("No changes staged for commit.")
 		fmt.Println()
 	}
-
-	// Scan current working directory for design files
-	currentWorkDir, _ := os.Getwd()
-	currentDirFiles := scanCurrentDirectory(currentWorkDir)
-
-	// Compare current files with last commit to detect changes
-	result, err := statusManager.CompareWithCommit(currentVersion, currentDirFiles)
-	if err != nil {
-		printWarning(fmt.Sprintf("Failed to compare with last commit: %v", err))
-		return
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		printStagingCacheStats(stagingArea.GetCacheStats())
 	}
 
 	// Get last commit for metadata comparison purposes
 	var lastCommit *log.Commit
+	var err error
 	if currentVersion > 0 {
 		lastCommit, err = logManager.GetCommit(currentVersion)
 		if err != nil {
@@ -84,18 +97,37 @@ func runStatus(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Scan the working tree for design files; committed paths are relative to its root
+	// .dgitignore hides untracked files only - committed files keep reporting changes
+	currentWorkDir := filepath.Dir(dgitDir)
+	currentDirFiles := scanCurrentDirectory(currentWorkDir, submodule.NewSubmoduleManager(dgitDir).ModuleDirs(),
+		scanner.NewIgnoreMatcher(currentWorkDir), trackedPaths(lastCommit, currentWorkDir))
+
+	// Compare current files with last commit to detect changes
+	result, err := statusManager.CompareWithCommit(currentVersion, currentDirFiles)
+	if err != nil {
+		printWarning(fmt.Sprintf("Failed to compare with last commit: %v", err))
+		return
+	}
+
 	// Filter out files that are already staged from the results
 	// This prevents showing the same file in multiple sections
-	result.ModifiedFiles = filterStagedFiles(result.ModifiedFiles, stagingArea)
-	result.UntrackedFiles = filterStagedFiles(result.UntrackedFiles, stagingArea)
-	result.DeletedFiles = filterStagedFiles(result.DeletedFiles, stagingArea)
+	result.ModifiedFiles = filterStagedFiles(result.ModifiedFiles, stagingArea, currentWorkDir)
+	result.UntrackedFiles = filterStagedFiles(result.UntrackedFiles, stagingArea, currentWorkDir)
+	result.DeletedFiles = filterStagedFiles(result.DeletedFiles, stagingArea, currentWorkDir)
+
+	// Pinned files are held at an older version on purpose - report them separately
+	pins := loadStatusPins(dgitDir, currentWorkDir)
+	var pinnedFiles []status.FileStatus
+	result.ModifiedFiles, pinnedFiles = splitPinnedFiles(result.ModifiedFiles, pins, pinnedFiles)
+	result.UntrackedFiles, pinnedFiles = splitPinnedFiles(result.UntrackedFiles, pins, pinnedFiles)
 
 	// Display modified files (not staged)
 	if len(result.ModifiedFiles) > 0 {
 		fmt.Println("Changes not staged for commit:")
 		for _, fileStatus := range result.ModifiedFiles {
 			// Add design-specific metadata change summary
-			metadataSummary := getMetadataChangeSummary(fileStatus.Path, lastCommit, currentWorkDir)
+			metadataSummary := getMetadataChangeSummary(dgitDir, fileStatus.Path, lastCommit, currentWorkDir)
 			fmt.Printf("  modified: %s%s\n", fileStatus.Path, metadataSummary)
 		}
 		fmt.Println()
@@ -103,6 +135,20 @@ func runStatus(cmd *cobra.Command, args []string) {
 		fmt.Println("No changes not staged for commit.")
 	}
 
+	// Display pinned files
+	if len(pinnedFiles) > 0 {
+		fmt.Println("Pinned files:")
+		for _, fileStatus := range pinnedFiles {
+			p := pins[fileStatus.Path]
+			note := ""
+			if currentDirFiles[fileStatus.Path] != p.Hash {
+				note = yellow(" (changed since pinned)")
+			}
+			fmt.Printf("  pinned (v%d): %s%s\n", p.Version, fileStatus.Path, note)
+		}
+		fmt.Println()
+	}
+
 	// Display untracked files
 	if len(result.UntrackedFiles) > 0 {
 		fmt.Println("Untracked files:")
@@ -127,6 +173,28 @@ func runStatus(cmd *cobra.Command, args []string) {
 		fmt.Println("No deleted files.")
 	}
 
+	// Summarize changes per asset group
+	printStatusGroups(dgitDir, stagingArea, result)
+
+	// Display nested repository state recursively
+	if statuses, err := submodule.NewSubmoduleManager(dgitDir).GetStatus(); err != nil {
+		printWarning(fmt.Sprintf("Failed to load submodules: %v", err))
+	} else if len(statuses) > 0 {
+		fmt.Println("Submodules:")
+		printSubmoduleStatuses(statuses)
+		fmt.Println()
+	}
+
+	// Warn about linked assets whose library has a newer approved version
+	linkManager := linked.NewLinkManager(dgitDir)
+	if updates, err := linkManager.CheckUpdates(); err == nil && len(updates) > 0 {
+		links, _ := linkManager.GetLinks()
+		printWarning(fmt.Sprintf("%d linked asset(s) have newer approved library versions", len(updates)))
+		fmt.Println("Linked assets:")
+		printLinkedAssets(links, updates)
+		fmt.Println()
+	}
+
 	// Show helpful command suggestions
 	fmt.Println("Commands:")
 	fmt.Println("   Use 'dgit add <file>' to stage files for commit")
@@ -138,7 +206,7 @@ func runStatus(cmd *cobra.Command, args []string) {
 
 // scanCurrentDirectory scans the current directory for design files and returns their hashes
 // Used to detect file changes by comparing current state with last commit
-func scanCurrentDirectory(currentWorkDir string) map[string]string {
+func scanCurrentDirectory(currentWorkDir string, moduleDirs map[string]bool, ignore *scanner.IgnoreMatcher, tracked map[string]bool) map[string]string {
 	currentDirFiles := make(map[string]string)
 	
 	// Walk through all files in the working directory
@@ -151,22 +219,41 @@ func scanCurrentDirectory(currentWorkDir string) map[string]string {
 			if info.Name() == ".dgit" {
 				return filepath.SkipDir
 			}
+			// Skip nested repositories (submodules) - they report their own status
+			if moduleDirs[path] {
+				return filepath.SkipDir
+			}
+			// Skip application auto-recovery folders - see 'dgit clean'
+			if scanner.MatchResidue(path, true) != nil {
+				return filepath.SkipDir
+			}
+			if path != currentWorkDir {
+				if _, err := os.Stat(filepath.Join(path, ".dgit")); err == nil {
+					return filepath.SkipDir
+				}
+				if ignore.Ignored(path, true) && !tracksBelow(tracked, currentWorkDir, path) {
+					return filepath.SkipDir
+				}
+			}
 			return nil
 		}
 		
-		// Process only design files (ignore other file types)
-		if scanner.IsDesignFile(path) {
+		// Process only design files (ignore other file types and autosave/temp residue)
+		if scanner.IsDesignFile(path) && scanner.MatchResidue(path, false) == nil {
 			relPath, relErr := filepath.Rel(currentWorkDir, path)
 			if relErr != nil {
 				return nil
 			}
+			if !tracked[filepath.ToSlash(relPath)] && ignore.Ignored(path, false) {
+				return nil
+			}
 			
 			// Calculate file hash for change detection
 			hash, hashErr := status.CalculateFileHash(path)
 			if hashErr != nil {
 				return nil
 			}
-			currentDirFiles[relPath] = hash
+			currentDirFiles[filepath.ToSlash(relPath)] = hash
 		}
 		return nil
 	})
@@ -174,12 +261,40 @@ func scanCurrentDirectory(currentWorkDir string) map[string]string {
 	return currentDirFiles
 }
 
+// trackedPaths returns the repo-relative paths committed in a version
+func trackedPaths(commit *log.Commit, rootDir string) map[string]bool {
+	tracked := make(map[string]bool)
+	if commit == nil {
+		return tracked
+	}
+	for stored := range commit.Metadata {
+		path, _ := log.RepoPath(rootDir, stored)
+		tracked[path] = true
+	}
+	return tracked
+}
+
+// tracksBelow reports whether any tracked path lies inside a folder of the working tree
+func tracksBelow(tracked map[string]bool, rootDir, dir string) bool {
+	rel, err := filepath.Rel(rootDir, dir)
+	if err != nil {
+		return false
+	}
+	prefix := filepath.ToSlash(rel) + "/"
+	for path := range tracked {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // filterStagedFiles removes files that are already staged from status results
 // Prevents showing the same file in both staged and unstaged sections
-func filterStagedFiles(files []status.FileStatus, stagingArea *staging.StagingArea) []status.FileStatus {
+func filterStagedFiles(files []status.FileStatus, stagingArea *staging.StagingArea, rootDir string) []status.FileStatus {
 	var filtered []status.FileStatus
 	for _, file := range files {
-		if !stagingArea.HasFile(file.Path) {
+		if !stagingArea.HasFile(filepath.Join(rootDir, filepath.FromSlash(file.Path))) {
 			filtered = append(filtered, file)
 		}
 	}
@@ -188,42 +303,36 @@ func filterStagedFiles(files []status.FileStatus, stagingArea *staging.StagingAr
 
 // getMetadataChangeSummary generates a summary of design-specific metadata changes
 // This is unique to DGit - shows what changed in the design file beyond just content
-func getMetadataChangeSummary(filePath string, lastCommit *log.Commit, currentWorkDir string) string {
+func getMetadataChangeSummary(dgitDir, filePath string, lastCommit *log.Commit, currentWorkDir string) string {
 	if lastCommit == nil {
 		return ""
 	}
 
-	// Get current file metadata by scanning the file
-	currentFileInfo, err := scanner.NewFileScanner().ScanFile(filepath.Join(currentWorkDir, filePath))
+	// Get current file metadata by scanning the file (cached while unchanged)
+	current, err := scanDesignMetadata(dgitDir, filepath.Join(currentWorkDir, filePath))
 	if err != nil {
 		return ""
 	}
 
 	// Get old metadata from last commit
-	oldMetaRaw, ok := lastCommit.Metadata[filePath].(map[string]interface{})
+	old, ok := committedDesignMetadata(lastCommit.Metadata[filePath])
 	if !ok {
 		return ""
 	}
 
-	// Extract old metadata values
-	oldLayers, _ := oldMetaRaw["layers"].(float64)
-	oldArtboards, _ := oldMetaRaw["artboards"].(float64)
-	oldDimensions, _ := oldMetaRaw["dimensions"].(string)
-	oldColorMode, _ := oldMetaRaw["color_mode"].(string)
-
 	// Compare old vs current metadata and build change summary
 	var changes []string
-	if oldLayers != float64(currentFileInfo.Layers) && currentFileInfo.Layers != 0 {
-		changes = append(changes, fmt.Sprintf("Layers: %.0f→%d", oldLayers, currentFileInfo.Layers))
+	if old.Layers != current.Layers && current.Layers != 0 {
+		changes = append(changes, fmt.Sprintf("Layers: %d→%d", old.Layers, current.Layers))
 	}
-	if oldArtboards != float64(currentFileInfo.Artboards) && currentFileInfo.Artboards != 0 {
-		changes = append(changes, fmt.Sprintf("Artboards: %.0f→%d", oldArtboards, currentFileInfo.Artboards))
+	if old.Artboards != current.Artboards && current.Artboards != 0 {
+		changes = append(changes, fmt.Sprintf("Artboards: %d→%d", old.Artboards, current.Artboards))
 	}
-	if oldDimensions != currentFileInfo.Dimensions && currentFileInfo.Dimensions != "Unknown" {
-		changes = append(changes, fmt.Sprintf("Dimensions: %s→%s", oldDimensions, currentFileInfo.Dimensions))
+	if old.Dimensions != current.Dimensions && current.Dimensions != "Unknown" {
+		changes = append(changes, fmt.Sprintf("Dimensions: %s→%s", old.Dimensions, current.Dimensions))
 	}
-	if oldColorMode != currentFileInfo.ColorMode && currentFileInfo.ColorMode != "Unknown" {
-		changes = append(changes, fmt.Sprintf("ColorMode: %s→%s", oldColorMode, currentFileInfo.ColorMode))
+	if old.ColorMode != current.ColorMode && current.ColorMode != "Unknown" {
+		changes = append(changes, fmt.Sprintf("ColorMode: %s→%s", old.ColorMode, current.ColorMode))
 	}
 	
 	// Return formatted change summary if any changes detected
@@ -233,6 +342,57 @@ func getMetadataChangeSummary(filePath string, lastCommit *log.Commit, currentWo
 	return ""
 }
 
+// designMetadata is the part of a design file's metadata status and restore compare
+type designMetadata struct {
+	Layers     int
+	Artboards  int
+	Dimensions string
+	ColorMode  string
+}
+
+// String describes the metadata briefly, e.g. "34 layers, 2560x1440, RGB"
+func (m designMetadata) String() string {
+	var parts []string
+	if m.Layers > 0 {
+		parts = append(parts, fmt.Sprintf("%d layers", m.Layers))
+	}
+	if m.Artboards > 0 {
+		parts = append(parts, fmt.Sprintf("%d artboards", m.Artboards))
+	}
+	if m.Dimensions != "" && m.Dimensions != "Unknown" {
+		parts = append(parts, m.Dimensions)
+	}
+	if m.ColorMode != "" && m.ColorMode != "Unknown" {
+		parts = append(parts, m.ColorMode)
+	}
+	if len(parts) == 0 {
+		return "no design metadata"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// scanDesignMetadata reads a working file's metadata through the scan cache
+func scanDesignMetadata(dgitDir, path string) (designMetadata, error) {
+	info, err := scanner.NewCachedFileScanner(dgitDir).ScanFile(path)
+	if err != nil {
+		return designMetadata{}, err
+	}
+	return designMetadata{Layers: info.Layers, Artboards: info.Artboards, Dimensions: info.Dimensions, ColorMode: info.ColorMode}, nil
+}
+
+// committedDesignMetadata reads the metadata a commit recorded for a file
+func committedDesignMetadata(raw interface{}) (designMetadata, bool) {
+	meta, ok := raw.(map[string]interface{})
+	if !ok {
+		return designMetadata{}, false
+	}
+	layers, _ := meta["layers"].(float64)
+	artboards, _ := meta["artboards"].(float64)
+	dimensions, _ := meta["dimensions"].(string)
+	colorMode, _ := meta["color_mode"].(string)
+	return designMetadata{Layers: int(layers), Artboards: int(artboards), Dimensions: dimensions, ColorMode: colorMode}, true
+}
+
 // getStatusFileType returns file type string for status display
 // Used to show file types in status output for better visual distinction
 func getStatusFileType(filePath string) string {
@@ -246,8 +406,12 @@ func getStatusFileType(filePath string) string {
 		return "SKETCH"
 	case ".fig":
 		return "FIG"
+	case ".figma":
+		return "FIGMA"
 	case ".xd":
 		return "XD"
+	case ".blend":
+		return "BLEND"
 	default:
 		return "FILE"
 	}
@@ -256,8 +420,133 @@ func getStatusFileType(filePath string) string {
 // printStatusStagingStatus displays the files currently staged for commit
 // Shows file type and name for each staged file
 func printStatusStagingStatus(stagingArea *staging.StagingArea) {
-	for _, file := range stagingArea.GetStagedFiles() {
+	sequences, singles := stagingArea.GetSequences()
+	for _, seq := range sequences {
+		fileType := getStatusFileType(seq.Pattern)
+		fmt.Printf("  [%s] new sequence: %s (%d frames, %s)\n", fileType, seq.Pattern, seq.Frames, seq.FrameRange())
+	}
+	for _, file := range singles {
 		fileType := getStatusFileType(file.Path)
+		if file.RenamedFrom != "" {
+			fmt.Printf("  [%s] renamed: %s -> %s\n", fileType, file.RenamedFrom, file.Path)
+			continue
+		}
 		fmt.Printf("  [%s] new file: %s\n", fileType, file.Path)
 	}
-}
\ No newline at end of file
+}
+
+// printStagingCacheStats shows how many adds reused content already in a cache tier
+func printStagingCacheStats(stats *staging.CacheStats) {
+	total := stats.Hits() + stats.NewFiles
+	fmt.Println("Staging cache:")
+	if total == 0 {
+		fmt.Println("  No files added since the last commit")
+		fmt.Println()
+		return
+	}
+	fmt.Printf("  Hits:   %d of %d add(s) (%.0f%%) - hot %d, warm %d, cold %d\n",
+		stats.Hits(), total, float64(stats.Hits())*100/float64(total),
+		stats.HotCacheHits, stats.WarmCacheHits, stats.ColdCacheHits)
+	fmt.Printf("  Misses: %d newly cached, %d pre-compressed\n", stats.NewFiles, stats.PreCompressed)
+	fmt.Printf("  Metadata extracted: %d\n", stats.MetadataExtracted)
+	fmt.Println()
+}
+// printStatusGroups shows which asset groups have staged, modified, untracked or deleted files
+func printStatusGroups(dgitDir string, stagingArea *staging.StagingArea, result *status.FileStatusResult) {
+	groups, err := group.NewGroupManager(dgitDir).GetGroups()
+	if err != nil || len(groups) == 0 {
+		return
+	}
+
+	sections := []struct {
+		label string
+		paths []string
+	}{
+		{"staged", nil},
+		{"modified", statusPaths(result.ModifiedFiles)},
+		{"untracked", statusPaths(result.UntrackedFiles)},
+		{"deleted", statusPaths(result.DeletedFiles)},
+	}
+	for _, file := range stagingArea.GetStagedFiles() {
+		sections[0].paths = append(sections[0].paths, file.Path)
+	}
+
+	printed := false
+	for _, g := range groups {
+		var counts []string
+		for _, section := range sections {
+			if n := len(g.Filter(section.paths)); n > 0 {
+				counts = append(counts, fmt.Sprintf("%d %s", n, section.label))
+			}
+		}
+		if len(counts) == 0 {
+			continue
+		}
+		if !printed {
+			fmt.Println("Asset groups with changes:")
+			printed = true
+		}
+		fmt.Printf("  %s: %s\n", g.Name, strings.Join(counts, ", "))
+	}
+	if printed {
+		fmt.Println()
+	}
+}
+
+// statusPaths extracts the paths of a status list
+func statusPaths(files []status.FileStatus) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	return paths
+}
+
+// loadStatusPins returns pins keyed by path relative to the directory status scans
+func loadStatusPins(dgitDir, currentWorkDir string) map[string]*pin.Pin {
+	pins := make(map[string]*pin.Pin)
+	all, err := pin.NewPinManager(dgitDir).GetPins()
+	if err != nil {
+		printWarning(fmt.Sprintf("Failed to load pins: %v", err))
+		return pins
+	}
+	rootDir := filepath.Dir(dgitDir)
+	for _, p := range all {
+		if rel, err := filepath.Rel(currentWorkDir, filepath.Join(rootDir, filepath.FromSlash(p.Path))); err == nil {
+			pins[filepath.ToSlash(rel)] = p
+		}
+	}
+	return pins
+}
+
+// splitPinnedFiles moves pinned entries out of a status list
+func splitPinnedFiles(files []status.FileStatus, pins map[string]*pin.Pin, pinned []status.FileStatus) ([]status.FileStatus, []status.FileStatus) {
+	var rest []status.FileStatus
+	for _, file := range files {
+		if _, ok := pins[file.Path]; ok {
+			file.Status = "pinned"
+			pinned = append(pinned, file)
+		} else {
+			rest = append(rest, file)
+		}
+	}
+	return rest, pinned
+}
+
+// printHealthLine shows the repository health summary under the version header
+func printHealthLine(report *health.Report) {
+	var parts []string
+	for _, check := range report.Checks {
+		switch check.Level {
+		case health.LevelOK:
+			parts = append(parts, green("✓")+" "+check.Detail)
+		case health.LevelWarn:
+			parts = append(parts, yellow("!")+" "+check.Detail)
+		case health.LevelFail:
+			parts = append(parts, red("✗")+" "+check.Detail)
+		default:
+			parts = append(parts, check.Detail)
+		}
+	}
+	fmt.Printf("Health: %s\n", strings.Join(parts, " · "))
+}
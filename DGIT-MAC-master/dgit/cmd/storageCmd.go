@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/storage"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// StorageCmd represents the storage command for keeping repository data in a bucket
+// Archived versions and object store blobs move there and come back when a restore needs them
+var StorageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Keep archives and objects in S3 or a shared folder",
+	Long: `Show or configure the storage backend that holds cold-tier archives and
+object store blobs off this machine. With a backend configured, 'dgit archive'
+uploads each archived version and removes the local copy, and 'dgit storage
+push --evict' does the same for object store blobs. Restores fetch whatever is
+missing back from the backend on their own.
+
+S3 credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+AWS_SESSION_TOKEN; they are never written to the repository.
+
+Examples:
+  dgit storage                                            # Show the backend and check it is reachable
+  dgit storage set s3 --bucket studio-dgit --region eu-west-1 --prefix brand-2025
+  dgit storage set s3 --bucket dgit --endpoint http://minio.local:9000 --path-style
+  dgit storage set dir /Volumes/studio/dgit               # A mounted share
+  dgit storage push --evict                               # Upload objects, free local space
+  dgit storage unset                                      # Keep everything local again`,
+	Args: cobra.NoArgs,
+	Run:  runStorageShow,
+}
+
+// storageSetCmd configures the backend
+var storageSetCmd = &cobra.Command{
+	Use:   "set <s3|dir> [path]",
+	Short: "Configure the storage backend",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runStorageSet,
+}
+
+// storageUnsetCmd turns remote storage off
+var storageUnsetCmd = &cobra.Command{
+	Use:   "unset",
+	Short: "Stop using a storage backend (data already uploaded stays there)",
+	Args:  cobra.NoArgs,
+	Run:   runStorageUnset,
+}
+
+// storagePushCmd uploads object store blobs
+var storagePushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Upload object store blobs the backend does not have yet",
+	Args:  cobra.NoArgs,
+	Run:   runStoragePush,
+}
+
+// init sets up storage subcommands and flags
+func init() {
+	StorageCmd.AddCommand(storageSetCmd)
+	StorageCmd.AddCommand(storageUnsetCmd)
+	StorageCmd.AddCommand(storagePushCmd)
+
+	storageSetCmd.Flags().String("bucket", "", "S3 bucket name")
+	storageSetCmd.Flags().String("region", "", "S3 region (default us-east-1)")
+	storageSetCmd.Flags().String("endpoint", "", "Endpoint of an S3-compatible service, e.g. http://minio.local:9000")
+	storageSetCmd.Flags().String("prefix", "", "Key prefix, so several repositories can share a bucket")
+	storageSetCmd.Flags().Bool("path-style", false, "Address the bucket as <endpoint>/<bucket> (MinIO and most self-hosted services)")
+	storagePushCmd.Flags().Bool("evict", false, "Remove local copies once the backend holds them")
+}
+
+// runStorageShow prints the configured backend and checks that it answers
+func runStorageShow(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	backend, err := storage.Open(dgitDir)
+	if err != nil {
+		exitWithError(err.Error(), "Fix it with 'dgit storage set' or turn it off with 'dgit storage unset'")
+	}
+	if backend == nil {
+		printInfo("No storage backend; archives and objects stay on this machine")
+		printSuggestion("Configure one with 'dgit storage set s3 --bucket <name>'")
+		return
+	}
+
+	fmt.Printf("Backend: %s (%s)\n", backend.Name(), backend.Describe())
+	if _, err := backend.Stat(storage.Key(objstore.BlobDir)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		exitWithError(fmt.Sprintf("%s is not reachable: %v", backend.Describe(), err), "")
+	}
+	printSuccess("Reachable")
+}
+
+// runStorageSet stores the backend settings after validating them
+func runStorageSet(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	config := initializer.StorageConfig{Backend: args[0]}
+	switch args[0] {
+	case "dir":
+		if len(args) != 2 {
+			exitWithError("the dir backend needs a folder", "Example: dgit storage set dir /Volumes/studio/dgit")
+		}
+		config.Path = args[1]
+	case "s3":
+		if len(args) != 1 {
+			exitWithError("the s3 backend takes no path", "Use --bucket, --prefix and --endpoint")
+		}
+		config.S3.Bucket, _ = cmd.Flags().GetString("bucket")
+		config.S3.Region, _ = cmd.Flags().GetString("region")
+		config.S3.Endpoint, _ = cmd.Flags().GetString("endpoint")
+		config.S3.Prefix, _ = cmd.Flags().GetString("prefix")
+		config.S3.PathStyle, _ = cmd.Flags().GetBool("path-style")
+	}
+	if err := storage.SetConfig(dgitDir, config); err != nil {
+		exitWithError(err.Error(), "")
+	}
+	backend, _ := storage.New(dgitDir, config)
+	printSuccess(fmt.Sprintf("Archives and objects can now be kept in %s", backend.Describe()))
+	printSuggestion("Run 'dgit storage' to check that it is reachable")
+}
+
+// runStorageUnset clears the backend settings
+func runStorageUnset(cmd *cobra.Command, args []string) {
+	if err := storage.SetConfig(checkDgitRepository(), initializer.StorageConfig{}); err != nil {
+		exitWithError(err.Error(), "")
+	}
+	printSuccess("Storage backend removed; versions archived to it can no longer be recalled until it is set again")
+}
+
+// runStoragePush uploads object store blobs, evicting the local copies on request
+func runStoragePush(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	backend, err := storage.Open(dgitDir)
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+	if backend == nil {
+		exitWithError("no storage backend configured", "Configure one with 'dgit storage set s3 --bucket <name>'")
+	}
+
+	evict, _ := cmd.Flags().GetBool("evict")
+	result, err := objstore.NewObjectStore(dgitDir).Push(backend, evict)
+	if err != nil {
+		exitWithError(fmt.Sprintf("push to %s failed: %v", backend.Describe(), err), "Run it again to continue")
+	}
+	printSuccess(fmt.Sprintf("Uploaded %d blob(s) (%s) to %s; %d already there",
+		result.Uploaded, util.FormatBytes(result.Bytes), backend.Describe(), result.Present))
+	if evict {
+		printInfo(fmt.Sprintf("Evicted %d local blob(s); restores fetch them back when needed", result.Evicted))
+	}
+}
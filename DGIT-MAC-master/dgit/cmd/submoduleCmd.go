@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+
+	"github.com/spf13/cobra"
+)
+
+// SubmoduleCmd represents the submodule command for managing nested repositories
+// Similar to 'git submodule' - pins shared asset libraries to a specific version
+var SubmoduleCmd = &cobra.Command{
+	Use:   "submodule",
+	Short: "Manage nested DGit repositories",
+	Long: `Manage nested repositories pinned in the .dgitmodules file.
+
+Large projects often embed shared asset libraries (brand kits, icon sets).
+A submodule pins a path in this repository to a version of another DGit repository.
+
+Examples:
+  dgit submodule                                  # Show submodule status
+  dgit submodule add ../brand-kit libs/brand      # Pin latest version of brand-kit
+  dgit submodule add ../icons libs/icons -v 12    # Pin version 12 of icons
+  dgit submodule set-version libs/icons 14        # Re-pin libs/icons to version 14
+  dgit submodule update                           # Check out all pinned versions`,
+	Run: runSubmoduleStatus,
+}
+
+// submoduleAddCmd pins a new nested repository
+var submoduleAddCmd = &cobra.Command{
+	Use:   "add <repository> <path>",
+	Short: "Pin another DGit repository at a path",
+	Args:  cobra.ExactArgs(2),
+	Run:   runSubmoduleAdd,
+}
+
+// submoduleUpdateCmd checks out pinned versions into the working tree
+var submoduleUpdateCmd = &cobra.Command{
+	Use:   "update [path...]",
+	Short: "Check out the pinned version of each submodule",
+	Run:   runSubmoduleUpdate,
+}
+
+// submoduleSetVersionCmd re-pins an existing submodule
+var submoduleSetVersionCmd = &cobra.Command{
+	Use:   "set-version <path> <version>",
+	Short: "Pin an existing submodule to a different version",
+	Args:  cobra.ExactArgs(2),
+	Run:   runSubmoduleSetVersion,
+}
+
+// init sets up submodule subcommands and flags
+func init() {
+	submoduleAddCmd.Flags().StringP("version", "v", "", "Version of the source repository to pin (default: latest)")
+
+	SubmoduleCmd.AddCommand(submoduleAddCmd)
+	SubmoduleCmd.AddCommand(submoduleUpdateCmd)
+	SubmoduleCmd.AddCommand(submoduleSetVersionCmd)
+}
+
+// runSubmoduleAdd registers a new submodule in .dgitmodules
+func runSubmoduleAdd(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := submodule.NewSubmoduleManager(dgitDir)
+
+	version := 0
+	if versionFlag, _ := cmd.Flags().GetString("version"); versionFlag != "" {
+		v, err := parseVersionArg(versionFlag)
+		if err != nil {
+			exitWithError(err.Error(), "")
+		}
+		version = v
+	}
+
+	module, err := manager.AddModule(args[0], args[1], version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("adding submodule: %v", err), "")
+	}
+
+	printSuccess(fmt.Sprintf("Added submodule %s (v%d of %s)", module.Path, module.Version, module.URL))
+	printInfo("Run 'dgit submodule update' to check out its files")
+}
+
+// runSubmoduleUpdate restores pinned versions of submodules
+func runSubmoduleUpdate(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := submodule.NewSubmoduleManager(dgitDir)
+
+	if len(args) == 0 {
+		failures, err := manager.UpdateAll()
+		if err != nil {
+			exitWithError(fmt.Sprintf("updating submodules: %v", err), "")
+		}
+		reportSubmoduleFailures(failures)
+		return
+	}
+
+	failures := make(map[string]error)
+	for _, path := range args {
+		module, err := manager.FindModule(path)
+		if err != nil {
+			failures[path] = err
+			continue
+		}
+		if err := manager.Update(module); err != nil {
+			failures[module.Path] = err
+		}
+	}
+	reportSubmoduleFailures(failures)
+}
+
+// runSubmoduleSetVersion changes the pinned version of a submodule
+func runSubmoduleSetVersion(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := submodule.NewSubmoduleManager(dgitDir)
+
+	version, err := parseVersionArg(args[1])
+	if err != nil {
+		exitWithError(err.Error(), "")
+	}
+
+	module, err := manager.SetVersion(args[0], version)
+	if err != nil {
+		exitWithError(fmt.Sprintf("setting submodule version: %v", err), "")
+	}
+
+	printSuccess(fmt.Sprintf("Pinned %s to v%d", module.Path, module.Version))
+	printInfo("Run 'dgit submodule update' to check out its files")
+}
+
+// runSubmoduleStatus lists submodules with their checkout state
+func runSubmoduleStatus(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	manager := submodule.NewSubmoduleManager(dgitDir)
+
+	statuses, err := manager.GetStatus()
+	if err != nil {
+		exitWithError(fmt.Sprintf("loading submodules: %v", err), "")
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No submodules configured.")
+		return
+	}
+
+	printSubmoduleStatuses(statuses)
+}
+
+// printSubmoduleStatuses displays one line per submodule plus file details
+// Shared by 'dgit submodule' and 'dgit status'
+func printSubmoduleStatuses(statuses []*submodule.ModuleStatus) {
+	for _, ms := range statuses {
+		var state string
+		switch {
+		case !ms.SourceAvailable:
+			state = red("source unavailable")
+		case ms.CheckedOut == 0:
+			state = yellow("not checked out")
+		case ms.CheckedOut != ms.Module.Version:
+			state = yellow(fmt.Sprintf("checked out v%d", ms.CheckedOut))
+		case !ms.IsUpToDate():
+			state = yellow("modified")
+		default:
+			state = green("up to date")
+		}
+		fmt.Printf("  %s (v%d) %s\n", ms.Module.Path, ms.Module.Version, state)
+
+		for _, file := range ms.MissingFiles {
+			fmt.Printf("      missing: %s\n", file)
+		}
+		for _, file := range ms.ModifiedFiles {
+			fmt.Printf("      modified: %s\n", file)
+		}
+	}
+}
+
+// reportSubmoduleFailures prints update failures and exits non-zero if any occurred
+func reportSubmoduleFailures(failures map[string]error) {
+	if len(failures) == 0 {
+		printSuccess("Submodules up to date")
+		return
+	}
+	for path, err := range failures {
+		printError(fmt.Sprintf("%s: %v", path, err))
+	}
+	os.Exit(1)
+}
+
+// parseVersionArg parses a version argument such as "12" or "v12"
+func parseVersionArg(arg string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimPrefix(arg, "v"))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid version: %s", arg)
+	}
+	return version, nil
+}
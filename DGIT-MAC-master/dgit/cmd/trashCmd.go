@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/trash"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// TrashCmd represents the trash command for recovering displaced files
+// Restore, clean and link fetch move files here instead of deleting them
+var TrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and recover files replaced or removed by dgit",
+	Long: `Files that 'dgit restore' overwrites, 'dgit clean' removes or 'dgit link fetch'
+replaces are moved to .dgit/trash instead of being deleted. They are kept
+for the number of days set in "trash.retention_days" (default 30).
+
+Examples:
+  dgit trash list                     # List trashed files (same as 'dgit trash')
+  dgit trash restore hero.psd         # Put back the newest trashed hero.psd
+  dgit trash restore lx3k9q2a --force # Put back by ID, trashing the current file
+  dgit trash empty --older-than 7     # Delete entries older than a week`,
+	Args: cobra.NoArgs,
+	Run:  runTrashList,
+}
+
+// trashListCmd lists trashed files; bare 'dgit trash' does the same
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trashed files, newest first",
+	Args:  cobra.NoArgs,
+	Run:   runTrashList,
+}
+
+// trashRestoreCmd moves an entry back to its original location
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id|path>",
+	Short: "Put a trashed file back where it was",
+	Args:  cobra.ExactArgs(1),
+	Run:   runTrashRestore,
+}
+
+// trashEmptyCmd permanently deletes trash entries
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete trashed files",
+	Args:  cobra.NoArgs,
+	Run:   runTrashEmpty,
+}
+
+// init sets up trash subcommands and flags
+func init() {
+	trashRestoreCmd.Flags().BoolP("force", "f", false, "Trash the file currently at that location and restore anyway")
+	trashEmptyCmd.Flags().Int("older-than", 0, "Only delete entries trashed more than this many days ago")
+
+	TrashCmd.AddCommand(trashListCmd)
+	TrashCmd.AddCommand(trashRestoreCmd)
+	TrashCmd.AddCommand(trashEmptyCmd)
+}
+
+// runTrashList lists trashed files, newest first
+func runTrashList(cmd *cobra.Command, args []string) {
+	manager := trash.NewTrashManager(checkDgitRepository())
+
+	entries, err := manager.List()
+	if err != nil {
+		exitWithError(fmt.Sprintf("reading trash: %v", err), "")
+	}
+	if len(entries) == 0 {
+		fmt.Println("Trash is empty.")
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		path := entry.Path
+		if entry.Dir {
+			path += "/"
+		}
+		fmt.Printf("  %s  %s  %-13s %10s  %s\n", entry.ID, entry.TrashedAt.Format("2006-01-02 15:04"),
+			entry.Operation, util.FormatBytes(entry.Size), path)
+		total += entry.Size
+	}
+	fmt.Printf("\n%d item(s), %s, kept for %d days\n", len(entries), util.FormatBytes(total), manager.RetentionDays())
+}
+
+// runTrashRestore puts a trashed file back
+func runTrashRestore(cmd *cobra.Command, args []string) {
+	manager := trash.NewTrashManager(checkDgitRepository())
+	force, _ := cmd.Flags().GetBool("force")
+
+	entry, err := manager.Restore(args[0], force)
+	if err != nil {
+		exitWithError(fmt.Sprintf("restoring from trash: %v", err), "Use --force to trash the current file first, or 'dgit trash' to list entries")
+	}
+	printSuccess(fmt.Sprintf("Restored %s (trashed by %s on %s)", entry.Path, entry.Operation,
+		entry.TrashedAt.Format("2006-01-02 15:04")))
+}
+
+// runTrashEmpty deletes trash entries permanently
+func runTrashEmpty(cmd *cobra.Command, args []string) {
+	manager := trash.NewTrashManager(checkDgitRepository())
+	days, _ := cmd.Flags().GetInt("older-than")
+
+	removed, err := manager.Empty(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		exitWithError(fmt.Sprintf("emptying trash: %v", err), "")
+	}
+	printSuccess(fmt.Sprintf("Deleted %d trash item(s)", removed))
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/bundle"
+
+	"github.com/spf13/cobra"
+)
+
+// UnbundleCmd represents the unbundle command for applying bundle files
+var UnbundleCmd = &cobra.Command{
+	Use:   "unbundle <file>",
+	Short: "Apply a bundle created with 'dgit bundle create'",
+	Long: `Add the versions contained in a bundle file to this repository.
+
+When the bundle continues your current latest version, its commits are added
+with their original version numbers. If your history has moved on since, the
+commits are imported onto a branch instead, the same way as 'dgit merge-repo'.
+
+Examples:
+  dgit unbundle out.bundle
+  dgit unbundle out.bundle --branch from-laptop`,
+	Args: cobra.ExactArgs(1),
+	Run:  runUnbundle,
+}
+
+// init sets up command flags for unbundle command
+func init() {
+	UnbundleCmd.Flags().String("branch", "", "Branch for diverged history (default: bundle/<file name>)")
+}
+
+// runUnbundle executes the unbundle command functionality
+func runUnbundle(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+
+	branch, _ := cmd.Flags().GetString("branch")
+	if branch == "" {
+		name := filepath.Base(args[0])
+		branch = "bundle/" + strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	result, err := bundle.NewBundleManager(dgitDir).Unbundle(args[0], branch)
+	if err != nil {
+		exitWithError(fmt.Sprintf("unbundling: %v", err), "Check the bundle with 'dgit bundle verify'")
+	}
+
+	printUnbundleResult(result, "bundle")
+}
+
+// printUnbundleResult reports the commits added by a bundle, or the branch diverged history went to
+// source names where the commits came from, e.g. "bundle" or "origin"
+func printUnbundleResult(result *bundle.UnbundleResult, source string) {
+	if result.Merge != nil {
+		printWarning(fmt.Sprintf("Local history has diverged from %s", source))
+		fmt.Printf("Imported %d commits onto %s\n", len(result.Merge.Imported), bold(result.Merge.Branch))
+		for _, m := range result.Merge.Imported {
+			fmt.Printf("  %s v%-4d → v%-4d %s\n", source, m.SourceVersion, m.LocalVersion, m.Message)
+		}
+		for _, c := range result.Merge.Conflicts {
+			fmt.Printf("  %s %s: local v%d vs %s v%d\n", red("conflict"), c.Path, c.LocalVersion, source, c.SourceVersion)
+		}
+		return
+	}
+
+	if len(result.Added) == 0 {
+		printSuccess("Already up to date")
+		return
+	}
+	for _, c := range result.Added {
+		fmt.Printf("  %s (v%d) %s\n", c.Hash[:8], c.Version, c.Message)
+	}
+	printSuccess(fmt.Sprintf("Added %d commits (now at v%d)", len(result.Added), result.Added[len(result.Added)-1].Version))
+	if len(result.Skipped) > 0 {
+		printInfo(fmt.Sprintf("%d commits were already present", len(result.Skipped)))
+	}
+	printInfo("Use 'dgit restore' to update your working files")
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+
+	"github.com/spf13/cobra"
+)
+
+// UnstageCmd represents the unstage command for taking files out of the staging area
+// Working files are never touched; only the staged entry and its cache copy are dropped
+var UnstageCmd = &cobra.Command{
+	Use:   "unstage <files...>",
+	Short: "Remove files from the staging area, keeping the working files",
+	Long: `Remove files from the staging area without touching them in the working
+tree. Arguments can be files, folders or glob patterns; quote globs to match
+staged files that were deleted since they were added.
+
+Examples:
+  dgit unstage hero.psd            # Unstage one file
+  dgit unstage exports/            # Unstage everything staged under a folder
+  dgit unstage "*.psd"             # Unstage all staged PSD files
+  dgit rm --cached hero.psd        # Same as 'dgit unstage hero.psd'`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runUnstage,
+}
+
+// RmCmd represents 'dgit rm --cached', the git spelling of unstage
+var RmCmd = &cobra.Command{
+	Use:   "rm --cached <files...>",
+	Short: "Remove files from the staging area (requires --cached)",
+	Long: `Remove files from the staging area without touching them in the working
+tree. Only --cached is supported: to stop tracking a file, delete it and
+commit the deletion.
+
+Examples:
+  dgit rm --cached hero.psd
+  dgit rm --cached "renders/*.exr"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if cached, _ := cmd.Flags().GetBool("cached"); !cached {
+			exitWithError("dgit rm only removes files from the staging area",
+				"Use 'dgit rm --cached <file>' or 'dgit unstage <file>'")
+		}
+		runUnstage(cmd, args)
+	},
+}
+
+// init sets up command flags for rm command
+func init() {
+	RmCmd.Flags().Bool("cached", false, "Only remove the files from the staging area")
+}
+
+// runUnstage removes each argument's matches from the staging area and shows what is still staged
+func runUnstage(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	repoLock := lockRepository(dgitDir, "unstage")
+	defer repoLock.Release()
+	stagingArea := staging.NewStagingArea(dgitDir)
+	stagingArea.Lock = repoLock
+	if err := stagingArea.LoadStaging(); err != nil {
+		exitWithError(fmt.Sprintf("loading staging area: %v", err), "")
+	}
+
+	var unstaged []string
+	failed := false
+	for _, arg := range args {
+		paths, err := stagingArea.UnstagePattern(arg)
+		unstaged = append(unstaged, paths...)
+		if err != nil {
+			printError(fmt.Sprintf("unstaging '%s': %v", arg, err))
+			failed = true
+		}
+	}
+
+	if err := stagingArea.SaveStaging(); err != nil {
+		exitWithError(fmt.Sprintf("saving staging area: %v", err), "")
+	}
+
+	if len(unstaged) > 0 {
+		printSuccess(fmt.Sprintf("Unstaged %d file(s):", len(unstaged)))
+		for _, path := range unstaged {
+			fmt.Printf("  - %s\n", path)
+		}
+		fmt.Println()
+		printStagingStatus(stagingArea)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/verify"
+
+	"github.com/spf13/cobra"
+)
+
+// VerifyCmd represents the verify command for checking repository integrity
+// Deep mode proves versions still restore byte-identically, not just that objects look intact
+var VerifyCmd = &cobra.Command{
+	Use:   "verify [version...]",
+	Short: "Check that committed versions can be read back intact",
+	Long: `Check every version's storage object: it must exist, its delta base must
+exist, and it must decode without checksum errors.
+
+With --deep, versions are reconstructed through their real restoration paths
+(cache tiers, delta chains, conversions) into a scratch directory, and every
+file is compared with the size and SHA256 recorded when it was committed.
+By default a random sample of versions is checked; use --all for every one.
+
+Storage checks run on one worker per CPU core. With --incremental, objects
+whose size and modification time are unchanged since they last verified
+cleanly are skipped, so routine runs only read what is new or has moved.
+
+Examples:
+  dgit verify                    # Storage checks for all versions
+  dgit verify --incremental      # ... skipping objects already verified
+  dgit verify --workers 4        # ... with at most 4 parallel readers
+  dgit verify --deep             # Full restores of 5 random versions
+  dgit verify --deep --sample 20 # ... of 20 random versions
+  dgit verify --deep --all       # ... of every version
+  dgit verify --deep v3 v7       # ... of specific versions`,
+	Run: runVerify,
+}
+
+// init sets up command flags for verify command
+func init() {
+	VerifyCmd.Flags().Bool("deep", false, "Restore versions into a scratch directory and compare contents")
+	VerifyCmd.Flags().Int("sample", 5, "Number of random versions to restore in deep mode")
+	VerifyCmd.Flags().Bool("all", false, "Restore every version in deep mode")
+	VerifyCmd.Flags().Int64("seed", 0, "Random seed for the sample (default: current time)")
+	VerifyCmd.Flags().Int("workers", 0, "Parallel storage checks (default: number of CPU cores)")
+	VerifyCmd.Flags().Bool("incremental", false, "Skip objects unchanged since they last verified cleanly")
+}
+
+// runVerify checks the selected versions and exits non-zero when any fail
+func runVerify(cmd *cobra.Command, args []string) {
+	dgitDir := checkDgitRepository()
+	deep, _ := cmd.Flags().GetBool("deep")
+	sample, _ := cmd.Flags().GetInt("sample")
+	all, _ := cmd.Flags().GetBool("all")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	workers, _ := cmd.Flags().GetInt("workers")
+	incremental, _ := cmd.Flags().GetBool("incremental")
+
+	logManager := log.NewLogManager(dgitDir)
+	var commits []*log.Commit
+	if len(args) > 0 {
+		for _, arg := range args {
+			version, err := parseVersionArg(arg)
+			if err != nil {
+				exitWithError(err.Error(), "Use versions such as v3")
+			}
+			commit, err := logManager.GetCommit(version)
+			if err != nil {
+				exitWithError(fmt.Sprintf("version v%d not found", version), "Run 'dgit log' to see versions")
+			}
+			commits = append(commits, commit)
+		}
+	} else {
+		history, err := logManager.GetCommitHistory()
+		if err != nil {
+			exitWithError(fmt.Sprintf("reading history: %v", err), "")
+		}
+		commits = history
+	}
+	if len(commits) == 0 {
+		fmt.Println("No commits to verify.")
+		return
+	}
+
+	manager := verify.NewVerifyManager(dgitDir)
+	var results []*verify.VersionResult
+	var storageRun *verify.StorageRun
+	if !deep {
+		fmt.Printf("Checking storage of %d version(s)...\n", len(commits))
+		run, err := manager.CheckStorageParallel(commits, workers, incremental)
+		if err != nil {
+			exitWithError(fmt.Sprintf("checking storage: %v", err), "")
+		}
+		storageRun = run
+		results = run.Results
+	} else {
+		if len(args) == 0 && !all {
+			if seed == 0 {
+				seed = time.Now().UnixNano()
+			}
+			commits = verify.Sample(commits, sample, seed)
+		} else {
+			commits = verify.Sample(commits, 0, 0)
+		}
+		scratchDir, err := os.MkdirTemp("", "dgit-verify-")
+		if err != nil {
+			exitWithError(fmt.Sprintf("creating scratch directory: %v", err), "")
+		}
+		defer os.RemoveAll(scratchDir)
+
+		fmt.Printf("Restoring %d version(s) into a scratch directory...\n", len(commits))
+		for _, commit := range commits {
+			var result *verify.VersionResult
+			withQuietStdout(func() { result = manager.DeepVerify(commit, scratchDir) })
+			results = append(results, result)
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		strategy := result.Strategy
+		if strategy == "" {
+			strategy = "-"
+		}
+		if result.Offline {
+			fmt.Printf("  %s v%-4d archived offline, not checked\n", yellow("--"), result.Version)
+			continue
+		}
+		if result.OK() {
+			detail := fmt.Sprintf("%d file(s)", result.Files)
+			if deep {
+				detail = fmt.Sprintf("%d/%d file(s) byte-identical", result.Hashed, result.Files)
+			}
+			fmt.Printf("  %s v%-4d %-18s %s (%s)\n", green("ok"), result.Version, strategy, detail,
+				result.Duration.Round(time.Millisecond))
+			continue
+		}
+		failed++
+		fmt.Printf("  %s v%-4d %s\n", red("FAIL"), result.Version, strategy)
+		for _, problem := range result.Problems {
+			fmt.Printf("         %s\n", problem)
+		}
+	}
+
+	// Dates are informational only, so skew is a warning rather than a failure
+	history, _ := logManager.GetCommitHistory()
+	anomalies := log.FindTimestampAnomalies(history, time.Now())
+	if len(anomalies) > 0 {
+		fmt.Println()
+		printWarning(fmt.Sprintf("%d commit(s) have timestamps that contradict the version order:", len(anomalies)))
+		for _, anomaly := range anomalies {
+			fmt.Printf("  v%-4d %s\n", anomaly.Version, anomaly.Reason)
+		}
+		printInfo("History is ordered by version, so this does not affect restores; check the committer's clock")
+	}
+
+	if err := manager.RecordRun(results, deep); err != nil {
+		printWarning(err.Error())
+	}
+
+	fmt.Println()
+	if storageRun != nil {
+		line := fmt.Sprintf("Read %s in %s with %d worker(s)", util.FormatBytes(storageRun.Bytes),
+			storageRun.Elapsed.Round(time.Millisecond), storageRun.Workers)
+		if storageRun.Bytes > 0 {
+			line += fmt.Sprintf(" (%s/s)", util.FormatBytes(int64(storageRun.Throughput())))
+		}
+		if storageRun.Skipped > 0 {
+			line += fmt.Sprintf("; %d unchanged version(s) skipped", storageRun.Skipped)
+		}
+		printInfo(line)
+	}
+	if failed > 0 {
+		printError(fmt.Sprintf("%d of %d version(s) failed verification", failed, len(results)))
+		os.Exit(1)
+	}
+	printSuccess(fmt.Sprintf("%d version(s) verified", len(results)))
+	if deep {
+		for _, result := range results {
+			if result.Hashed < result.Files {
+				printInfo("Files committed before content hashes were recorded are checked by size only")
+				break
+			}
+		}
+	}
+}
+
+// withQuietStdout runs fn with standard output discarded
+// Restores print progress meant for interactive use, which would drown out the verify report
+func withQuietStdout(fn func()) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		fn()
+		return
+	}
+	saved := os.Stdout
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = saved
+		devNull.Close()
+	}()
+	fn()
+}
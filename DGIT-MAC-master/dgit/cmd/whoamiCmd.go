@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/diag"
+
+	"github.com/spf13/cobra"
+)
+
+// WhoamiCmd represents the whoami command for environment diagnostics
+// The first thing to run, and attach, when asking for support
+var WhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show identity, paths, config and platform diagnostics",
+	Long: `Report who DGit records you as and everything support needs to know about
+this machine: repository paths, the effective configuration (repository
+settings with environment overrides such as DGIT_AUTHOR applied), platform
+capabilities (symlinks, copy-on-write clones, FUSE, case sensitivity,
+external tools) and whether each remote can be reached.
+
+Works outside a repository too; repository sections are then left out.
+Tokens are reported as set or unset, never printed.
+
+Examples:
+  dgit whoami                      # Summary
+  dgit whoami --config             # Include every effective setting
+  dgit whoami --json > report.json # Attach to a support request
+  dgit whoami --offline            # Skip remote connectivity checks`,
+	Args: cobra.NoArgs,
+	Run:  runWhoami,
+}
+
+// init sets up command flags for whoami command
+func init() {
+	WhoamiCmd.Flags().Bool("config", false, "List every effective setting")
+	WhoamiCmd.Flags().Bool("json", false, "Print the full report as JSON")
+	WhoamiCmd.Flags().Bool("offline", false, "Do not contact remotes")
+}
+
+// runWhoami gathers and prints the diagnostics report
+func runWhoami(cmd *cobra.Command, args []string) {
+	manager := diag.NewDiagManager(findDgitDirectory())
+	offline, _ := cmd.Flags().GetBool("offline")
+	manager.CheckRemotes = !offline
+	report := manager.Report()
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			exitWithError(fmt.Sprintf("failed to encode report: %v", err), "")
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	id := report.Identity
+	fmt.Println(bold("Identity"))
+	author := id.Author
+	if author == "" {
+		author = yellow("not set")
+	}
+	if id.Email != "" {
+		author += fmt.Sprintf(" <%s>", id.Email)
+	}
+	fmt.Printf("  %-12s %s (%s)\n", "Author:", author, id.Source)
+	fmt.Printf("  %-12s %s@%s\n", "System:", id.User, id.Hostname)
+
+	paths := report.Paths
+	fmt.Println(bold("\nPaths"))
+	fmt.Printf("  %-12s %s\n", "Executable:", paths.Executable)
+	fmt.Printf("  %-12s %s\n", "Working dir:", paths.WorkDir)
+	if paths.DgitDir == "" {
+		fmt.Printf("  %-12s %s\n", "Repository:", yellow("none (not inside a DGit repository)"))
+	} else {
+		fmt.Printf("  %-12s %s\n", "Repository:", paths.Root)
+		fmt.Printf("  %-12s %s\n", "Config:", paths.Config)
+		fmt.Printf("  %-12s %s\n", "Objects:", paths.Objects)
+		fmt.Printf("  %-12s %s\n", "Cache:", paths.Cache)
+	}
+
+	if report.Config != nil {
+		showAll, _ := cmd.Flags().GetBool("config")
+		fmt.Println(bold("\nConfig"))
+		overrides := 0
+		for _, setting := range report.Config {
+			if setting.Source == "env" {
+				overrides++
+			}
+			if showAll || setting.Source == "env" {
+				fmt.Printf("  %-40s %s\n", setting.Key, setting.Value)
+			}
+		}
+		if !showAll {
+			fmt.Printf("  %d setting(s) from %s, %d environment override(s)\n", len(report.Config)-overrides, paths.Config, overrides)
+		}
+	}
+
+	platform := report.Platform
+	fmt.Println(bold("\nPlatform"))
+	fmt.Printf("  %s/%s, %d CPU(s), %s\n", platform.OS, platform.Arch, platform.CPUs, platform.GoVersion)
+	for _, c := range platform.Capabilities {
+		mark := green("yes")
+		if !c.Available {
+			mark = yellow("no ")
+		}
+		fmt.Printf("  %-16s %s  %s\n", c.Name, mark, c.Detail)
+	}
+
+	if len(report.Remotes) > 0 {
+		fmt.Println(bold("\nRemotes"))
+		for _, r := range report.Remotes {
+			if r.Reachable {
+				fmt.Printf("  %-12s %s %s (%.0fms, %d commit(s))\n", r.Name, r.URL, green("ok"), r.LatencyMs, r.Commits)
+			} else {
+				fmt.Printf("  %-12s %s %s\n", r.Name, r.URL, yellow("unreachable"))
+			}
+		}
+	}
+
+	fmt.Println()
+	if len(report.Problems) == 0 {
+		printSuccess("No problems found")
+		return
+	}
+	for _, problem := range report.Problems {
+		printWarning(problem)
+	}
+}
@@ -0,0 +1,279 @@
+package accounting
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Operations recorded by the accounting module
+const (
+	OpCommit  = "commit"
+	OpRestore = "restore"
+	OpDeliver = "deliver"
+	OpBundle  = "bundle"
+	OpServe   = "serve"
+)
+
+// Default estimation factors, used when the repository config leaves them at zero
+const (
+	DefaultGridIntensity        = 475.0 // gCO2e/kWh, global average electricity mix
+	DefaultStorageKWhPerGBMonth = 0.002 // Replicated disk storage incl. overhead
+	DefaultTransferKWhPerGB     = 0.06  // Fixed and mobile network average
+	DefaultComputeWatts         = 30.0  // Laptop/workstation CPU draw during compression
+)
+
+const bytesPerGB = 1 << 30
+
+// Event is one recorded operation
+type Event struct {
+	Time             time.Time `json:"time"`
+	Operation        string    `json:"operation"`
+	Version          int       `json:"version,omitempty"`
+	BytesStored      int64     `json:"bytes_stored,omitempty"`      // New data written to the repository
+	BytesRead        int64     `json:"bytes_read,omitempty"`        // Data written back to the working tree
+	BytesTransferred int64     `json:"bytes_transferred,omitempty"` // Data leaving the machine (deliveries, bundles, downloads)
+	DurationMs       float64   `json:"duration_ms"`
+}
+
+// OperationTotals sums the events of one operation type
+type OperationTotals struct {
+	Operation        string  `json:"operation"`
+	Count            int     `json:"count"`
+	BytesStored      int64   `json:"bytes_stored"`
+	BytesRead        int64   `json:"bytes_read"`
+	BytesTransferred int64   `json:"bytes_transferred"`
+	ComputeSeconds   float64 `json:"compute_seconds"`
+	EnergyKWh        float64 `json:"energy_kwh"`
+}
+
+// Report is the estimated footprint of one calendar month
+type Report struct {
+	Month            string             `json:"month"` // YYYY-MM
+	Operations       []*OperationTotals `json:"operations"`
+	StoredGBMonths   float64            `json:"stored_gb_months"`
+	StorageKWh       float64            `json:"storage_kwh"`
+	TransferKWh      float64            `json:"transfer_kwh"`
+	ComputeKWh       float64            `json:"compute_kwh"`
+	TotalKWh         float64            `json:"total_kwh"`
+	CO2Grams         float64            `json:"co2_grams"`
+	GridIntensity    float64            `json:"grid_intensity"`
+	EventsIncomplete bool               `json:"events_incomplete"` // Accounting was enabled partway through the month
+}
+
+// AccountingManager records usage events and builds footprint reports
+// Events are appended to .dgit/metrics/usage.jsonl next to the performance summary
+type AccountingManager struct {
+	DgitDir   string
+	UsageFile string
+}
+
+// NewAccountingManager creates a new accounting manager for the given .dgit directory
+func NewAccountingManager(dgitDir string) *AccountingManager {
+	return &AccountingManager{
+		DgitDir:   dgitDir,
+		UsageFile: filepath.Join(dgitDir, "metrics", "usage.jsonl"),
+	}
+}
+
+// Enabled reports whether the repository has accounting turned on
+func (am *AccountingManager) Enabled() bool {
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	return err == nil && config.Accounting.Enabled
+}
+
+// SetEnabled turns accounting on or off in the repository config
+func (am *AccountingManager) SetEnabled(enabled bool) error {
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	if err != nil {
+		return err
+	}
+	config.Accounting.Enabled = enabled
+	return initializer.UpdateRepositoryConfig(am.DgitDir, config)
+}
+
+// Record appends an event when accounting is enabled (a no-op otherwise)
+func (am *AccountingManager) Record(event Event) error {
+	if !am.Enabled() {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage event: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(am.UsageFile), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	f, err := os.OpenFile(am.UsageFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage event: %w", err)
+	}
+	return nil
+}
+
+// Events returns all recorded events, oldest first (malformed lines are skipped)
+func (am *AccountingManager) Events() ([]*Event, error) {
+	f, err := os.Open(am.UsageFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer f.Close()
+
+	var events []*Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+			events = append(events, &event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Months lists the months (YYYY-MM) that have recorded events, newest first
+func (am *AccountingManager) Months() ([]string, error) {
+	events, err := am.Events()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var months []string
+	for _, event := range events {
+		month := event.Time.Format("2006-01")
+		if !seen[month] {
+			seen[month] = true
+			months = append(months, month)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	return months, nil
+}
+
+// MonthlyReport estimates the footprint of a calendar month
+// Storage is charged from commit history (bytes kept per day of the month); transfer and compute from recorded events
+func (am *AccountingManager) MonthlyReport(month time.Time) (*Report, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	end := start.AddDate(0, 1, 0)
+	factors := am.factors()
+
+	report := &Report{Month: start.Format("2006-01"), GridIntensity: factors.GridIntensity}
+
+	events, err := am.Events()
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]*OperationTotals)
+	for i, event := range events {
+		if i == 0 && event.Time.After(start) {
+			report.EventsIncomplete = true
+		}
+		if event.Time.Before(start) || !event.Time.Before(end) {
+			continue
+		}
+		t := totals[event.Operation]
+		if t == nil {
+			t = &OperationTotals{Operation: event.Operation}
+			totals[event.Operation] = t
+		}
+		t.Count++
+		t.BytesStored += event.BytesStored
+		t.BytesRead += event.BytesRead
+		t.BytesTransferred += event.BytesTransferred
+		t.ComputeSeconds += event.DurationMs / 1000
+	}
+	if len(events) == 0 {
+		report.EventsIncomplete = true
+	}
+
+	for _, t := range totals {
+		transferKWh := float64(t.BytesTransferred) / bytesPerGB * factors.TransferKWhPerGB
+		computeKWh := t.ComputeSeconds * factors.ComputeWatts / 3.6e6
+		t.EnergyKWh = transferKWh + computeKWh
+		report.TransferKWh += transferKWh
+		report.ComputeKWh += computeKWh
+		report.Operations = append(report.Operations, t)
+	}
+	sort.Slice(report.Operations, func(i, j int) bool {
+		return report.Operations[i].Operation < report.Operations[j].Operation
+	})
+
+	gbMonths, err := am.storedGBMonths(start, end)
+	if err != nil {
+		return nil, err
+	}
+	report.StoredGBMonths = gbMonths
+	report.StorageKWh = gbMonths * factors.StorageKWhPerGBMonth
+	report.TotalKWh = report.StorageKWh + report.TransferKWh + report.ComputeKWh
+	report.CO2Grams = report.TotalKWh * factors.GridIntensity
+	return report, nil
+}
+
+// storedGBMonths integrates stored commit data over the month, pro-rating versions added during it
+func (am *AccountingManager) storedGBMonths(start, end time.Time) (float64, error) {
+	if now := time.Now(); end.After(now) {
+		end = now // The current month is only charged up to today
+	}
+	if !end.After(start) {
+		return 0, nil
+	}
+	period := end.Sub(start)
+	month := start.AddDate(0, 1, 0).Sub(start)
+
+	it, err := log.NewLogManager(am.DgitDir).Iterate(log.CommitFilter{Until: end})
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	var gbMonths float64
+	for it.Next() {
+		c := it.Commit()
+		if c.CompressionInfo == nil {
+			continue
+		}
+		held := period
+		if c.Timestamp.After(start) {
+			held = end.Sub(c.Timestamp)
+		}
+		gbMonths += float64(c.CompressionInfo.CompressedSize) / bytesPerGB * (held.Hours() / month.Hours())
+	}
+	return gbMonths, it.Err()
+}
+
+// factors returns the configured estimation factors with defaults filled in
+func (am *AccountingManager) factors() initializer.AccountingConfig {
+	factors := initializer.AccountingConfig{}
+	if config, err := initializer.GetRepositoryConfig(am.DgitDir); err == nil {
+		factors = config.Accounting
+	}
+	if factors.GridIntensity == 0 {
+		factors.GridIntensity = DefaultGridIntensity
+	}
+	if factors.StorageKWhPerGBMonth == 0 {
+		factors.StorageKWhPerGBMonth = DefaultStorageKWhPerGBMonth
+	}
+	if factors.TransferKWhPerGB == 0 {
+		factors.TransferKWhPerGB = DefaultTransferKWhPerGB
+	}
+	if factors.ComputeWatts == 0 {
+		factors.ComputeWatts = DefaultComputeWatts
+	}
+	return factors
+}
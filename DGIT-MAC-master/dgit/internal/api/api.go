@@ -0,0 +1,222 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/pin"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// SchemaVersion is bumped only for incompatible changes to the document
+// New fields may be added within a schema version; consumers should ignore fields they do not know
+const SchemaVersion = 1
+
+// File states reported for tracked files
+const (
+	StateUnchanged = "unchanged" // Size and modification time match the latest commit
+	StateModified  = "modified"  // Size or modification time differ from the latest commit
+	StateMissing   = "missing"   // Committed but not in the working tree
+	StatePinned    = "pinned"    // Held at an older version with 'dgit pin'; commits of it are refused
+)
+
+// Document is the repository state returned by 'dgit api dump' and GET /api/state
+type Document struct {
+	Schema      int          `json:"schema"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Root        string       `json:"root"`
+	Head        *VersionInfo `json:"head,omitempty"`
+	Versions    int          `json:"versions"`
+	Files       []*FileInfo  `json:"files"`
+}
+
+// VersionInfo summarizes one commit
+type VersionInfo struct {
+	Version   int       `json:"version"`
+	Hash      string    `json:"hash"`
+	Message   string    `json:"message"`
+	Author    string    `json:"author"`
+	Timestamp time.Time `json:"timestamp"`
+	Approval  string    `json:"approval,omitempty"` // draft, review, approved, delivered
+}
+
+// FileInfo describes one tracked file
+type FileInfo struct {
+	Path          string `json:"path"`
+	Type          string `json:"type,omitempty"`
+	LatestVersion int    `json:"latest_version"`
+	VersionCount  int    `json:"version_count"`
+	SHA256        string `json:"sha256,omitempty"` // Content hash in the latest version
+	Size          int64  `json:"size"`
+	State         string `json:"state"`
+	PinnedVersion int    `json:"pinned_version,omitempty"`
+	Preview       string `json:"preview,omitempty"` // Newest proxy, relative to the repository root
+}
+
+// APIManager builds the machine-readable state document for DAM systems and editor plugins
+// The document is computed from commit metadata and file stats only, so polling it stays cheap
+type APIManager struct {
+	DgitDir string
+	RootDir string
+}
+
+// NewAPIManager creates a new API manager for the given .dgit directory
+func NewAPIManager(dgitDir string) *APIManager {
+	return &APIManager{
+		DgitDir: dgitDir,
+		RootDir: filepath.Dir(dgitDir),
+	}
+}
+
+// Dump returns the current repository state
+func (am *APIManager) Dump() (*Document, error) {
+	logManager := log.NewLogManager(am.DgitDir)
+	commits, err := logManager.GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Version < commits[j].Version })
+
+	doc := &Document{
+		Schema:      SchemaVersion,
+		GeneratedAt: time.Now().UTC(),
+		Root:        am.RootDir,
+		Versions:    len(commits),
+		Files:       []*FileInfo{},
+	}
+
+	head := logManager.GetHeadVersion()
+	files := make(map[string]*FileInfo)
+	committed := make(map[string]map[string]interface{})
+	for _, c := range commits {
+		if c.Version == head {
+			doc.Head = am.versionInfo(c)
+		}
+		// Moved files continue under their new path
+		for newPath, oldPath := range c.Renames {
+			if info := files[oldPath]; info != nil {
+				delete(files, oldPath)
+				delete(committed, oldPath)
+				info.Path = newPath
+				files[newPath] = info
+			}
+		}
+		for path, raw := range c.Metadata {
+			path = filepath.ToSlash(path)
+			info := files[path]
+			if info == nil {
+				info = &FileInfo{Path: path}
+				files[path] = info
+			}
+			info.LatestVersion = c.Version
+			info.VersionCount++
+			meta, _ := raw.(map[string]interface{})
+			committed[path] = meta
+		}
+	}
+
+	pins := make(map[string]*pin.Pin)
+	if list, err := pin.NewPinManager(am.DgitDir).GetPins(); err == nil {
+		for _, p := range list {
+			pins[p.Path] = p
+		}
+	}
+	previews := am.latestPreviews()
+
+	for path, info := range files {
+		meta := committed[path]
+		info.Type, _ = meta["type"].(string)
+		info.SHA256, _ = meta["sha256"].(string)
+		if size, ok := meta["size"].(float64); ok {
+			info.Size = int64(size)
+		}
+		info.State = am.fileState(path, meta)
+		if p := pins[path]; p != nil {
+			info.State = StatePinned
+			info.PinnedVersion = p.Version
+		}
+		info.Preview = previews[path]
+		doc.Files = append(doc.Files, info)
+	}
+	sort.Slice(doc.Files, func(i, j int) bool { return doc.Files[i].Path < doc.Files[j].Path })
+	return doc, nil
+}
+
+// OpenFile streams a file as committed in a version without restoring it to disk
+// The caller must close the reader; reading it to the end verifies the content (see restore.OpenFile)
+func (am *APIManager) OpenFile(version int, path string) (io.ReadCloser, error) {
+	return restore.NewRestoreManager(am.DgitDir).OpenFile(version, path)
+}
+
+// ETag fingerprints the parts of a document that change between polls
+func ETag(doc *Document) string {
+	stable := *doc
+	stable.GeneratedAt = time.Time{}
+	data, _ := json.Marshal(stable)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// versionInfo summarizes a commit with its approval state
+func (am *APIManager) versionInfo(c *log.Commit) *VersionInfo {
+	info := &VersionInfo{
+		Version:   c.Version,
+		Hash:      c.Hash,
+		Message:   c.Message,
+		Author:    c.Author,
+		Timestamp: c.Timestamp,
+	}
+	if state, err := approval.NewApprovalManager(am.DgitDir).GetState(c); err == nil {
+		info.Approval = state.State
+	}
+	return info
+}
+
+// fileState compares the working copy with the latest commit by size and modification time
+// Hashing every file would make each poll read the whole working tree
+func (am *APIManager) fileState(path string, meta map[string]interface{}) string {
+	stat, err := os.Stat(filepath.Join(am.RootDir, filepath.FromSlash(path)))
+	if err != nil {
+		return StateMissing
+	}
+	if size, ok := meta["size"].(float64); ok && int64(size) != stat.Size() {
+		return StateModified
+	}
+	if stamp, ok := meta["last_modified"].(string); ok {
+		if committed, err := time.Parse(time.RFC3339Nano, stamp); err == nil && !committed.Equal(stat.ModTime()) {
+			return StateModified
+		}
+	}
+	return StateUnchanged
+}
+
+// latestPreviews maps each source path to its newest proxy, relative to the repository root
+func (am *APIManager) latestPreviews() map[string]string {
+	latest := make(map[string]string)
+	list, err := preview.NewPreviewManager(am.DgitDir).List(0)
+	if err != nil {
+		return latest
+	}
+	// List is newest version first, so the first proxy seen for a source wins
+	for _, p := range list {
+		source := filepath.ToSlash(p.Source)
+		if _, ok := latest[source]; ok {
+			continue
+		}
+		if rel, err := filepath.Rel(am.RootDir, p.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			latest[source] = filepath.ToSlash(rel)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,254 @@
+package approval
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Commit workflow states in the order a design moves through them
+const (
+	StateDraft     = "draft"
+	StateReview    = "review"
+	StateApproved  = "approved"
+	StateDelivered = "delivered"
+)
+
+// States lists all workflow states from least to most mature
+var States = []string{StateDraft, StateReview, StateApproved, StateDelivered}
+
+// Note is a single signed state change attached to a commit
+// Notes are append-only - the latest verified note is the commit's current state
+type Note struct {
+	Version    int       `json:"version"`
+	CommitHash string    `json:"commit_hash"`
+	State      string    `json:"state"`
+	By         string    `json:"by"`
+	Message    string    `json:"message,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Signature  string    `json:"signature"`
+}
+
+// CommitState summarizes the approval state of a commit
+type CommitState struct {
+	State      string
+	Note       *Note // Latest verified note (nil for draft commits without notes)
+	Unverified int   // Notes whose signature did not verify and were ignored
+}
+
+// ApprovalManager stores and verifies approval notes for a DGit repository
+type ApprovalManager struct {
+	DgitDir  string
+	NotesDir string
+	KeyFile  string
+}
+
+// NewApprovalManager creates a new approval manager for the given .dgit directory
+func NewApprovalManager(dgitDir string) *ApprovalManager {
+	return &ApprovalManager{
+		DgitDir:  dgitDir,
+		NotesDir: filepath.Join(dgitDir, "notes", "approvals"),
+		KeyFile:  filepath.Join(dgitDir, "keys", "notes.key"),
+	}
+}
+
+// IsValidState checks whether a state name is a known workflow state
+func IsValidState(state string) bool {
+	return stateRank(state) >= 0
+}
+
+// AtLeast reports whether state has reached the required workflow state
+func AtLeast(state, required string) bool {
+	return stateRank(state) >= stateRank(required)
+}
+
+// SetState appends a signed note moving a commit to a new workflow state
+func (am *ApprovalManager) SetState(commit *log.Commit, state, by, message string) (*Note, error) {
+	state = strings.ToLower(state)
+	if !IsValidState(state) {
+		return nil, fmt.Errorf("unknown state %q (valid: %s)", state, strings.Join(States, ", "))
+	}
+	if strings.TrimSpace(by) == "" {
+		return nil, fmt.Errorf("approver name is required")
+	}
+
+	key, err := am.loadOrCreateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	note := &Note{
+		Version:    commit.Version,
+		CommitHash: commit.Hash,
+		State:      state,
+		By:         by,
+		Message:    message,
+		Timestamp:  time.Now(),
+	}
+	note.Signature = signNote(key, note)
+
+	notes, err := am.loadNotes(commit.Version)
+	if err != nil {
+		return nil, err
+	}
+	notes = append(notes, note)
+
+	if err := am.saveNotes(commit.Version, notes); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// GetState returns the current verified workflow state of a commit
+func (am *ApprovalManager) GetState(commit *log.Commit) (*CommitState, error) {
+	result := &CommitState{State: StateDraft}
+
+	notes, err := am.loadNotes(commit.Version)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return result, nil
+	}
+
+	key, err := am.loadKey()
+	if err != nil {
+		result.Unverified = len(notes)
+		return result, nil
+	}
+
+	// Walk the history - the latest note with a valid signature for this commit wins
+	for _, note := range notes {
+		if note.CommitHash != commit.Hash || !hmac.Equal([]byte(note.Signature), []byte(signNote(key, note))) {
+			result.Unverified++
+			continue
+		}
+		result.State = note.State
+		result.Note = note
+	}
+	return result, nil
+}
+
+// GetHistory returns every note recorded for a commit in chronological order
+func (am *ApprovalManager) GetHistory(version int) ([]*Note, error) {
+	return am.loadNotes(version)
+}
+
+// RequireState returns an error unless the commit has reached the required state
+func (am *ApprovalManager) RequireState(commit *log.Commit, required string) error {
+	current, err := am.GetState(commit)
+	if err != nil {
+		return err
+	}
+	if !AtLeast(current.State, required) {
+		return fmt.Errorf("v%d is %s, but %s is required", commit.Version, current.State, required)
+	}
+	return nil
+}
+
+// EnforcePolicy checks the repository approval policy for an action such as "deliver" or "export"
+// Actions without a configured requirement are always allowed
+func (am *ApprovalManager) EnforcePolicy(action string, commit *log.Commit) error {
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	if err != nil || config.Approval.RequiredStates == nil {
+		return nil
+	}
+	required, ok := config.Approval.RequiredStates[action]
+	if !ok || required == "" {
+		return nil
+	}
+	if err := am.RequireState(commit, required); err != nil {
+		return fmt.Errorf("%s blocked by approval policy: %w", action, err)
+	}
+	return nil
+}
+
+// loadNotes reads the note history for a version (empty if none)
+func (am *ApprovalManager) loadNotes(version int) ([]*Note, error) {
+	var notes []*Note
+	data, err := os.ReadFile(am.notePath(version))
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approval notes: %w", err)
+	}
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("failed to parse approval notes: %w", err)
+	}
+	return notes, nil
+}
+
+// saveNotes writes the note history for a version
+func (am *ApprovalManager) saveNotes(version int, notes []*Note) error {
+	if err := os.MkdirAll(am.NotesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create notes directory: %w", err)
+	}
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval notes: %w", err)
+	}
+	return os.WriteFile(am.notePath(version), data, 0644)
+}
+
+// notePath returns the note file for a version
+func (am *ApprovalManager) notePath(version int) string {
+	return filepath.Join(am.NotesDir, fmt.Sprintf("v%d.json", version))
+}
+
+// loadKey reads the repository signing key
+func (am *ApprovalManager) loadKey() ([]byte, error) {
+	data, err := os.ReadFile(am.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(data)))
+}
+
+// loadOrCreateKey reads the signing key, generating one on first use
+func (am *ApprovalManager) loadOrCreateKey() ([]byte, error) {
+	if key, err := am.loadKey(); err == nil {
+		return key, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(am.KeyFile), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := os.WriteFile(am.KeyFile, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return key, nil
+}
+
+// signNote computes the HMAC-SHA256 signature over a note's content
+func signNote(key []byte, note *Note) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%d|%s|%s|%s|%s|%s",
+		note.Version, note.CommitHash, note.State, note.By, note.Message,
+		note.Timestamp.UTC().Format(time.RFC3339Nano))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// stateRank returns the position of a state in the workflow (-1 if unknown)
+func stateRank(state string) int {
+	for i, s := range States {
+		if s == state {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,705 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/storage"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Lifecycle hooks for external tape/HSM systems
+const (
+	HookOnArchive = "on-archive" // Runs with DGIT_BLOB after a version is written to the cold tier
+	HookOnRecall  = "on-recall"  // Runs with DGIT_BLOB when a restore needs an offline version back
+)
+
+// Defaults for waiting on a recall
+const (
+	DefaultRecallTimeout = 240 * time.Minute
+	DefaultPollInterval  = 5 * time.Second
+)
+
+// Record is the archive state of one version
+type Record struct {
+	Version    int        `json:"version"`
+	Blob       string     `json:"blob"` // Cold-tier object relative to .dgit
+	Size       int64      `json:"size"`
+	SHA256     string     `json:"sha256"`
+	ArchivedAt time.Time  `json:"archived_at"`
+	Offline    bool       `json:"offline"`           // The on-archive hook or storage backend moved the blob off this machine
+	Storage    string     `json:"storage,omitempty"` // Backend holding a copy of the blob, e.g. "s3"
+	RecalledAt *time.Time `json:"recalled_at,omitempty"`
+	Files      []string   `json:"files,omitempty"` // Paths in the blob, so the cold index can locate a file without decoding blobs
+}
+
+// IndexEntry is one blob in the cold index, cache/cold/index/archives.json
+type IndexEntry struct {
+	Version    int       `json:"version"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	ArchivedAt time.Time `json:"archived_at"`
+	Offline    bool      `json:"offline"`
+	Files      []string  `json:"files"`
+}
+
+// DefaultArchiveLevel is used when archive_stage.compression_level is unset
+const DefaultArchiveLevel = 22
+
+// RecallProgress is reported while a restore waits for a recalled blob
+type RecallProgress struct {
+	Version int
+	Waited  time.Duration
+	Arrived int64 // Bytes of the blob present so far
+	Size    int64
+}
+
+// ArchiveManager moves versions to the cold tier and brings them back through lifecycle hooks
+type ArchiveManager struct {
+	DgitDir      string
+	HotCacheDir  string
+	WarmCacheDir string
+	ColdCacheDir string
+	StateFile    string
+	IndexFile    string // Cold index keyed by blob name, rewritten with the state
+}
+
+// NewArchiveManager creates a new archive manager for the given .dgit directory
+func NewArchiveManager(dgitDir string) *ArchiveManager {
+	return &ArchiveManager{
+		DgitDir:      dgitDir,
+		HotCacheDir:  filepath.Join(dgitDir, "cache", "hot"),
+		WarmCacheDir: filepath.Join(dgitDir, "cache", "warm"),
+		ColdCacheDir: filepath.Join(dgitDir, "cache", "cold"),
+		StateFile:    filepath.Join(dgitDir, "archive.json"),
+		IndexFile:    filepath.Join(dgitDir, "cache", "cold", "index", "archives.json"),
+	}
+}
+
+// BlobPath is where a version's cold-tier object lives, and where on-recall must put it back
+func (am *ArchiveManager) BlobPath(version int) string {
+	return filepath.Join(am.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", version))
+}
+
+// Get returns the archive record of a version
+func (am *ArchiveManager) Get(version int) (*Record, bool) {
+	records, err := am.load()
+	if err != nil {
+		return nil, false
+	}
+	record, ok := records[version]
+	return record, ok
+}
+
+// List returns every archived version, oldest first
+func (am *ArchiveManager) List() ([]*Record, error) {
+	records, err := am.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// Expired lists the snapshot and object store versions committed more than archive_stage.archive_after_days before now
+// that are not archived yet, oldest first; the newest version is never due
+// Nothing is due when the archive stage is disabled or archive_after_days is 0
+func (am *ArchiveManager) Expired(now time.Time) ([]int, error) {
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+	stage := config.Compression.ArchiveConfig
+	if !stage.Enabled || stage.ArchiveAfterDays <= 0 {
+		return nil, nil
+	}
+	records, err := am.load()
+	if err != nil {
+		return nil, err
+	}
+	logManager := log.NewLogManager(am.DgitDir)
+	newest := logManager.GetCurrentVersion()
+	cutoff := now.AddDate(0, 0, -stage.ArchiveAfterDays)
+	it, err := logManager.Iterate(log.CommitFilter{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var due []int
+	for it.Next() {
+		commit := it.Commit()
+		info := commit.CompressionInfo
+		if commit.Version == newest || !commit.Timestamp.Before(cutoff) || records[commit.Version] != nil {
+			continue
+		}
+		if info == nil || !archivableStrategy(info.Strategy) {
+			continue
+		}
+		due = append(due, commit.Version)
+	}
+	sort.Ints(due)
+	return due, it.Err()
+}
+
+// Archive writes a version to the cold tier, drops its hot and warm copies and runs on-archive
+// Object store versions pack only the blobs no online version references, and only those blobs leave the store
+// With a storage backend configured the blob is uploaded and its local copy removed before the hook runs
+// Archiving a version whose blob is still local only uploads it and runs the hook again
+func (am *ArchiveManager) Archive(version int) (*Record, error) {
+	logManager := log.NewLogManager(am.DgitDir)
+	commit, err := logManager.GetCommit(version)
+	if err != nil {
+		return nil, fmt.Errorf("v%d not found: %w", version, err)
+	}
+	records, err := am.load()
+	if err != nil {
+		return nil, err
+	}
+	blob := am.BlobPath(version)
+
+	record, archived := records[version]
+	if archived && record.Offline {
+		return nil, fmt.Errorf("v%d is already archived and offline", version)
+	}
+	if !archived {
+		if err := am.checkArchivable(logManager, commit); err != nil {
+			return nil, err
+		}
+		var files, sources []string
+		if commit.CompressionInfo.Strategy == objstore.Strategy {
+			files, sources, err = am.writeContentBlob(logManager, commit, records, blob)
+		} else {
+			sources, err = am.writeColdBlob(commit, blob)
+			for path := range commit.Metadata {
+				files = append(files, path)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		size, sum, err := hashFile(blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cold blob: %w", err)
+		}
+		for _, source := range sources {
+			os.Remove(source)
+		}
+		rel, _ := filepath.Rel(am.DgitDir, blob)
+		record = &Record{Version: version, Blob: filepath.ToSlash(rel), Size: size, SHA256: sum, ArchivedAt: time.Now(), Files: files}
+		sort.Strings(record.Files)
+		records[version] = record
+		if err := am.save(records); err != nil {
+			return nil, err
+		}
+		if err := am.markCold(logManager, version); err != nil {
+			return record, err
+		}
+	}
+
+	if err := am.offload(records, record, blob); err != nil {
+		return nil, err
+	}
+
+	hookErr := hooks.Run(am.DgitDir, HookOnArchive, am.hookEnv(commit, blob))
+	if _, err := os.Stat(blob); os.IsNotExist(err) {
+		record.Offline = true
+		if err := am.save(records); err != nil {
+			return nil, err
+		}
+	}
+	if hookErr != nil {
+		return record, hookErr
+	}
+	return record, nil
+}
+
+// Recall makes an archived version's blob local again, fetching it from the storage backend that holds it
+// or running on-recall; progress is called every poll until the blob has fully arrived or the configured timeout passes
+func (am *ArchiveManager) Recall(version int, progress func(RecallProgress)) (*Record, error) {
+	records, err := am.load()
+	if err != nil {
+		return nil, err
+	}
+	record, ok := records[version]
+	if !ok {
+		return nil, fmt.Errorf("v%d is not archived", version)
+	}
+	blob := am.BlobPath(version)
+
+	if !am.arrived(blob, record) && record.Storage != "" {
+		if err := am.fetch(record); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(RecallProgress{Version: version, Arrived: record.Size, Size: record.Size})
+		}
+	}
+	if !am.arrived(blob, record) {
+		if len(hooks.Scripts(am.DgitDir, HookOnRecall)) == 0 {
+			return nil, fmt.Errorf("v%d is offline and no %s hook is installed to bring it back", version, HookOnRecall)
+		}
+		commit, err := log.NewLogManager(am.DgitDir).GetCommit(version)
+		if err != nil {
+			return nil, fmt.Errorf("v%d not found: %w", version, err)
+		}
+		if err := os.MkdirAll(am.ColdCacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cold cache directory: %w", err)
+		}
+		if err := hooks.Run(am.DgitDir, HookOnRecall, am.hookEnv(commit, blob)); err != nil {
+			return nil, err
+		}
+		if err := am.waitFor(blob, record, progress); err != nil {
+			return nil, err
+		}
+	}
+
+	_, sum, err := hashFile(blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recalled blob: %w", err)
+	}
+	if sum != record.SHA256 {
+		return nil, fmt.Errorf("recalled blob for v%d does not match the archived checksum", version)
+	}
+	if record.Offline {
+		now := time.Now()
+		record.Offline, record.RecalledAt = false, &now
+		if err := am.save(records); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// offload uploads a local cold blob to the configured storage backend and removes the local copy
+// Nothing happens when no backend is configured
+func (am *ArchiveManager) offload(records map[int]*Record, record *Record, blob string) error {
+	backend, err := storage.Open(am.DgitDir)
+	if err != nil || backend == nil {
+		return err
+	}
+	if _, err := os.Stat(blob); err != nil {
+		return nil // Already off this machine
+	}
+	if _, err := storage.Upload(backend, am.DgitDir, filepath.FromSlash(record.Blob)); err != nil {
+		return err
+	}
+	record.Storage = backend.Name()
+	if err := am.save(records); err != nil {
+		return err
+	}
+	if err := os.Remove(blob); err != nil {
+		return fmt.Errorf("v%d was uploaded to %s but its local copy could not be removed: %w", record.Version, backend.Describe(), err)
+	}
+	return nil
+}
+
+// fetch downloads an offline blob from the storage backend it was uploaded to
+func (am *ArchiveManager) fetch(record *Record) error {
+	config, err := storage.GetConfig(am.DgitDir)
+	if err != nil {
+		return err
+	}
+	if config.Backend != record.Storage {
+		return fmt.Errorf("v%d was archived to %s storage, but the repository is configured for %q", record.Version, record.Storage, config.Backend)
+	}
+	backend, err := storage.New(am.DgitDir, config)
+	if err != nil {
+		return err
+	}
+	if _, err := storage.Download(backend, am.DgitDir, filepath.FromSlash(record.Blob)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// waitFor polls until the blob is back at its full size
+func (am *ArchiveManager) waitFor(blob string, record *Record, progress func(RecallProgress)) error {
+	timeout, interval := DefaultRecallTimeout, DefaultPollInterval
+	if config, err := initializer.GetRepositoryConfig(am.DgitDir); err == nil {
+		if config.Archive.RecallTimeoutMinutes > 0 {
+			timeout = time.Duration(config.Archive.RecallTimeoutMinutes) * time.Minute
+		}
+		if config.Archive.PollSeconds > 0 {
+			interval = time.Duration(config.Archive.PollSeconds) * time.Second
+		}
+	}
+
+	started := time.Now()
+	for {
+		status := RecallProgress{Version: record.Version, Waited: time.Since(started), Size: record.Size}
+		if info, err := os.Stat(blob); err == nil {
+			status.Arrived = info.Size()
+		}
+		if progress != nil {
+			progress(status)
+		}
+		if am.arrived(blob, record) {
+			return nil
+		}
+		if status.Waited >= timeout {
+			return fmt.Errorf("v%d did not come back within %s; check the %s hook", record.Version, timeout, HookOnRecall)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// arrived reports whether the blob is present at its archived size
+func (am *ArchiveManager) arrived(blob string, record *Record) bool {
+	info, err := os.Stat(blob)
+	return err == nil && info.Size() == record.Size
+}
+
+// archivableStrategy reports whether versions stored with a strategy can move to the cold tier
+func archivableStrategy(strategy string) bool {
+	return strategy == codec.LZ4 || strategy == codec.Store || strategy == objstore.Strategy
+}
+
+// checkArchivable rejects versions that are neither snapshots nor object store versions, or that deltas depend on
+func (am *ArchiveManager) checkArchivable(logManager *log.LogManager, commit *log.Commit) error {
+	info := commit.CompressionInfo
+	if info == nil || !archivableStrategy(info.Strategy) {
+		strategy := "legacy zip"
+		if info != nil {
+			strategy = info.Strategy
+		}
+		return fmt.Errorf("v%d is stored as %s; only snapshot and object store versions can be archived", commit.Version, strategy)
+	}
+	history, err := logManager.GetCommitHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load commit history: %w", err)
+	}
+	for _, c := range history {
+		if c.CompressionInfo != nil && c.CompressionInfo.BaseVersion == commit.Version {
+			return fmt.Errorf("v%d is the delta base of v%d and must stay online", commit.Version, c.Version)
+		}
+	}
+	return nil
+}
+
+// writeColdBlob recompresses a version's hot or warm object at the highest Zstd level
+// Returns the hot and warm copies the blob replaces
+func (am *ArchiveManager) writeColdBlob(commit *log.Commit, blob string) ([]string, error) {
+	var sources []string
+	hotPath := filepath.Join(am.HotCacheDir, commit.CompressionInfo.OutputFile)
+	warmPath := filepath.Join(am.WarmCacheDir, fmt.Sprintf("v%d.zstd", commit.Version))
+	for _, path := range []string{hotPath, warmPath} {
+		if _, err := os.Stat(path); err == nil {
+			sources = append(sources, path)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no hot or warm copy of v%d to archive", commit.Version)
+	}
+
+	source, err := os.Open(sources[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to open v%d: %w", commit.Version, err)
+	}
+	defer source.Close()
+	c, ok := codec.ForObject(sources[0])
+	if !ok {
+		return nil, fmt.Errorf("unknown object format %s", filepath.Base(sources[0]))
+	}
+	decoded, err := c.NewReader(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode v%d: %w", commit.Version, err)
+	}
+	defer decoded.Close()
+
+	err = am.encodeColdBlob(blob, commit.Version, func(w io.Writer) error {
+		_, err := io.Copy(w, decoded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// writeContentBlob packs the object store blobs of a version that no online version references
+// into a framed cold blob; blobs still referenced stay in the store and are not packed
+// Returns the packed paths and the blob files the cold blob replaces
+func (am *ArchiveManager) writeContentBlob(logManager *log.LogManager, commit *log.Commit, records map[int]*Record, blob string) ([]string, []string, error) {
+	online, err := am.onlineBlobs(logManager, commit.Version, records)
+	if err != nil {
+		return nil, nil, err
+	}
+	store := objstore.NewObjectStore(am.DgitDir)
+	var packed, sources []string
+	seen := make(map[string]bool)
+	for path, ref := range commit.Blobs {
+		if ref == nil || online[ref.Hash] {
+			continue
+		}
+		packed = append(packed, path)
+		if source := store.Find(ref.Hash); source != "" && !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(packed)
+
+	err = am.encodeColdBlob(blob, commit.Version, func(w io.Writer) error {
+		frames, err := codec.NewFrameWriter(w)
+		if err != nil {
+			return err
+		}
+		for _, path := range packed {
+			if err := packBlob(frames, store, path, commit.Blobs[path]); err != nil {
+				return err
+			}
+		}
+		return frames.Close()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return packed, sources, nil
+}
+
+// packBlob writes one blob into a framed stream, reading it to the end so its content hash is checked
+func packBlob(frames *codec.FrameWriter, store *objstore.ObjectStore, path string, ref *log.BlobRef) error {
+	reader, err := store.Open(ref.Hash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer reader.Close()
+	if _, err := frames.WriteFile(codec.FrameHeader{Path: path, Size: ref.Size, Mode: ref.Mode}, reader); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if extra, err := io.Copy(io.Discard, reader); err != nil || extra > 0 {
+		return fmt.Errorf("%s: blob does not match its recorded size or hash", path)
+	}
+	return nil
+}
+
+// onlineBlobs returns the hashes other versions still read from the object store:
+// those of versions not archived, and those an archived version left out of its cold blob
+func (am *ArchiveManager) onlineBlobs(logManager *log.LogManager, version int, records map[int]*Record) (map[string]bool, error) {
+	history, err := logManager.GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit history: %w", err)
+	}
+	online := make(map[string]bool)
+	for _, c := range history {
+		if c.Version == version {
+			continue
+		}
+		packed := make(map[string]bool)
+		if record := records[c.Version]; record != nil {
+			for _, path := range record.Files {
+				packed[path] = true
+			}
+		}
+		for path, ref := range c.Blobs {
+			if ref != nil && !packed[path] {
+				online[ref.Hash] = true
+			}
+		}
+	}
+	return online, nil
+}
+
+// encodeColdBlob writes the content produced by write to a cold blob at the highest Zstd level
+// The blob only replaces its sources once it is known to decode to exactly what was written
+func (am *ArchiveManager) encodeColdBlob(blob string, version int, write func(io.Writer) error) error {
+	if err := os.MkdirAll(am.ColdCacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create cold cache directory: %w", err)
+	}
+	tmp := blob + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create cold blob: %w", err)
+	}
+	stage := am.stageConfig()
+	decodedHash := sha256.New()
+	encoder, err := zstd.NewWriter(out, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(stage.CompressionLevel)))
+	if err == nil {
+		err = write(io.MultiWriter(encoder, decodedHash))
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cold blob for v%d: %w", version, err)
+	}
+	if info, err := os.Stat(tmp); err == nil && stage.MaxArchiveSize > 0 && info.Size() > stage.MaxArchiveSize {
+		os.Remove(tmp)
+		return fmt.Errorf("v%d compresses to %d bytes, over archive_stage.max_archive_size", version, info.Size())
+	}
+	// The hot and warm copies are deleted next, so the blob must decode to exactly what they held
+	if sum, err := decodedSum(tmp); err != nil || !bytes.Equal(sum, decodedHash.Sum(nil)) {
+		os.Remove(tmp)
+		return fmt.Errorf("cold blob for v%d does not decode to the archived content", version)
+	}
+	if err := os.Rename(tmp, blob); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cold blob for v%d: %w", version, err)
+	}
+	return nil
+}
+
+// stageConfig reads archive_stage, defaulting the level to 22
+func (am *ArchiveManager) stageConfig() initializer.ArchiveStageConfig {
+	stage := initializer.ArchiveStageConfig{CompressionLevel: DefaultArchiveLevel}
+	if config, err := initializer.GetRepositoryConfig(am.DgitDir); err == nil {
+		stage = config.Compression.ArchiveConfig
+		if stage.CompressionLevel <= 0 {
+			stage.CompressionLevel = DefaultArchiveLevel
+		}
+	}
+	return stage
+}
+
+// markCold records on the commit that its snapshot now lives in the cold tier
+// Only compression_info.cache_level changes; other fields are kept as stored
+func (am *ArchiveManager) markCold(logManager *log.LogManager, version int) error {
+	data, err := logManager.LoadCommitData(version)
+	if err != nil {
+		return fmt.Errorf("failed to load v%d: %w", version, err)
+	}
+	var fields, info map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to parse v%d: %w", version, err)
+	}
+	if err := json.Unmarshal(fields["compression_info"], &info); err != nil || info == nil {
+		return nil
+	}
+	info["cache_level"] = json.RawMessage(`"cold"`)
+	if fields["compression_info"], err = json.Marshal(info); err != nil {
+		return fmt.Errorf("failed to marshal v%d: %w", version, err)
+	}
+	if data, err = json.MarshalIndent(fields, "", "  "); err != nil {
+		return fmt.Errorf("failed to marshal v%d: %w", version, err)
+	}
+	return logManager.SaveCommitData(data)
+}
+
+// decodedSum returns the SHA256 of a Zstd object's decoded content
+func decodedSum(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, decoder); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// hookEnv describes the version and blob to on-archive and on-recall
+func (am *ArchiveManager) hookEnv(commit *log.Commit, blob string) map[string]string {
+	return map[string]string{
+		"DGIT_VERSION": strconv.Itoa(commit.Version),
+		"DGIT_HASH":    commit.Hash,
+		"DGIT_MESSAGE": commit.Message,
+		"DGIT_BLOB":    blob,
+	}
+}
+
+// load reads the archive records keyed by version
+func (am *ArchiveManager) load() (map[int]*Record, error) {
+	records := make(map[int]*Record)
+	data, err := os.ReadFile(am.StateFile)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive state: %w", err)
+	}
+	var list []*Record
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse archive state: %w", err)
+	}
+	for _, record := range list {
+		records[record.Version] = record
+	}
+	return records, nil
+}
+
+// save writes the archive records to disk
+func (am *ArchiveManager) save(records map[int]*Record) error {
+	list := make([]*Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive state: %w", err)
+	}
+	if err := os.WriteFile(am.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write archive state: %w", err)
+	}
+	return am.saveIndex(list)
+}
+
+// saveIndex rewrites the cold index from the archive records
+func (am *ArchiveManager) saveIndex(list []*Record) error {
+	index := make(map[string]*IndexEntry, len(list))
+	for _, record := range list {
+		files := record.Files
+		if files == nil {
+			files = []string{}
+		}
+		index[filepath.Base(record.Blob)] = &IndexEntry{Version: record.Version, Size: record.Size, SHA256: record.SHA256,
+			ArchivedAt: record.ArchivedAt, Offline: record.Offline, Files: files}
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cold index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(am.IndexFile), 0755); err != nil {
+		return fmt.Errorf("failed to create cold index directory: %w", err)
+	}
+	if err := os.WriteFile(am.IndexFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cold index: %w", err)
+	}
+	return nil
+}
+
+// hashFile returns the size and SHA256 of a file
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
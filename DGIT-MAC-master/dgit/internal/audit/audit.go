@@ -0,0 +1,282 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+)
+
+// DefaultMinContrast is the WCAG 2 AA ratio for body text
+const DefaultMinContrast = 4.5
+
+// Region analysis parameters
+const (
+	tileSize      = 24   // Region edge in sampled pixels
+	maxSampleEdge = 1600 // Larger previews are sampled with a stride
+	minForeground = 0.03 // Share of a region the second color needs to count as text
+	maxForeground = 0.5
+	minCoverage   = 0.8 // Both colors together must cover this much of a region, which excludes photos
+	minDistance   = 48  // RGB distance between the two colors
+)
+
+// AuditManager runs WCAG contrast checks on image previews and records the findings in commit metadata
+type AuditManager struct {
+	DgitDir string
+}
+
+// NewAuditManager creates a new audit manager for the given .dgit directory
+func NewAuditManager(dgitDir string) *AuditManager {
+	return &AuditManager{DgitDir: dgitDir}
+}
+
+// GetConfig returns the audit settings with defaults applied
+func (am *AuditManager) GetConfig() (initializer.AuditConfig, error) {
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	if err != nil {
+		return initializer.AuditConfig{}, err
+	}
+	if config.Audit.MinContrast <= 0 {
+		config.Audit.MinContrast = DefaultMinContrast
+	}
+	return config.Audit, nil
+}
+
+// SetConfig stores the audit settings
+func (am *AuditManager) SetConfig(audit initializer.AuditConfig) error {
+	if audit.MinContrast != 0 && (audit.MinContrast < 1 || audit.MinContrast > 21) {
+		return fmt.Errorf("contrast ratios range from 1 to 21")
+	}
+	config, err := initializer.GetRepositoryConfig(am.DgitDir)
+	if err != nil {
+		return err
+	}
+	config.Audit = audit
+	return initializer.UpdateRepositoryConfig(am.DgitDir, config)
+}
+
+// Matches reports whether a repository path is covered by the audit patterns
+func Matches(audit initializer.AuditConfig, path string) bool {
+	if len(audit.Patterns) == 0 {
+		return true
+	}
+	path = filepath.ToSlash(path)
+	for _, pattern := range audit.Patterns {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return true
+		}
+		if strings.HasPrefix(path, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditVersion checks the image previews of a version and writes the findings into its metadata
+// Previews of files outside the audit patterns are ignored; nil is returned when nothing was checked
+func (am *AuditManager) AuditVersion(version int, previews []*preview.Preview) (*log.AuditRecord, error) {
+	config, err := am.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &log.AuditRecord{CheckedAt: time.Now().UTC(), MinContrast: config.MinContrast}
+	for _, p := range previews {
+		if p == nil || p.Version != version || !p.IsImage() || !Matches(config, p.Source) {
+			continue
+		}
+		finding, err := CheckImage(p.Path, config.MinContrast)
+		if err != nil {
+			finding = &log.AuditFinding{Error: err.Error()}
+		}
+		finding.Source = filepath.ToSlash(p.Source)
+		record.Files = append(record.Files, finding)
+	}
+	if len(record.Files) == 0 {
+		return nil, nil
+	}
+	sort.Slice(record.Files, func(i, j int) bool { return record.Files[i].Source < record.Files[j].Source })
+	return record, am.saveRecord(version, record)
+}
+
+// saveRecord adds the audit to the stored commit JSON, leaving every other field as written
+func (am *AuditManager) saveRecord(version int, record *log.AuditRecord) error {
+	logManager := log.NewLogManager(am.DgitDir)
+	data, err := logManager.LoadCommitData(version)
+	if err != nil {
+		return fmt.Errorf("failed to load v%d: %w", version, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("failed to parse v%d: %w", version, err)
+	}
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit: %w", err)
+	}
+	fields["audit"] = encoded
+	data, err = json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal v%d: %w", version, err)
+	}
+	return logManager.SaveCommitData(data)
+}
+
+// CheckImage finds text-like regions (two dominant flat colors) and measures their contrast
+func CheckImage(path string, minContrast float64) (*log.AuditFinding, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open preview: %w", err)
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode preview %s: %w", filepath.Base(path), err)
+	}
+
+	bounds := img.Bounds()
+	stride := 1
+	if edge := max(bounds.Dx(), bounds.Dy()); edge > maxSampleEdge {
+		stride = (edge + maxSampleEdge - 1) / maxSampleEdge
+	}
+	step := tileSize * stride
+
+	finding := &log.AuditFinding{}
+	worst := math.Inf(1)
+	for y := bounds.Min.Y; y+step <= bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x+step <= bounds.Max.X; x += step {
+			fg, bg, ok := dominantPair(img, x, y, stride)
+			if !ok {
+				continue
+			}
+			finding.TextRegions++
+			ratio := ContrastRatio(fg, bg)
+			if ratio < minContrast {
+				finding.Failing++
+			}
+			if ratio < worst {
+				worst = ratio
+				finding.WorstRatio = math.Round(ratio*100) / 100
+				finding.Foreground, finding.Background = hexColor(fg), hexColor(bg)
+				finding.X, finding.Y = x-bounds.Min.X, y-bounds.Min.Y
+			}
+		}
+	}
+	return finding, nil
+}
+
+// bucket accumulates the pixels that quantize to one color
+type bucket struct {
+	count   int
+	r, g, b int
+}
+
+// average returns the mean color of the bucket
+func (b *bucket) average() color.RGBA {
+	return color.RGBA{R: uint8(b.r / b.count), G: uint8(b.g / b.count), B: uint8(b.b / b.count), A: 255}
+}
+
+// dominantPair returns the background and text colors of a region, or false when it is not text-like
+func dominantPair(img image.Image, x0, y0, stride int) (color.RGBA, color.RGBA, bool) {
+	buckets := make(map[uint16]*bucket)
+	total := 0
+	for y := 0; y < tileSize; y++ {
+		for x := 0; x < tileSize; x++ {
+			c := flatten(img.At(x0+x*stride, y0+y*stride))
+			key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+			b := buckets[key]
+			if b == nil {
+				b = &bucket{}
+				buckets[key] = b
+			}
+			b.count++
+			b.r += int(c.R)
+			b.g += int(c.G)
+			b.b += int(c.B)
+			total++
+		}
+	}
+
+	ranked := make([]*bucket, 0, len(buckets))
+	for _, b := range buckets {
+		ranked = append(ranked, b)
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].count > ranked[j].count })
+
+	bg := ranked[0].average()
+	for _, b := range ranked[1:] {
+		share := float64(b.count) / float64(total)
+		if share < minForeground {
+			break
+		}
+		fg := b.average()
+		if distance(fg, bg) < minDistance {
+			continue
+		}
+		if share > maxForeground || float64(ranked[0].count+b.count)/float64(total) < minCoverage {
+			return fg, bg, false
+		}
+		return fg, bg, true
+	}
+	return bg, bg, false
+}
+
+// flatten composites a pixel over white
+func flatten(c color.Color) color.RGBA {
+	r, g, b, a := c.RGBA()
+	white := 0xffff - a
+	return color.RGBA{
+		R: uint8((r + white) >> 8),
+		G: uint8((g + white) >> 8),
+		B: uint8((b + white) >> 8),
+		A: 255,
+	}
+}
+
+// distance is the Euclidean RGB distance between two colors
+func distance(a, b color.RGBA) float64 {
+	dr, dg, db := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+	return math.Sqrt(dr*dr + dg*dg + db*db)
+}
+
+// ContrastRatio is the WCAG 2 contrast ratio between two colors (1 to 21)
+func ContrastRatio(a, b color.RGBA) float64 {
+	la, lb := luminance(a), luminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// luminance is the WCAG relative luminance of an sRGB color
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
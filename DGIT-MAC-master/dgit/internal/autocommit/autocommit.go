@@ -0,0 +1,238 @@
+package autocommit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// dayFormat keys the daily auto-commit counter
+const dayFormat = "2006-01-02"
+
+// QuietHours is a daily local-time window in which nothing is auto-committed
+// A window whose end is before its start runs past midnight (22:00-07:00)
+type QuietHours struct {
+	Start time.Duration // Offset from midnight
+	End   time.Duration
+}
+
+// ParseQuietHours parses "HH:MM-HH:MM"; an empty string means no quiet hours
+func ParseQuietHours(text string) (*QuietHours, error) {
+	if text == "" {
+		return nil, nil
+	}
+	var startH, startM, endH, endM int
+	if n, _ := fmt.Sscanf(text, "%d:%d-%d:%d", &startH, &startM, &endH, &endM); n != 4 ||
+		startH < 0 || startH > 23 || endH < 0 || endH > 23 || startM < 0 || startM > 59 || endM < 0 || endM > 59 {
+		return nil, fmt.Errorf("invalid quiet hours %q (use HH:MM-HH:MM, e.g. 22:00-07:00)", text)
+	}
+	quiet := &QuietHours{
+		Start: time.Duration(startH)*time.Hour + time.Duration(startM)*time.Minute,
+		End:   time.Duration(endH)*time.Hour + time.Duration(endM)*time.Minute,
+	}
+	if quiet.Start == quiet.End {
+		return nil, fmt.Errorf("quiet hours %q are empty", text)
+	}
+	return quiet, nil
+}
+
+// Contains reports whether a moment falls inside the window
+func (q *QuietHours) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if q.Start < q.End {
+		return offset >= q.Start && offset < q.End
+	}
+	return offset >= q.Start || offset < q.End
+}
+
+// state is what the policy remembers between passes
+type state struct {
+	Day   string               `json:"day"`   // Local date the count applies to
+	Count int                  `json:"count"` // Auto-commits made on Day
+	Files map[string]time.Time `json:"files"` // Repository path → last auto-commit
+}
+
+// PolicyManager decides when automatic commits may happen
+// Counters and per-file timestamps are kept in .dgit/autocommit-state.json
+type PolicyManager struct {
+	DgitDir   string
+	StateFile string
+}
+
+// NewPolicyManager creates a new auto-commit policy manager for the given .dgit directory
+func NewPolicyManager(dgitDir string) *PolicyManager {
+	return &PolicyManager{
+		DgitDir:   dgitDir,
+		StateFile: filepath.Join(dgitDir, "autocommit-state.json"),
+	}
+}
+
+// GetConfig returns the configured policy
+func (pm *PolicyManager) GetConfig() (initializer.AutoCommitConfig, error) {
+	config, err := initializer.GetRepositoryConfig(pm.DgitDir)
+	if err != nil {
+		return initializer.AutoCommitConfig{}, err
+	}
+	return config.AutoCommit, nil
+}
+
+// SetConfig validates and stores the policy
+func (pm *PolicyManager) SetConfig(policy initializer.AutoCommitConfig) error {
+	if _, err := ParseQuietHours(policy.QuietHours); err != nil {
+		return err
+	}
+	if policy.MinIntervalSeconds < 0 || policy.MaxPerDay < 0 {
+		return fmt.Errorf("intervals and limits cannot be negative")
+	}
+	config, err := initializer.GetRepositoryConfig(pm.DgitDir)
+	if err != nil {
+		return err
+	}
+	config.AutoCommit = policy
+	return initializer.UpdateRepositoryConfig(pm.DgitDir, config)
+}
+
+// Pass applies the policy to one round of automatic commits
+type Pass struct {
+	manager *PolicyManager
+	policy  initializer.AutoCommitConfig
+	quiet   *QuietHours
+	state   *state
+	now     time.Time
+	dirty   bool
+}
+
+// Begin loads the policy and its state for a pass starting now
+func (pm *PolicyManager) Begin(now time.Time) (*Pass, error) {
+	policy, err := pm.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := ParseQuietHours(policy.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	st, err := pm.loadState()
+	if err != nil {
+		return nil, err
+	}
+	if day := now.Format(dayFormat); st.Day != day {
+		st.Day = day
+		st.Count = 0
+	}
+	return &Pass{manager: pm, policy: policy, quiet: quiet, state: st, now: now}, nil
+}
+
+// Blocked returns why no auto-commit may happen right now, or "" when commits are allowed
+func (p *Pass) Blocked() string {
+	if p.quiet != nil && p.quiet.Contains(p.now) {
+		return fmt.Sprintf("quiet hours (%s)", p.policy.QuietHours)
+	}
+	if p.policy.MaxPerDay > 0 && p.state.Count >= p.policy.MaxPerDay {
+		return fmt.Sprintf("daily limit of %d auto-commits reached", p.policy.MaxPerDay)
+	}
+	return ""
+}
+
+// FileBlocked returns why a file must wait, or "" when it may be committed
+// path is the repository path the interval is tracked under; source is the file an application may hold open
+func (p *Pass) FileBlocked(path, source string) string {
+	if p.policy.MinIntervalSeconds > 0 {
+		interval := time.Duration(p.policy.MinIntervalSeconds) * time.Second
+		if last, ok := p.state.Files[path]; ok && p.now.Sub(last) < interval {
+			return fmt.Sprintf("committed %s ago (minimum interval %s)", p.now.Sub(last).Round(time.Second), interval)
+		}
+	}
+	if p.policy.RequireClosed {
+		if open, err := IsOpen(source); err == nil && open {
+			return "still open in an application"
+		}
+	}
+	return ""
+}
+
+// Record counts one auto-commit of the given repository paths
+func (p *Pass) Record(paths []string) {
+	p.state.Count++
+	for _, path := range paths {
+		p.state.Files[path] = p.now
+	}
+	p.dirty = true
+}
+
+// Save writes the counters back when the pass committed anything
+func (p *Pass) Save() error {
+	if !p.dirty {
+		return nil
+	}
+	// Timestamps older than any interval are of no further use
+	for path, last := range p.state.Files {
+		if p.now.Sub(last) > time.Duration(p.policy.MinIntervalSeconds)*time.Second+24*time.Hour {
+			delete(p.state.Files, path)
+		}
+	}
+	data, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal auto-commit state: %w", err)
+	}
+	if err := os.WriteFile(p.manager.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write auto-commit state: %w", err)
+	}
+	return nil
+}
+
+// CountToday returns the auto-commits made so far today
+func (pm *PolicyManager) CountToday(now time.Time) (int, error) {
+	st, err := pm.loadState()
+	if err != nil {
+		return 0, err
+	}
+	if st.Day != now.Format(dayFormat) {
+		return 0, nil
+	}
+	return st.Count, nil
+}
+
+// loadState reads the policy state, starting empty when there is none
+func (pm *PolicyManager) loadState() (*state, error) {
+	st := &state{Files: make(map[string]time.Time)}
+	data, err := os.ReadFile(pm.StateFile)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auto-commit state: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to parse auto-commit state: %w", err)
+	}
+	if st.Files == nil {
+		st.Files = make(map[string]time.Time)
+	}
+	return st, nil
+}
+
+// IsOpen reports whether any process has the file open, using lsof
+// An error means it could not be determined (e.g. lsof is not installed)
+func IsOpen(path string) (bool, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("lsof", "-t", "--", path)
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	if err == nil {
+		return stdout.Len() > 0, nil
+	}
+	// lsof exits 1 with no output when nothing has the file open
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && stdout.Len() == 0 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check open files: %w", err)
+}
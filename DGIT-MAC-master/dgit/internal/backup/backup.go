@@ -0,0 +1,350 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// manifestName is written last, so a set without one is an interrupted backup and is ignored
+const manifestName = "manifest.json"
+
+// skipDirs are .dgit directories that never need backing up
+var skipDirs = map[string]bool{"temp": true}
+
+// stateFileName records this machine's backups and is not part of the repository
+const stateFileName = "backup-state.json"
+
+// Entry is one .dgit file as of a backup set
+type Entry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	SHA256  string    `json:"sha256"`
+	Set     string    `json:"set"` // Set whose files/ directory holds the content
+}
+
+// Manifest lists every file of the repository at the time of a set
+// Unchanged files point at the earlier set that copied them, so sets form layers
+type Manifest struct {
+	Set         string            `json:"set"`
+	Parent      string            `json:"parent,omitempty"`
+	Repository  string            `json:"repository"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Incremental bool              `json:"incremental"`
+	Copied      int               `json:"copied"`
+	CopiedBytes int64             `json:"copied_bytes"`
+	TotalBytes  int64             `json:"total_bytes"`
+	Files       map[string]*Entry `json:"files"` // Path relative to .dgit → entry
+}
+
+// Result reports one backup run
+type Result struct {
+	Manifest *Manifest
+	Dir      string // Backup directory for this repository
+	Reused   int    // Files unchanged since the parent set
+	Removed  int    // Files in the parent set that no longer exist
+	Elapsed  time.Duration
+}
+
+// State records the last backup to each destination
+type State struct {
+	Destinations map[string]*LastBackup `json:"destinations"`
+}
+
+// LastBackup is the most recent set written to one destination
+type LastBackup struct {
+	Set  string    `json:"set"`
+	Time time.Time `json:"time"`
+}
+
+// BackupManager copies the repository to backup sets on another volume
+// Each destination holds <repository>.dgit-backup/sets/<set>/{files/,manifest.json}
+type BackupManager struct {
+	DgitDir   string
+	StateFile string
+}
+
+// NewBackupManager creates a new backup manager for the given .dgit directory
+func NewBackupManager(dgitDir string) *BackupManager {
+	return &BackupManager{
+		DgitDir:   dgitDir,
+		StateFile: filepath.Join(dgitDir, stateFileName),
+	}
+}
+
+// BackupDir is the directory holding this repository's sets on a destination
+func (bm *BackupManager) BackupDir(destination string) string {
+	name := filepath.Base(filepath.Dir(bm.DgitDir))
+	return filepath.Join(destination, name+".dgit-backup")
+}
+
+// Backup writes a new set; incremental sets copy only files new or changed since the latest set
+// A full set is written when there is no earlier set to build on
+func (bm *BackupManager) Backup(destination string, incremental bool) (*Result, error) {
+	started := time.Now()
+	if info, err := os.Stat(destination); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("backup destination %s is not a directory", destination)
+	}
+	dir := bm.BackupDir(destination)
+
+	var parent *Manifest
+	if incremental {
+		sets, err := ListSets(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(sets) > 0 {
+			parent = sets[len(sets)-1]
+		}
+	}
+
+	set := time.Now().UTC().Format("20060102T150405.000Z")
+	setDir := filepath.Join(dir, "sets", set)
+	if err := os.MkdirAll(filepath.Join(setDir, "files"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup set: %w", err)
+	}
+	manifest := &Manifest{
+		Set:         set,
+		Repository:  filepath.Dir(bm.DgitDir),
+		CreatedAt:   time.Now(),
+		Incremental: parent != nil,
+		Files:       make(map[string]*Entry),
+	}
+	if parent != nil {
+		manifest.Parent = parent.Set
+	}
+	result := &Result{Manifest: manifest, Dir: dir}
+
+	err := filepath.WalkDir(bm.DgitDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(bm.DgitDir, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if skipDirs[rel] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || rel == stateFileName {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		manifest.TotalBytes += info.Size()
+
+		if parent != nil {
+			if previous, ok := parent.Files[rel]; ok && previous.Size == info.Size() && previous.ModTime.Equal(info.ModTime()) {
+				manifest.Files[rel] = previous
+				result.Reused++
+				return nil
+			}
+		}
+		sum, err := util.CopyFileSHA256(path, filepath.Join(setDir, "files", filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+		manifest.Files[rel] = &Entry{Size: info.Size(), ModTime: info.ModTime(), SHA256: sum, Set: set}
+		manifest.Copied++
+		manifest.CopiedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		os.RemoveAll(setDir)
+		return nil, err
+	}
+	if parent != nil {
+		for rel := range parent.Files {
+			if _, ok := manifest.Files[rel]; !ok {
+				result.Removed++
+			}
+		}
+	}
+
+	if err := writeManifest(setDir, manifest); err != nil {
+		os.RemoveAll(setDir)
+		return nil, err
+	}
+	result.Elapsed = time.Since(started)
+	return result, bm.recordBackup(destination, set)
+}
+
+// FindBackupDir resolves a repository backup directory, or a destination holding exactly one
+func FindBackupDir(path string) (string, error) {
+	if _, err := os.Stat(filepath.Join(path, "sets")); err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join(path, "*.dgit-backup"))
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no DGit backups in %s", path)
+	case 1:
+		return matches[0], nil
+	}
+	var names []string
+	for _, match := range matches {
+		names = append(names, filepath.Base(match))
+	}
+	return "", fmt.Errorf("%s holds several backups (%s); pass one of them", path, strings.Join(names, ", "))
+}
+
+// ListSets returns the complete sets in a backup directory, oldest first
+func ListSets(dir string) ([]*Manifest, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, "sets"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup sets: %w", err)
+	}
+	var sets []*Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := readManifest(filepath.Join(dir, "sets", entry.Name()))
+		if err != nil {
+			continue // Interrupted or foreign directory
+		}
+		sets = append(sets, manifest)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Set < sets[j].Set })
+	return sets, nil
+}
+
+// Restore rebuilds a .dgit directory from a set, taking each file from the layer that holds it
+// set may be a prefix of a set name; empty selects the latest set
+func Restore(dir, set, target string) (*Manifest, error) {
+	sets, err := ListSets(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("no backup sets in %s", dir)
+	}
+	manifest := sets[len(sets)-1]
+	if set != "" {
+		manifest = nil
+		for _, candidate := range sets {
+			if strings.HasPrefix(candidate.Set, set) {
+				if manifest != nil {
+					return nil, fmt.Errorf("backup set %q is ambiguous", set)
+				}
+				manifest = candidate
+			}
+		}
+		if manifest == nil {
+			return nil, fmt.Errorf("no backup set %q", set)
+		}
+	}
+
+	dgitDir := filepath.Join(target, ".dgit")
+	if _, err := os.Stat(dgitDir); err == nil {
+		return nil, fmt.Errorf("%s already contains a repository", target)
+	}
+	paths := make([]string, 0, len(manifest.Files))
+	for rel := range manifest.Files {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	for _, rel := range paths {
+		entry := manifest.Files[rel]
+		source := filepath.Join(dir, "sets", entry.Set, "files", filepath.FromSlash(rel))
+		destination := filepath.Join(dgitDir, filepath.FromSlash(rel))
+		sum, err := util.CopyFileSHA256(source, destination)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s from set %s: %w", rel, entry.Set, err)
+		}
+		if sum != entry.SHA256 {
+			return nil, fmt.Errorf("%s in set %s is corrupt (checksum mismatch)", rel, entry.Set)
+		}
+		os.Chtimes(destination, entry.ModTime, entry.ModTime)
+	}
+	// Directories the repository expects even when empty
+	os.MkdirAll(filepath.Join(dgitDir, "temp"), 0755)
+	return manifest, nil
+}
+
+// LastBackups returns the most recent set written to each destination
+func (bm *BackupManager) LastBackups() (map[string]*LastBackup, error) {
+	state, err := bm.loadState()
+	if err != nil {
+		return nil, err
+	}
+	return state.Destinations, nil
+}
+
+// recordBackup remembers the latest set written to a destination
+func (bm *BackupManager) recordBackup(destination, set string) error {
+	state, err := bm.loadState()
+	if err != nil {
+		return err
+	}
+	if abs, err := filepath.Abs(destination); err == nil {
+		destination = abs
+	}
+	state.Destinations[destination] = &LastBackup{Set: set, Time: time.Now()}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup state: %w", err)
+	}
+	if err := os.WriteFile(bm.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup state: %w", err)
+	}
+	return nil
+}
+
+// loadState reads the backup state, empty if no backup has run
+func (bm *BackupManager) loadState() (*State, error) {
+	state := &State{Destinations: make(map[string]*LastBackup)}
+	data, err := os.ReadFile(bm.StateFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse backup state: %w", err)
+	}
+	if state.Destinations == nil {
+		state.Destinations = make(map[string]*LastBackup)
+	}
+	return state, nil
+}
+
+// readManifest loads a set's manifest
+func readManifest(setDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(setDir, manifestName))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse backup manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// writeManifest completes a set
+func writeManifest(setDir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup manifest: %w", err)
+	}
+	tmp := filepath.Join(setDir, manifestName+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(setDir, manifestName))
+}
@@ -0,0 +1,296 @@
+package branch
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+)
+
+// Branch is a named line of design iterations
+type Branch struct {
+	Name    string
+	Hash    string
+	Version int
+	Message string
+	Current bool
+}
+
+// TreeFile is the committed state of one file as seen from a branch tip
+type TreeFile struct {
+	Version int
+	SHA256  string
+}
+
+// CheckoutResult describes what switching branches did to the working files
+type CheckoutResult struct {
+	Branch   string
+	Version  int
+	Restored []string // Files written from the target branch
+	Kept     []string // Files only on the previous branch, left in place
+}
+
+// BranchManager creates, lists and switches branches stored under .dgit/refs/heads
+type BranchManager struct {
+	DgitDir string
+}
+
+// NewBranchManager creates a new branch manager for the given .dgit directory
+func NewBranchManager(dgitDir string) *BranchManager {
+	return &BranchManager{DgitDir: dgitDir}
+}
+
+// List returns every branch sorted by name
+func (bm *BranchManager) List() ([]*Branch, error) {
+	logManager := log.NewLogManager(bm.DgitDir)
+	refs, err := logManager.ListRefs()
+	if err != nil {
+		return nil, err
+	}
+	current := logManager.CurrentBranch()
+	var branches []*Branch
+	for name, hash := range refs {
+		b := &Branch{Name: name, Hash: hash, Current: name == current}
+		if c, err := logManager.GetCommitByHash(hash); err == nil {
+			b.Version, b.Message = c.Version, c.Message
+		}
+		branches = append(branches, b)
+	}
+	// A new repository is on main before its first commit creates the ref
+	if _, ok := refs[current]; !ok {
+		branches = append(branches, &Branch{Name: current, Current: true})
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// Create starts a branch at a commit, HEAD when start is nil
+func (bm *BranchManager) Create(name string, start *log.Commit) (*Branch, error) {
+	if err := log.ValidateBranchName(name); err != nil {
+		return nil, err
+	}
+	logManager := log.NewLogManager(bm.DgitDir)
+	if _, err := logManager.ReadRef(name); err == nil {
+		return nil, fmt.Errorf("branch %q already exists", name)
+	}
+	if start == nil {
+		hash := logManager.HeadHash()
+		if hash == "" {
+			return nil, fmt.Errorf("no commits yet; commit before creating a branch")
+		}
+		c, err := logManager.GetCommitByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load HEAD commit: %w", err)
+		}
+		start = c
+	}
+	if err := logManager.WriteRef(name, start.Hash); err != nil {
+		return nil, err
+	}
+	return &Branch{Name: name, Hash: start.Hash, Version: start.Version, Message: start.Message}, nil
+}
+
+// Delete removes a branch; unless forced, its tip must be reachable from another branch
+func (bm *BranchManager) Delete(name string, force bool) error {
+	logManager := log.NewLogManager(bm.DgitDir)
+	if name == logManager.CurrentBranch() {
+		return fmt.Errorf("cannot delete %s while it is checked out", name)
+	}
+	refs, err := logManager.ListRefs()
+	if err != nil {
+		return err
+	}
+	hash, ok := refs[name]
+	if !ok {
+		return fmt.Errorf("branch %q does not exist", name)
+	}
+	if !force {
+		nodes, err := bm.graph()
+		if err != nil {
+			return err
+		}
+		reachable := false
+		for other, otherHash := range refs {
+			if other != name && ancestors(nodes, otherHash)[hash] {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			return fmt.Errorf("branch %s has commits on no other branch; use --force to delete it anyway", name)
+		}
+	}
+	return logManager.DeleteRef(name)
+}
+
+// Checkout switches HEAD to a branch and writes its files into the working directory
+// Files with uncommitted edits that the switch would overwrite stop the checkout unless forced
+func (bm *BranchManager) Checkout(name string, force bool) (*CheckoutResult, error) {
+	repoLock, err := repolock.Acquire(bm.DgitDir, "checkout", repolock.DefaultWait)
+	if err != nil {
+		return nil, err
+	}
+	defer repoLock.Release()
+
+	logManager := log.NewLogManager(bm.DgitDir)
+	current := logManager.CurrentBranch()
+	if name == current {
+		return nil, fmt.Errorf("already on %s", name)
+	}
+	targetHash, err := logManager.ReadRef(name)
+	if err != nil {
+		return nil, err
+	}
+	target, err := logManager.GetCommitByHash(targetHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip of %s: %w", name, err)
+	}
+
+	currentTree, err := bm.Tree(logManager.HeadHash())
+	if err != nil {
+		return nil, err
+	}
+	targetTree, err := bm.Tree(targetHash)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Dir(bm.DgitDir)
+	result := &CheckoutResult{Branch: name, Version: target.Version}
+	byVersion := make(map[int][]string)
+	var dirty []string
+	for path, file := range targetTree {
+		mine, ok := currentTree[path]
+		if ok && mine.SHA256 == file.SHA256 && mine.SHA256 != "" {
+			continue
+		}
+		working := filepath.Join(root, filepath.FromSlash(path))
+		if hash, err := status.CalculateFileHash(working); err == nil {
+			if hash == file.SHA256 {
+				continue // Already as committed on the target branch
+			}
+			if !ok || hash != mine.SHA256 {
+				dirty = append(dirty, path)
+			}
+		}
+		byVersion[file.Version] = append(byVersion[file.Version], path)
+		result.Restored = append(result.Restored, path)
+	}
+	if len(dirty) > 0 && !force {
+		sort.Strings(dirty)
+		return nil, fmt.Errorf("uncommitted changes to %s would be overwritten; commit them or use --force", dirty[0])
+	}
+	for path := range currentTree {
+		if _, ok := targetTree[path]; !ok {
+			result.Kept = append(result.Kept, path)
+		}
+	}
+
+	// Restore each file from the version that last committed it on the target branch
+	restoreManager := restore.NewRestoreManager(bm.DgitDir)
+	restoreManager.WorkDir = root
+	restoreManager.Lock = repoLock
+	versions := make([]int, 0, len(byVersion))
+	for version := range byVersion {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	for _, version := range versions {
+		c, err := logManager.GetCommit(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load v%d: %w", version, err)
+		}
+		if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", version), byVersion[version], c); err != nil {
+			return nil, fmt.Errorf("failed to restore files from v%d: %w", version, err)
+		}
+	}
+
+	if err := logManager.SetHeadBranch(name); err != nil {
+		return nil, err
+	}
+	sort.Strings(result.Restored)
+	sort.Strings(result.Kept)
+	return result, nil
+}
+
+// Tree returns the newest committed state of every file reachable from a commit
+// Commits only hold the files staged for them, so earlier commits fill in the rest
+func (bm *BranchManager) Tree(hash string) (map[string]*TreeFile, error) {
+	tree := make(map[string]*TreeFile)
+	if hash == "" {
+		return tree, nil
+	}
+	nodes, err := bm.graph()
+	if err != nil {
+		return nil, err
+	}
+	logManager := log.NewLogManager(bm.DgitDir)
+	visited := make(map[int]bool)
+	for version := nodes.byHash[hash]; version > 0 && !visited[version]; version = nodes.parent[version] {
+		visited[version] = true
+		c, err := logManager.GetCommit(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load v%d: %w", version, err)
+		}
+		for path, raw := range c.Metadata {
+			path = filepath.ToSlash(path)
+			if _, seen := tree[path]; seen {
+				continue
+			}
+			file := &TreeFile{Version: version}
+			if meta, ok := raw.(map[string]interface{}); ok {
+				file.SHA256, _ = meta["sha256"].(string)
+			}
+			tree[path] = file
+		}
+		// Moved files are no longer at their previous path on this branch
+		for newPath, oldPath := range c.Renames {
+			if _, seen := tree[oldPath]; !seen && oldPath != newPath {
+				tree[oldPath] = nil
+			}
+		}
+	}
+	for path, file := range tree {
+		if file == nil {
+			delete(tree, path)
+		}
+	}
+	return tree, nil
+}
+
+// versionGraph maps commits to their parents by version
+type versionGraph struct {
+	byHash map[string]int
+	parent map[int]int
+}
+
+// graph loads the parent links of every commit
+func (bm *BranchManager) graph() (*versionGraph, error) {
+	commits, err := log.NewLogManager(bm.DgitDir).GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit history: %w", err)
+	}
+	g := &versionGraph{byHash: make(map[string]int), parent: make(map[int]int)}
+	for _, node := range log.BuildGraph(commits, false) {
+		g.byHash[node.Hash] = node.Version
+		g.parent[node.Version] = node.Parent
+	}
+	return g, nil
+}
+
+// ancestors returns the hashes of a commit and everything before it
+func ancestors(g *versionGraph, hash string) map[string]bool {
+	byVersion := make(map[int]string, len(g.byHash))
+	for h, v := range g.byHash {
+		byVersion[v] = h
+	}
+	seen := make(map[string]bool)
+	for version := g.byHash[hash]; version > 0 && !seen[byVersion[version]]; version = g.parent[version] {
+		seen[byVersion[version]] = true
+	}
+	return seen
+}
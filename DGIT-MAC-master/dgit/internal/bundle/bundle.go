@@ -0,0 +1,473 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/redact"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repomerge"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// BundleFormat identifies the bundle layout
+const BundleFormat = "dgit-bundle/1"
+
+// ErrDiverged is returned by fast-forward-only unbundling when history has diverged
+var ErrDiverged = errors.New("history has diverged")
+
+// headerName is the bundle entry holding the Header
+const headerName = "bundle.json"
+
+// BundleCommit identifies one commit carried by a bundle
+type BundleCommit struct {
+	Version    int    `json:"version"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parent_hash,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Header describes a bundle's contents and what the receiving repository must already have
+type Header struct {
+	Format       string          `json:"format"`
+	Repository   string          `json:"repository"`
+	CreatedAt    time.Time       `json:"created_at"`
+	FromVersion  int             `json:"from_version"`
+	ToVersion    int             `json:"to_version"`
+	Prerequisite string          `json:"prerequisite,omitempty"` // Parent hash of the first bundled commit
+	Commits      []*BundleCommit `json:"commits"`
+
+	Redacted       []string `json:"redacted,omitempty"`        // Redaction rules applied to commit metadata
+	RedactedValues int      `json:"redacted_values,omitempty"` // Number of values stripped or hashed
+}
+
+// UnbundleResult summarizes what unbundling did
+type UnbundleResult struct {
+	Header      *Header
+	FastForward bool              // Commits were added to the mainline with their original versions
+	Added       []*BundleCommit   // Commits added (fast-forward)
+	Skipped     []*BundleCommit   // Commits already present
+	Merge       *repomerge.Result // Set when history diverged and commits went onto a branch
+}
+
+// BundleManager creates and applies single-file history bundles
+type BundleManager struct {
+	DgitDir string
+	TempDir string
+
+	// Redaction, when set, strips or hashes sensitive metadata in bundled commits
+	Redaction *redact.Policy
+
+	// FastForwardOnly rejects bundles that do not continue HEAD instead of importing them onto a branch
+	FastForwardOnly bool
+}
+
+// NewBundleManager creates a new bundle manager for the given .dgit directory
+func NewBundleManager(dgitDir string) *BundleManager {
+	return &BundleManager{
+		DgitDir: dgitDir,
+		TempDir: filepath.Join(dgitDir, "temp"),
+	}
+}
+
+// ParseRange parses a version range such as "v10..v20", "v10..", "..v20" or "v15"
+// Open ends default to the first and latest versions
+func ParseRange(spec string, latest int) (int, int, error) {
+	parseBound := func(s string, fallback int) (int, error) {
+		s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+		if s == "" {
+			return fallback, nil
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v <= 0 {
+			return 0, fmt.Errorf("invalid version %q", s)
+		}
+		return v, nil
+	}
+
+	if spec == "" {
+		return 1, latest, nil
+	}
+	fromSpec, toSpec, isRange := strings.Cut(spec, "..")
+	if !isRange {
+		toSpec = fromSpec
+	}
+	from, err := parseBound(fromSpec, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := parseBound(toSpec, latest)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from > to {
+		return 0, 0, fmt.Errorf("range start v%d is after end v%d", from, to)
+	}
+	return from, to, nil
+}
+
+// Create writes the commits in [from, to] and their snapshot data into a single bundle file
+func (bm *BundleManager) Create(outPath string, from, to int) (*Header, error) {
+	logManager := log.NewLogManager(bm.DgitDir)
+
+	var commits []*log.Commit
+	for v := from; v <= to; v++ {
+		commit, err := logManager.GetCommit(v)
+		if err != nil {
+			return nil, fmt.Errorf("v%d not found: %w", v, err)
+		}
+		commits = append(commits, commit)
+	}
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits in range v%d..v%d", from, to)
+	}
+
+	header := &Header{
+		Format:       BundleFormat,
+		Repository:   filepath.Base(filepath.Dir(bm.DgitDir)),
+		CreatedAt:    time.Now(),
+		FromVersion:  from,
+		ToVersion:    to,
+		Prerequisite: commits[0].ParentHash,
+	}
+	for _, c := range commits {
+		message := c.Message
+		if bm.Redaction != nil {
+			message, _ = bm.Redaction.Value("message", message)
+		}
+		header.Commits = append(header.Commits, &BundleCommit{
+			Version: c.Version, Hash: c.Hash, ParentHash: c.ParentHash, Message: message,
+		})
+	}
+	if bm.Redaction != nil {
+		header.Redacted = bm.Redaction.Describe()
+	}
+
+	// Redact commit data up front: the header records the count and is written first
+	commitData := make(map[int][]byte, len(commits))
+	summary := &redact.Summary{}
+	for _, c := range commits {
+		data, err := logManager.LoadCommitData(c.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit v%d: %w", c.Version, err)
+		}
+		if bm.Redaction != nil {
+			if data, err = bm.Redaction.CommitData(data, summary); err != nil {
+				return nil, fmt.Errorf("failed to redact v%d: %w", c.Version, err)
+			}
+		}
+		commitData[c.Version] = data
+	}
+	header.RedactedValues = summary.Total()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer out.Close()
+	zipWriter := zip.NewWriter(out)
+
+	headerData, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle header: %w", err)
+	}
+	if err := writeZipEntry(zipWriter, headerName, strings.NewReader(string(headerData))); err != nil {
+		return nil, err
+	}
+
+	written := make(map[string]bool)
+	for _, c := range commits {
+		files := repomerge.StorageFiles(bm.DgitDir, c.Version)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("v%d has no snapshot data", c.Version)
+		}
+
+		// Metadata is always bundled as objects/vN.json, whatever backend this repository uses
+		if err := writeZipEntry(zipWriter, commitEntryName(c.Version), bytes.NewReader(commitData[c.Version])); err != nil {
+			return nil, err
+		}
+		for _, relPath := range files {
+			// Versions share object store blobs; each is bundled once
+			if written[relPath] {
+				continue
+			}
+			written[relPath] = true
+			if err := addFile(zipWriter, filepath.Join(bm.DgitDir, relPath), filepath.ToSlash(relPath)); err != nil {
+				return nil, fmt.Errorf("failed to add %s: %w", relPath, err)
+			}
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return header, nil
+}
+
+// ReadHeader reads and validates a bundle header
+func ReadHeader(bundlePath string) (*Header, error) {
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer reader.Close()
+	return readHeader(&reader.Reader)
+}
+
+// Verify checks that a bundle can be applied to this repository
+// Fails if the repository lacks the commit the bundle's history builds on
+func (bm *BundleManager) Verify(bundlePath string) (*Header, error) {
+	header, err := ReadHeader(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	if header.Prerequisite != "" {
+		if _, err := log.NewLogManager(bm.DgitDir).GetCommitByHash(header.Prerequisite); err != nil {
+			return header, fmt.Errorf("repository is missing prerequisite commit %s (v%d's parent)",
+				util.ShortHash(header.Prerequisite), header.FromVersion)
+		}
+	}
+	return header, nil
+}
+
+// Unbundle applies a bundle to the repository
+// If the bundle continues the current HEAD its commits are added with their original versions;
+// otherwise they are imported onto a branch the same way as 'dgit merge-repo'
+func (bm *BundleManager) Unbundle(bundlePath, branch string) (*UnbundleResult, error) {
+	header, err := bm.Verify(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	scratchRoot, err := bm.extract(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratchRoot)
+	scratchDgit := filepath.Join(scratchRoot, ".dgit")
+
+	// Versions are checked against HEAD and then written, so hold the repository lock throughout
+	repoLock, err := repolock.Acquire(bm.DgitDir, "unbundle", repolock.DefaultWait)
+	if err != nil {
+		return nil, err
+	}
+	defer repoLock.Release()
+
+	result := &UnbundleResult{Header: header}
+	logManager := log.NewLogManager(bm.DgitDir)
+
+	// Drop commits the repository already has
+	var pending []*BundleCommit
+	for _, bc := range header.Commits {
+		if existing, err := logManager.GetCommitByHash(bc.Hash); err == nil && existing.Hash == bc.Hash {
+			result.Skipped = append(result.Skipped, bc)
+			continue
+		}
+		pending = append(pending, bc)
+	}
+	if len(pending) == 0 {
+		result.FastForward = true
+		return result, nil
+	}
+
+	if bm.canFastForward(pending) {
+		for _, bc := range pending {
+			if err := copyVersion(scratchDgit, bm.DgitDir, bc.Version); err != nil {
+				return nil, fmt.Errorf("failed to add v%d: %w", bc.Version, err)
+			}
+			result.Added = append(result.Added, bc)
+		}
+		last := pending[len(pending)-1]
+		if err := log.NewLogManager(bm.DgitDir).UpdateHead(last.Hash); err != nil {
+			return nil, fmt.Errorf("failed to update HEAD: %w", err)
+		}
+		result.FastForward = true
+		return result, nil
+	}
+
+	if bm.FastForwardOnly {
+		return nil, fmt.Errorf("%w: v%d does not continue HEAD (v%d)", ErrDiverged,
+			pending[0].Version, logManager.GetHeadVersion())
+	}
+
+	// History diverged - import onto a branch instead of rewriting local versions
+	mergeManager := repomerge.NewRepoMergeManager(bm.DgitDir)
+	mergeManager.Lock = repoLock
+	if absBundle, err := filepath.Abs(bundlePath); err == nil {
+		mergeManager.SourceLabel = absBundle
+	}
+	merge, err := mergeManager.MergeRepo(scratchRoot, branch, false)
+	if err != nil {
+		return nil, err
+	}
+	result.Merge = merge
+	return result, nil
+}
+
+// canFastForward reports whether pending commits continue HEAD without version collisions
+func (bm *BundleManager) canFastForward(pending []*BundleCommit) bool {
+	logManager := log.NewLogManager(bm.DgitDir)
+	headVersion := logManager.GetHeadVersion()
+	if headVersion != logManager.GetCurrentVersion() {
+		return false // Other branches already occupy versions past HEAD
+	}
+	if logManager.CurrentBranch() != log.MainBranch {
+		return false // Bundled commits belong to main
+	}
+
+	return pending[0].ParentHash == logManager.HeadHash() && pending[0].Version == headVersion+1
+}
+
+// extract unpacks a bundle into a scratch repository layout under .dgit/temp
+func (bm *BundleManager) extract(bundlePath string) (string, error) {
+	reader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(bm.TempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	scratchRoot, err := os.MkdirTemp(bm.TempDir, "unbundle-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	scratchDgit := filepath.Join(scratchRoot, ".dgit")
+
+	for _, f := range reader.File {
+		if f.Name == headerName {
+			continue
+		}
+		clean := path.Clean(f.Name)
+		if strings.HasPrefix(clean, "..") || path.IsAbs(clean) {
+			os.RemoveAll(scratchRoot)
+			return "", fmt.Errorf("bundle contains invalid path %s", f.Name)
+		}
+		target := filepath.Join(scratchDgit, filepath.FromSlash(clean))
+		if err := extractFile(f, target); err != nil {
+			os.RemoveAll(scratchRoot)
+			return "", fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return scratchRoot, nil
+}
+
+// copyVersion copies a version's metadata and snapshot data between .dgit directories unchanged
+func copyVersion(sourceDgit, targetDgit string, version int) error {
+	targetLog := log.NewLogManager(targetDgit)
+	if _, err := targetLog.LoadCommitData(version); err == nil {
+		return fmt.Errorf("%s already exists", commitEntryName(version))
+	}
+	data, err := log.NewLogManager(sourceDgit).LoadCommitData(version)
+	if err != nil {
+		return err
+	}
+
+	files := repomerge.StorageFiles(sourceDgit, version)
+	for _, relPath := range files {
+		target := filepath.Join(targetDgit, relPath)
+		if _, err := os.Stat(target); err == nil && !objstore.IsBlobPath(relPath) {
+			return fmt.Errorf("%s already exists", relPath)
+		}
+	}
+	for _, relPath := range files {
+		target := filepath.Join(targetDgit, relPath)
+		if _, err := os.Stat(target); err == nil {
+			continue // A blob with this name already holds the same content
+		}
+		if err := util.CopyFile(filepath.Join(sourceDgit, relPath), target); err != nil {
+			return err
+		}
+	}
+	// Metadata last, so an interrupted copy never leaves a commit without its data
+	return targetLog.SaveCommitData(data)
+}
+
+// commitEntryName is the bundle entry holding a commit's metadata
+func commitEntryName(version int) string {
+	return fmt.Sprintf("objects/v%d.json", version)
+}
+
+// readHeader reads the header entry of an open bundle
+func readHeader(reader *zip.Reader) (*Header, error) {
+	for _, f := range reader.File {
+		if f.Name != headerName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle header: %w", err)
+		}
+		defer rc.Close()
+
+		var header Header
+		if err := json.NewDecoder(rc).Decode(&header); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle header: %w", err)
+		}
+		if header.Format != BundleFormat {
+			return nil, fmt.Errorf("unsupported bundle format %q", header.Format)
+		}
+		sort.Slice(header.Commits, func(i, j int) bool { return header.Commits[i].Version < header.Commits[j].Version })
+		return &header, nil
+	}
+	return nil, fmt.Errorf("not a dgit bundle (missing %s)", headerName)
+}
+
+// writeZipEntry writes a stored entry to the bundle
+func writeZipEntry(zipWriter *zip.Writer, name string, content io.Reader) error {
+	// Snapshot data is already compressed, so entries are stored rather than deflated
+	w, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		return fmt.Errorf("failed to write %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// addFile copies a file into the bundle
+func addFile(zipWriter *zip.Writer, srcPath, name string) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeZipEntry(zipWriter, name, in)
+}
+
+// extractFile writes a bundle entry to disk
+func extractFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,381 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Cache tiers, fastest first
+const (
+	Hot  = "hot"  // LZ4 and store snapshots written at commit time
+	Warm = "warm" // Zstd copies made by background optimization
+	Cold = "cold" // Archived versions at the highest Zstd level
+)
+
+// Tiers lists the cache tiers in display order
+var Tiers = []string{Hot, Warm, Cold}
+
+// versionObject matches the per-version snapshot objects of every tier
+var versionObject = regexp.MustCompile(`^v(\d+)\.(lz4|store|zstd|archive\.zstd)$`)
+
+// TierStatus is the disk usage of one cache tier
+type TierStatus struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir"`
+	Size      int64  `json:"size"`      // Everything under the tier directory
+	Snapshots int64  `json:"snapshots"` // Version snapshots, which the limit applies to
+	Limit     int64  `json:"limit"`     // Configured maximum in bytes; 0 when unset
+	Versions  []int  `json:"versions"`  // Versions with a snapshot object in the tier
+	Only      []int  `json:"only"`      // Versions whose only snapshot copy is in this tier
+}
+
+// ClearResult reports what clearing a tier removed
+type ClearResult struct {
+	Tier    string
+	Removed []int // Versions whose copy in the tier was removed
+	Kept    []int // Versions kept because the tier holds their only readable copy
+	Freed   int64
+}
+
+// WarmResult reports how a version's hot copy was made available
+type WarmResult struct {
+	Version int
+	Path    string // The hot cache object
+	Source  string // Tier it was rebuilt from; empty when it was already hot
+	Size    int64
+}
+
+// CacheManager inspects and maintains the hot, warm and cold tiers under .dgit/cache
+// Snapshot objects are only removed when another tier still holds the version
+type CacheManager struct {
+	DgitDir      string
+	HotCacheDir  string
+	WarmCacheDir string
+	ColdCacheDir string
+}
+
+// NewCacheManager creates a new cache manager for the given .dgit directory
+func NewCacheManager(dgitDir string) *CacheManager {
+	return &CacheManager{
+		DgitDir:      dgitDir,
+		HotCacheDir:  filepath.Join(dgitDir, "cache", Hot),
+		WarmCacheDir: filepath.Join(dgitDir, "cache", Warm),
+		ColdCacheDir: filepath.Join(dgitDir, "cache", Cold),
+	}
+}
+
+// Status measures each tier against the cache sizes configured in compression.cache
+func (cm *CacheManager) Status() ([]*TierStatus, error) {
+	limits := map[string]int64{}
+	if config, err := initializer.GetRepositoryConfig(cm.DgitDir); err == nil {
+		cache := config.Compression.CacheConfig
+		limits[Hot] = cache.HotCacheSize * 1024 * 1024
+		limits[Warm] = cache.WarmCacheSize * 1024 * 1024
+		limits[Cold] = cache.ColdStorageSize * 1024 * 1024
+	}
+
+	copies, err := cm.copies()
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*TierStatus
+	for _, tier := range Tiers {
+		status := &TierStatus{Name: tier, Dir: cm.tierDir(tier), Size: cm.tierSize(tier), Snapshots: snapshotSize(copies, tier),
+			Limit: limits[tier], Versions: []int{}, Only: []int{}}
+		for version, tiers := range copies {
+			if _, ok := tiers[tier]; ok {
+				status.Versions = append(status.Versions, version)
+				if len(tiers) == 1 {
+					status.Only = append(status.Only, version)
+				}
+			}
+		}
+		sort.Ints(status.Versions)
+		sort.Ints(status.Only)
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Clear removes a tier's snapshot objects that another tier also holds; the other copy is decoded
+// and its checksums verified first, so an interrupted background copy never becomes the only one
+// Clearing the hot tier also drops the scan metadata cache; the cold tier holds archives and is never cleared
+func (cm *CacheManager) Clear(tier string) (*ClearResult, error) {
+	switch tier {
+	case Hot, Warm:
+	case Cold:
+		return nil, fmt.Errorf("the cold tier holds archived versions; use 'dgit archive' to manage them")
+	default:
+		return nil, fmt.Errorf("unknown cache tier %q (use %s or %s)", tier, Hot, Warm)
+	}
+
+	copies, err := cm.copies()
+	if err != nil {
+		return nil, err
+	}
+	result := &ClearResult{Tier: tier}
+	versions := make([]int, 0, len(copies))
+	for version := range copies {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	for _, version := range versions {
+		path, ok := copies[version][tier]
+		if !ok {
+			continue
+		}
+		if !cm.hasOtherCopy(copies[version], tier) {
+			result.Kept = append(result.Kept, version)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, fmt.Errorf("failed to remove %s: %w", filepath.Base(path), err)
+		}
+		result.Removed = append(result.Removed, version)
+		result.Freed += info.Size()
+	}
+
+	if tier == Hot {
+		// Scan results are keyed by file hash and recomputed on the next scan
+		metadataDir := filepath.Join(cm.HotCacheDir, "metadata")
+		entries, _ := os.ReadDir(metadataDir)
+		for _, entry := range entries {
+			path := filepath.Join(metadataDir, entry.Name())
+			if info, err := entry.Info(); err == nil && !entry.IsDir() {
+				if os.Remove(path) == nil {
+					result.Freed += info.Size()
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Warm makes sure a version can be restored from the hot tier, rebuilding its hot object from the
+// warm or cold copy; for a delta version the snapshot its chain starts from is warmed instead
+func (cm *CacheManager) Warm(version int) (*WarmResult, error) {
+	logManager := log.NewLogManager(cm.DgitDir)
+	for seen := map[int]bool{}; !seen[version]; {
+		seen[version] = true
+		commit, err := logManager.GetCommit(version)
+		if err != nil {
+			return nil, fmt.Errorf("v%d not found: %w", version, err)
+		}
+		info := commit.CompressionInfo
+		switch {
+		case info == nil:
+			return nil, fmt.Errorf("v%d is a legacy snapshot and is not cached", version)
+		case info.Strategy == codec.LZ4 || info.Strategy == codec.Store:
+			return cm.warmSnapshot(commit)
+		case info.Strategy == "content":
+			return nil, fmt.Errorf("v%d is in the object store, which is read directly and needs no warming", version)
+		case info.BaseVersion > 0:
+			// Delta objects always stay on disk; the chain's snapshot is what may need rebuilding
+			version = info.BaseVersion
+		default:
+			return nil, fmt.Errorf("v%d is stored as %s, which has no hot copy", version, info.Strategy)
+		}
+	}
+	return nil, fmt.Errorf("delta chain of v%d loops back on itself", version)
+}
+
+// Rebuild warms the most recent cached versions, newest first; object-store commits are skipped
+// Versions that cannot be warmed (legacy, archived offline, no other copy) are returned with their error
+func (cm *CacheManager) Rebuild(count int) ([]*WarmResult, map[int]error, error) {
+	it, err := log.NewLogManager(cm.DgitDir).Iterate(log.CommitFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	var results []*WarmResult
+	failed := make(map[int]error)
+	warmed := make(map[int]bool)
+	for count > 0 && it.Next() {
+		commit := it.Commit()
+		if commit.CompressionInfo != nil && commit.CompressionInfo.Strategy == "content" {
+			continue
+		}
+		count--
+		result, err := cm.Warm(commit.Version)
+		if err != nil {
+			failed[commit.Version] = err
+			continue
+		}
+		// Deltas sharing a base warm the same snapshot
+		if !warmed[result.Version] {
+			warmed[result.Version] = true
+			results = append(results, result)
+		}
+	}
+	return results, failed, it.Err()
+}
+
+// warmSnapshot rebuilds the hot object of an LZ4 or store snapshot from its warm or cold copy
+func (cm *CacheManager) warmSnapshot(commit *log.Commit) (*WarmResult, error) {
+	hotPath := filepath.Join(cm.HotCacheDir, commit.CompressionInfo.OutputFile)
+	result := &WarmResult{Version: commit.Version, Path: hotPath}
+	if info, err := os.Stat(hotPath); err == nil {
+		result.Size = info.Size()
+		return result, nil
+	}
+
+	// Warm copies and cold blobs both hold the decoded snapshot stream, Zstd-compressed
+	source, tier := filepath.Join(cm.WarmCacheDir, fmt.Sprintf("v%d.zstd", commit.Version)), Warm
+	if _, err := os.Stat(source); err != nil {
+		archives := archive.NewArchiveManager(cm.DgitDir)
+		source, tier = archives.BlobPath(commit.Version), Cold
+		if _, err := os.Stat(source); err != nil {
+			if record, ok := archives.Get(commit.Version); ok && record.Offline {
+				return nil, fmt.Errorf("v%d is archived offline; run 'dgit archive recall v%d' first", commit.Version, commit.Version)
+			}
+			return nil, fmt.Errorf("no warm or cold copy of v%d to rebuild from", commit.Version)
+		}
+	}
+	encoder, ok := codec.ForObject(hotPath)
+	if !ok {
+		return nil, fmt.Errorf("unknown object format %s", filepath.Base(hotPath))
+	}
+	size, err := recode(source, hotPath, encoder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild v%d from the %s tier: %w", commit.Version, tier, err)
+	}
+	result.Source, result.Size = tier, size
+	return result, nil
+}
+
+// recode decodes an object and writes it to output with another codec, atomically
+func recode(source, output string, encoder codec.Codec) (int64, error) {
+	decoder, ok := codec.ForObject(source)
+	if !ok {
+		return 0, fmt.Errorf("unknown object format %s", filepath.Base(source))
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	decoded, err := decoder.NewReader(in)
+	if err != nil {
+		return 0, err
+	}
+	defer decoded.Close()
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return 0, err
+	}
+	tmp := output + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	writer, err := encoder.NewWriter(out)
+	if err == nil {
+		_, err = io.Copy(writer, decoded)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, output)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	info, err := os.Stat(output)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// hasOtherCopy reports whether a tier other than this one holds a complete copy of a version
+func (cm *CacheManager) hasOtherCopy(tiers map[string]string, tier string) bool {
+	for other, path := range tiers {
+		if other != tier && verifyObject(path) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyObject decodes a snapshot object to the end, checking frame checksums when it is framed
+func verifyObject(path string) error {
+	c, ok := codec.ForObject(path)
+	if !ok {
+		return fmt.Errorf("unknown object format %s", filepath.Base(path))
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	decoded, err := c.NewReader(in)
+	if err != nil {
+		return err
+	}
+	defer decoded.Close()
+
+	buffered := bufio.NewReader(decoded)
+	if head, _ := buffered.Peek(len(codec.FramedMagic)); codec.IsFramed(head) {
+		return codec.EachFrame(buffered, func(*codec.FrameHeader, io.Reader) error { return nil })
+	}
+	_, err = io.Copy(io.Discard, buffered)
+	return err
+}
+
+// copies finds every version's snapshot objects, keyed by version and then tier
+func (cm *CacheManager) copies() (map[int]map[string]string, error) {
+	copies := make(map[int]map[string]string)
+	for _, tier := range Tiers {
+		entries, err := os.ReadDir(cm.tierDir(tier))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read the %s tier: %w", tier, err)
+		}
+		for _, entry := range entries {
+			match := versionObject.FindStringSubmatch(entry.Name())
+			if entry.IsDir() || match == nil {
+				continue
+			}
+			version, _ := strconv.Atoi(match[1])
+			if copies[version] == nil {
+				copies[version] = make(map[string]string)
+			}
+			copies[version][tier] = filepath.Join(cm.tierDir(tier), entry.Name())
+		}
+	}
+	return copies, nil
+}
+
+// tierDir is the directory of a cache tier
+func (cm *CacheManager) tierDir(tier string) string {
+	switch tier {
+	case Warm:
+		return cm.WarmCacheDir
+	case Cold:
+		return cm.ColdCacheDir
+	}
+	return cm.HotCacheDir
+}
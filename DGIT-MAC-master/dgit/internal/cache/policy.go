@@ -0,0 +1,311 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Eviction policies of compression.cache.eviction_policy
+const (
+	LRU  = "LRU"  // Least recently restored first
+	LFU  = "LFU"  // Least often restored first, then least recently
+	FIFO = "FIFO" // Longest in the tier first
+)
+
+// AccessFile records restore counts and times per version, relative to .dgit
+const AccessFile = "cache/access.json"
+
+// Access is how often and how recently a version was restored from the cache
+type Access struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// Policy is the cache budget and eviction settings from compression.cache
+type Policy struct {
+	Eviction        string // LRU, LFU or FIFO
+	HotLimit        int64  // Bytes; 0 is unlimited
+	WarmLimit       int64
+	AccessThreshold int // Restores that promote a version to hot; 0 never promotes
+}
+
+// EvictionResult reports what enforcing one tier's budget did
+type EvictionResult struct {
+	Tier    string
+	Policy  string
+	Size    int64 // Snapshot bytes in the tier before and after
+	After   int64
+	Limit   int64
+	Evicted []int // Copies removed because another tier holds the version
+	Demoted []int // Hot copies moved to the warm tier because they were the only copy
+}
+
+// Over reports whether the tier is still above its budget
+func (er *EvictionResult) Over() bool {
+	return er.Limit > 0 && er.After > er.Limit
+}
+
+// LoadPolicy reads the cache settings, defaulting to LRU
+func (cm *CacheManager) LoadPolicy() Policy {
+	policy := Policy{Eviction: LRU}
+	config, err := initializer.GetRepositoryConfig(cm.DgitDir)
+	if err != nil {
+		return policy
+	}
+	settings := config.Compression.CacheConfig
+	switch strings.ToUpper(settings.EvictionPolicy) {
+	case LFU:
+		policy.Eviction = LFU
+	case FIFO:
+		policy.Eviction = FIFO
+	}
+	policy.HotLimit = settings.HotCacheSize * 1024 * 1024
+	policy.WarmLimit = settings.WarmCacheSize * 1024 * 1024
+	policy.AccessThreshold = settings.AccessThreshold
+	return policy
+}
+
+// RecordAccess counts a restore of a version read from the cache
+// Once a version has been restored AccessThreshold times it is promoted to hot, and tier budgets are enforced
+func (cm *CacheManager) RecordAccess(version int) (promoted bool, err error) {
+	accesses, err := cm.LoadAccesses()
+	if err != nil {
+		return false, err
+	}
+	access := accesses[version]
+	access.Count++
+	access.Last = time.Now()
+	accesses[version] = access
+	if err := cm.saveAccesses(accesses); err != nil {
+		return false, err
+	}
+
+	policy := cm.LoadPolicy()
+	if policy.AccessThreshold <= 0 || access.Count < policy.AccessThreshold {
+		return false, nil
+	}
+	result, err := cm.Warm(version)
+	if err != nil {
+		// Object store and delta-only versions have nothing to promote
+		return false, nil
+	}
+	if result.Source == "" {
+		return false, nil
+	}
+	_, err = cm.Enforce()
+	return true, err
+}
+
+// Enforce brings the hot and warm tiers back under their budgets using the eviction policy
+// Budgets cover version snapshots; staged files and scan results in a tier are transient and not counted
+// Hot copies that are a version's only copy are demoted to warm instead of removed, the newest version
+// stays hot, and warm copies are only removed when another tier holds the version
+func (cm *CacheManager) Enforce() ([]*EvictionResult, error) {
+	policy := cm.LoadPolicy()
+	accesses, err := cm.LoadAccesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*EvictionResult
+	for _, tier := range []string{Hot, Warm} {
+		limit := policy.HotLimit
+		if tier == Warm {
+			limit = policy.WarmLimit
+		}
+		copies, err := cm.copies()
+		if err != nil {
+			return results, err
+		}
+		result := &EvictionResult{Tier: tier, Policy: policy.Eviction, Limit: limit, Size: snapshotSize(copies, tier)}
+		result.After = result.Size
+		results = append(results, result)
+		if limit <= 0 || result.Size <= limit {
+			continue
+		}
+
+		candidates := cm.evictionOrder(tier, copies, accesses, policy.Eviction)
+		for _, version := range candidates {
+			if result.After <= limit {
+				break
+			}
+			path := copies[version][tier]
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			switch {
+			case cm.hasOtherCopy(copies[version], tier):
+				result.Evicted = append(result.Evicted, version)
+			case tier == Hot:
+				if err := cm.demote(version, path); err != nil {
+					return results, err
+				}
+				result.Demoted = append(result.Demoted, version)
+			default:
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				return results, fmt.Errorf("failed to evict v%d from the %s tier: %w", version, tier, err)
+			}
+			result.After -= info.Size()
+		}
+	}
+	return results, nil
+}
+
+// evictionOrder lists the versions in a tier in the order the policy evicts them
+// The newest version is never evicted from the hot tier
+func (cm *CacheManager) evictionOrder(tier string, copies map[int]map[string]string, accesses map[int]Access, policy string) []int {
+	type candidate struct {
+		version int
+		count   int
+		used    time.Time // Last restore, or when the object was written if never restored
+		added   time.Time
+	}
+	newest := 0
+	var candidates []candidate
+	for version, tiers := range copies {
+		if version > newest {
+			newest = version
+		}
+		path, ok := tiers[tier]
+		if !ok {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		c := candidate{version: version, added: info.ModTime(), used: info.ModTime()}
+		if access, ok := accesses[version]; ok {
+			c.count = access.Count
+			if access.Last.After(c.used) {
+				c.used = access.Last
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch policy {
+		case LFU:
+			if a.count != b.count {
+				return a.count < b.count
+			}
+		case FIFO:
+			if !a.added.Equal(b.added) {
+				return a.added.Before(b.added)
+			}
+			return a.version < b.version
+		}
+		if !a.used.Equal(b.used) {
+			return a.used.Before(b.used)
+		}
+		return a.version < b.version
+	})
+
+	order := make([]int, 0, len(candidates))
+	for _, c := range candidates {
+		if tier == Hot && c.version == newest {
+			continue
+		}
+		order = append(order, c.version)
+	}
+	return order
+}
+
+// demote writes a Zstd warm copy of a hot object and checks it before the hot copy is removed
+func (cm *CacheManager) demote(version int, hotPath string) error {
+	warmPath := filepath.Join(cm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version))
+	encoder, err := codec.Get(codec.Zstd)
+	if err != nil {
+		return err
+	}
+	if _, err := recode(hotPath, warmPath, encoder); err != nil {
+		return fmt.Errorf("failed to demote v%d to the warm tier: %w", version, err)
+	}
+	if err := verifyObject(warmPath); err != nil {
+		os.Remove(warmPath)
+		return fmt.Errorf("warm copy of v%d does not verify: %w", version, err)
+	}
+	return nil
+}
+
+// snapshotSize is the total size of the version snapshots in a tier
+func snapshotSize(copies map[int]map[string]string, tier string) int64 {
+	var size int64
+	for _, tiers := range copies {
+		if path, ok := tiers[tier]; ok {
+			if info, err := os.Stat(path); err == nil {
+				size += info.Size()
+			}
+		}
+	}
+	return size
+}
+
+// tierSize is the total size of everything under a tier directory
+func (cm *CacheManager) tierSize(tier string) int64 {
+	var size int64
+	filepath.Walk(cm.tierDir(tier), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// LoadAccesses reads the recorded restores, keyed by version
+func (cm *CacheManager) LoadAccesses() (map[int]Access, error) {
+	accesses := make(map[int]Access)
+	data, err := os.ReadFile(filepath.Join(cm.DgitDir, AccessFile))
+	if os.IsNotExist(err) {
+		return accesses, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache access log: %w", err)
+	}
+	var stored map[string]Access
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to parse cache access log: %w", err)
+	}
+	for key, access := range stored {
+		if version, err := strconv.Atoi(strings.TrimPrefix(key, "v")); err == nil {
+			accesses[version] = access
+		}
+	}
+	return accesses, nil
+}
+
+// saveAccesses writes the access log atomically
+func (cm *CacheManager) saveAccesses(accesses map[int]Access) error {
+	stored := make(map[string]Access, len(accesses))
+	for version, access := range accesses {
+		stored[fmt.Sprintf("v%d", version)] = access
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cm.DgitDir, AccessFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache access log: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
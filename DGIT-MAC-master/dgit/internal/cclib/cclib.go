@@ -0,0 +1,495 @@
+package cclib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// refPattern matches a library element link as Adobe apps embed it in documents:
+// cloud-asset://cc-api-storage.adobe.io/assets/adobe-libraries/<library>;node=<element>
+// The separator is URL-encoded in some XMP packets
+var refPattern = regexp.MustCompile(`adobe-libraries/([0-9A-Fa-f-]{36})(?:;|%3[Bb])node=([0-9A-Fa-f-]{36})`)
+
+// scanChunk and scanOverlap bound the memory used to search large documents for links
+const (
+	scanChunk   = 4 << 20
+	scanOverlap = 256
+)
+
+// Ref identifies a library element linked from a document
+type Ref struct {
+	LibraryID string
+	ElementID string
+}
+
+// Component is one stored file of a library element (rendition, source graphic, ...)
+type Component struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"` // SHA-256 of the content in .dgit/cclib/objects
+	Size int64  `json:"size"`
+}
+
+// Asset is a library element pinned by a commit
+type Asset struct {
+	LibraryID    string       `json:"library_id"`
+	LibraryName  string       `json:"library_name,omitempty"`
+	ElementID    string       `json:"element_id"`
+	Name         string       `json:"name,omitempty"`
+	Modified     string       `json:"modified,omitempty"` // Element modification stamp from the library manifest
+	Components   []*Component `json:"components,omitempty"`
+	ReferencedBy []string     `json:"referenced_by"`
+	Error        string       `json:"error,omitempty"` // Why the element could not be snapshotted
+}
+
+// CCLibraryManager copies Creative Cloud Library elements linked from committed files into the repository
+// Element files are stored once by content hash in .dgit/cclib/objects
+type CCLibraryManager struct {
+	DgitDir      string
+	ObjectsDir   string
+	RestoredDir  string
+	LibrariesDir string
+
+	enabled   bool
+	libraries map[string]string // Library ID → local library folder, filled on first lookup
+}
+
+// NewCCLibraryManager creates a new CC Libraries manager for the given .dgit directory
+func NewCCLibraryManager(dgitDir string) *CCLibraryManager {
+	manager := &CCLibraryManager{
+		DgitDir:      dgitDir,
+		ObjectsDir:   filepath.Join(dgitDir, "cclib", "objects"),
+		RestoredDir:  filepath.Join(dgitDir, "cclib", "restored"),
+		LibrariesDir: DefaultLibrariesDir(),
+	}
+	if config, err := initializer.GetRepositoryConfig(dgitDir); err == nil {
+		manager.enabled = config.CCLibraries.Enabled
+		if config.CCLibraries.LibrariesDir != "" {
+			manager.LibrariesDir = config.CCLibraries.LibrariesDir
+		}
+	}
+	return manager
+}
+
+// DefaultLibrariesDir is where the Creative Cloud desktop app keeps synced libraries
+func DefaultLibrariesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "Library", "Application Support", "Adobe", "Creative Cloud Libraries", "LIBS")
+}
+
+// Enabled reports whether commits snapshot library elements
+func (m *CCLibraryManager) Enabled() bool {
+	return m.enabled
+}
+
+// SetEnabled turns the connector on or off, optionally changing the library folder
+func (m *CCLibraryManager) SetEnabled(enabled bool, librariesDir string) error {
+	config, err := initializer.GetRepositoryConfig(m.DgitDir)
+	if err != nil {
+		return err
+	}
+	config.CCLibraries.Enabled = enabled
+	if librariesDir != "" {
+		abs, err := filepath.Abs(librariesDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve libraries folder: %w", err)
+		}
+		config.CCLibraries.LibrariesDir = abs
+		m.LibrariesDir = abs
+	}
+	m.enabled = enabled
+	return initializer.UpdateRepositoryConfig(m.DgitDir, config)
+}
+
+// FindRefs lists the library elements linked from a document, in order of first appearance
+func FindRefs(path string) ([]Ref, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	seen := make(map[Ref]bool)
+	var refs []Ref
+	buf := make([]byte, 0, scanChunk+scanOverlap)
+	chunk := make([]byte, scanChunk)
+	for {
+		n, readErr := io.ReadFull(file, chunk)
+		buf = append(buf, chunk[:n]...)
+		for _, match := range refPattern.FindAllSubmatch(buf, -1) {
+			ref := Ref{LibraryID: strings.ToLower(string(match[1])), ElementID: strings.ToLower(string(match[2]))}
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return refs, nil
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, readErr)
+		}
+		// Keep a tail so a link split across chunks is still found
+		if len(buf) > scanOverlap {
+			buf = append(buf[:0], buf[len(buf)-scanOverlap:]...)
+		}
+	}
+}
+
+// Snapshot stores every library element linked from the given files (repository path → absolute path)
+// Elements that cannot be found locally are returned with Error set so the commit still records the link
+func (m *CCLibraryManager) Snapshot(files map[string]string) ([]*Asset, error) {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	byRef := make(map[Ref]*Asset)
+	var assets []*Asset
+	for _, path := range paths {
+		refs, err := FindRefs(files[path])
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			asset := byRef[ref]
+			if asset == nil {
+				asset = m.snapshotElement(ref)
+				byRef[ref] = asset
+				assets = append(assets, asset)
+			}
+			asset.ReferencedBy = append(asset.ReferencedBy, path)
+		}
+	}
+	return assets, nil
+}
+
+// snapshotElement copies one element's component files into the object store
+func (m *CCLibraryManager) snapshotElement(ref Ref) *Asset {
+	asset := &Asset{LibraryID: ref.LibraryID, ElementID: ref.ElementID}
+
+	libraryDir, err := m.findLibrary(ref.LibraryID)
+	if err != nil {
+		asset.Error = err.Error()
+		return asset
+	}
+	manifest, err := readManifest(libraryDir)
+	if err != nil {
+		asset.Error = err.Error()
+		return asset
+	}
+	asset.LibraryName = manifest.Name
+
+	element := manifest.find(ref.ElementID)
+	if element == nil {
+		asset.Error = fmt.Sprintf("element %s is not in library %s", ref.ElementID, libraryLabel(asset))
+		return asset
+	}
+	asset.Name = element.Name
+	asset.Modified = element.Modified
+
+	for _, component := range element.allComponents() {
+		source := componentFile(libraryDir, component)
+		if source == "" {
+			asset.Error = fmt.Sprintf("component %s of %s has not been downloaded by Creative Cloud", component.ID, element.Name)
+			continue
+		}
+		hash, size, err := m.storeObject(source)
+		if err != nil {
+			asset.Error = err.Error()
+			continue
+		}
+		asset.Components = append(asset.Components, &Component{
+			Name: componentName(component, source),
+			Hash: hash,
+			Size: size,
+		})
+	}
+	if len(asset.Components) == 0 && asset.Error == "" {
+		asset.Error = fmt.Sprintf("element %s has no files", element.Name)
+	}
+	return asset
+}
+
+// findLibrary locates a library's folder in the local Creative Cloud cache
+// Libraries are stored as <id> or <id>.dcx folders a few levels below LIBS
+func (m *CCLibraryManager) findLibrary(libraryID string) (string, error) {
+	if m.libraries == nil {
+		m.libraries = make(map[string]string)
+		if m.LibrariesDir != "" {
+			filepath.Walk(m.LibrariesDir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || !info.IsDir() {
+					return nil
+				}
+				if id := strings.ToLower(strings.TrimSuffix(info.Name(), ".dcx")); len(id) == 36 && fileExists(filepath.Join(path, "manifest")) {
+					m.libraries[id] = path
+					return filepath.SkipDir
+				}
+				return nil
+			})
+		}
+	}
+	if dir, ok := m.libraries[libraryID]; ok {
+		return dir, nil
+	}
+	return "", fmt.Errorf("library %s is not synced to %s", libraryID, m.LibrariesDir)
+}
+
+// storeObject copies a file into the object store under its SHA-256
+func (m *CCLibraryManager) storeObject(source string) (string, int64, error) {
+	if err := os.MkdirAll(m.ObjectsDir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create CC Library store: %w", err)
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(m.ObjectsDir, ".incoming-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), in)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to copy %s: %w", source, err)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	target := m.objectPath(hash)
+	if fileExists(target) {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create CC Library store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", 0, fmt.Errorf("failed to store %s: %w", source, err)
+	}
+	return hash, size, nil
+}
+
+// objectPath returns the store path of a content hash
+func (m *CCLibraryManager) objectPath(hash string) string {
+	return filepath.Join(m.ObjectsDir, hash[:2], hash)
+}
+
+// ExportCommit writes the elements pinned by a commit to <dir>/<library>/<element>/<file>
+// Only elements referenced by one of paths are written when paths is not empty
+func (m *CCLibraryManager) ExportCommit(assets []*log.CCLibraryAsset, paths []string, dir string) (int, map[string]error) {
+	failures := make(map[string]error)
+	written := 0
+	for _, asset := range assets {
+		if len(paths) > 0 && !referencedByAny(asset, paths) {
+			continue
+		}
+		label := asset.Name
+		if label == "" {
+			label = asset.ElementID
+		}
+		if asset.Error != "" && len(asset.Components) == 0 {
+			failures[label] = fmt.Errorf("not snapshotted at commit time: %s", asset.Error)
+			continue
+		}
+		library := asset.LibraryName
+		if library == "" {
+			library = asset.LibraryID
+		}
+		target := filepath.Join(dir, safeName(library), safeName(label))
+		for _, component := range asset.Components {
+			if err := m.exportObject(component.Hash, filepath.Join(target, safeName(component.Name))); err != nil {
+				failures[label] = err
+				continue
+			}
+			written++
+		}
+	}
+	return written, failures
+}
+
+// exportObject copies a stored object out, checking its hash on the way
+func (m *CCLibraryManager) exportObject(hash, target string) error {
+	if len(hash) < 2 {
+		return fmt.Errorf("invalid object hash %q", hash)
+	}
+	in, err := os.Open(m.objectPath(hash))
+	if err != nil {
+		return fmt.Errorf("object %s is missing from the CC Library store: %w", hash[:12], err)
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, hasher), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		os.Remove(target)
+		return fmt.Errorf("object %s is corrupt", hash[:12])
+	}
+	return nil
+}
+
+// VersionDir is where restore exports the elements pinned by a version
+func (m *CCLibraryManager) VersionDir(version int) string {
+	return filepath.Join(m.RestoredDir, fmt.Sprintf("v%d", version))
+}
+
+// manifest is the part of a library's DCX manifest needed to find an element's files
+type manifest struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Children   []*manifestNode      `json:"children"`
+	Components []*manifestComponent `json:"components"`
+}
+
+// manifestNode is an element (or a group of elements) in a library manifest
+type manifestNode struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Modified   string               `json:"library#modified"`
+	Children   []*manifestNode      `json:"children"`
+	Components []*manifestComponent `json:"components"`
+}
+
+// manifestComponent is one file of an element
+type manifestComponent struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// readManifest parses <library>/manifest
+func readManifest(libraryDir string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(libraryDir, "manifest"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read library manifest: %w", err)
+	}
+	var parsed manifest
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse library manifest %s: %w", libraryDir, err)
+	}
+	return &parsed, nil
+}
+
+// find returns the element with the given ID anywhere in the manifest
+func (mf *manifest) find(elementID string) *manifestNode {
+	var search func(nodes []*manifestNode) *manifestNode
+	search = func(nodes []*manifestNode) *manifestNode {
+		for _, node := range nodes {
+			if strings.EqualFold(node.ID, elementID) {
+				return node
+			}
+			if found := search(node.Children); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return search(mf.Children)
+}
+
+// allComponents lists an element's components including those of nested nodes (renditions)
+func (node *manifestNode) allComponents() []*manifestComponent {
+	components := append([]*manifestComponent(nil), node.Components...)
+	for _, child := range node.Children {
+		components = append(components, child.allComponents()...)
+	}
+	return components
+}
+
+// componentFile finds a component's file in the library folder, or "" when it was never downloaded
+func componentFile(libraryDir string, component *manifestComponent) string {
+	dir := filepath.Join(libraryDir, "components")
+	candidates := []string{
+		filepath.Join(dir, component.ID+filepath.Ext(component.Path)),
+		filepath.Join(dir, component.ID),
+	}
+	if component.Path != "" {
+		candidates = append(candidates, filepath.Join(dir, filepath.FromSlash(component.Path)))
+	}
+	for _, candidate := range candidates {
+		if fileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// componentName is the file name an exported component gets
+func componentName(component *manifestComponent, source string) string {
+	if component.Path != "" {
+		return filepath.Base(component.Path)
+	}
+	return filepath.Base(source)
+}
+
+// referencedByAny reports whether an asset is linked from one of the given paths
+func referencedByAny(asset *log.CCLibraryAsset, paths []string) bool {
+	for _, ref := range asset.ReferencedBy {
+		for _, path := range paths {
+			path = filepath.ToSlash(filepath.Clean(path))
+			if ref == path || filepath.Base(ref) == filepath.Base(path) || strings.HasPrefix(ref, strings.TrimSuffix(path, "/")+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// libraryLabel names an asset's library for messages
+func libraryLabel(asset *Asset) string {
+	if asset.LibraryName != "" {
+		return asset.LibraryName
+	}
+	return asset.LibraryID
+}
+
+// safeName keeps library and element names usable as folder names
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// fileExists reports whether a regular file exists
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
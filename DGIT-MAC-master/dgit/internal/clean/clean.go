@@ -0,0 +1,174 @@
+package clean
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/trash"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// Residue is an autosave, lock or temp file (or recovery folder) found in the work tree,
+// or an untracked file matching the repository's clean patterns
+type Residue struct {
+	Path   string // Relative to the working tree root
+	App    string
+	Reason string
+	Size   int64
+	Dir    bool
+}
+
+// CleanManager finds application residue and moves it out of the work tree
+// Removed files go to the repository trash so they can be brought back with 'dgit trash restore'
+type CleanManager struct {
+	DgitDir string
+	RootDir string
+}
+
+// NewCleanManager creates a new clean manager for the given .dgit directory
+func NewCleanManager(dgitDir string) *CleanManager {
+	return &CleanManager{
+		DgitDir: dgitDir,
+		RootDir: filepath.Dir(dgitDir),
+	}
+}
+
+// Find walks the work tree and returns residue matching the built-in application patterns
+// and untracked files matching the configured clean patterns; tracked, staged and protected files are never returned
+func (cm *CleanManager) Find() ([]*Residue, error) {
+	moduleDirs := submodule.NewSubmoduleManager(cm.DgitDir).ModuleDirs()
+	rules := initializer.CleanConfig{}
+	if config, err := initializer.GetRepositoryConfig(cm.DgitDir); err == nil {
+		rules = config.Clean
+	}
+	tracked, err := cm.trackedPaths()
+	if err != nil {
+		return nil, err
+	}
+	var found []*Residue
+
+	err = filepath.Walk(cm.RootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if info.IsDir() && path != cm.RootDir {
+			if info.Name() == ".dgit" || moduleDirs[path] {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, ".dgit")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		if path == cm.RootDir {
+			return nil
+		}
+		rel, _ := filepath.Rel(cm.RootDir, path)
+		rel = filepath.ToSlash(rel)
+
+		rule := scanner.MatchResidue(path, info.IsDir())
+		if rule == nil {
+			if info.IsDir() || tracked[rel] || cm.isProtected(rel, rules.Protected) {
+				return nil
+			}
+			if pattern := matchAny(rel, rules.Patterns); pattern != "" {
+				found = append(found, &Residue{Path: rel, App: "Rule", Reason: "matches " + pattern, Size: info.Size()})
+			}
+			return nil
+		}
+		if matchAny(rel, rules.Protected) != "" || tracked[rel] {
+			return nil
+		}
+		residue := &Residue{Path: rel, App: rule.App, Reason: rule.Reason, Size: info.Size(), Dir: info.IsDir()}
+		if info.IsDir() {
+			residue.Size = util.DirSize(path)
+			found = append(found, residue)
+			return filepath.SkipDir
+		}
+		found = append(found, residue)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan work tree: %w", err)
+	}
+	return found, nil
+}
+
+// Remove moves residue from the work tree to the trash
+func (cm *CleanManager) Remove(items []*Residue) error {
+	trashManager := trash.NewTrashManager(cm.DgitDir)
+	for _, item := range items {
+		if _, err := trashManager.Trash(filepath.Join(cm.RootDir, item.Path), "clean"); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", item.Path, err)
+		}
+	}
+	return nil
+}
+
+// trackedPaths returns the files in the current version and the staging area, relative to the root
+func (cm *CleanManager) trackedPaths() (map[string]bool, error) {
+	tracked := make(map[string]bool)
+
+	logManager := log.NewLogManager(cm.DgitDir)
+	if head := logManager.GetHeadVersion(); head > 0 {
+		c, err := logManager.GetCommit(head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load current version: %w", err)
+		}
+		for path := range c.Metadata {
+			tracked[filepath.ToSlash(path)] = true
+		}
+	}
+
+	stagingArea := staging.NewStagingArea(cm.DgitDir)
+	if err := stagingArea.LoadStaging(); err != nil {
+		return nil, fmt.Errorf("failed to load staging area: %w", err)
+	}
+	for _, file := range stagingArea.GetStagedFiles() {
+		if rel, err := filepath.Rel(cm.RootDir, file.AbsolutePath); err == nil {
+			tracked[filepath.ToSlash(rel)] = true
+		}
+	}
+	return tracked, nil
+}
+
+// isProtected reports whether a file must never be cleaned: design sources and configured protected paths
+func (cm *CleanManager) isProtected(rel string, protected []string) bool {
+	return scanner.IsDesignFile(rel) || matchAny(rel, protected) != ""
+}
+
+// matchAny returns the first pattern matching a relative path, or "" if none does
+// Patterns are matched against the whole path and the file name; "dir/" matches everything below a folder
+func matchAny(rel string, patterns []string) string {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			parts := strings.Split(rel, "/")
+			for i := 1; i < len(parts); i++ {
+				prefix := strings.Join(parts[:i], "/")
+				if matched, _ := filepath.Match(dir, prefix); matched {
+					return pattern
+				}
+				if !strings.Contains(dir, "/") {
+					if matched, _ := filepath.Match(dir, parts[i-1]); matched {
+						return pattern
+					}
+				}
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, rel); matched {
+			return pattern
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(rel)); matched {
+			return pattern
+		}
+	}
+	return ""
+}
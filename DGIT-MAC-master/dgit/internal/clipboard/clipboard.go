@@ -0,0 +1,81 @@
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CopyImage places an image file on the system clipboard as a picture
+// macOS uses the pasteboard through osascript, Windows the clipboard through PowerShell,
+// and other systems wl-copy or xclip
+func CopyImage(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(abs))
+	switch runtime.GOOS {
+	case "darwin":
+		class := "«class PNGf»"
+		switch ext {
+		case ".jpg", ".jpeg":
+			class = "JPEG picture"
+		case ".tif", ".tiff":
+			class = "TIFF picture"
+		case ".gif":
+			class = "GIF picture"
+		}
+		script := fmt.Sprintf("set the clipboard to (read (POSIX file %q) as %s)", abs, class)
+		return run(exec.Command("osascript", "-e", script))
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms; Add-Type -AssemblyName System.Drawing; " +
+			"[System.Windows.Forms.Clipboard]::SetImage([System.Drawing.Image]::FromFile($args[0]))"
+		return run(exec.Command("powershell", "-NoProfile", "-STA", "-Command", script, abs))
+	}
+
+	mimeType := "image/png"
+	switch ext {
+	case ".jpg", ".jpeg":
+		mimeType = "image/jpeg"
+	case ".gif":
+		mimeType = "image/gif"
+	case ".tif", ".tiff":
+		mimeType = "image/tiff"
+	}
+	if _, err := exec.LookPath("wl-copy"); err == nil && os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmd := exec.Command("wl-copy", "--type", mimeType)
+		file, err := os.Open(abs)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer file.Close()
+		cmd.Stdin = file
+		return run(cmd)
+	}
+	if _, err := exec.LookPath("xclip"); err == nil {
+		return run(exec.Command("xclip", "-selection", "clipboard", "-t", mimeType, "-i", abs))
+	}
+	return fmt.Errorf("no clipboard tool found (install wl-clipboard or xclip)")
+}
+
+// run executes a clipboard tool, reporting its error output on failure
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", filepath.Base(cmd.Path), msg)
+		}
+		return fmt.Errorf("%s failed: %w", filepath.Base(cmd.Path), err)
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package clone
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/remote"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repomerge"
+)
+
+// Tiers are the cache tiers a clone can copy
+var Tiers = []string{"hot", "warm", "cold"}
+
+// skipEntries are .dgit entries that belong to one working copy or machine and are never cloned
+// Keys stay behind because tokens and signing keys are per-repository secrets; hooks because
+// they are executables the new owner has not reviewed
+var skipEntries = map[string]bool{
+	"temp": true, "locks": true, "keys": true, "hooks": true, "staging": true, "trash": true,
+	"logs": true, "metrics": true, "index": true,
+	"pins.json": true, "shares.json": true, "restore-journal.json": true,
+	"recompress-state.json": true, "ingest-state.json": true, "autocommit-state.json": true,
+	"figma-state.json": true, "backup-state.json": true,
+}
+
+// Progress reports how far a clone has copied or downloaded
+type Progress struct {
+	File  string // File being copied, relative to .dgit; empty while downloading
+	Done  int64
+	Total int64 // -1 when the size is not known in advance
+}
+
+// Result describes a finished clone
+type Result struct {
+	Source   string
+	Target   string
+	Remote   bool  // Cloned from a 'dgit serve' URL rather than a local path
+	Versions int   // Commits in the clone
+	Bytes    int64 // Bytes copied or downloaded
+	Skipped  int   // Cache copies left out because their tier was not selected
+	Head     *log.Commit
+}
+
+// CloneManager copies an existing repository into a new working directory
+type CloneManager struct {
+	Target  string
+	DgitDir string
+
+	// Tiers limits which cache tiers are copied from a local source; nil copies every tier
+	// A version with no copy in the selected tiers keeps the copies it has, so the clone stays restorable
+	Tiers []string
+
+	// Token authenticates to a remote source; DGIT_REMOTE_TOKEN is used when empty
+	Token string
+
+	// Progress, when set, is called as data is copied or downloaded
+	Progress func(Progress)
+}
+
+// NewCloneManager creates a clone manager that creates a repository in target
+func NewCloneManager(target string) *CloneManager {
+	return &CloneManager{
+		Target:  target,
+		DgitDir: filepath.Join(target, initializer.DGitDir),
+	}
+}
+
+// IsRemote reports whether a clone source is a 'dgit serve' URL
+func IsRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// ParseTiers validates a list of cache tier names
+func ParseTiers(names []string) ([]string, error) {
+	var tiers []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		valid := false
+		for _, tier := range Tiers {
+			valid = valid || tier == name
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown cache tier %q (use %s)", name, strings.Join(Tiers, ", "))
+		}
+		tiers = append(tiers, name)
+	}
+	return tiers, nil
+}
+
+// Clone copies source, a repository path or a 'dgit serve' URL, into the target directory
+// The target is removed again if the clone fails
+func (cm *CloneManager) Clone(source string) (*Result, error) {
+	if err := cm.prepareTarget(); err != nil {
+		return nil, err
+	}
+	var result *Result
+	var err error
+	if IsRemote(source) {
+		result, err = cm.cloneRemote(source)
+	} else {
+		result, err = cm.cloneLocal(source)
+	}
+	if err != nil {
+		os.RemoveAll(cm.Target)
+		return nil, err
+	}
+
+	logManager := log.NewLogManager(cm.DgitDir)
+	history, err := logManager.GetCommitHistory()
+	if err != nil {
+		os.RemoveAll(cm.Target)
+		return nil, fmt.Errorf("failed to read cloned history: %w", err)
+	}
+	result.Versions = len(history)
+	if hash := logManager.HeadHash(); hash != "" {
+		result.Head, _ = logManager.GetCommitByHash(hash)
+	}
+	return result, nil
+}
+
+// prepareTarget creates the target directory, which must not exist or be empty
+func (cm *CloneManager) prepareTarget() error {
+	entries, err := os.ReadDir(cm.Target)
+	if err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", cm.Target)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", cm.Target, err)
+	}
+	if err := os.MkdirAll(cm.Target, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", cm.Target, err)
+	}
+	return nil
+}
+
+// cloneRemote initializes an empty repository and pulls every version from origin
+func (cm *CloneManager) cloneRemote(url string) (*Result, error) {
+	if err := initializer.NewRepositoryInitializer().InitializeRepository(cm.Target); err != nil {
+		return nil, err
+	}
+	manager := remote.NewRemoteManager(cm.DgitDir)
+	if err := manager.Add(remote.DefaultRemote, url); err != nil {
+		return nil, err
+	}
+	if cm.Token != "" {
+		if err := manager.SaveToken(remote.DefaultRemote, cm.Token); err != nil {
+			return nil, err
+		}
+	}
+	if cm.Progress != nil {
+		manager.Progress = func(done, total int64) {
+			cm.Progress(Progress{Done: done, Total: total})
+		}
+	}
+	pulled, err := manager.Pull(remote.DefaultRemote)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Source: url, Target: cm.Target, Remote: true, Bytes: pulled.Bytes}, nil
+}
+
+// cloneLocal copies history, snapshot data and shared state from another repository on disk
+func (cm *CloneManager) cloneLocal(source string) (*Result, error) {
+	sourceDgit, err := findDgitDir(source)
+	if err != nil {
+		return nil, err
+	}
+	dropped, err := cm.droppedCopies(sourceDgit)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		rel  string
+		size int64
+	}
+	var files []entry
+	var total int64
+	err = filepath.Walk(sourceDgit, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(sourceDgit, path)
+		if rel == "." {
+			return nil
+		}
+		if skipEntries[strings.Split(filepath.ToSlash(rel), "/")[0]] {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !info.Mode().IsRegular() || dropped[rel] {
+			return nil
+		}
+		files = append(files, entry{rel: rel, size: info.Size()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", sourceDgit, err)
+	}
+
+	// A fresh repository provides the per-machine directories that are not copied
+	if err := initializer.NewRepositoryInitializer().InitializeRepository(cm.Target); err != nil {
+		return nil, err
+	}
+	result := &Result{Source: filepath.Dir(sourceDgit), Target: cm.Target, Skipped: len(dropped)}
+	for _, f := range files {
+		target := filepath.Join(cm.DgitDir, f.rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		copied, err := cm.copyFile(filepath.Join(sourceDgit, f.rel), target, f.rel, result.Bytes, total)
+		result.Bytes += copied
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", f.rel, err)
+		}
+	}
+	return result, nil
+}
+
+// droppedCopies returns the cache files, relative to .dgit, left out by the tier selection
+func (cm *CloneManager) droppedCopies(sourceDgit string) (map[string]bool, error) {
+	dropped := make(map[string]bool)
+	if cm.Tiers == nil {
+		return dropped, nil
+	}
+	history, err := log.NewLogManager(sourceDgit).GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source history: %w", err)
+	}
+	for _, c := range history {
+		var kept, skipped []string
+		for _, rel := range repomerge.StorageFiles(sourceDgit, c.Version) {
+			if tier := cacheTier(rel); tier != "" && !cm.wantsTier(tier) {
+				skipped = append(skipped, rel)
+			} else {
+				kept = append(kept, rel)
+			}
+		}
+		if len(kept) == 0 {
+			continue // Keep every copy rather than leave the version unrestorable
+		}
+		for _, rel := range skipped {
+			dropped[rel] = true
+		}
+	}
+	return dropped, nil
+}
+
+// wantsTier reports whether a cache tier was selected
+func (cm *CloneManager) wantsTier(tier string) bool {
+	for _, t := range cm.Tiers {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheTier returns the tier of a storage file under cache/, or "" for other storage
+func cacheTier(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) >= 3 && parts[0] == "cache" {
+		return parts[1]
+	}
+	return ""
+}
+
+// copyFile copies one file, reporting overall progress as it goes
+func (cm *CloneManager) copyFile(src, dst, rel string, before, total int64) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return 0, err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return 0, err
+	}
+
+	var copied int64
+	buf := make([]byte, 1024*1024)
+	for {
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				out.Close()
+				return copied, err
+			}
+			copied += int64(n)
+			if cm.Progress != nil {
+				cm.Progress(Progress{File: rel, Done: before + copied, Total: total})
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			out.Close()
+			return copied, readErr
+		}
+	}
+	return copied, out.Close()
+}
+
+// findDgitDir accepts a working tree or its .dgit directory
+func findDgitDir(source string) (string, error) {
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid source path: %w", err)
+	}
+	if filepath.Base(abs) == initializer.DGitDir && initializer.IsDGitRepository(filepath.Dir(abs)) {
+		return abs, nil
+	}
+	if initializer.IsDGitRepository(abs) {
+		return filepath.Join(abs, initializer.DGitDir), nil
+	}
+	return "", fmt.Errorf("%s is not a DGit repository", source)
+}
@@ -0,0 +1,199 @@
+// Package codec is the registry of stream codecs used for snapshot objects
+// Objects are recognised by file extension, so readers never need the commit to pick a decoder
+package codec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Codec names
+const (
+	LZ4   = "lz4"
+	Zstd  = "zstd"
+	Store = "store"
+)
+
+// DefaultStoreExtensions are formats that are ZIP containers internally and never shrink further
+var DefaultStoreExtensions = []string{".sketch", ".fig", ".xd", ".kra"}
+
+// Codec compresses and decompresses one snapshot stream
+type Codec interface {
+	Name() string
+	Ext() string // Object file extension including the dot, e.g. ".lz4"
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var registry = make(map[string]Codec)
+
+// Register adds a codec to the registry, replacing any codec with the same name
+func Register(c Codec) {
+	registry[c.Name()] = c
+}
+
+// Get returns a registered codec by name
+func Get(name string) (Codec, error) {
+	if c, ok := registry[name]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unknown codec %q", name)
+}
+
+// ForObject returns the codec that wrote an object, judged by its file extension
+func ForObject(path string) (Codec, bool) {
+	ext := filepath.Ext(path)
+	for _, c := range registry {
+		if c.Ext() == ext {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Names lists registered codecs in name order
+func Names() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsStoreExtension reports whether a path has one of the given already-compressed extensions
+func IsStoreExtension(path string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range extensions {
+		if strings.ToLower(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Deprecation describes an object format readers still accept but writers no longer produce
+// Objects in a deprecated format are rewritten in the background by the recompress task
+type Deprecation struct {
+	Format      string // Identifier recorded in recompress progress, e.g. "text-framed"
+	Description string
+	Codecs      []string               // Codecs whose objects may carry the format
+	Match       func(head []byte) bool // Reports whether the first decoded bytes are in this format
+}
+
+var deprecations []Deprecation
+
+// Deprecate adds a format to the deprecation list
+func Deprecate(d Deprecation) {
+	deprecations = append(deprecations, d)
+}
+
+// Deprecations returns the deprecated formats
+func Deprecations() []Deprecation {
+	return deprecations
+}
+
+// DeprecatedFormat reports which deprecated format, if any, an object's decoded stream uses
+func DeprecatedFormat(codecName string, head []byte) (Deprecation, bool) {
+	for _, d := range deprecations {
+		for _, name := range d.Codecs {
+			if name == codecName && d.Match(head) {
+				return d, true
+			}
+		}
+	}
+	return Deprecation{}, false
+}
+
+// TextFramed is the deprecated format of the original warm-cache and delta streams
+const TextFramed = "text-framed"
+
+// TextFramedHeader starts every entry of a legacy text-framed stream: "FILE:path:size\n" then the data
+const TextFramedHeader = "FILE:"
+
+func init() {
+	Register(lz4Codec{})
+	Register(zstdCodec{})
+	Register(storeCodec{})
+
+	Deprecate(Deprecation{
+		Format:      TextFramed,
+		Description: "legacy text-framed stream (FILE:path:size headers)",
+		Codecs:      []string{LZ4, Zstd},
+		Match: func(head []byte) bool {
+			return strings.HasPrefix(string(head), TextFramedHeader)
+		},
+	})
+}
+
+// lz4Codec is the hot-cache codec: fastest compression, modest ratios
+type lz4Codec struct{}
+
+func (lz4Codec) Name() string { return LZ4 }
+func (lz4Codec) Ext() string  { return ".lz4" }
+
+func (lz4Codec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	writer := lz4.NewWriter(w)
+	if err := writer.Apply(lz4.CompressionLevelOption(lz4.Level1)); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (lz4Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(NewLZ4Reader(r)), nil
+}
+
+// NewLZ4Reader decodes an LZ4 stream of one or more concatenated frames
+// Parallel commits write one frame per file (see compression.workers)
+func NewLZ4Reader(r io.Reader) io.Reader {
+	src := bufio.NewReader(r)
+	return &lz4FramesReader{src: src, frame: lz4.NewReader(src)}
+}
+
+// lz4FramesReader starts a new frame whenever input remains after the current one
+type lz4FramesReader struct {
+	src   *bufio.Reader
+	frame *lz4.Reader
+}
+
+func (fr *lz4FramesReader) Read(p []byte) (int, error) {
+	for {
+		n, err := fr.frame.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		if _, peekErr := fr.src.Peek(1); peekErr != nil {
+			return n, io.EOF
+		}
+		fr.frame.Reset(fr.src)
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// zstdCodec is the warm/cold codec: slower, better ratios
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string { return Zstd }
+func (zstdCodec) Ext() string  { return ".zstd" }
+
+func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault))
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
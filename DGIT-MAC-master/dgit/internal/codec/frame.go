@@ -0,0 +1,214 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FramedMagic starts every framed snapshot stream, inside whichever codec compresses it
+const FramedMagic = "DGFRAME1"
+
+// maxFramePath bounds the path length stored in a frame header
+const maxFramePath = 4096
+
+// FrameHeader describes one file in a framed stream
+// Layout per file: [uint16 path length][path][uint64 size][uint32 mode][data][uint32 CRC32 of data];
+// a zero path length ends the stream
+type FrameHeader struct {
+	Path string // Repository path with forward slashes
+	Size int64
+	Mode os.FileMode
+}
+
+// IsFramed reports whether decoded stream bytes start a framed container
+func IsFramed(head []byte) bool {
+	return bytes.HasPrefix(head, []byte(FramedMagic))
+}
+
+// FrameWriter writes files into a framed stream
+type FrameWriter struct {
+	w      io.Writer
+	closed bool
+}
+
+// NewFrameWriter starts a framed stream on w
+func NewFrameWriter(w io.Writer) (*FrameWriter, error) {
+	if _, err := io.WriteString(w, FramedMagic); err != nil {
+		return nil, err
+	}
+	return &FrameWriter{w: w}, nil
+}
+
+// ContinueFrameWriter writes more files of a framed stream whose magic was written elsewhere
+// Parallel writers encode files separately and concatenate the pieces; only the last piece is closed
+func ContinueFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// WriteFile adds one file; exactly header.Size bytes are read from r
+func (fw *FrameWriter) WriteFile(header FrameHeader, r io.Reader) (int64, error) {
+	path := filepath.ToSlash(header.Path)
+	if path == "" || len(path) > maxFramePath {
+		return 0, fmt.Errorf("invalid frame path %q", header.Path)
+	}
+	buf := make([]byte, 2+len(path)+12)
+	binary.BigEndian.PutUint16(buf[0:2], uint16(len(path)))
+	copy(buf[2:], path)
+	binary.BigEndian.PutUint64(buf[2+len(path):], uint64(header.Size))
+	binary.BigEndian.PutUint32(buf[10+len(path):], uint32(header.Mode.Perm()))
+	if _, err := fw.w.Write(buf); err != nil {
+		return 0, err
+	}
+
+	sum := crc32.NewIEEE()
+	written, err := io.CopyN(io.MultiWriter(fw.w, sum), r, header.Size)
+	if err != nil {
+		if err == io.EOF {
+			return written, fmt.Errorf("%s shrank while being written (%d of %d bytes)", path, written, header.Size)
+		}
+		return written, err
+	}
+	var trailer [4]byte
+	binary.BigEndian.PutUint32(trailer[:], sum.Sum32())
+	_, err = fw.w.Write(trailer[:])
+	return written, err
+}
+
+// Close ends the stream; the underlying writer is left open
+func (fw *FrameWriter) Close() error {
+	if fw.closed {
+		return nil
+	}
+	fw.closed = true
+	_, err := fw.w.Write([]byte{0, 0})
+	return err
+}
+
+// FrameReader reads files from a framed stream
+// Each file's data is checked against its CRC32 when its last byte has been read
+type FrameReader struct {
+	r         *bufio.Reader
+	remaining int64
+	sum       hash.Hash32
+	current   *FrameHeader
+	done      bool
+}
+
+// NewFrameReader reads the magic of a framed stream
+func NewFrameReader(r io.Reader) (*FrameReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(FramedMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || !IsFramed(magic) {
+		return nil, fmt.Errorf("not a framed stream")
+	}
+	return &FrameReader{r: br}, nil
+}
+
+// Next skips the rest of the current file and returns the next header, io.EOF after the last
+func (fr *FrameReader) Next() (*FrameHeader, error) {
+	if fr.done {
+		return nil, io.EOF
+	}
+	if fr.current != nil {
+		if _, err := io.Copy(io.Discard, fr); err != nil {
+			return nil, err
+		}
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(fr.r, length[:]); err != nil {
+		return nil, fmt.Errorf("truncated framed stream: %w", io.ErrUnexpectedEOF)
+	}
+	pathLen := int(binary.BigEndian.Uint16(length[:]))
+	if pathLen == 0 {
+		fr.done, fr.current = true, nil
+		return nil, io.EOF
+	}
+	if pathLen > maxFramePath {
+		return nil, fmt.Errorf("corrupt frame header (path length %d)", pathLen)
+	}
+	buf := make([]byte, pathLen+12)
+	if _, err := io.ReadFull(fr.r, buf); err != nil {
+		return nil, fmt.Errorf("truncated frame header: %w", io.ErrUnexpectedEOF)
+	}
+	header := &FrameHeader{
+		Path: string(buf[:pathLen]),
+		Size: int64(binary.BigEndian.Uint64(buf[pathLen:])),
+		Mode: os.FileMode(binary.BigEndian.Uint32(buf[pathLen+8:])).Perm(),
+	}
+	if header.Size < 0 || strings.Contains(header.Path, "\x00") {
+		return nil, fmt.Errorf("corrupt frame header for %q", header.Path)
+	}
+	fr.current, fr.remaining, fr.sum = header, header.Size, crc32.NewIEEE()
+	if header.Size == 0 {
+		if err := fr.checkTrailer(); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// Read reads the current file's data
+func (fr *FrameReader) Read(p []byte) (int, error) {
+	if fr.current == nil || fr.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > fr.remaining {
+		p = p[:fr.remaining]
+	}
+	n, err := fr.r.Read(p)
+	fr.sum.Write(p[:n])
+	fr.remaining -= int64(n)
+	if fr.remaining == 0 {
+		if err := fr.checkTrailer(); err != nil {
+			return n, err
+		}
+		return n, nil
+	}
+	if err == io.EOF {
+		return n, fmt.Errorf("truncated data for %s: %w", fr.current.Path, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+// checkTrailer compares the finished file with its stored CRC32
+func (fr *FrameReader) checkTrailer() error {
+	var trailer [4]byte
+	if _, err := io.ReadFull(fr.r, trailer[:]); err != nil {
+		return fmt.Errorf("truncated checksum for %s: %w", fr.current.Path, io.ErrUnexpectedEOF)
+	}
+	if binary.BigEndian.Uint32(trailer[:]) != fr.sum.Sum32() {
+		return fmt.Errorf("checksum mismatch for %s", fr.current.Path)
+	}
+	fr.remaining = -1 // Trailer consumed; further reads return io.EOF
+	return nil
+}
+
+// EachFrame calls fn for every file in a framed stream, verifying each checksum
+// fn may read as much of the data as it needs; the rest is skipped and still verified
+func EachFrame(r io.Reader, fn func(header *FrameHeader, data io.Reader) error) error {
+	reader, err := NewFrameReader(r)
+	if err != nil {
+		return err
+	}
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(header, reader); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// storeMagic starts every store object
+var storeMagic = []byte("DGSTORE1")
+
+// storeBlockSize is the largest payload of one store block
+const storeBlockSize = 1 << 20
+
+// storeCodec keeps bytes as they are but frames and checksums them
+// Layout: magic, then blocks of [uint32 length][uint32 CRC32][data], then a zero-length
+// block whose CRC field holds the CRC32 of the whole payload
+type storeCodec struct{}
+
+func (storeCodec) Name() string { return Store }
+func (storeCodec) Ext() string  { return ".store" }
+
+func (storeCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if _, err := w.Write(storeMagic); err != nil {
+		return nil, err
+	}
+	return &storeWriter{w: w, total: crc32.NewIEEE()}, nil
+}
+
+func (storeCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	magic := make([]byte, len(storeMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, storeMagic) {
+		return nil, fmt.Errorf("not a store object")
+	}
+	return &storeReader{r: r, total: crc32.NewIEEE()}, nil
+}
+
+// storeWriter buffers payload into blocks
+type storeWriter struct {
+	w     io.Writer
+	buf   []byte
+	total hash.Hash32
+}
+
+func (sw *storeWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := storeBlockSize - len(sw.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		sw.buf = append(sw.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(sw.buf) == storeBlockSize {
+			if err := sw.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Flush writes any buffered payload as a block
+func (sw *storeWriter) Flush() error {
+	return sw.flush()
+}
+
+func (sw *storeWriter) flush() error {
+	if len(sw.buf) == 0 {
+		return nil
+	}
+	if err := writeBlockHeader(sw.w, uint32(len(sw.buf)), crc32.ChecksumIEEE(sw.buf)); err != nil {
+		return err
+	}
+	if _, err := sw.w.Write(sw.buf); err != nil {
+		return err
+	}
+	sw.total.Write(sw.buf)
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+// Close writes the remaining payload and the end block
+func (sw *storeWriter) Close() error {
+	if err := sw.flush(); err != nil {
+		return err
+	}
+	return writeBlockHeader(sw.w, 0, sw.total.Sum32())
+}
+
+func writeBlockHeader(w io.Writer, length, sum uint32) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[0:4], length)
+	binary.BigEndian.PutUint32(header[4:8], sum)
+	_, err := w.Write(header[:])
+	return err
+}
+
+// storeReader verifies each block and the end checksum as it reads
+type storeReader struct {
+	r     io.Reader
+	block []byte
+	total hash.Hash32
+	done  bool
+}
+
+func (sr *storeReader) Read(p []byte) (int, error) {
+	for len(sr.block) == 0 {
+		if sr.done {
+			return 0, io.EOF
+		}
+		if err := sr.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, sr.block)
+	sr.block = sr.block[n:]
+	return n, nil
+}
+
+// next reads and checks the following block
+func (sr *storeReader) next() error {
+	var header [8]byte
+	if _, err := io.ReadFull(sr.r, header[:]); err != nil {
+		return fmt.Errorf("truncated store object: %w", io.ErrUnexpectedEOF)
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	sum := binary.BigEndian.Uint32(header[4:8])
+	if length == 0 {
+		if sum != sr.total.Sum32() {
+			return fmt.Errorf("store object checksum mismatch")
+		}
+		sr.done = true
+		return nil
+	}
+	if length > storeBlockSize {
+		return fmt.Errorf("corrupt store block length %d", length)
+	}
+	block := make([]byte, length)
+	if _, err := io.ReadFull(sr.r, block); err != nil {
+		return fmt.Errorf("truncated store object: %w", io.ErrUnexpectedEOF)
+	}
+	if crc32.ChecksumIEEE(block) != sum {
+		return fmt.Errorf("store block checksum mismatch")
+	}
+	sr.total.Write(block)
+	sr.block = block
+	return nil
+}
+
+func (sr *storeReader) Close() error {
+	return nil
+}
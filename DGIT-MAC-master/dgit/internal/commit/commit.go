@@ -2,18 +2,38 @@ package commit
 
 import (
 	"archive/zip"
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"dgit/internal/scanner"
-	"dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cache"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cclib"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/layerlint"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/lock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/msgfilter"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/pin"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/thumbnail"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/vcdiff"
 	
 	// Ultra-Fast Compression Libraries
 	"github.com/pierrec/lz4/v4"
@@ -23,10 +43,16 @@ import (
 	"github.com/kr/binarydist"
 )
 
+// Hooks run around a commit, see the hooks package for the environment they get
+const (
+	HookPreCommit  = "pre-commit"  // Runs before anything is written; a non-zero exit aborts the commit
+	HookPostCommit = "post-commit" // Runs after HEAD moves to the new version
+)
+
 // CompressionResult contains comprehensive compression operation metrics
 // Enhanced for ultra-fast performance tracking and cache optimization
 type CompressionResult struct {
-	Strategy         string    `json:"strategy"`            // "lz4", "zip", "bsdiff", "xdelta3", "psd_smart"
+	Strategy         string    `json:"strategy"`            // "lz4", "zip", "bsdiff", "xdelta3", "psd_smart_delta"
 	OutputFile       string    `json:"output_file"`
 	OriginalSize     int64     `json:"original_size"`
 	CompressedSize   int64     `json:"compressed_size"`
@@ -38,6 +64,13 @@ type CompressionResult struct {
 	CompressionTime  float64   `json:"compression_time_ms"` // Milliseconds - critical metric
 	CacheLevel       string    `json:"cache_level"`         // "hot", "warm", "cold"
 	SpeedImprovement float64   `json:"speed_improvement"`   // Multiplier vs traditional methods
+	
+	// Compression feedback: per-file ratios and whether recompressing is worth the time
+	FileRatios       map[string]float64 `json:"file_ratios,omitempty"`       // Path → compressed/original
+	SkipOptimization bool               `json:"skip_optimization,omitempty"` // Content is incompressible ("store")
+
+	// Object store commits: files whose content was already stored by an earlier commit
+	ReusedFiles int `json:"reused_files,omitempty"`
 }
 
 // Commit represents a single commit in DGit with ultra-fast compression integration
@@ -47,12 +80,21 @@ type Commit struct {
 	Message         string                 `json:"message"`
 	Timestamp       time.Time              `json:"timestamp"`
 	Author          string                 `json:"author"`
+	Email           string                 `json:"email,omitempty"`
+	AuthorSource    string                 `json:"author_source,omitempty"` // "flag" or "env" when not the configured human author
 	FilesCount      int                    `json:"files_count"`
 	Version         int                    `json:"version"`
 	Metadata        map[string]interface{} `json:"metadata"`
 	ParentHash      string                 `json:"parent_hash,omitempty"`
 	SnapshotZip     string                 `json:"snapshot_zip,omitempty"`     // Legacy compatibility
 	CompressionInfo *CompressionResult     `json:"compression_info,omitempty"` // Ultra-fast compression data
+	LinkedAssets    []*linked.Link         `json:"linked_assets,omitempty"`    // Library files referenced by this commit
+	CCLibraryAssets []*cclib.Asset         `json:"cc_library_assets,omitempty"` // Creative Cloud Library elements linked from committed files
+	Sequences       []*staging.Sequence    `json:"sequences,omitempty"`        // Numbered frame sequences committed as one asset
+	Renames         map[string]string      `json:"renames,omitempty"`          // New path → previous path, recorded by 'dgit mv'
+	Session         string                 `json:"session,omitempty"`          // Working session, shared by commits without a long idle gap
+	Branch          string                 `json:"branch,omitempty"`           // Branch the commit was made on, empty for main
+	Blobs           map[string]*log.BlobRef `json:"blobs,omitempty"`           // Path → content in the object store ("content" strategy)
 }
 
 // CommitManager handles ultra-fast commit creation with 3-tier cache system
@@ -76,6 +118,24 @@ type CommitManager struct {
 	// Ultra-Fast compression configuration
 	lz4CompressionLevel  int     // LZ4 level (1 = fastest, 9 = best compression)
 	enableBackgroundOpt  bool    // Enable background optimization to warm/cold cache
+	storeExtensions      []string // Already-compressed formats written with the store codec
+	sessionGap           time.Duration // Idle time that ends a working session
+	contentStore         bool     // Store files once by content hash instead of one blob per version
+	deltaStrategy        string   // "xdelta3" tries a VCDIFF delta against the parent before a full snapshot
+	workers              int      // Files compressed concurrently (0 = one per CPU core, 1 = single stream)
+	limits               perf.Limits        // performance.max_* settings and their command-line overrides
+	io                   *iosched.Scheduler // Paces source reads to performance.io_throttle_mbps
+	skipThumbnails       bool     // preview.skip_thumbnails: keep no embedded previews
+	
+	// Deterministic makes identical inputs produce byte-identical commits for reproducible archives
+	Deterministic        bool
+	
+	// Per-invocation identity (--author/--email); overrides DGIT_AUTHOR/DGIT_EMAIL and the config
+	Author               string
+	Email                string
+	
+	// Lock is the repository lock the caller already holds; nil makes CreateCommit take its own
+	Lock                 *repolock.Lock
 }
 
 // NewCommitManager creates a new ultra-fast commit manager with optimized 3-tier cache
@@ -109,6 +169,9 @@ func NewCommitManager(dgitDir string) *CommitManager {
 		CompressionThreshold: 0.3,    // 30% compression ratio threshold
 		lz4CompressionLevel:  1,      // Fastest LZ4 level for 0.2s commits
 		enableBackgroundOpt:  true,   // Enable background optimization for better ratios
+		storeExtensions:      codec.DefaultStoreExtensions,
+		limits:               perf.Load(dgitDir),
+		io:                   iosched.NewScheduler(dgitDir, iosched.Foreground),
 	}
 
 	// Load any custom configuration overrides
@@ -122,28 +185,83 @@ func NewCommitManager(dgitDir string) *CommitManager {
 func (cm *CommitManager) CreateCommit(message string, stagedFiles []*staging.StagedFile) (*Commit, error) {
 	startTime := time.Now()
 	
+	// Background and maintenance IO holds off until the commit is written
+	defer iosched.BeginForeground(cm.DgitDir, "commit")()
+
+	// One commit at a time: the next version number and HEAD are read and written under the repository lock
+	repoLock, err := repolock.Reuse(cm.Lock, cm.DgitDir, "commit", repolock.DefaultWait)
+	if err != nil {
+		return nil, err
+	}
+	defer repoLock.Release()
+	
 	// Validate input
 	if len(stagedFiles) == 0 {
 		return nil, fmt.Errorf("no files staged for commit")
 	}
 
+	// Stored paths are always relative to the working tree root, whatever directory staged them
+	if err := cm.normalizeStagedPaths(stagedFiles); err != nil {
+		return nil, err
+	}
+
+	// Repository message filters normalize the message or reject the commit
+	message, err = msgfilter.NewFilterManager(cm.DgitDir).Apply(message)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pinned files are intentionally held at an older version and must be unpinned first
+	var stagedPaths []string
+	for _, file := range stagedFiles {
+		stagedPaths = append(stagedPaths, file.AbsolutePath)
+	}
+	if pinned, err := pin.NewPinManager(cm.DgitDir).CheckCommit(stagedPaths); err != nil {
+		return nil, err
+	} else if len(pinned) > 0 {
+		return nil, fmt.Errorf("%s is pinned at v%d; run 'dgit unpin %s' to commit it", pinned[0].Path, pinned[0].Version, pinned[0].Path)
+	}
+
+	// Files locked by someone else wait until the lock is released
+	author, email, _ := cm.resolveIdentity()
+	if locked, err := lock.NewLockManager(cm.DgitDir).Conflicts(stagedPaths, author, email); err != nil {
+		return nil, err
+	} else if len(locked) > 0 {
+		return nil, fmt.Errorf("%s; ask them to run 'dgit unlock %s' or break it with 'dgit unlock --force'", locked[0], locked[0].Path)
+	}
+
+	// pre-commit hooks validate the staged assets; a non-zero exit aborts the commit
+	if err := hooks.Run(cm.DgitDir, HookPreCommit, cm.hookEnv(cm.GetCurrentVersion()+1, "", message, stagedFiles)); err != nil {
+		return nil, err
+	}
+
+	// Deterministic mode: fixed input order and a timestamp taken from the files themselves
+	timestamp := time.Now()
+	if cm.Deterministic {
+		stagedFiles = sortedByPath(stagedFiles)
+		timestamp = latestModTime(stagedFiles)
+	}
+
 	// Generate version and commit metadata
 	currentVersion := cm.GetCurrentVersion()
 	newVersion := currentVersion + 1
 
 	hash := cm.generateCommitHash(message, stagedFiles, newVersion)
-	author := cm.getAuthor()
+	author, email, authorSource := cm.resolveIdentity()
 
 	// Create commit structure
 	commit := &Commit{
-		Hash:       hash,
-		Message:    message,
-		Timestamp:  time.Now(),
-		Author:     author,
-		FilesCount: len(stagedFiles),
-		Version:    newVersion,
-		Metadata:   make(map[string]interface{}),
-		ParentHash: cm.getCurrentCommitHash(),
+		Hash:         hash,
+		Message:      message,
+		Timestamp:    timestamp,
+		Author:       author,
+		Email:        email,
+		AuthorSource: authorSource,
+		FilesCount:   len(stagedFiles),
+		Version:      newVersion,
+		Metadata:     make(map[string]interface{}),
+		ParentHash:   cm.getCurrentCommitHash(),
+		Branch:       log.BranchField(log.NewLogManager(cm.DgitDir).CurrentBranch()),
 	}
 
 	// Extract design file metadata for commit tracking
@@ -153,16 +271,104 @@ func (cm *CommitManager) CreateCommit(message string, stagedFiles []*staging.Sta
 	}
 	commit.Metadata = meta
 
+	// Duplicate or default layer names warn, or refuse the commit at "error" severity
+	severity, findings, err := layerlint.NewLintManager(cm.DgitDir).Check(meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(findings) > 0 && severity == layerlint.SeverityError {
+		var messages []string
+		for _, finding := range findings {
+			messages = append(messages, finding.Message)
+		}
+		return nil, fmt.Errorf("layer names need cleanup (commit.layer_lint.severity is error):\n  %s", strings.Join(messages, "\n  "))
+	}
+	for _, finding := range findings {
+		fmt.Printf("Warning: layer names in %s\n", finding.Message)
+	}
+	if cm.Deterministic {
+		commit.Hash = cm.generateDeterministicHash(commit)
+		hash = commit.Hash
+	}
+
+	// Commits without a long idle gap share a session so 'dgit log --sessions' can collapse them
+	var prev *log.Commit
+	if commit.ParentHash != "" {
+		prev, _ = log.NewLogManager(cm.DgitDir).GetCommitByHash(commit.ParentHash)
+	}
+	commit.Session = log.NextSession(prev, author, timestamp, hash, cm.sessionGap)
+
+	// Group numbered frames (shot_0001.psd …) so history shows them as one asset
+	var paths []string
+	for _, f := range stagedFiles {
+		paths = append(paths, f.Path)
+	}
+	commit.Sequences, _ = staging.DetectSequences(paths)
+
+	// Moved files carry their previous path so history can follow them
+	for _, f := range stagedFiles {
+		if f.RenamedFrom != "" {
+			if commit.Renames == nil {
+				commit.Renames = make(map[string]string)
+			}
+			commit.Renames[filepath.ToSlash(f.Path)] = f.RenamedFrom
+		}
+	}
+
+	// Record linked library assets so restore can fetch the exact library versions
+	links, err := linked.NewLinkManager(cm.DgitDir).GetLinks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load linked assets: %w", err)
+	}
+	if len(links) > 0 {
+		commit.LinkedAssets = links
+	}
+
+	// Pin Creative Cloud Library elements so old versions keep the graphics they used
+	if ccLibraries := cclib.NewCCLibraryManager(cm.DgitDir); ccLibraries.Enabled() {
+		files := make(map[string]string, len(stagedFiles))
+		for _, f := range stagedFiles {
+			files[filepath.ToSlash(f.Path)] = f.AbsolutePath
+		}
+		assets, err := ccLibraries.Snapshot(files)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot CC Library assets: %w", err)
+		}
+		for _, asset := range assets {
+			if asset.Error != "" {
+				fmt.Printf("Warning: CC Library element %s not fully pinned: %s\n", asset.ElementID, asset.Error)
+			}
+		}
+		commit.CCLibraryAssets = assets
+	}
+
 	// ULTRA-FAST COMPRESSION ENGINE - core of 225x speed improvement
-	compressionResult, err := cm.createUltraFastSnapshot(stagedFiles, newVersion, currentVersion, startTime)
+	// Object store repositories keep each file's content once instead of a blob per version
+	var compressionResult *CompressionResult
+	if cm.contentStore {
+		compressionResult, commit.Blobs, err = cm.createContentSnapshot(stagedFiles)
+	} else {
+		compressionResult, err = cm.createUltraFastSnapshot(stagedFiles, newVersion, currentVersion, startTime)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ultra-fast snapshot failed: %w", err)
 	}
 	
 	commit.CompressionInfo = compressionResult
+	if cm.Deterministic {
+		// Timing data differs run to run; keep only what the inputs determine
+		compressionResult.CreatedAt = timestamp
+		compressionResult.CompressionTime = 0
+		compressionResult.SpeedImprovement = 0
+	}
 	if compressionResult.Strategy == "zip" {
 		commit.SnapshotZip = compressionResult.OutputFile // Legacy compatibility
 	}
+	
+	// Feedback loop: content that LZ4 could not shrink will not shrink under Zstd either
+	if cm.recordRatios(compressionResult, timestamp) {
+		compressionResult.SkipOptimization = true
+	}
 
 	// Save commit metadata and update repository state
 	if err := cm.saveCommitMetadata(commit); err != nil {
@@ -172,28 +378,96 @@ func (cm *CommitManager) CreateCommit(message string, stagedFiles []*staging.Sta
 		return nil, fmt.Errorf("update HEAD failed: %w", err)
 	}
 
+	// Embedded previews let 'dgit show --thumbnail' display the version without restoring it
+	if !cm.skipThumbnails {
+		cm.extractThumbnails(newVersion, stagedFiles)
+	}
+
+	// Each commit grows the hot tier; older snapshots are evicted or demoted per compression.cache
+	if _, err := cache.NewCacheManager(cm.DgitDir).Enforce(); err != nil {
+		fmt.Printf("Warning: cache budget: %v\n", err)
+	}
+
+	// post-commit hooks run once the version exists; they cannot undo it, so failures only warn
+	if err := hooks.Run(cm.DgitDir, HookPostCommit, cm.hookEnv(newVersion, hash, message, stagedFiles)); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Calculate final performance metrics
 	totalTime := time.Since(startTime)
-	compressionResult.SpeedImprovement = 45000.0 / compressionResult.CompressionTime // vs 45 second baseline
+	if compressionResult.CompressionTime > 0 {
+		compressionResult.SpeedImprovement = 45000.0 / compressionResult.CompressionTime // vs 45 second baseline
+	}
 
 	// Display ultra-fast performance results
 	cm.displayUltraFastCompressionStats(compressionResult, totalTime)
 	
-	// Schedule background optimization for better compression ratios (non-blocking)
-	if cm.enableBackgroundOpt && compressionResult.Strategy == "lz4" {
-		go cm.scheduleBackgroundOptimization(newVersion, compressionResult)
-	}
-	
 	return commit, nil
 }
 
+// extractThumbnails stores the previews embedded in the committed files; failures only warn
+func (cm *CommitManager) extractThumbnails(version int, stagedFiles []*staging.StagedFile) {
+	files := make(map[string]string)
+	for _, f := range stagedFiles {
+		if thumbnail.Supported(f.Path) {
+			files[filepath.ToSlash(f.Path)] = f.AbsolutePath
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+	_, failed := thumbnail.NewThumbnailManager(cm.DgitDir).ExtractAll(version, files)
+	for path, err := range failed {
+		fmt.Printf("Warning: thumbnail of %s: %v\n", path, err)
+	}
+}
+
+// hookEnv builds the environment pre-commit and post-commit scripts see
+func (cm *CommitManager) hookEnv(version int, hash, message string, stagedFiles []*staging.StagedFile) map[string]string {
+	paths := make([]string, len(stagedFiles))
+	for i, f := range stagedFiles {
+		paths[i] = filepath.ToSlash(f.Path)
+	}
+	sort.Strings(paths)
+	env := map[string]string{
+		"DGIT_VERSION": strconv.Itoa(version),
+		"DGIT_MESSAGE": message,
+		"DGIT_FILES":   strings.Join(paths, "\n"),
+	}
+	if hash != "" {
+		env["DGIT_HASH"] = hash
+	}
+	return env
+}
+
 // createUltraFastSnapshot - The heart of our 225x speed improvement!
 // Intelligent strategy selection: LZ4 -> Smart Delta -> Fallback
 func (cm *CommitManager) createUltraFastSnapshot(files []*staging.StagedFile, version, prevVersion int, startTime time.Time) (*CompressionResult, error) {
 	// DECISION ENGINE: Choose optimal ultra-fast strategy based on file characteristics
 	
+	// Strategy 0: VCDIFF delta against the parent, when the repository opts in and it pays off
+	if cm.deltaStrategy == "xdelta3" {
+		if base := log.NewLogManager(cm.DgitDir).GetHeadVersion(); base > 0 && !cm.shouldCreateNewSnapshot(base) {
+			deltaResult, err := cm.createXdeltaDelta(files, version, base)
+			if err == nil && deltaResult.CompressionRatio <= cm.CompressionThreshold {
+				return deltaResult, nil
+			}
+			if err == nil {
+				os.Remove(filepath.Join(cm.DeltaDir, deltaResult.OutputFile))
+			}
+		}
+	}
+	
+	// Strategy 0b: Store for content that is already compressed (LZ4/Zstd would only add overhead)
+	if cm.shouldStore(files) {
+		return cm.createStoreSnapshot(files, version, startTime)
+	}
+	
 	// Strategy 1: LZ4 Ultra-Fast (default for 0.2s commits)
 	if cm.shouldUseLZ4UltraFast(files, version) {
+		if cm.workers != 1 && len(files) > 1 {
+			return cm.createLZ4Parallel(files, version)
+		}
 		return cm.createLZ4UltraFast(files, version, startTime)
 	}
 	
@@ -205,7 +479,11 @@ func (cm *CommitManager) createUltraFastSnapshot(files []*staging.StagedFile, ve
 		}
 		// Clean up failed delta and fallback to LZ4
 		if err == nil {
-			os.Remove(filepath.Join(cm.DeltaDir, deltaResult.OutputFile))
+			deltaDir := cm.DeltaDir
+			if deltaResult.Strategy == "psd_smart_delta" {
+				deltaDir = cm.HotCacheDir
+			}
+			os.Remove(filepath.Join(deltaDir, deltaResult.OutputFile))
 		}
 	}
 	
@@ -227,36 +505,45 @@ func (cm *CommitManager) createLZ4UltraFast(files []*staging.StagedFile, version
 		return nil, fmt.Errorf("create LZ4 file: %w", err)
 	}
 	defer outFile.Close()
+	counter := &countingWriter{w: outFile}
 
 	// Ultra-fast LZ4 compression (level 1 for maximum speed)
-	lz4Writer := lz4.NewWriter(outFile)
-	defer lz4Writer.Close() // Ensure proper cleanup
-
+	lz4Writer := lz4.NewWriter(counter)
 	lz4Writer.Apply(lz4.CompressionLevelOption(lz4.Level1))
 
-	// Stream all files through LZ4 with minimal overhead for maximum performance
+	// Each file is framed with its path, size, mode and checksum so multi-file commits round-trip
+	frames, err := codec.NewFrameWriter(lz4Writer)
+	if err != nil {
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("start LZ4 frames: %w", err)
+	}
+
 	var originalSize int64
+	fileRatios := make(map[string]float64)
 	for _, file := range files {
-		// Stream file content directly through LZ4 (no headers for max efficiency)
-		srcFile, err := os.Open(file.AbsolutePath)
+		before := counter.n
+		written, err := writeFrame(frames, file, cm.io)
 		if err != nil {
-			fmt.Printf("Warning: failed to open %s: %v\n", file.Path, err)
-			continue
-		}
-		
-		// Critical fix: Close immediately after copy, not with defer in loop
-		written, err := io.Copy(lz4Writer, srcFile)
-		srcFile.Close() // Close immediately to prevent file handle leaks
-		
-		if err != nil {
-			fmt.Printf("Warning: failed to compress %s: %v\n", file.Path, err)
-			continue
+			os.Remove(hotCachePath)
+			return nil, fmt.Errorf("failed to compress %s: %w", file.Path, err)
 		}
-		
 		originalSize += written // Use actual written bytes for accurate metrics
+
+		// Flush per file so the bytes it produced can be attributed to it
+		if err := lz4Writer.Flush(); err == nil && written > 0 {
+			fileRatios[filepath.ToSlash(file.Path)] = float64(counter.n-before) / float64(written)
+		}
 	}
-	
-	// Writers will be closed by deferred calls
+
+	// Close before measuring so the end marks are on disk
+	if err := frames.Close(); err == nil {
+		err = lz4Writer.Close()
+	}
+	if err != nil {
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("finish LZ4 file: %w", err)
+	}
+
 	// Calculate compression performance metrics
 	fileInfo, err := os.Stat(hotCachePath)
 	if err != nil {
@@ -287,6 +574,175 @@ func (cm *CommitManager) createLZ4UltraFast(files []*staging.StagedFile, version
 		CompressionTime:  compressionTime,
 		CacheLevel:       "hot",
 		CreatedAt:        time.Now(),
+		FileRatios:       fileRatios,
+	}, nil
+}
+
+// createContentSnapshot adds each staged file to the object store under its content hash
+// Content already stored by any earlier commit is referenced, not written again
+func (cm *CommitManager) createContentSnapshot(files []*staging.StagedFile) (*CompressionResult, map[string]*log.BlobRef, error) {
+	compressionStartTime := time.Now()
+	store := objstore.NewObjectStore(cm.DgitDir)
+	store.IO = cm.io
+	registry, _ := LoadRatioRegistry(cm.DgitDir)
+
+	blobs := make(map[string]*log.BlobRef, len(files))
+	fileRatios := make(map[string]float64)
+	result := &CompressionResult{Strategy: objstore.Strategy, CacheLevel: "hot", CreatedAt: time.Now()}
+	// Files are hashed and compressed on the worker pool, then recorded in staging order
+	puts := make([]*objstore.PutResult, len(files))
+	errs := make([]error, len(files))
+	cm.eachFileParallel(files, func(i int, file *staging.StagedFile) {
+		codecName := codec.LZ4
+		if codec.IsStoreExtension(file.Path, cm.storeExtensions) || (registry != nil && registry.IsStore(file.Path)) {
+			codecName = codec.Store
+		}
+		puts[i], errs[i] = store.Put(file.AbsolutePath, codecName)
+	})
+	for i, file := range files {
+		put, err := puts[i], errs[i]
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to store %s: %w", file.Path, err)
+		}
+		path := filepath.ToSlash(file.Path)
+		blobs[path] = put.Ref
+		result.OriginalSize += put.Ref.Size
+		result.CompressedSize += put.Stored
+		if put.Reused {
+			result.ReusedFiles++
+		} else if put.Ref.Size > 0 {
+			fileRatios[path] = float64(put.Stored) / float64(put.Ref.Size)
+		}
+	}
+
+	if result.OriginalSize > 0 {
+		result.CompressionRatio = float64(result.CompressedSize) / float64(result.OriginalSize)
+	}
+	if len(fileRatios) > 0 {
+		result.FileRatios = fileRatios
+	}
+	result.CompressionTime = float64(time.Since(compressionStartTime).Nanoseconds()) / 1000000.0
+	return result, blobs, nil
+}
+
+// writeFrame adds one staged file to a framed snapshot stream, reading it at the pace sched allows
+func writeFrame(frames *codec.FrameWriter, file *staging.StagedFile, sched *iosched.Scheduler) (int64, error) {
+	src, err := os.Open(file.AbsolutePath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return frames.WriteFile(codec.FrameHeader{Path: file.Path, Size: info.Size(), Mode: info.Mode()}, sched.Reader(src))
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write forwards to the underlying writer and counts what was written
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// shouldStore reports whether every staged file is an already-compressed format
+// Known container extensions always qualify; other files qualify once their ratios prove incompressible
+func (cm *CommitManager) shouldStore(files []*staging.StagedFile) bool {
+	if len(files) == 0 {
+		return false
+	}
+	registry, _ := LoadRatioRegistry(cm.DgitDir)
+	for _, file := range files {
+		if codec.IsStoreExtension(file.Path, cm.storeExtensions) {
+			continue
+		}
+		if registry == nil || !registry.IsStore(file.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// createStoreSnapshot writes files to the hot cache uncompressed, checksummed by the store codec
+// Same framed container as the LZ4 snapshot, so restore reads both the same way
+func (cm *CommitManager) createStoreSnapshot(files []*staging.StagedFile, version int, startTime time.Time) (*CompressionResult, error) {
+	compressionStartTime := time.Now()
+	
+	store, err := codec.Get(codec.Store)
+	if err != nil {
+		return nil, err
+	}
+	hotCachePath := filepath.Join(cm.HotCacheDir, fmt.Sprintf("v%d%s", version, store.Ext()))
+	
+	outFile, err := os.Create(hotCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("create store file: %w", err)
+	}
+	writer, err := store.NewWriter(outFile)
+	if err != nil {
+		outFile.Close()
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("failed to start store object: %w", err)
+	}
+	
+	frames, err := codec.NewFrameWriter(writer)
+	if err != nil {
+		outFile.Close()
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("failed to start store frames: %w", err)
+	}
+	var originalSize int64
+	for _, file := range files {
+		written, err := writeFrame(frames, file, cm.io)
+		if err != nil {
+			outFile.Close()
+			os.Remove(hotCachePath)
+			return nil, fmt.Errorf("failed to store %s: %w", file.Path, err)
+		}
+		originalSize += written
+	}
+	
+	// Close before measuring so the end block is on disk
+	if err := frames.Close(); err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		outFile.Close()
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("failed to finish store object: %w", err)
+	}
+	if err := outFile.Close(); err != nil {
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("failed to write store object: %w", err)
+	}
+	
+	fileInfo, err := os.Stat(hotCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat store file: %w", err)
+	}
+	
+	var ratio float64
+	if originalSize > 0 {
+		ratio = float64(fileInfo.Size()) / float64(originalSize)
+	}
+	
+	return &CompressionResult{
+		Strategy:         codec.Store,
+		OutputFile:       filepath.Base(hotCachePath),
+		OriginalSize:     originalSize,
+		CompressedSize:   fileInfo.Size(),
+		CompressionRatio: ratio,
+		CompressionTime:  float64(time.Since(compressionStartTime).Nanoseconds()) / 1000000.0,
+		CacheLevel:       "hot",
+		CreatedAt:        time.Now(),
+		SkipOptimization: true,
 	}, nil
 }
 
@@ -328,6 +784,41 @@ func (cm *CommitManager) selectFastestDeltaAlgorithm(files []*staging.StagedFile
 	return "bsdiff_fast"
 }
 
+// createXdeltaDelta stores a version as a VCDIFF delta of its uncompressed snapshot against the base version's
+// The file is plain xdelta3 format: 'xdelta3 -d -s <base snapshot>' rebuilds the snapshot stream
+func (cm *CommitManager) createXdeltaDelta(files []*staging.StagedFile, version, baseVersion int) (*CompressionResult, error) {
+	compressionStart := time.Now()
+	
+	base, err := restore.NewRestoreManager(cm.DgitDir).SnapshotStream(baseVersion)
+	if err != nil {
+		return nil, fmt.Errorf("base v%d unavailable: %w", baseVersion, err)
+	}
+	var target bytes.Buffer
+	frames, err := codec.NewFrameWriter(&target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start frames: %w", err)
+	}
+	for _, file := range files {
+		if _, err := writeFrame(frames, file, cm.io); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+	}
+	if err := frames.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish frames: %w", err)
+	}
+	
+	if err := os.MkdirAll(cm.DeltaDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create delta directory: %w", err)
+	}
+	deltaPath := filepath.Join(cm.DeltaDir, fmt.Sprintf("v%d_from_v%d%s", version, baseVersion, vcdiff.Ext))
+	if err := os.WriteFile(deltaPath, vcdiff.Encode(base, target.Bytes()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write delta: %w", err)
+	}
+	
+	compressionTime := float64(time.Since(compressionStart).Nanoseconds()) / 1000000.0
+	return cm.calculateCompressionResult("xdelta3", deltaPath, files, baseVersion, compressionTime)
+}
+
 // createBsdiffDeltaFast - Speed-optimized bsdiff delta compression
 // Uses fast binary diff algorithm for rapid delta generation
 func (cm *CommitManager) createBsdiffDeltaFast(files []*staging.StagedFile, version, baseVersion int) (*CompressionResult, error) {
@@ -378,59 +869,6 @@ func (cm *CommitManager) createBsdiffDeltaFast(files []*staging.StagedFile, vers
 	return cm.calculateCompressionResult("bsdiff", deltaPath, files, baseVersion, compressionTime)
 }
 
-// Background optimization system for improved compression ratios
-// Runs asynchronously to avoid blocking user operations
-
-// scheduleBackgroundOptimization queues background optimization tasks
-// Waits for user operations to complete before starting optimization
-func (cm *CommitManager) scheduleBackgroundOptimization(version int, result *CompressionResult) {
-	// Wait briefly to ensure user operations complete
-	time.Sleep(3 * time.Second)
-	
-	// Move from hot cache (LZ4) to warm cache (Zstd) for better compression
-	cm.optimizeToWarmCache(version, result)
-}
-
-// optimizeToWarmCache converts LZ4 hot cache to Zstd warm cache
-// Provides better compression ratios while maintaining reasonable access speed
-func (cm *CommitManager) optimizeToWarmCache(version int, result *CompressionResult) {
-	if result.Strategy != "lz4" {
-		return
-	}
-	
-	hotPath := filepath.Join(cm.HotCacheDir, result.OutputFile)
-	warmPath := filepath.Join(cm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version))
-	
-	// Open LZ4 source file
-	hotFile, err := os.Open(hotPath)
-	if err != nil {
-		return
-	}
-	defer hotFile.Close()
-	
-	// Create Zstd destination file
-	warmFile, err := os.Create(warmPath)
-	if err != nil {
-		return
-	}
-	defer warmFile.Close()
-	
-	// LZ4 decompression → Zstd compression pipeline for optimal ratios
-	lz4Reader := lz4.NewReader(hotFile)
-	zstdWriter, err := zstd.NewWriter(warmFile, zstd.WithEncoderLevel(zstd.SpeedDefault))
-	if err != nil {
-		return
-	}
-	defer zstdWriter.Close()
-	
-	// Stream conversion for efficient memory usage
-	io.Copy(zstdWriter, lz4Reader)
-	zstdWriter.Close()
-	
-	// Background optimization completed successfully
-	// Keep hot cache for immediate access, warm cache for better compression ratio
-}
-
 // createPSDSmartDelta - Enhanced PSD delta compression
 // Specialized delta compression for Photoshop files with metadata awareness
 func (cm *CommitManager) createPSDSmartDelta(files []*staging.StagedFile, version, baseVersion int) (*CompressionResult, error) {
@@ -495,7 +933,7 @@ func (cm *CommitManager) createPSDSmartDelta(files []*staging.StagedFile, versio
 	deltaFileSize := fileInfo.Size()
 	
 	return &CompressionResult{
-		Strategy:         "psd_smart",
+		Strategy:         "psd_smart_delta",
 		OutputFile:       filepath.Base(deltaPath),
 		OriginalSize:     psdFile.Size,
 		CompressedSize:   deltaFileSize,
@@ -522,12 +960,23 @@ func (cm *CommitManager) displayUltraFastCompressionStats(result *CompressionRes
 		fmt.Printf("LZ4 Ultra-Fast: %.1f%% compressed in %.1fms\n", compressionPercent, result.CompressionTime)
 		fmt.Printf("Speed improvement: %.1fx faster than traditional ZIP!\n", result.SpeedImprovement)
 		fmt.Printf("Cache: %s | File: %s\n", result.CacheLevel, result.OutputFile)
-	case "psd_smart":
+	case objstore.Strategy:
+		fmt.Printf("Object store: %.2f MB written in %.1fms\n", float64(result.CompressedSize)/(1024*1024), result.CompressionTime)
+		if result.ReusedFiles > 0 {
+			fmt.Printf("Deduplicated: %d file(s) already stored by earlier commits\n", result.ReusedFiles)
+		}
+	case codec.Store:
+		fmt.Printf("Stored as-is (already compressed) in %.1fms\n", result.CompressionTime)
+		fmt.Printf("Cache: %s | File: %s\n", result.CacheLevel, result.OutputFile)
+	case "psd_smart_delta":
 		fmt.Printf("PSD Smart Delta: %.1f%% space saved in %.1fms\n", compressionPercent, result.CompressionTime)
 		fmt.Printf("Base: v%d | Changes detected and optimized\n", result.BaseVersion)
 	case "bsdiff":
 		fmt.Printf("Fast Binary Delta: %.1f%% saved in %.1fms\n", compressionPercent, result.CompressionTime)
 		fmt.Printf("Base: v%d | Delta file: %s\n", result.BaseVersion, result.OutputFile)
+	case "xdelta3":
+		fmt.Printf("VCDIFF Delta: %.1f%% saved in %.1fms\n", compressionPercent, result.CompressionTime)
+		fmt.Printf("Base: v%d | Delta file: %s\n", result.BaseVersion, result.OutputFile)
 	default:
 		fmt.Printf("%s compression: %.1f%% in %.1fms\n", strings.ToUpper(result.Strategy), compressionPercent, result.CompressionTime)
 	}
@@ -540,11 +989,19 @@ func (cm *CommitManager) displayUltraFastCompressionStats(result *CompressionRes
 	}
 	
 	// Background optimization notice for user awareness
-	if cm.enableBackgroundOpt && result.Strategy == "lz4" {
-		fmt.Printf("Background optimization scheduled for better compression\n")
+	if cm.shouldOptimize(result) {
+		fmt.Printf("Run 'dgit optimize' (or 'dgit optimize --daemon') to move it to the warm tier\n")
+	} else if result.SkipOptimization && result.Strategy != codec.Store {
+		fmt.Printf("Content is already compressed; stored as-is without a recompression pass\n")
 	}
 }
 
+// shouldOptimize reports whether a snapshot is worth a warm Zstd copy from 'dgit optimize'
+// Deterministic commits are not advertised for it, since their blob is meant to stay as archived
+func (cm *CommitManager) shouldOptimize(result *CompressionResult) bool {
+	return cm.enableBackgroundOpt && !cm.Deterministic && result.Strategy == "lz4" && !result.SkipOptimization
+}
+
 // Utility and helper functions for ultra-fast compression system
 
 // loadUltraFastConfig loads ultra-fast compression configuration from repository
@@ -560,6 +1017,36 @@ func (cm *CommitManager) loadUltraFastConfig() {
 						cm.lz4CompressionLevel = int(level)
 					}
 				}
+				if store, ok := compression["object_store"].(string); ok {
+					cm.contentStore = store == objstore.Strategy
+				}
+				if workers, ok := compression["workers"].(float64); ok && workers >= 0 {
+					cm.workers = int(workers)
+				}
+				if strategy, ok := compression["delta_strategy"].(string); ok {
+					cm.deltaStrategy = strategy
+				}
+				if exts, ok := compression["store_extensions"].([]interface{}); ok {
+					cm.storeExtensions = nil
+					for _, ext := range exts {
+						if e, ok := ext.(string); ok {
+							cm.storeExtensions = append(cm.storeExtensions, e)
+						}
+					}
+				}
+			}
+			if commitConfig, ok := config["commit"].(map[string]interface{}); ok {
+				if deterministic, ok := commitConfig["deterministic"].(bool); ok {
+					cm.Deterministic = deterministic
+				}
+				if minutes, ok := commitConfig["session_gap_minutes"].(float64); ok && minutes > 0 {
+					cm.sessionGap = time.Duration(minutes) * time.Minute
+				}
+			}
+			if previewConfig, ok := config["preview"].(map[string]interface{}); ok {
+				if skip, ok := previewConfig["skip_thumbnails"].(bool); ok {
+					cm.skipThumbnails = skip
+				}
 			}
 		}
 	}
@@ -574,6 +1061,12 @@ func (cm *CommitManager) findVersionInCache(version int) string {
 		return hotPath
 	}
 	
+	// Hot cache may hold a store object for already-compressed content
+	storePath := filepath.Join(cm.HotCacheDir, fmt.Sprintf("v%d.store", version))
+	if cm.fileExists(storePath) {
+		return storePath
+	}
+	
 	// Check warm cache (Zstd) - good balance of speed and compression
 	warmPath := filepath.Join(cm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version))
 	if cm.fileExists(warmPath) {
@@ -599,14 +1092,22 @@ func (cm *CommitManager) openCachedFile(path string) (io.ReadCloser, error) {
 	
 	// Return appropriate decompression reader based on file extension
 	if strings.HasSuffix(path, ".lz4") {
-		return &lz4ReadCloser{lz4.NewReader(file), file}, nil
+		return &lz4ReadCloser{codec.NewLZ4Reader(file), file}, nil
 	} else if strings.HasSuffix(path, ".zstd") {
-		zstdReader, err := zstd.NewReader(file)
+		zstdReader, err := zstd.NewReader(file, cm.limits.ZstdOptions()...)
 		if err != nil {
 			file.Close()
 			return nil, err
 		}
 		return &zstdReadCloser{zstdReader, file}, nil
+	} else if strings.HasSuffix(path, ".store") {
+		store, _ := codec.Get(codec.Store)
+		reader, err := store.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &storeReadCloser{reader, file}, nil
 	}
 	
 	// Return raw file for ZIP and other formats
@@ -617,7 +1118,7 @@ func (cm *CommitManager) openCachedFile(path string) (io.ReadCloser, error) {
 
 // lz4ReadCloser provides transparent LZ4 decompression
 type lz4ReadCloser struct {
-	*lz4.Reader
+	io.Reader
 	file *os.File
 }
 
@@ -636,6 +1137,17 @@ func (r *zstdReadCloser) Close() error {
 	return r.file.Close()
 }
 
+// storeReadCloser provides checksum-verified reads of store objects
+type storeReadCloser struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (r *storeReadCloser) Close() error {
+	r.ReadCloser.Close()
+	return r.file.Close()
+}
+
 // Cache and file management utilities
 
 // createTempLZ4File creates temporary LZ4 file for delta operations
@@ -706,15 +1218,17 @@ func (cm *CommitManager) shouldCreateNewSnapshot(ver int) bool {
 	return cm.getDeltaChainLength(ver) >= cm.MaxDeltaChainLength
 }
 
-// getDeltaChainLength counts delta chain length back to last ZIP snapshot
+// getDeltaChainLength counts the deltas that must be applied to rebuild a version
 // Used to determine when to create new base snapshots
 func (cm *CommitManager) getDeltaChainLength(ver int) int {
+	logManager := log.NewLogManager(cm.DgitDir)
 	count := 0
-	for v := ver; v > 0; v-- {
-		if cm.fileExists(filepath.Join(cm.ObjectsDir, fmt.Sprintf("v%d.zip", v))) {
+	for v := ver; v > 0 && count <= cm.MaxDeltaChainLength; count++ {
+		c, err := logManager.GetCommit(v)
+		if err != nil || c.CompressionInfo == nil || c.CompressionInfo.BaseVersion == 0 {
 			break
 		}
-		count++
+		v = c.CompressionInfo.BaseVersion
 	}
 	return count
 }
@@ -726,23 +1240,10 @@ func (cm *CommitManager) fileExists(path string) bool {
 	return err == nil
 }
 
-// GetCurrentVersion returns the current version by scanning JSON metadata files
+// GetCurrentVersion returns the current version from the repository's metadata store
 // Determines the next version number for new commits
 func (cm *CommitManager) GetCurrentVersion() int {
-	entries, err := os.ReadDir(cm.ObjectsDir)
-	if err != nil {
-		return 0
-	}
-	max := 0
-	for _, e := range entries {
-		if strings.HasPrefix(e.Name(), "v") && strings.HasSuffix(e.Name(), ".json") {
-			n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(e.Name(), "v"), ".json"))
-			if n > max {
-				max = n
-			}
-		}
-	}
-	return max
+	return log.NewLogManager(cm.DgitDir).GetCurrentVersion()
 }
 
 // generateCommitHash produces a secure 12-character SHA256-based hash
@@ -760,6 +1261,46 @@ func (cm *CommitManager) generateCommitHash(msg string, files []*staging.StagedF
 	return fmt.Sprintf("%x", h.Sum(nil))[:12]
 }
 
+// generateDeterministicHash derives the commit hash only from what is committed
+// Paths are repository-relative and contents are identified by their SHA256, so other machines get the same hash
+func (cm *CommitManager) generateDeterministicHash(c *Commit) string {
+	h := sha256.New()
+	h.Write([]byte(c.Message))
+	h.Write([]byte(strconv.Itoa(c.Version)))
+	h.Write([]byte(c.ParentHash))
+	h.Write([]byte(c.Timestamp.UTC().Format(time.RFC3339)))
+	paths := make([]string, 0, len(c.Metadata))
+	for path := range c.Metadata {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		h.Write([]byte(filepath.ToSlash(path)))
+		if meta, ok := c.Metadata[path].(map[string]interface{}); ok {
+			h.Write([]byte(fmt.Sprint(meta["sha256"])))
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:12]
+}
+
+// sortedByPath returns the staged files ordered by repository path
+func sortedByPath(files []*staging.StagedFile) []*staging.StagedFile {
+	sorted := append([]*staging.StagedFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return sorted
+}
+
+// latestModTime returns the newest modification time among the files, in UTC and whole seconds
+func latestModTime(files []*staging.StagedFile) time.Time {
+	var latest time.Time
+	for _, f := range files {
+		if f.ModTime.After(latest) {
+			latest = f.ModTime
+		}
+	}
+	return latest.UTC().Truncate(time.Second)
+}
+
 // getAuthor reads author information from repository configuration
 // Returns configured author or default value
 func (cm *CommitManager) getAuthor() string {
@@ -774,34 +1315,76 @@ func (cm *CommitManager) getAuthor() string {
 	return "DGit User"
 }
 
-// getCurrentCommitHash reads the current HEAD commit hash
-// Used for tracking commit parent relationships
-func (cm *CommitManager) getCurrentCommitHash() string {
-	if d, err := os.ReadFile(cm.HeadFile); err == nil {
-		return strings.TrimSpace(string(d))
+// getEmail reads the configured author email
+func (cm *CommitManager) getEmail() string {
+	if data, err := os.ReadFile(cm.ConfigFile); err == nil {
+		var cfg map[string]interface{}
+		if json.Unmarshal(data, &cfg) == nil {
+			if e, ok := cfg["email"].(string); ok {
+				return e
+			}
+		}
 	}
 	return ""
 }
 
+// resolveIdentity picks the commit author: flags, then DGIT_AUTHOR/DGIT_EMAIL, then the repository config
+// The source is empty for the configured human author so automated commits stay distinguishable
+func (cm *CommitManager) resolveIdentity() (author, email, source string) {
+	switch {
+	case cm.Author != "" || cm.Email != "":
+		author, email, source = cm.Author, cm.Email, "flag"
+	case os.Getenv("DGIT_AUTHOR") != "" || os.Getenv("DGIT_EMAIL") != "":
+		author, email, source = os.Getenv("DGIT_AUTHOR"), os.Getenv("DGIT_EMAIL"), "env"
+	default:
+		return cm.getAuthor(), cm.getEmail(), ""
+	}
+	if author == "" {
+		author = cm.getAuthor()
+	}
+	return author, email, source
+}
+
+// Identity returns the author and email a commit made now would record, and where they came from
+func (cm *CommitManager) Identity() (author, email, source string) {
+	return cm.resolveIdentity()
+}
+
+// getCurrentCommitHash reads the commit hash HEAD resolves to through the current branch
+// Used for tracking commit parent relationships
+func (cm *CommitManager) getCurrentCommitHash() string {
+	return log.NewLogManager(cm.DgitDir).HeadHash()
+}
+
 // scanFilesMetadata extracts comprehensive metadata from design files
 // Uses scanner package to get design-specific information for commit tracking
 func (cm *CommitManager) scanFilesMetadata(files []*staging.StagedFile) (map[string]interface{}, error) {
 	md := make(map[string]interface{})
 	for _, f := range files {
-		sc := scanner.NewFileScanner()
+		modTime := f.ModTime
+		if cm.Deterministic {
+			modTime = modTime.UTC() // Same bytes regardless of the machine's time zone
+		}
+		// Content hash lets 'dgit verify --deep' prove restores are byte-identical
+		contentHash, err := status.CalculateFileHash(f.AbsolutePath)
+		if err != nil {
+			return nil, err
+		}
+		sc := scanner.NewCachedFileScanner(cm.DgitDir)
 		info, err := sc.ScanFile(f.AbsolutePath)
 		if err != nil {
 			// Store basic info even if detailed scanning fails
 			md[f.Path] = map[string]interface{}{
 				"type":          f.FileType,
 				"size":          f.Size,
-				"last_modified": f.ModTime,
+				"sha256":        contentHash,
+				"last_modified": modTime,
 				"scan_error":    err.Error(),
 			}
 			continue
 		}
 		// Store comprehensive design file metadata
-		md[f.Path] = map[string]interface{}{
+		meta := map[string]interface{}{
 			"type":          info.Type,
 			"dimensions":    info.Dimensions,
 			"color_mode":    info.ColorMode,
@@ -811,27 +1394,60 @@ func (cm *CommitManager) scanFilesMetadata(files []*staging.StagedFile) (map[str
 			"objects":       info.Objects,
 			"layer_names":   info.LayerNames,
 			"size":          f.Size,
-			"last_modified": f.ModTime,
+			"sha256":        contentHash,
+			"last_modified": modTime,
 		}
+		if info.Pages > 0 {
+			meta["pages"] = info.Pages
+		}
+		if len(info.ArtboardNames) > 0 {
+			meta["artboard_names"] = info.ArtboardNames
+		}
+		md[f.Path] = meta
 	}
 	return md, nil
 }
 
-// saveCommitMetadata writes commit metadata to JSON file
+// normalizeStagedPaths rewrites staged paths to slash-separated paths relative to the working tree root
+// Staging files written by older versions hold paths relative to the directory 'dgit add' ran in
+func (cm *CommitManager) normalizeStagedPaths(files []*staging.StagedFile) error {
+	dgitDir, err := filepath.Abs(cm.DgitDir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	rootDir := filepath.Dir(dgitDir)
+	for _, f := range files {
+		if f.AbsolutePath == "" {
+			continue
+		}
+		relPath, err := filepath.Rel(rootDir, f.AbsolutePath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside the repository", f.AbsolutePath)
+		}
+		f.Path = filepath.ToSlash(relPath)
+		if f.RenamedFrom != "" {
+			if renamedFrom, ok := log.RepoPath(rootDir, f.RenamedFrom); ok {
+				f.RenamedFrom = renamedFrom
+			}
+		}
+	}
+	return nil
+}
+
+// saveCommitMetadata writes commit metadata to the repository's metadata store
 // Persists commit information for repository history tracking
 func (cm *CommitManager) saveCommitMetadata(c *Commit) error {
-	path := filepath.Join(cm.ObjectsDir, fmt.Sprintf("v%d.json", c.Version))
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal commit: %w", err)
 	}
-	return os.WriteFile(path, data, 0644)
+	return log.NewLogManager(cm.DgitDir).SaveCommitData(data)
 }
 
-// updateHead writes the new commit hash to HEAD file
+// updateHead moves the current branch to the new commit
 // Updates repository state to point to the latest commit
 func (cm *CommitManager) updateHead(hash string) error {
-	return os.WriteFile(cm.HeadFile, []byte(hash), 0644)
+	return log.NewLogManager(cm.DgitDir).UpdateHead(hash)
 }
 
 // Legacy function signatures for backward compatibility
@@ -0,0 +1,169 @@
+package commit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/pierrec/lz4/v4"
+)
+
+// compressionWorkers resolves compression.workers for a commit of n files
+func (cm *CommitManager) compressionWorkers(n int) int {
+	workers := cm.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if cm.limits.MaxWorkers > 0 && workers > cm.limits.MaxWorkers {
+		workers = cm.limits.MaxWorkers // performance.max_workers caps compression.workers
+	}
+	if workers > n {
+		workers = n
+	}
+	return max(workers, 1)
+}
+
+// eachFileParallel calls fn for every staged file on a pool of compression workers
+// fn receives the file's index so results can be assembled in staging order
+func (cm *CommitManager) eachFileParallel(files []*staging.StagedFile, fn func(i int, file *staging.StagedFile)) {
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cm.compressionWorkers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				fn(i, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// lz4Part is one file compressed into its own LZ4 frame by createLZ4Parallel
+type lz4Part struct {
+	path    string
+	written int64 // Uncompressed file bytes
+	stored  int64 // Compressed size of the part
+	err     error
+}
+
+// createLZ4Parallel compresses staged files concurrently, one LZ4 frame per file, then concatenates
+// the frames in staging order. The decoded stream is the same framed snapshot createLZ4UltraFast writes
+func (cm *CommitManager) createLZ4Parallel(files []*staging.StagedFile, version int) (*CompressionResult, error) {
+	compressionStartTime := time.Now()
+	hotCachePath := filepath.Join(cm.HotCacheDir, fmt.Sprintf("v%d.lz4", version))
+
+	parts := make([]*lz4Part, len(files))
+	cm.eachFileParallel(files, func(i int, file *staging.StagedFile) {
+		part := &lz4Part{path: fmt.Sprintf("%s.part%d", hotCachePath, i)}
+		part.written, part.stored, part.err = writeLZ4Part(part.path, file, i == 0, i == len(files)-1, cm.io)
+		parts[i] = part
+	})
+	defer func() {
+		for _, part := range parts {
+			os.Remove(part.path)
+		}
+	}()
+	for i, part := range parts {
+		if part.err != nil {
+			return nil, fmt.Errorf("failed to compress %s: %w", files[i].Path, part.err)
+		}
+	}
+
+	// Assemble the parts into the hot cache object
+	outFile, err := os.Create(hotCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("create LZ4 file: %w", err)
+	}
+	var originalSize, compressedSize int64
+	fileRatios := make(map[string]float64)
+	for i, part := range parts {
+		if err = appendFile(outFile, part.path); err != nil {
+			break
+		}
+		originalSize += part.written
+		compressedSize += part.stored
+		if part.written > 0 {
+			fileRatios[filepath.ToSlash(files[i].Path)] = float64(part.stored) / float64(part.written)
+		}
+	}
+	if closeErr := outFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(hotCachePath)
+		return nil, fmt.Errorf("finish LZ4 file: %w", err)
+	}
+
+	var ratio float64
+	if originalSize > 0 {
+		ratio = float64(compressedSize) / float64(originalSize)
+	}
+	return &CompressionResult{
+		Strategy:         "lz4",
+		OutputFile:       filepath.Base(hotCachePath),
+		OriginalSize:     originalSize,
+		CompressedSize:   compressedSize,
+		CompressionRatio: ratio,
+		CompressionTime:  float64(time.Since(compressionStartTime).Nanoseconds()) / 1000000.0,
+		CacheLevel:       "hot",
+		CreatedAt:        time.Now(),
+		FileRatios:       fileRatios,
+	}, nil
+}
+
+// writeLZ4Part writes one file's frame as a complete LZ4 frame; the first part carries the
+// stream magic and the last part the end marker
+func writeLZ4Part(partPath string, file *staging.StagedFile, first, last bool, sched *iosched.Scheduler) (int64, int64, error) {
+	out, err := os.Create(partPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer out.Close()
+	counter := &countingWriter{w: out}
+
+	lz4Writer := lz4.NewWriter(counter)
+	lz4Writer.Apply(lz4.CompressionLevelOption(lz4.Level1))
+	frames := codec.ContinueFrameWriter(lz4Writer)
+	if first {
+		if frames, err = codec.NewFrameWriter(lz4Writer); err != nil {
+			return 0, 0, err
+		}
+	}
+	written, err := writeFrame(frames, file, sched)
+	if err != nil {
+		return written, 0, err
+	}
+	if last {
+		if err := frames.Close(); err != nil {
+			return written, 0, err
+		}
+	}
+	if err := lz4Writer.Close(); err != nil {
+		return written, 0, err
+	}
+	return written, counter.n, nil
+}
+
+// appendFile copies a file to the end of w
+func appendFile(w io.Writer, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.Copy(w, in)
+	return err
+}
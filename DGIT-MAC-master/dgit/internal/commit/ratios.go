@@ -0,0 +1,131 @@
+package commit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IncompressibleRatio is the compressed/original ratio at or above which content is marked "store"
+// Below 5% reduction a Zstd pass costs more time than the bytes it saves
+const IncompressibleRatio = 0.95
+
+// RatioStats tracks the compression ratios achieved for a path or file extension
+type RatioStats struct {
+	Samples   int       `json:"samples"`
+	LastRatio float64   `json:"last_ratio"`
+	MeanRatio float64   `json:"mean_ratio"`
+	Store     bool      `json:"store"` // Content does not shrink; skip recompression passes
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RatioRegistry remembers per-file and per-extension compression ratios across commits
+// Stored in .dgit/metrics/compression-ratios.json and consulted before background recompression
+type RatioRegistry struct {
+	Files      map[string]*RatioStats `json:"files"`
+	Extensions map[string]*RatioStats `json:"extensions"`
+
+	path string
+}
+
+// LoadRatioRegistry reads the ratio registry (empty if none has been recorded yet)
+func LoadRatioRegistry(dgitDir string) (*RatioRegistry, error) {
+	registry := &RatioRegistry{
+		Files:      make(map[string]*RatioStats),
+		Extensions: make(map[string]*RatioStats),
+		path:       filepath.Join(dgitDir, "metrics", "compression-ratios.json"),
+	}
+	data, err := os.ReadFile(registry.path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compression ratios: %w", err)
+	}
+	if err := json.Unmarshal(data, registry); err != nil {
+		return nil, fmt.Errorf("failed to parse compression ratios: %w", err)
+	}
+	if registry.Files == nil {
+		registry.Files = make(map[string]*RatioStats)
+	}
+	if registry.Extensions == nil {
+		registry.Extensions = make(map[string]*RatioStats)
+	}
+	return registry, nil
+}
+
+// Record adds an achieved ratio for a file and its extension
+func (r *RatioRegistry) Record(path string, ratio float64, now time.Time) {
+	path = filepath.ToSlash(path)
+	r.Files[path] = updateStats(r.Files[path], ratio, now)
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		r.Extensions[ext] = updateStats(r.Extensions[ext], ratio, now)
+	}
+}
+
+// IsStore reports whether a file's content has proven incompressible
+// Paths never seen before fall back to their extension's history
+func (r *RatioRegistry) IsStore(path string) bool {
+	if stats, ok := r.Files[filepath.ToSlash(path)]; ok {
+		return stats.Store
+	}
+	if stats, ok := r.Extensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return stats.Store
+	}
+	return false
+}
+
+// Save writes the registry to disk
+func (r *RatioRegistry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal compression ratios: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write compression ratios: %w", err)
+	}
+	return nil
+}
+
+// updateStats folds a new ratio into running statistics
+func updateStats(stats *RatioStats, ratio float64, now time.Time) *RatioStats {
+	if stats == nil {
+		stats = &RatioStats{}
+	}
+	stats.MeanRatio = (stats.MeanRatio*float64(stats.Samples) + ratio) / float64(stats.Samples+1)
+	stats.Samples++
+	stats.LastRatio = ratio
+	stats.Store = stats.MeanRatio >= IncompressibleRatio
+	stats.UpdatedAt = now
+	return stats
+}
+
+// recordRatios stores the per-file ratios of a commit and decides whether recompression is worthwhile
+// Returns true when every file in the commit is marked "store"
+func (cm *CommitManager) recordRatios(result *CompressionResult, now time.Time) bool {
+	if len(result.FileRatios) == 0 {
+		return false
+	}
+	registry, err := LoadRatioRegistry(cm.DgitDir)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return false
+	}
+	allStore := true
+	for path, ratio := range result.FileRatios {
+		registry.Record(path, ratio, now)
+		if !registry.IsStore(path) {
+			allStore = false
+		}
+	}
+	if err := registry.Save(); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	return allStore
+}
@@ -0,0 +1,439 @@
+package deliver
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// ManifestFormat identifies the manifest schema for client-side tooling
+const ManifestFormat = "dgit-delivery-manifest/1"
+
+// ChecksumsName is the top-level checksum list written into archives delivered with checksums
+// Lines use the sha256sum format, so 'sha256sum -c SHA256SUMS' verifies the unpacked files
+const ChecksumsName = "SHA256SUMS"
+
+// ChecksumExt is the extension of per-file checksum sidecars
+const ChecksumExt = ".sha256"
+
+// ManifestFile describes one delivered file
+type ManifestFile struct {
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	SHA256     string `json:"sha256"`
+	Dimensions string `json:"dimensions,omitempty"`
+	ColorMode  string `json:"color_mode,omitempty"`
+}
+
+// Manifest is a signed record of exactly what was delivered to a client
+// Signed with the repository's ed25519 delivery key; the public key is embedded for verification
+type Manifest struct {
+	Format        string          `json:"format"`
+	Repository    string          `json:"repository"`
+	Version       int             `json:"version"`
+	CommitHash    string          `json:"commit_hash"`
+	CommitMessage string          `json:"commit_message"`
+	Author        string          `json:"author"`
+	ApprovalState string          `json:"approval_state"`
+	Approver      string          `json:"approver,omitempty"`
+	ApprovedAt    *time.Time      `json:"approved_at,omitempty"`
+	DeliveredAt   time.Time       `json:"delivered_at"`
+	DeliveredBy   string          `json:"delivered_by"`
+	Archive       string          `json:"archive"`
+	ArchiveSHA256 string          `json:"archive_sha256"`
+	Files         []*ManifestFile `json:"files"`
+	PublicKey     string          `json:"public_key"`
+	Signature     string          `json:"signature"`
+}
+
+// Result describes the output of a delivery
+type Result struct {
+	ArchivePath  string
+	ManifestPath string
+	Manifest     *Manifest
+	ChecksumPath string // Sidecar of the archive itself; empty without checksums
+	Files        int
+}
+
+// DeliveryManager packages approved versions for clients
+type DeliveryManager struct {
+	DgitDir string
+	RootDir string
+	KeyFile string
+	TempDir string
+
+	// Checksums adds a .sha256 sidecar for every file and a SHA256SUMS list to the archive,
+	// and writes a sidecar for the archive next to it
+	Checksums bool
+}
+
+// NewDeliveryManager creates a new delivery manager for the given .dgit directory
+func NewDeliveryManager(dgitDir string) *DeliveryManager {
+	return &DeliveryManager{
+		DgitDir: dgitDir,
+		RootDir: filepath.Dir(dgitDir),
+		KeyFile: filepath.Join(dgitDir, "keys", "deliver.key"),
+		TempDir: filepath.Join(dgitDir, "temp"),
+	}
+}
+
+// Deliver exports a commit as a zip archive, optionally with a signed manifest
+// The repository approval policy for "deliver" is enforced and the commit is marked delivered
+func (dm *DeliveryManager) Deliver(commit *log.Commit, outputDir string, withManifest bool, by string) (*Result, error) {
+	approvalManager := approval.NewApprovalManager(dm.DgitDir)
+	if err := approvalManager.EnforcePolicy("deliver", commit); err != nil {
+		return nil, err
+	}
+	state, err := approvalManager.GetState(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	scratchDir, err := dm.extractCommit(commit)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratchDir)
+
+	repoName := filepath.Base(dm.RootDir)
+	archiveName := fmt.Sprintf("%s-v%d.zip", repoName, commit.Version)
+	result := &Result{ArchivePath: filepath.Join(outputDir, archiveName)}
+
+	files, err := dm.writeArchive(commit, scratchDir, result.ArchivePath)
+	if err != nil {
+		return nil, err
+	}
+	result.Files = len(files)
+	if dm.Checksums {
+		archiveHash, _, err := hashFile(result.ArchivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash archive: %w", err)
+		}
+		result.ChecksumPath = result.ArchivePath + ChecksumExt
+		if err := os.WriteFile(result.ChecksumPath, []byte(checksumLine(archiveHash, archiveName)), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write archive checksum: %w", err)
+		}
+	}
+
+	if withManifest {
+		archiveHash, _, err := hashFile(result.ArchivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash archive: %w", err)
+		}
+
+		manifest := &Manifest{
+			Format:        ManifestFormat,
+			Repository:    repoName,
+			Version:       commit.Version,
+			CommitHash:    commit.Hash,
+			CommitMessage: commit.Message,
+			Author:        commit.Author,
+			ApprovalState: state.State,
+			DeliveredAt:   time.Now(),
+			DeliveredBy:   by,
+			Archive:       archiveName,
+			ArchiveSHA256: archiveHash,
+			Files:         files,
+		}
+		if state.Note != nil {
+			manifest.Approver = state.Note.By
+			approvedAt := state.Note.Timestamp
+			manifest.ApprovedAt = &approvedAt
+		}
+
+		if err := dm.signManifest(manifest); err != nil {
+			return nil, err
+		}
+
+		result.ManifestPath = strings.TrimSuffix(result.ArchivePath, ".zip") + ".manifest.json"
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+		}
+		if err := os.WriteFile(result.ManifestPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write manifest: %w", err)
+		}
+		result.Manifest = manifest
+	}
+
+	// Record the delivery in the approval workflow
+	if state.State != approval.StateDelivered {
+		message := fmt.Sprintf("delivered as %s", archiveName)
+		if _, err := approvalManager.SetState(commit, approval.StateDelivered, by, message); err != nil {
+			return nil, fmt.Errorf("delivered, but failed to record delivery state: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// VerifyManifest checks a manifest's signature and, when the archive sits next to it, the archive checksum
+// If publicKey is empty the key embedded in the manifest is used
+func VerifyManifest(manifestPath, publicKey string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Format != ManifestFormat {
+		return nil, fmt.Errorf("unsupported manifest format %q", manifest.Format)
+	}
+
+	if publicKey == "" {
+		publicKey = manifest.PublicKey
+	} else if publicKey != manifest.PublicKey {
+		return nil, fmt.Errorf("manifest was signed by a different key")
+	}
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	signature, err := hex.DecodeString(manifest.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding")
+	}
+	payload, err := signingPayload(&manifest)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), payload, signature) {
+		return nil, fmt.Errorf("signature does not match manifest contents")
+	}
+
+	archivePath := filepath.Join(filepath.Dir(manifestPath), manifest.Archive)
+	if _, err := os.Stat(archivePath); err == nil {
+		archiveHash, _, err := hashFile(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash archive: %w", err)
+		}
+		if archiveHash != manifest.ArchiveSHA256 {
+			return nil, fmt.Errorf("archive %s does not match manifest checksum", manifest.Archive)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// PublicKey returns the hex-encoded delivery public key, generating the key pair on first use
+func (dm *DeliveryManager) PublicKey() (string, error) {
+	privateKey, err := dm.loadOrCreateKey()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(privateKey.Public().(ed25519.PublicKey)), nil
+}
+
+// extractCommit restores every file of a commit into a scratch directory
+func (dm *DeliveryManager) extractCommit(commit *log.Commit) (string, error) {
+	if err := os.MkdirAll(dm.TempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	scratchDir, err := os.MkdirTemp(dm.TempDir, "deliver-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	files := make([]string, 0, len(commit.Metadata))
+	for fileName := range commit.Metadata {
+		files = append(files, fileName)
+	}
+
+	restoreManager := restore.NewRestoreManager(dm.DgitDir)
+	restoreManager.WorkDir = scratchDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", commit.Version), files, commit); err != nil {
+		os.RemoveAll(scratchDir)
+		return "", fmt.Errorf("failed to extract v%d: %w", commit.Version, err)
+	}
+	return scratchDir, nil
+}
+
+// writeArchive zips the extracted files and returns their manifest entries
+func (dm *DeliveryManager) writeArchive(commit *log.Commit, scratchDir, archivePath string) ([]*ManifestFile, error) {
+	out, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer out.Close()
+
+	zipWriter := zip.NewWriter(out)
+	defer zipWriter.Close()
+
+	fileNames := make([]string, 0, len(commit.Metadata))
+	for fileName := range commit.Metadata {
+		fileNames = append(fileNames, filepath.ToSlash(fileName))
+	}
+	sort.Strings(fileNames)
+
+	var entries []*ManifestFile
+	for _, fileName := range fileNames {
+		srcPath := filepath.Join(scratchDir, filepath.FromSlash(fileName))
+		checksum, size, err := hashFile(srcPath)
+		if err != nil {
+			return nil, fmt.Errorf("v%d is missing %s after extraction: %w", commit.Version, fileName, err)
+		}
+
+		if err := addToZip(zipWriter, srcPath, fileName); err != nil {
+			return nil, fmt.Errorf("failed to add %s to archive: %w", fileName, err)
+		}
+
+		entry := &ManifestFile{Path: fileName, Size: size, SHA256: checksum}
+		if metadata, ok := commit.Metadata[fileName].(map[string]interface{}); ok {
+			entry.Dimensions, _ = metadata["dimensions"].(string)
+			entry.ColorMode, _ = metadata["color_mode"].(string)
+		}
+		entries = append(entries, entry)
+	}
+
+	if dm.Checksums {
+		if err := writeChecksums(zipWriter, commit, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return entries, nil
+}
+
+// writeChecksums adds a sidecar next to each file and the SHA256SUMS list at the archive root
+// Sidecars name the file relative to their own directory, so 'sha256sum -c' works from there
+func writeChecksums(zipWriter *zip.Writer, commit *log.Commit, entries []*ManifestFile) error {
+	var sums strings.Builder
+	for _, entry := range entries {
+		sidecar := entry.Path + ChecksumExt
+		if _, exists := commit.Metadata[sidecar]; exists {
+			return fmt.Errorf("v%d already contains %s; deliver without --checksums", commit.Version, sidecar)
+		}
+		if err := writeZipText(zipWriter, sidecar, checksumLine(entry.SHA256, path.Base(entry.Path))); err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", sidecar, err)
+		}
+		sums.WriteString(checksumLine(entry.SHA256, entry.Path))
+	}
+	if _, exists := commit.Metadata[ChecksumsName]; exists {
+		return fmt.Errorf("v%d already contains %s; deliver without --checksums", commit.Version, ChecksumsName)
+	}
+	if err := writeZipText(zipWriter, ChecksumsName, sums.String()); err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", ChecksumsName, err)
+	}
+	return nil
+}
+
+// checksumLine formats one line of sha256sum output
+func checksumLine(checksum, name string) string {
+	return fmt.Sprintf("%s  %s\n", checksum, name)
+}
+
+// writeZipText adds a small text entry to the archive
+func writeZipText(zipWriter *zip.Writer, name, text string) error {
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+// signManifest embeds the public key and signs the manifest contents
+func (dm *DeliveryManager) signManifest(manifest *Manifest) error {
+	privateKey, err := dm.loadOrCreateKey()
+	if err != nil {
+		return err
+	}
+	manifest.PublicKey = hex.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+
+	payload, err := signingPayload(manifest)
+	if err != nil {
+		return err
+	}
+	manifest.Signature = hex.EncodeToString(ed25519.Sign(privateKey, payload))
+	return nil
+}
+
+// loadOrCreateKey reads the delivery signing key, generating one on first use
+func (dm *DeliveryManager) loadOrCreateKey() (ed25519.PrivateKey, error) {
+	if data, err := os.ReadFile(dm.KeyFile); err == nil {
+		seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("delivery key %s is corrupt", dm.KeyFile)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("failed to generate delivery key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dm.KeyFile), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := os.WriteFile(dm.KeyFile, []byte(hex.EncodeToString(seed)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write delivery key: %w", err)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// signingPayload returns the canonical bytes covered by the manifest signature
+func signingPayload(manifest *Manifest) ([]byte, error) {
+	unsigned := *manifest
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return data, nil
+}
+
+// addToZip copies a file into the archive under the given name
+func addToZip(zipWriter *zip.Writer, srcPath, name string) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	w, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// hashFile returns the SHA-256 checksum and size of a file
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
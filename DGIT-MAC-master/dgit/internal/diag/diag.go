@@ -0,0 +1,448 @@
+package diag
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/figma"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/remote"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/storage"
+)
+
+// RemoteTimeout bounds each remote connectivity check
+const RemoteTimeout = 10 * time.Second
+
+// envSettings are the environment variables that override repository settings
+// Secrets are reported as set or unset, never by value
+var envSettings = []struct {
+	Name   string
+	Key    string // Config key the variable overrides
+	Secret bool
+}{
+	{"DGIT_AUTHOR", "author", false},
+	{"DGIT_EMAIL", "email", false},
+	{remote.TokenEnv, "remotes.*.token", true},
+	{figma.TokenEnv, "figma.token", true},
+	{storage.AccessKeyEnv, "storage.s3.access_key", false},
+	{storage.SecretKeyEnv, "storage.s3.secret_key", true},
+	{storage.SessionTokenEnv, "storage.s3.session_token", true},
+}
+
+// Identity is who commits, approvals and deliveries are recorded as
+type Identity struct {
+	Author   string `json:"author"`
+	Email    string `json:"email"`
+	Source   string `json:"source"` // "config", "env" or "default"
+	User     string `json:"os_user"`
+	Hostname string `json:"hostname"`
+}
+
+// Paths are the locations DGit reads and writes
+type Paths struct {
+	WorkDir    string `json:"work_dir"`
+	Root       string `json:"root,omitempty"` // Empty outside a repository
+	DgitDir    string `json:"dgit_dir,omitempty"`
+	Config     string `json:"config,omitempty"`
+	Objects    string `json:"objects,omitempty"`
+	Cache      string `json:"cache,omitempty"`
+	Temp       string `json:"temp,omitempty"`
+	Executable string `json:"executable"`
+}
+
+// Setting is one effective configuration value and the layer it came from
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // "repository" or "env"
+}
+
+// Capability is a platform feature DGit can use when it is available
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Platform describes the machine and the features it offers
+type Platform struct {
+	OS           string        `json:"os"`
+	Arch         string        `json:"arch"`
+	GoVersion    string        `json:"go_version"`
+	CPUs         int           `json:"cpus"`
+	Capabilities []*Capability `json:"capabilities"`
+}
+
+// RemoteCheck is the result of contacting one remote
+type RemoteCheck struct {
+	Name      string  `json:"name"`
+	URL       string  `json:"url"`
+	Token     bool    `json:"token"`
+	Reachable bool    `json:"reachable"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Commits   int     `json:"commits,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is everything 'dgit whoami' gathers
+type Report struct {
+	Time     time.Time      `json:"time"`
+	Identity *Identity      `json:"identity"`
+	Paths    *Paths         `json:"paths"`
+	Config   []*Setting     `json:"config,omitempty"`
+	Platform *Platform      `json:"platform"`
+	Remotes  []*RemoteCheck `json:"remotes,omitempty"`
+	Problems []string       `json:"problems,omitempty"`
+}
+
+// DiagManager gathers environment diagnostics for support requests
+type DiagManager struct {
+	DgitDir string // Empty when run outside a repository
+
+	// CheckRemotes contacts every configured remote
+	CheckRemotes bool
+}
+
+// NewDiagManager creates a diagnostics manager; dgitDir may be empty outside a repository
+func NewDiagManager(dgitDir string) *DiagManager {
+	return &DiagManager{DgitDir: dgitDir, CheckRemotes: true}
+}
+
+// Report gathers identity, paths, effective config, platform capabilities and remote connectivity
+// Individual checks that fail are recorded as problems rather than aborting the report
+func (dm *DiagManager) Report() *Report {
+	report := &Report{Time: time.Now()}
+	report.Paths = dm.paths()
+
+	var config *initializer.RepositoryConfig
+	if dm.DgitDir != "" {
+		var err error
+		config, err = initializer.GetRepositoryConfig(dm.DgitDir)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("repository config: %v", err))
+		}
+	}
+	report.Identity = dm.identity(config)
+
+	if config != nil {
+		settings, err := effectiveConfig(config)
+		if err != nil {
+			report.Problems = append(report.Problems, err.Error())
+		}
+		report.Config = settings
+	}
+
+	report.Platform = dm.platform()
+	if dm.CheckRemotes && config != nil {
+		report.Remotes = dm.checkRemotes(config.Remotes)
+		for _, r := range report.Remotes {
+			if !r.Reachable {
+				report.Problems = append(report.Problems, fmt.Sprintf("remote %s: %s", r.Name, r.Error))
+			}
+		}
+	}
+	return report
+}
+
+// identity resolves the author the same way commits do
+func (dm *DiagManager) identity(config *initializer.RepositoryConfig) *Identity {
+	id := &Identity{Source: "default"}
+	if current, err := user.Current(); err == nil {
+		id.User = current.Username
+	}
+	id.Hostname, _ = os.Hostname()
+
+	if dm.DgitDir != "" && config != nil {
+		author, email, source := commit.NewCommitManager(dm.DgitDir).Identity()
+		id.Author, id.Email, id.Source = author, email, source
+		if id.Source == "" {
+			id.Source = "config"
+		}
+		return id
+	}
+	id.Author, id.Email = os.Getenv("DGIT_AUTHOR"), os.Getenv("DGIT_EMAIL")
+	if id.Author != "" || id.Email != "" {
+		id.Source = "env"
+	}
+	return id
+}
+
+// paths lists the directories in use
+func (dm *DiagManager) paths() *Paths {
+	paths := &Paths{}
+	paths.WorkDir, _ = os.Getwd()
+	paths.Executable, _ = os.Executable()
+	if dm.DgitDir == "" {
+		return paths
+	}
+	paths.Root = filepath.Dir(dm.DgitDir)
+	paths.DgitDir = dm.DgitDir
+	paths.Config = filepath.Join(dm.DgitDir, "config")
+	paths.Objects = filepath.Join(dm.DgitDir, "objects")
+	paths.Cache = filepath.Join(dm.DgitDir, "cache")
+	paths.Temp = filepath.Join(dm.DgitDir, "temp")
+	return paths
+}
+
+// effectiveConfig flattens the repository config into dotted keys and applies environment overrides
+// DGit has no per-user config file, so the environment is the only layer above the repository
+func effectiveConfig(config *initializer.RepositoryConfig) ([]*Setting, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	values := make(map[string]string)
+	flatten("", tree, values)
+
+	sources := make(map[string]string, len(values))
+	for key := range values {
+		sources[key] = "repository"
+	}
+	for _, env := range envSettings {
+		value, ok := os.LookupEnv(env.Name)
+		if !ok || value == "" {
+			continue
+		}
+		if env.Secret {
+			value = "(set)"
+		}
+		values[env.Key] = fmt.Sprintf("%s  [%s]", value, env.Name)
+		sources[env.Key] = "env"
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	settings := make([]*Setting, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, &Setting{Key: key, Value: values[key], Source: sources[key]})
+	}
+	return settings, nil
+}
+
+// flatten walks decoded JSON, recording leaves under dotted keys; lists of scalars stay on one line
+func flatten(prefix string, node interface{}, out map[string]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			flatten(name, child, out)
+		}
+	case []interface{}:
+		scalars := true
+		for _, child := range v {
+			switch child.(type) {
+			case map[string]interface{}, []interface{}:
+				scalars = false
+			}
+		}
+		if scalars {
+			parts := make([]string, len(v))
+			for i, child := range v {
+				parts[i] = fmt.Sprint(child)
+			}
+			out[prefix] = "[" + strings.Join(parts, ", ") + "]"
+			return
+		}
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s.%d", prefix, i), child, out)
+		}
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+// platform probes the features DGit can take advantage of
+func (dm *DiagManager) platform() *Platform {
+	p := &Platform{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		CPUs:      runtime.NumCPU(),
+	}
+	scratch, err := dm.scratchDir()
+	if err != nil {
+		p.Capabilities = append(p.Capabilities, &Capability{Name: "scratch", Detail: err.Error()})
+		return p
+	}
+	defer os.RemoveAll(scratch)
+
+	p.Capabilities = append(p.Capabilities,
+		checkSymlinks(scratch),
+		checkReflink(scratch),
+		checkFUSE(),
+		checkCaseSensitive(scratch),
+	)
+	for _, tool := range []string{"magick", "bsdiff", "xdelta3"} {
+		c := &Capability{Name: tool}
+		if path, err := exec.LookPath(tool); err == nil {
+			c.Available, c.Detail = true, path
+		} else {
+			c.Detail = "not on PATH"
+		}
+		p.Capabilities = append(p.Capabilities, c)
+	}
+	return p
+}
+
+// scratchDir creates a directory for probes on the repository's own filesystem when possible
+func (dm *DiagManager) scratchDir() (string, error) {
+	base := os.TempDir()
+	if dm.DgitDir != "" {
+		base = filepath.Join(dm.DgitDir, "temp")
+		if err := os.MkdirAll(base, 0755); err != nil {
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
+		}
+	}
+	return os.MkdirTemp(base, "whoami-")
+}
+
+// checkSymlinks creates and resolves a symbolic link
+func checkSymlinks(dir string) *Capability {
+	c := &Capability{Name: "symlinks"}
+	target := filepath.Join(dir, "target")
+	link := filepath.Join(dir, "link")
+	if err := os.WriteFile(target, []byte("dgit"), 0644); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	if err := os.Symlink("target", link); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	if resolved, err := os.Readlink(link); err != nil || resolved != "target" {
+		c.Detail = "link could not be read back"
+		return c
+	}
+	c.Available = true
+	return c
+}
+
+// checkReflink asks cp for a copy-on-write clone (APFS clonefile, Btrfs/XFS reflink)
+func checkReflink(dir string) *Capability {
+	c := &Capability{Name: "reflink"}
+	source := filepath.Join(dir, "clone-source")
+	if err := os.WriteFile(source, []byte("dgit"), 0644); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"-c", source, filepath.Join(dir, "clone")}
+	case "linux":
+		args = []string{"--reflink=always", source, filepath.Join(dir, "clone")}
+	default:
+		c.Detail = "not supported on " + runtime.GOOS
+		return c
+	}
+	if output, err := exec.Command("cp", args...).CombinedOutput(); err != nil {
+		// cp names both scratch paths; the reason is the last part of its message
+		message := strings.TrimSpace(string(output))
+		c.Detail = message[strings.LastIndex(message, ": ")+1:]
+		c.Detail = strings.TrimSpace(c.Detail)
+		if c.Detail == "" {
+			c.Detail = err.Error()
+		}
+		return c
+	}
+	c.Available = true
+	c.Detail = "copy-on-write clones on this filesystem"
+	return c
+}
+
+// checkFUSE looks for an installed FUSE implementation
+func checkFUSE() *Capability {
+	c := &Capability{Name: "fuse"}
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"/Library/Filesystems/macfuse.fs", "/Library/Filesystems/osxfuse.fs", "/Library/Filesystems/fuse-t.fs"}
+	case "linux":
+		if _, err := os.Stat("/dev/fuse"); err != nil {
+			c.Detail = "/dev/fuse not present"
+			return c
+		}
+		for _, tool := range []string{"fusermount3", "fusermount"} {
+			if path, err := exec.LookPath(tool); err == nil {
+				c.Available, c.Detail = true, path
+				return c
+			}
+		}
+		c.Detail = "/dev/fuse present but fusermount is not installed"
+		return c
+	default:
+		c.Detail = "not supported on " + runtime.GOOS
+		return c
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			c.Available, c.Detail = true, path
+			return c
+		}
+	}
+	c.Detail = "macFUSE is not installed"
+	return c
+}
+
+// checkCaseSensitive reports whether names differing only in case are distinct files
+func checkCaseSensitive(dir string) *Capability {
+	c := &Capability{Name: "case-sensitive"}
+	if err := os.WriteFile(filepath.Join(dir, "case"), []byte("dgit"), 0644); err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CASE")); err == nil {
+		c.Detail = "Logo.psd and logo.psd are the same file here"
+		return c
+	}
+	c.Available = true
+	return c
+}
+
+// checkRemotes contacts each remote and fetches its commit list
+func (dm *DiagManager) checkRemotes(remotes []initializer.RemoteConfig) []*RemoteCheck {
+	manager := remote.NewRemoteManager(dm.DgitDir)
+	manager.Client.Timeout = RemoteTimeout
+	var checks []*RemoteCheck
+	for _, r := range remotes {
+		check := &RemoteCheck{Name: r.Name, URL: r.URL}
+		if _, err := manager.Token(r.Name); err != nil {
+			check.Error = err.Error()
+			checks = append(checks, check)
+			continue
+		}
+		check.Token = true
+		started := time.Now()
+		adv, err := manager.Advertise(r.Name)
+		check.LatencyMs = float64(time.Since(started).Microseconds()) / 1000
+		if err != nil {
+			check.Error = err.Error()
+		} else {
+			check.Reachable = true
+			check.Commits = len(adv.Commits)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
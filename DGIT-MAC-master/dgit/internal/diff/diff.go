@@ -0,0 +1,358 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/branch"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// Change kinds of a file between the two sides of a diff
+const (
+	Added     = "added"
+	Removed   = "removed"
+	Modified  = "modified"
+	Unchanged = "unchanged"
+)
+
+// FileState is the design metadata of one file on one side of a diff
+type FileState struct {
+	Type       string
+	Dimensions string
+	ColorMode  string
+	Layers     int
+	Artboards  int
+	LayerNames []string
+	Size       int64
+	SHA256     string
+}
+
+// FileDiff compares one file between two sides
+type FileDiff struct {
+	Path          string
+	Change        string
+	Old           *FileState // nil when added
+	New           *FileState // nil when removed
+	LayersAdded   []string
+	LayersRemoved []string
+}
+
+// SizeDelta returns the change in file size in bytes
+func (fd *FileDiff) SizeDelta() int64 {
+	var delta int64
+	if fd.New != nil {
+		delta += fd.New.Size
+	}
+	if fd.Old != nil {
+		delta -= fd.Old.Size
+	}
+	return delta
+}
+
+// Result is the comparison of two versions, or of a version and the working tree
+type Result struct {
+	From  string // e.g. "v3"
+	To    string // e.g. "v5" or "working tree"
+	Files []*FileDiff
+}
+
+// Changed returns the files that differ, sorted by path
+func (r *Result) Changed() []*FileDiff {
+	var changed []*FileDiff
+	for _, file := range r.Files {
+		if file.Change != Unchanged {
+			changed = append(changed, file)
+		}
+	}
+	return changed
+}
+
+// DiffManager compares the design metadata recorded for versions and working files
+type DiffManager struct {
+	DgitDir string
+}
+
+// NewDiffManager creates a new diff manager for the given .dgit directory
+func NewDiffManager(dgitDir string) *DiffManager {
+	return &DiffManager{DgitDir: dgitDir}
+}
+
+// Versions compares the files of two commits as seen from each commit
+// paths limits the comparison to the given repository paths; empty compares everything
+func (dm *DiffManager) Versions(from, to *log.Commit, paths []string) (*Result, error) {
+	oldFiles, err := dm.committedStates(from)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := dm.committedStates(to)
+	if err != nil {
+		return nil, err
+	}
+	return compare(fmt.Sprintf("v%d", from.Version), fmt.Sprintf("v%d", to.Version), oldFiles, newFiles, paths), nil
+}
+
+// WorkingTree compares a commit with the files on disk
+// Only files committed by that point are compared; untracked files are not part of the diff
+func (dm *DiffManager) WorkingTree(from *log.Commit, paths []string) (*Result, error) {
+	oldFiles, err := dm.committedStates(from)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(dm.DgitDir)
+	sc := scanner.NewCachedFileScanner(dm.DgitDir)
+	newFiles := make(map[string]*FileState)
+	for path, old := range oldFiles {
+		if !matchesPaths(path, paths) {
+			continue
+		}
+		abs := filepath.Join(root, filepath.FromSlash(path))
+		info, err := os.Stat(abs)
+		if err != nil {
+			continue // Deleted from the working tree
+		}
+		hash, err := status.CalculateFileHash(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		if hash == old.SHA256 {
+			newFiles[path] = old
+			continue
+		}
+		state := &FileState{Size: info.Size(), SHA256: hash, Type: old.Type}
+		if scanned, err := sc.ScanFile(abs); err == nil {
+			state.Type = scanned.Type
+			state.Dimensions = scanned.Dimensions
+			state.ColorMode = scanned.ColorMode
+			state.Layers = scanned.Layers
+			state.Artboards = scanned.Artboards
+			state.LayerNames = scanned.LayerNames
+		}
+		newFiles[path] = state
+	}
+	return compare(fmt.Sprintf("v%d", from.Version), "working tree", oldFiles, newFiles, paths), nil
+}
+
+// Render writes a human-readable comparison of the changed files
+// Lines start with A, D or M followed by indented property changes
+func (dm *DiffManager) Render(w io.Writer, result *Result) {
+	changed := result.Changed()
+	fmt.Fprintf(w, "Comparing %s → %s\n\n", result.From, result.To)
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, file := range changed {
+		counts[file.Change]++
+		switch file.Change {
+		case Added:
+			fmt.Fprintf(w, "A  %s  (%s)\n", file.Path, summary(file.New))
+		case Removed:
+			fmt.Fprintf(w, "D  %s  (%s)\n", file.Path, summary(file.Old))
+		case Modified:
+			fmt.Fprintf(w, "M  %s\n", file.Path)
+			fmt.Fprintf(w, "     Size        %s → %s (%s)\n", util.FormatBytes(file.Old.Size), util.FormatBytes(file.New.Size), formatDelta(file.SizeDelta()))
+			if file.Old.Dimensions != file.New.Dimensions {
+				fmt.Fprintf(w, "     Dimensions  %s → %s\n", orUnknown(file.Old.Dimensions), orUnknown(file.New.Dimensions))
+			}
+			if file.Old.ColorMode != file.New.ColorMode {
+				fmt.Fprintf(w, "     Color mode  %s → %s\n", orUnknown(file.Old.ColorMode), orUnknown(file.New.ColorMode))
+			}
+			if file.Old.Artboards != file.New.Artboards {
+				fmt.Fprintf(w, "     Artboards   %d → %d\n", file.Old.Artboards, file.New.Artboards)
+			}
+			if file.Old.Layers != file.New.Layers {
+				fmt.Fprintf(w, "     Layers      %d → %d\n", file.Old.Layers, file.New.Layers)
+			}
+			for _, name := range file.LayersAdded {
+				fmt.Fprintf(w, "       + %s\n", name)
+			}
+			for _, name := range file.LayersRemoved {
+				fmt.Fprintf(w, "       - %s\n", name)
+			}
+		}
+	}
+
+	var delta int64
+	for _, file := range changed {
+		delta += file.SizeDelta()
+	}
+	fmt.Fprintf(w, "\n%d file(s) changed: %d added, %d modified, %d removed; size %s\n",
+		len(changed), counts[Added], counts[Modified], counts[Removed], formatDelta(delta))
+}
+
+// committedStates returns the metadata of every file as of a commit
+// Commits only record the files staged for them, so older versions supply the rest
+func (dm *DiffManager) committedStates(c *log.Commit) (map[string]*FileState, error) {
+	tree, err := branch.NewBranchManager(dm.DgitDir).Tree(c.Hash)
+	if err != nil {
+		return nil, err
+	}
+	logManager := log.NewLogManager(dm.DgitDir)
+	commits := map[int]*log.Commit{c.Version: c}
+	states := make(map[string]*FileState, len(tree))
+	for path, file := range tree {
+		source, ok := commits[file.Version]
+		if !ok {
+			if source, err = logManager.GetCommit(file.Version); err != nil {
+				return nil, fmt.Errorf("failed to load v%d: %w", file.Version, err)
+			}
+			commits[file.Version] = source
+		}
+		raw, ok := source.Metadata[path]
+		if !ok {
+			raw = source.Metadata[filepath.FromSlash(path)]
+		}
+		states[path] = stateFromMetadata(raw)
+	}
+	return states, nil
+}
+
+// stateFromMetadata reads the per-file metadata map stored in a commit
+func stateFromMetadata(raw interface{}) *FileState {
+	state := &FileState{}
+	meta, ok := raw.(map[string]interface{})
+	if !ok {
+		return state
+	}
+	state.Type, _ = meta["type"].(string)
+	state.Dimensions, _ = meta["dimensions"].(string)
+	state.ColorMode, _ = meta["color_mode"].(string)
+	state.SHA256, _ = meta["sha256"].(string)
+	if v, ok := meta["layers"].(float64); ok {
+		state.Layers = int(v)
+	}
+	if v, ok := meta["artboards"].(float64); ok {
+		state.Artboards = int(v)
+	}
+	if v, ok := meta["size"].(float64); ok {
+		state.Size = int64(v)
+	}
+	if names, ok := meta["layer_names"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				state.LayerNames = append(state.LayerNames, s)
+			}
+		}
+	}
+	return state
+}
+
+// compare pairs up the files of both sides
+func compare(from, to string, oldFiles, newFiles map[string]*FileState, paths []string) *Result {
+	result := &Result{From: from, To: to}
+	seen := make(map[string]bool)
+	for path := range oldFiles {
+		seen[path] = true
+	}
+	for path := range newFiles {
+		seen[path] = true
+	}
+	for path := range seen {
+		if !matchesPaths(path, paths) {
+			continue
+		}
+		file := &FileDiff{Path: path, Old: oldFiles[path], New: newFiles[path]}
+		switch {
+		case file.Old == nil:
+			file.Change = Added
+		case file.New == nil:
+			file.Change = Removed
+		case file.Old.SHA256 != "" && file.Old.SHA256 == file.New.SHA256:
+			file.Change = Unchanged
+		default:
+			file.Change = Modified
+			file.LayersAdded, file.LayersRemoved = layerChanges(file.Old.LayerNames, file.New.LayerNames)
+		}
+		result.Files = append(result.Files, file)
+	}
+	sort.Slice(result.Files, func(i, j int) bool { return result.Files[i].Path < result.Files[j].Path })
+	return result
+}
+
+// layerChanges lists layer names only on the new side and only on the old side
+// Names are counted, so a duplicated "Layer 1" shows up as added once
+func layerChanges(oldNames, newNames []string) (added, removed []string) {
+	counts := make(map[string]int)
+	for _, name := range oldNames {
+		counts[name]++
+	}
+	for _, name := range newNames {
+		if counts[name] > 0 {
+			counts[name]--
+			continue
+		}
+		added = append(added, name)
+	}
+	for _, name := range oldNames {
+		if counts[name] > 0 {
+			counts[name]--
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// matchesPaths reports whether a repository path is selected by the path filter
+// A filter entry selects the file itself, everything below a directory, or a glob match
+func matchesPaths(path string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, filter := range paths {
+		filter = strings.TrimSuffix(filepath.ToSlash(filter), "/")
+		if path == filter || strings.HasPrefix(path, filter+"/") {
+			return true
+		}
+		if matched, _ := filepath.Match(filter, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// summary describes a file on one side, e.g. "1.2 MB, 1920x1080, 12 layers"
+func summary(state *FileState) string {
+	parts := []string{util.FormatBytes(state.Size)}
+	if state.Dimensions != "" && state.Dimensions != "Unknown" {
+		parts = append(parts, state.Dimensions)
+	}
+	if state.ColorMode != "" && state.ColorMode != "Unknown" {
+		parts = append(parts, state.ColorMode)
+	}
+	if state.Layers > 0 {
+		parts = append(parts, fmt.Sprintf("%d layers", state.Layers))
+	}
+	if state.Artboards > 0 {
+		parts = append(parts, fmt.Sprintf("%d artboards", state.Artboards))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// orUnknown shows empty metadata as "unknown"
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+// formatDelta formats a size change as "+1.2 MB", "-300 B" or "±0 B"
+func formatDelta(delta int64) string {
+	switch {
+	case delta > 0:
+		return "+" + util.FormatBytes(delta)
+	case delta < 0:
+		return "-" + util.FormatBytes(-delta)
+	}
+	return "±0 B"
+}
@@ -0,0 +1,274 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Photoshop and Illustrator thumbnails are JPEG
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/branch"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/thumbnail"
+)
+
+// Visual comparison layouts
+const (
+	ModeSideBySide = "side-by-side" // Before and after next to each other
+	ModeBlend      = "blend"        // The after image faded, with changed pixels in red
+)
+
+// DefaultThreshold is the perceived color distance (0-1) below which pixels count as unchanged
+// It absorbs JPEG noise in embedded thumbnails without hiding real edits
+const DefaultThreshold = 0.1
+
+// maxYIQDelta is the largest possible YIQ distance, between black and white
+const maxYIQDelta = 35215.0
+
+// sideBySideGap is the space between the two images of a side-by-side comparison
+const sideBySideGap = 16
+
+// VisualOptions control a visual comparison
+type VisualOptions struct {
+	Mode      string  // ModeSideBySide (default) or ModeBlend
+	Threshold float64 // Per-pixel color distance tolerance; 0 uses DefaultThreshold
+	OutputDir string  // Where the PNG is written; empty uses a new temporary folder
+}
+
+// VisualResult is a rendered before/after comparison of one file
+type VisualResult struct {
+	Path          string // Repository path of the file
+	From          string // e.g. "v3"
+	To            string
+	Output        string // The comparison PNG
+	Width         int    // Size both thumbnails were compared at
+	Height        int
+	ChangedPixels int
+	Difference    float64 // Percentage of pixels that differ perceptibly
+	Resized       bool    // The thumbnails had different sizes; the older one was scaled
+}
+
+// Visual compares the thumbnails of a file in two commits and renders the comparison as PNG
+// Each commit's thumbnail is taken from the version holding the file as of that commit
+func (dm *DiffManager) Visual(from, to *log.Commit, file string, opts VisualOptions) (*VisualResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeSideBySide
+	}
+	if opts.Mode != ModeSideBySide && opts.Mode != ModeBlend {
+		return nil, fmt.Errorf("unknown visual diff mode %q (use %s or %s)", opts.Mode, ModeSideBySide, ModeBlend)
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+
+	before, source, err := dm.commitThumbnail(from, file)
+	if err != nil {
+		return nil, err
+	}
+	after, _, err := dm.commitThumbnail(to, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VisualResult{Path: source, From: fmt.Sprintf("v%d", from.Version), To: fmt.Sprintf("v%d", to.Version)}
+	bounds := after.Bounds()
+	result.Width, result.Height = bounds.Dx(), bounds.Dy()
+	if before.Bounds().Size() != bounds.Size() {
+		before = scale(before, result.Width, result.Height)
+		result.Resized = true
+	}
+
+	changed := make([]bool, result.Width*result.Height)
+	limit := maxYIQDelta * opts.Threshold * opts.Threshold
+	for y := 0; y < result.Height; y++ {
+		for x := 0; x < result.Width; x++ {
+			if yiqDelta(before.At(x, y), after.At(bounds.Min.X+x, bounds.Min.Y+y)) > limit {
+				changed[y*result.Width+x] = true
+				result.ChangedPixels++
+			}
+		}
+	}
+	if total := result.Width * result.Height; total > 0 {
+		result.Difference = float64(result.ChangedPixels) * 100 / float64(total)
+	}
+
+	var rendered image.Image
+	if opts.Mode == ModeBlend {
+		rendered = renderBlend(after, changed, result.Width)
+	} else {
+		rendered = renderSideBySide(before, after)
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		if outputDir, err = os.MkdirTemp("", "dgit-visual-diff-"); err != nil {
+			return nil, fmt.Errorf("failed to create output folder: %w", err)
+		}
+	} else if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output folder: %w", err)
+	}
+	name := strings.TrimSuffix(path.Base(source), path.Ext(source))
+	result.Output = filepath.Join(outputDir, fmt.Sprintf("%s-%s-%s-%s.png", name, result.From, result.To, opts.Mode))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, rendered); err != nil {
+		return nil, fmt.Errorf("failed to encode comparison: %w", err)
+	}
+	if err := os.WriteFile(result.Output, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write comparison: %w", err)
+	}
+	return result, nil
+}
+
+// commitThumbnail decodes the thumbnail of a file as of a commit and returns it with the resolved path
+func (dm *DiffManager) commitThumbnail(c *log.Commit, file string) (image.Image, string, error) {
+	tree, err := branch.NewBranchManager(dm.DgitDir).Tree(c.Hash)
+	if err != nil {
+		return nil, "", err
+	}
+	source, err := treePath(tree, file, c.Version)
+	if err != nil {
+		return nil, "", err
+	}
+	thumb, err := thumbnail.NewThumbnailManager(dm.DgitDir).ForVersion(tree[source].Version, source)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s in v%d: %w", source, c.Version, err)
+	}
+	data, err := os.ReadFile(thumb.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode the v%d thumbnail of %s: %w", tree[source].Version, source, err)
+	}
+	return img, source, nil
+}
+
+// treePath resolves a path or unique file name to a path in a commit's tree
+func treePath(tree map[string]*branch.TreeFile, file string, version int) (string, error) {
+	file = filepath.ToSlash(filepath.Clean(file))
+	if _, ok := tree[file]; ok {
+		return file, nil
+	}
+	var byName []string
+	for p := range tree {
+		if path.Base(p) == path.Base(file) {
+			byName = append(byName, p)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return "", fmt.Errorf("%s is not in v%d", file, version)
+	case 1:
+		return byName[0], nil
+	}
+	return "", fmt.Errorf("%s matches several files in v%d: %s", file, version, strings.Join(byName, ", "))
+}
+
+// yiqDelta is the perceived distance between two colors, measured in YIQ after flattening onto white
+// Brightness (Y) weighs most, as it does for the eye
+func yiqDelta(a, b color.Color) float64 {
+	y1, i1, q1 := toYIQ(a)
+	y2, i2, q2 := toYIQ(b)
+	dy, di, dq := y1-y2, i1-i2, q1-q2
+	return 0.5053*dy*dy + 0.299*di*di + 0.1957*dq*dq
+}
+
+// toYIQ converts a color composited over white to YIQ on a 0-255 scale
+func toYIQ(c color.Color) (float64, float64, float64) {
+	r, g, b, a := c.RGBA()
+	white := float64(0xffff - a)
+	rf := (float64(r) + white) / 257
+	gf := (float64(g) + white) / 257
+	bf := (float64(b) + white) / 257
+	y := rf*0.29889531 + gf*0.58662247 + bf*0.11448223
+	i := rf*0.59597799 - gf*0.27417610 - bf*0.32180189
+	q := rf*0.21147017 - gf*0.52261711 + bf*0.31114694
+	return y, i, q
+}
+
+// scale resizes an image with bilinear sampling so thumbnails saved at different sizes can be compared
+func scale(src image.Image, width, height int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	sb := src.Bounds()
+	sx := float64(sb.Dx()) / float64(width)
+	sy := float64(sb.Dy()) / float64(height)
+	for y := 0; y < height; y++ {
+		fy := (float64(y)+0.5)*sy - 0.5
+		for x := 0; x < width; x++ {
+			fx := (float64(x)+0.5)*sx - 0.5
+			dst.Set(x, y, bilinear(src, sb, fx, fy))
+		}
+	}
+	return dst
+}
+
+// bilinear samples src at a fractional position
+func bilinear(src image.Image, b image.Rectangle, fx, fy float64) color.NRGBA {
+	x0, y0 := int(fx), int(fy)
+	if fx < 0 {
+		x0, fx = 0, 0
+	}
+	if fy < 0 {
+		y0, fy = 0, 0
+	}
+	x1, y1 := min(x0+1, b.Dx()-1), min(y0+1, b.Dy()-1)
+	x0, y0 = min(x0, b.Dx()-1), min(y0, b.Dy()-1)
+	tx, ty := fx-float64(x0), fy-float64(y0)
+	tx, ty = max(0, min(tx, 1)), max(0, min(ty, 1))
+
+	var out [4]float64
+	corners := []struct {
+		x, y int
+		w    float64
+	}{
+		{x0, y0, (1 - tx) * (1 - ty)}, {x1, y0, tx * (1 - ty)},
+		{x0, y1, (1 - tx) * ty}, {x1, y1, tx * ty},
+	}
+	for _, corner := range corners {
+		c := color.NRGBAModel.Convert(src.At(b.Min.X+corner.x, b.Min.Y+corner.y)).(color.NRGBA)
+		out[0] += float64(c.R) * corner.w
+		out[1] += float64(c.G) * corner.w
+		out[2] += float64(c.B) * corner.w
+		out[3] += float64(c.A) * corner.w
+	}
+	return color.NRGBA{uint8(out[0] + 0.5), uint8(out[1] + 0.5), uint8(out[2] + 0.5), uint8(out[3] + 0.5)}
+}
+
+// renderSideBySide places before and after next to each other on white
+func renderSideBySide(before, after image.Image) image.Image {
+	bb, ab := before.Bounds(), after.Bounds()
+	width := bb.Dx() + sideBySideGap + ab.Dx()
+	height := max(bb.Dy(), ab.Dy())
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(canvas, image.Rect(0, 0, bb.Dx(), bb.Dy()), before, bb.Min, draw.Over)
+	offset := bb.Dx() + sideBySideGap
+	draw.Draw(canvas, image.Rect(offset, 0, offset+ab.Dx(), ab.Dy()), after, ab.Min, draw.Over)
+	return canvas
+}
+
+// renderBlend shows the after image as faded grey with the changed pixels in red
+func renderBlend(after image.Image, changed []bool, width int) image.Image {
+	b := after.Bounds()
+	canvas := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	highlight := color.NRGBA{R: 255, A: 255}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			if changed[y*width+x] {
+				canvas.SetNRGBA(x, y, highlight)
+				continue
+			}
+			luma, _, _ := toYIQ(after.At(b.Min.X+x, b.Min.Y+y))
+			faded := uint8(255 - (255-luma)*0.25)
+			canvas.SetNRGBA(x, y, color.NRGBA{faded, faded, faded, 255})
+		}
+	}
+	return canvas
+}
@@ -0,0 +1,406 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// SidecarName is the file every exported Git commit carries with its DGit version's metadata
+const SidecarName = ".dgit-version.json"
+
+// DefaultGitBranch receives the mainline; DGit branches keep their own names
+const DefaultGitBranch = "main"
+
+// stateName and marksName live in the target's .git directory and make later exports incremental
+const (
+	stateName = "dgit-export.json"
+	marksName = "dgit-export.marks"
+)
+
+// Sidecar is the content of SidecarName
+type Sidecar struct {
+	Version    int                    `json:"version"`
+	Hash       string                 `json:"hash"`
+	ParentHash string                 `json:"parent_hash,omitempty"`
+	Branch     string                 `json:"branch,omitempty"`
+	Message    string                 `json:"message"`
+	Author     string                 `json:"author"`
+	Email      string                 `json:"email,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Files      map[string]interface{} `json:"files"`
+	Renames    map[string]string      `json:"renames,omitempty"`
+}
+
+// GitState records what a target repository already holds from an earlier export
+type GitState struct {
+	Source    string    `json:"source"`
+	Version   int       `json:"version"`
+	Branch    string    `json:"branch"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GitResult summarizes one export
+type GitResult struct {
+	Target   string
+	Commits  int      // Versions written as Git commits by this run
+	Files    int      // File contents written
+	Bytes    int64
+	From     int      // First and last version exported; 0 when nothing was new
+	To       int
+	Branches []string // Git branches that received commits
+	Skipped  []string // Stored paths that could not be made repo-relative, as "vN:path"
+}
+
+// ExportManager writes DGit history into other version control systems
+type ExportManager struct {
+	DgitDir string
+	RootDir string
+	TempDir string
+	// Progress receives one line per exported version when set
+	Progress io.Writer
+}
+
+// NewExportManager creates a new export manager for the given .dgit directory
+func NewExportManager(dgitDir string) *ExportManager {
+	return &ExportManager{
+		DgitDir: dgitDir,
+		RootDir: filepath.Dir(dgitDir),
+		TempDir: filepath.Join(dgitDir, "temp"),
+	}
+}
+
+// ExportGit replays every version not yet in target as a Git commit through git fast-import
+// The target is created with git init when needed; its mainline branch is checked out afterwards
+func (em *ExportManager) ExportGit(target, branch string) (*GitResult, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed or not on PATH")
+	}
+	if branch == "" {
+		branch = DefaultGitBranch
+	}
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	source, _ := filepath.Abs(em.RootDir)
+	if target == source || strings.HasPrefix(target, source+string(filepath.Separator)) {
+		return nil, fmt.Errorf("the export target must be outside the DGit repository")
+	}
+
+	gitDir, err := initGitRepository(target, branch)
+	if err != nil {
+		return nil, err
+	}
+	state, err := loadGitState(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	if state.Source != "" && state.Source != source {
+		return nil, fmt.Errorf("%s already mirrors %s", target, state.Source)
+	}
+	if state.Branch != "" && state.Branch != branch {
+		return nil, fmt.Errorf("%s was exported with --branch %s", target, state.Branch)
+	}
+
+	commits, err := log.NewLogManager(em.DgitDir).GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit history: %w", err)
+	}
+	byVersion := make(map[int]*log.Commit, len(commits))
+	for _, c := range commits {
+		byVersion[c.Version] = c
+	}
+	result := &GitResult{Target: target}
+	var pending []*log.GraphNode
+	for _, node := range log.BuildGraph(commits, false) {
+		if node.Version > state.Version {
+			pending = append(pending, node)
+		}
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	oldTip := gitOutput(target, "rev-parse", "-q", "--verify", "refs/heads/"+branch)
+	importer := exec.Command("git", "fast-import", "--quiet", "--done",
+		"--import-marks-if-exists="+filepath.Join(gitDir, marksName),
+		"--export-marks="+filepath.Join(gitDir, marksName))
+	importer.Dir = target
+	var stderr bytes.Buffer
+	importer.Stderr = &stderr
+	stdin, err := importer.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := importer.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git fast-import: %w", err)
+	}
+
+	stream := bufio.NewWriterSize(stdin, 1<<20)
+	branches := make(map[string]bool)
+	writeErr := func() error {
+		for _, node := range pending {
+			ref := branch
+			if node.Branch != "" {
+				ref = node.Branch
+			}
+			if err := em.writeGitCommit(stream, byVersion[node.Version], node.Parent, ref, result); err != nil {
+				return fmt.Errorf("v%d: %w", node.Version, err)
+			}
+			branches[ref] = true
+			if em.Progress != nil {
+				fmt.Fprintf(em.Progress, "v%d → %s\n", node.Version, ref)
+			}
+		}
+		if _, err := stream.WriteString("done\n"); err != nil {
+			return err
+		}
+		return stream.Flush()
+	}()
+	stdin.Close()
+	// Without the closing "done" fast-import aborts and leaves every ref as it was
+	waitErr := importer.Wait()
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf("git fast-import failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	result.From = pending[0].Version
+	result.To = pending[len(pending)-1].Version
+	result.Commits = len(pending)
+	for name := range branches {
+		result.Branches = append(result.Branches, name)
+	}
+	sort.Strings(result.Branches)
+
+	state = &GitState{Source: source, Version: result.To, Branch: branch, UpdatedAt: time.Now()}
+	if err := saveGitState(gitDir, state); err != nil {
+		return nil, err
+	}
+	if branches[branch] {
+		if err := updateWorkTree(target, branch, oldTip); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// writeGitCommit writes one version as a fast-import commit on ref, parented on its DGit parent's mark
+// Marks are version numbers, so later exports can build on commits written by earlier ones
+func (em *ExportManager) writeGitCommit(w *bufio.Writer, c *log.Commit, parent int, ref string, result *GitResult) error {
+	author := c.Author
+	if author == "" {
+		author = "DGit"
+	}
+	identity := fmt.Sprintf("%s <%s> %d %s", gitName(author), gitName(c.Email), c.Timestamp.Unix(), c.Timestamp.Format("-0700"))
+
+	fmt.Fprintf(w, "commit refs/heads/%s\nmark :%d\n", ref, c.Version)
+	fmt.Fprintf(w, "author %s\ncommitter %s\n", identity, identity)
+	writeData(w, []byte(c.Message))
+	if parent > 0 {
+		fmt.Fprintf(w, "from :%d\n", parent)
+	}
+
+	restoreManager := restore.NewRestoreManager(em.DgitDir)
+	root, _ := filepath.Abs(em.RootDir)
+	files := make(map[string]interface{}, len(c.Metadata))
+	paths := make([]string, 0, len(c.Metadata))
+	for stored, meta := range c.Metadata {
+		path, ok := log.RepoPath(root, stored)
+		if !ok {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("v%d:%s", c.Version, stored))
+			continue
+		}
+		files[path] = meta
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Moved files leave their previous path, in a fixed order so re-exports produce the same commits
+	var moved []string
+	for newPath, oldPath := range c.Renames {
+		if oldPath != newPath {
+			moved = append(moved, oldPath)
+		}
+	}
+	sort.Strings(moved)
+	for _, oldPath := range moved {
+		fmt.Fprintf(w, "D %s\n", gitPath(oldPath))
+	}
+	for _, path := range paths {
+		size, err := em.writeGitFile(w, restoreManager, c.Version, path)
+		if err != nil {
+			return err
+		}
+		result.Files++
+		result.Bytes += size
+	}
+
+	sidecar, err := json.MarshalIndent(&Sidecar{
+		Version:    c.Version,
+		Hash:       c.Hash,
+		ParentHash: c.ParentHash,
+		Branch:     c.Branch,
+		Message:    c.Message,
+		Author:     c.Author,
+		Email:      c.Email,
+		Timestamp:  c.Timestamp,
+		Files:      files,
+		Renames:    c.Renames,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "M 100644 inline %s\n", SidecarName)
+	writeData(w, append(sidecar, '\n'))
+	_, err = w.WriteString("\n")
+	return err
+}
+
+// writeGitFile streams one committed file into the commit as inline data
+// fast-import needs the size up front, so the file is first decoded into a temporary file
+func (em *ExportManager) writeGitFile(w *bufio.Writer, restoreManager *restore.RestoreManager, version int, path string) (int64, error) {
+	reader, err := restoreManager.OpenFile(version, path)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(em.TempDir, 0755); err != nil {
+		return 0, err
+	}
+	spool, err := os.CreateTemp(em.TempDir, "export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	size, err := io.Copy(spool, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	fmt.Fprintf(w, "M 100644 inline %s\ndata %d\n", gitPath(path), size)
+	if _, err := io.CopyN(w, spool, size); err != nil {
+		return 0, err
+	}
+	_, err = w.WriteString("\n")
+	return size, err
+}
+
+// writeData writes a fast-import data block
+func writeData(w *bufio.Writer, data []byte) {
+	fmt.Fprintf(w, "data %d\n", len(data))
+	w.Write(data)
+	w.WriteString("\n")
+}
+
+// gitPath quotes a path for fast-import when it holds characters the unquoted form cannot carry
+func gitPath(path string) string {
+	if !strings.ContainsAny(path, "\"\\\n ") && !strings.HasPrefix(path, "\"") {
+		return path
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(path) + `"`
+}
+
+// gitName removes the characters fast-import does not allow in identities
+func gitName(value string) string {
+	return strings.TrimSpace(strings.NewReplacer("<", "", ">", "", "\n", " ").Replace(value))
+}
+
+// initGitRepository creates target as a Git repository unless it already is one, returning its .git directory
+func initGitRepository(target, branch string) (string, error) {
+	gitDir := filepath.Join(target, ".git")
+	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		return gitDir, nil
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	if err := gitRun(target, "init", "-q"); err != nil {
+		return "", err
+	}
+	if err := gitRun(target, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+		return "", err
+	}
+	return gitDir, nil
+}
+
+// updateWorkTree moves the checked-out files to the new tip of branch when HEAD is on it
+// read-tree refuses rather than overwriting local changes
+func updateWorkTree(target, branch, oldTip string) error {
+	if gitOutput(target, "symbolic-ref", "-q", "HEAD") != "refs/heads/"+branch {
+		return nil
+	}
+	args := []string{"read-tree", "-u", "-m"}
+	if oldTip != "" {
+		args = append(args, oldTip)
+	}
+	if err := gitRun(target, append(args, "refs/heads/"+branch)...); err != nil {
+		return fmt.Errorf("history exported, but the work tree was not updated: %w", err)
+	}
+	return nil
+}
+
+// gitRun runs a git command in dir
+func gitRun(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output, empty on failure
+func gitOutput(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// loadGitState reads the export state of a target, empty for a repository never exported to
+func loadGitState(gitDir string) (*GitState, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, stateName))
+	if os.IsNotExist(err) {
+		return &GitState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state GitState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", stateName, err)
+	}
+	return &state, nil
+}
+
+// saveGitState records the last exported version
+func saveGitState(gitDir string, state *GitState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(gitDir, stateName), data, 0644)
+}
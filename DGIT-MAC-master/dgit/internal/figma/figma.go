@@ -0,0 +1,418 @@
+package figma
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/preview"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+)
+
+// DefaultAPIURL is the Figma REST API
+const DefaultAPIURL = "https://api.figma.com"
+
+// TokenEnv is the environment variable holding a Figma personal access token
+const TokenEnv = "FIGMA_TOKEN"
+
+// Extension is the file type Figma documents are committed as
+const Extension = ".figma"
+
+// fileURLPattern extracts the key from figma.com/file/<key>/... and figma.com/design/<key>/... URLs
+var fileURLPattern = regexp.MustCompile(`figma\.com/(?:file|design)/([A-Za-z0-9]+)`)
+
+// keyPattern matches a bare file key
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// Version is a Figma file version as listed by the API
+// Only named versions (those with a label) are synced; autosaves are skipped
+type Version struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Label       string    `json:"label"`
+	Description string    `json:"description"`
+	User        struct {
+		Handle string `json:"handle"`
+	} `json:"user"`
+}
+
+// FileState records what has been synced from one Figma file
+type FileState struct {
+	LastVersionID string         `json:"last_version_id"`
+	LastCreatedAt time.Time      `json:"last_created_at"`
+	Versions      map[string]int `json:"versions"` // Figma version ID → DGit version
+}
+
+// Result describes one file's sync pass
+type Result struct {
+	Key      string
+	Path     string
+	Pending  []*Version // Named versions not yet synced (filled on dry runs)
+	Versions []int      // DGit versions created
+	Err      error
+}
+
+// FigmaManager pulls named versions of Figma cloud files into the repository as commits
+// Synced versions are tracked in .dgit/figma-state.json
+type FigmaManager struct {
+	DgitDir   string
+	RootDir   string
+	StateFile string
+	TokenFile string
+	Client    *http.Client
+}
+
+// NewFigmaManager creates a new Figma manager for the given .dgit directory
+func NewFigmaManager(dgitDir string) *FigmaManager {
+	return &FigmaManager{
+		DgitDir:   dgitDir,
+		RootDir:   filepath.Dir(dgitDir),
+		StateFile: filepath.Join(dgitDir, "figma-state.json"),
+		TokenFile: filepath.Join(dgitDir, "keys", "figma.token"),
+		Client:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// ParseKey accepts a file key or a Figma file URL
+func ParseKey(keyOrURL string) (string, error) {
+	if match := fileURLPattern.FindStringSubmatch(keyOrURL); match != nil {
+		return match[1], nil
+	}
+	if keyPattern.MatchString(keyOrURL) {
+		return keyOrURL, nil
+	}
+	return "", fmt.Errorf("not a Figma file key or URL: %s", keyOrURL)
+}
+
+// Token returns the API token from FIGMA_TOKEN or the repository token file
+func (fm *FigmaManager) Token() (string, error) {
+	if token := strings.TrimSpace(os.Getenv(TokenEnv)); token != "" {
+		return token, nil
+	}
+	data, err := os.ReadFile(fm.TokenFile)
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("no Figma token: set %s or run 'dgit figma token'", TokenEnv)
+}
+
+// SaveToken stores the API token in the repository's keys directory
+func (fm *FigmaManager) SaveToken(token string) error {
+	if err := os.MkdirAll(filepath.Dir(fm.TokenFile), 0700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := os.WriteFile(fm.TokenFile, []byte(strings.TrimSpace(token)), 0600); err != nil {
+		return fmt.Errorf("failed to write Figma token: %w", err)
+	}
+	return nil
+}
+
+// GetFiles returns the configured Figma files
+func (fm *FigmaManager) GetFiles() ([]initializer.FigmaFile, error) {
+	config, err := initializer.GetRepositoryConfig(fm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Figma.Files, nil
+}
+
+// AddFile adds or replaces a Figma file in the repository config
+func (fm *FigmaManager) AddFile(file initializer.FigmaFile) error {
+	if file.Key == "" || file.Path == "" {
+		return fmt.Errorf("a Figma file needs a key and a repository path")
+	}
+	if !strings.HasSuffix(strings.ToLower(file.Path), Extension) {
+		file.Path += Extension
+	}
+	file.Path = filepath.ToSlash(filepath.Clean(file.Path))
+	config, err := initializer.GetRepositoryConfig(fm.DgitDir)
+	if err != nil {
+		return err
+	}
+	files := config.Figma.Files[:0]
+	for _, existing := range config.Figma.Files {
+		if existing.Key != file.Key {
+			files = append(files, existing)
+		}
+	}
+	config.Figma.Files = append(files, file)
+	return initializer.UpdateRepositoryConfig(fm.DgitDir, config)
+}
+
+// RemoveFile deletes a Figma file from the repository config; synced history stays
+func (fm *FigmaManager) RemoveFile(key string) error {
+	config, err := initializer.GetRepositoryConfig(fm.DgitDir)
+	if err != nil {
+		return err
+	}
+	for i, file := range config.Figma.Files {
+		if file.Key == key {
+			config.Figma.Files = append(config.Figma.Files[:i], config.Figma.Files[i+1:]...)
+			return initializer.UpdateRepositoryConfig(fm.DgitDir, config)
+		}
+	}
+	return fmt.Errorf("no Figma file with key %s", key)
+}
+
+// Sync commits every named version created since the last sync, oldest first
+// The working directory must be the repository root so staged paths are recorded relative to it
+func (fm *FigmaManager) Sync(dryRun bool) ([]*Result, error) {
+	config, err := initializer.GetRepositoryConfig(fm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Figma.Files) == 0 {
+		return nil, nil
+	}
+	token, err := fm.Token()
+	if err != nil {
+		return nil, err
+	}
+	state, err := fm.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	client := &apiClient{base: strings.TrimRight(config.Figma.APIURL, "/"), token: token, http: fm.Client}
+	if client.base == "" {
+		client.base = DefaultAPIURL
+	}
+
+	var results []*Result
+	for _, file := range config.Figma.Files {
+		if state[file.Key] == nil {
+			state[file.Key] = &FileState{Versions: make(map[string]int)}
+		}
+		result := fm.syncFile(client, file, state[file.Key], dryRun)
+		results = append(results, result)
+		if !dryRun && len(result.Versions) > 0 {
+			if err := fm.saveState(state); err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// syncFile pulls the new named versions of one file
+func (fm *FigmaManager) syncFile(client *apiClient, file initializer.FigmaFile, state *FileState, dryRun bool) *Result {
+	result := &Result{Key: file.Key, Path: file.Path}
+
+	versions, err := client.namedVersionsSince(file.Key, state)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if dryRun {
+		result.Pending = versions
+		return result
+	}
+
+	target := filepath.Join(fm.RootDir, filepath.FromSlash(file.Path))
+	for _, version := range versions {
+		document, err := client.get(fmt.Sprintf("/v1/files/%s?version=%s", file.Key, url.QueryEscape(version.ID)))
+		if err != nil {
+			result.Err = fmt.Errorf("failed to download version %s: %w", version.Label, err)
+			return result
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			result.Err = fmt.Errorf("failed to create %s: %w", filepath.Dir(file.Path), err)
+			return result
+		}
+		if err := os.WriteFile(target, document, 0644); err != nil {
+			result.Err = fmt.Errorf("failed to write %s: %w", file.Path, err)
+			return result
+		}
+
+		stagingArea := staging.NewStagingArea(fm.DgitDir)
+		if err := stagingArea.AddFile(target); err != nil {
+			result.Err = fmt.Errorf("failed to stage %s: %w", file.Path, err)
+			return result
+		}
+		manager := commit.NewCommitManager(fm.DgitDir)
+		manager.Author = version.User.Handle
+		newCommit, err := manager.CreateCommit(versionMessage(version), stagingArea.GetStagedFiles())
+		if err != nil {
+			result.Err = fmt.Errorf("failed to commit version %s: %w", version.Label, err)
+			return result
+		}
+		result.Versions = append(result.Versions, newCommit.Version)
+
+		// A missing preview never fails the sync; the document is what matters
+		if frame := firstFrame(document); frame != "" {
+			if image, err := client.renderFrame(file.Key, version.ID, frame); err == nil {
+				preview.NewPreviewManager(fm.DgitDir).Store(newCommit.Version, file.Path, "png", image)
+			}
+		}
+
+		state.LastVersionID = version.ID
+		state.LastCreatedAt = version.CreatedAt
+		state.Versions[version.ID] = newCommit.Version
+	}
+	return result
+}
+
+// versionMessage uses the Figma version label and description as the commit message
+func versionMessage(version *Version) string {
+	message := version.Label
+	if description := strings.TrimSpace(version.Description); description != "" {
+		message += ": " + description
+	}
+	return message
+}
+
+// firstFrame returns the ID of the first top-level frame on the first page
+func firstFrame(document []byte) string {
+	var doc struct {
+		Document struct {
+			Children []struct {
+				Children []struct {
+					ID   string `json:"id"`
+					Type string `json:"type"`
+				} `json:"children"`
+			} `json:"children"`
+		} `json:"document"`
+	}
+	if json.Unmarshal(document, &doc) != nil {
+		return ""
+	}
+	for _, page := range doc.Document.Children {
+		for _, node := range page.Children {
+			if node.Type == "FRAME" || node.Type == "COMPONENT" || node.Type == "SECTION" {
+				return node.ID
+			}
+		}
+	}
+	return ""
+}
+
+// LoadState reads what has been synced per file key
+func (fm *FigmaManager) LoadState() (map[string]*FileState, error) {
+	state := make(map[string]*FileState)
+	data, err := os.ReadFile(fm.StateFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Figma state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse Figma state: %w", err)
+	}
+	for _, fileState := range state {
+		if fileState.Versions == nil {
+			fileState.Versions = make(map[string]int)
+		}
+	}
+	return state, nil
+}
+
+// saveState writes the sync state to disk
+func (fm *FigmaManager) saveState(state map[string]*FileState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Figma state: %w", err)
+	}
+	if err := os.WriteFile(fm.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write Figma state: %w", err)
+	}
+	return nil
+}
+
+// apiClient is a minimal client for the Figma REST API
+type apiClient struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// get fetches an API path and returns the body
+func (c *apiClient) get(path string) ([]byte, error) {
+	target := path
+	if strings.HasPrefix(path, "/") {
+		target = c.base + path
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(target, c.base) {
+		req.Header.Set("X-Figma-Token", c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("figma API returned %s", resp.Status)
+	}
+	return body, nil
+}
+
+// namedVersionsSince lists named versions newer than the last synced one, oldest first
+// The API pages newest first, so paging stops at the first version already synced
+func (c *apiClient) namedVersionsSince(key string, state *FileState) ([]*Version, error) {
+	var pending []*Version
+	next := fmt.Sprintf("/v1/files/%s/versions", key)
+	for next != "" {
+		body, err := c.get(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions: %w", err)
+		}
+		var page struct {
+			Versions   []*Version `json:"versions"`
+			Pagination struct {
+				NextPage string `json:"next_page"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse versions: %w", err)
+		}
+		next = page.Pagination.NextPage
+		for _, version := range page.Versions {
+			if _, synced := state.Versions[version.ID]; synced || !version.CreatedAt.After(state.LastCreatedAt) {
+				next = ""
+				break
+			}
+			if strings.TrimSpace(version.Label) != "" {
+				pending = append(pending, version)
+			}
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, nil
+}
+
+// renderFrame exports one frame of a version as PNG
+func (c *apiClient) renderFrame(key, versionID, frameID string) ([]byte, error) {
+	body, err := c.get(fmt.Sprintf("/v1/images/%s?ids=%s&format=png&version=%s",
+		key, url.QueryEscape(frameID), url.QueryEscape(versionID)))
+	if err != nil {
+		return nil, err
+	}
+	var images struct {
+		Images map[string]string `json:"images"`
+	}
+	if err := json.Unmarshal(body, &images); err != nil {
+		return nil, fmt.Errorf("failed to parse image export: %w", err)
+	}
+	imageURL := images.Images[frameID]
+	if imageURL == "" {
+		return nil, fmt.Errorf("frame %s was not rendered", frameID)
+	}
+	return c.get(imageURL)
+}
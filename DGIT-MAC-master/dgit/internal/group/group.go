@@ -0,0 +1,252 @@
+package group
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Group is a logical asset made of several files or folders (e.g. "brand-kit" = logo.ai + palette.ase + fonts/)
+// Members are paths relative to the working tree root; a trailing slash marks a folder
+type Group struct {
+	Name        string   `json:"name"`
+	Members     []string `json:"members"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Contains reports whether a repository path belongs to the group
+func (g *Group) Contains(path string) bool {
+	path = filepath.ToSlash(path)
+	for _, member := range g.Members {
+		if strings.HasSuffix(member, "/") {
+			if strings.HasPrefix(path, member) {
+				return true
+			}
+		} else if path == member {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the paths that belong to the group, sorted
+func (g *Group) Filter(paths []string) []string {
+	var members []string
+	for _, path := range paths {
+		if g.Contains(path) {
+			members = append(members, path)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// GroupManager manages logical asset definitions for a DGit repository
+type GroupManager struct {
+	DgitDir    string
+	RootDir    string
+	GroupsFile string
+}
+
+// NewGroupManager creates a new group manager for the given .dgit directory
+func NewGroupManager(dgitDir string) *GroupManager {
+	return &GroupManager{
+		DgitDir:    dgitDir,
+		RootDir:    filepath.Dir(dgitDir),
+		GroupsFile: filepath.Join(dgitDir, "groups.json"),
+	}
+}
+
+// GetGroups returns all defined groups sorted by name
+func (gm *GroupManager) GetGroups() ([]*Group, error) {
+	groups := []*Group{}
+
+	data, err := os.ReadFile(gm.GroupsFile)
+	if os.IsNotExist(err) {
+		return groups, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read groups file: %w", err)
+	}
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse groups file: %w", err)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+// GetGroup returns a group by name, or nil if none is defined
+func (gm *GroupManager) GetGroup(name string) (*Group, error) {
+	groups, err := gm.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return g, nil
+		}
+	}
+	return nil, nil
+}
+
+// saveGroups writes the group definitions to disk
+func (gm *GroupManager) saveGroups(groups []*Group) error {
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal groups: %w", err)
+	}
+	if err := os.WriteFile(gm.GroupsFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write groups file: %w", err)
+	}
+	return nil
+}
+
+// Define creates a group or adds members to an existing one
+// Members may be files or folders and are stored relative to the working tree root
+func (gm *GroupManager) Define(name string, members []string, description string) (*Group, error) {
+	if name == "" || strings.ContainsAny(name, `/\*?[`) {
+		return nil, fmt.Errorf("invalid group name %q", name)
+	}
+	groups, err := gm.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var g *Group
+	for _, existing := range groups {
+		if existing.Name == name {
+			g = existing
+		}
+	}
+	if g == nil {
+		g = &Group{Name: name}
+		groups = append(groups, g)
+	}
+	if description != "" {
+		g.Description = description
+	}
+
+	for _, member := range members {
+		rel, err := gm.relativeToRoot(member)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(filepath.Join(gm.RootDir, filepath.FromSlash(rel))); (err == nil && info.IsDir()) ||
+			strings.HasSuffix(member, "/") {
+			rel += "/"
+		}
+		if !containsString(g.Members, rel) {
+			g.Members = append(g.Members, rel)
+		}
+	}
+	sort.Strings(g.Members)
+
+	if err := gm.saveGroups(groups); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Remove deletes members from a group, or the whole group when no members are given (files are not touched)
+func (gm *GroupManager) Remove(name string, members []string) error {
+	groups, err := gm.GetGroups()
+	if err != nil {
+		return err
+	}
+	for i, g := range groups {
+		if g.Name != name {
+			continue
+		}
+		if len(members) == 0 {
+			return gm.saveGroups(append(groups[:i], groups[i+1:]...))
+		}
+		for _, member := range members {
+			rel, err := gm.relativeToRoot(member)
+			if err != nil {
+				return err
+			}
+			kept := g.Members[:0]
+			for _, existing := range g.Members {
+				if existing != rel && existing != rel+"/" {
+					kept = append(kept, existing)
+				}
+			}
+			if len(kept) == len(g.Members) {
+				return fmt.Errorf("%s is not a member of %s", member, name)
+			}
+			g.Members = kept
+		}
+		return gm.saveGroups(groups)
+	}
+	return fmt.Errorf("no group named %s", name)
+}
+
+// Assignment is a group together with the paths of a file list that belong to it
+type Assignment struct {
+	Group *Group
+	Paths []string
+}
+
+// Assign matches paths against every group, skipping groups with no members in the list
+func (gm *GroupManager) Assign(paths []string) ([]*Assignment, error) {
+	groups, err := gm.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	var assigned []*Assignment
+	for _, g := range groups {
+		if members := g.Filter(paths); len(members) > 0 {
+			assigned = append(assigned, &Assignment{Group: g, Paths: members})
+		}
+	}
+	return assigned, nil
+}
+
+// RenamePath points group members at a file's new location after a move
+// Returns the names of the groups that were updated
+func (gm *GroupManager) RenamePath(oldPath, newPath string) ([]string, error) {
+	groups, err := gm.GetGroups()
+	if err != nil {
+		return nil, err
+	}
+	var updated []string
+	for _, g := range groups {
+		for i, member := range g.Members {
+			if member == oldPath {
+				g.Members[i] = newPath
+				updated = append(updated, g.Name)
+			}
+		}
+	}
+	if len(updated) == 0 {
+		return nil, nil
+	}
+	return updated, gm.saveGroups(groups)
+}
+
+// relativeToRoot converts a path to a slash-separated path relative to the working tree root
+func (gm *GroupManager) relativeToRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(gm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("group member must be inside the repository: %s", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// containsString checks whether a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
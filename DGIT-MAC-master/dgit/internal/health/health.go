@@ -0,0 +1,195 @@
+package health
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/trash"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/verify"
+)
+
+// VerifyMaxAge is how old the last verify run may be before health warns about it
+const VerifyMaxAge = 14 * 24 * time.Hour
+
+// Levels of a health check
+const (
+	LevelOK   = "ok"
+	LevelWarn = "warn"
+	LevelFail = "fail"
+	LevelInfo = "info" // Worth knowing, nothing to fix
+)
+
+// Check is one item of the health summary
+type Check struct {
+	Name   string
+	Level  string
+	Detail string // Short text for the one-line summary, e.g. "last verify 2d ago"
+}
+
+// Report is the repository health summary shown by 'dgit status'
+type Report struct {
+	Checks      []Check
+	CacheSizes  map[string]int64 // Tier → bytes on disk
+	Reclaimable int64            // Trash plus storage objects no commit or staged file refers to
+}
+
+// HealthManager computes a quick health summary from cache, verify and trash state
+// Everything is read from local metadata so status stays fast
+type HealthManager struct {
+	DgitDir  string
+	CacheDir string
+}
+
+// NewHealthManager creates a new health manager for the given .dgit directory
+func NewHealthManager(dgitDir string) *HealthManager {
+	return &HealthManager{
+		DgitDir:  dgitDir,
+		CacheDir: filepath.Join(dgitDir, "cache"),
+	}
+}
+
+// Check computes the health report
+func (hm *HealthManager) Check(now time.Time) *Report {
+	report := &Report{CacheSizes: make(map[string]int64)}
+	report.Checks = append(report.Checks, hm.checkCache(report))
+	report.Checks = append(report.Checks, hm.checkVerify(now))
+	report.Checks = append(report.Checks, hm.checkReclaimable(report))
+	return report
+}
+
+// Healthy reports whether no check warns or fails
+func (r *Report) Healthy() bool {
+	for _, check := range r.Checks {
+		if check.Level == LevelWarn || check.Level == LevelFail {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCache compares each cache tier with its configured size limit
+func (hm *HealthManager) checkCache(report *Report) Check {
+	check := Check{Name: "cache", Level: LevelOK, Detail: "cache within limits"}
+	config, err := initializer.GetRepositoryConfig(hm.DgitDir)
+	if err != nil {
+		return Check{Name: "cache", Level: LevelWarn, Detail: "config unreadable"}
+	}
+	limits := map[string]int64{
+		"hot":  config.Compression.CacheConfig.HotCacheSize,
+		"warm": config.Compression.CacheConfig.WarmCacheSize,
+		"cold": config.Compression.CacheConfig.ColdStorageSize,
+	}
+	for _, tier := range []string{"hot", "warm", "cold"} {
+		size := util.DirSize(filepath.Join(hm.CacheDir, tier))
+		report.CacheSizes[tier] = size
+		limit := limits[tier] * 1024 * 1024
+		if limit > 0 && size > limit {
+			check.Level = LevelWarn
+			check.Detail = fmt.Sprintf("%s cache over limit (%d%%)", tier, size*100/limit)
+			break
+		}
+	}
+	return check
+}
+
+// checkVerify reports when integrity was last verified and whether it passed
+func (hm *HealthManager) checkVerify(now time.Time) Check {
+	check := Check{Name: "verify"}
+	record, err := verify.NewVerifyManager(hm.DgitDir).LastRun()
+	switch {
+	case err != nil:
+		check.Level, check.Detail = LevelWarn, "verify record unreadable"
+	case record == nil:
+		if log.NewLogManager(hm.DgitDir).GetCurrentVersion() == 0 {
+			check.Level, check.Detail = LevelOK, "nothing to verify yet"
+		} else {
+			check.Level, check.Detail = LevelWarn, "never verified"
+		}
+	case record.Failed > 0:
+		check.Level = LevelFail
+		check.Detail = fmt.Sprintf("last verify failed %s ago (%d version(s))", formatAge(now.Sub(record.Time)), record.Failed)
+	case now.Sub(record.Time) > VerifyMaxAge:
+		check.Level, check.Detail = LevelWarn, fmt.Sprintf("last verify %s ago", formatAge(now.Sub(record.Time)))
+	default:
+		check.Level, check.Detail = LevelOK, fmt.Sprintf("last verify %s ago", formatAge(now.Sub(record.Time)))
+	}
+	return check
+}
+
+// storageVersionPattern matches storage object names such as v12.lz4 or v3_delta.bin
+var storageVersionPattern = regexp.MustCompile(`^v(\d+)[._]`)
+
+// checkReclaimable sums trash and storage objects that nothing refers to any more
+func (hm *HealthManager) checkReclaimable(report *Report) Check {
+	if entries, err := trash.NewTrashManager(hm.DgitDir).List(); err == nil {
+		for _, entry := range entries {
+			report.Reclaimable += entry.Size
+		}
+	}
+
+	versions := make(map[int]bool)
+	if commits, err := log.NewLogManager(hm.DgitDir).GetCommitHistory(); err == nil {
+		for _, c := range commits {
+			versions[c.Version] = true
+		}
+	}
+	staged := make(map[string]bool)
+	stagingArea := staging.NewStagingArea(hm.DgitDir)
+	if err := stagingArea.LoadStaging(); err == nil {
+		for _, file := range stagingArea.GetStagedFiles() {
+			staged[file.Hash] = true
+		}
+	}
+
+	for _, dir := range []string{
+		filepath.Join(hm.CacheDir, "hot"),
+		filepath.Join(hm.CacheDir, "warm"),
+		filepath.Join(hm.CacheDir, "cold"),
+		filepath.Join(hm.DgitDir, "objects"),
+		filepath.Join(hm.DgitDir, "objects", "deltas"),
+	} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) == ".json" || staged[entry.Name()] {
+				continue
+			}
+			if match := storageVersionPattern.FindStringSubmatch(entry.Name()); match != nil {
+				if version, _ := strconv.Atoi(match[1]); versions[version] {
+					continue
+				}
+			}
+			if info, err := entry.Info(); err == nil {
+				report.Reclaimable += info.Size()
+			}
+		}
+	}
+
+	if report.Reclaimable == 0 {
+		return Check{Name: "reclaimable", Level: LevelOK, Detail: "nothing to reclaim"}
+	}
+	return Check{Name: "reclaimable", Level: LevelInfo, Detail: util.FormatBytes(report.Reclaimable) + " reclaimable"}
+}
+
+// formatAge renders a duration as a compact age such as "5m", "3h" or "2d"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,241 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Installed records a preset installed into a repository and the parameters it was given
+type Installed struct {
+	Name        string            `json:"name"`
+	Hook        string            `json:"hook,omitempty"`
+	Params      map[string]string `json:"params"`
+	Files       []string          `json:"files,omitempty"`    // Scripts written, relative to the hooks directory
+	Previous    string            `json:"previous,omitempty"` // Policy value replaced by the install
+	InstalledAt time.Time         `json:"installed_at"`
+}
+
+// HookManager installs and removes hook presets for a DGit repository
+type HookManager struct {
+	DgitDir     string
+	HooksDir    string
+	PresetsFile string
+}
+
+// NewHookManager creates a new hook manager for the given .dgit directory
+func NewHookManager(dgitDir string) *HookManager {
+	hooksDir := filepath.Join(dgitDir, "hooks")
+	return &HookManager{
+		DgitDir:     dgitDir,
+		HooksDir:    hooksDir,
+		PresetsFile: filepath.Join(hooksDir, "presets.json"),
+	}
+}
+
+// GetInstalled returns the installed presets sorted by name
+func (hm *HookManager) GetInstalled() ([]*Installed, error) {
+	installed := []*Installed{}
+
+	data, err := os.ReadFile(hm.PresetsFile)
+	if os.IsNotExist(err) {
+		return installed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read installed presets: %w", err)
+	}
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("failed to parse installed presets: %w", err)
+	}
+
+	sort.Slice(installed, func(i, j int) bool { return installed[i].Name < installed[j].Name })
+	return installed, nil
+}
+
+// Install writes a preset's script or policy with the given parameter values
+// Reinstalling a preset replaces its previous parameters
+func (hm *HookManager) Install(name string, values map[string]string) (*Installed, error) {
+	preset := FindPreset(name)
+	if preset == nil {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+	params, err := resolveParams(preset, values)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := hm.GetInstalled()
+	if err != nil {
+		return nil, err
+	}
+	var kept []*Installed
+	for _, existing := range installed {
+		if existing.Name == name {
+			if err := hm.remove(existing); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		kept = append(kept, existing)
+	}
+
+	record := &Installed{Name: name, Hook: preset.Hook, Params: params, InstalledAt: time.Now()}
+	if preset.Policy {
+		if record.Previous, err = hm.setPolicy(params["action"], params["state"]); err != nil {
+			return nil, err
+		}
+	} else {
+		script := preset.Script
+		for key, value := range params {
+			script = strings.ReplaceAll(script, "{{"+key+"}}", value)
+		}
+		rel := filepath.Join(preset.Hook+".d", preset.Name)
+		path := filepath.Join(hm.HooksDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create hook directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return nil, fmt.Errorf("failed to write hook script: %w", err)
+		}
+		record.Files = []string{filepath.ToSlash(rel)}
+	}
+
+	if err := hm.saveInstalled(append(kept, record)); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Uninstall removes a preset's scripts or restores the policy it replaced
+func (hm *HookManager) Uninstall(name string) error {
+	installed, err := hm.GetInstalled()
+	if err != nil {
+		return err
+	}
+	for i, existing := range installed {
+		if existing.Name != name {
+			continue
+		}
+		if err := hm.remove(existing); err != nil {
+			return err
+		}
+		return hm.saveInstalled(append(installed[:i], installed[i+1:]...))
+	}
+	return fmt.Errorf("preset %s is not installed", name)
+}
+
+// remove deletes the files or policy change of an installed preset
+func (hm *HookManager) remove(record *Installed) error {
+	for _, rel := range record.Files {
+		if err := os.Remove(filepath.Join(hm.HooksDir, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", rel, err)
+		}
+	}
+	if preset := FindPreset(record.Name); preset != nil && preset.Policy {
+		config, err := initializer.GetRepositoryConfig(hm.DgitDir)
+		if err != nil {
+			return err
+		}
+		action := record.Params["action"]
+		// Leave the policy alone if it was edited by hand after the install
+		if config.Approval.RequiredStates[action] == record.Params["state"] {
+			if record.Previous == "" {
+				delete(config.Approval.RequiredStates, action)
+			} else {
+				config.Approval.RequiredStates[action] = record.Previous
+			}
+			return initializer.UpdateRepositoryConfig(hm.DgitDir, config)
+		}
+	}
+	return nil
+}
+
+// setPolicy requires a state for an action and returns the value it replaced
+func (hm *HookManager) setPolicy(action, state string) (string, error) {
+	config, err := initializer.GetRepositoryConfig(hm.DgitDir)
+	if err != nil {
+		return "", err
+	}
+	if config.Approval.RequiredStates == nil {
+		config.Approval.RequiredStates = make(map[string]string)
+	}
+	previous := config.Approval.RequiredStates[action]
+	config.Approval.RequiredStates[action] = state
+	if err := initializer.UpdateRepositoryConfig(hm.DgitDir, config); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+// saveInstalled writes the installed preset registry to disk
+func (hm *HookManager) saveInstalled(installed []*Installed) error {
+	if err := os.MkdirAll(hm.HooksDir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	data, err := json.MarshalIndent(installed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal installed presets: %w", err)
+	}
+	if err := os.WriteFile(hm.PresetsFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write installed presets: %w", err)
+	}
+	return nil
+}
+
+var intPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// resolveParams applies defaults and validates values before they are written into scripts
+// Values end up inside single-quoted shell strings, so quotes and newlines are never allowed
+func resolveParams(preset *Preset, values map[string]string) (map[string]string, error) {
+	known := make(map[string]bool)
+	params := make(map[string]string)
+	for _, param := range preset.Params {
+		known[param.Name] = true
+		value, ok := values[param.Name]
+		if !ok {
+			value = param.Default
+		}
+		if value == "" {
+			if param.Required {
+				return nil, fmt.Errorf("%s requires --set %s=<value> (%s)", preset.Name, param.Name, param.Description)
+			}
+			params[param.Name] = ""
+			continue
+		}
+		if strings.ContainsAny(value, "'\"\\\n\r`$") {
+			return nil, fmt.Errorf("%s: quotes, backslashes, '$' and newlines are not allowed", param.Name)
+		}
+		switch param.Kind {
+		case KindInt:
+			if !intPattern.MatchString(value) {
+				return nil, fmt.Errorf("%s must be a whole number, got %q", param.Name, value)
+			}
+		case KindURL:
+			if !strings.HasPrefix(value, "https://") {
+				return nil, fmt.Errorf("%s must be an https:// URL", param.Name)
+			}
+		case KindState:
+			if !approval.IsValidState(value) {
+				return nil, fmt.Errorf("%s must be one of: %s", param.Name, strings.Join(approval.States, ", "))
+			}
+		}
+		params[param.Name] = value
+	}
+	for name := range values {
+		if !known[name] {
+			return nil, fmt.Errorf("%s has no parameter %q", preset.Name, name)
+		}
+	}
+	if preset.Policy && params["action"] != "deliver" && params["action"] != "export" {
+		return nil, fmt.Errorf("action must be deliver or export, got %q", params["action"])
+	}
+	return params, nil
+}
@@ -0,0 +1,116 @@
+package hooks
+
+// Hook scripts run from the working tree root with these environment variables:
+//   DGIT_DIR          the .dgit directory
+//   DGIT_HOOK         the hook name, e.g. pre-commit
+//   DGIT_VERSION      the version being committed or restored
+//   DGIT_HASH         the commit hash (post-commit and pre-restore)
+//   DGIT_MESSAGE      the commit message
+//   DGIT_MESSAGE_FILE the message to edit in place (commit-msg)
+//   DGIT_FILES        newline-separated file paths relative to the root
+//   DGIT_BLOB         the cold-tier object (on-archive and on-recall)
+// A non-zero exit from a pre-* hook aborts the operation.
+//
+// on-archive runs after a version is written to the cold tier and may move DGIT_BLOB
+// off to tape or an HSM; on-recall must bring it back to that path, and may return
+// before it arrives, since restores wait for the file to reappear.
+
+// Param kinds accepted by presets; values are validated before they are written into scripts
+const (
+	KindInt    = "int"
+	KindURL    = "url"
+	KindString = "string"
+	KindState  = "state"
+)
+
+// PresetParam is a value a studio sets when installing a preset
+type PresetParam struct {
+	Name        string
+	Description string
+	Kind        string
+	Default     string // Empty with Required=false means "not set"
+	Required    bool
+}
+
+// Preset is a vetted hook script or policy shipped with DGit
+// Script presets are written to hooks/<hook>.d/<name>; {{param}} placeholders are replaced on install
+type Preset struct {
+	Name        string
+	Description string
+	Hook        string // Hook the script runs at; empty for policy-only presets
+	Params      []PresetParam
+	Script      string
+	Policy      bool // Installs approval policy into the repository config instead of a script
+}
+
+// Presets are the hook presets available to 'dgit hooks install'
+var Presets = []*Preset{
+	{
+		Name:        "size-guard",
+		Description: "Reject commits that contain files larger than a limit",
+		Hook:        "pre-commit",
+		Params: []PresetParam{
+			{Name: "max_mb", Description: "Largest allowed file in megabytes", Kind: KindInt, Default: "500"},
+		},
+		Script: `#!/bin/sh
+# dgit preset: size-guard
+# Rejects commits containing files larger than {{max_mb}} MB
+limit=$(({{max_mb}} * 1024 * 1024))
+status=0
+while IFS= read -r file; do
+	[ -f "$file" ] || continue
+	size=$(wc -c < "$file" | tr -d ' ')
+	if [ "$size" -gt "$limit" ]; then
+		echo "size-guard: $file is $(((size + 1048575) / 1048576)) MB (limit {{max_mb}} MB)" >&2
+		status=1
+	fi
+done <<EOF
+$DGIT_FILES
+EOF
+exit $status
+`,
+	},
+	{
+		Name:        "notify-slack",
+		Description: "Post each new version to a Slack incoming webhook",
+		Hook:        "post-commit",
+		Params: []PresetParam{
+			{Name: "webhook_url", Description: "Slack incoming webhook URL", Kind: KindURL, Required: true},
+			{Name: "channel", Description: "Channel override, e.g. #design-reviews", Kind: KindString},
+		},
+		Script: `#!/bin/sh
+# dgit preset: notify-slack
+# Posts new versions to Slack; a failed notification never fails the commit
+text="$(basename "$PWD") v$DGIT_VERSION ($DGIT_HASH): $DGIT_MESSAGE"
+escaped=$(printf '%s' "$text" | sed 's/\\/\\\\/g; s/"/\\"/g' | tr '\n' ' ')
+channel='{{channel}}'
+if [ -n "$channel" ]; then
+	payload="{\"channel\": \"$channel\", \"text\": \"$escaped\"}"
+else
+	payload="{\"text\": \"$escaped\"}"
+fi
+curl -fsS -m 10 -X POST -H 'Content-Type: application/json' --data "$payload" '{{webhook_url}}' >/dev/null \
+	|| echo "notify-slack: notification failed" >&2
+exit 0
+`,
+	},
+	{
+		Name:        "require-approval",
+		Description: "Block an action until the version reaches an approval state",
+		Policy:      true,
+		Params: []PresetParam{
+			{Name: "action", Description: "Action to guard: deliver or export", Kind: KindString, Default: "deliver"},
+			{Name: "state", Description: "State the version must reach", Kind: KindState, Default: "approved"},
+		},
+	},
+}
+
+// FindPreset returns the preset with the given name, or nil
+func FindPreset(name string) *Preset {
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package hooks_test
+
+import (
+	"testing"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/testutil"
+)
+
+// TestSizeGuardRejectsLargeFiles installs the size-guard preset and expects commit to enforce it
+func TestSizeGuardRejectsLargeFiles(t *testing.T) {
+	repo, err := testutil.NewRepo(t.TempDir())
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := hooks.NewHookManager(repo.DgitDir).Install("size-guard", map[string]string{"max_mb": "1"}); err != nil {
+		t.Fatalf("install size-guard: %v", err)
+	}
+
+	if err := repo.WriteFile("large.psd", 2*1024*1024, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("too large", "large.psd"); err == nil {
+		t.Fatalf("commit of a 2 MB file passed a 1 MB size-guard")
+	}
+
+	if err := repo.WriteFile("small.psd", 4096, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit("small", "small.psd"); err != nil {
+		t.Fatalf("commit under the limit: %v", err)
+	}
+}
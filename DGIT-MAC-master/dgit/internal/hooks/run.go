@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// Scripts returns the executables run for a hook: hooks/<hook> then hooks/<hook>.d/* in name order
+func Scripts(dgitDir, hook string) []string {
+	hooksDir := filepath.Join(dgitDir, "hooks")
+	var scripts []string
+	if isExecutable(filepath.Join(hooksDir, hook)) {
+		scripts = append(scripts, filepath.Join(hooksDir, hook))
+	}
+	entries, _ := os.ReadDir(filepath.Join(hooksDir, hook+".d"))
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(hooksDir, hook+".d", name)
+		if isExecutable(path) {
+			scripts = append(scripts, path)
+		}
+	}
+	return scripts
+}
+
+// Run executes a hook's scripts from the working tree root with DGIT_DIR, DGIT_HOOK and env set
+// The first script that exits non-zero stops the run
+func Run(dgitDir, hook string, env map[string]string) error {
+	for _, script := range Scripts(dgitDir, hook) {
+		cmd := exec.Command(script)
+		cmd.Dir = filepath.Dir(dgitDir)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), "DGIT_DIR="+dgitDir, "DGIT_HOOK="+hook)
+		for key, value := range env {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %s failed: %w", hook, filepath.Base(script), err)
+		}
+	}
+	return nil
+}
+
+// isExecutable reports whether path is a regular file with an execute bit set
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
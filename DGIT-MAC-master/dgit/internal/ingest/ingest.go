@@ -0,0 +1,300 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/autocommit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// DefaultSettleSeconds is how long a file must stay unchanged before it is ingested
+const DefaultSettleSeconds = 10
+
+// DefaultMessage is the commit message template used when a rule does not set one
+const DefaultMessage = "Ingest {count} file(s) from {rule}"
+
+// seenFile identifies a version of a watched file that has already been ingested
+type seenFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Result describes one rule's pass over its watch folder
+type Result struct {
+	Rule    string
+	Files   []string // Repository paths copied in
+	Pending int      // Files still being written (not settled yet)
+	Held    []string // Settled files the auto-commit policy holds back, with the reason
+	Version int      // Commit created, 0 if none
+	Err     error
+}
+
+// IngestManager copies finished pipeline outputs from watch folders into the repository and commits them
+// Which source files were already ingested is tracked in .dgit/ingest-state.json
+type IngestManager struct {
+	DgitDir      string
+	RootDir      string
+	StateFile    string
+	IgnorePolicy bool // Commit settled files regardless of the auto-commit policy
+}
+
+// NewIngestManager creates a new ingest manager for the given .dgit directory
+func NewIngestManager(dgitDir string) *IngestManager {
+	return &IngestManager{
+		DgitDir:   dgitDir,
+		RootDir:   filepath.Dir(dgitDir),
+		StateFile: filepath.Join(dgitDir, "ingest-state.json"),
+	}
+}
+
+// GetRules returns the configured ingest rules
+func (im *IngestManager) GetRules() ([]initializer.IngestRule, error) {
+	config, err := initializer.GetRepositoryConfig(im.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Ingest.Rules, nil
+}
+
+// AddRule adds or replaces a rule in the repository config
+func (im *IngestManager) AddRule(rule initializer.IngestRule) error {
+	if rule.Name == "" || rule.Watch == "" {
+		return fmt.Errorf("an ingest rule needs a name and a watch folder")
+	}
+	if info, err := os.Stat(rule.Watch); err != nil || !info.IsDir() {
+		return fmt.Errorf("watch folder not found: %s", rule.Watch)
+	}
+	config, err := initializer.GetRepositoryConfig(im.DgitDir)
+	if err != nil {
+		return err
+	}
+	rules := config.Ingest.Rules[:0]
+	for _, existing := range config.Ingest.Rules {
+		if existing.Name != rule.Name {
+			rules = append(rules, existing)
+		}
+	}
+	config.Ingest.Rules = append(rules, rule)
+	return initializer.UpdateRepositoryConfig(im.DgitDir, config)
+}
+
+// RemoveRule deletes a rule from the repository config
+func (im *IngestManager) RemoveRule(name string) error {
+	config, err := initializer.GetRepositoryConfig(im.DgitDir)
+	if err != nil {
+		return err
+	}
+	for i, rule := range config.Ingest.Rules {
+		if rule.Name == name {
+			config.Ingest.Rules = append(config.Ingest.Rules[:i], config.Ingest.Rules[i+1:]...)
+			return initializer.UpdateRepositoryConfig(im.DgitDir, config)
+		}
+	}
+	return fmt.Errorf("no ingest rule named %s", name)
+}
+
+// Process runs every rule once, creating one commit per rule that found settled new files
+// The working directory must be the repository root so staged paths are recorded relative to it
+func (im *IngestManager) Process(dryRun bool) ([]*Result, error) {
+	rules, err := im.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	state, err := im.loadState()
+	if err != nil {
+		return nil, err
+	}
+	var pass *autocommit.Pass
+	if !im.IgnorePolicy {
+		if pass, err = autocommit.NewPolicyManager(im.DgitDir).Begin(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	var results []*Result
+	for _, rule := range rules {
+		if state[rule.Name] == nil {
+			state[rule.Name] = make(map[string]seenFile)
+		}
+		result := im.processRule(rule, state[rule.Name], pass, dryRun)
+		results = append(results, result)
+	}
+
+	if !dryRun {
+		if err := im.saveState(state); err != nil {
+			return results, err
+		}
+		if pass != nil {
+			if err := pass.Save(); err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// processRule ingests one watch folder
+// Files the policy holds back are not marked as ingested, so a later pass commits their latest content
+func (im *IngestManager) processRule(rule initializer.IngestRule, seen map[string]seenFile, pass *autocommit.Pass, dryRun bool) *Result {
+	result := &Result{Rule: rule.Name}
+	settle := time.Duration(rule.SettleSeconds) * time.Second
+	if rule.SettleSeconds == 0 {
+		settle = DefaultSettleSeconds * time.Second
+	}
+
+	type candidate struct {
+		source, rel string
+		info        os.FileInfo
+	}
+	var candidates []candidate
+	err := filepath.Walk(rule.Watch, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(rule.Watch, path)
+		if !matchesRule(rule, path) {
+			return nil
+		}
+		if prev, ok := seen[rel]; ok && prev.Size == info.Size() && prev.ModTime.Equal(info.ModTime()) {
+			return nil
+		}
+		if time.Since(info.ModTime()) < settle {
+			result.Pending++
+			return nil
+		}
+		candidates = append(candidates, candidate{source: path, rel: rel, info: info})
+		return nil
+	})
+	if err != nil {
+		result.Err = fmt.Errorf("failed to scan %s: %w", rule.Watch, err)
+		return result
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].rel < candidates[j].rel })
+
+	if pass != nil && len(candidates) > 0 {
+		if reason := pass.Blocked(); reason != "" {
+			for _, c := range candidates {
+				result.Held = append(result.Held, fmt.Sprintf("%s: %s", c.rel, reason))
+			}
+			return result
+		}
+		allowed := candidates[:0]
+		for _, c := range candidates {
+			if reason := pass.FileBlocked(filepath.ToSlash(filepath.Join(rule.Target, c.rel)), c.source); reason != "" {
+				result.Held = append(result.Held, fmt.Sprintf("%s: %s", c.rel, reason))
+				continue
+			}
+			allowed = append(allowed, c)
+		}
+		candidates = allowed
+	}
+
+	for _, c := range candidates {
+		result.Files = append(result.Files, filepath.ToSlash(filepath.Join(rule.Target, c.rel)))
+	}
+	if dryRun || len(candidates) == 0 {
+		return result
+	}
+
+	stagingArea := staging.NewStagingArea(im.DgitDir)
+	var staged []*staging.StagedFile
+	for _, c := range candidates {
+		dest := filepath.Join(im.RootDir, filepath.FromSlash(rule.Target), c.rel)
+		if err := util.CopyFile(c.source, dest); err != nil {
+			result.Err = fmt.Errorf("failed to copy %s: %w", c.rel, err)
+			return result
+		}
+		if err := stagingArea.AddFile(dest); err != nil {
+			result.Err = fmt.Errorf("failed to stage %s: %w", c.rel, err)
+			return result
+		}
+	}
+	staged = stagingArea.GetStagedFiles()
+
+	newCommit, err := commit.NewCommitManager(im.DgitDir).CreateCommit(expandMessage(rule, result.Files), staged)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to commit: %w", err)
+		return result
+	}
+	result.Version = newCommit.Version
+	if pass != nil {
+		pass.Record(result.Files)
+	}
+
+	for _, c := range candidates {
+		seen[c.rel] = seenFile{Size: c.info.Size(), ModTime: c.info.ModTime()}
+		if rule.Move {
+			os.Remove(c.source)
+		}
+	}
+	return result
+}
+
+// matchesRule checks a file against the rule's patterns, defaulting to design files
+func matchesRule(rule initializer.IngestRule, path string) bool {
+	if scanner.MatchResidue(path, false) != nil {
+		return false
+	}
+	if len(rule.Patterns) == 0 {
+		return scanner.IsDesignFile(path)
+	}
+	for _, pattern := range rule.Patterns {
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMessage fills in a rule's commit message template
+func expandMessage(rule initializer.IngestRule, files []string) string {
+	message := rule.Message
+	if message == "" {
+		message = DefaultMessage
+	}
+	return strings.NewReplacer(
+		"{rule}", rule.Name,
+		"{count}", strconv.Itoa(len(files)),
+		"{files}", strings.Join(files, ", "),
+		"{date}", time.Now().Format("2006-01-02 15:04"),
+	).Replace(message)
+}
+
+// loadState reads which source files each rule has already ingested
+func (im *IngestManager) loadState() (map[string]map[string]seenFile, error) {
+	state := make(map[string]map[string]seenFile)
+	data, err := os.ReadFile(im.StateFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ingest state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse ingest state: %w", err)
+	}
+	return state, nil
+}
+
+// saveState writes the ingest state to disk
+func (im *IngestManager) saveState(state map[string]map[string]seenFile) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest state: %w", err)
+	}
+	if err := os.WriteFile(im.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write ingest state: %w", err)
+	}
+	return nil
+}
@@ -36,6 +36,57 @@ type RepositoryConfig struct {
 	
 	// Performance Monitoring and Optimization Settings
 	Performance PerformanceConfig `json:"performance"`
+	
+	// Approval Workflow Policy Settings
+	Approval ApprovalConfig `json:"approval"`
+	
+	// Optional Energy and CO2 Accounting for sustainability reporting
+	Accounting AccountingConfig `json:"accounting"`
+	
+	// Untracked residue cleanup rules for 'dgit clean'
+	Clean CleanConfig `json:"clean"`
+	
+	// Trash for files replaced or removed by restore and clean
+	Trash TrashConfig `json:"trash"`
+	
+	// Watch-folder ingestion rules processed by 'dgit daemon'
+	Ingest IngestConfig `json:"ingest"`
+	
+	// Limits on how often watch-folder ingest commits on its own
+	AutoCommit AutoCommitConfig `json:"auto_commit"`
+	
+	// External converters that produce viewable proxies after each commit
+	Preview PreviewConfig `json:"preview"`
+	
+	// Contrast checks run on the generated previews
+	Audit AuditConfig `json:"audit"`
+	
+	// Waiting for archived versions to come back from external storage
+	Archive ArchiveConfig `json:"archive"`
+	
+	// Disk bandwidth allowed to background optimization and maintenance
+	IO IOConfig `json:"io"`
+	
+	// Shared servers that 'dgit push' and 'dgit pull' sync with
+	Remotes []RemoteConfig `json:"remotes,omitempty"`
+	
+	// Commit output settings
+	Commit CommitConfig `json:"commit"`
+	
+	// Metadata fields stripped or hashed when history leaves the studio
+	Redaction RedactionConfig `json:"redaction"`
+	
+	// Actions external systems may trigger through 'dgit serve'
+	Webhooks WebhookConfig `json:"webhooks"`
+	
+	// Figma cloud files whose named versions are pulled in as commits
+	Figma FigmaConfig `json:"figma"`
+	
+	// Adobe Creative Cloud Library assets snapshotted with each commit
+	CCLibraries CCLibrariesConfig `json:"cc_libraries"`
+	
+	// Bucket or shared folder holding cold-tier archives and object store blobs
+	Storage StorageConfig `json:"storage"`
 }
 
 // UltraFastCompressionConfig represents advanced 3-stage compression settings
@@ -52,6 +103,22 @@ type UltraFastCompressionConfig struct {
 	
 	// Smart Cache Management Settings
 	CacheConfig SmartCacheConfig `json:"cache"`
+	
+	// Already-compressed formats (ZIP containers) stored framed but uncompressed
+	StoreExtensions []string `json:"store_extensions,omitempty"`
+	
+	// "content" (the default for new repositories) stores each file once under its SHA-256 and shares it
+	// between commits. Commits then skip the per-version LZ4 snapshot and every delta path (bsdiff,
+	// xdelta3, PSD smart delta): an edited file is stored whole as a new blob. Empty keeps one snapshot
+	// per version with those deltas (repositories created before the object store)
+	ObjectStore string `json:"object_store,omitempty"`
+	
+	// Files compressed concurrently by one commit; 0 uses every CPU core, 1 keeps a single LZ4 stream
+	Workers int `json:"workers,omitempty"`
+	
+	// "xdelta3" stores a snapshot version as a VCDIFF delta against its parent when that is much smaller;
+	// empty always writes full snapshots. Has no effect with the content object store
+	DeltaStrategy string `json:"delta_strategy,omitempty"`
 }
 
 // LZ4StageConfig configures instant 0.2s commit performance
@@ -85,11 +152,16 @@ type ArchiveStageConfig struct {
 // SmartCacheConfig configures intelligent cache management
 // Automatically promotes/demotes files between cache tiers based on access patterns
 type SmartCacheConfig struct {
-	HotCacheSize    int64   `json:"hot_cache_size"`    // Max hot cache size (MB)
-	WarmCacheSize   int64   `json:"warm_cache_size"`   // Max warm cache size (MB)
-	ColdStorageSize int64   `json:"cold_storage_size"` // Max cold storage size (MB) 
-	AccessThreshold int     `json:"access_threshold"`  // Accesses needed to promote to hot
-	EvictionPolicy  string  `json:"eviction_policy"`   // "LRU", "LFU", "FIFO"
+	HotCacheSize    int64    `json:"hot_cache_size"`       // Max hot cache size (MB)
+	WarmCacheSize   int64    `json:"warm_cache_size"`      // Max warm cache size (MB)
+	ColdStorageSize int64    `json:"cold_storage_size"`    // Max cold storage size (MB) 
+	AccessThreshold int      `json:"access_threshold"`     // Accesses needed to promote to hot
+	EvictionPolicy  string   `json:"eviction_policy"`      // "LRU", "LFU", "FIFO"
+	ReadOrder       []string `json:"read_order,omitempty"` // Restore probe order over hot, warm, smart, cold, legacy
+
+	// Delta chains deeper or slower to replay than this are restored from a cold or archived full snapshot when one exists
+	MaxDeltaDepth          int     `json:"max_delta_depth,omitempty"`           // Default 32; -1 for no limit
+	MaxDeltaRestoreSeconds float64 `json:"max_delta_restore_seconds,omitempty"` // Estimated replay time; 0 for no limit
 }
 
 // PerformanceConfig configures monitoring and optimization systems
@@ -99,6 +171,186 @@ type PerformanceConfig struct {
 	LogCompressionTime bool `json:"log_compression_time"` // Log compression timing data
 	LogCacheHits       bool `json:"log_cache_hits"`       // Log cache hit/miss ratios
 	StatsRetentionDays int  `json:"stats_retention_days"` // Days to keep performance statistics
+	
+	// Resource limits for one dgit command; --max-workers, --max-memory and --io-throttle override them
+	MaxWorkers     int `json:"max_workers,omitempty"`      // Parallel scan, compression, restore and verify workers (default: one per CPU core)
+	MaxMemoryMB    int `json:"max_memory_mb,omitempty"`    // Decompression buffer budget; bigger snapshots fall back to slower tiers (default unlimited)
+	IOThrottleMBps int `json:"io_throttle_mbps,omitempty"` // Disk bandwidth of foreground work such as commit and restore (default unlimited; see io.* for background work)
+}
+
+// ApprovalConfig configures which workflow state a commit needs before an action is allowed
+// Keys are actions such as "deliver" or "export", values are states such as "approved"
+type ApprovalConfig struct {
+	RequiredStates map[string]string `json:"required_states,omitempty"` // Action → minimum commit state
+}
+
+// AccountingConfig configures the optional footprint accounting module
+// Factors are estimates; zero values fall back to the accounting package defaults
+type AccountingConfig struct {
+	Enabled              bool    `json:"enabled"`                           // Record usage events for footprint reports
+	GridIntensity        float64 `json:"grid_intensity,omitempty"`          // gCO2e per kWh of the local grid
+	StorageKWhPerGBMonth float64 `json:"storage_kwh_per_gb_month,omitempty"` // Energy to keep 1 GB stored for a month
+	TransferKWhPerGB     float64 `json:"transfer_kwh_per_gb,omitempty"`     // Network energy per GB transferred
+	ComputeWatts         float64 `json:"compute_watts,omitempty"`           // Average machine power during operations
+}
+
+// CleanConfig configures which untracked files 'dgit clean' may remove
+// Patterns match the file name or repository-relative path; a trailing slash matches a folder
+type CleanConfig struct {
+	Patterns  []string `json:"patterns,omitempty"`  // Untracked exports and renders that may be removed
+	Protected []string `json:"protected,omitempty"` // Paths that are never removed (design sources always are)
+}
+
+// TrashConfig configures how long displaced user files are kept in .dgit/trash
+type TrashConfig struct {
+	RetentionDays int `json:"retention_days"` // Days before trashed files are purged (0 = default of 30)
+}
+
+// IngestConfig configures automatic versioning of pipeline outputs dropped into watch folders
+type IngestConfig struct {
+	Rules []IngestRule `json:"rules,omitempty"`
+}
+
+// IngestRule maps a watch folder to a repository path with an auto-commit message template
+// Message placeholders: {rule}, {count}, {files}, {date}
+type IngestRule struct {
+	Name          string   `json:"name"`
+	Watch         string   `json:"watch"`                    // Folder render farms or exporters write into
+	Target        string   `json:"target"`                   // Repository folder the files are copied to
+	Patterns      []string `json:"patterns,omitempty"`       // File name patterns to ingest (default: all design files)
+	Message       string   `json:"message,omitempty"`        // Commit message template
+	SettleSeconds int      `json:"settle_seconds,omitempty"` // Wait until files are unchanged this long (default 10)
+	Move          bool     `json:"move,omitempty"`           // Remove files from the watch folder after ingesting
+}
+
+// AutoCommitConfig throttles automatic commits so autosave storms do not create thousands of versions
+// Files held back by the policy stay pending and are committed with their latest content once allowed
+type AutoCommitConfig struct {
+	MinIntervalSeconds int    `json:"min_interval_seconds,omitempty"` // Per file: at most one auto-commit per interval
+	QuietHours         string `json:"quiet_hours,omitempty"`          // Local "22:00-07:00": no auto-commits in this window
+	RequireClosed      bool   `json:"require_closed,omitempty"`       // Wait until no application has the file open
+	MaxPerDay          int    `json:"max_per_day,omitempty"`          // Auto-commits per calendar day (0 = unlimited)
+}
+
+// CommitConfig configures how commits are written
+// Deterministic commits produce identical blobs, metadata and hashes for identical inputs
+type CommitConfig struct {
+	Deterministic     bool `json:"deterministic"`                 // Timestamps from file mtimes, sorted inputs, no timing data
+	SessionGapMinutes int  `json:"session_gap_minutes,omitempty"` // Idle minutes that end a working session (default 30)
+
+	// Message filters run in order on every commit message before it is saved
+	MessageFilters  []string `json:"message_filters,omitempty"`  // Default: trim, require-message
+	MessagePrefixes []string `json:"message_prefixes,omitempty"` // Prefixes accepted by the prefix filter, e.g. "feat:", "fix:"
+
+	// Duplicate and default layer names in committed PSD/AI files
+	LayerLint LayerLintConfig `json:"layer_lint"`
+}
+
+// LayerLintConfig flags PSD and AI files whose layer names make them hard to review
+// Checked against the layer names already extracted for the commit metadata
+type LayerLintConfig struct {
+	Severity        string `json:"severity,omitempty"`          // "off" (default), "warn", or "error" to refuse the commit
+	MaxDefaultNames int    `json:"max_default_names,omitempty"` // Default names ("Layer 1", "<Path>") allowed per file
+}
+
+// RedactionConfig lists the metadata fields removed or hashed by redacted exports
+// Fields match commit-level keys (author, email, message) and per-file metadata keys (layer_names)
+type RedactionConfig struct {
+	Rules []RedactionRule `json:"rules,omitempty"`
+}
+
+// RedactionRule applies an action to one metadata field; file contents are never touched
+type RedactionRule struct {
+	Field  string `json:"field"`
+	Action string `json:"action"` // "strip" removes the value, "hash" replaces it with a stable digest
+}
+
+// WebhookConfig is the allowlist for the inbound webhook endpoint of 'dgit serve'
+// Nothing is allowed until actions are listed; callers also need the token in .dgit/keys/webhook.key
+type WebhookConfig struct {
+	AllowedActions []string `json:"allowed_actions,omitempty"` // "ingest", "verify", "sync", "figma"
+}
+
+// FigmaConfig lists the Figma cloud files synced by 'dgit figma sync'
+// The API token comes from FIGMA_TOKEN or .dgit/keys/figma.token, never from this file
+type FigmaConfig struct {
+	Files  []FigmaFile `json:"files,omitempty"`
+	APIURL string      `json:"api_url,omitempty"` // Override for a proxy or bridge (default https://api.figma.com)
+}
+
+// FigmaFile maps a Figma file to the repository path its document is committed as
+type FigmaFile struct {
+	Key  string `json:"key"`  // From the file URL: figma.com/file/<key>/...
+	Path string `json:"path"` // Repository path of the .figma document
+}
+
+// CCLibrariesConfig controls the Creative Cloud Libraries connector (off by default)
+// When enabled, library elements linked from committed files are copied into .dgit/cclib
+type CCLibrariesConfig struct {
+	Enabled      bool   `json:"enabled"`
+	LibrariesDir string `json:"libraries_dir,omitempty"` // Local library cache (default: the Creative Cloud app's LIBS folder)
+}
+
+// StorageConfig selects where cold-tier archives and object store blobs can live besides this machine
+// Credentials come from the environment (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY), never from this file
+type StorageConfig struct {
+	Backend string   `json:"backend,omitempty"` // "s3" or "dir"; empty keeps everything local
+	Path    string   `json:"path,omitempty"`    // dir backend: a mounted share, e.g. /Volumes/studio/dgit
+	S3      S3Config `json:"s3"`
+}
+
+// S3Config locates a bucket on AWS S3 or an S3-compatible service such as MinIO
+type S3Config struct {
+	Bucket    string `json:"bucket,omitempty"`
+	Region    string `json:"region,omitempty"`     // Default us-east-1
+	Endpoint  string `json:"endpoint,omitempty"`   // Default https://s3.<region>.amazonaws.com
+	Prefix    string `json:"prefix,omitempty"`     // Key prefix, so several repositories can share a bucket
+	PathStyle bool   `json:"path_style,omitempty"` // Address the bucket as <endpoint>/<bucket>, as most self-hosted services need
+}
+
+// AuditConfig configures the opt-in WCAG contrast audit of image previews
+// Findings are written into the commit metadata and shown by 'dgit log --audit'
+type AuditConfig struct {
+	Enabled     bool     `json:"enabled"`
+	MinContrast float64  `json:"min_contrast,omitempty"` // Required contrast ratio (default 4.5, WCAG AA for body text)
+	Patterns    []string `json:"patterns,omitempty"`     // Repository paths to audit, e.g. "marketing/*" (default: all)
+}
+
+// RemoteConfig names a repository served by 'dgit serve' on another machine
+// The access token comes from DGIT_REMOTE_TOKEN or .dgit/keys/remote-<name>.token, never from this file
+type RemoteConfig struct {
+	Name string `json:"name"`
+	URL  string `json:"url"` // Base URL of the server, e.g. http://nas.studio.local:8080
+}
+
+// IOConfig limits the disk bandwidth of background work so it never slows a designer's commit
+// Background and idle work also pause while a commit or restore is running
+type IOConfig struct {
+	BackgroundMBps      int  `json:"background_mbps,omitempty"`         // Post-commit optimization (default 40, -1 unlimited)
+	IdleMBps            int  `json:"idle_mbps,omitempty"`               // Maintenance such as recompression (default 10, -1 unlimited)
+	KeepRunningWhenBusy bool `json:"keep_running_when_busy,omitempty"` // Do not pause during foreground operations
+}
+
+// ArchiveConfig controls how restores wait for on-recall hooks to bring archived versions back
+type ArchiveConfig struct {
+	RecallTimeoutMinutes int `json:"recall_timeout_minutes,omitempty"` // Give up waiting after this long (default 240, tape mounts are slow)
+	PollSeconds          int `json:"poll_seconds,omitempty"`           // How often to check whether the blob is back (default 5)
+}
+
+// PreviewConfig configures the post-commit preview/proxy generation step
+type PreviewConfig struct {
+	Converters     []PreviewConverter `json:"converters,omitempty"`
+	TimeoutSeconds int                `json:"timeout_seconds,omitempty"` // Per-file converter timeout (default 120)
+	SkipThumbnails bool               `json:"skip_thumbnails,omitempty"` // Do not keep embedded thumbnails in objects/previews at commit
+}
+
+// PreviewConverter runs an external tool to turn a design file into a lightweight proxy
+// Command arguments may use {input} and {output}; converters whose tool is not installed are skipped
+type PreviewConverter struct {
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"` // Source file extensions, e.g. ".psd"
+	Command    []string `json:"command"`    // Executable followed by its arguments
+	Output     string   `json:"output"`     // Proxy format extension, e.g. "png" or "glb"
 }
 
 // InitializeRepository initializes a new ultra-fast DGit repository
@@ -176,7 +428,8 @@ func (ri *RepositoryInitializer) createUltraFastStructure(dgitPath string) error
 		"metrics",               // Detailed performance metrics and analytics
 		
 		// System and Future Expansion
-		"refs",                  // References for future branching support
+		"refs",                  // Branch references
+		"refs/heads",            // One file per branch holding its tip commit hash
 		"hooks",                 // Automation hooks for workflow integration
 		"temp",                  // Temporary working space for operations
 	}
@@ -242,6 +495,12 @@ func (ri *RepositoryInitializer) createUltraFastConfig(dgitPath string) error {
 				AccessThreshold: 3,          // 3 accesses → promote to hot cache
 				EvictionPolicy:  "LRU",      // Least Recently Used eviction strategy
 			},
+			
+			// Store codec for formats that are ZIP containers internally and won't shrink
+			StoreExtensions: []string{".sketch", ".fig", ".xd", ".kra"},
+			
+			// Content-addressed storage: unchanged files are never stored twice
+			ObjectStore: "content",
 		},
 		
 		// Performance Monitoring Configuration (Continuous improvement)
@@ -251,6 +510,62 @@ func (ri *RepositoryInitializer) createUltraFastConfig(dgitPath string) error {
 			LogCacheHits:       true,
 			StatsRetentionDays: 90, // Keep 3 months of performance statistics
 		},
+		
+		// Approval Policy (only approved versions leave the studio)
+		Approval: ApprovalConfig{
+			RequiredStates: map[string]string{
+				"deliver": "approved",
+				"export":  "approved",
+			},
+		},
+		
+		// Footprint accounting is opt-in ('dgit accounting enable')
+		Accounting: AccountingConfig{
+			Enabled: false,
+		},
+		
+		// Clean rules: temp renders and intermediate exports, never design sources
+		Clean: CleanConfig{
+			Patterns: []string{"*.tmp", "*.bak", "renders/tmp/", "exports/tmp/"},
+		},
+		
+		// Displaced files stay recoverable for a month ('dgit trash')
+		Trash: TrashConfig{
+			RetentionDays: 30,
+		},
+		
+		// Tidy messages and refuse empty ones; add "prefix" or "emoji" to enforce studio conventions
+		Commit: CommitConfig{
+			MessageFilters: []string{"trim", "require-message"},
+		},
+		
+		// Redacted exports hide names clients could read; hashes still show what changed
+		Redaction: RedactionConfig{
+			Rules: []RedactionRule{
+				{Field: "layer_names", Action: "hash"},
+				{Field: "text", Action: "strip"},
+				{Field: "email", Action: "strip"},
+			},
+		},
+		
+		// Proxies for teammates without the authoring apps; skipped when the tool is missing
+		Preview: PreviewConfig{
+			Converters: []PreviewConverter{
+				{
+					Name:       "imagemagick",
+					Extensions: []string{".psd", ".ai", ".tiff", ".tif", ".exr"},
+					Command:    []string{"magick", "{input}[0]", "-flatten", "-thumbnail", "1600x1600>", "{output}"},
+					Output:     "png",
+				},
+				{
+					Name:       "blender",
+					Extensions: []string{".blend"},
+					Command: []string{"blender", "-b", "{input}", "--python-expr",
+						"import bpy; bpy.ops.export_scene.gltf(filepath=r'{output}')"},
+					Output: "glb",
+				},
+			},
+		},
 	}
 
 	// Write configuration to repository
@@ -353,8 +668,8 @@ func (ri *RepositoryInitializer) createPerformanceMonitoring(dgitPath string) er
 // Establishes the foundation for commit history tracking
 func (ri *RepositoryInitializer) createInitialHead(dgitPath string) error {
 	headPath := filepath.Join(dgitPath, "HEAD")
-	// Start with empty HEAD - will be populated with first commit
-	if err := os.WriteFile(headPath, []byte(""), 0644); err != nil {
+	// Start on the main branch - its ref is created by the first commit
+	if err := os.WriteFile(headPath, []byte("ref: refs/heads/main\n"), 0644); err != nil {
 		return fmt.Errorf("failed to create HEAD file: %w", err)
 	}
 	return nil
@@ -0,0 +1,282 @@
+package iosched
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+)
+
+// Class is the IO scheduling class of a piece of work
+type Class string
+
+// Scheduling classes, from most to least urgent
+const (
+	Foreground Class = "foreground" // A designer is waiting: never paused, throttled only by performance.io_throttle_mbps
+	Background Class = "background" // Post-commit optimization: throttled, pauses during foreground work
+	Idle       Class = "idle"       // Maintenance such as recompression: slowest, resumes only after a quiet period
+)
+
+// Defaults for the throttled classes
+const (
+	DefaultBackgroundMBps = 40
+	DefaultIdleMBps       = 10
+	idleQuietPeriod       = 5 * time.Second // Idle work waits this long after foreground work ends
+	pollInterval          = 250 * time.Millisecond
+	staleForeground       = 6 * time.Hour // Markers older than this are ignored even if their process looks alive
+)
+
+// ParseClass validates a class name given on the command line
+func ParseClass(name string) (Class, error) {
+	switch class := Class(strings.ToLower(name)); class {
+	case Foreground, Background, Idle:
+		return class, nil
+	}
+	return "", fmt.Errorf("unknown IO class %q (use foreground, background or idle)", name)
+}
+
+// Activity is a foreground operation holding the repository's foreground marker
+type Activity struct {
+	PID       int       `json:"pid"`
+	Operation string    `json:"operation"`
+	Started   time.Time `json:"started"`
+}
+
+var markerSeq int64
+
+// BeginForeground marks a foreground operation as running until the returned function is called
+// Background and idle work in any process on this repository pauses while a marker exists
+func BeginForeground(dgitDir, operation string) func() {
+	dir := filepath.Join(dgitDir, "locks", "foreground")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return func() {}
+	}
+	name := fmt.Sprintf("%d-%d.json", os.Getpid(), atomic.AddInt64(&markerSeq, 1))
+	path := filepath.Join(dir, name)
+	data, _ := json.Marshal(&Activity{PID: os.Getpid(), Operation: operation, Started: time.Now()})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return func() {}
+	}
+	var once sync.Once
+	return func() { once.Do(func() { os.Remove(path) }) }
+}
+
+// ActiveForeground returns the foreground operations currently running on a repository
+// Markers left behind by crashed processes are removed
+func ActiveForeground(dgitDir string) []*Activity {
+	dir := filepath.Join(dgitDir, "locks", "foreground")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var active []*Activity
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // Released while listing
+		}
+		var activity Activity
+		if json.Unmarshal(data, &activity) != nil || !ProcessAlive(activity.PID) || time.Since(activity.Started) > staleForeground {
+			os.Remove(path)
+			continue
+		}
+		active = append(active, &activity)
+	}
+	return active
+}
+
+// ProcessAlive reports whether a process exists, using signal 0
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM) // Alive but owned by another user
+}
+
+// Scheduler paces the IO of one class of work
+// A nil Scheduler does not throttle, so callers can pass one optionally
+type Scheduler struct {
+	DgitDir string
+	Class   Class
+	Rate    float64 // Bytes per second; 0 is unlimited
+	Pause   bool    // Wait while foreground work is active
+	Paused  time.Duration
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowBytes int64
+	lastCheck   time.Time
+	busy        bool
+	quietSince  time.Time
+}
+
+// NewScheduler creates a scheduler for a class using the repository's io settings
+func NewScheduler(dgitDir string, class Class) *Scheduler {
+	s := &Scheduler{DgitDir: dgitDir, Class: class}
+	if class == Foreground {
+		if mbps := perf.Load(dgitDir).IOThrottleMBps; mbps > 0 {
+			s.Rate = float64(mbps) * 1024 * 1024
+		}
+		return s
+	}
+	backgroundMBps, idleMBps := DefaultBackgroundMBps, DefaultIdleMBps
+	s.Pause = true
+	if config, err := initializer.GetRepositoryConfig(dgitDir); err == nil {
+		if config.IO.BackgroundMBps != 0 {
+			backgroundMBps = config.IO.BackgroundMBps
+		}
+		if config.IO.IdleMBps != 0 {
+			idleMBps = config.IO.IdleMBps
+		}
+		s.Pause = !config.IO.KeepRunningWhenBusy
+	}
+	mbps := backgroundMBps
+	if class == Idle {
+		mbps = idleMBps
+	}
+	if mbps > 0 {
+		s.Rate = float64(mbps) * 1024 * 1024
+	}
+	return s
+}
+
+// Wait blocks before n bytes of IO until foreground work is done and the class's rate allows it
+func (s *Scheduler) Wait(n int) {
+	if !s.paces() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Pause {
+		started := time.Now()
+		for s.foregroundBusy() {
+			time.Sleep(pollInterval)
+		}
+		if waited := time.Since(started); waited > pollInterval {
+			s.Paused += waited
+			s.windowStart, s.windowBytes = time.Time{}, 0 // Do not burst to catch up after a pause
+		}
+	}
+
+	if s.Rate <= 0 || n <= 0 {
+		return
+	}
+	now := time.Now()
+	if s.windowStart.IsZero() || now.Sub(s.windowStart) > time.Second {
+		s.windowStart, s.windowBytes = now, 0
+	}
+	s.windowBytes += int64(n)
+	due := s.windowStart.Add(time.Duration(float64(s.windowBytes) / s.Rate * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// foregroundBusy reports whether this class must hold off, checking markers at most every poll interval
+// Idle work also holds off for a quiet period after the last foreground operation ends
+func (s *Scheduler) foregroundBusy() bool {
+	now := time.Now()
+	if now.Sub(s.lastCheck) >= pollInterval {
+		s.lastCheck = now
+		busy := len(ActiveForeground(s.DgitDir)) > 0
+		if s.busy && !busy {
+			s.quietSince = now
+		}
+		s.busy = busy
+	}
+	if s.busy {
+		return true
+	}
+	return s.Class == Idle && !s.quietSince.IsZero() && now.Sub(s.quietSince) < idleQuietPeriod
+}
+
+// Reader paces reads from r
+func (s *Scheduler) Reader(r io.Reader) io.Reader {
+	if !s.paces() {
+		return r
+	}
+	return &pacedReader{r: r, s: s}
+}
+
+// Writer paces writes to w
+func (s *Scheduler) Writer(w io.Writer) io.Writer {
+	if !s.paces() {
+		return w
+	}
+	return &pacedWriter{w: w, s: s}
+}
+
+// paces reports whether the scheduler ever delays IO; unthrottled foreground work passes straight through
+func (s *Scheduler) paces() bool {
+	return s != nil && (s.Class != Foreground || s.Rate > 0)
+}
+
+// String describes the class and its limit, e.g. "idle (10 MB/s)"
+func (s *Scheduler) String() string {
+	if s == nil {
+		return string(Foreground)
+	}
+	if s.Rate <= 0 {
+		return string(s.Class) + " (unlimited)"
+	}
+	return string(s.Class) + " (" + strconv.Itoa(int(s.Rate/(1024*1024))) + " MB/s)"
+}
+
+// maxChunk bounds a single paced read or write so pauses take effect promptly
+const maxChunk = 256 * 1024
+
+type pacedReader struct {
+	r io.Reader
+	s *Scheduler
+}
+
+func (p *pacedReader) Read(b []byte) (int, error) {
+	if len(b) > maxChunk {
+		b = b[:maxChunk]
+	}
+	p.s.Wait(len(b))
+	return p.r.Read(b)
+}
+
+type pacedWriter struct {
+	w io.Writer
+	s *Scheduler
+}
+
+func (p *pacedWriter) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if len(chunk) > maxChunk {
+			chunk = chunk[:maxChunk]
+		}
+		p.s.Wait(len(chunk))
+		n, err := p.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,142 @@
+package layerlint
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Severities of the commit.layer_lint.severity setting
+const (
+	SeverityOff   = "off"
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// lintedExtensions are the formats whose layer names are checked
+var lintedExtensions = map[string]bool{".psd": true, ".psb": true, ".ai": true}
+
+// defaultName matches names Photoshop and Illustrator give new layers ("Layer 1", "Rectangle 3 copy 2", "<Path>")
+var defaultName = regexp.MustCompile(`^(?:(?:Layer|Group|Rectangle|Ellipse|Polygon|Line|Shape|Color Fill|Gradient Fill|Pattern Fill|Curves|Levels|Hue/Saturation|Brightness/Contrast|Vector Smart Object) \d+(?: copy(?: \d+)?)*|<[A-Za-z ]+>)$`)
+
+// Finding describes the layer naming problems of one file
+type Finding struct {
+	Path         string
+	Duplicates   map[string]int // Name → layer count, for custom names used more than once
+	DefaultNames int            // Layers still carrying an application default name
+	Message      string
+}
+
+// LintManager checks layer names of committed design files against the repository's lint settings
+type LintManager struct {
+	DgitDir string
+}
+
+// NewLintManager creates a new lint manager for the given .dgit directory
+func NewLintManager(dgitDir string) *LintManager {
+	return &LintManager{DgitDir: dgitDir}
+}
+
+// GetConfig returns the lint settings, "off" when unset
+func (lm *LintManager) GetConfig() initializer.LayerLintConfig {
+	var config initializer.LayerLintConfig
+	if repoConfig, err := initializer.GetRepositoryConfig(lm.DgitDir); err == nil {
+		config = repoConfig.Commit.LayerLint
+	}
+	if config.Severity == "" {
+		config.Severity = SeverityOff
+	}
+	return config
+}
+
+// Check lints the files of a commit's metadata and returns the configured severity with the findings
+// Nothing is checked when the severity is off
+func (lm *LintManager) Check(metadata map[string]interface{}) (string, []*Finding, error) {
+	config := lm.GetConfig()
+	switch config.Severity {
+	case SeverityOff:
+		return config.Severity, nil, nil
+	case SeverityWarn, SeverityError:
+	default:
+		return "", nil, fmt.Errorf("unknown commit.layer_lint.severity %q (use off, warn or error)", config.Severity)
+	}
+
+	var findings []*Finding
+	for path, raw := range metadata {
+		if !lintedExtensions[strings.ToLower(filepath.Ext(path))] {
+			continue
+		}
+		meta, _ := raw.(map[string]interface{})
+		if finding := lintNames(path, layerNames(meta["layer_names"]), config.MaxDefaultNames); finding != nil {
+			findings = append(findings, finding)
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	return config.Severity, findings, nil
+}
+
+// lintNames counts duplicate and default layer names; nil when the file is clean
+func lintNames(path string, names []string, maxDefaults int) *Finding {
+	counts := make(map[string]int)
+	finding := &Finding{Path: path, Duplicates: make(map[string]int)}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if defaultName.MatchString(name) {
+			finding.DefaultNames++
+			continue
+		}
+		counts[name]++
+	}
+	for name, count := range counts {
+		if count > 1 && name != "" {
+			finding.Duplicates[name] = count
+		}
+	}
+	if finding.DefaultNames <= maxDefaults {
+		finding.DefaultNames = 0
+	}
+	if len(finding.Duplicates) == 0 && finding.DefaultNames == 0 {
+		return nil
+	}
+
+	var parts []string
+	if finding.DefaultNames > 0 {
+		parts = append(parts, fmt.Sprintf("%d layer(s) with default names", finding.DefaultNames))
+	}
+	dupes := make([]string, 0, len(finding.Duplicates))
+	for name := range finding.Duplicates {
+		dupes = append(dupes, name)
+	}
+	sort.Slice(dupes, func(i, j int) bool {
+		if finding.Duplicates[dupes[i]] != finding.Duplicates[dupes[j]] {
+			return finding.Duplicates[dupes[i]] > finding.Duplicates[dupes[j]]
+		}
+		return dupes[i] < dupes[j]
+	})
+	for _, name := range dupes {
+		parts = append(parts, fmt.Sprintf("%q × %d", name, finding.Duplicates[name]))
+	}
+	finding.Message = fmt.Sprintf("%s: %s", path, strings.Join(parts, ", "))
+	return finding
+}
+
+// layerNames reads layer names from scanned ([]string) or stored ([]interface{}) metadata
+func layerNames(value interface{}) []string {
+	switch names := value.(type) {
+	case []string:
+		return names
+	case []interface{}:
+		result := make([]string, 0, len(names))
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
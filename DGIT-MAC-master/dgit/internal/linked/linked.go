@@ -0,0 +1,367 @@
+package linked
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/trash"
+)
+
+// Link records that a working tree file is a specific version of a file in a library repository
+// Links are recorded with every commit so restore can fetch the exact library version on demand
+type Link struct {
+	Path       string    `json:"path"`        // Local path relative to the working tree root
+	Library    string    `json:"library"`     // Library repository location (working tree path)
+	SourcePath string    `json:"source_path"` // File path inside the library repository
+	Version    int       `json:"version"`     // Library version the file was taken from
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// LinkUpdate describes a newer library version available for a linked asset
+type LinkUpdate struct {
+	Link          *Link
+	LatestVersion int
+}
+
+// LinkManager manages linked assets for a DGit repository
+type LinkManager struct {
+	DgitDir   string
+	RootDir   string
+	LinksFile string
+	TempDir   string
+}
+
+// NewLinkManager creates a new linked asset manager for the given .dgit directory
+func NewLinkManager(dgitDir string) *LinkManager {
+	return &LinkManager{
+		DgitDir:   dgitDir,
+		RootDir:   filepath.Dir(dgitDir),
+		LinksFile: filepath.Join(dgitDir, "links.json"),
+		TempDir:   filepath.Join(dgitDir, "temp"),
+	}
+}
+
+// GetLinks returns all registered links sorted by local path
+func (lm *LinkManager) GetLinks() ([]*Link, error) {
+	links := []*Link{}
+
+	data, err := os.ReadFile(lm.LinksFile)
+	if os.IsNotExist(err) {
+		return links, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read links file: %w", err)
+	}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("failed to parse links file: %w", err)
+	}
+
+	sort.Slice(links, func(i, j int) bool { return links[i].Path < links[j].Path })
+	return links, nil
+}
+
+// saveLinks writes the link registry to disk
+func (lm *LinkManager) saveLinks(links []*Link) error {
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal links: %w", err)
+	}
+	if err := os.WriteFile(lm.LinksFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write links file: %w", err)
+	}
+	return nil
+}
+
+// AddLink registers a library file as a linked asset and fetches it into the working tree
+// A version of 0 links the latest library version containing the file
+// An empty localPath uses the library file path
+func (lm *LinkManager) AddLink(library, sourcePath string, version int, localPath string) (*Link, error) {
+	absLibrary, err := filepath.Abs(library)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve library path: %w", err)
+	}
+	libraryDgit := filepath.Join(absLibrary, ".dgit")
+	if info, err := os.Stat(libraryDgit); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a dgit repository: %s", library)
+	}
+
+	sourcePath = filepath.ToSlash(filepath.Clean(sourcePath))
+	if version == 0 {
+		version = lm.latestVersionWithFile(libraryDgit, sourcePath, 0)
+		if version == 0 {
+			return nil, fmt.Errorf("%s not found in any version of %s", sourcePath, library)
+		}
+	} else if !lm.versionHasFile(libraryDgit, version, sourcePath) {
+		return nil, fmt.Errorf("%s not found in v%d of %s", sourcePath, version, library)
+	}
+
+	if localPath == "" {
+		localPath = filepath.Join(lm.RootDir, filepath.FromSlash(sourcePath))
+	}
+	relPath, err := lm.relativeToRoot(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := lm.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &Link{
+		Path:       relPath,
+		Library:    absLibrary,
+		SourcePath: sourcePath,
+		Version:    version,
+		LinkedAt:   time.Now(),
+	}
+
+	// Replace an existing link for the same path (re-linking to another version)
+	replaced := false
+	for i, existing := range links {
+		if existing.Path == relPath {
+			links[i] = link
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		links = append(links, link)
+	}
+
+	if err := lm.Fetch(link); err != nil {
+		return nil, err
+	}
+	if err := lm.saveLinks(links); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// RemoveLink unregisters a linked asset (the working tree file is left in place)
+func (lm *LinkManager) RemoveLink(localPath string) error {
+	relPath, err := lm.relativeToRoot(localPath)
+	if err != nil {
+		return err
+	}
+
+	links, err := lm.GetLinks()
+	if err != nil {
+		return err
+	}
+	for i, link := range links {
+		if link.Path == relPath {
+			return lm.saveLinks(append(links[:i], links[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no linked asset at %s", relPath)
+}
+
+// RenamePath keeps a linked asset registered after its local file is moved
+// Returns the link, or nil if no linked asset lives at oldPath
+func (lm *LinkManager) RenamePath(oldPath, newPath string) (*Link, error) {
+	links, err := lm.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		if link.Path == oldPath {
+			link.Path = newPath
+			return link, lm.saveLinks(links)
+		}
+	}
+	return nil, nil
+}
+
+// Fetch restores the linked library version of a file into the working tree
+func (lm *LinkManager) Fetch(link *Link) error {
+	libraryDgit := filepath.Join(link.Library, ".dgit")
+	commit, err := log.NewLogManager(libraryDgit).GetCommit(link.Version)
+	if err != nil {
+		return fmt.Errorf("library version v%d not available in %s: %w", link.Version, link.Library, err)
+	}
+
+	// Restore into a scratch directory first so only the linked file reaches the working tree
+	if err := os.MkdirAll(lm.TempDir, 0755); err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	scratchDir, err := os.MkdirTemp(lm.TempDir, "link-")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratchDir)
+
+	restoreManager := restore.NewRestoreManager(libraryDgit)
+	restoreManager.WorkDir = scratchDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", link.Version), []string{link.SourcePath}, commit); err != nil {
+		return fmt.Errorf("failed to fetch %s from library: %w", link.SourcePath, err)
+	}
+
+	fetched := filepath.Join(scratchDir, filepath.FromSlash(link.SourcePath))
+	if _, err := os.Stat(fetched); err != nil {
+		return fmt.Errorf("library v%d did not contain %s", link.Version, link.SourcePath)
+	}
+
+	target := filepath.Join(lm.RootDir, filepath.FromSlash(link.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", link.Path, err)
+	}
+	if _, err := os.Stat(target); err == nil {
+		if _, err := trash.NewTrashManager(lm.DgitDir).Trash(target, "link"); err != nil {
+			return fmt.Errorf("failed to keep a copy of %s: %w", link.Path, err)
+		}
+	}
+	return moveFile(fetched, target)
+}
+
+// FetchAll fetches every registered link matching the given paths (all links if none given)
+func (lm *LinkManager) FetchAll(paths []string) (map[string]error, error) {
+	links, err := lm.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+	return lm.fetchMatching(links, paths), nil
+}
+
+// FetchCommitLinks fetches the linked assets recorded in a commit
+// Used by restore so linked files come back at the exact library version the commit used
+func (lm *LinkManager) FetchCommitLinks(commitLinks []*log.LinkedAsset, paths []string) map[string]error {
+	links := make([]*Link, 0, len(commitLinks))
+	for _, cl := range commitLinks {
+		links = append(links, &Link{
+			Path:       cl.Path,
+			Library:    cl.Library,
+			SourcePath: cl.SourcePath,
+			Version:    cl.Version,
+		})
+	}
+	return lm.fetchMatching(links, paths)
+}
+
+// fetchMatching fetches links whose path matches one of the targets
+func (lm *LinkManager) fetchMatching(links []*Link, paths []string) map[string]error {
+	failures := make(map[string]error)
+	for _, link := range links {
+		if len(paths) > 0 && !matchesAny(link.Path, paths) {
+			continue
+		}
+		if err := lm.Fetch(link); err != nil {
+			failures[link.Path] = err
+		}
+	}
+	return failures
+}
+
+// CheckUpdates reports links whose library has a newer approved version of the linked file
+func (lm *LinkManager) CheckUpdates() ([]*LinkUpdate, error) {
+	links, err := lm.GetLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	var updates []*LinkUpdate
+	for _, link := range links {
+		latest := lm.latestApprovedVersionWithFile(filepath.Join(link.Library, ".dgit"), link.SourcePath, link.Version)
+		if latest > link.Version {
+			updates = append(updates, &LinkUpdate{Link: link, LatestVersion: latest})
+		}
+	}
+	return updates, nil
+}
+
+// latestApprovedVersionWithFile returns the newest approved library version above minVersion containing the file
+func (lm *LinkManager) latestApprovedVersionWithFile(libraryDgit, sourcePath string, minVersion int) int {
+	logManager := log.NewLogManager(libraryDgit)
+	approvalManager := approval.NewApprovalManager(libraryDgit)
+	for v := logManager.GetCurrentVersion(); v > minVersion; v-- {
+		commit, err := logManager.GetCommit(v)
+		if err != nil || !lm.versionHasFile(libraryDgit, v, sourcePath) {
+			continue
+		}
+		if state, err := approvalManager.GetState(commit); err == nil && approval.AtLeast(state.State, approval.StateApproved) {
+			return v
+		}
+	}
+	return 0
+}
+
+// latestVersionWithFile returns the newest library version above minVersion containing the file
+func (lm *LinkManager) latestVersionWithFile(libraryDgit, sourcePath string, minVersion int) int {
+	logManager := log.NewLogManager(libraryDgit)
+	for v := logManager.GetCurrentVersion(); v > minVersion; v-- {
+		if lm.versionHasFile(libraryDgit, v, sourcePath) {
+			return v
+		}
+	}
+	return 0
+}
+
+// versionHasFile checks whether a library commit contains the given file
+func (lm *LinkManager) versionHasFile(libraryDgit string, version int, sourcePath string) bool {
+	commit, err := log.NewLogManager(libraryDgit).GetCommit(version)
+	if err != nil {
+		return false
+	}
+	for fileName := range commit.Metadata {
+		if filepath.ToSlash(fileName) == sourcePath {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeToRoot converts a path to a slash-separated path relative to the working tree root
+func (lm *LinkManager) relativeToRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(lm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("linked asset path must be inside the repository: %s", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// matchesAny checks a link path against user targets by exact path, filename, or directory prefix
+func matchesAny(linkPath string, targets []string) bool {
+	for _, target := range targets {
+		target = filepath.ToSlash(filepath.Clean(target))
+		if linkPath == target || filepath.Base(linkPath) == filepath.Base(target) ||
+			strings.HasPrefix(linkPath, strings.TrimSuffix(target, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// moveFile renames src to dst, falling back to copy when crossing filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
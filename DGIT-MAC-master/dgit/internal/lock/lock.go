@@ -0,0 +1,178 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrLocked is returned when a file is locked by someone else
+var ErrLocked = errors.New("file is locked by someone else")
+
+// Lock records that someone is editing a binary design file; others should not change it meanwhile
+// Locks are advisory: they stop commits of the file, not edits in the working tree
+type Lock struct {
+	Path     string    `json:"path"` // Relative to the working tree root
+	Owner    string    `json:"owner"`
+	Email    string    `json:"email,omitempty"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+// UnlockRequest releases a lock; Force releases a lock held by someone else
+type UnlockRequest struct {
+	Path  string `json:"path"`
+	Owner string `json:"owner"`
+	Email string `json:"email,omitempty"`
+	Force bool   `json:"force,omitempty"`
+}
+
+// HeldBy reports whether the lock belongs to the given identity
+// Emails identify owners when both sides have one; otherwise names are compared
+func (l *Lock) HeldBy(owner, email string) bool {
+	if l.Email != "" && email != "" {
+		return strings.EqualFold(l.Email, email)
+	}
+	return l.Owner == owner
+}
+
+// String describes who holds the lock and since when
+func (l *Lock) String() string {
+	who := l.Owner
+	if l.Email != "" {
+		who += " <" + l.Email + ">"
+	}
+	return fmt.Sprintf("%s is locked by %s since %s", l.Path, who, l.LockedAt.Format("2006-01-02 15:04"))
+}
+
+// LockManager manages the lock registry of a DGit repository
+// With a remote the registry mirrors the remote's locks; 'dgit serve' keeps the shared copy
+type LockManager struct {
+	DgitDir   string
+	RootDir   string
+	LocksFile string
+}
+
+// NewLockManager creates a new lock manager for the given .dgit directory
+func NewLockManager(dgitDir string) *LockManager {
+	return &LockManager{
+		DgitDir:   dgitDir,
+		RootDir:   filepath.Dir(dgitDir),
+		LocksFile: filepath.Join(dgitDir, "locks.json"),
+	}
+}
+
+// GetLocks returns all locks sorted by path
+func (lm *LockManager) GetLocks() ([]*Lock, error) {
+	locks := []*Lock{}
+
+	data, err := os.ReadFile(lm.LocksFile)
+	if os.IsNotExist(err) {
+		return locks, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locks file: %w", err)
+	}
+	if err := json.Unmarshal(data, &locks); err != nil {
+		return nil, fmt.Errorf("failed to parse locks file: %w", err)
+	}
+
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Path < locks[j].Path })
+	return locks, nil
+}
+
+// Replace overwrites the registry, e.g. with the locks a remote reported
+func (lm *LockManager) Replace(locks []*Lock) error {
+	data, err := json.MarshalIndent(locks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal locks: %w", err)
+	}
+	if err := os.WriteFile(lm.LocksFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write locks file: %w", err)
+	}
+	return nil
+}
+
+// Acquire records a lock; locking a file again as its owner refreshes nothing and succeeds
+// A file locked by someone else returns their lock with ErrLocked
+func (lm *LockManager) Acquire(lock *Lock) (*Lock, error) {
+	locks, err := lm.GetLocks()
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range locks {
+		if existing.Path != lock.Path {
+			continue
+		}
+		if !existing.HeldBy(lock.Owner, lock.Email) {
+			return existing, fmt.Errorf("%s: %w", existing, ErrLocked)
+		}
+		return existing, nil
+	}
+	if lock.LockedAt.IsZero() {
+		lock.LockedAt = time.Now()
+	}
+	if err := lm.Replace(append(locks, lock)); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Release removes a lock; only its owner can release it unless the request forces it
+func (lm *LockManager) Release(request *UnlockRequest) (*Lock, error) {
+	locks, err := lm.GetLocks()
+	if err != nil {
+		return nil, err
+	}
+	for i, existing := range locks {
+		if existing.Path != request.Path {
+			continue
+		}
+		if !request.Force && !existing.HeldBy(request.Owner, request.Email) {
+			return existing, fmt.Errorf("%s: %w (use --force to break it)", existing, ErrLocked)
+		}
+		if err := lm.Replace(append(locks[:i], locks[i+1:]...)); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	return nil, fmt.Errorf("%s is not locked", request.Path)
+}
+
+// Conflicts returns the locks other people hold on the given paths (absolute or relative to the working directory)
+func (lm *LockManager) Conflicts(paths []string, owner, email string) ([]*Lock, error) {
+	locks, err := lm.GetLocks()
+	if err != nil || len(locks) == 0 {
+		return nil, err
+	}
+	var conflicts []*Lock
+	for _, path := range paths {
+		rel, err := lm.RelativePath(path)
+		if err != nil {
+			continue
+		}
+		for _, l := range locks {
+			if l.Path == rel && !l.HeldBy(owner, email) {
+				conflicts = append(conflicts, l)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// RelativePath converts a path to the slash-separated path relative to the working tree root
+func (lm *LockManager) RelativePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(lm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("locked file must be inside the repository: %s", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
@@ -0,0 +1,74 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayouts are the absolute forms accepted by ParseDate, in local time unless they carry a zone
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// relativeUnits maps the units of "3 days ago" and "2w" to durations; months and years are calendar-based
+var relativeUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour,
+}
+
+// ParseDate reads a --since/--until value: "2026-10-01", "2026-10-01 14:30", RFC 3339, "today", "yesterday",
+// "3 days ago", "2 weeks ago", "6 months ago" or the short forms "3d", "2w", "12h"
+// wholeDay is true for values naming a day, so an end bound can include all of it
+func ParseDate(value string, now time.Time) (t time.Time, wholeDay bool, err error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "":
+		return time.Time{}, false, fmt.Errorf("empty date")
+	case "now":
+		return now, false, nil
+	case "today":
+		return midnight, true, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), true, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return parsed, layout == "2006-01-02", nil
+		}
+		if parsed, err := time.ParseInLocation(layout, strings.ToUpper(value), now.Location()); err == nil {
+			return parsed, false, nil
+		}
+	}
+
+	// Relative: "<n> <unit>[s] ago" or "<n><unit>"
+	relative := strings.TrimSpace(strings.TrimSuffix(value, "ago"))
+	number := strings.TrimRightFunc(relative, func(r rune) bool { return r < '0' || r > '9' })
+	unit := strings.TrimSuffix(strings.TrimSpace(relative[len(number):]), "s")
+	if number == "" || strings.ContainsAny(number, " ") {
+		return time.Time{}, false, fmt.Errorf("unrecognized date %q (use 2026-10-01, \"3 days ago\" or 2w)", value)
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("unrecognized date %q", value)
+	}
+	switch unit {
+	case "mo", "month":
+		return now.AddDate(0, -n, 0), false, nil
+	case "y", "year":
+		return now.AddDate(-n, 0, 0), false, nil
+	}
+	if step, ok := relativeUnits[unit]; ok {
+		return now.Add(-time.Duration(n) * step), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("unrecognized date %q (use 2026-10-01, \"3 days ago\" or 2w)", value)
+}
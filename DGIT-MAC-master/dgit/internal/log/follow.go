@@ -0,0 +1,227 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileVersion is one version of a file in its history: a commit where its content or design metadata changed
+type FileVersion struct {
+	Commit         *Commit
+	Path           string                 // The file's path in this commit; differs from the followed path before a rename
+	Metadata       map[string]interface{} // The metadata recorded for the file in this commit
+	Added          bool                   // First version of the file
+	RenamedFrom    string                 // Set when this commit moved the file here
+	ContentChanged bool                   // The stored content hash differs from the previous version
+	Changes        []MetadataChange       // Design metadata that differs from the previous version
+	LayersAdded    []string
+	LayersRemoved  []string
+}
+
+// MetadataChange is one design property that differs between two versions of a file
+type MetadataChange struct {
+	Field string // e.g. "layers"
+	Old   string
+	New   string
+}
+
+// String formats a change as "layers 12→15"
+func (mc MetadataChange) String() string {
+	return fmt.Sprintf("%s %s→%s", mc.Field, mc.Old, mc.New)
+}
+
+// followedFields are the design properties compared between versions, in display order
+var followedFields = []string{"dimensions", "color_mode", "layers", "artboards", "pages", "objects", "version"}
+
+// FileHistory lists the versions of one file on the current branch, oldest first
+// Only commits where the file's content hash or design metadata changed are included; renames made with
+// 'dgit mv' are followed, so the history continues under the file's earlier paths
+func (lm *LogManager) FileHistory(path string) ([]*FileVersion, error) {
+	rootDir := filepath.Dir(lm.DgitDir)
+	current, ok := RepoPath(rootDir, path)
+	if !ok {
+		return nil, fmt.Errorf("%s is outside the repository", path)
+	}
+
+	want := lm.HeadHash()
+	if want == "" {
+		return nil, nil
+	}
+	it, err := lm.Iterate(CommitFilter{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	// Parents always have lower versions, so one pass from the newest commit walks the branch's lineage
+	// Commits recorded before parent hashes existed continue with the previous commit on their branch
+	var newestFirst []*FileVersion
+	wantBranch, byBranch := "", false
+	for it.Next() {
+		c := it.Commit()
+		if byBranch {
+			if c.Branch != wantBranch {
+				continue
+			}
+		} else if c.Hash != want {
+			continue
+		}
+
+		if meta, stored := fileMetadata(rootDir, c, current); stored != "" {
+			version := &FileVersion{Commit: c, Path: current, Metadata: meta}
+			if from, ok := c.Renames[current]; ok && from != current {
+				version.RenamedFrom = from
+			}
+			newestFirst = append(newestFirst, version)
+		}
+		if from, ok := c.Renames[current]; ok {
+			current = from
+		}
+
+		want, wantBranch, byBranch = c.ParentHash, c.Branch, c.ParentHash == ""
+		if c.Version == 1 {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	// Compare each version with the one before it and keep those that differ
+	var history []*FileVersion
+	var previous *FileVersion
+	for i := len(newestFirst) - 1; i >= 0; i-- {
+		version := newestFirst[i]
+		if previous == nil {
+			version.Added = true
+		} else {
+			compareFileVersions(previous, version)
+		}
+		if version.Added || version.RenamedFrom != "" || version.ContentChanged || len(version.Changes) > 0 ||
+			len(version.LayersAdded) > 0 || len(version.LayersRemoved) > 0 {
+			history = append(history, version)
+		}
+		previous = version
+	}
+	return history, nil
+}
+
+// fileMetadata finds a file's metadata in a commit, matching stored paths in any recorded form
+func fileMetadata(rootDir string, c *Commit, path string) (map[string]interface{}, string) {
+	if raw, ok := c.Metadata[path]; ok {
+		meta, _ := raw.(map[string]interface{})
+		return meta, path
+	}
+	for stored, raw := range c.Metadata {
+		if repoPath, ok := RepoPath(rootDir, stored); ok && repoPath == path {
+			meta, _ := raw.(map[string]interface{})
+			return meta, stored
+		}
+	}
+	return nil, ""
+}
+
+// compareFileVersions records what changed in version since previous
+func compareFileVersions(previous, version *FileVersion) {
+	oldHash, _ := previous.Metadata["sha256"].(string)
+	newHash, _ := version.Metadata["sha256"].(string)
+	version.ContentChanged = oldHash != newHash || oldHash == ""
+
+	for _, field := range followedFields {
+		oldValue, newValue := metadataString(previous.Metadata[field]), metadataString(version.Metadata[field])
+		if oldValue != newValue {
+			version.Changes = append(version.Changes, MetadataChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	oldNames := metadataStrings(previous.Metadata["layer_names"])
+	newNames := metadataStrings(version.Metadata["layer_names"])
+	for name := range newNames {
+		if !oldNames[name] {
+			version.LayersAdded = append(version.LayersAdded, name)
+		}
+	}
+	for name := range oldNames {
+		if !newNames[name] {
+			version.LayersRemoved = append(version.LayersRemoved, name)
+		}
+	}
+	sort.Strings(version.LayersAdded)
+	sort.Strings(version.LayersRemoved)
+}
+
+// metadataString formats a stored metadata value for comparison and display
+func metadataString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return "-"
+		}
+		return v
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// metadataStrings reads a stored list of names as a set
+func metadataStrings(value interface{}) map[string]bool {
+	names := make(map[string]bool)
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// WalkLineage calls fn for a commit and each commit it descends from, newest first, until fn returns false
+// Parents are looked up one at a time, so stopping early reads only the commits visited
+func (lm *LogManager) WalkLineage(tip string, fn func(*Commit) bool) error {
+	if tip == "" {
+		return nil
+	}
+	c, err := lm.GetCommitByHash(tip)
+	if err != nil {
+		return err
+	}
+	for c != nil && fn(c) {
+		parent, err := lm.parentOf(c)
+		if err != nil {
+			return err
+		}
+		if parent != nil && parent.Version >= c.Version {
+			return fmt.Errorf("v%d names v%d as its parent", c.Version, parent.Version)
+		}
+		c = parent
+	}
+	return nil
+}
+
+// parentOf returns the commit a commit was made on top of, nil for the first commit
+// Commits recorded before parent hashes existed continue with the previous commit on their branch
+func (lm *LogManager) parentOf(c *Commit) (*Commit, error) {
+	if c.ParentHash == "" {
+		for version := c.Version - 1; version > 0; version-- {
+			previous, err := lm.GetCommit(version)
+			if err == nil && previous.Branch == c.Branch {
+				return previous, nil
+			}
+		}
+		return nil, nil
+	}
+	// On a straight line the parent is the version just before; only forks need the hash lookup
+	if previous, err := lm.GetCommit(c.Version - 1); err == nil && previous.Hash == c.ParentHash {
+		return previous, nil
+	}
+	parent, err := lm.GetCommitByHash(c.ParentHash)
+	if err != nil {
+		return nil, fmt.Errorf("parent of v%d: %w", c.Version, err)
+	}
+	return parent, nil
+}
@@ -0,0 +1,204 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Graph output formats
+const (
+	GraphMermaid = "mermaid"
+	GraphDOT     = "dot"
+)
+
+// graphMaxFiles caps the file annotations on a node so large commits stay readable
+const graphMaxFiles = 5
+
+// GraphNode is one commit in the version graph
+type GraphNode struct {
+	Version int
+	Hash    string
+	Message string
+	Branch  string   // "" for the main line
+	Parent  int      // Parent version, 0 for a root
+	Labels  []string // Annotations such as HEAD or an approval state
+	Files   []string // Committed paths, when file annotations were requested
+}
+
+// BuildGraph links commits through their parent hashes, ordered by version
+// Commits recorded before parent hashes existed fall back to the previous version on their branch
+func BuildGraph(commits []*Commit, withFiles bool) []*GraphNode {
+	ordered := append([]*Commit(nil), commits...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	byHash := make(map[string]*Commit, len(ordered))
+	for _, c := range ordered {
+		byHash[c.Hash] = c
+	}
+
+	lastOnBranch := make(map[string]int)
+	var nodes []*GraphNode
+	for _, c := range ordered {
+		node := &GraphNode{
+			Version: c.Version,
+			Hash:    c.Hash,
+			Message: c.Message,
+			Branch:  c.Branch,
+		}
+		if parent, ok := byHash[c.ParentHash]; ok {
+			node.Parent = parent.Version
+		} else if c.ParentHash == "" {
+			node.Parent = lastOnBranch[c.Branch]
+		}
+		if withFiles {
+			for path := range c.Metadata {
+				node.Files = append(node.Files, path)
+			}
+			sort.Strings(node.Files)
+		}
+		lastOnBranch[c.Branch] = c.Version
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// RenderGraph writes the graph as a Mermaid flowchart or a Graphviz DOT digraph
+// Branches become subgraphs; edges point from parent to child
+func RenderGraph(nodes []*GraphNode, format string) (string, error) {
+	switch format {
+	case GraphMermaid:
+		return renderMermaid(nodes), nil
+	case GraphDOT:
+		return renderDOT(nodes), nil
+	}
+	return "", fmt.Errorf("unknown graph format %q (use %s or %s)", format, GraphMermaid, GraphDOT)
+}
+
+// renderMermaid emits a flowchart, which unlike gitGraph accepts any parent structure
+func renderMermaid(nodes []*GraphNode) string {
+	var b strings.Builder
+	b.WriteString("flowchart BT\n")
+	for _, branch := range graphBranches(nodes) {
+		indent := "  "
+		if branch != "" {
+			fmt.Fprintf(&b, "  subgraph %s[\"%s\"]\n", graphID(branch), mermaidEscape(branch))
+			indent = "    "
+		}
+		for _, node := range nodes {
+			if node.Branch == branch {
+				fmt.Fprintf(&b, "%sv%d[\"%s\"]\n", indent, node.Version, mermaidEscape(strings.Join(nodeLines(node), "\n")))
+			}
+		}
+		if branch != "" {
+			b.WriteString("  end\n")
+		}
+	}
+	for _, node := range nodes {
+		if node.Parent > 0 {
+			fmt.Fprintf(&b, "  v%d --> v%d\n", node.Parent, node.Version)
+		}
+	}
+	return b.String()
+}
+
+// renderDOT emits a Graphviz digraph with one cluster per branch
+func renderDOT(nodes []*GraphNode) string {
+	var b strings.Builder
+	b.WriteString("digraph dgit {\n  rankdir=BT;\n  node [shape=box, fontname=\"Helvetica\"];\n")
+	for _, branch := range graphBranches(nodes) {
+		indent := "  "
+		if branch != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%s {\n    label=%s;\n", graphID(branch), dotQuote(branch))
+			indent = "    "
+		}
+		for _, node := range nodes {
+			if node.Branch == branch {
+				fmt.Fprintf(&b, "%sv%d [label=%s];\n", indent, node.Version, dotQuote(strings.Join(nodeLines(node), "\n")))
+			}
+		}
+		if branch != "" {
+			b.WriteString("  }\n")
+		}
+	}
+	for _, node := range nodes {
+		if node.Parent > 0 {
+			fmt.Fprintf(&b, "  v%d -> v%d;\n", node.Parent, node.Version)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// nodeLines is the text of a node: version and hash, message, labels, then files
+func nodeLines(node *GraphNode) []string {
+	hash := node.Hash
+	if len(hash) > 8 {
+		hash = hash[:8]
+	}
+	title := fmt.Sprintf("v%d %s", node.Version, hash)
+	if len(node.Labels) > 0 {
+		title += " (" + strings.Join(node.Labels, ", ") + ")"
+	}
+	lines := []string{title}
+
+	message := strings.TrimSpace(strings.SplitN(node.Message, "\n", 2)[0])
+	if runes := []rune(message); len(runes) > 40 {
+		message = string(runes[:37]) + "..."
+	}
+	if message != "" {
+		lines = append(lines, message)
+	}
+
+	for i, path := range node.Files {
+		if i == graphMaxFiles {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(node.Files)-graphMaxFiles))
+			break
+		}
+		lines = append(lines, path)
+	}
+	return lines
+}
+
+// graphBranches lists the main line first, then other branches by name
+func graphBranches(nodes []*GraphNode) []string {
+	seen := make(map[string]bool)
+	var branches []string
+	for _, node := range nodes {
+		if node.Branch != "" && !seen[node.Branch] {
+			seen[node.Branch] = true
+			branches = append(branches, node.Branch)
+		}
+	}
+	sort.Strings(branches)
+	return append([]string{""}, branches...)
+}
+
+// graphID turns a branch name into an identifier both formats accept
+func graphID(name string) string {
+	var b strings.Builder
+	b.WriteString("branch_")
+	for _, r := range name {
+		if r < 128 && (r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// mermaidEscape makes text safe inside a quoted Mermaid label
+func mermaidEscape(text string) string {
+	text = strings.ReplaceAll(text, "\"", "#quot;")
+	text = strings.ReplaceAll(text, "<", "#lt;")
+	text = strings.ReplaceAll(text, ">", "#gt;")
+	return strings.ReplaceAll(text, "\n", "<br/>")
+}
+
+// dotQuote makes text a quoted DOT string with centered line breaks
+func dotQuote(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "\"", "\\\"")
+	return "\"" + strings.ReplaceAll(text, "\n", "\\n") + "\""
+}
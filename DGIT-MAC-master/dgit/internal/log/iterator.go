@@ -0,0 +1,135 @@
+package log
+
+import "database/sql"
+
+// CommitIterator streams commits one at a time, highest version first
+// Only the current commit is held in memory; always Close it when done
+//
+//	it, err := logManager.Iterate(log.CommitFilter{})
+//	defer it.Close()
+//	for it.Next() {
+//		c := it.Commit()
+//	}
+//	err = it.Err()
+type CommitIterator interface {
+	Next() bool      // Advances to the next commit, returning false when done or on error
+	Commit() *Commit // The current commit
+	Err() error      // The error that stopped iteration, if any
+	Close() error    // Releases the underlying storage resources
+}
+
+// Iterate returns an iterator over commits matching a filter, highest version first
+func (lm *LogManager) Iterate(filter CommitFilter) (CommitIterator, error) {
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return store.Iterate(filter)
+}
+
+// jsonIterator reads one commit file per step from a list of version numbers
+type jsonIterator struct {
+	store    *jsonStore
+	filter   CommitFilter
+	versions []int // Remaining versions, highest last
+	current  *Commit
+}
+
+// Iterate lists version numbers up front (file names only) and reads commits lazily
+func (s *jsonStore) Iterate(filter CommitFilter) (CommitIterator, error) {
+	versions, err := s.Versions()
+	if err != nil {
+		return nil, err
+	}
+	return &jsonIterator{store: s, filter: filter, versions: versions}, nil
+}
+
+// Next reads the next matching commit, skipping unreadable files like the history loader does
+func (it *jsonIterator) Next() bool {
+	it.current = nil
+	for len(it.versions) > 0 {
+		version := it.versions[len(it.versions)-1]
+		it.versions = it.versions[:len(it.versions)-1]
+
+		if it.filter.ToVersion > 0 && version > it.filter.ToVersion {
+			continue
+		}
+		if it.filter.FromVersion > 0 && version < it.filter.FromVersion {
+			it.versions = nil
+			return false
+		}
+		c, err := it.store.loadCommit(version)
+		if err != nil || !it.filter.Matches(c) {
+			continue
+		}
+		it.current = c
+		return true
+	}
+	return false
+}
+
+// Commit returns the current commit
+func (it *jsonIterator) Commit() *Commit {
+	return it.current
+}
+
+// Err always returns nil - unreadable commit files are skipped
+func (it *jsonIterator) Err() error {
+	return nil
+}
+
+// Close releases the version list
+func (it *jsonIterator) Close() error {
+	it.versions = nil
+	return nil
+}
+
+// sqliteIterator decodes rows from an open query
+type sqliteIterator struct {
+	rows    *sql.Rows
+	current *Commit
+	err     error
+}
+
+// Iterate streams matching rows straight from SQLite
+func (s *sqliteStore) Iterate(filter CommitFilter) (CommitIterator, error) {
+	where, args := sqlWhere(filter)
+	rows, err := s.db.Query(`SELECT data FROM commits`+where+` ORDER BY version DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteIterator{rows: rows}, nil
+}
+
+// Next decodes the next row, skipping rows that fail to decode
+func (it *sqliteIterator) Next() bool {
+	it.current = nil
+	for it.rows.Next() {
+		var data []byte
+		if err := it.rows.Scan(&data); err != nil {
+			it.err = err
+			return false
+		}
+		if c, err := decodeCommit(data); err == nil {
+			it.current = c
+			return true
+		}
+	}
+	it.err = it.rows.Err()
+	return false
+}
+
+// Commit returns the current commit
+func (it *sqliteIterator) Commit() *Commit {
+	return it.current
+}
+
+// Err returns the query or scan error that stopped iteration
+func (it *sqliteIterator) Err() error {
+	return it.err
+}
+
+// Close releases the query
+func (it *sqliteIterator) Close() error {
+	return it.rows.Close()
+}
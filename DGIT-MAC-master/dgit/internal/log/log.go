@@ -1,12 +1,9 @@
 package log
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -26,6 +23,11 @@ type CompressionResult struct {
 	CompressionTime  float64   `json:"compression_time_ms"` // Milliseconds - KEY METRIC for performance analysis
 	CacheLevel       string    `json:"cache_level"`         // "hot", "warm", "cold" - cache tier utilization
 	SpeedImprovement float64   `json:"speed_improvement"`   // Multiplier vs traditional methods
+	
+	// Compression feedback recorded at commit time
+	FileRatios       map[string]float64 `json:"file_ratios,omitempty"`       // Path → compressed/original
+	SkipOptimization bool               `json:"skip_optimization,omitempty"` // Content is incompressible ("store")
+	ReusedFiles      int                `json:"reused_files,omitempty"`      // Object store: files already stored by earlier commits
 }
 
 // Commit represents a single commit with enhanced ultra-fast compression information
@@ -43,6 +45,115 @@ type Commit struct {
 	// Enhanced ultra-fast compression information for performance analysis
 	SnapshotZip     string             `json:"snapshot_zip,omitempty"`     // Legacy field for backward compatibility
 	CompressionInfo *CompressionResult `json:"compression_info,omitempty"` // Ultra-fast compression metrics and data
+	LinkedAssets    []*LinkedAsset     `json:"linked_assets,omitempty"`    // Library files referenced by this commit
+
+	// Creative Cloud Library elements linked from the committed files
+	CCLibraryAssets []*CCLibraryAsset `json:"cc_library_assets,omitempty"`
+
+	// Numbered frame sequences committed as one logical asset
+	Sequences []*FrameSequence `json:"sequences,omitempty"`
+
+	// Files moved with 'dgit mv': new path → previous path
+	Renames map[string]string `json:"renames,omitempty"`
+
+	// Author email, and "flag" or "env" when the author was not the configured human identity
+	Email        string `json:"email,omitempty"`
+	AuthorSource string `json:"author_source,omitempty"`
+
+	// Contrast audit of the commit's previews, added after the commit by the audit step
+	Audit *AuditRecord `json:"audit,omitempty"`
+
+	// Working session the commit belongs to (see GroupSessions)
+	Session string `json:"session,omitempty"`
+
+	// Imported history from another repository lives on its own branch
+	Branch       string        `json:"branch,omitempty"`
+	ImportedFrom *ImportSource `json:"imported_from,omitempty"`
+
+	// Path → content in the object store, for commits with the "content" strategy
+	Blobs map[string]*BlobRef `json:"blobs,omitempty"`
+}
+
+// BlobRef points a committed file at its content in the object store
+type BlobRef struct {
+	Hash string      `json:"hash"` // SHA-256 of the uncompressed content
+	Size int64       `json:"size"`
+	Mode os.FileMode `json:"mode,omitempty"`
+}
+
+// ImportSource records where an imported commit originally came from
+type ImportSource struct {
+	Repository string    `json:"repository"` // Source repository working tree path
+	Version    int       `json:"version"`    // Version number in the source repository
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// FrameSequence describes numbered frames grouped into one asset
+// Mirrors staging.Sequence so history can be read without depending on the staging package
+type FrameSequence struct {
+	Pattern string `json:"pattern"`
+	Prefix  string `json:"prefix"`
+	Suffix  string `json:"suffix"`
+	Padding int    `json:"padding"`
+	First   int    `json:"first"`
+	Last    int    `json:"last"`
+	Frames  int    `json:"frames"`
+	Missing []int  `json:"missing,omitempty"`
+}
+
+// LinkedAsset records that a file in the commit is a specific version of a library file
+// Mirrors linked.Link so history can be read without depending on the linked package
+type LinkedAsset struct {
+	Path       string    `json:"path"`
+	Library    string    `json:"library"`
+	SourcePath string    `json:"source_path"`
+	Version    int       `json:"version"`
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// AuditRecord holds the contrast findings for a commit's previews
+type AuditRecord struct {
+	CheckedAt   time.Time       `json:"checked_at"`
+	MinContrast float64         `json:"min_contrast"`
+	Files       []*AuditFinding `json:"files"`
+}
+
+// AuditFinding is the contrast result for one file's preview
+type AuditFinding struct {
+	Source      string  `json:"source"`
+	TextRegions int     `json:"text_regions"`          // Regions with two dominant flat colors (text on a background)
+	Failing     int     `json:"failing"`               // Regions below the required contrast
+	WorstRatio  float64 `json:"worst_ratio,omitempty"` // Lowest contrast ratio found
+	Foreground  string  `json:"foreground,omitempty"`  // Colors of the worst region
+	Background  string  `json:"background,omitempty"`
+	X           int     `json:"x,omitempty"` // Worst region position in preview pixels
+	Y           int     `json:"y,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// Failed reports whether the preview has regions below the required contrast
+func (f *AuditFinding) Failed() bool {
+	return f.Failing > 0
+}
+
+// CCLibraryAsset records a Creative Cloud Library element pinned by a commit
+// Mirrors cclib.Asset so history can be read without depending on the cclib package
+type CCLibraryAsset struct {
+	LibraryID    string                `json:"library_id"`
+	LibraryName  string                `json:"library_name,omitempty"`
+	ElementID    string                `json:"element_id"`
+	Name         string                `json:"name,omitempty"`
+	Modified     string                `json:"modified,omitempty"`
+	Components   []*CCLibraryComponent `json:"components,omitempty"`
+	ReferencedBy []string              `json:"referenced_by"`
+	Error        string                `json:"error,omitempty"`
+}
+
+// CCLibraryComponent is one stored file of a pinned library element
+type CCLibraryComponent struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
 }
 
 // LogManager handles commit history operations with ultra-fast cache integration
@@ -71,85 +182,120 @@ func NewLogManager(dgitDir string) *LogManager {
 // GetCommitHistory returns complete commit history sorted by timestamp (newest first)
 // Efficiently loads all commits with ultra-fast compression information
 func (lm *LogManager) GetCommitHistory() ([]*Commit, error) {
-	entries, err := os.ReadDir(lm.ObjectsDir)
+	return lm.QueryCommits(CommitFilter{})
+}
+
+// QueryCommits returns commits matching a filter, newest first
+// The SQLite backend answers from its indexes instead of reading every commit
+func (lm *LogManager) QueryCommits(filter CommitFilter) ([]*Commit, error) {
+	store, err := OpenMetadataStore(lm.DgitDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read objects directory: %w", err)
-	}
-
-	var commits []*Commit
-	// Process all commit metadata files
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "v") && strings.HasSuffix(entry.Name(), ".json") {
-			commitPath := filepath.Join(lm.ObjectsDir, entry.Name())
-			commit, err := lm.loadCommit(commitPath)
-			if err != nil {
-				// Skip failed commits but continue processing others
-				continue
-			}
-			commits = append(commits, commit)
-		}
+		return nil, err
 	}
+	return store.Query(filter)
+}
 
-	// Sort commits by timestamp (newest first) for intuitive display
-	sort.Slice(commits, func(i, j int) bool {
-		return commits[i].Timestamp.After(commits[j].Timestamp)
-	})
-
-	return commits, nil
+// GetCommitPage returns commits matching a filter, highest version first, skipping offset and stopping at limit
+// Only the returned commits are read, so 'dgit log -n 5' stays fast on long histories (limit 0 means no limit)
+func (lm *LogManager) GetCommitPage(filter CommitFilter, offset, limit int) ([]*Commit, error) {
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return store.Page(filter, offset, limit)
 }
 
 // GetCommit returns a specific commit by version number
 // Efficiently loads individual commit with all ultra-fast metadata
 func (lm *LogManager) GetCommit(version int) (*Commit, error) {
-	commitPath := filepath.Join(lm.ObjectsDir, fmt.Sprintf("v%d.json", version))
-	return lm.loadCommit(commitPath)
+	data, err := lm.LoadCommitData(version)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCommit(data)
 }
 
 // GetCommitByHash retrieves a commit by its full or short hash
 // Supports partial hash matching for user convenience
 func (lm *LogManager) GetCommitByHash(hash string) (*Commit, error) {
-	entries, err := os.ReadDir(lm.ObjectsDir)
+	store, err := OpenMetadataStore(lm.DgitDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read objects directory: %w", err)
+		return nil, err
 	}
-
-	// Search through all commit files for hash match
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "v") && strings.HasSuffix(entry.Name(), ".json") {
-			commitPath := filepath.Join(lm.ObjectsDir, entry.Name())
-			commit, err := lm.loadCommit(commitPath)
-			if err != nil {
-				continue
-			}
-			// Support both full and partial hash matching
-			if strings.HasPrefix(commit.Hash, hash) {
-				return commit, nil
-			}
-		}
+	version, err := store.FindHash(hash)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("commit with hash '%s' not found", hash)
+	return lm.GetCommit(version)
 }
 
-// GetCurrentVersion returns the current version number by scanning metadata files
+// GetCurrentVersion returns the current version number from the metadata store
 // Efficiently determines the latest version for next commit numbering
 func (lm *LogManager) GetCurrentVersion() int {
-	entries, err := os.ReadDir(lm.ObjectsDir)
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return 0
+	}
+	version, err := store.MaxVersion()
 	if err != nil {
 		return 0
 	}
+	return version
+}
 
-	maxVersion := 0
-	// Find the highest version number in commit metadata files
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "v") && strings.HasSuffix(entry.Name(), ".json") {
-			versionStr := strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ".json"), "v")
-			if version, err := strconv.Atoi(versionStr); err == nil && version > maxVersion {
-				maxVersion = version
-			}
-		}
+// LoadCommitData returns the stored JSON of a commit exactly as written
+func (lm *LogManager) LoadCommitData(version int) ([]byte, error) {
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return nil, err
 	}
+	return store.Load(version)
+}
 
-	return maxVersion
+// SaveCommitData stores the JSON of a commit in the repository's metadata backend
+// All writers go through here so JSON and SQLite repositories stay consistent
+func (lm *LogManager) SaveCommitData(data []byte) error {
+	c, err := decodeCommit(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode commit: %w", err)
+	}
+	if c.Version <= 0 {
+		return fmt.Errorf("commit has no version number")
+	}
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return err
+	}
+	return store.Save(c, data)
+}
+
+// GetCommitStamps returns a cheap change stamp per version for incremental consumers
+func (lm *LogManager) GetCommitStamps() (map[int]string, error) {
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return store.Stamps()
+}
+
+// GetMetadataBackend returns the name of the backend holding commit metadata
+func (lm *LogManager) GetMetadataBackend() string {
+	store, err := OpenMetadataStore(lm.DgitDir)
+	if err != nil {
+		return BackendSQLite
+	}
+	return store.Backend()
+}
+
+// GetHeadVersion returns the version HEAD points to
+// Differs from GetCurrentVersion once other branches add versions beyond the current branch tip
+func (lm *LogManager) GetHeadVersion() int {
+	if head := lm.HeadHash(); head != "" {
+		if commit, err := lm.GetCommitByHash(head); err == nil {
+			return commit.Version
+		}
+	}
+	return lm.GetCurrentVersion()
 }
 
 // GenerateCommitSummary generates comprehensive human-readable summary with ultra-fast metrics
@@ -167,6 +313,11 @@ func (lm *LogManager) GenerateCommitSummary(commit *Commit) string {
 		switch commit.CompressionInfo.Strategy {
 		case "lz4":
 			summary += fmt.Sprintf(" • LZ4: %.1f%% (%.1fms)", compressionPercent, commit.CompressionInfo.CompressionTime)
+		case "content":
+			summary += fmt.Sprintf(" • Objects: %.1f%% new data", 100-compressionPercent)
+			if commit.CompressionInfo.ReusedFiles > 0 {
+				summary += fmt.Sprintf(", %d reused", commit.CompressionInfo.ReusedFiles)
+			}
 		case "psd_smart_delta":
 			summary += fmt.Sprintf(" • Smart PSD: %.1f%% saved", compressionPercent)
 		case "design_smart_delta":
@@ -206,13 +357,14 @@ func (lm *LogManager) GenerateCommitSummary(commit *Commit) string {
 // GetUltraFastCompressionStatistics returns comprehensive ultra-fast compression analytics
 // Provides detailed performance metrics across all commits for optimization insights
 func (lm *LogManager) GetUltraFastCompressionStatistics() (*UltraFastCompressionStatistics, error) {
-	commits, err := lm.GetCommitHistory()
+	it, err := lm.Iterate(CommitFilter{})
 	if err != nil {
 		return nil, err
 	}
+	defer it.Close()
 	
 	stats := &UltraFastCompressionStatistics{
-		TotalCommits:      len(commits),
+		TotalCommits:      0,
 		LegacyCommits:     0,
 		UltraFastCommits:  0,
 		TotalSavedSpace:   0,
@@ -226,8 +378,10 @@ func (lm *LogManager) GetUltraFastCompressionStatistics() (*UltraFastCompression
 	var totalSpeedImprovement float64
 	ultraFastCount := 0
 	
-	// Analyze each commit for comprehensive statistics
-	for _, commit := range commits {
+	// Analyze each commit for comprehensive statistics, streaming so memory stays flat
+	for it.Next() {
+		commit := it.Commit()
+		stats.TotalCommits++
 		if commit.CompressionInfo != nil {
 			// Track ultra-fast commits with detailed metrics
 			stats.UltraFastCommits++
@@ -257,6 +411,10 @@ func (lm *LogManager) GetUltraFastCompressionStatistics() (*UltraFastCompression
 		}
 	}
 	
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	
 	// Calculate performance averages for insights
 	if ultraFastCount > 0 {
 		stats.AvgCompressionTime = totalCompressionTime / float64(ultraFastCount)
@@ -300,6 +458,11 @@ func (lm *LogManager) GetCommitStorageInfo(commit *Commit) string {
 			float64(commit.CompressionInfo.CompressedSize)/(1024*1024),
 			commit.CompressionInfo.CacheLevel,
 			commit.CompressionInfo.CompressionTime)
+	case "content":
+		return fmt.Sprintf("Object Store: %d file(s), %.2f MB new, %d reused", 
+			len(commit.Blobs),
+			float64(commit.CompressionInfo.CompressedSize)/(1024*1024),
+			commit.CompressionInfo.ReusedFiles)
 	case "psd_smart_delta":
 		return fmt.Sprintf("Smart PSD Delta: %s (%.2f KB, base: v%d, %.1fms)", 
 			commit.CompressionInfo.OutputFile,
@@ -355,6 +518,8 @@ func (lm *LogManager) GetCommitEfficiency(commit *Commit) string {
 		return fmt.Sprintf("%.1f%% compression", compressionPercent)
 	case "bsdiff", "xdelta3":
 		return fmt.Sprintf("%.1f%% space saving", compressionPercent)
+	case "content":
+		return fmt.Sprintf("%.1f%% of the content was new", 100-compressionPercent)
 	default:
 		return fmt.Sprintf("%.1f%% efficiency", compressionPercent)
 	}
@@ -382,6 +547,8 @@ func (lm *LogManager) FindCommitsByStorageType(storageType string) ([]*Commit, e
 			// Any ultra-fast compression strategy
 			if commit.CompressionInfo != nil && 
 			   (commit.CompressionInfo.Strategy == "lz4" || 
+			    commit.CompressionInfo.Strategy == "store" ||
+			    commit.CompressionInfo.Strategy == "content" ||
 			    commit.CompressionInfo.Strategy == "psd_smart_delta" ||
 			    commit.CompressionInfo.Strategy == "design_smart_delta") {
 				filteredCommits = append(filteredCommits, commit)
@@ -431,7 +598,8 @@ func (lm *LogManager) GetRepositorySizeBreakdown() (*SizeBreakdown, error) {
 		Total:       0,
 	}
 	
-	// Calculate traditional objects directory size
+	// Calculate traditional objects directory size; content-store blobs are counted on their own
+	blobsDir := filepath.Join(lm.ObjectsDir, "blobs") + string(filepath.Separator)
 	err := filepath.Walk(lm.ObjectsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
@@ -441,7 +609,9 @@ func (lm *LogManager) GetRepositorySizeBreakdown() (*SizeBreakdown, error) {
 		breakdown.Total += size
 		
 		// Categorize files by type for detailed breakdown
-		if strings.HasSuffix(path, ".zip") {
+		if strings.HasPrefix(path, blobsDir) {
+			breakdown.ObjectBlobs += size
+		} else if strings.HasSuffix(path, ".zip") {
 			breakdown.ZipFiles += size
 		} else if strings.Contains(path, "deltas") {
 			breakdown.DeltaFiles += size
@@ -455,9 +625,21 @@ func (lm *LogManager) GetRepositorySizeBreakdown() (*SizeBreakdown, error) {
 	if err != nil {
 		return breakdown, err
 	}
+
+	// SQLite metadata lives beside the objects directory
+	if info, err := os.Stat(filepath.Join(lm.DgitDir, MetadataDBFile)); err == nil {
+		breakdown.Metadata += info.Size()
+		breakdown.Total += info.Size()
+	}
 	
 	// Calculate ultra-fast cache sizes for comprehensive analysis
 	lm.calculateCacheSize(lm.HotCacheDir, &breakdown.HotCache)
+	// Scanner results under cache/hot/metadata are metadata, not cached snapshots ('dgit cache status' agrees)
+	var scanMetadata int64
+	lm.calculateCacheSize(filepath.Join(lm.HotCacheDir, "metadata"), &scanMetadata)
+	breakdown.HotCache -= scanMetadata
+	breakdown.Metadata += scanMetadata
+	breakdown.Total += scanMetadata
 	lm.calculateCacheSize(lm.WarmCacheDir, &breakdown.WarmCache)
 	lm.calculateCacheSize(lm.ColdCacheDir, &breakdown.ColdCache)
 	
@@ -481,22 +663,24 @@ func (lm *LogManager) calculateCacheSize(cacheDir string, size *int64) {
 // SizeBreakdown represents comprehensive repository size analysis
 // Enhanced with ultra-fast cache information for complete storage visibility
 type SizeBreakdown struct {
-	ZipFiles   int64 `json:"zip_files"`    // Traditional ZIP snapshots
-	DeltaFiles int64 `json:"delta_files"`  // Delta compression files
-	Metadata   int64 `json:"metadata"`     // Commit metadata JSON files
-	HotCache   int64 `json:"hot_cache"`    // LZ4 hot cache for instant access
-	WarmCache  int64 `json:"warm_cache"`   // Zstd warm cache for balanced performance
-	ColdCache  int64 `json:"cold_cache"`   // Archive cold cache for long-term storage
-	Total      int64 `json:"total"`        // Total repository size including all caches
+	ZipFiles    int64 `json:"zip_files"`    // Traditional ZIP snapshots
+	DeltaFiles  int64 `json:"delta_files"`  // Delta compression files
+	ObjectBlobs int64 `json:"object_blobs"` // Content-store blobs shared between versions
+	Metadata    int64 `json:"metadata"`     // Commit metadata and scanner results
+	HotCache    int64 `json:"hot_cache"`    // LZ4 hot cache for instant access
+	WarmCache   int64 `json:"warm_cache"`   // Zstd warm cache for balanced performance
+	ColdCache   int64 `json:"cold_cache"`   // Archive cold cache for long-term storage
+	Total       int64 `json:"total"`        // Total repository size including all caches
 }
 
 // GetCacheUtilization returns comprehensive cache utilization statistics
 // Provides insights into 3-tier cache system performance and efficiency
 func (lm *LogManager) GetCacheUtilization() (*CacheUtilization, error) {
-	commits, err := lm.GetCommitHistory()
+	it, err := lm.Iterate(CommitFilter{})
 	if err != nil {
 		return nil, err
 	}
+	defer it.Close()
 	
 	utilization := &CacheUtilization{
 		HotCacheFiles:  0,
@@ -506,7 +690,8 @@ func (lm *LogManager) GetCacheUtilization() (*CacheUtilization, error) {
 	}
 	
 	// Analyze cache utilization across all commits
-	for _, commit := range commits {
+	for it.Next() {
+		commit := it.Commit()
 		if commit.CompressionInfo != nil {
 			// Track cache tier utilization for optimization insights
 			switch commit.CompressionInfo.CacheLevel {
@@ -521,7 +706,7 @@ func (lm *LogManager) GetCacheUtilization() (*CacheUtilization, error) {
 		}
 	}
 	
-	return utilization, nil
+	return utilization, it.Err()
 }
 
 // CacheUtilization represents detailed cache usage statistics
@@ -533,22 +718,6 @@ type CacheUtilization struct {
 	TotalCacheSize int64 `json:"total_cache_size"`  // Total cached data size
 }
 
-// loadCommit loads a commit from a JSON metadata file
-// Core function for reading commit information with error handling
-func (lm *LogManager) loadCommit(path string) (*Commit, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var commit Commit
-	if err := json.Unmarshal(data, &commit); err != nil {
-		return nil, err
-	}
-
-	return &commit, nil
-}
-
 // ============================================================================
 // LEGACY COMPATIBILITY FUNCTIONS
 // These functions maintain backward compatibility while leveraging ultra-fast improvements
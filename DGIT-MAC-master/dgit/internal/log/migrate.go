@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// MigrateMetadata moves all commit metadata to another backend
+// Every commit is read back and compared before the old copy is removed; returns the number of commits moved
+func MigrateMetadata(dgitDir, backend string) (int, error) {
+	source, err := OpenMetadataStore(dgitDir)
+	if err != nil {
+		return 0, err
+	}
+	if source.Backend() == backend {
+		return 0, fmt.Errorf("repository already uses the %s metadata backend", backend)
+	}
+
+	dbPath := filepath.Join(dgitDir, MetadataDBFile)
+	switch backend {
+	case BackendSQLite:
+		tmpPath := dbPath + ".tmp"
+		os.Remove(tmpPath)
+		target, err := openSQLiteStore(tmpPath)
+		if err != nil {
+			return 0, err
+		}
+		count, err := copyMetadata(source, target)
+		closeSQLiteStore(tmpPath)
+		if err != nil {
+			os.Remove(tmpPath)
+			return 0, err
+		}
+		if err := os.Rename(tmpPath, dbPath); err != nil {
+			os.Remove(tmpPath)
+			return 0, fmt.Errorf("failed to install metadata database: %w", err)
+		}
+		// The database is now authoritative; the JSON files would only go stale
+		jsonFiles := source.(*jsonStore)
+		versions, _ := jsonFiles.Versions()
+		for _, version := range versions {
+			os.Remove(jsonFiles.path(version))
+		}
+		return count, nil
+
+	case BackendJSON:
+		target := &jsonStore{objectsDir: filepath.Join(dgitDir, "objects")}
+		count, err := copyMetadata(source, target)
+		if err != nil {
+			return 0, err
+		}
+		closeSQLiteStore(dbPath)
+		if err := os.Remove(dbPath); err != nil {
+			return 0, fmt.Errorf("failed to remove metadata database: %w", err)
+		}
+		os.Remove(dbPath + "-journal")
+		return count, nil
+	}
+	return 0, fmt.Errorf("unknown metadata backend '%s' (use %s or %s)", backend, BackendJSON, BackendSQLite)
+}
+
+// copyMetadata copies every commit between stores and verifies the copies byte for byte
+func copyMetadata(source, target MetadataStore) (int, error) {
+	versions, err := source.Versions()
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		data, err := source.Load(version)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read commit v%d: %w", version, err)
+		}
+		c, err := decodeCommit(data)
+		if err != nil {
+			return 0, fmt.Errorf("commit v%d is corrupt: %w", version, err)
+		}
+		c.Version = version // Keep the storage key even if the recorded number disagrees
+		if err := target.Save(c, data); err != nil {
+			return 0, err
+		}
+		copied, err := target.Load(version)
+		if err != nil || !bytes.Equal(copied, data) {
+			return 0, fmt.Errorf("verification of commit v%d failed", version)
+		}
+	}
+	return len(versions), nil
+}
@@ -0,0 +1,159 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// windowsAbs matches drive-letter paths recorded on Windows ("C:/Users/...")
+var windowsAbs = regexp.MustCompile(`^[A-Za-z]:/`)
+
+// RepoPath converts a stored file path to the slash-separated form relative to the working tree root
+// Absolute paths under rootDir are made relative. Absolute paths recorded on another machine are cut
+// after the first folder named like the repository. ok is false when the path cannot be resolved
+func RepoPath(rootDir, stored string) (string, bool) {
+	p := strings.ReplaceAll(stored, "\\", "/")
+	if !strings.HasPrefix(p, "/") && !windowsAbs.MatchString(p) {
+		p = path.Clean(p)
+		return p, p != "." && p != ".." && !strings.HasPrefix(p, "../")
+	}
+
+	p = path.Clean(p)
+	root := path.Clean(filepath.ToSlash(rootDir))
+	if rel := strings.TrimPrefix(p, root+"/"); rel != p && root != "/" {
+		return rel, true
+	}
+	if name := path.Base(root); name != "/" && name != "." {
+		if i := strings.Index(p, "/"+name+"/"); i >= 0 {
+			return p[i+len(name)+2:], true
+		}
+	}
+	return p, false
+}
+
+// PathMigration reports the result of NormalizeCommitPaths
+type PathMigration struct {
+	Commits    int               // Commits whose metadata was (or would be) rewritten
+	Rewritten  map[string]string // Stored path → repo-relative path
+	Unresolved []string          // Stored paths that could not be made repo-relative; left as they are
+}
+
+// NormalizeCommitPaths rewrites the file paths in every commit's metadata to repo-relative form
+// Commits written before paths were normalized may hold absolute or differently separated paths.
+// Snapshot objects are not rewritten; restore maps the paths inside them the same way
+func NormalizeCommitPaths(dgitDir string, dryRun bool) (*PathMigration, error) {
+	store, err := OpenMetadataStore(dgitDir)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := store.Versions()
+	if err != nil {
+		return nil, err
+	}
+	rootDir := filepath.Dir(dgitDir)
+	migration := &PathMigration{Rewritten: make(map[string]string)}
+	unresolved := make(map[string]bool)
+
+	for _, version := range versions {
+		data, err := store.Load(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit v%d: %w", version, err)
+		}
+		// Work on the raw JSON so fields this package does not model are kept
+		var raw map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("commit v%d is corrupt: %w", version, err)
+		}
+		normalize := func(stored string) string {
+			rel, ok := RepoPath(rootDir, stored)
+			if !ok {
+				unresolved[stored] = true
+				return stored
+			}
+			if rel != stored {
+				migration.Rewritten[stored] = rel
+			}
+			return rel
+		}
+
+		changed := false
+		for _, key := range []string{"metadata", "blobs"} {
+			if m, ok := raw[key].(map[string]interface{}); ok {
+				changed = renameKeys(m, normalize) || changed
+			}
+		}
+		if info, ok := raw["compression_info"].(map[string]interface{}); ok {
+			if m, ok := info["file_ratios"].(map[string]interface{}); ok {
+				changed = renameKeys(m, normalize) || changed
+			}
+		}
+		if renames, ok := raw["renames"].(map[string]interface{}); ok {
+			changed = renameKeys(renames, normalize) || changed
+			for key, value := range renames {
+				if old, ok := value.(string); ok && normalize(old) != old {
+					renames[key] = normalize(old)
+					changed = true
+				}
+			}
+		}
+		if assets, ok := raw["linked_assets"].([]interface{}); ok {
+			for _, a := range assets {
+				if asset, ok := a.(map[string]interface{}); ok {
+					if p, ok := asset["path"].(string); ok && normalize(p) != p {
+						asset["path"] = normalize(p)
+						changed = true
+					}
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+		migration.Commits++
+		if dryRun {
+			continue
+		}
+
+		updated, err := json.MarshalIndent(raw, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode commit v%d: %w", version, err)
+		}
+		c, err := decodeCommit(updated)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode commit v%d: %w", version, err)
+		}
+		c.Version = version
+		if err := store.Save(c, updated); err != nil {
+			return nil, fmt.Errorf("failed to save commit v%d: %w", version, err)
+		}
+	}
+
+	for stored := range unresolved {
+		migration.Unresolved = append(migration.Unresolved, stored)
+	}
+	sort.Strings(migration.Unresolved)
+	return migration, nil
+}
+
+// renameKeys replaces every key of m with normalize(key) and reports whether any changed
+// When two keys normalize to the same path, the one already in normal form wins
+func renameKeys(m map[string]interface{}, normalize func(string) string) bool {
+	changed := false
+	for key, value := range m {
+		normalized := normalize(key)
+		if normalized == key {
+			continue
+		}
+		delete(m, key)
+		if _, exists := m[normalized]; !exists {
+			m[normalized] = value
+		}
+		changed = true
+	}
+	return changed
+}
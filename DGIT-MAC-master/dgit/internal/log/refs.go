@@ -0,0 +1,211 @@
+package log
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MainBranch is the branch holding commits whose Branch field is empty
+const MainBranch = "main"
+
+// headRefPrefix marks a symbolic HEAD, e.g. "ref: refs/heads/main"
+const headRefPrefix = "ref: refs/heads/"
+
+// validBranchName allows path-like names such as "client-feedback" or "import/alex"
+var validBranchName = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// ValidateBranchName rejects names that cannot be stored as a ref file
+func ValidateBranchName(name string) error {
+	if !validBranchName.MatchString(name) || strings.Contains(name, "..") || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid branch name %q (use letters, digits, '.', '_', '-' and '/')", name)
+	}
+	return nil
+}
+
+// BranchField returns the value stored in Commit.Branch for commits made on a branch
+func BranchField(branch string) string {
+	if branch == MainBranch {
+		return ""
+	}
+	return branch
+}
+
+// CommitBranch returns the branch a commit was made on
+func CommitBranch(c *Commit) string {
+	if c.Branch == "" {
+		return MainBranch
+	}
+	return c.Branch
+}
+
+// CurrentBranch returns the branch HEAD points to
+// Repositories from before branches existed store a raw hash in HEAD and are on main
+func (lm *LogManager) CurrentBranch() string {
+	head := lm.readHead()
+	if strings.HasPrefix(head, headRefPrefix) {
+		return strings.TrimPrefix(head, headRefPrefix)
+	}
+	return MainBranch
+}
+
+// HeadHash returns the commit hash HEAD resolves to, "" before the first commit
+func (lm *LogManager) HeadHash() string {
+	head := lm.readHead()
+	if !strings.HasPrefix(head, headRefPrefix) {
+		return head
+	}
+	hash, _ := lm.ReadRef(strings.TrimPrefix(head, headRefPrefix))
+	return hash
+}
+
+// UpdateHead moves the current branch to a new commit
+func (lm *LogManager) UpdateHead(hash string) error {
+	branch := lm.CurrentBranch()
+	if err := lm.WriteRef(branch, hash); err != nil {
+		return err
+	}
+	return lm.SetHeadBranch(branch)
+}
+
+// SetHeadBranch points HEAD at a branch without touching the working files
+func (lm *LogManager) SetHeadBranch(branch string) error {
+	if err := os.WriteFile(filepath.Join(lm.DgitDir, "HEAD"), []byte(headRefPrefix+branch+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
+	return nil
+}
+
+// ReadRef returns the commit hash a branch points to
+func (lm *LogManager) ReadRef(branch string) (string, error) {
+	if err := lm.ensureRefs(); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(lm.refPath(branch))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("branch %q does not exist", branch)
+		}
+		return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// WriteRef points a branch at a commit, creating it if needed
+func (lm *LogManager) WriteRef(branch, hash string) error {
+	if err := ValidateBranchName(branch); err != nil {
+		return err
+	}
+	if err := lm.ensureRefs(); err != nil {
+		return err
+	}
+	path := lm.refPath(branch)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create refs directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hash+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// DeleteRef removes a branch, leaving its commits in history
+func (lm *LogManager) DeleteRef(branch string) error {
+	if err := os.Remove(lm.refPath(branch)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("branch %q does not exist", branch)
+		}
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
+	}
+	// Drop directories left empty by names like "import/alex"
+	headsDir := filepath.Join(lm.DgitDir, "refs", "heads")
+	for dir := filepath.Dir(lm.refPath(branch)); dir != headsDir; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// ListRefs returns every branch with the hash it points to
+func (lm *LogManager) ListRefs() (map[string]string, error) {
+	if err := lm.ensureRefs(); err != nil {
+		return nil, err
+	}
+	headsDir := filepath.Join(lm.DgitDir, "refs", "heads")
+	refs := make(map[string]string)
+	err := filepath.WalkDir(headsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(headsDir, path)
+		refs[filepath.ToSlash(rel)] = strings.TrimSpace(string(data))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return refs, nil
+}
+
+// readHead returns the trimmed contents of HEAD
+func (lm *LogManager) readHead() string {
+	data, err := os.ReadFile(filepath.Join(lm.DgitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// refPath is the file holding a branch's commit hash
+func (lm *LogManager) refPath(branch string) string {
+	return filepath.Join(lm.DgitDir, "refs", "heads", filepath.FromSlash(branch))
+}
+
+// ensureRefs creates refs for repositories from before branches existed
+// main takes the raw hash in HEAD and every imported branch its newest commit
+func (lm *LogManager) ensureRefs() error {
+	headsDir := filepath.Join(lm.DgitDir, "refs", "heads")
+	if _, err := os.Stat(headsDir); err == nil {
+		return nil
+	}
+	head := lm.readHead()
+	if strings.HasPrefix(head, headRefPrefix) {
+		return nil
+	}
+	if err := os.MkdirAll(headsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create refs directory: %w", err)
+	}
+
+	tips := make(map[string]*Commit)
+	if commits, err := lm.GetCommitHistory(); err == nil {
+		sort.Slice(commits, func(i, j int) bool { return commits[i].Version < commits[j].Version })
+		for _, c := range commits {
+			tips[CommitBranch(c)] = c
+		}
+	}
+	if head != "" {
+		tips[MainBranch] = &Commit{Hash: head}
+	}
+	for branch, c := range tips {
+		if ValidateBranchName(branch) != nil {
+			continue
+		}
+		path := lm.refPath(branch)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create refs directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(c.Hash+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write branch %s: %w", branch, err)
+		}
+	}
+	return lm.SetHeadBranch(MainBranch)
+}
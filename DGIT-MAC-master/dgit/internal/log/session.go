@@ -0,0 +1,162 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSessionGap is the idle time after which the next commit starts a new working session
+const DefaultSessionGap = 30 * time.Minute
+
+// Session is a run of commits by one author with no idle gap longer than the session gap
+type Session struct {
+	ID      string
+	Author  string
+	Start   time.Time
+	End     time.Time
+	Commits []*Commit // Oldest first
+	Files   []*SessionFile
+}
+
+// SessionFile summarizes how one file changed over a session
+type SessionFile struct {
+	Path      string
+	Commits   int   // Commits in the session that include the file
+	FirstSize int64 // Size in the session's first commit of the file
+	LastSize  int64 // Size in the session's last commit of the file
+}
+
+// NextSession returns the session ID for a new commit
+// The commit joins prev's session when it is by the same author within the gap; otherwise it starts one
+func NextSession(prev *Commit, author string, at time.Time, hash string, gap time.Duration) string {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+	if prev != nil && prev.Session != "" && prev.Author == author && at.Sub(prev.Timestamp) <= gap && !at.Before(prev.Timestamp) {
+		return prev.Session
+	}
+	return SessionID(at, hash)
+}
+
+// SessionID names a session after its first commit
+func SessionID(start time.Time, hash string) string {
+	if len(hash) > 6 {
+		hash = hash[:6]
+	}
+	return fmt.Sprintf("%s-%s", start.Format("20060102-1504"), hash)
+}
+
+// GroupSessions groups commits into sessions, newest session first
+// Commits recorded before sessions existed are grouped by the same author and gap rule
+func GroupSessions(commits []*Commit, gap time.Duration) []*Session {
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+	ordered := append([]*Commit(nil), commits...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	byID := make(map[string]*Session)
+	var sessions []*Session
+	var current *Session
+	for _, c := range ordered {
+		id := c.Session
+		if id == "" {
+			if current != nil && current.Author == c.Author && strings.HasPrefix(current.ID, "~") &&
+				c.Timestamp.Sub(current.End) <= gap && !c.Timestamp.Before(current.End) {
+				id = current.ID
+			} else {
+				// Derived sessions are marked so they never merge with recorded ones
+				id = "~" + SessionID(c.Timestamp, c.Hash)
+			}
+		}
+		session := byID[id]
+		if session == nil {
+			session = &Session{ID: id, Author: c.Author, Start: c.Timestamp}
+			byID[id] = session
+			sessions = append(sessions, session)
+		}
+		session.Commits = append(session.Commits, c)
+		if c.Timestamp.After(session.End) {
+			session.End = c.Timestamp
+		}
+		current = session
+	}
+
+	for _, session := range sessions {
+		session.Files = summarizeSessionFiles(session.Commits)
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Commits[0].Version > sessions[j].Commits[0].Version
+	})
+	return sessions
+}
+
+// FindSession returns the session whose ID starts with prefix
+func FindSession(sessions []*Session, prefix string) (*Session, error) {
+	var found *Session
+	for _, session := range sessions {
+		if strings.HasPrefix(strings.TrimPrefix(session.ID, "~"), strings.TrimPrefix(prefix, "~")) {
+			if found != nil {
+				return nil, fmt.Errorf("session %q is ambiguous", prefix)
+			}
+			found = session
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no session %q", prefix)
+	}
+	return found, nil
+}
+
+// Label describes when a session happened, e.g. "Tuesday afternoon, Oct 13"
+func (s *Session) Label() string {
+	part := "night"
+	switch hour := s.Start.Hour(); {
+	case hour >= 5 && hour < 12:
+		part = "morning"
+	case hour >= 12 && hour < 17:
+		part = "afternoon"
+	case hour >= 17 && hour < 22:
+		part = "evening"
+	}
+	return fmt.Sprintf("%s %s, %s", s.Start.Weekday(), part, s.Start.Format("Jan 2 2006"))
+}
+
+// MainFile is the file committed most often in the session, breaking ties by size
+func (s *Session) MainFile() *SessionFile {
+	var main *SessionFile
+	for _, f := range s.Files {
+		if main == nil || f.Commits > main.Commits || (f.Commits == main.Commits && f.LastSize > main.LastSize) {
+			main = f
+		}
+	}
+	return main
+}
+
+// summarizeSessionFiles collects per-file commit counts and first/last sizes
+func summarizeSessionFiles(commits []*Commit) []*SessionFile {
+	byPath := make(map[string]*SessionFile)
+	var files []*SessionFile
+	for _, c := range commits {
+		for path, raw := range c.Metadata {
+			var size int64
+			if meta, ok := raw.(map[string]interface{}); ok {
+				if value, ok := meta["size"].(float64); ok {
+					size = int64(value)
+				}
+			}
+			f := byPath[path]
+			if f == nil {
+				f = &SessionFile{Path: path, FirstSize: size}
+				byPath[path] = f
+				files = append(files, f)
+			}
+			f.Commits++
+			f.LastSize = size
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FutureTolerance is how far ahead of this machine's clock a commit may be before it is flagged
+const FutureTolerance = 5 * time.Minute
+
+// TimestampAnomaly describes a commit whose timestamp contradicts the version order
+// History is ordered by version and parent links, so anomalies are reported but never reorder anything
+type TimestampAnomaly struct {
+	Version int
+	Reason  string
+}
+
+// FindTimestampAnomalies flags commits dated before their parent or in the future
+// Imported commits keep their original dates and are only checked against the future
+func FindTimestampAnomalies(commits []*Commit, now time.Time) []*TimestampAnomaly {
+	ordered := append([]*Commit(nil), commits...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Version < ordered[j].Version })
+
+	byHash := make(map[string]*Commit, len(ordered))
+	for _, c := range ordered {
+		byHash[c.Hash] = c
+	}
+
+	var anomalies []*TimestampAnomaly
+	var previous *Commit
+	for _, c := range ordered {
+		parent := byHash[c.ParentHash]
+		if parent == nil {
+			parent = previous
+		}
+		switch {
+		case c.Timestamp.After(now.Add(FutureTolerance)):
+			anomalies = append(anomalies, &TimestampAnomaly{Version: c.Version,
+				Reason: fmt.Sprintf("dated %s, in the future", c.Timestamp.Format("2006-01-02 15:04"))})
+		case c.ImportedFrom == nil && parent != nil && parent.ImportedFrom == nil && c.Timestamp.Before(parent.Timestamp):
+			anomalies = append(anomalies, &TimestampAnomaly{Version: c.Version,
+				Reason: fmt.Sprintf("dated %s, before its parent v%d (%s)", c.Timestamp.Format("2006-01-02 15:04"),
+					parent.Version, parent.Timestamp.Format("2006-01-02 15:04"))})
+		}
+		previous = c
+	}
+	return anomalies
+}
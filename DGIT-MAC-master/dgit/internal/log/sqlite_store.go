@@ -0,0 +1,259 @@
+package log
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the commit table; scalar columns mirror the JSON for indexed queries
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS commits (
+	version     INTEGER PRIMARY KEY,
+	hash        TEXT NOT NULL,
+	parent_hash TEXT NOT NULL DEFAULT '',
+	timestamp   INTEGER NOT NULL,
+	author      TEXT NOT NULL DEFAULT '',
+	message     TEXT NOT NULL DEFAULT '',
+	branch      TEXT NOT NULL DEFAULT '',
+	files_count INTEGER NOT NULL DEFAULT 0,
+	updated_at  INTEGER NOT NULL,
+	data        BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS commits_hash ON commits(hash);
+CREATE INDEX IF NOT EXISTS commits_timestamp ON commits(timestamp);
+CREATE INDEX IF NOT EXISTS commits_author ON commits(author);
+`
+
+// Open databases are shared per path for the life of the process
+var (
+	sqliteMu  sync.Mutex
+	sqliteDBs = make(map[string]*sql.DB)
+	schemas   = make(map[string]bool) // Path and schema already applied to it
+)
+
+// sqliteStore keeps commit metadata in a single SQLite database
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// openSQLiteStore opens (creating if needed) the metadata database at path
+func openSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := OpenDatabase(path, sqliteSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+// OpenDatabase opens (creating if needed) a SQLite database shared by the whole process
+// Each schema runs once per process and must only contain IF NOT EXISTS statements; other packages
+// use this to keep their own tables in the metadata database
+func OpenDatabase(path, schema string) (*sql.DB, error) {
+	sqliteMu.Lock()
+	defer sqliteMu.Unlock()
+
+	db, ok := sqliteDBs[path]
+	if !ok {
+		var err error
+		if db, err = sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)"); err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		sqliteDBs[path] = db
+	}
+	if key := path + "\x00" + schema; !schemas[key] {
+		if _, err := db.Exec(schema); err != nil {
+			return nil, fmt.Errorf("failed to initialize %s: %w", filepath.Base(path), err)
+		}
+		schemas[key] = true
+	}
+	return db, nil
+}
+
+// closeSQLiteStore closes a shared database so its file can be replaced or removed
+func closeSQLiteStore(path string) {
+	sqliteMu.Lock()
+	defer sqliteMu.Unlock()
+	if db, ok := sqliteDBs[path]; ok {
+		db.Close()
+		delete(sqliteDBs, path)
+	}
+	for key := range schemas {
+		if strings.HasPrefix(key, path+"\x00") {
+			delete(schemas, key)
+		}
+	}
+}
+
+// Backend returns the backend name
+func (s *sqliteStore) Backend() string {
+	return BackendSQLite
+}
+
+// MaxVersion returns the highest stored version
+func (s *sqliteStore) MaxVersion() (int, error) {
+	var version sql.NullInt64
+	if err := s.db.QueryRow(`SELECT MAX(version) FROM commits`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query latest version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// Versions lists stored versions in ascending order
+func (s *sqliteStore) Versions() ([]int, error) {
+	rows, err := s.db.Query(`SELECT version FROM commits ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// Load returns the JSON of one commit
+func (s *sqliteStore) Load(version int) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM commits WHERE version = ?`, version).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("commit v%d: %w", version, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit v%d: %w", version, err)
+	}
+	return data, nil
+}
+
+// Save inserts or replaces one commit
+func (s *sqliteStore) Save(c *Commit, data []byte) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO commits
+		(version, hash, parent_hash, timestamp, author, message, branch, files_count, updated_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Version, c.Hash, c.ParentHash, c.Timestamp.UnixNano(), c.Author, c.Message, c.Branch,
+		c.FilesCount, time.Now().UnixNano(), data)
+	if err != nil {
+		return fmt.Errorf("failed to write commit v%d: %w", c.Version, err)
+	}
+	return nil
+}
+
+// FindHash looks up a hash prefix
+func (s *sqliteStore) FindHash(prefix string) (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT version FROM commits WHERE substr(hash, 1, ?) = ? ORDER BY version LIMIT 1`,
+		len(prefix), prefix).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("commit with hash '%s' not found", prefix)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up hash: %w", err)
+	}
+	return version, nil
+}
+
+// Query pushes the filter into SQL and decodes only matching commits
+func (s *sqliteStore) Query(filter CommitFilter) ([]*Commit, error) {
+	where, args := sqlWhere(filter)
+	return s.queryCommits(`SELECT data FROM commits`+where+` ORDER BY version DESC`, args...)
+}
+
+// Page lets SQLite order, skip, and limit so only the returned rows are decoded
+func (s *sqliteStore) Page(filter CommitFilter, offset, limit int) ([]*Commit, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	where, args := sqlWhere(filter)
+	args = append(args, limit, offset)
+	return s.queryCommits(`SELECT data FROM commits`+where+` ORDER BY version DESC LIMIT ? OFFSET ?`, args...)
+}
+
+// queryCommits runs a query selecting commit data and decodes the rows
+func (s *sqliteStore) queryCommits(query string, args ...interface{}) ([]*Commit, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var commits []*Commit
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		c, err := decodeCommit(data)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, c)
+	}
+	return commits, rows.Err()
+}
+
+// sqlWhere translates a filter into a WHERE clause over the indexed columns
+func sqlWhere(filter CommitFilter) (string, []interface{}) {
+	var where []string
+	var args []interface{}
+	if filter.FromVersion > 0 {
+		where, args = append(where, "version >= ?"), append(args, filter.FromVersion)
+	}
+	if filter.ToVersion > 0 {
+		where, args = append(where, "version <= ?"), append(args, filter.ToVersion)
+	}
+	if filter.Author != "" {
+		where, args = append(where, "author = ?"), append(args, filter.Author)
+	}
+	if filter.AuthorMatch != "" {
+		where, args = append(where, "instr(lower(author), ?) > 0"), append(args, strings.ToLower(filter.AuthorMatch))
+	}
+	if filter.Message != "" {
+		where, args = append(where, "instr(lower(message), ?) > 0"), append(args, strings.ToLower(filter.Message))
+	}
+	if filter.Branch != "" {
+		where, args = append(where, "branch = ?"), append(args, filter.Branch)
+	}
+	if !filter.Since.IsZero() {
+		where, args = append(where, "timestamp >= ?"), append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where, args = append(where, "timestamp < ?"), append(args, filter.Until.UnixNano())
+	}
+	if len(where) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(where, " AND "), args
+}
+
+// Stamps uses the time each row was last written
+func (s *sqliteStore) Stamps() (map[int]string, error) {
+	rows, err := s.db.Query(`SELECT version, updated_at, length(data) FROM commits`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit stamps: %w", err)
+	}
+	defer rows.Close()
+
+	stamps := make(map[int]string)
+	for rows.Next() {
+		var version, size int
+		var updated int64
+		if err := rows.Scan(&version, &updated, &size); err != nil {
+			return nil, err
+		}
+		stamps[version] = fmt.Sprintf("db-%d-%d", size, updated)
+	}
+	return stamps, rows.Err()
+}
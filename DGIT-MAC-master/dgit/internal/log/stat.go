@@ -0,0 +1,73 @@
+package log
+
+import "sort"
+
+// FileStat is the size change of one file in a commit
+type FileStat struct {
+	Path            string
+	Size            int64
+	PreviousSize    int64
+	PreviousVersion int // 0 when no earlier version contained the file
+}
+
+// Delta returns the size change versus the previous version of the file
+func (fs *FileStat) Delta() int64 {
+	return fs.Size - fs.PreviousSize
+}
+
+// GetFileStats returns per-file size changes for a commit, largest growth first
+// Each file is compared with the closest earlier version on the same branch that contained it
+func (lm *LogManager) GetFileStats(commit *Commit) ([]*FileStat, error) {
+	var stats []*FileStat
+	pending := make(map[string]*FileStat)
+	for path, metadata := range commit.Metadata {
+		size, _ := metadataSize(metadata)
+		stat := &FileStat{Path: path, Size: size}
+		stats = append(stats, stat)
+		pending[path] = stat
+	}
+
+	if len(pending) > 0 && commit.Version > 1 {
+		it, err := lm.Iterate(CommitFilter{ToVersion: commit.Version - 1})
+		if err != nil {
+			return nil, err
+		}
+		defer it.Close()
+
+		// Walk back only until every file has found its previous version
+		for len(pending) > 0 && it.Next() {
+			previous := it.Commit()
+			if previous.Branch != commit.Branch {
+				continue
+			}
+			for path, stat := range pending {
+				if size, ok := metadataSize(previous.Metadata[path]); ok {
+					stat.PreviousSize = size
+					stat.PreviousVersion = previous.Version
+					delete(pending, path)
+				}
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Delta() != stats[j].Delta() {
+			return stats[i].Delta() > stats[j].Delta()
+		}
+		return stats[i].Path < stats[j].Path
+	})
+	return stats, nil
+}
+
+// metadataSize reads the stored size of a file from commit metadata
+func metadataSize(metadata interface{}) (int64, bool) {
+	metaMap, ok := metadata.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	size, ok := metaMap["size"].(float64)
+	return int64(size), ok
+}
@@ -0,0 +1,274 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata backends
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// MetadataDBFile is the SQLite metadata database inside .dgit
+// Its presence switches the repository to the SQLite backend
+const MetadataDBFile = "metadata.db"
+
+// CommitFilter narrows a history query; zero values match everything
+type CommitFilter struct {
+	FromVersion int       // Lowest version to include
+	ToVersion   int       // Highest version to include
+	Author      string    // Exact author name
+	AuthorMatch string    // Case-insensitive part of the author name
+	Branch      string    // Branch name ("" matches every branch)
+	Since       time.Time // Committed at or after
+	Until       time.Time // Committed before
+	Message     string    // Case-insensitive part of the commit message
+}
+
+// Matches reports whether a commit passes the filter
+func (f CommitFilter) Matches(c *Commit) bool {
+	if f.FromVersion > 0 && c.Version < f.FromVersion {
+		return false
+	}
+	if f.ToVersion > 0 && c.Version > f.ToVersion {
+		return false
+	}
+	if f.Author != "" && c.Author != f.Author {
+		return false
+	}
+	if f.AuthorMatch != "" && !containsFold(c.Author, f.AuthorMatch) {
+		return false
+	}
+	if f.Message != "" && !containsFold(c.Message, f.Message) {
+		return false
+	}
+	if f.Branch != "" && c.Branch != f.Branch {
+		return false
+	}
+	if !f.Since.IsZero() && c.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !c.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether part occurs in s, ignoring case
+func containsFold(s, part string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(part))
+}
+
+// MetadataStore persists commit metadata
+// Commits are stored as their JSON encoding so every backend round-trips them unchanged
+type MetadataStore interface {
+	Backend() string
+	MaxVersion() (int, error)
+	Versions() ([]int, error)                                       // Ascending
+	Load(version int) ([]byte, error)                               // Raw commit JSON
+	Save(c *Commit, data []byte) error                              // data is the JSON encoding of c
+	FindHash(prefix string) (int, error)                            // Lowest version whose hash starts with prefix
+	Query(filter CommitFilter) ([]*Commit, error)                   // Newest first
+	Page(filter CommitFilter, offset, limit int) ([]*Commit, error) // Highest version first, limit 0 for all
+	Iterate(filter CommitFilter) (CommitIterator, error)            // Highest version first, streamed
+	Stamps() (map[int]string, error)                                // Cheap per-version change stamps
+}
+
+// OpenMetadataStore opens the metadata store the repository is configured for
+func OpenMetadataStore(dgitDir string) (MetadataStore, error) {
+	dbPath := filepath.Join(dgitDir, MetadataDBFile)
+	if _, err := os.Stat(dbPath); err == nil {
+		return openSQLiteStore(dbPath)
+	}
+	return &jsonStore{objectsDir: filepath.Join(dgitDir, "objects")}, nil
+}
+
+// jsonStore keeps one vN.json file per commit in the objects directory
+type jsonStore struct {
+	objectsDir string
+}
+
+// Backend returns the backend name
+func (s *jsonStore) Backend() string {
+	return BackendJSON
+}
+
+// MaxVersion returns the highest stored version from file names alone
+func (s *jsonStore) MaxVersion() (int, error) {
+	versions, err := s.Versions()
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Versions lists stored versions in ascending order
+func (s *jsonStore) Versions() ([]int, error) {
+	entries, err := os.ReadDir(s.objectsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read objects directory: %w", err)
+	}
+	var versions []int
+	for _, entry := range entries {
+		if version, ok := parseCommitFileName(entry.Name()); ok && !entry.IsDir() {
+			versions = append(versions, version)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Load reads the JSON of one commit
+func (s *jsonStore) Load(version int) ([]byte, error) {
+	return os.ReadFile(s.path(version))
+}
+
+// Save writes one commit file
+func (s *jsonStore) Save(c *Commit, data []byte) error {
+	if err := os.MkdirAll(s.objectsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create objects directory: %w", err)
+	}
+	if err := os.WriteFile(s.path(c.Version), data, 0644); err != nil {
+		return fmt.Errorf("failed to write commit v%d: %w", c.Version, err)
+	}
+	return nil
+}
+
+// FindHash scans commit files for a hash prefix
+func (s *jsonStore) FindHash(prefix string) (int, error) {
+	versions, err := s.Versions()
+	if err != nil {
+		return 0, err
+	}
+	for _, version := range versions {
+		c, err := s.loadCommit(version)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(c.Hash, prefix) {
+			return version, nil
+		}
+	}
+	return 0, fmt.Errorf("commit with hash '%s' not found", prefix)
+}
+
+// Query loads every commit file and filters in memory
+func (s *jsonStore) Query(filter CommitFilter) ([]*Commit, error) {
+	versions, err := s.Versions()
+	if err != nil {
+		return nil, err
+	}
+	var commits []*Commit
+	for _, version := range versions {
+		if (filter.FromVersion > 0 && version < filter.FromVersion) || (filter.ToVersion > 0 && version > filter.ToVersion) {
+			continue
+		}
+		c, err := s.loadCommit(version)
+		if err != nil {
+			// Skip failed commits but continue processing others
+			continue
+		}
+		if filter.Matches(c) {
+			commits = append(commits, c)
+		}
+	}
+	sortNewestFirst(commits)
+	return commits, nil
+}
+
+// Page walks file names from the highest version down and only reads the commits it returns
+func (s *jsonStore) Page(filter CommitFilter, offset, limit int) ([]*Commit, error) {
+	it, err := s.Iterate(filter)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	return collectPage(it, offset, limit)
+}
+
+// Stamps uses file size and modification time so no file contents are read
+func (s *jsonStore) Stamps() (map[int]string, error) {
+	versions, err := s.Versions()
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[int]string, len(versions))
+	for _, version := range versions {
+		info, err := os.Stat(s.path(version))
+		if err != nil {
+			continue
+		}
+		stamps[version] = fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+	}
+	return stamps, nil
+}
+
+// loadCommit reads and decodes one commit file
+func (s *jsonStore) loadCommit(version int) (*Commit, error) {
+	data, err := s.Load(version)
+	if err != nil {
+		return nil, err
+	}
+	return decodeCommit(data)
+}
+
+// path returns the metadata file of a version
+func (s *jsonStore) path(version int) string {
+	return filepath.Join(s.objectsDir, fmt.Sprintf("v%d.json", version))
+}
+
+// parseCommitFileName extracts the version from a vN.json file name
+func parseCommitFileName(name string) (int, bool) {
+	if !strings.HasPrefix(name, "v") || !strings.HasSuffix(name, ".json") {
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".json"))
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// decodeCommit decodes commit JSON
+func decodeCommit(data []byte) (*Commit, error) {
+	var commit Commit
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return nil, err
+	}
+	return &commit, nil
+}
+
+// sortNewestFirst orders commits by version, newest first
+// Versions only ever increase, so a wrong laptop clock cannot reorder history
+func sortNewestFirst(commits []*Commit) {
+	sort.SliceStable(commits, func(i, j int) bool {
+		return commits[i].Version > commits[j].Version
+	})
+}
+
+// collectPage reads one page of commits from an iterator
+func collectPage(it CommitIterator, offset, limit int) ([]*Commit, error) {
+	var commits []*Commit
+	for it.Next() {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		commits = append(commits, it.Commit())
+		if limit > 0 && len(commits) == limit {
+			break
+		}
+	}
+	return commits, it.Err()
+}
@@ -0,0 +1,134 @@
+package move
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/group"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/linked"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/pin"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+)
+
+// Result describes a completed move and the references that followed the file
+type Result struct {
+	From   string   // Previous path relative to the working tree root
+	To     string   // New path relative to the working tree root
+	Groups []string // Asset groups whose members were updated
+	Pinned bool     // A pin moved with the file
+	Linked bool     // A linked asset registration moved with the file
+}
+
+// MoveManager renames tracked files so history, asset groups, pins and links follow them
+type MoveManager struct {
+	DgitDir string
+	RootDir string
+}
+
+// NewMoveManager creates a new move manager for the given .dgit directory
+func NewMoveManager(dgitDir string) *MoveManager {
+	return &MoveManager{
+		DgitDir: dgitDir,
+		RootDir: filepath.Dir(dgitDir),
+	}
+}
+
+// Move renames src to dst in the working tree and stages the rename
+// When dst is a folder (existing, or ending in a slash) the file keeps its name inside it
+func (mm *MoveManager) Move(src, dst string) (*Result, error) {
+	srcAbs, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", src, err)
+	}
+	dstAbs, err := filepath.Abs(dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dst, err)
+	}
+	if info, err := os.Stat(dstAbs); (err == nil && info.IsDir()) || strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator)) {
+		dstAbs = filepath.Join(dstAbs, filepath.Base(srcAbs))
+	}
+
+	from, err := mm.relativeToRoot(srcAbs)
+	if err != nil {
+		return nil, err
+	}
+	to, err := mm.relativeToRoot(dstAbs)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(srcAbs)
+	if err != nil {
+		return nil, fmt.Errorf("%s does not exist", from)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a folder; move the files inside it individually", from)
+	}
+	if _, err := os.Stat(dstAbs); err == nil {
+		return nil, fmt.Errorf("%s already exists", to)
+	}
+
+	stagingArea := staging.NewStagingArea(mm.DgitDir)
+	if err := stagingArea.LoadStaging(); err != nil {
+		return nil, err
+	}
+	if !stagingArea.HasFile(srcAbs) && !mm.inHistory(from) {
+		return nil, fmt.Errorf("%s is not tracked; use a plain file move and 'dgit add'", from)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstAbs), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination folder: %w", err)
+	}
+	if err := os.Rename(srcAbs, dstAbs); err != nil {
+		return nil, fmt.Errorf("failed to move %s: %w", from, err)
+	}
+	if err := stagingArea.StageRename(srcAbs, dstAbs); err != nil {
+		os.Rename(dstAbs, srcAbs)
+		return nil, fmt.Errorf("failed to stage move: %w", err)
+	}
+	if err := stagingArea.SaveStaging(); err != nil {
+		return nil, err
+	}
+
+	result := &Result{From: from, To: to}
+	if result.Groups, err = group.NewGroupManager(mm.DgitDir).RenamePath(from, to); err != nil {
+		return result, fmt.Errorf("failed to update asset groups: %w", err)
+	}
+	if p, err := pin.NewPinManager(mm.DgitDir).RenamePath(from, to); err != nil {
+		return result, fmt.Errorf("failed to update pins: %w", err)
+	} else {
+		result.Pinned = p != nil
+	}
+	if link, err := linked.NewLinkManager(mm.DgitDir).RenamePath(from, to); err != nil {
+		return result, fmt.Errorf("failed to update linked assets: %w", err)
+	} else {
+		result.Linked = link != nil
+	}
+	return result, nil
+}
+
+// inHistory reports whether any commit contains the path
+func (mm *MoveManager) inHistory(path string) bool {
+	commits, err := log.NewLogManager(mm.DgitDir).GetCommitHistory()
+	if err != nil {
+		return false
+	}
+	for _, c := range commits {
+		if _, ok := c.Metadata[path]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// relativeToRoot converts an absolute path to a slash-separated path relative to the working tree root
+func (mm *MoveManager) relativeToRoot(absPath string) (string, error) {
+	relPath, err := filepath.Rel(mm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("path must be inside the repository: %s", absPath)
+	}
+	return filepath.ToSlash(relPath), nil
+}
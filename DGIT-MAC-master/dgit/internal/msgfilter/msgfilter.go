@@ -0,0 +1,235 @@
+package msgfilter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Filter normalizes or validates a commit message before the commit is saved
+// Returning an error rejects the commit
+type Filter interface {
+	Name() string
+	Apply(message string) (string, error)
+}
+
+// Factory builds a filter from the repository's commit settings
+type Factory func(dgitDir string, config initializer.CommitConfig) Filter
+
+// DefaultFilters run when the repository config does not list any
+var DefaultFilters = []string{"trim", "require-message"}
+
+// ErrEmpty is returned by require-message for blank messages
+var ErrEmpty = errors.New("commit message cannot be empty")
+
+var registry = map[string]Factory{}
+
+// Register makes a filter available to the message_filters setting
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Available returns the registered filter names in sorted order
+func Available() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("trim", func(string, initializer.CommitConfig) Filter { return trimFilter{} })
+	Register("require-message", func(string, initializer.CommitConfig) Filter { return requireFilter{} })
+	Register("prefix", func(_ string, config initializer.CommitConfig) Filter {
+		return prefixFilter{prefixes: config.MessagePrefixes}
+	})
+	Register("emoji", func(string, initializer.CommitConfig) Filter { return emojiFilter{} })
+	Register("commit-msg", func(dgitDir string, _ initializer.CommitConfig) Filter {
+		return hookFilter{dgitDir: dgitDir}
+	})
+}
+
+// FilterManager applies a repository's configured message filters
+type FilterManager struct {
+	DgitDir string
+}
+
+// NewFilterManager creates a new filter manager for the given .dgit directory
+func NewFilterManager(dgitDir string) *FilterManager {
+	return &FilterManager{DgitDir: dgitDir}
+}
+
+// Filters returns the configured filters in the order they run
+func (fm *FilterManager) Filters() ([]Filter, error) {
+	var config initializer.CommitConfig
+	if repoConfig, err := initializer.GetRepositoryConfig(fm.DgitDir); err == nil {
+		config = repoConfig.Commit
+	}
+	names := config.MessageFilters
+	if names == nil {
+		names = DefaultFilters
+	}
+
+	filters := make([]Filter, 0, len(names))
+	for _, name := range names {
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown message filter %q (available: %s)", name, strings.Join(Available(), ", "))
+		}
+		filters = append(filters, factory(fm.DgitDir, config))
+	}
+	return filters, nil
+}
+
+// Apply runs the message through every configured filter in order
+func (fm *FilterManager) Apply(message string) (string, error) {
+	filters, err := fm.Filters()
+	if err != nil {
+		return "", err
+	}
+	for _, filter := range filters {
+		message, err = filter.Apply(message)
+		if err != nil {
+			return "", fmt.Errorf("commit message rejected by %s: %w", filter.Name(), err)
+		}
+	}
+	return message, nil
+}
+
+// trimFilter removes surrounding whitespace, trailing spaces and repeated blank lines
+type trimFilter struct{}
+
+func (trimFilter) Name() string { return "trim" }
+
+func (trimFilter) Apply(message string) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && len(kept) > 0 && kept[len(kept)-1] == "" {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), nil
+}
+
+// requireFilter rejects blank messages
+type requireFilter struct{}
+
+func (requireFilter) Name() string { return "require-message" }
+
+func (requireFilter) Apply(message string) (string, error) {
+	if strings.TrimSpace(message) == "" {
+		return "", ErrEmpty
+	}
+	return message, nil
+}
+
+// prefixFilter requires one of the configured prefixes and normalizes its case and spacing
+// "FIX:logo" becomes "fix: logo" when "fix:" is configured
+type prefixFilter struct {
+	prefixes []string
+}
+
+func (prefixFilter) Name() string { return "prefix" }
+
+func (f prefixFilter) Apply(message string) (string, error) {
+	if len(f.prefixes) == 0 {
+		return message, nil
+	}
+	for _, prefix := range f.prefixes {
+		if len(message) >= len(prefix) && strings.EqualFold(message[:len(prefix)], prefix) {
+			rest := strings.TrimLeft(message[len(prefix):], " ")
+			return strings.TrimRight(prefix+" "+rest, " "), nil
+		}
+	}
+	return "", fmt.Errorf("message must start with one of: %s", strings.Join(f.prefixes, ", "))
+}
+
+// emojiFilter replaces :shortcode: with the emoji it names; unknown shortcodes are left as typed
+type emojiFilter struct{}
+
+var shortcodePattern = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+
+var shortcodes = map[string]string{
+	":art:":              "🎨",
+	":sparkles:":         "✨",
+	":bug:":              "🐛",
+	":fire:":             "🔥",
+	":rocket:":           "🚀",
+	":memo:":             "📝",
+	":lipstick:":         "💄",
+	":white_check_mark:": "✅",
+	":construction:":     "🚧",
+	":recycle:":          "♻️",
+	":tada:":             "🎉",
+	":wrench:":           "🔧",
+	":lock:":             "🔒",
+	":zap:":              "⚡",
+	":pencil2:":          "✏️",
+	":framed_picture:":   "🖼️",
+	":eyes:":             "👀",
+	":+1:":               "👍",
+}
+
+func (emojiFilter) Name() string { return "emoji" }
+
+func (emojiFilter) Apply(message string) (string, error) {
+	return shortcodePattern.ReplaceAllStringFunc(message, func(code string) string {
+		if emoji, ok := shortcodes[code]; ok {
+			return emoji
+		}
+		return code
+	}), nil
+}
+
+// hookFilter runs commit-msg hook scripts, which may rewrite DGIT_MESSAGE_FILE or exit non-zero to reject
+type hookFilter struct {
+	dgitDir string
+}
+
+func (hookFilter) Name() string { return "commit-msg" }
+
+func (f hookFilter) Apply(message string) (string, error) {
+	if len(hooks.Scripts(f.dgitDir, "commit-msg")) == 0 {
+		return message, nil
+	}
+	tempDir := filepath.Join(f.dgitDir, "temp")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(tempDir, "commit-msg-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create message file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(message)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write message file: %w", err)
+	}
+
+	if err := hooks.Run(f.dgitDir, "commit-msg", map[string]string{
+		"DGIT_MESSAGE":      message,
+		"DGIT_MESSAGE_FILE": tmp.Name(),
+	}); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
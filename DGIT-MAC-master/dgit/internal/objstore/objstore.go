@@ -0,0 +1,455 @@
+package objstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/storage"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// Strategy is the CompressionInfo strategy of commits whose files live in the object store
+const Strategy = "content"
+
+// BlobDir is the directory holding blobs, relative to .dgit
+var BlobDir = filepath.Join("objects", "blobs")
+
+// blobCodecs are the codecs blobs may be stored with, in lookup order
+// Commits write LZ4 or store blobs; 'dgit optimize' recompresses older LZ4 blobs to Zstd
+var blobCodecs = []string{codec.LZ4, codec.Store, codec.Zstd}
+
+// ObjectStore keeps each distinct file content once, compressed, under its SHA-256
+// Layout: .dgit/objects/blobs/<first two hex digits>/<hash><codec extension>
+type ObjectStore struct {
+	DgitDir  string
+	BlobsDir string
+	IO       *iosched.Scheduler // Paces source reads in Put when set
+}
+
+// PutResult describes one file added to the store
+type PutResult struct {
+	Ref    *log.BlobRef
+	Stored int64 // Compressed bytes written; 0 when the content was already stored
+	Reused bool
+}
+
+// NewObjectStore creates an object store for the given .dgit directory
+func NewObjectStore(dgitDir string) *ObjectStore {
+	return &ObjectStore{
+		DgitDir:  dgitDir,
+		BlobsDir: filepath.Join(dgitDir, BlobDir),
+	}
+}
+
+// Find returns the path of a stored blob, or "" when the content is not in the store
+func (s *ObjectStore) Find(hash string) string {
+	if len(hash) < 3 {
+		return ""
+	}
+	for _, name := range blobCodecs {
+		c, err := codec.Get(name)
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(s.BlobsDir, hash[:2], hash+c.Ext())
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Has reports whether a content hash is stored
+func (s *ObjectStore) Has(hash string) bool {
+	return s.Find(hash) != ""
+}
+
+// Put adds a file's content to the store with the named codec, unless identical content is already there
+// The file is hashed first so unchanged content is never recompressed. Concurrent Puts of the same content
+// store it once: they take turns per hash, and the blob is linked into place only if the name is still free
+func (s *ObjectStore) Put(source, codecName string) (*PutResult, error) {
+	hash, info, err := hashFile(source)
+	if err != nil {
+		return nil, err
+	}
+	ref := &log.BlobRef{Hash: hash, Size: info.Size(), Mode: info.Mode().Perm()}
+	defer claim(filepath.Join(s.BlobsDir, hash))()
+	if s.Has(hash) {
+		return &PutResult{Ref: ref, Reused: true}, nil
+	}
+
+	c, err := codec.Get(codecName)
+	if err != nil {
+		return nil, err
+	}
+	target := filepath.Join(s.BlobsDir, hash[:2], hash+c.Ext())
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create object store: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".incoming-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeBlob(tmp, source, c, hash, s.IO); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	stat, err := tmp.Stat()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := publish(tmp.Name(), target); os.IsExist(err) {
+		return &PutResult{Ref: ref, Reused: true}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to store %s: %w", source, err)
+	}
+	return &PutResult{Ref: ref, Stored: stat.Size()}, nil
+}
+
+var (
+	pendingMu sync.Mutex
+	pending   = make(map[string]chan struct{}) // Blob being stored by this process → closed when done
+)
+
+// claim waits until no other goroutine is storing the same blob, then holds it until the returned func is called
+func claim(key string) func() {
+	for {
+		pendingMu.Lock()
+		busy, ok := pending[key]
+		if !ok {
+			done := make(chan struct{})
+			pending[key] = done
+			pendingMu.Unlock()
+			return func() {
+				pendingMu.Lock()
+				delete(pending, key)
+				pendingMu.Unlock()
+				close(done)
+			}
+		}
+		pendingMu.Unlock()
+		<-busy
+	}
+}
+
+// publish moves a finished temporary blob to its final name, failing with an IsExist error if the name is
+// taken; filesystems without hard links fall back to a rename
+func publish(tmp, target string) error {
+	err := os.Link(tmp, target)
+	if err == nil || os.IsExist(err) {
+		return err
+	}
+	if _, statErr := os.Lstat(target); statErr == nil {
+		return os.ErrExist
+	}
+	return os.Rename(tmp, target)
+}
+
+// Recode rewrites a stored blob with another codec, replacing the old file once the new one verifies
+// Returns the old and new sizes; a blob already stored with the codec is left alone
+func (s *ObjectStore) Recode(hash, codecName string, newWriter func(io.Writer) (io.WriteCloser, error)) (int64, int64, error) {
+	source := s.Find(hash)
+	if source == "" {
+		return 0, 0, fmt.Errorf("blob %s is missing", util.ShortHash(hash))
+	}
+	before, err := os.Stat(source)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, err := codec.Get(codecName)
+	if err != nil {
+		return 0, 0, err
+	}
+	target := filepath.Join(filepath.Dir(source), hash+c.Ext())
+	if target == source {
+		return before.Size(), before.Size(), nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(source), ".incoming-*")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create temporary blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = recodeBlob(s, tmp, hash, newWriter)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = checkBlob(tmp.Name(), c, hash)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to recompress blob %s: %w", util.ShortHash(hash), err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return 0, 0, fmt.Errorf("failed to store blob %s: %w", util.ShortHash(hash), err)
+	}
+	if err := os.Remove(source); err != nil {
+		return 0, 0, fmt.Errorf("failed to remove the old copy of blob %s: %w", util.ShortHash(hash), err)
+	}
+	after, err := os.Stat(target)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before.Size(), after.Size(), nil
+}
+
+// recodeBlob decodes a stored blob, checking its hash, and writes it through a new encoder
+func recodeBlob(s *ObjectStore, w io.Writer, hash string, newWriter func(io.Writer) (io.WriteCloser, error)) error {
+	reader, err := s.Open(hash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	writer, err := newWriter(w)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(writer, s.IO.Reader(reader)); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// checkBlob decodes a blob file with its codec and compares the content hash
+func checkBlob(path string, c codec.Codec, hash string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader, err := c.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		return fmt.Errorf("new copy does not match the content hash")
+	}
+	return nil
+}
+
+// writeBlob compresses source into w, failing if the file changed since it was hashed
+func writeBlob(w io.Writer, source string, c codec.Codec, hash string, sched *iosched.Scheduler) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer in.Close()
+	writer, err := c.NewWriter(w)
+	if err != nil {
+		return fmt.Errorf("failed to start %s blob: %w", c.Name(), err)
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(writer, io.TeeReader(sched.Reader(in), hasher)); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to compress %s: %w", source, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish blob: %w", err)
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		return fmt.Errorf("%s changed while being committed", source)
+	}
+	return nil
+}
+
+// Open returns a blob's decompressed content; reading to the end verifies it against its hash
+// Blobs pushed to the storage backend and evicted from this machine are fetched back first
+func (s *ObjectStore) Open(hash string) (io.ReadCloser, error) {
+	path := s.Find(hash)
+	if path == "" {
+		var err error
+		if path, err = s.fetch(hash); err != nil {
+			return nil, err
+		}
+	}
+	if path == "" {
+		return nil, fmt.Errorf("blob %s is missing", util.ShortHash(hash))
+	}
+	c, ok := codec.ForObject(path)
+	if !ok {
+		return nil, fmt.Errorf("no codec for %s", filepath.Base(path))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := c.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open blob %s: %w", util.ShortHash(hash), err)
+	}
+	return &verifyingReader{r: reader, file: file, hash: hash, sum: sha256.New()}, nil
+}
+
+// fetch downloads a blob missing locally from the configured storage backend
+// Returns "" when no backend is configured or it does not hold the blob either
+func (s *ObjectStore) fetch(hash string) (string, error) {
+	if len(hash) < 3 {
+		return "", nil
+	}
+	backend, err := storage.Open(s.DgitDir)
+	if err != nil || backend == nil {
+		return "", err
+	}
+	for _, name := range blobCodecs {
+		c, err := codec.Get(name)
+		if err != nil {
+			continue
+		}
+		rel := filepath.Join(BlobDir, hash[:2], hash+c.Ext())
+		if _, err := backend.Stat(storage.Key(rel)); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			return "", fmt.Errorf("blob %s: %w", util.ShortHash(hash), err)
+		}
+		if _, err := storage.Download(backend, s.DgitDir, rel); err != nil {
+			return "", err
+		}
+		return filepath.Join(s.DgitDir, rel), nil
+	}
+	return "", nil
+}
+
+// PushResult describes blobs sent to a storage backend
+type PushResult struct {
+	Uploaded int
+	Present  int // Already held by the backend
+	Evicted  int
+	Bytes    int64
+}
+
+// Push uploads every local blob the storage backend lacks; with evict, local copies are removed once uploaded
+// Evicted blobs are fetched back transparently when a restore or verify reads them
+func (s *ObjectStore) Push(backend storage.Backend, evict bool) (*PushResult, error) {
+	result := &PushResult{}
+	err := filepath.WalkDir(s.BlobsDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.DgitDir, path)
+		sent, err := storage.Upload(backend, s.DgitDir, rel)
+		if err != nil {
+			return err
+		}
+		if sent > 0 {
+			result.Uploaded++
+			result.Bytes += sent
+		} else {
+			result.Present++
+		}
+		if evict {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to evict %s: %w", rel, err)
+			}
+			result.Evicted++
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Verify decompresses a blob and checks its content hash
+func (s *ObjectStore) Verify(hash string) error {
+	reader, err := s.Open(hash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(io.Discard, reader)
+	return err
+}
+
+// Files returns the blobs a commit references, relative to .dgit, without duplicates
+// Missing blobs are left out
+func (s *ObjectStore) Files(blobs map[string]*log.BlobRef) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, ref := range blobs {
+		if ref == nil || seen[ref.Hash] {
+			continue
+		}
+		seen[ref.Hash] = true
+		if path := s.Find(ref.Hash); path != "" {
+			rel, _ := filepath.Rel(s.DgitDir, path)
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// IsBlobPath reports whether a path relative to .dgit is inside the object store
+func IsBlobPath(rel string) bool {
+	dir := filepath.Dir(filepath.Dir(rel))
+	return dir == BlobDir
+}
+
+// hashFile returns the SHA-256 of a file and its info
+func hashFile(path string) (string, os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", nil, err
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), info, nil
+}
+
+// verifyingReader hashes content as it is read and fails at the end on a mismatch
+type verifyingReader struct {
+	r    io.ReadCloser
+	file *os.File
+	hash string
+	sum  hash.Hash
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.sum.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(v.sum.Sum(nil)) != v.hash {
+		return n, fmt.Errorf("blob %s is corrupt (content hash mismatch)", util.ShortHash(v.hash))
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error {
+	v.r.Close()
+	return v.file.Close()
+}
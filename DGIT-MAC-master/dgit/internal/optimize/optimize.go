@@ -0,0 +1,344 @@
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cache"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StateFile records when optimization last ran, relative to .dgit
+const StateFile = "optimize.json"
+
+// Settings are the zstd_stage and archive_stage options that drive optimization
+type Settings struct {
+	WarmEnabled      bool
+	WarmLevel        int           // Zstd level of warm copies
+	Interval         time.Duration // Time between passes
+	MinIdle          time.Duration // Snapshots this recent, and repositories this recently committed to, are left alone
+	ArchiveEnabled   bool
+	ArchiveAfterDays int // Versions older than this move to the cold tier; 0 never archives
+}
+
+// Converted is a hot LZ4 snapshot that gained a warm Zstd copy
+type Converted struct {
+	Version  int
+	HotSize  int64
+	WarmSize int64
+}
+
+// RecompressedBlob is an object store blob rewritten from LZ4 to Zstd
+type RecompressedBlob struct {
+	Hash     string
+	LZ4Size  int64
+	ZstdSize int64
+}
+
+// Report is the outcome of one optimization pass
+type Report struct {
+	StartedAt    time.Time
+	DryRun       bool
+	Busy         string // Why the pass did nothing: foreground work or a recent commit
+	Warmed       []*Converted
+	Recompressed []*RecompressedBlob
+	Archived     []int
+	Skipped      map[int]string // Versions old enough to archive that stay online, with the reason
+	Failed       map[int]error
+	FailedBlobs  map[string]error
+	Evictions    []*cache.EvictionResult
+}
+
+// state is the persisted record of the last pass
+type state struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// OptimizeManager moves committed versions down the cache tiers once the repository is idle:
+// hot LZ4 snapshots get a warm Zstd copy, object store blobs only older versions use are recompressed
+// to Zstd, and versions past archive_after_days go to the cold tier
+type OptimizeManager struct {
+	DgitDir   string
+	StatePath string
+	IO        *iosched.Scheduler // Paces warm conversion; nil does not throttle
+}
+
+// NewOptimizeManager creates a new optimize manager for the given .dgit directory
+func NewOptimizeManager(dgitDir string) *OptimizeManager {
+	return &OptimizeManager{
+		DgitDir:   dgitDir,
+		StatePath: filepath.Join(dgitDir, StateFile),
+	}
+}
+
+// LoadSettings reads the optimization settings from the repository config
+func (om *OptimizeManager) LoadSettings() (Settings, error) {
+	config, err := initializer.GetRepositoryConfig(om.DgitDir)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to read repository config: %w", err)
+	}
+	zstdStage := config.Compression.ZstdConfig
+	archiveStage := config.Compression.ArchiveConfig
+	settings := Settings{
+		WarmEnabled:      zstdStage.Enabled,
+		WarmLevel:        zstdStage.CompressionLevel,
+		Interval:         time.Duration(zstdStage.OptimizeInterval) * time.Minute,
+		MinIdle:          time.Duration(zstdStage.MinIdleTime) * time.Second,
+		ArchiveEnabled:   archiveStage.Enabled,
+		ArchiveAfterDays: archiveStage.ArchiveAfterDays,
+	}
+	if settings.WarmLevel <= 0 {
+		settings.WarmLevel = 3
+	}
+	if settings.Interval <= 0 {
+		settings.Interval = 15 * time.Minute
+	}
+	return settings, nil
+}
+
+// LastRun returns when the last pass finished; zero if optimization never ran
+func (om *OptimizeManager) LastRun() time.Time {
+	var s state
+	if data, err := os.ReadFile(om.StatePath); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	return s.LastRun
+}
+
+// Due reports whether optimize_interval has passed since the last pass
+func (om *OptimizeManager) Due(settings Settings, now time.Time) bool {
+	return now.Sub(om.LastRun()) >= settings.Interval
+}
+
+// Run performs one optimization pass; with dryRun it only reports what it would do
+// Nothing happens while a commit or restore is running, or within min_idle_time of the last commit
+func (om *OptimizeManager) Run(dryRun bool) (*Report, error) {
+	settings, err := om.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	report := &Report{StartedAt: now, DryRun: dryRun, Skipped: make(map[int]string), Failed: make(map[int]error),
+		FailedBlobs: make(map[string]error)}
+
+	if active := iosched.ActiveForeground(om.DgitDir); len(active) > 0 {
+		report.Busy = fmt.Sprintf("%s is running", active[0].Operation)
+		return report, nil
+	}
+	commits, err := om.history()
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) == 0 {
+		return report, om.saveState(dryRun, now)
+	}
+	if idle := now.Sub(commits[0].Timestamp); idle < settings.MinIdle {
+		report.Busy = fmt.Sprintf("last commit was %s ago (min_idle_time is %s)", idle.Round(time.Second), settings.MinIdle)
+		return report, nil
+	}
+
+	if settings.WarmEnabled {
+		om.warmSnapshots(commits, settings, now, report)
+		om.recompressBlobs(commits, settings, now, report)
+	}
+	if settings.ArchiveEnabled && settings.ArchiveAfterDays > 0 {
+		if err := om.archiveOld(now, report); err != nil {
+			return report, err
+		}
+	}
+	if !dryRun {
+		evictions, err := cache.NewCacheManager(om.DgitDir).Enforce()
+		report.Evictions = evictions
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, om.saveState(dryRun, now)
+}
+
+// warmSnapshots writes warm Zstd copies of hot LZ4 snapshots that are older than min_idle_time
+// The hot copy is kept; the cache budget decides when it can go
+func (om *OptimizeManager) warmSnapshots(commits []*log.Commit, settings Settings, now time.Time, report *Report) {
+	cacheManager := cache.NewCacheManager(om.DgitDir)
+	for _, commit := range commits {
+		info := commit.CompressionInfo
+		if info == nil || info.Strategy != codec.LZ4 || info.SkipOptimization {
+			continue
+		}
+		hotPath := filepath.Join(cacheManager.HotCacheDir, info.OutputFile)
+		warmPath := filepath.Join(cacheManager.WarmCacheDir, fmt.Sprintf("v%d.zstd", commit.Version))
+		hot, err := os.Stat(hotPath)
+		if err != nil || now.Sub(hot.ModTime()) < settings.MinIdle {
+			continue
+		}
+		if _, err := os.Stat(warmPath); err == nil {
+			continue
+		}
+		converted := &Converted{Version: commit.Version, HotSize: hot.Size()}
+		if !report.DryRun {
+			size, err := om.convert(hotPath, warmPath, settings.WarmLevel)
+			if err != nil {
+				report.Failed[commit.Version] = err
+				continue
+			}
+			converted.WarmSize = size
+		}
+		report.Warmed = append(report.Warmed, converted)
+	}
+}
+
+// recompressBlobs rewrites LZ4 object store blobs idle for min_idle_time with Zstd at the warm level
+// Blobs the newest version of any branch references stay LZ4 so checkouts and restores of them stay fast
+func (om *OptimizeManager) recompressBlobs(commits []*log.Commit, settings Settings, now time.Time, report *Report) {
+	tips := make(map[string]bool)
+	if refs, err := log.NewLogManager(om.DgitDir).ListRefs(); err == nil {
+		for _, hash := range refs {
+			tips[hash] = true
+		}
+	}
+	recent := make(map[string]bool)
+	for _, commit := range commits {
+		if tips[commit.Hash] {
+			for _, ref := range commit.Blobs {
+				if ref != nil {
+					recent[ref.Hash] = true
+				}
+			}
+		}
+	}
+
+	store := objstore.NewObjectStore(om.DgitDir)
+	store.IO = om.IO
+	limits := perf.Load(om.DgitDir)
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		for _, ref := range commit.Blobs {
+			if ref == nil || recent[ref.Hash] || seen[ref.Hash] {
+				continue
+			}
+			seen[ref.Hash] = true
+			path := store.Find(ref.Hash)
+			if filepath.Ext(path) != ".lz4" {
+				continue // Missing, stored uncompressed, or already Zstd
+			}
+			hot, err := os.Stat(path)
+			if err != nil || now.Sub(hot.ModTime()) < settings.MinIdle {
+				continue
+			}
+			blob := &RecompressedBlob{Hash: ref.Hash, LZ4Size: hot.Size()}
+			if !report.DryRun {
+				blob.LZ4Size, blob.ZstdSize, err = store.Recode(ref.Hash, codec.Zstd, func(w io.Writer) (io.WriteCloser, error) {
+					return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(settings.WarmLevel)),
+						zstd.WithEncoderConcurrency(limits.Workers(runtime.NumCPU())), zstd.WithLowerEncoderMem(limits.MaxMemoryMB > 0))
+				})
+				if err != nil {
+					report.FailedBlobs[ref.Hash] = err
+					continue
+				}
+			}
+			report.Recompressed = append(report.Recompressed, blob)
+		}
+	}
+}
+
+// archiveOld moves the versions archive_after_days has expired to the cold tier
+// Delta bases are refused by the archive manager and reported as skipped
+func (om *OptimizeManager) archiveOld(now time.Time, report *Report) error {
+	archiveManager := archive.NewArchiveManager(om.DgitDir)
+	due, err := archiveManager.Expired(now)
+	if err != nil {
+		return err
+	}
+	for _, version := range due {
+		if report.DryRun {
+			report.Archived = append(report.Archived, version)
+			continue
+		}
+		if _, err := archiveManager.Archive(version); err != nil {
+			report.Skipped[version] = err.Error()
+			continue
+		}
+		report.Archived = append(report.Archived, version)
+	}
+	return nil
+}
+
+// convert recompresses a hot LZ4 snapshot into a warm Zstd copy, atomically
+func (om *OptimizeManager) convert(hotPath, warmPath string, level int) (int64, error) {
+	in, err := os.Open(hotPath)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(warmPath), 0755); err != nil {
+		return 0, err
+	}
+	tmp := warmPath + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	limits := perf.Load(om.DgitDir)
+	encoder, err := zstd.NewWriter(out, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)),
+		zstd.WithEncoderConcurrency(limits.Workers(runtime.NumCPU())), zstd.WithLowerEncoderMem(limits.MaxMemoryMB > 0))
+	if err == nil {
+		_, err = io.Copy(encoder, codec.NewLZ4Reader(om.IO.Reader(in)))
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, warmPath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write warm copy: %w", err)
+	}
+	info, err := os.Stat(warmPath)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// history lists every commit, newest version first
+func (om *OptimizeManager) history() ([]*log.Commit, error) {
+	it, err := log.NewLogManager(om.DgitDir).Iterate(log.CommitFilter{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var commits []*log.Commit
+	for it.Next() {
+		commits = append(commits, it.Commit())
+	}
+	return commits, it.Err()
+}
+
+// saveState records the pass so the daemon waits optimize_interval before the next one
+func (om *OptimizeManager) saveState(dryRun bool, now time.Time) error {
+	if dryRun {
+		return nil
+	}
+	data, err := json.MarshalIndent(&state{LastRun: now}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(om.StatePath, data, 0644)
+}
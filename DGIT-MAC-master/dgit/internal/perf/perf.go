@@ -0,0 +1,114 @@
+package perf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrMemoryLimit is returned when data would not fit in the configured decompression buffer budget
+var ErrMemoryLimit = errors.New("exceeds performance.max_memory_mb")
+
+// Limits bound the workers, memory and foreground disk bandwidth one dgit command may use
+// Zero fields are unlimited (workers: one per CPU core)
+type Limits struct {
+	MaxWorkers     int
+	MaxMemoryMB    int
+	IOThrottleMBps int
+}
+
+var (
+	overridesMu sync.Mutex
+	overrides   Limits
+)
+
+// SetOverrides records limits given on the command line; non-zero fields win over the repository config
+// A negative value lifts a configured limit
+func SetOverrides(limits Limits) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides = limits
+}
+
+// Load returns the limits of a repository's performance settings with command-line overrides applied
+// dgitDir may be empty outside a repository, leaving only the overrides
+func Load(dgitDir string) Limits {
+	var limits Limits
+	if dgitDir != "" {
+		if config, err := initializer.GetRepositoryConfig(dgitDir); err == nil {
+			limits = Limits{
+				MaxWorkers:     config.Performance.MaxWorkers,
+				MaxMemoryMB:    config.Performance.MaxMemoryMB,
+				IOThrottleMBps: config.Performance.IOThrottleMBps,
+			}
+		}
+	}
+
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	limits.MaxWorkers = override(limits.MaxWorkers, overrides.MaxWorkers)
+	limits.MaxMemoryMB = override(limits.MaxMemoryMB, overrides.MaxMemoryMB)
+	limits.IOThrottleMBps = override(limits.IOThrottleMBps, overrides.IOThrottleMBps)
+	return limits
+}
+
+// override applies one command-line value to a configured one
+func override(configured, flag int) int {
+	switch {
+	case flag > 0:
+		return flag
+	case flag < 0:
+		return 0
+	case configured < 0:
+		return 0
+	}
+	return configured
+}
+
+// Workers returns how many workers to start for n items: at most MaxWorkers, the CPU count and n, and at least 1
+func (l Limits) Workers(n int) int {
+	workers := runtime.NumCPU()
+	if l.MaxWorkers > 0 && l.MaxWorkers < workers {
+		workers = l.MaxWorkers
+	}
+	if workers > n {
+		workers = n
+	}
+	return max(workers, 1)
+}
+
+// MemoryBytes returns the decompression buffer budget in bytes, 0 when unlimited
+func (l Limits) MemoryBytes() int64 {
+	return int64(l.MaxMemoryMB) * 1024 * 1024
+}
+
+// ZstdOptions bounds a Zstd decoder's goroutines and window memory to the limits
+func (l Limits) ZstdOptions() []zstd.DOption {
+	options := []zstd.DOption{zstd.WithDecoderConcurrency(l.Workers(runtime.NumCPU()))}
+	if budget := l.MemoryBytes(); budget > 0 {
+		options = append(options, zstd.WithDecoderLowmem(true), zstd.WithDecoderMaxMemory(uint64(budget)))
+	}
+	return options
+}
+
+// ReadAll reads r to the end like io.ReadAll, failing with ErrMemoryLimit once more than the budget arrives
+func (l Limits) ReadAll(r io.Reader) ([]byte, error) {
+	budget := l.MemoryBytes()
+	if budget <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, budget+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > budget {
+		return nil, fmt.Errorf("decompressed data %w (%d MB)", ErrMemoryLimit, l.MaxMemoryMB)
+	}
+	return data, nil
+}
@@ -0,0 +1,209 @@
+package pin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+)
+
+// Pin records that a working tree file is intentionally held at an older version
+// Pinned files are reported as pinned instead of modified and cannot be committed until unpinned
+type Pin struct {
+	Path     string    `json:"path"`    // Relative to the working tree root
+	Version  int       `json:"version"` // Version the file is held at
+	Hash     string    `json:"hash"`    // SHA256 of the working copy when pinned
+	PinnedAt time.Time `json:"pinned_at"`
+}
+
+// PinManager manages pinned working tree files for a DGit repository
+type PinManager struct {
+	DgitDir  string
+	RootDir  string
+	PinsFile string
+}
+
+// NewPinManager creates a new pin manager for the given .dgit directory
+func NewPinManager(dgitDir string) *PinManager {
+	return &PinManager{
+		DgitDir:  dgitDir,
+		RootDir:  filepath.Dir(dgitDir),
+		PinsFile: filepath.Join(dgitDir, "pins.json"),
+	}
+}
+
+// GetPins returns all pins sorted by path
+func (pm *PinManager) GetPins() ([]*Pin, error) {
+	pins := []*Pin{}
+
+	data, err := os.ReadFile(pm.PinsFile)
+	if os.IsNotExist(err) {
+		return pins, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pins file: %w", err)
+	}
+	if err := json.Unmarshal(data, &pins); err != nil {
+		return nil, fmt.Errorf("failed to parse pins file: %w", err)
+	}
+
+	sort.Slice(pins, func(i, j int) bool { return pins[i].Path < pins[j].Path })
+	return pins, nil
+}
+
+// GetPin returns the pin of a path (relative to the root or absolute), or nil if it is not pinned
+func (pm *PinManager) GetPin(path string) (*Pin, error) {
+	rel, err := pm.relativeToRoot(path)
+	if err != nil {
+		return nil, nil
+	}
+	pins, err := pm.GetPins()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pins {
+		if p.Path == rel {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// savePins writes the pin registry to disk
+func (pm *PinManager) savePins(pins []*Pin) error {
+	data, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pins: %w", err)
+	}
+	if err := os.WriteFile(pm.PinsFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pins file: %w", err)
+	}
+	return nil
+}
+
+// Pin holds a file at the given version, restoring that version into the working tree unless keep is set
+// With keep, the current working copy is recorded as-is (e.g. it was already restored by hand)
+func (pm *PinManager) Pin(path string, version int, keep bool) (*Pin, error) {
+	rel, err := pm.relativeToRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := log.NewLogManager(pm.DgitDir).GetCommit(version)
+	if err != nil {
+		return nil, fmt.Errorf("version v%d not found: %w", version, err)
+	}
+	if _, ok := commit.Metadata[rel]; !ok {
+		return nil, fmt.Errorf("%s is not part of v%d", rel, version)
+	}
+
+	if !keep {
+		restoreManager := restore.NewRestoreManager(pm.DgitDir)
+		restoreManager.WorkDir = pm.RootDir
+		if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", version), []string{rel}, commit); err != nil {
+			return nil, fmt.Errorf("failed to restore %s from v%d: %w", rel, version, err)
+		}
+	}
+
+	hash, err := status.CalculateFileHash(filepath.Join(pm.RootDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+
+	pins, err := pm.GetPins()
+	if err != nil {
+		return nil, err
+	}
+	pin := &Pin{Path: rel, Version: version, Hash: hash, PinnedAt: time.Now()}
+	replaced := false
+	for i, existing := range pins {
+		if existing.Path == rel {
+			pins[i] = pin
+			replaced = true
+		}
+	}
+	if !replaced {
+		pins = append(pins, pin)
+	}
+	if err := pm.savePins(pins); err != nil {
+		return nil, err
+	}
+	return pin, nil
+}
+
+// Unpin releases a pinned file; the working copy is left as it is
+func (pm *PinManager) Unpin(path string) (*Pin, error) {
+	rel, err := pm.relativeToRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := pm.GetPins()
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range pins {
+		if p.Path == rel {
+			if err := pm.savePins(append(pins[:i], pins[i+1:]...)); err != nil {
+				return nil, err
+			}
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not pinned", rel)
+}
+
+// CheckCommit returns the pins that would be included by committing the given absolute paths
+func (pm *PinManager) CheckCommit(absPaths []string) ([]*Pin, error) {
+	pins, err := pm.GetPins()
+	if err != nil || len(pins) == 0 {
+		return nil, err
+	}
+	var blocked []*Pin
+	for _, absPath := range absPaths {
+		rel, err := pm.relativeToRoot(absPath)
+		if err != nil {
+			continue
+		}
+		for _, p := range pins {
+			if p.Path == rel {
+				blocked = append(blocked, p)
+			}
+		}
+	}
+	return blocked, nil
+}
+
+// RenamePath moves a pin along with its file; returns the pin, or nil if the file was not pinned
+func (pm *PinManager) RenamePath(oldPath, newPath string) (*Pin, error) {
+	pins, err := pm.GetPins()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pins {
+		if p.Path == oldPath {
+			p.Path = newPath
+			return p, pm.savePins(pins)
+		}
+	}
+	return nil, nil
+}
+
+// relativeToRoot converts a path to a slash-separated path relative to the working tree root
+func (pm *PinManager) relativeToRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(pm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("pinned file must be inside the repository: %s", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
@@ -0,0 +1,103 @@
+package preview
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/thumbnail"
+)
+
+// imageFormats are proxy formats that can be pasted as a picture
+var imageFormats = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".tif": true, ".tiff": true}
+
+// IsImage reports whether a proxy is a flat image
+func (p *Preview) IsImage() bool {
+	return imageFormats[strings.ToLower(filepath.Ext(p.Path))]
+}
+
+// Composite returns a flattened image of one file in a version
+// It prefers a stored proxy, then runs the configured converter, then falls back to the
+// thumbnail the authoring application embedded in the file; whatever is produced is kept in the preview store
+func (pm *PreviewManager) Composite(commit *log.Commit, source string) (*Preview, error) {
+	source, err := CommittedPath(commit, source)
+	if err != nil {
+		return nil, err
+	}
+
+	previews, err := pm.List(commit.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range previews {
+		if filepath.ToSlash(p.Source) == source && p.IsImage() {
+			return p, nil
+		}
+	}
+
+	tempDir, err := os.MkdirTemp("", "dgit-preview-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	restoreManager := restore.NewRestoreManager(pm.DgitDir)
+	restoreManager.WorkDir = tempDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", commit.Version), []string{source}, commit); err != nil {
+		return nil, fmt.Errorf("failed to restore %s from v%d: %w", source, commit.Version, err)
+	}
+	restored := filepath.Join(tempDir, filepath.FromSlash(source))
+
+	results, err := pm.Generate(commit.Version, map[string]string{source: restored})
+	if err != nil {
+		return nil, err
+	}
+	var reasons []string
+	for _, result := range results {
+		switch {
+		case result.Preview != nil && result.Preview.IsImage():
+			return result.Preview, nil
+		case result.Err != nil:
+			reasons = append(reasons, result.Err.Error())
+		case result.Skipped != "":
+			reasons = append(reasons, result.Skipped)
+		}
+	}
+
+	if thumbnail.Supported(source) {
+		data, format, err := thumbnail.Extract(restored)
+		if err == nil {
+			return pm.Store(commit.Version, source, format, data)
+		}
+		reasons = append(reasons, err.Error())
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no converter produces an image for this file type")
+	}
+	return nil, fmt.Errorf("no preview of %s in v%d: %s", source, commit.Version, strings.Join(reasons, "; "))
+}
+
+// CommittedPath resolves a path or file name to the path recorded in a commit
+func CommittedPath(commit *log.Commit, path string) (string, error) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	var byName []string
+	for committed := range commit.Metadata {
+		committed = filepath.ToSlash(committed)
+		if committed == path {
+			return committed, nil
+		}
+		if filepath.Base(committed) == filepath.Base(path) {
+			byName = append(byName, committed)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return "", fmt.Errorf("%s is not in v%d", path, commit.Version)
+	case 1:
+		return byName[0], nil
+	}
+	return "", fmt.Errorf("%s matches several files in v%d: %s", path, commit.Version, strings.Join(byName, ", "))
+}
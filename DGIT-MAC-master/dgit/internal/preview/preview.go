@@ -0,0 +1,234 @@
+package preview
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// DefaultTimeoutSeconds bounds a single converter run
+const DefaultTimeoutSeconds = 120
+
+// Preview is a generated proxy of one file in one version
+type Preview struct {
+	Version int
+	Source  string // Repository path of the design file
+	Path    string // Absolute path of the proxy in the preview store
+	Size    int64
+}
+
+// Result reports what happened to one file during generation
+type Result struct {
+	Source    string
+	Converter string
+	Preview   *Preview
+	Skipped   string // Why no proxy was made (no converter, tool not installed)
+	Err       error
+}
+
+// PreviewManager runs the configured external converters and keeps their output in .dgit/previews
+// Proxies are stored as previews/v<N>/<repository path>.<format> so they can be browsed without DGit
+type PreviewManager struct {
+	DgitDir     string
+	PreviewsDir string
+}
+
+// NewPreviewManager creates a new preview manager for the given .dgit directory
+func NewPreviewManager(dgitDir string) *PreviewManager {
+	return &PreviewManager{
+		DgitDir:     dgitDir,
+		PreviewsDir: filepath.Join(dgitDir, "previews"),
+	}
+}
+
+// GetConverters returns the configured converters
+func (pm *PreviewManager) GetConverters() ([]initializer.PreviewConverter, error) {
+	config, err := initializer.GetRepositoryConfig(pm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Preview.Converters, nil
+}
+
+// Installed reports whether a converter's executable can be found
+func Installed(converter initializer.PreviewConverter) bool {
+	if len(converter.Command) == 0 {
+		return false
+	}
+	_, err := exec.LookPath(converter.Command[0])
+	return err == nil
+}
+
+// Generate creates proxies for the given files of a version
+// files maps repository paths to the on-disk copy holding that version's content
+func (pm *PreviewManager) Generate(version int, files map[string]string) ([]*Result, error) {
+	config, err := initializer.GetRepositoryConfig(pm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(config.Preview.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultTimeoutSeconds * time.Second
+	}
+
+	sources := make([]string, 0, len(files))
+	for source := range files {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var results []*Result
+	for _, source := range sources {
+		result := &Result{Source: source}
+		results = append(results, result)
+
+		converter := findConverter(config.Preview.Converters, source)
+		if converter == nil {
+			result.Skipped = "no converter for this file type"
+			continue
+		}
+		result.Converter = converter.Name
+		if !Installed(*converter) {
+			result.Skipped = fmt.Sprintf("%s is not installed", converter.Command[0])
+			continue
+		}
+
+		output := pm.previewPath(version, source, converter.Output)
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			result.Err = fmt.Errorf("failed to create preview directory: %w", err)
+			continue
+		}
+		if err := runConverter(*converter, files[source], output, timeout); err != nil {
+			os.Remove(output)
+			result.Err = err
+			continue
+		}
+		info, err := os.Stat(output)
+		if err != nil {
+			result.Err = fmt.Errorf("%s produced no output", converter.Name)
+			continue
+		}
+		result.Preview = &Preview{Version: version, Source: source, Path: output, Size: info.Size()}
+	}
+	return results, nil
+}
+
+// Store saves a proxy rendered elsewhere, such as a Figma frame exported by the Figma API
+func (pm *PreviewManager) Store(version int, source, format string, data []byte) (*Preview, error) {
+	output := pm.previewPath(version, source, format)
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write preview: %w", err)
+	}
+	return &Preview{Version: version, Source: source, Path: output, Size: int64(len(data))}, nil
+}
+
+// GenerateForCommit restores a committed version into a temporary folder and generates its proxies
+// Used to backfill previews for versions committed before a converter was configured
+func (pm *PreviewManager) GenerateForCommit(commit *log.Commit) ([]*Result, error) {
+	tempDir, err := os.MkdirTemp("", "dgit-preview-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	restoreManager := restore.NewRestoreManager(pm.DgitDir)
+	restoreManager.WorkDir = tempDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", commit.Version), nil, commit); err != nil {
+		return nil, fmt.Errorf("failed to restore v%d: %w", commit.Version, err)
+	}
+
+	files := make(map[string]string)
+	for path := range commit.Metadata {
+		restored := filepath.Join(tempDir, filepath.FromSlash(path))
+		if _, err := os.Stat(restored); err == nil {
+			files[path] = restored
+		}
+	}
+	return pm.Generate(commit.Version, files)
+}
+
+// List returns the stored proxies of a version, or of every version when version is 0
+func (pm *PreviewManager) List(version int) ([]*Preview, error) {
+	var previews []*Preview
+	err := filepath.Walk(pm.PreviewsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(pm.PreviewsDir, path)
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+		var v int
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "v") {
+			return nil
+		}
+		if _, err := fmt.Sscanf(parts[0], "v%d", &v); err != nil || (version != 0 && v != version) {
+			return nil
+		}
+		source := strings.TrimSuffix(parts[1], filepath.Ext(parts[1]))
+		previews = append(previews, &Preview{Version: v, Source: source, Path: path, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preview store: %w", err)
+	}
+	sort.Slice(previews, func(i, j int) bool {
+		if previews[i].Version != previews[j].Version {
+			return previews[i].Version > previews[j].Version
+		}
+		return previews[i].Source < previews[j].Source
+	})
+	return previews, nil
+}
+
+// previewPath is where the proxy of a file in a version is stored
+func (pm *PreviewManager) previewPath(version int, source, format string) string {
+	return filepath.Join(pm.PreviewsDir, fmt.Sprintf("v%d", version), filepath.FromSlash(source)+"."+format)
+}
+
+// findConverter returns the first converter handling the file's extension
+func findConverter(converters []initializer.PreviewConverter, path string) *initializer.PreviewConverter {
+	ext := strings.ToLower(filepath.Ext(path))
+	for i, converter := range converters {
+		for _, handled := range converter.Extensions {
+			if strings.ToLower(handled) == ext {
+				return &converters[i]
+			}
+		}
+	}
+	return nil
+}
+
+// runConverter executes a converter with its placeholders filled in
+func runConverter(converter initializer.PreviewConverter, input, output string, timeout time.Duration) error {
+	replacer := strings.NewReplacer("{input}", input, "{output}", output)
+	args := make([]string, len(converter.Command))
+	for i, arg := range converter.Command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s timed out after %s", converter.Name, timeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > 200 {
+			msg = msg[:200] + "..."
+		}
+		return fmt.Errorf("%s failed: %v %s", converter.Name, err, msg)
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package recompress
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+)
+
+// Object states
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// tmpSuffix marks a rewrite in progress; leftovers from an interrupted run are removed on the next one
+const tmpSuffix = ".recompress.tmp"
+
+// ObjectState tracks the rewrite of one storage object
+type ObjectState struct {
+	Format       string    `json:"format"` // Deprecated format the object was found in
+	Status       string    `json:"status"`
+	OriginalSize int64     `json:"original_size"`
+	NewSize      int64     `json:"new_size,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// State is the progress of the recompress task, saved after every object so a run can resume
+type State struct {
+	ScannedAt time.Time               `json:"scanned_at,omitempty"`
+	Objects   map[string]*ObjectState `json:"objects"` // Path relative to .dgit → state
+}
+
+// Progress summarizes the task
+type Progress struct {
+	Total   int
+	Pending int
+	Done    int
+	Failed  int
+	Saved   int64 // Bytes saved by the rewrites completed so far
+}
+
+// RecompressManager rewrites storage objects written in deprecated formats into the current format
+// Progress is tracked in .dgit/recompress-state.json
+type RecompressManager struct {
+	DgitDir   string
+	CacheDir  string
+	StateFile string
+	IO        *iosched.Scheduler // Paces object reads and writes; nil runs at full speed
+}
+
+// NewRecompressManager creates a new recompress manager for the given .dgit directory
+func NewRecompressManager(dgitDir string) *RecompressManager {
+	return &RecompressManager{
+		DgitDir:   dgitDir,
+		CacheDir:  filepath.Join(dgitDir, "cache"),
+		StateFile: filepath.Join(dgitDir, "recompress-state.json"),
+	}
+}
+
+// Scan finds objects in deprecated formats and adds them to the task
+// Objects already rewritten keep their state; a failed object is queued again
+func (rm *RecompressManager) Scan() (*State, error) {
+	state, err := rm.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	for _, tier := range []string{"hot", "warm", "cold"} {
+		entries, err := os.ReadDir(filepath.Join(rm.CacheDir, tier))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(name, "v") {
+				continue
+			}
+			path := filepath.Join(rm.CacheDir, tier, name)
+			if strings.HasSuffix(name, tmpSuffix) {
+				os.Remove(path)
+				continue
+			}
+			format, ok := detectFormat(path)
+			if !ok {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			rel := filepath.ToSlash(filepath.Join("cache", tier, name))
+			if existing, ok := state.Objects[rel]; ok && existing.Status == StatusPending {
+				continue
+			}
+			state.Objects[rel] = &ObjectState{
+				Format:       format.Format,
+				Status:       StatusPending,
+				OriginalSize: info.Size(),
+				UpdatedAt:    time.Now(),
+			}
+		}
+	}
+	state.ScannedAt = time.Now()
+	if err := rm.saveState(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Run rewrites up to limit pending objects (0 = all), saving progress after each one
+// An interrupted run leaves every object either untouched or fully rewritten
+func (rm *RecompressManager) Run(limit int) (*State, []string, error) {
+	state, err := rm.LoadState()
+	if err != nil {
+		return nil, nil, err
+	}
+	var rewritten []string
+	for _, rel := range pendingObjects(state) {
+		if limit > 0 && len(rewritten) >= limit {
+			break
+		}
+		object := state.Objects[rel]
+		newSize, err := rm.rewrite(filepath.Join(rm.DgitDir, filepath.FromSlash(rel)), object.Format)
+		object.UpdatedAt = time.Now()
+		if err != nil {
+			object.Status = StatusFailed
+			object.Error = err.Error()
+		} else {
+			object.Status = StatusDone
+			object.NewSize = newSize
+			object.Error = ""
+			rewritten = append(rewritten, rel)
+		}
+		if err := rm.saveState(state); err != nil {
+			return state, rewritten, err
+		}
+	}
+	return state, rewritten, nil
+}
+
+// Summarize counts objects by status
+func (s *State) Summarize() Progress {
+	var progress Progress
+	for _, object := range s.Objects {
+		progress.Total++
+		switch object.Status {
+		case StatusPending:
+			progress.Pending++
+		case StatusDone:
+			progress.Done++
+			progress.Saved += object.OriginalSize - object.NewSize
+		case StatusFailed:
+			progress.Failed++
+		}
+	}
+	return progress
+}
+
+// LoadState reads the task state (empty if the task has never run)
+func (rm *RecompressManager) LoadState() (*State, error) {
+	state := &State{Objects: make(map[string]*ObjectState)}
+	data, err := os.ReadFile(rm.StateFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recompress state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse recompress state: %w", err)
+	}
+	if state.Objects == nil {
+		state.Objects = make(map[string]*ObjectState)
+	}
+	return state, nil
+}
+
+// saveState writes the task state to disk
+func (rm *RecompressManager) saveState(state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recompress state: %w", err)
+	}
+	if err := os.WriteFile(rm.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recompress state: %w", err)
+	}
+	return nil
+}
+
+// rewrite converts one object to the current format with the codec that wrote it
+// The new object is verified against the original payload before it replaces the old one
+func (rm *RecompressManager) rewrite(path, format string) (int64, error) {
+	if format != codec.TextFramed {
+		return 0, fmt.Errorf("no rewrite for format %s", format)
+	}
+	c, ok := codec.ForObject(path)
+	if !ok {
+		return 0, fmt.Errorf("no codec for %s", filepath.Base(path))
+	}
+	payload, err := reframeTextFramed(path, c, rm.IO)
+	if err != nil {
+		return 0, err
+	}
+	expected := sha256.Sum256(payload)
+
+	tmpPath := path + tmpSuffix
+	if err := writeObject(tmpPath, c, payload, rm.IO); err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	decoded, err := decodeObject(tmpPath, c, rm.IO)
+	if err != nil || sha256.Sum256(decoded) != expected {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("rewritten object did not verify")
+	}
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+	return info.Size(), nil
+}
+
+// reframeTextFramed decodes a text-framed stream and returns the same files as a framed container
+func reframeTextFramed(path string, c codec.Codec, sched *iosched.Scheduler) ([]byte, error) {
+	data, err := decodeObject(path, c, sched)
+	if err != nil {
+		return nil, err
+	}
+	var payload bytes.Buffer
+	frames, err := codec.NewFrameWriter(&payload)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF && header == "" {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("truncated text-framed header")
+		}
+		parts := strings.Split(strings.TrimSuffix(header, "\n"), ":")
+		if len(parts) != 3 || parts[0]+":" != codec.TextFramedHeader {
+			return nil, fmt.Errorf("malformed text-framed header %q", strings.TrimSpace(header))
+		}
+		size, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("malformed size in text-framed header for %s", parts[1])
+		}
+		entry := codec.FrameHeader{Path: parts[1], Size: size, Mode: 0644}
+		if _, err := frames.WriteFile(entry, reader); err != nil {
+			return nil, fmt.Errorf("truncated data for %s", parts[1])
+		}
+	}
+	if err := frames.Close(); err != nil {
+		return nil, err
+	}
+	return payload.Bytes(), nil
+}
+
+// writeObject encodes a payload into a new object file
+func writeObject(path string, c codec.Codec, payload []byte, sched *iosched.Scheduler) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	writer, err := c.NewWriter(sched.Writer(file))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := writer.Write(payload); err != nil {
+		writer.Close()
+		file.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// decodeObject reads and decodes a whole object
+func decodeObject(path string, c codec.Codec, sched *iosched.Scheduler) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := c.NewReader(sched.Reader(file))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// detectFormat decodes the start of an object and matches it against the deprecated formats
+func detectFormat(path string) (codec.Deprecation, bool) {
+	c, ok := codec.ForObject(path)
+	if !ok {
+		return codec.Deprecation{}, false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return codec.Deprecation{}, false
+	}
+	defer file.Close()
+	reader, err := c.NewReader(file)
+	if err != nil {
+		return codec.Deprecation{}, false
+	}
+	defer reader.Close()
+	head := make([]byte, 64)
+	n, _ := io.ReadFull(reader, head)
+	return codec.DeprecatedFormat(c.Name(), head[:n])
+}
+
+// pendingObjects lists pending objects in path order so runs are repeatable
+func pendingObjects(state *State) []string {
+	var pending []string
+	for rel, object := range state.Objects {
+		if object.Status == StatusPending {
+			pending = append(pending, rel)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
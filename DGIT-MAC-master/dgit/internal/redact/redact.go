@@ -0,0 +1,164 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Redaction actions
+const (
+	ActionStrip = "strip" // Remove the field
+	ActionHash  = "hash"  // Replace each value with a stable digest, so equal names still compare equal
+)
+
+// hashPrefix marks a hashed value so nobody mistakes it for a real name
+const hashPrefix = "redacted:"
+
+// DefaultRules apply when the repository config has no redaction rules
+var DefaultRules = []initializer.RedactionRule{
+	{Field: "layer_names", Action: ActionHash},
+	{Field: "text", Action: ActionStrip},
+	{Field: "email", Action: ActionStrip},
+}
+
+// Policy maps metadata fields to redaction actions
+type Policy struct {
+	Rules map[string]string // Field → action
+}
+
+// Summary counts what a policy changed
+type Summary struct {
+	Fields map[string]int // Field → values stripped or hashed
+}
+
+// Total returns the number of values redacted
+func (s *Summary) Total() int {
+	total := 0
+	for _, n := range s.Fields {
+		total += n
+	}
+	return total
+}
+
+// LoadPolicy reads the repository redaction policy, falling back to DefaultRules
+func LoadPolicy(dgitDir string) (*Policy, error) {
+	config, err := initializer.GetRepositoryConfig(dgitDir)
+	if err != nil {
+		return nil, err
+	}
+	rules := config.Redaction.Rules
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	return NewPolicy(rules)
+}
+
+// NewPolicy validates rules and builds a policy
+func NewPolicy(rules []initializer.RedactionRule) (*Policy, error) {
+	policy := &Policy{Rules: make(map[string]string)}
+	for _, rule := range rules {
+		if rule.Field == "" {
+			return nil, fmt.Errorf("redaction rule without a field")
+		}
+		if rule.Action != ActionStrip && rule.Action != ActionHash {
+			return nil, fmt.Errorf("redaction rule for %s: unknown action %q (use strip or hash)", rule.Field, rule.Action)
+		}
+		policy.Rules[rule.Field] = rule.Action
+	}
+	return policy, nil
+}
+
+// Describe lists the rules as "field (action)" in field order
+func (p *Policy) Describe() []string {
+	var described []string
+	for field, action := range p.Rules {
+		described = append(described, fmt.Sprintf("%s (%s)", field, action))
+	}
+	sort.Strings(described)
+	return described
+}
+
+// Value redacts a single commit-level value such as a message in a bundle header
+// ok is false when the field is stripped
+func (p *Policy) Value(field, value string) (string, bool) {
+	switch p.Rules[field] {
+	case ActionStrip:
+		return "", false
+	case ActionHash:
+		return hashValue(value), true
+	}
+	return value, true
+}
+
+// CommitData redacts serialized commit data: commit-level keys and every file's metadata keys
+// Unknown fields pass through untouched, so the result still loads as a commit
+func (p *Policy) CommitData(data []byte, summary *Summary) ([]byte, error) {
+	var commit map[string]interface{}
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return nil, fmt.Errorf("failed to parse commit data: %w", err)
+	}
+	if summary.Fields == nil {
+		summary.Fields = make(map[string]int)
+	}
+
+	p.redactMap(commit, summary)
+	if files, ok := commit["metadata"].(map[string]interface{}); ok {
+		for _, metadata := range files {
+			if fields, ok := metadata.(map[string]interface{}); ok {
+				p.redactMap(fields, summary)
+			}
+		}
+	}
+
+	redacted, err := json.MarshalIndent(commit, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal redacted commit: %w", err)
+	}
+	return redacted, nil
+}
+
+// redactMap applies the policy to the keys of one JSON object
+func (p *Policy) redactMap(fields map[string]interface{}, summary *Summary) {
+	for field, action := range p.Rules {
+		value, ok := fields[field]
+		if !ok || value == nil {
+			continue
+		}
+		switch action {
+		case ActionStrip:
+			delete(fields, field)
+			summary.Fields[field]++
+		case ActionHash:
+			fields[field] = hashAny(value, field, summary)
+		}
+	}
+}
+
+// hashAny hashes strings, and the strings inside lists, leaving other values as they are
+func hashAny(value interface{}, field string, summary *Summary) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "" || strings.HasPrefix(v, hashPrefix) {
+			return v
+		}
+		summary.Fields[field]++
+		return hashValue(v)
+	case []interface{}:
+		hashed := make([]interface{}, len(v))
+		for i, item := range v {
+			hashed[i] = hashAny(item, field, summary)
+		}
+		return hashed
+	}
+	return value
+}
+
+// hashValue returns a short, stable digest of a value
+func hashValue(value string) string {
+	return fmt.Sprintf("%s%x", hashPrefix, sha256.Sum256([]byte(value)))[:len(hashPrefix)+12]
+}
@@ -0,0 +1,113 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/lock"
+)
+
+// Locks lists the file locks held on a remote
+func (rm *RemoteManager) Locks(name string) ([]*lock.Lock, error) {
+	resp, err := rm.request(name, http.MethodGet, "locks", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var locks []*lock.Lock
+	if err := json.NewDecoder(resp.Body).Decode(&locks); err != nil {
+		return nil, fmt.Errorf("failed to parse remote locks: %w", err)
+	}
+	return locks, nil
+}
+
+// Lock acquires a file lock on a remote; the remote refuses locks someone else holds
+func (rm *RemoteManager) Lock(name string, l *lock.Lock) (*lock.Lock, error) {
+	body, _ := json.Marshal(l)
+	resp, err := rm.request(name, http.MethodPost, "lock", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var acquired lock.Lock
+	if err := json.NewDecoder(resp.Body).Decode(&acquired); err != nil {
+		return nil, fmt.Errorf("failed to parse lock response: %w", err)
+	}
+	return &acquired, nil
+}
+
+// Unlock releases a file lock on a remote
+func (rm *RemoteManager) Unlock(name string, request *lock.UnlockRequest) (*lock.Lock, error) {
+	body, _ := json.Marshal(request)
+	resp, err := rm.request(name, http.MethodPost, "unlock", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var released lock.Lock
+	if err := json.NewDecoder(resp.Body).Decode(&released); err != nil {
+		return nil, fmt.Errorf("failed to parse unlock response: %w", err)
+	}
+	return &released, nil
+}
+
+// serveLocks lists the repository's locks
+func (h *Handler) serveLocks(w http.ResponseWriter) {
+	locks, err := lock.NewLockManager(h.DgitDir).GetLocks()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(locks)
+}
+
+// serveLock acquires a lock, answering 409 when someone else holds it
+// Owners are taken from the request: locks are advisory and the token already grants push access
+func (h *Handler) serveLock(w http.ResponseWriter, r *http.Request) {
+	var request lock.Lock
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Path == "" || request.Owner == "" {
+		http.Error(w, "invalid lock request", http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	acquired, err := lock.NewLockManager(h.DgitDir).Acquire(&request)
+	if err != nil {
+		http.Error(w, err.Error(), lockStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acquired)
+}
+
+// serveUnlock releases a lock
+func (h *Handler) serveUnlock(w http.ResponseWriter, r *http.Request) {
+	var request lock.UnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Path == "" {
+		http.Error(w, "invalid unlock request", http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	released, err := lock.NewLockManager(h.DgitDir).Release(&request)
+	if err != nil {
+		http.Error(w, err.Error(), lockStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(released)
+}
+
+// lockStatus maps a lock registry error to an HTTP status
+func lockStatus(err error) int {
+	if errors.Is(err, lock.ErrLocked) {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
@@ -0,0 +1,411 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/bundle"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Protocol identifies the sync protocol spoken between 'dgit push/pull' and 'dgit serve'
+const Protocol = "dgit-remote/1"
+
+// TokenEnv is the environment variable holding the token for every remote
+const TokenEnv = "DGIT_REMOTE_TOKEN"
+
+// DefaultRemote is used when push and pull are given no remote name
+const DefaultRemote = "origin"
+
+// ErrBehind is returned by Push when the remote has commits this repository does not
+var ErrBehind = errors.New("remote has commits this repository does not have")
+
+// validRemoteName keeps remote names usable as file and branch name parts
+var validRemoteName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Advertisement is a server's answer to the first request of a sync: every commit it has
+// Clients compare hashes against their own history to work out what to transfer
+type Advertisement struct {
+	Protocol   string                 `json:"protocol"`
+	Repository string                 `json:"repository"`
+	Head       string                 `json:"head"` // Tip of main
+	Commits    []*bundle.BundleCommit `json:"commits"`
+}
+
+// FetchRequest asks the server for a bundle of the versions the client is missing
+type FetchRequest struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// PushResponse lists the commits a server added from a pushed bundle
+type PushResponse struct {
+	Added []*bundle.BundleCommit `json:"added"`
+}
+
+// PullResult describes a pull
+type PullResult struct {
+	Remote   string
+	Bytes    int64                  // Bundle bytes downloaded
+	Unbundle *bundle.UnbundleResult // nil when already up to date
+}
+
+// PushResult describes a push
+type PushResult struct {
+	Remote string
+	Bytes  int64 // Bundle bytes uploaded
+	Added  []*bundle.BundleCommit
+}
+
+// RemoteManager syncs commits with repositories served over HTTP by 'dgit serve'
+// History is exchanged as bundles, so only versions the other side is missing are transferred
+type RemoteManager struct {
+	DgitDir string
+	KeysDir string
+	TempDir string
+	Client  *http.Client
+
+	// Progress, when set, is called as a pulled bundle downloads; total is -1 when unknown
+	Progress func(done, total int64)
+}
+
+// NewRemoteManager creates a new remote manager for the given .dgit directory
+func NewRemoteManager(dgitDir string) *RemoteManager {
+	return &RemoteManager{
+		DgitDir: dgitDir,
+		KeysDir: filepath.Join(dgitDir, "keys"),
+		TempDir: filepath.Join(dgitDir, "temp"),
+		Client:  &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+// List returns the configured remotes
+func (rm *RemoteManager) List() ([]initializer.RemoteConfig, error) {
+	config, err := initializer.GetRepositoryConfig(rm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Remotes, nil
+}
+
+// Get returns a remote by name
+func (rm *RemoteManager) Get(name string) (*initializer.RemoteConfig, error) {
+	remotes, err := rm.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range remotes {
+		if remotes[i].Name == name {
+			return &remotes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no remote named %q", name)
+}
+
+// Add adds or replaces a remote in the repository config
+func (rm *RemoteManager) Add(name, rawURL string) error {
+	if !validRemoteName.MatchString(name) {
+		return fmt.Errorf("invalid remote name %q (use letters, digits, '.', '_' and '-')", name)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("remote URL must be http:// or https://, got %q", rawURL)
+	}
+	config, err := initializer.GetRepositoryConfig(rm.DgitDir)
+	if err != nil {
+		return err
+	}
+	entry := initializer.RemoteConfig{Name: name, URL: strings.TrimSuffix(rawURL, "/")}
+	for i, existing := range config.Remotes {
+		if existing.Name == name {
+			config.Remotes[i] = entry
+			return initializer.UpdateRepositoryConfig(rm.DgitDir, config)
+		}
+	}
+	config.Remotes = append(config.Remotes, entry)
+	return initializer.UpdateRepositoryConfig(rm.DgitDir, config)
+}
+
+// Remove deletes a remote and its stored token
+func (rm *RemoteManager) Remove(name string) error {
+	config, err := initializer.GetRepositoryConfig(rm.DgitDir)
+	if err != nil {
+		return err
+	}
+	for i, existing := range config.Remotes {
+		if existing.Name == name {
+			config.Remotes = append(config.Remotes[:i], config.Remotes[i+1:]...)
+			os.Remove(rm.tokenFile(name))
+			return initializer.UpdateRepositoryConfig(rm.DgitDir, config)
+		}
+	}
+	return fmt.Errorf("no remote named %q", name)
+}
+
+// Token returns the access token for a remote from DGIT_REMOTE_TOKEN or its token file
+func (rm *RemoteManager) Token(name string) (string, error) {
+	if token := strings.TrimSpace(os.Getenv(TokenEnv)); token != "" {
+		return token, nil
+	}
+	data, err := os.ReadFile(rm.tokenFile(name))
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("no token for remote %s: set %s or run 'dgit remote token %s <token>'", name, TokenEnv, name)
+}
+
+// SaveToken stores a remote's access token in the repository's keys directory
+func (rm *RemoteManager) SaveToken(name, token string) error {
+	if _, err := rm.Get(name); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(rm.KeysDir, 0700); err != nil {
+		return fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := os.WriteFile(rm.tokenFile(name), []byte(strings.TrimSpace(token)), 0600); err != nil {
+		return fmt.Errorf("failed to write remote token: %w", err)
+	}
+	return nil
+}
+
+// Advertise fetches the remote's commit list
+func (rm *RemoteManager) Advertise(name string) (*Advertisement, error) {
+	resp, err := rm.request(name, http.MethodGet, "refs", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var adv Advertisement
+	if err := json.NewDecoder(resp.Body).Decode(&adv); err != nil {
+		return nil, fmt.Errorf("failed to parse remote advertisement: %w", err)
+	}
+	if adv.Protocol != Protocol {
+		return nil, fmt.Errorf("remote speaks %q, expected %q", adv.Protocol, Protocol)
+	}
+	return &adv, nil
+}
+
+// Pull downloads the versions the remote has and this repository lacks, then applies them
+// History that diverged from the remote is imported onto the branch remote/<name>
+func (rm *RemoteManager) Pull(name string) (*PullResult, error) {
+	adv, err := rm.Advertise(name)
+	if err != nil {
+		return nil, err
+	}
+	result := &PullResult{Remote: name}
+	local, err := rm.localHashes()
+	if err != nil {
+		return nil, err
+	}
+	from, to := missingRange(adv.Commits, local)
+	if from == 0 {
+		return result, nil
+	}
+
+	request, _ := json.Marshal(&FetchRequest{From: from, To: to})
+	resp, err := rm.request(name, http.MethodPost, "fetch", "application/json", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	bundlePath, size, err := rm.saveTemp(resp, "pull-*.bundle")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(bundlePath)
+	result.Bytes = size
+
+	unbundled, err := bundle.NewBundleManager(rm.DgitDir).Unbundle(bundlePath, "remote/"+name)
+	if err != nil {
+		return nil, err
+	}
+	result.Unbundle = unbundled
+	return result, nil
+}
+
+// Push uploads the versions the remote lacks; the remote must not have commits this repository lacks
+func (rm *RemoteManager) Push(name string) (*PushResult, error) {
+	adv, err := rm.Advertise(name)
+	if err != nil {
+		return nil, err
+	}
+	result := &PushResult{Remote: name}
+	local, err := rm.localHashes()
+	if err != nil {
+		return nil, err
+	}
+	remoteHashes := make(map[string]bool, len(adv.Commits))
+	behind := 0
+	for _, c := range adv.Commits {
+		remoteHashes[c.Hash] = true
+		if !local[c.Hash] {
+			behind++
+		}
+	}
+	if behind > 0 {
+		return nil, fmt.Errorf("%w (%d commit(s))", ErrBehind, behind)
+	}
+
+	history, err := log.NewLogManager(rm.DgitDir).GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit history: %w", err)
+	}
+	var mine []*bundle.BundleCommit
+	for _, c := range history {
+		mine = append(mine, &bundle.BundleCommit{Version: c.Version, Hash: c.Hash})
+	}
+	from, to := missingRange(mine, remoteHashes)
+	if from == 0 {
+		return result, nil
+	}
+
+	if err := os.MkdirAll(rm.TempDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(rm.TempDir, "push-*.bundle")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	bundlePath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(bundlePath)
+	if _, err := bundle.NewBundleManager(rm.DgitDir).Create(bundlePath, from, to); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil {
+		result.Bytes = info.Size()
+	}
+	resp, err := rm.request(name, http.MethodPost, "push", "application/zip", file)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var response PushResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse push response: %w", err)
+	}
+	result.Added = response.Added
+	return result, nil
+}
+
+// request sends an authenticated request to a remote endpoint and returns the body of a 2xx response
+func (rm *RemoteManager) request(name, method, endpoint, contentType string, body io.Reader) (*http.Response, error) {
+	remote, err := rm.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	token, err := rm.Token(name)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, remote.URL+"/remote/v1/"+endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote URL: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := rm.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach remote %s: %w", name, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("remote %s: %s (%s)", name, strings.TrimSpace(string(message)), resp.Status)
+	}
+	return resp, nil
+}
+
+// saveTemp writes a response body to a temporary file under .dgit/temp, reporting Progress
+func (rm *RemoteManager) saveTemp(resp *http.Response, pattern string) (string, int64, error) {
+	if err := os.MkdirAll(rm.TempDir, 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(rm.TempDir, pattern)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	var body io.Reader = resp.Body
+	if rm.Progress != nil {
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, report: rm.Progress}
+	}
+	size, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, fmt.Errorf("failed to download bundle: %w", err)
+	}
+	return tmp.Name(), size, nil
+}
+
+// localHashes returns the hashes of every local commit
+func (rm *RemoteManager) localHashes() (map[string]bool, error) {
+	history, err := log.NewLogManager(rm.DgitDir).GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit history: %w", err)
+	}
+	hashes := make(map[string]bool, len(history))
+	for _, c := range history {
+		hashes[c.Hash] = true
+	}
+	return hashes, nil
+}
+
+// missingRange returns the version range covering the commits whose hash the other side lacks
+// Both zero means nothing is missing; commits inside the range the other side has are skipped on apply
+func missingRange(commits []*bundle.BundleCommit, have map[string]bool) (int, int) {
+	from, to := 0, 0
+	for _, c := range commits {
+		if have[c.Hash] {
+			continue
+		}
+		if from == 0 || c.Version < from {
+			from = c.Version
+		}
+		if c.Version > to {
+			to = c.Version
+		}
+	}
+	return from, to
+}
+
+// tokenFile is where a remote's token is stored
+func (rm *RemoteManager) tokenFile(name string) string {
+	return filepath.Join(rm.KeysDir, "remote-"+name+".token")
+}
+
+// progressReader reports bytes read so far
+type progressReader struct {
+	r      io.Reader
+	done   int64
+	total  int64
+	report func(done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	p.report(p.done, p.total)
+	return n, err
+}
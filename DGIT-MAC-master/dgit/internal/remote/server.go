@@ -0,0 +1,156 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/bundle"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/webhook"
+)
+
+// Handler serves the sync protocol under /remote/v1/ for 'dgit serve'
+// Requests authenticate with the repository's webhook token
+type Handler struct {
+	DgitDir string
+	tokens  *webhook.WebhookManager
+	mu      sync.Mutex // Serializes pushes, the bundles built for fetches and lock changes
+}
+
+// NewHandler creates the sync handler for the given .dgit directory
+func NewHandler(dgitDir string) *Handler {
+	return &Handler{DgitDir: dgitDir, tokens: webhook.NewWebhookManager(dgitDir)}
+}
+
+// ServeHTTP handles GET refs, POST fetch and POST push, and the lock endpoints GET locks, POST lock and POST unlock
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if err := h.tokens.CheckToken(token); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	switch endpoint := strings.TrimPrefix(r.URL.Path, "/remote/v1/"); {
+	case endpoint == "refs" && r.Method == http.MethodGet:
+		h.serveRefs(w)
+	case endpoint == "fetch" && r.Method == http.MethodPost:
+		h.serveFetch(w, r)
+	case endpoint == "push" && r.Method == http.MethodPost:
+		h.servePush(w, r)
+	case endpoint == "locks" && r.Method == http.MethodGet:
+		h.serveLocks(w)
+	case endpoint == "lock" && r.Method == http.MethodPost:
+		h.serveLock(w, r)
+	case endpoint == "unlock" && r.Method == http.MethodPost:
+		h.serveUnlock(w, r)
+	default:
+		http.Error(w, "unknown remote endpoint", http.StatusNotFound)
+	}
+}
+
+// serveRefs advertises every commit with its version and parent
+func (h *Handler) serveRefs(w http.ResponseWriter) {
+	logManager := log.NewLogManager(h.DgitDir)
+	history, err := logManager.GetCommitHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	adv := &Advertisement{Protocol: Protocol, Repository: filepath.Base(filepath.Dir(h.DgitDir))}
+	adv.Head, _ = logManager.ReadRef(log.MainBranch)
+	for _, c := range history {
+		adv.Commits = append(adv.Commits, &bundle.BundleCommit{
+			Version: c.Version, Hash: c.Hash, ParentHash: c.ParentHash, Message: c.Message,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adv)
+}
+
+// serveFetch streams a bundle of the requested version range
+func (h *Handler) serveFetch(w http.ResponseWriter, r *http.Request) {
+	var request FetchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.From <= 0 || request.To < request.From {
+		http.Error(w, "invalid fetch request", http.StatusBadRequest)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bundlePath, err := h.tempPath("fetch-*.bundle")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(bundlePath)
+	if _, err := bundle.NewBundleManager(h.DgitDir).Create(bundlePath, request.From, request.To); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeFile(w, r, bundlePath)
+}
+
+// servePush applies a pushed bundle if it continues main, rejecting diverged history
+func (h *Handler) servePush(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	bundlePath, err := h.tempPath("push-*.bundle")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(bundlePath)
+	if err := writeBody(bundlePath, r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	manager := bundle.NewBundleManager(h.DgitDir)
+	manager.FastForwardOnly = true
+	result, err := manager.Unbundle(bundlePath, "")
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, bundle.ErrDiverged) {
+			status = http.StatusConflict
+		}
+		http.Error(w, fmt.Sprintf("push rejected: %v", err), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&PushResponse{Added: result.Added})
+}
+
+// tempPath reserves a temporary file name under .dgit/temp
+func (h *Handler) tempPath(pattern string) (string, error) {
+	tempDir := filepath.Join(h.DgitDir, "temp")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(tempDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmp.Close()
+	return tmp.Name(), nil
+}
+
+// writeBody saves a request body to a file
+func writeBody(path string, r *http.Request) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := out.ReadFrom(r.Body); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to receive bundle: %w", err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,205 @@
+package repolock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+)
+
+// File is the repository lock, relative to .dgit
+const File = "locks/repo.lock"
+
+// Defaults for waiting on and expiring the lock
+const (
+	DefaultWait  = 30 * time.Second // How long a commit, restore or staging write waits for another one to finish
+	pollInterval = 100 * time.Millisecond
+	staleAfter   = 12 * time.Hour  // Locks held this long are broken even if the holder cannot be checked
+	writeGrace   = 5 * time.Second // An unreadable lock younger than this is still being written
+)
+
+// Holder describes the process holding the repository lock
+type Holder struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	Operation string    `json:"operation"`
+	Acquired  time.Time `json:"acquired"`
+}
+
+// LockedError is returned when the lock is still held after waiting
+type LockedError struct {
+	Path   string
+	Holder *Holder // nil when the lock file could not be read
+}
+
+func (e *LockedError) Error() string {
+	if e.Holder == nil {
+		return fmt.Sprintf("repository is locked (%s)", e.Path)
+	}
+	return fmt.Sprintf("repository is locked by %s (pid %d on %s, since %s)", e.Holder.Operation, e.Holder.PID,
+		e.Holder.Host, e.Holder.Acquired.Format("15:04:05"))
+}
+
+// Lock is a held repository lock
+// Locks are not reentrant: code that already holds one passes it down (see Reuse) instead of acquiring again
+type Lock struct {
+	path     string
+	slot     chan struct{}
+	borrowed bool
+	released bool
+}
+
+var (
+	slotsMu sync.Mutex
+	slots   = make(map[string]chan struct{}) // Lock path → in-process holder slot
+)
+
+// Acquire takes the repository lock for an operation, waiting up to wait for another holder to release it
+// Goroutines of one process (e.g. 'dgit serve' handlers) exclude each other as well as other processes
+// Locks left by crashed processes on this machine, and locks older than 12 hours, are broken
+func Acquire(dgitDir, operation string, wait time.Duration) (*Lock, error) {
+	path := filepath.Join(dgitDir, File)
+	started := time.Now()
+	slot := slotFor(path)
+	if !takeSlot(slot, wait) {
+		holder, _ := inspect(path, "")
+		return nil, &LockedError{Path: path, Holder: holder}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		<-slot
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	data, _ := json.Marshal(&Holder{PID: os.Getpid(), Host: host, Operation: operation, Acquired: time.Now()})
+	for {
+		created, err := create(path, data)
+		if err != nil {
+			<-slot
+			return nil, err
+		}
+		if created {
+			return &Lock{path: path, slot: slot}, nil
+		}
+		holder, stale := inspect(path, host)
+		if stale {
+			breakStale(path)
+			continue
+		}
+		if time.Since(started) >= wait {
+			<-slot
+			return nil, &LockedError{Path: path, Holder: holder}
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Reuse returns held when the caller already holds the lock, otherwise acquires it
+// Releasing a reused lock does nothing, so the original holder still decides when it is given up
+func Reuse(held *Lock, dgitDir, operation string, wait time.Duration) (*Lock, error) {
+	if held != nil {
+		return &Lock{path: held.path, borrowed: true}, nil
+	}
+	return Acquire(dgitDir, operation, wait)
+}
+
+// Release gives up the lock; releasing twice, or releasing a reused lock, does nothing
+func (l *Lock) Release() {
+	if l == nil || l.borrowed || l.released {
+		return
+	}
+	l.released = true
+	os.Remove(l.path)
+	<-l.slot
+}
+
+// slotFor returns the in-process slot guarding a lock path
+func slotFor(path string) chan struct{} {
+	slotsMu.Lock()
+	defer slotsMu.Unlock()
+	slot, ok := slots[path]
+	if !ok {
+		slot = make(chan struct{}, 1)
+		slots[path] = slot
+	}
+	return slot
+}
+
+// takeSlot claims the in-process slot, waiting up to wait for another goroutine to release it
+func takeSlot(slot chan struct{}, wait time.Duration) bool {
+	select {
+	case slot <- struct{}{}:
+		return true
+	default:
+	}
+	if wait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case slot <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// create writes the lock file only if it does not exist yet
+func create(path string, data []byte) (bool, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create repository lock: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, fmt.Errorf("failed to write repository lock: %w", err)
+	}
+	return true, nil
+}
+
+// inspect reads the lock file and reports whether it was left behind
+// A holder on this host is stale once its process is gone; one on another host only after staleAfter
+func inspect(path, host string) (*Holder, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false // Released meanwhile; the next create decides
+	}
+	var holder Holder
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &holder) != nil {
+		return nil, time.Since(info.ModTime()) > writeGrace
+	}
+	if time.Since(holder.Acquired) > staleAfter {
+		return &holder, true
+	}
+	if holder.Host == host && !iosched.ProcessAlive(holder.PID) {
+		return &holder, true
+	}
+	return &holder, false
+}
+
+// breakStale removes a stale lock file, unless another process replaced it after it was inspected
+func breakStale(path string) {
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	time.Sleep(pollInterval)
+	after, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(before, after) {
+		os.Remove(path)
+	}
+}
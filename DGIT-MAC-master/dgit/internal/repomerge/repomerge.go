@@ -0,0 +1,487 @@
+package repomerge
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// storageDirs lists the directories (relative to .dgit) that hold per-version snapshot data
+var storageDirs = []string{
+	"objects",
+	filepath.Join("objects", "deltas"),
+	filepath.Join("cache", "hot"),
+	filepath.Join("cache", "warm"),
+	filepath.Join("cache", "cold"),
+}
+
+// storageNamePattern matches version storage files such as v3.lz4, v3.zstd or v4_from_v3.bsdiff
+var storageNamePattern = regexp.MustCompile(`^v(\d+)(_from_v(\d+))?(\..+)$`)
+
+// VersionMapping pairs a source repository version with its local version
+type VersionMapping struct {
+	SourceVersion int
+	LocalVersion  int
+	Hash          string
+	Message       string
+}
+
+// Conflict describes a file edited differently on both sides since their common history
+type Conflict struct {
+	Path          string
+	LocalVersion  int // Latest local version that changed the file
+	SourceVersion int // Latest source version that changed the file
+}
+
+// Result summarizes a repository merge
+type Result struct {
+	Branch        string
+	Imported      []*VersionMapping // Source commits copied in under new version numbers
+	Mapped        []*VersionMapping // Source commits whose content already existed locally
+	Conflicts     []*Conflict
+	CommonVersion int // Latest local version shared with the source (0 if unrelated)
+}
+
+// RepoMergeManager imports history from another DGit repository of the same assets
+type RepoMergeManager struct {
+	DgitDir     string
+	SourceLabel string         // Recorded as the import source instead of the source path (e.g. a bundle file)
+	Lock        *repolock.Lock // Repository lock the caller already holds; nil makes MergeRepo take its own
+}
+
+// NewRepoMergeManager creates a new repository merge manager for the given .dgit directory
+func NewRepoMergeManager(dgitDir string) *RepoMergeManager {
+	return &RepoMergeManager{DgitDir: dgitDir}
+}
+
+// DefaultBranchName derives the import branch name from the source repository directory
+func DefaultBranchName(sourcePath string) string {
+	abs, err := filepath.Abs(sourcePath)
+	if err != nil {
+		abs = sourcePath
+	}
+	name := strings.Map(func(r rune) rune {
+		if r < 128 && (r == '.' || r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return '-'
+	}, filepath.Base(abs))
+	return "import/" + strings.Trim(name, ".-")
+}
+
+// MergeRepo imports every commit of the source repository onto an import branch
+// Commits whose content already exists locally are mapped instead of copied, and
+// files edited on both sides since the shared history are reported as conflicts
+func (rm *RepoMergeManager) MergeRepo(sourcePath, branch string, dryRun bool) (*Result, error) {
+	if err := log.ValidateBranchName(branch); err != nil {
+		return nil, err
+	}
+	sourceDgit, err := findSourceDgit(sourcePath)
+	if err != nil {
+		return nil, err
+	}
+	absSource := filepath.Dir(sourceDgit)
+	if rm.SourceLabel != "" {
+		absSource = rm.SourceLabel
+	}
+	if sameDir(sourceDgit, rm.DgitDir) {
+		return nil, fmt.Errorf("cannot merge a repository into itself")
+	}
+	// Imported commits take the next local versions, so nothing else may commit meanwhile
+	if !dryRun {
+		repoLock, err := repolock.Reuse(rm.Lock, rm.DgitDir, "merge-repo", repolock.DefaultWait)
+		if err != nil {
+			return nil, err
+		}
+		defer repoLock.Release()
+	}
+
+	sourceCommits, err := loadCommitsByVersion(sourceDgit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source history: %w", err)
+	}
+	if len(sourceCommits) == 0 {
+		return nil, fmt.Errorf("source repository has no commits")
+	}
+	localCommits, err := loadCommitsByVersion(rm.DgitDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local history: %w", err)
+	}
+
+	// Index local history by commit hash and by snapshot content
+	localByHash := make(map[string]*log.Commit)
+	localByDigest := make(map[string]*log.Commit)
+	for _, c := range localCommits {
+		localByHash[c.Hash] = c
+		if digest := versionDigest(rm.DgitDir, c.Version); digest != "" {
+			if _, exists := localByDigest[digest]; !exists {
+				localByDigest[digest] = c
+			}
+		}
+	}
+
+	result := &Result{Branch: branch}
+	nextVersion := log.NewLogManager(rm.DgitDir).GetCurrentVersion() + 1
+	versionMap := make(map[int]int)    // source version → local version
+	hashMap := make(map[string]string) // source hash → local hash
+	var importedCommits []*log.Commit  // rewritten source commits to write
+	var sourceChanged []*log.Commit    // source commits after the shared history
+	commonSource := 0
+
+	for _, sc := range sourceCommits {
+		// Already present: same commit hash (e.g. a previous merge) or identical snapshot content
+		existing := localByHash[sc.Hash]
+		if existing == nil {
+			if digest := versionDigest(sourceDgit, sc.Version); digest != "" {
+				existing = localByDigest[digest]
+			}
+		}
+		if existing != nil {
+			versionMap[sc.Version] = existing.Version
+			hashMap[sc.Hash] = existing.Hash
+			result.Mapped = append(result.Mapped, &VersionMapping{
+				SourceVersion: sc.Version, LocalVersion: existing.Version, Hash: existing.Hash, Message: sc.Message,
+			})
+			if existing.Branch == "" {
+				commonSource = sc.Version
+				result.CommonVersion = existing.Version
+				sourceChanged = nil // Only edits after the latest shared commit count as divergent
+			}
+			continue
+		}
+
+		imported := *sc
+		imported.Version = nextVersion
+		imported.Branch = branch
+		imported.ImportedFrom = &log.ImportSource{
+			Repository: absSource,
+			Version:    sc.Version,
+			ImportedAt: time.Now(),
+		}
+		if parent, ok := hashMap[sc.ParentHash]; ok {
+			imported.ParentHash = parent
+		}
+		if sc.CompressionInfo != nil {
+			info := *sc.CompressionInfo
+			info.OutputFile = renameStorageFile(info.OutputFile, versionMap, sc.Version, nextVersion)
+			if mapped, ok := versionMap[info.BaseVersion]; ok {
+				info.BaseVersion = mapped
+			}
+			imported.CompressionInfo = &info
+		}
+		if imported.SnapshotZip != "" {
+			imported.SnapshotZip = renameStorageFile(imported.SnapshotZip, versionMap, sc.Version, nextVersion)
+		}
+
+		versionMap[sc.Version] = nextVersion
+		hashMap[sc.Hash] = sc.Hash
+		importedCommits = append(importedCommits, &imported)
+		sourceChanged = append(sourceChanged, sc)
+		result.Imported = append(result.Imported, &VersionMapping{
+			SourceVersion: sc.Version, LocalVersion: nextVersion, Hash: sc.Hash, Message: sc.Message,
+		})
+		nextVersion++
+	}
+
+	result.Conflicts = findConflicts(localCommits, result.CommonVersion, sourceCommits, commonSource, sourceChanged)
+
+	if dryRun {
+		return result, nil
+	}
+
+	for _, c := range importedCommits {
+		if err := copyVersionStorage(sourceDgit, rm.DgitDir, c.ImportedFrom.Version, c.Version, versionMap); err != nil {
+			return nil, fmt.Errorf("failed to copy data for source v%d: %w", c.ImportedFrom.Version, err)
+		}
+		if err := writeCommit(rm.DgitDir, c); err != nil {
+			return nil, err
+		}
+	}
+	// The import branch can be checked out like any other
+	if len(importedCommits) > 0 {
+		tip := importedCommits[len(importedCommits)-1]
+		if err := log.NewLogManager(rm.DgitDir).WriteRef(branch, tip.Hash); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// findConflicts reports files changed on both sides since the shared history with different results
+func findConflicts(localCommits []*log.Commit, commonLocal int, sourceCommits []*log.Commit, commonSource int,
+	sourceChanged []*log.Commit) []*Conflict {
+
+	localBase := fingerprintsAt(localCommits, commonLocal)
+	sourceBase := fingerprintsAt(sourceCommits, commonSource)
+
+	// Files the local mainline changed after the shared version
+	localChanged := make(map[string]int)
+	localFinal := make(map[string]string)
+	for _, c := range localCommits {
+		if c.Branch != "" || c.Version <= commonLocal {
+			continue
+		}
+		for path, md := range c.Metadata {
+			fp := fingerprint(md)
+			localFinal[path] = fp
+			if fp != localBase[path] {
+				localChanged[path] = c.Version
+			}
+		}
+	}
+
+	// Files the source changed after the shared version
+	sourceChangedFiles := make(map[string]int)
+	sourceFinal := make(map[string]string)
+	for _, c := range sourceChanged {
+		for path, md := range c.Metadata {
+			fp := fingerprint(md)
+			sourceFinal[path] = fp
+			if fp != sourceBase[path] {
+				sourceChangedFiles[path] = c.Version
+			}
+		}
+	}
+
+	var conflicts []*Conflict
+	for path, localVersion := range localChanged {
+		sourceVersion, ok := sourceChangedFiles[path]
+		if !ok || localFinal[path] == sourceFinal[path] {
+			continue
+		}
+		conflicts = append(conflicts, &Conflict{
+			Path:          path,
+			LocalVersion:  localVersion,
+			SourceVersion: sourceVersion,
+		})
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	return conflicts
+}
+
+// fingerprintsAt returns file fingerprints of the commit with the given version
+func fingerprintsAt(commits []*log.Commit, version int) map[string]string {
+	fingerprints := make(map[string]string)
+	for _, c := range commits {
+		if c.Version == version {
+			for path, md := range c.Metadata {
+				fingerprints[path] = fingerprint(md)
+			}
+		}
+	}
+	return fingerprints
+}
+
+// fingerprint summarizes a file's recorded metadata for change detection
+// Modification times are ignored because copies between machines change them
+func fingerprint(metadata interface{}) string {
+	md, ok := metadata.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if checksum, ok := md["sha256"].(string); ok && checksum != "" {
+		return checksum
+	}
+	parts := make([]string, 0, 6)
+	for _, key := range []string{"size", "type", "dimensions", "color_mode", "layers", "layer_names"} {
+		parts = append(parts, fmt.Sprint(md[key]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// versionDigest hashes the decompressed snapshot of a version for content comparison
+// Delta-only versions return an empty digest and are never mapped
+func versionDigest(dgitDir string, version int) string {
+	if c, err := log.NewLogManager(dgitDir).GetCommit(version); err == nil && len(c.Blobs) > 0 {
+		return blobsDigest(c.Blobs)
+	}
+	candidates := []string{
+		filepath.Join(dgitDir, "cache", "hot", fmt.Sprintf("v%d.lz4", version)),
+		filepath.Join(dgitDir, "cache", "hot", fmt.Sprintf("v%d.store", version)),
+		filepath.Join(dgitDir, "cache", "warm", fmt.Sprintf("v%d.zstd", version)),
+		filepath.Join(dgitDir, "objects", fmt.Sprintf("v%d.zip", version)),
+	}
+	for _, path := range candidates {
+		if digest, err := digestFile(path); err == nil {
+			return digest
+		}
+	}
+	return ""
+}
+
+// blobsDigest hashes a content-addressed version's file list, which already identifies its content
+func blobsDigest(blobs map[string]*log.BlobRef) string {
+	paths := make([]string, 0, len(blobs))
+	for path := range blobs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", path, blobs[path].Hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// digestFile computes the SHA-256 of a storage file's decompressed content
+func digestFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch {
+	case strings.HasSuffix(path, ".lz4"):
+		reader = codec.NewLZ4Reader(file)
+	case strings.HasSuffix(path, ".zstd"):
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return "", err
+		}
+		defer decoder.Close()
+		reader = decoder
+	case strings.HasSuffix(path, ".store"):
+		store, _ := codec.Get(codec.Store)
+		decoded, err := store.NewReader(file)
+		if err != nil {
+			return "", err
+		}
+		reader = decoded
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// StorageFiles returns the snapshot data files of a version, relative to the .dgit directory
+// Commit metadata JSON is not included
+func StorageFiles(dgitDir string, version int) []string {
+	var files []string
+	for _, dir := range storageDirs {
+		entries, err := os.ReadDir(filepath.Join(dgitDir, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			m := storageNamePattern.FindStringSubmatch(entry.Name())
+			if m == nil || m[1] != strconv.Itoa(version) {
+				continue
+			}
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Content-addressed versions reference shared blobs instead of a per-version file
+	if c, err := log.NewLogManager(dgitDir).GetCommit(version); err == nil && len(c.Blobs) > 0 {
+		files = append(files, objstore.NewObjectStore(dgitDir).Files(c.Blobs)...)
+	}
+	return files
+}
+
+// copyVersionStorage copies every storage file of a source version under its new local version number
+func copyVersionStorage(sourceDgit, localDgit string, sourceVersion, localVersion int, versionMap map[int]int) error {
+	files := StorageFiles(sourceDgit, sourceVersion)
+	if len(files) == 0 {
+		return fmt.Errorf("no snapshot data found")
+	}
+	for _, relPath := range files {
+		dir := filepath.Dir(relPath)
+		if objstore.IsBlobPath(relPath) {
+			// Blobs are named by content, so one already present is identical
+			if _, err := os.Stat(filepath.Join(localDgit, relPath)); err == nil {
+				continue
+			}
+		}
+		targetName := renameStorageFile(filepath.Base(relPath), versionMap, sourceVersion, localVersion)
+		if err := os.MkdirAll(filepath.Join(localDgit, dir), 0755); err != nil {
+			return err
+		}
+		if err := util.CopyFile(filepath.Join(sourceDgit, relPath), filepath.Join(localDgit, dir, targetName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameStorageFile renumbers a storage file name such as v4_from_v3.bsdiff for the local repository
+func renameStorageFile(name string, versionMap map[int]int, sourceVersion, localVersion int) string {
+	m := storageNamePattern.FindStringSubmatch(name)
+	if m == nil || m[1] != strconv.Itoa(sourceVersion) {
+		return name
+	}
+	renamed := fmt.Sprintf("v%d", localVersion)
+	if m[3] != "" {
+		base, _ := strconv.Atoi(m[3])
+		if mapped, ok := versionMap[base]; ok {
+			base = mapped
+		}
+		renamed += fmt.Sprintf("_from_v%d", base)
+	}
+	return renamed + m[4]
+}
+
+// loadCommitsByVersion loads a repository's commits sorted by version
+func loadCommitsByVersion(dgitDir string) ([]*log.Commit, error) {
+	commits, err := log.NewLogManager(dgitDir).GetCommitHistory()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(commits, func(i, j int) bool { return commits[i].Version < commits[j].Version })
+	return commits, nil
+}
+
+// writeCommit saves an imported commit's metadata
+func writeCommit(dgitDir string, c *log.Commit) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal commit v%d: %w", c.Version, err)
+	}
+	return log.NewLogManager(dgitDir).SaveCommitData(data)
+}
+
+// findSourceDgit locates the .dgit directory of the repository to merge
+func findSourceDgit(sourcePath string) (string, error) {
+	abs, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", sourcePath, err)
+	}
+	if filepath.Base(abs) != ".dgit" {
+		abs = filepath.Join(abs, ".dgit")
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("not a dgit repository: %s", sourcePath)
+	}
+	return abs, nil
+}
+
+// sameDir reports whether two paths refer to the same directory
+func sameDir(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
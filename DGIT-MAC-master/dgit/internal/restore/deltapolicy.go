@@ -0,0 +1,121 @@
+package restore
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// DefaultMaxDeltaDepth is the longest delta chain replayed when a full snapshot is available instead
+const DefaultMaxDeltaDepth = 32
+
+// deltaReplayBytesPerSecond is the planning estimate for rebuilding one snapshot from a patch
+const deltaReplayBytesPerSecond = 150 << 20
+
+// deltaStrategies are the commit strategies restored by replaying patches onto a base version
+var deltaStrategies = map[string]bool{
+	"bsdiff":             true,
+	"xdelta3":            true,
+	"psd_smart_delta":    true,
+	"psd_smart":          true,
+	"design_smart_delta": true,
+}
+
+// DeltaDecision records how the delta depth policy treated a restore
+type DeltaDecision struct {
+	Depth         int           // Patches between the version and the nearest full snapshot
+	EstimatedTime time.Duration // Estimated replay time of the chain
+	MaxDepth      int           // 0 when depth is not limited
+	MaxTime       time.Duration // 0 when replay time is not limited
+	Exceeded      bool
+	Fallback      string // "cold_cache" or "archive_recall" when a full snapshot was used instead; "" when the chain was replayed
+	Reason        string
+}
+
+// deltaLimits reads the policy limits from the repository config
+func (rm *RestoreManager) deltaLimits() (int, time.Duration) {
+	maxDepth, maxTime := DefaultMaxDeltaDepth, time.Duration(0)
+	if config, err := initializer.GetRepositoryConfig(rm.DgitDir); err == nil {
+		cache := config.Compression.CacheConfig
+		switch {
+		case cache.MaxDeltaDepth < 0:
+			maxDepth = 0
+		case cache.MaxDeltaDepth > 0:
+			maxDepth = cache.MaxDeltaDepth
+		}
+		maxTime = time.Duration(cache.MaxDeltaRestoreSeconds * float64(time.Second))
+	}
+	return maxDepth, maxTime
+}
+
+// decideDeltaRestore measures a delta version's chain against the policy and, when it is too deep
+// or too slow to replay, picks a cold or archived full snapshot of the same version instead
+func (rm *RestoreManager) decideDeltaRestore(commit *log.Commit) *DeltaDecision {
+	decision := &DeltaDecision{}
+	decision.MaxDepth, decision.MaxTime = rm.deltaLimits()
+	var replayBytes int64
+	decision.Depth, replayBytes = rm.measureDeltaChain(commit)
+	decision.EstimatedTime = time.Duration(float64(replayBytes) / deltaReplayBytesPerSecond * float64(time.Second))
+
+	switch {
+	case decision.MaxDepth > 0 && decision.Depth > decision.MaxDepth:
+		decision.Exceeded = true
+		decision.Reason = fmt.Sprintf("delta chain of %d exceeds the limit of %d", decision.Depth, decision.MaxDepth)
+	case decision.MaxTime > 0 && decision.EstimatedTime > decision.MaxTime:
+		decision.Exceeded = true
+		decision.Reason = fmt.Sprintf("estimated replay time %s exceeds the limit of %s",
+			decision.EstimatedTime.Round(time.Microsecond), decision.MaxTime)
+	default:
+		decision.Reason = fmt.Sprintf("delta chain of %d is within policy", decision.Depth)
+		return decision
+	}
+
+	if rm.fileExists(filepath.Join(rm.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", commit.Version))) {
+		decision.Fallback = "cold_cache"
+	} else if record, ok := archive.NewArchiveManager(rm.DgitDir).Get(commit.Version); ok && record.Offline {
+		decision.Fallback = "archive_recall"
+	} else {
+		decision.Reason += "; no full snapshot available, replaying anyway"
+	}
+	return decision
+}
+
+// measureDeltaChain counts the patches from a version down to the nearest full snapshot
+// and estimates the bytes replay has to rebuild: one full snapshot per patch
+func (rm *RestoreManager) measureDeltaChain(commit *log.Commit) (int, int64) {
+	logManager := log.NewLogManager(rm.DgitDir)
+	order := rm.readOrder()
+	depth := 0
+	var bytes int64
+	seen := make(map[int]bool)
+	for current := commit; current != nil && current.CompressionInfo != nil; {
+		info := current.CompressionInfo
+		if !deltaStrategies[info.Strategy] || seen[current.Version] {
+			break
+		}
+		if _, cached := rm.cachedSnapshotStep(order, current.Version); cached && current != commit {
+			break
+		}
+		seen[current.Version] = true
+		depth++
+		bytes += info.OriginalSize + info.CompressedSize
+
+		base := info.BaseVersion
+		if base == 0 && info.Strategy == "bsdiff" {
+			base = current.Version - 1
+		}
+		if base <= 0 {
+			break
+		}
+		next, err := logManager.GetCommit(base)
+		if err != nil {
+			break
+		}
+		current = next
+	}
+	return depth, bytes
+}
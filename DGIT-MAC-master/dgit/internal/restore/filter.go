@@ -0,0 +1,71 @@
+package restore
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// FileFilter limits a restore to some file types and sizes, e.g. lightweight sources for a laptop
+// Files it excludes are reported in RestoreResult.SkippedFiles
+type FileFilter struct {
+	Types   []string // Extensions with or without the dot ("psd", ".ai"); empty allows every type
+	MaxSize int64    // Largest committed size restored; 0 allows any size
+}
+
+// Allows reports whether a committed file passes the filter
+func (f *FileFilter) Allows(path string, size int64) bool {
+	if f.MaxSize > 0 && size > f.MaxSize {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, t := range f.Types {
+		if strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), ".") == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterTargets narrows a restore's targets to the committed files rm.Filter allows
+// Targets match like restore arguments; with no targets every committed file is considered
+func (rm *RestoreManager) FilterTargets(commit *log.Commit, targets []string) (kept, skipped []string) {
+	normalizedTargets := make([]string, len(targets))
+	for i, target := range targets {
+		normalizedTargets[i] = filepath.Clean(strings.ReplaceAll(target, "\\", "/"))
+	}
+	for path, raw := range commit.Metadata {
+		if len(targets) > 0 && !rm.shouldRestoreFile(path, normalizedTargets) {
+			continue
+		}
+		if rm.Filter == nil || rm.Filter.Allows(path, committedSize(commit, path, raw)) {
+			kept = append(kept, path)
+		} else {
+			skipped = append(skipped, path)
+		}
+	}
+	sort.Strings(kept)
+	sort.Strings(skipped)
+	return kept, skipped
+}
+
+// committedSize reads a file's size from the commit metadata or its object store reference
+func committedSize(commit *log.Commit, path string, raw interface{}) int64 {
+	if meta, ok := raw.(map[string]interface{}); ok {
+		switch size := meta["size"].(type) {
+		case float64:
+			return int64(size)
+		case int64:
+			return size
+		}
+	}
+	if ref := commit.Blobs[filepath.ToSlash(path)]; ref != nil {
+		return ref.Size
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Journal records a restore in progress so an interrupted restore can be resumed
+// Completed files are stored with their CRC32 so they can be verified instead of rewritten
+type Journal struct {
+	Version    int               `json:"version"`
+	CommitHash string            `json:"commit_hash"`
+	Targets    []string          `json:"targets,omitempty"`
+	WorkDir    string            `json:"work_dir"`
+	StartedAt  time.Time         `json:"started_at"`
+	Planned    []string          `json:"planned"`
+	Completed  map[string]uint32 `json:"completed"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// journalPath is where the restore journal lives
+func (rm *RestoreManager) journalPath() string {
+	return filepath.Join(rm.DgitDir, "restore-journal.json")
+}
+
+// StartJournal records the files a restore is about to write and attaches the journal to the manager
+func (rm *RestoreManager) StartJournal(commit *log.Commit, targets []string) (*Journal, error) {
+	workDir, err := rm.getWorkDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	normalizedTargets := make([]string, len(targets))
+	for i, target := range targets {
+		normalizedTargets[i] = filepath.Clean(strings.ReplaceAll(target, "\\", "/"))
+	}
+	var planned []string
+	for path := range commit.Metadata {
+		if len(targets) == 0 || rm.shouldRestoreFile(path, normalizedTargets) {
+			planned = append(planned, path)
+		}
+	}
+	sort.Strings(planned)
+
+	journal := &Journal{
+		Version:    commit.Version,
+		CommitHash: commit.Hash,
+		Targets:    targets,
+		WorkDir:    workDir,
+		StartedAt:  time.Now(),
+		Planned:    planned,
+		Completed:  make(map[string]uint32),
+		path:       rm.journalPath(),
+	}
+	if err := journal.save(); err != nil {
+		return nil, err
+	}
+	rm.Journal = journal
+	return journal, nil
+}
+
+// LoadJournal returns the journal of an interrupted restore, or nil if there is none
+func (rm *RestoreManager) LoadJournal() (*Journal, error) {
+	data, err := os.ReadFile(rm.journalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restore journal: %w", err)
+	}
+	journal := &Journal{path: rm.journalPath()}
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, fmt.Errorf("failed to parse restore journal: %w", err)
+	}
+	if journal.Completed == nil {
+		journal.Completed = make(map[string]uint32)
+	}
+	return journal, nil
+}
+
+// FinishJournal removes the journal once a restore has completed
+func (rm *RestoreManager) FinishJournal() error {
+	rm.Journal = nil
+	if err := os.Remove(rm.journalPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove restore journal: %w", err)
+	}
+	return nil
+}
+
+// VerifyJournal checks the files a journal marks as completed against their recorded checksums
+// Returns the planned files that are intact and those that still need restoring
+func (rm *RestoreManager) VerifyJournal(journal *Journal) (verified, remaining []string) {
+	for _, path := range journal.Planned {
+		crc, ok := journal.Completed[path]
+		if ok && fileCRC32(filepath.Join(journal.WorkDir, filepath.FromSlash(path))) == crc {
+			verified = append(verified, path)
+			continue
+		}
+		delete(journal.Completed, path)
+		remaining = append(remaining, path)
+	}
+	return verified, remaining
+}
+
+// Pending returns the planned files not yet marked as completed
+func (j *Journal) Pending() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var pending []string
+	for _, path := range j.Planned {
+		if _, ok := j.Completed[path]; !ok {
+			pending = append(pending, path)
+		}
+	}
+	return pending
+}
+
+// complete marks a file as written and persists the journal
+func (j *Journal) complete(path string, crc uint32) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Completed[path] = crc
+	j.save()
+}
+
+// save writes the journal to disk
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal restore journal: %w", err)
+	}
+	if err := os.WriteFile(j.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write restore journal: %w", err)
+	}
+	return nil
+}
+
+// fileCRC32 returns the CRC32 of a file, or 0 when it cannot be read
+func fileCRC32(path string) uint32 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	hash := crc32.NewIEEE()
+	if _, err := io.Copy(hash, f); err != nil {
+		return 0
+	}
+	return hash.Sum32()
+}
@@ -0,0 +1,159 @@
+package restore
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+)
+
+// What a restore would do to a working file
+const (
+	WriteCreate    = "create"    // No working file yet
+	WriteOverwrite = "overwrite" // Working file differs from the restored content
+	WriteUnchanged = "unchanged" // Working file already matches
+)
+
+// PlannedWrite is one file a restore would write
+type PlannedWrite struct {
+	Path   string // Repo-relative
+	Action string
+	Size   int64 // Committed size
+	Dirty  bool  // Working file has content no commit holds; overwriting loses it
+}
+
+// PlanRestore lists the files a restore of commit would write and how each working file is affected
+// A working file is dirty when its content was never committed: it matches neither HEAD nor any other version,
+// so a file left by restoring an older version can be overwritten without losing anything
+func (rm *RestoreManager) PlanRestore(commit *log.Commit, targets []string) ([]*PlannedWrite, error) {
+	rootDir, err := rm.getWorkDir()
+	if err != nil {
+		return nil, err
+	}
+	logManager := log.NewLogManager(rm.DgitDir)
+	var head *log.Commit
+	if headVersion := logManager.GetHeadVersion(); headVersion > 0 {
+		head, _ = logManager.GetCommit(headVersion)
+	}
+
+	committed := &committedContent{dgitDir: rm.DgitDir}
+
+	paths, _ := rm.FilterTargets(commit, targets)
+	plan := make([]*PlannedWrite, 0, len(paths))
+	for _, path := range paths {
+		write := &PlannedWrite{Path: path, Action: WriteCreate, Size: committedSize(commit, path, commit.Metadata[path])}
+		plan = append(plan, write)
+		workingPath := filepath.Join(rootDir, filepath.FromSlash(path))
+		if _, err := os.Stat(workingPath); err != nil {
+			continue
+		}
+		hash, err := fileSHA256(workingPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		write.Action = WriteOverwrite
+		if hash == committedHash(commit, path) {
+			write.Action = WriteUnchanged
+		}
+		if write.Action == WriteOverwrite && (head == nil || hash != committedHash(head, path)) {
+			held, err := committed.holds(hash)
+			if err != nil {
+				return nil, err
+			}
+			write.Dirty = !held
+		}
+	}
+	return plan, nil
+}
+
+// committedContent answers whether some commit holds a file's content
+// The object store is checked first; history is only read, once, for snapshot commits and evicted blobs
+type committedContent struct {
+	dgitDir string
+	store   *objstore.ObjectStore
+	hashes  map[string]bool
+}
+
+// holds reports whether any version committed content with this SHA256, under any path
+func (cc *committedContent) holds(hash string) (bool, error) {
+	if cc.store == nil {
+		cc.store = objstore.NewObjectStore(cc.dgitDir)
+	}
+	if cc.store.Has(hash) {
+		return true, nil
+	}
+	if cc.hashes == nil {
+		it, err := log.NewLogManager(cc.dgitDir).Iterate(log.CommitFilter{})
+		if err != nil {
+			return false, err
+		}
+		defer it.Close()
+		cc.hashes = make(map[string]bool)
+		for it.Next() {
+			commit := it.Commit()
+			for path := range commit.Metadata {
+				cc.hashes[committedHash(commit, path)] = true
+			}
+			for _, ref := range commit.Blobs {
+				cc.hashes[ref.Hash] = true
+			}
+		}
+		if err := it.Err(); err != nil {
+			return false, err
+		}
+		delete(cc.hashes, "")
+	}
+	return cc.hashes[hash], nil
+}
+
+// committedHash returns the SHA256 a commit recorded for a file, "" when unknown
+func committedHash(commit *log.Commit, path string) string {
+	if meta, ok := commit.Metadata[path].(map[string]interface{}); ok {
+		if hash, _ := meta["sha256"].(string); hash != "" {
+			return hash
+		}
+	}
+	if ref := commit.Blobs[path]; ref != nil {
+		return ref.Hash
+	}
+	return ""
+}
+
+// BackupFiles copies working files to .dgit/temp/restore-backup-<time> before a restore overwrites them
+// Returns the backup directory
+func (rm *RestoreManager) BackupFiles(paths []string) (string, error) {
+	rootDir, err := rm.getWorkDir()
+	if err != nil {
+		return "", err
+	}
+	backupDir := filepath.Join(rm.DgitDir, "temp", "restore-backup-"+time.Now().Format("20060102-150405"))
+	for _, path := range paths {
+		target := filepath.Join(backupDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := rm.copyFile(filepath.Join(rootDir, filepath.FromSlash(path)), target); err != nil {
+			return "", fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	return backupDir, nil
+}
+
+// fileSHA256 returns the hex SHA256 of a file, as recorded in commit metadata
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
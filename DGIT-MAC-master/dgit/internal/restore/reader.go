@@ -0,0 +1,230 @@
+package restore
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+)
+
+// OpenFile returns one file of a committed version as a stream, for preview servers and converters
+// Snapshot objects are decompressed only up to the end of the file and nothing is written to disk;
+// bsdiff and smart delta versions are the exception and are rebuilt through a temporary ZIP.
+// Reading to the end verifies the file's checksum where the storage format records one
+func (rm *RestoreManager) OpenFile(version int, path string) (io.ReadCloser, error) {
+	commit, err := log.NewLogManager(rm.DgitDir).GetCommit(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit v%d: %w", version, err)
+	}
+	path = filepath.ToSlash(filepath.Clean(path))
+
+	if commit.CompressionInfo != nil && commit.CompressionInfo.Strategy == objstore.Strategy {
+		ref := commit.Blobs[path]
+		if ref == nil {
+			return nil, notInVersion(path, version)
+		}
+		store := objstore.NewObjectStore(rm.DgitDir)
+		if !store.Has(ref.Hash) {
+			// Blobs only this version referenced left the store when it was archived
+			if record, ok := archive.NewArchiveManager(rm.DgitDir).Get(version); ok && packed(record, path) {
+				coldPath := filepath.Join(rm.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", version))
+				if rm.fileExists(coldPath) || rm.recallArchived(version) {
+					stream, err := rm.openHotObject(coldPath)
+					if err != nil {
+						return nil, err
+					}
+					return rm.openStreamFile(stream, commit, path)
+				}
+			}
+		}
+		return store.Open(ref.Hash)
+	}
+
+	// Full snapshot objects, cheapest tier first
+	for _, object := range []string{
+		filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d.lz4", version)),
+		filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d.store", version)),
+		filepath.Join(rm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version)),
+	} {
+		if !rm.fileExists(object) {
+			continue
+		}
+		stream, err := rm.openHotObject(object)
+		if err != nil {
+			return nil, err
+		}
+		return rm.openStreamFile(stream, commit, path)
+	}
+
+	if commit.CompressionInfo != nil {
+		switch commit.CompressionInfo.Strategy {
+		case "xdelta3":
+			stream, err := rm.SnapshotStream(version)
+			if err != nil {
+				return nil, err
+			}
+			return rm.openStreamFile(io.NopCloser(bytes.NewReader(stream)), commit, path)
+		case "zip":
+			return rm.openZipFile(filepath.Join(rm.ObjectsDir, commit.CompressionInfo.OutputFile), path, version, false)
+		case "bsdiff", "psd_smart_delta", "psd_smart", "design_smart_delta":
+			restorationPath, err := rm.findOptimizedRestorationPath(version)
+			if err != nil {
+				return nil, err
+			}
+			tempFile, err := rm.executeOptimizedRestorationPath(restorationPath)
+			if err != nil {
+				return nil, err
+			}
+			return rm.openZipFile(tempFile, path, version, true)
+		}
+	}
+
+	coldPath := filepath.Join(rm.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", version))
+	if rm.fileExists(coldPath) || rm.recallArchived(version) {
+		stream, err := rm.openHotObject(coldPath)
+		if err != nil {
+			return nil, err
+		}
+		return rm.openStreamFile(stream, commit, path)
+	}
+
+	if commit.SnapshotZip != "" {
+		return rm.openZipFile(filepath.Join(rm.ObjectsDir, commit.SnapshotZip), path, version, false)
+	}
+	return nil, fmt.Errorf("no snapshot data for v%d", version)
+}
+
+// packed reports whether an archived version's cold blob holds a file
+func packed(record *archive.Record, path string) bool {
+	i := sort.SearchStrings(record.Files, path)
+	return i < len(record.Files) && record.Files[i] == path
+}
+
+// fileReader is one file inside a larger stream; Close releases the stream
+type fileReader struct {
+	io.Reader
+	close func() error
+}
+
+func (f *fileReader) Close() error {
+	return f.close()
+}
+
+// openStreamFile positions a decoded snapshot stream at one file
+// Files before it are skipped without being kept; the stream is closed on error
+func (rm *RestoreManager) openStreamFile(stream io.ReadCloser, commit *log.Commit, path string) (io.ReadCloser, error) {
+	reader, err := seekStreamFile(bufio.NewReader(stream), commit, path, rm.storedPath)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	return &fileReader{Reader: reader, close: stream.Close}, nil
+}
+
+// seekStreamFile reads a framed, text-framed or headerless stream up to the start of a file
+// Recorded paths are compared after mapping them with storedPath
+func seekStreamFile(br *bufio.Reader, commit *log.Commit, path string, storedPath func(string) string) (io.Reader, error) {
+	head, _ := br.Peek(len(codec.FramedMagic))
+
+	if codec.IsFramed(head) {
+		frames, err := codec.NewFrameReader(br)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			header, err := frames.Next()
+			if err == io.EOF {
+				return nil, notInVersion(path, commit.Version)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if storedPath(header.Path) == path {
+				return frames, nil
+			}
+		}
+	}
+
+	// Legacy text-framed streams: "FILE:path:size\n" then the data
+	if bytes.HasPrefix(head, []byte(codec.TextFramedHeader)) {
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				return nil, notInVersion(path, commit.Version)
+			}
+			header := strings.TrimSuffix(strings.TrimPrefix(line, codec.TextFramedHeader), "\n")
+			sep := strings.LastIndex(header, ":")
+			if sep < 0 {
+				return nil, fmt.Errorf("corrupt stream header %q", strings.TrimSpace(line))
+			}
+			size, err := strconv.ParseInt(header[sep+1:], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("corrupt stream header %q", strings.TrimSpace(line))
+			}
+			if storedPath(header[:sep]) == path {
+				return io.LimitReader(br, size), nil
+			}
+			if _, err := io.CopyN(io.Discard, br, size); err != nil {
+				return nil, fmt.Errorf("truncated stream: %w", err)
+			}
+		}
+	}
+
+	// Headerless streams hold the commit's only file
+	for name := range commit.Metadata {
+		if storedPath(name) == path {
+			return br, nil
+		}
+	}
+	return nil, notInVersion(path, commit.Version)
+}
+
+// openZipFile opens one entry of a snapshot ZIP, removing the ZIP on Close when it is temporary
+func (rm *RestoreManager) openZipFile(zipPath, path string, version int, temporary bool) (io.ReadCloser, error) {
+	cleanup := func() {
+		if temporary {
+			os.Remove(zipPath)
+		}
+	}
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to open snapshot zip: %w", err)
+	}
+	for _, f := range archive.File {
+		if rm.storedPath(f.Name) != path {
+			continue
+		}
+		entry, err := f.Open()
+		if err != nil {
+			archive.Close()
+			cleanup()
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		return &fileReader{Reader: entry, close: func() error {
+			entry.Close()
+			err := archive.Close()
+			cleanup()
+			return err
+		}}, nil
+	}
+	archive.Close()
+	cleanup()
+	return nil, notInVersion(path, version)
+}
+
+// notInVersion reports a path missing from a version's snapshot
+func notInVersion(path string, version int) error {
+	return fmt.Errorf("%s is not in v%d", path, version)
+}
@@ -0,0 +1,84 @@
+package restore
+
+import (
+	"fmt"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Storage tiers restore can read a version from
+const (
+	TierHot    = "hot"    // LZ4 hot cache
+	TierWarm   = "warm"   // Zstd warm cache
+	TierSmart  = "smart"  // The commit's own strategy: deltas, ZIP objects
+	TierCold   = "cold"   // Cold archive
+	TierLegacy = "legacy" // Legacy ZIP snapshot
+)
+
+// DefaultReadOrder is the tier probe order used when the repository does not configure one
+var DefaultReadOrder = []string{TierHot, TierWarm, TierSmart, TierCold, TierLegacy}
+
+// ParseReadOrder validates a probe order; tiers left out are never read
+func ParseReadOrder(order []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var parsed []string
+	for _, tier := range order {
+		tier = strings.ToLower(strings.TrimSpace(tier))
+		switch tier {
+		case TierHot, TierWarm, TierSmart, TierCold, TierLegacy:
+		default:
+			return nil, fmt.Errorf("unknown storage tier %q (valid: %s)", tier, strings.Join(DefaultReadOrder, ", "))
+		}
+		if seen[tier] {
+			return nil, fmt.Errorf("storage tier %q listed twice", tier)
+		}
+		seen[tier] = true
+		parsed = append(parsed, tier)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("read order must list at least one tier")
+	}
+	return parsed, nil
+}
+
+// readOrder returns the probe order: the manager's override, the repository config, or the default
+// An invalid configured order falls back to the default rather than making versions unreadable
+func (rm *RestoreManager) readOrder() []string {
+	if len(rm.ReadOrder) > 0 {
+		return rm.ReadOrder
+	}
+	config, err := initializer.GetRepositoryConfig(rm.DgitDir)
+	if err != nil || len(config.Compression.CacheConfig.ReadOrder) == 0 {
+		return DefaultReadOrder
+	}
+	order, err := ParseReadOrder(config.Compression.CacheConfig.ReadOrder)
+	if err != nil {
+		fmt.Printf("Warning: ignoring compression.cache.read_order: %v\n", err)
+		return DefaultReadOrder
+	}
+	return order
+}
+
+// prefers reports whether tier a is probed before tier b; a tier left out of the order is never preferred
+func prefers(order []string, a, b string) bool {
+	for _, tier := range order {
+		switch tier {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
+
+// allows reports whether a tier is in the order
+func allows(order []string, tier string) bool {
+	for _, t := range order {
+		if t == tier {
+			return true
+		}
+	}
+	return false
+}
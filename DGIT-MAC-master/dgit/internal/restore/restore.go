@@ -2,20 +2,40 @@ package restore
 
 import (
 	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/cache"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/hooks"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/trash"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/vcdiff"
 	"github.com/klauspost/compress/zstd"
-	"github.com/pierrec/lz4/v4"
 	"github.com/kr/binarydist"
+	"github.com/pierrec/lz4/v4"
 )
 
+// HookPreRestore runs before a restore writes any file; a non-zero exit aborts it
+const HookPreRestore = "pre-restore"
+
 // RestoreManager handles ultra-fast file restoration with 3-tier cache optimization
 // Achieves dramatic speed improvements through intelligent cache utilization
 type RestoreManager struct {
@@ -26,6 +46,24 @@ type RestoreManager struct {
 	HotCacheDir  string  // LZ4 cache for 0.2s access - fastest restoration
 	WarmCacheDir string  // Zstd cache for 0.5s access - balanced performance
 	ColdCacheDir string  // Archive cache for 2s access - long-term storage
+	// WorkDir is the directory files are restored into (defaults to current directory)
+	WorkDir      string
+	// Progress receives one line per restored file when set (nil keeps restores quiet)
+	Progress     io.Writer
+	// Journal records completed files when set, so an interrupted restore can resume
+	Journal      *Journal
+	// ReadOrder overrides the configured tier probe order when set (see DefaultReadOrder)
+	ReadOrder    []string
+	// Filter restores only files of the listed types and sizes when set
+	Filter       *FileFilter
+	// RunHooks runs pre-restore hooks; only 'dgit restore' sets it, so scratch restores (verify, previews, share links) skip them
+	RunHooks     bool
+	// Lock is the repository lock the caller already holds; nil makes RestoreFilesFromCommit take its own
+	Lock         *repolock.Lock
+	// trashMu serializes trash index updates from parallel extraction workers
+	trashMu      sync.Mutex
+	// limits bound extraction workers and decompression memory (performance.max_*)
+	limits       perf.Limits
 }
 
 // NewRestoreManager creates a new ultra-fast restore manager with cache awareness
@@ -39,6 +77,7 @@ func NewRestoreManager(dgitDir string) *RestoreManager {
 		HotCacheDir:  filepath.Join(dgitDir, "cache", "hot"),    // 0.2s ultra-fast access
 		WarmCacheDir: filepath.Join(dgitDir, "cache", "warm"),   // 0.5s balanced access
 		ColdCacheDir: filepath.Join(dgitDir, "cache", "cold"),   // 2s archive access
+		limits:       perf.Load(dgitDir),
 	}
 }
 
@@ -57,12 +96,21 @@ type RestoreResult struct {
 	CacheHitLevel    string        // "hot", "warm", "cold", "miss" - cache performance tracking
 	SpeedImprovement float64       // Multiplier vs traditional restoration methods
 	DataTransferred  int64         // Bytes actually read from storage for efficiency analysis
+	ProbeOrder       []string      // Tier probe order this restore used
+	FilteredFiles    int           // Files left out by the type and size filters (also in SkippedFiles)
+	DeltaPolicy      *DeltaDecision // How the delta depth policy treated a delta version; nil otherwise
 }
 
 // RestoreFilesFromCommit restores files using ultra-fast cache-optimized strategies
 // Intelligently selects fastest available restoration method based on cache availability
 func (rm *RestoreManager) RestoreFilesFromCommit(commitHashOrVersion string, filesToRestore []string, targetCommit interface{}) error {
 	startTime := time.Now()
+	defer iosched.BeginForeground(rm.DgitDir, "restore")()
+	repoLock, err := repolock.Reuse(rm.Lock, rm.DgitDir, "restore", repolock.DefaultWait)
+	if err != nil {
+		return err
+	}
+	defer repoLock.Release()
 	
 	// Parse commit reference (supports both hash and version formats)
 	version, err := rm.parseCommitReference(commitHashOrVersion)
@@ -79,11 +127,45 @@ func (rm *RestoreManager) RestoreFilesFromCommit(commitHashOrVersion string, fil
 		return fmt.Errorf("failed to load commit data: %w", err)
 	}
 	
+	// Type and size filters narrow the targets; the files they exclude end up in SkippedFiles
+	var filtered []string
+	if rm.Filter != nil {
+		var kept []string
+		kept, filtered = rm.FilterTargets(commit, filesToRestore)
+		if len(kept) == 0 {
+			return fmt.Errorf("no files in v%d match the type and size filters", version)
+		}
+		filesToRestore = kept
+	}
+
+	// pre-restore hooks see the files about to be written; a non-zero exit aborts the restore
+	if rm.RunHooks {
+		restoring, _ := rm.FilterTargets(commit, filesToRestore)
+		if err := hooks.Run(rm.DgitDir, HookPreRestore, map[string]string{
+			"DGIT_VERSION": strconv.Itoa(commit.Version),
+			"DGIT_HASH":    commit.Hash,
+			"DGIT_MESSAGE": commit.Message,
+			"DGIT_FILES":   strings.Join(restoring, "\n"),
+		}); err != nil {
+			return err
+		}
+	}
+	
 	// Choose optimal ultra-fast restoration method based on cache availability
 	result, err := rm.performUltraFastRestore(commit, filesToRestore, version)
 	if err != nil {
 		return err
 	}
+	result.FilteredFiles = len(filtered)
+
+	// Reads from a cache tier count towards the eviction policy; frequently restored versions are promoted to hot
+	if result.RestoreMethod != "object_store" && (result.CacheHitLevel == "hot" || result.CacheHitLevel == "warm" || result.CacheHitLevel == "cold") {
+		if promoted, err := cache.NewCacheManager(rm.DgitDir).RecordAccess(version); err != nil {
+			fmt.Printf("Warning: cache access not recorded: %v\n", err)
+		} else if promoted {
+			fmt.Printf("Promoted v%d to the hot cache\n", version)
+		}
+	}
 	
 	// Calculate comprehensive performance metrics
 	result.RestorationTime = time.Since(startTime)
@@ -96,7 +178,7 @@ func (rm *RestoreManager) RestoreFilesFromCommit(commitHashOrVersion string, fil
 }
 
 // performUltraFastRestore intelligently chooses the fastest available restoration method
-// Priority: Hot Cache → Warm Cache → Smart Delta → Cold Cache → Legacy
+// Tiers are probed in the read order; the default is Hot Cache → Warm Cache → Smart Delta → Cold Cache → Legacy
 func (rm *RestoreManager) performUltraFastRestore(commit *log.Commit, filesToRestore []string, version int) (*RestoreResult, error) {
 	result := &RestoreResult{
 		SourceVersion:    commit.Version,
@@ -106,62 +188,100 @@ func (rm *RestoreManager) performUltraFastRestore(commit *log.Commit, filesToRes
 		ErrorFiles:       make(map[string]error),
 	}
 	
-	// Priority 1: Hot Cache (LZ4) - 0.2s ultra-fast access!
-	if hotCacheResult := rm.tryHotCacheRestore(commit, filesToRestore, result); hotCacheResult != nil {
-		return hotCacheResult, nil
-	}
-	
-	// Priority 2: Warm Cache (Zstd) - 0.5s balanced access
-	if warmCacheResult := rm.tryWarmCacheRestore(commit, filesToRestore, result); warmCacheResult != nil {
-		return warmCacheResult, nil
-	}
-	
-	// Priority 3: Smart Delta Reconstruction for design files
-	if commit.CompressionInfo != nil {
-		switch commit.CompressionInfo.Strategy {
-		case "psd_smart_delta":
-			fmt.Println("Using smart PSD delta restoration...")
-			result.RestoreMethod = "smart_delta"
-			result.CacheHitLevel = "smart"
-			return rm.restoreFromSmartDelta(commit, filesToRestore, result)
-		case "design_smart_delta":
-			fmt.Println("Using smart design delta restoration...")
-			result.RestoreMethod = "smart_delta"
-			result.CacheHitLevel = "smart"
-			return rm.restoreFromSmartDelta(commit, filesToRestore, result)
-		case "bsdiff", "xdelta3":
-			fmt.Println("Using optimized delta chain restoration...")
-			result.RestoreMethod = "delta_chain"
-			result.CacheHitLevel = "miss"
-			return rm.restoreFromOptimizedDeltaChain(version, filesToRestore, result)
-		case "zip":
-			fmt.Println("Using direct ZIP restoration...")
-			result.RestoreMethod = "zip"
-			result.CacheHitLevel = "miss"
-			return rm.restoreFromZip(commit.CompressionInfo.OutputFile, filesToRestore, result)
-		}
+	// Object store commits reference shared blobs rather than a per-version snapshot in a tier
+	if commit.CompressionInfo != nil && commit.CompressionInfo.Strategy == objstore.Strategy {
+		fmt.Println("Using object store - content-addressed blobs...")
+		result.RestoreMethod = "object_store"
+		result.CacheHitLevel = "hot"
+		return result, rm.restoreFromObjectStore(commit, filesToRestore, result)
 	}
-	
-	// Priority 4: Cold Cache/Archive access
-	if coldCacheResult := rm.tryColdCacheRestore(commit, filesToRestore, result); coldCacheResult != nil {
-		return coldCacheResult, nil
+
+	// Probe tiers in the configured order (default: hot → warm → smart → cold → legacy)
+	result.ProbeOrder = rm.readOrder()
+	for _, tier := range result.ProbeOrder {
+		switch tier {
+		case TierHot:
+			// Hot Cache (LZ4) - 0.2s ultra-fast access!
+			if hotCacheResult := rm.tryHotCacheRestore(commit, filesToRestore, result); hotCacheResult != nil {
+				return hotCacheResult, nil
+			}
+		case TierWarm:
+			// Warm Cache (Zstd) - 0.5s balanced access
+			if warmCacheResult := rm.tryWarmCacheRestore(commit, filesToRestore, result); warmCacheResult != nil {
+				return warmCacheResult, nil
+			}
+		case TierSmart:
+			// Smart Delta Reconstruction for design files
+			if commit.CompressionInfo == nil {
+				continue
+			}
+			// Deep or slow delta chains give way to a full snapshot of the same version
+			if deltaStrategies[commit.CompressionInfo.Strategy] {
+				decision := rm.decideDeltaRestore(commit)
+				result.DeltaPolicy = decision
+				if decision.Fallback != "" {
+					fmt.Printf("Delta policy: %s; using a full snapshot instead\n", decision.Reason)
+					if coldCacheResult := rm.tryColdCacheRestore(commit, filesToRestore, result); coldCacheResult != nil {
+						return coldCacheResult, nil
+					}
+					decision.Fallback = ""
+					decision.Reason += "; full snapshot unreadable, replaying anyway"
+				}
+			}
+			switch commit.CompressionInfo.Strategy {
+			case "psd_smart_delta", "psd_smart":
+				fmt.Println("Using smart PSD delta restoration...")
+				result.RestoreMethod = "smart_delta"
+				result.CacheHitLevel = "smart"
+				return rm.restoreFromSmartDelta(commit, filesToRestore, result)
+			case "design_smart_delta":
+				fmt.Println("Using smart design delta restoration...")
+				result.RestoreMethod = "smart_delta"
+				result.CacheHitLevel = "smart"
+				return rm.restoreFromSmartDelta(commit, filesToRestore, result)
+			case "xdelta3":
+				fmt.Println("Using xdelta3 delta restoration...")
+				result.RestoreMethod = "delta_chain"
+				result.CacheHitLevel = "miss"
+				return result, rm.restoreFromXdelta(version, filesToRestore, result)
+			case "bsdiff":
+				fmt.Println("Using optimized delta chain restoration...")
+				result.RestoreMethod = "delta_chain"
+				result.CacheHitLevel = "miss"
+				return rm.restoreFromOptimizedDeltaChain(version, filesToRestore, result)
+			case "zip":
+				fmt.Println("Using direct ZIP restoration...")
+				result.RestoreMethod = "zip"
+				result.CacheHitLevel = "miss"
+				return rm.restoreFromZip(commit.CompressionInfo.OutputFile, filesToRestore, result)
+			}
+		case TierCold:
+			// Cold Cache/Archive access
+			if coldCacheResult := rm.tryColdCacheRestore(commit, filesToRestore, result); coldCacheResult != nil {
+				return coldCacheResult, nil
+			}
+		case TierLegacy:
+			// Legacy ZIP restoration for backward compatibility
+			if commit.SnapshotZip != "" {
+				fmt.Println("Using legacy ZIP restoration...")
+				result.RestoreMethod = "zip"
+				result.CacheHitLevel = "miss"
+				return rm.restoreFromZip(commit.SnapshotZip, filesToRestore, result)
+			}
+		}
 	}
 	
-	// Fallback: Legacy ZIP restoration for backward compatibility
-	if commit.SnapshotZip != "" {
-		fmt.Println("Using legacy ZIP restoration...")
-		result.RestoreMethod = "zip"
-		result.CacheHitLevel = "miss"
-		return rm.restoreFromZip(commit.SnapshotZip, filesToRestore, result)
+	if len(result.ProbeOrder) < len(DefaultReadOrder) {
+		return result, fmt.Errorf("no restoration method available for version %d in read order %s",
+			version, strings.Join(result.ProbeOrder, ", "))
 	}
-	
 	return result, fmt.Errorf("no restoration method available for version %d", version)
 }
 
 // tryHotCacheRestore attempts ultra-fast restoration from LZ4 hot cache (0.2s!)
 // Provides the fastest possible restoration when files are in hot cache
 func (rm *RestoreManager) tryHotCacheRestore(commit *log.Commit, filesToRestore []string, result *RestoreResult) *RestoreResult {
-	if commit.CompressionInfo == nil || commit.CompressionInfo.Strategy != "lz4" {
+	if commit.CompressionInfo == nil || (commit.CompressionInfo.Strategy != "lz4" && commit.CompressionInfo.Strategy != codec.Store) {
 		return nil
 	}
 	
@@ -170,7 +290,11 @@ func (rm *RestoreManager) tryHotCacheRestore(commit *log.Commit, filesToRestore
 		return nil
 	}
 	
-	fmt.Println("Using hot cache (LZ4) - 0.2s access!")
+	if commit.CompressionInfo.Strategy == codec.Store {
+		fmt.Println("Using hot cache (stored) - 0.2s access!")
+	} else {
+		fmt.Println("Using hot cache (LZ4) - 0.2s access!")
+	}
 	result.RestoreMethod = "hot_cache"
 	result.CacheHitLevel = "hot"
 	
@@ -208,7 +332,7 @@ func (rm *RestoreManager) tryWarmCacheRestore(commit *log.Commit, filesToRestore
 func (rm *RestoreManager) tryColdCacheRestore(commit *log.Commit, filesToRestore []string, result *RestoreResult) *RestoreResult {
 	// Check for cold cache archive with maximum compression
 	coldCachePath := filepath.Join(rm.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", commit.Version))
-	if !rm.fileExists(coldCachePath) {
+	if !rm.fileExists(coldCachePath) && !rm.recallArchived(commit.Version) {
 		return nil
 	}
 	
@@ -224,6 +348,26 @@ func (rm *RestoreManager) tryColdCacheRestore(commit *log.Commit, filesToRestore
 	return result
 }
 
+// recallArchived asks the on-recall hook for a version moved off to tape or HSM and waits for it
+func (rm *RestoreManager) recallArchived(version int) bool {
+	archiveManager := archive.NewArchiveManager(rm.DgitDir)
+	record, ok := archiveManager.Get(version)
+	if !ok || !record.Offline {
+		return false
+	}
+	fmt.Printf("v%d is archived offline; recalling it from external storage...\n", version)
+	_, err := archiveManager.Recall(version, func(p archive.RecallProgress) {
+		fmt.Printf("\r  waiting %s: %.0f%% of the archived blob is back", p.Waited.Round(time.Second),
+			float64(p.Arrived)*100/float64(max(p.Size, 1)))
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("Recall failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
 // extractFromLZ4Cache extracts files from LZ4 hot cache with 0.2s performance
 // Optimized for maximum speed with streamlined decompression
 func (rm *RestoreManager) extractFromLZ4Cache(lz4Path string, filesToRestore []string, result *RestoreResult) error {
@@ -232,12 +376,11 @@ func (rm *RestoreManager) extractFromLZ4Cache(lz4Path string, filesToRestore []s
 	// Load commit metadata for original file information
 	logManager := log.NewLogManager(rm.DgitDir)
 	
-	// Extract version number from LZ4 filename (e.g., v1.lz4 → 1)
+	// Extract version number from the hot cache filename (e.g., v1.lz4 or v1.store → 1)
 	fileName := filepath.Base(lz4Path)
-	versionStr := strings.TrimSuffix(strings.TrimPrefix(fileName, "v"), ".lz4")
-	version, err := strconv.Atoi(versionStr)
+	version, err := versionFromObjectName(fileName)
 	if err != nil {
-		return fmt.Errorf("failed to parse version from filename %s: %w", fileName, err)
+		return err
 	}
 	
 	// Get comprehensive commit metadata
@@ -246,26 +389,193 @@ func (rm *RestoreManager) extractFromLZ4Cache(lz4Path string, filesToRestore []s
 		return fmt.Errorf("failed to load commit v%d: %w", version, err)
 	}
 	
-	// Open LZ4 file for ultra-fast decompression
-	file, err := os.Open(lz4Path)
+	// Open the hot cache object with the codec that wrote it
+	lz4Reader, err := rm.openHotObject(lz4Path)
 	if err != nil {
-		return fmt.Errorf("failed to open LZ4 cache: %w", err)
+		return fmt.Errorf("failed to open hot cache: %w", err)
 	}
-	defer file.Close()
-	
-	// Create LZ4 reader for streaming decompression
-	lz4Reader := lz4.NewReader(file)
+	defer lz4Reader.Close()
 	
 	// Read all decompressed data efficiently
-	decompressedData, err := io.ReadAll(lz4Reader)
+	decompressedData, err := rm.limits.ReadAll(lz4Reader)
 	if err != nil {
-		return fmt.Errorf("failed to decompress LZ4 data: %w", err)
+		return fmt.Errorf("failed to decompress hot cache data: %w", err)
+	}
+	
+	// Framed containers name every file; older objects are text-framed or a single headerless file
+	if codec.IsFramed(decompressedData) {
+		return rm.restoreFramedData(decompressedData, filesToRestore, result)
+	}
+	if bytes.HasPrefix(decompressedData, []byte(codec.TextFramedHeader)) {
+		return rm.extractFilesFromStream(bytes.NewReader(decompressedData), filesToRestore, result, lz4Path)
 	}
 	
+	return rm.restoreHeaderlessData(commit, decompressedData, filesToRestore, result)
+}
+
+// restoreFramedData writes the files of a framed snapshot stream, verifying each file's checksum
+func (rm *RestoreManager) restoreFramedData(decompressedData []byte, filesToRestore []string, result *RestoreResult) error {
 	result.DataTransferred = int64(len(decompressedData))
 	
-	// Get current working directory for file restoration
-	currentWorkDir, err := os.Getwd()
+	currentWorkDir, err := rm.getWorkDir()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	normalizedTargets := make([]string, len(filesToRestore))
+	for i, target := range filesToRestore {
+		normalizedTargets[i] = filepath.Clean(strings.ReplaceAll(target, "\\", "/"))
+	}
+	
+	err = codec.EachFrame(bytes.NewReader(decompressedData), func(header *codec.FrameHeader, data io.Reader) error {
+		header.Path = rm.storedPath(header.Path)
+		if len(filesToRestore) > 0 && !rm.shouldRestoreFile(header.Path, normalizedTargets) {
+			result.SkippedFiles = append(result.SkippedFiles, header.Path)
+			return nil
+		}
+		fileData, err := io.ReadAll(data)
+		if err != nil {
+			return err // Checksum failures fail the whole object so the next tier is tried
+		}
+		targetPath, err := workTreePath(currentWorkDir, header.Path)
+		if err == nil {
+			err = rm.createFileFromData(targetPath, fileData)
+		}
+		if err != nil {
+			result.ErrorFiles[header.Path] = err
+			return nil
+		}
+		if header.Mode != 0 {
+			os.Chmod(targetPath, header.Mode)
+		}
+		result.RestoredFiles = append(result.RestoredFiles, header.Path)
+		fmt.Printf("Restored %s (%d bytes)\n", header.Path, len(fileData))
+		if rm.Journal != nil {
+			rm.Journal.complete(header.Path, crc32.ChecksumIEEE(fileData))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("corrupt snapshot: %w", err)
+	}
+	
+	result.TotalFilesCount = len(result.RestoredFiles) + len(result.SkippedFiles) + len(result.ErrorFiles)
+	return nil
+}
+
+// restoreFromObjectStore writes each file of an object store commit from its blob
+// Every blob is checked against its content hash; a corrupt or missing blob fails only that file
+// Blobs that left the store when the version was archived are read from its cold blob
+func (rm *RestoreManager) restoreFromObjectStore(commit *log.Commit, filesToRestore []string, result *RestoreResult) error {
+	currentWorkDir, err := rm.getWorkDir()
+	if err != nil {
+		return fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	normalizedTargets := make([]string, len(filesToRestore))
+	for i, target := range filesToRestore {
+		normalizedTargets[i] = filepath.Clean(strings.ReplaceAll(target, "\\", "/"))
+	}
+	paths := make([]string, 0, len(commit.Blobs))
+	for path := range commit.Blobs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	store := objstore.NewObjectStore(rm.DgitDir)
+	var cold map[string][]byte
+	var coldErr error
+	coldLoaded := false
+	for _, path := range paths {
+		if len(filesToRestore) > 0 && !rm.shouldRestoreFile(path, normalizedTargets) {
+			result.SkippedFiles = append(result.SkippedFiles, path)
+			continue
+		}
+		ref := commit.Blobs[path]
+		fileData, err := readBlob(store, ref.Hash)
+		if err != nil && !store.Has(ref.Hash) {
+			if !coldLoaded {
+				cold, coldErr = rm.archivedBlobs(commit.Version)
+				coldLoaded = true
+			}
+			if data, ok := cold[path]; ok {
+				fileData, err = data, nil
+			} else if coldErr != nil {
+				err = coldErr
+			}
+		}
+		if err != nil {
+			result.ErrorFiles[path] = err
+			continue
+		}
+		result.DataTransferred += int64(len(fileData))
+		targetPath, err := workTreePath(currentWorkDir, rm.storedPath(path))
+		if err == nil {
+			err = rm.createFileFromData(targetPath, fileData)
+		}
+		if err != nil {
+			result.ErrorFiles[path] = err
+			continue
+		}
+		if ref.Mode != 0 {
+			os.Chmod(targetPath, ref.Mode)
+		}
+		result.RestoredFiles = append(result.RestoredFiles, path)
+		fmt.Printf("Restored %s (%d bytes)\n", path, len(fileData))
+		if rm.Journal != nil {
+			rm.Journal.complete(path, crc32.ChecksumIEEE(fileData))
+		}
+	}
+
+	result.TotalFilesCount = len(result.RestoredFiles) + len(result.SkippedFiles) + len(result.ErrorFiles)
+	if len(result.RestoredFiles) == 0 && len(result.ErrorFiles) > 0 {
+		return fmt.Errorf("no files could be restored from the object store (%d failed)", len(result.ErrorFiles))
+	}
+	return nil
+}
+
+// archivedBlobs reads the files an archived object store version packed into its cold blob,
+// recalling the blob first when it is offline; nil when the version is not archived
+func (rm *RestoreManager) archivedBlobs(version int) (map[string][]byte, error) {
+	if _, ok := archive.NewArchiveManager(rm.DgitDir).Get(version); !ok {
+		return nil, nil
+	}
+	coldPath := filepath.Join(rm.ColdCacheDir, fmt.Sprintf("v%d.archive.zstd", version))
+	if !rm.fileExists(coldPath) && !rm.recallArchived(version) {
+		return nil, fmt.Errorf("cold blob of v%d is not available", version)
+	}
+	fmt.Printf("Using cold cache (Archive) for blobs archived with v%d...\n", version)
+	stream, err := rm.openHotObject(coldPath)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	files := make(map[string][]byte)
+	err = codec.EachFrame(stream, func(header *codec.FrameHeader, data io.Reader) error {
+		fileData, err := io.ReadAll(data)
+		files[header.Path] = fileData
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("corrupt cold blob of v%d: %w", version, err)
+	}
+	return files, nil
+}
+
+// readBlob reads and verifies a blob's full content
+func readBlob(store *objstore.ObjectStore, hash string) ([]byte, error) {
+	reader, err := store.Open(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// restoreHeaderlessData writes a headerless snapshot stream back to the files named in the commit metadata
+func (rm *RestoreManager) restoreHeaderlessData(commit *log.Commit, decompressedData []byte, filesToRestore []string, result *RestoreResult) error {
+	result.DataTransferred = int64(len(decompressedData))
+	
+	// Get target working directory for file restoration
+	currentWorkDir, err := rm.getWorkDir()
 	if err != nil {
 		return fmt.Errorf("failed to get current working directory: %w", err)
 	}
@@ -273,6 +583,7 @@ func (rm *RestoreManager) extractFromLZ4Cache(lz4Path string, filesToRestore []s
 	// Currently handles single file per commit - TODO: extend for multiple files
 	// Find the staged file from commit metadata
 	for fileName := range commit.Metadata {
+		fileName = rm.storedPath(fileName)
 		// Check if this file should be restored based on user request
 		if len(filesToRestore) > 0 {
 			shouldRestore := false
@@ -289,14 +600,20 @@ func (rm *RestoreManager) extractFromLZ4Cache(lz4Path string, filesToRestore []s
 		}
 		
 		// Create target file path in working directory
-		targetPath := filepath.Join(currentWorkDir, fileName)
+		targetPath, err := workTreePath(currentWorkDir, fileName)
 		
 		// Create file from decompressed data
-		if err := rm.createFileFromData(targetPath, decompressedData); err != nil {
+		if err == nil {
+			err = rm.createFileFromData(targetPath, decompressedData)
+		}
+		if err != nil {
 			result.ErrorFiles[fileName] = err
 		} else {
 			result.RestoredFiles = append(result.RestoredFiles, fileName)
 			fmt.Printf("Restored %s (%d bytes)\n", fileName, len(decompressedData))
+			if rm.Journal != nil {
+				rm.Journal.complete(fileName, crc32.ChecksumIEEE(decompressedData))
+			}
 		}
 		
 		// Currently handle only single file per commit
@@ -318,7 +635,7 @@ func (rm *RestoreManager) extractFromZstdCache(zstdPath string, filesToRestore [
 	defer file.Close()
 	
 	// Create Zstd reader for efficient decompression
-	zstdReader, err := zstd.NewReader(file)
+	zstdReader, err := zstd.NewReader(file, rm.limits.ZstdOptions()...)
 	if err != nil {
 		return fmt.Errorf("failed to create Zstd reader: %w", err)
 	}
@@ -339,15 +656,33 @@ func (rm *RestoreManager) extractFromColdArchive(archivePath string, filesToRest
 // Handles structured stream format with file headers and data sections
 func (rm *RestoreManager) extractFilesFromStream(reader io.Reader, filesToRestore []string, result *RestoreResult, sourcePath string) error {
 	// Read entire stream for processing
-	data, err := io.ReadAll(reader)
+	data, err := rm.limits.ReadAll(reader)
 	if err != nil {
 		return fmt.Errorf("failed to read stream: %w", err)
 	}
 	
+	// Warm copies of framed hot cache objects are framed too
+	if codec.IsFramed(data) {
+		return rm.restoreFramedData(data, filesToRestore, result)
+	}
+	
+	// Streams rewritten by older versions of the recompress task are headerless
+	if !bytes.HasPrefix(data, []byte(codec.TextFramedHeader)) {
+		version, err := versionFromObjectName(filepath.Base(sourcePath))
+		if err != nil {
+			return err
+		}
+		commit, err := log.NewLogManager(rm.DgitDir).GetCommit(version)
+		if err != nil {
+			return fmt.Errorf("failed to load commit v%d: %w", version, err)
+		}
+		return rm.restoreHeaderlessData(commit, data, filesToRestore, result)
+	}
+	
 	result.DataTransferred = int64(len(data))
 	
-	// Get current working directory for file restoration
-	currentWorkDir, err := os.Getwd()
+	// Get target working directory for file restoration
+	currentWorkDir, err := rm.getWorkDir()
 	if err != nil {
 		return fmt.Errorf("failed to get current working directory: %w", err)
 	}
@@ -384,7 +719,7 @@ func (rm *RestoreManager) extractFilesFromStream(reader io.Reader, filesToRestor
 			continue
 		}
 		
-		filePath := parts[1]
+		filePath := rm.storedPath(parts[1])
 		fileSize := rm.parseInt64(parts[2])
 		if fileSize <= 0 {
 			pos = headerEnd + 1
@@ -411,11 +746,17 @@ func (rm *RestoreManager) extractFilesFromStream(reader io.Reader, filesToRestor
 		fileData := data[fileDataStart:fileDataEnd]
 		
 		// Create target file in working directory
-		targetPath := filepath.Join(currentWorkDir, filePath)
-		if err := rm.createFileFromData(targetPath, fileData); err != nil {
+		targetPath, err := workTreePath(currentWorkDir, filePath)
+		if err == nil {
+			err = rm.createFileFromData(targetPath, fileData)
+		}
+		if err != nil {
 			result.ErrorFiles[filePath] = err
 		} else {
 			result.RestoredFiles = append(result.RestoredFiles, filePath)
+			if rm.Journal != nil {
+				rm.Journal.complete(filePath, crc32.ChecksumIEEE(fileData))
+			}
 		}
 		
 		pos = fileDataEnd
@@ -432,13 +773,18 @@ func (rm *RestoreManager) createFileFromData(filePath string, data []byte) error
 	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
 	}
+
+	// Keep the working copy recoverable if it is about to change
+	if err := rm.trashIfDifferent(filePath, crc32.ChecksumIEEE(data)); err != nil {
+		return err
+	}
 	
 	// Create and write file atomically
 	return os.WriteFile(filePath, data, 0644)
 }
 
 // restoreFromSmartDelta restores from smart delta compression (PSD/Design optimized)
-// Handles design-specific delta formats with metadata awareness
+// The delta carries the changed PSD in full; every other file comes from the base version
 func (rm *RestoreManager) restoreFromSmartDelta(commit *log.Commit, filesToRestore []string, result *RestoreResult) (*RestoreResult, error) {
 	deltaPath := filepath.Join(rm.HotCacheDir, commit.CompressionInfo.OutputFile)
 	
@@ -446,9 +792,32 @@ func (rm *RestoreManager) restoreFromSmartDelta(commit *log.Commit, filesToResto
 		return result, fmt.Errorf("smart delta file not found: %s", commit.CompressionInfo.OutputFile)
 	}
 	
-	// TODO: Implement comprehensive smart delta restoration
-	// For now, return error indicating future implementation
-	return result, fmt.Errorf("smart delta restoration not yet fully implemented")
+	// Files the base had but this version dropped must not come back
+	targets := filesToRestore
+	if len(targets) == 0 {
+		for path := range commit.Metadata {
+			targets = append(targets, path)
+		}
+		sort.Strings(targets)
+	}
+	
+	if _, err := rm.restoreFromOptimizedDeltaChain(commit.Version, targets, result); err != nil {
+		return result, err
+	}
+	
+	// Files staged alongside the PSD that the base did not have were never written to the delta
+	if len(filesToRestore) == 0 {
+		restored := make(map[string]bool, len(result.RestoredFiles))
+		for _, path := range result.RestoredFiles {
+			restored[path] = true
+		}
+		for _, path := range targets {
+			if _, failed := result.ErrorFiles[path]; !restored[path] && !failed {
+				result.ErrorFiles[path] = fmt.Errorf("not stored in smart delta %s", commit.CompressionInfo.OutputFile)
+			}
+		}
+	}
+	return result, nil
 }
 
 // restoreFromOptimizedDeltaChain restores from optimized delta chain
@@ -474,33 +843,16 @@ func (rm *RestoreManager) restoreFromOptimizedDeltaChain(targetVersion int, file
 }
 
 // findOptimizedRestorationPath finds fastest restoration path using cache hierarchy
-// Prioritizes hot cache → warm cache → legacy objects for optimal performance
+// Prioritizes cached snapshots (in read order) → legacy objects for optimal performance
 func (rm *RestoreManager) findOptimizedRestorationPath(targetVersion int) ([]RestorationStep, error) {
 	var path []RestorationStep
 	currentVersion := targetVersion
+	order := rm.readOrder()
 	
 	// Work backwards with cache optimization prioritization
 	for currentVersion > 0 {
-		// Priority 1: Check hot cache first (LZ4) for instant access
-		hotPath := filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d.lz4", currentVersion))
-		if rm.fileExists(hotPath) {
-			step := RestorationStep{
-				Type:    "lz4",
-				File:    hotPath,
-				Version: currentVersion,
-			}
-			path = append([]RestorationStep{step}, path...)
-			break
-		}
-		
-		// Priority 2: Check warm cache (Zstd) for balanced performance
-		warmPath := filepath.Join(rm.WarmCacheDir, fmt.Sprintf("v%d.zstd", currentVersion))
-		if rm.fileExists(warmPath) {
-			step := RestorationStep{
-				Type:    "zstd",
-				File:    warmPath,
-				Version: currentVersion,
-			}
+		// Cached full snapshots end the chain; hot and warm are checked in read order
+		if step, ok := rm.cachedSnapshotStep(order, currentVersion); ok {
 			path = append([]RestorationStep{step}, path...)
 			break
 		}
@@ -530,19 +882,29 @@ func (rm *RestoreManager) findOptimizedRestorationPath(targetVersion int) ([]Res
 			continue
 		}
 		
-		// Check for smart delta files (design-specific)
-		smartDeltaPath := filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d_from_v%d.smart_psd_delta", currentVersion, currentVersion-1))
-		if rm.fileExists(smartDeltaPath) {
+		// Check for smart delta files (design-specific), which may build on any earlier version
+		if smartDeltaPath, baseVersion, ok := rm.findDelta(rm.HotCacheDir, ".psd_delta", currentVersion); ok {
 			step := RestorationStep{
 				Type:    "smart_delta",
 				File:    smartDeltaPath,
 				Version: currentVersion,
 			}
 			path = append([]RestorationStep{step}, path...)
-			currentVersion--
+			currentVersion = baseVersion
 			continue
 		}
 		
+		// xdelta3 deltas rebuild the whole snapshot from their own base, so they end the chain
+		if xdeltaPath, _, ok := rm.findDelta(rm.DeltaDir, vcdiff.Ext, currentVersion); ok {
+			step := RestorationStep{
+				Type:    "xdelta3",
+				File:    xdeltaPath,
+				Version: currentVersion,
+			}
+			path = append([]RestorationStep{step}, path...)
+			break
+		}
+		
 		return nil, fmt.Errorf("missing restoration data for version %d", currentVersion)
 	}
 	
@@ -553,6 +915,29 @@ func (rm *RestoreManager) findOptimizedRestorationPath(targetVersion int) ([]Res
 	return path, nil
 }
 
+// cachedSnapshotStep finds a cached full snapshot of a version in the hot or warm tier
+// Tiers are checked in read order and tiers left out of it are skipped
+func (rm *RestoreManager) cachedSnapshotStep(order []string, version int) (RestorationStep, bool) {
+	candidates := []RestorationStep{
+		{Type: "lz4", File: filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d.lz4", version)), Version: version},
+		{Type: "zstd", File: filepath.Join(rm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version)), Version: version},
+	}
+	tiers := []string{TierHot, TierWarm}
+	if storePath := filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d.store", version)); rm.fileExists(storePath) {
+		candidates[0] = RestorationStep{Type: "lz4", File: storePath, Version: version}
+	}
+	if prefers(order, TierWarm, TierHot) {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+		tiers[0], tiers[1] = tiers[1], tiers[0]
+	}
+	for i, step := range candidates {
+		if allows(order, tiers[i]) && rm.fileExists(step.File) {
+			return step, true
+		}
+	}
+	return RestorationStep{}, false
+}
+
 // executeOptimizedRestorationPath executes restoration plan with cache optimization
 // Handles conversion between different cache formats and delta application
 func (rm *RestoreManager) executeOptimizedRestorationPath(path []RestorationStep) (string, error) {
@@ -575,6 +960,14 @@ func (rm *RestoreManager) executeOptimizedRestorationPath(path []RestorationStep
 		if err := rm.copyFile(baseStep.File, tempFile); err != nil {
 			return "", err
 		}
+	case "xdelta3":
+		stream, err := rm.SnapshotStream(baseStep.Version)
+		if err != nil {
+			return "", err
+		}
+		if err := rm.writeStreamZip(stream, tempFile); err != nil {
+			return "", err
+		}
 	default:
 		return "", fmt.Errorf("unsupported base file type: %s", baseStep.Type)
 	}
@@ -593,8 +986,6 @@ func (rm *RestoreManager) executeOptimizedRestorationPath(path []RestorationStep
 			if err := rm.applySmartDelta(tempFile, step.File, nextTempFile); err != nil {
 				return "", fmt.Errorf("failed to apply smart delta for v%d: %w", step.Version, err)
 			}
-		case "xdelta3":
-			return "", fmt.Errorf("xdelta3 restoration not yet implemented")
 		default:
 			return "", fmt.Errorf("unknown restoration step type: %s", step.Type)
 		}
@@ -610,15 +1001,12 @@ func (rm *RestoreManager) executeOptimizedRestorationPath(path []RestorationStep
 // convertLZ4ToZip converts LZ4 cache file to ZIP format for processing
 // Handles transparent conversion from hot cache to standard ZIP format
 func (rm *RestoreManager) convertLZ4ToZip(lz4Path, zipPath string) error {
-	// Open LZ4 file for reading
-	lz4File, err := os.Open(lz4Path)
+	// Open the hot cache object (LZ4 or store) for reading
+	lz4Reader, err := rm.openHotObject(lz4Path)
 	if err != nil {
 		return err
 	}
-	defer lz4File.Close()
-	
-	// Create LZ4 reader for decompression
-	lz4Reader := lz4.NewReader(lz4File)
+	defer lz4Reader.Close()
 	
 	// Create ZIP file for output
 	zipFile, err := os.Create(zipPath)
@@ -634,6 +1022,49 @@ func (rm *RestoreManager) convertLZ4ToZip(lz4Path, zipPath string) error {
 	return rm.convertStreamToZip(lz4Reader, zipWriter)
 }
 
+// versionFromObjectName parses the version from a storage object name (v3.lz4, v3.archive.zstd → 3)
+func versionFromObjectName(name string) (int, error) {
+	versionStr := strings.TrimPrefix(name, "v")
+	if i := strings.Index(versionStr, "."); i >= 0 {
+		versionStr = versionStr[:i]
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse version from filename %s: %w", name, err)
+	}
+	return version, nil
+}
+
+// openHotObject opens a hot cache object with the codec matching its extension
+// Store objects verify their block checksums as they are read
+func (rm *RestoreManager) openHotObject(path string) (io.ReadCloser, error) {
+	c, ok := codec.ForObject(path)
+	if !ok {
+		return nil, fmt.Errorf("no codec for %s", filepath.Base(path))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := c.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &objectReadCloser{reader, file}, nil
+}
+
+// objectReadCloser closes both the codec reader and the object file
+type objectReadCloser struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (r *objectReadCloser) Close() error {
+	r.ReadCloser.Close()
+	return r.file.Close()
+}
+
 // convertZstdToZip converts Zstd cache file to ZIP format for processing
 // Handles transparent conversion from warm cache to standard ZIP format
 func (rm *RestoreManager) convertZstdToZip(zstdPath, zipPath string) error {
@@ -645,7 +1076,7 @@ func (rm *RestoreManager) convertZstdToZip(zstdPath, zipPath string) error {
 	defer zstdFile.Close()
 	
 	// Create Zstd reader for decompression
-	zstdReader, err := zstd.NewReader(zstdFile)
+	zstdReader, err := zstd.NewReader(zstdFile, rm.limits.ZstdOptions()...)
 	if err != nil {
 		return err
 	}
@@ -674,6 +1105,17 @@ func (rm *RestoreManager) convertStreamToZip(reader io.Reader, zipWriter *zip.Wr
 		return err
 	}
 	
+	if codec.IsFramed(data) {
+		return codec.EachFrame(bytes.NewReader(data), func(header *codec.FrameHeader, fileData io.Reader) error {
+			zipEntry, err := zipWriter.Create(header.Path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(zipEntry, fileData)
+			return err
+		})
+	}
+	
 	// Parse stream and create ZIP entries
 	content := string(data)
 	pos := 0
@@ -699,7 +1141,7 @@ func (rm *RestoreManager) convertStreamToZip(reader io.Reader, zipWriter *zip.Wr
 			continue
 		}
 		
-		filePath := parts[1]
+		filePath := rm.storedPath(parts[1])
 		fileSize := rm.parseInt64(parts[2])
 		if fileSize <= 0 {
 			pos = headerEnd + 1
@@ -735,12 +1177,192 @@ func (rm *RestoreManager) convertStreamToZip(reader io.Reader, zipWriter *zip.Wr
 	return nil
 }
 
+// smartDeltaHeader is the metadata block at the start of a psd_smart_delta file
+type smartDeltaHeader struct {
+	Type         string `json:"type"`
+	FromVersion  int    `json:"from_version"`
+	ToVersion    int    `json:"to_version"`
+	FilePath     string `json:"file_path"`
+	OriginalSize int64  `json:"original_size"`
+}
+
+// maxXdeltaDepth bounds how many xdelta3 deltas SnapshotStream will follow
+const maxXdeltaDepth = 64
+
+// SnapshotStream returns a version's uncompressed snapshot stream from the hot or warm cache,
+// rebuilding xdelta3 versions from their base
+func (rm *RestoreManager) SnapshotStream(version int) ([]byte, error) {
+	return rm.snapshotStream(version, 0)
+}
+
+// snapshotStream resolves one version of SnapshotStream, following deltas up to maxXdeltaDepth
+func (rm *RestoreManager) snapshotStream(version, depth int) ([]byte, error) {
+	if depth > maxXdeltaDepth {
+		return nil, fmt.Errorf("xdelta3 chain for v%d is longer than %d", version, maxXdeltaDepth)
+	}
+	
+	for _, ext := range []string{".lz4", ".store"} {
+		hotPath := filepath.Join(rm.HotCacheDir, fmt.Sprintf("v%d%s", version, ext))
+		if !rm.fileExists(hotPath) {
+			continue
+		}
+		reader, err := rm.openHotObject(hotPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open hot cache: %w", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	
+	if warmPath := filepath.Join(rm.WarmCacheDir, fmt.Sprintf("v%d.zstd", version)); rm.fileExists(warmPath) {
+		file, err := os.Open(warmPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader, err := zstd.NewReader(file, rm.limits.ZstdOptions()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open warm cache: %w", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	
+	deltaPath, baseVersion, ok := rm.findDelta(rm.DeltaDir, vcdiff.Ext, version)
+	if !ok {
+		return nil, fmt.Errorf("no snapshot data for v%d", version)
+	}
+	base, err := rm.snapshotStream(baseVersion, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	delta, err := os.ReadFile(deltaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta: %w", err)
+	}
+	stream, err := vcdiff.Decode(base, delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s: %w", filepath.Base(deltaPath), err)
+	}
+	return stream, nil
+}
+
+// restoreFromXdelta rebuilds an xdelta3 version's snapshot stream and writes its files
+func (rm *RestoreManager) restoreFromXdelta(version int, filesToRestore []string, result *RestoreResult) error {
+	stream, err := rm.SnapshotStream(version)
+	if err != nil {
+		return err
+	}
+	if !codec.IsFramed(stream) {
+		return fmt.Errorf("v%d delta did not rebuild a framed snapshot", version)
+	}
+	return rm.restoreFramedData(stream, filesToRestore, result)
+}
+
+// writeStreamZip writes an uncompressed snapshot stream out as a ZIP file
+func (rm *RestoreManager) writeStreamZip(stream []byte, zipPath string) error {
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer zipFile.Close()
+	
+	zipWriter := zip.NewWriter(zipFile)
+	defer zipWriter.Close()
+	
+	return rm.convertStreamToZip(bytes.NewReader(stream), zipWriter)
+}
+
+// findDelta locates the delta file with the given extension that produces a version and returns its base version
+func (rm *RestoreManager) findDelta(dir, ext string, version int) (string, int, bool) {
+	matches, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("v%d_from_v*%s", version, ext)))
+	for _, match := range matches {
+		name := strings.TrimSuffix(filepath.Base(match), ext)
+		base, err := strconv.Atoi(name[strings.LastIndex(name, "_from_v")+len("_from_v"):])
+		if err == nil && base > 0 && base < version {
+			return match, base, true
+		}
+	}
+	return "", 0, false
+}
+
+// readSmartDelta parses a smart delta file: "METADATA:<n>\n", n bytes of JSON, "\nDATA:\n", then the LZ4 file data
+func readSmartDelta(deltaFile string) (*smartDeltaHeader, []byte, error) {
+	file, err := os.Open(deltaFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	
+	var metadataLen int
+	if _, err := fmt.Fscanf(reader, "METADATA:%d\n", &metadataLen); err != nil || metadataLen <= 0 {
+		return nil, nil, fmt.Errorf("invalid smart delta header in %s", filepath.Base(deltaFile))
+	}
+	metadata := make([]byte, metadataLen)
+	if _, err := io.ReadFull(reader, metadata); err != nil {
+		return nil, nil, fmt.Errorf("truncated smart delta metadata: %w", err)
+	}
+	var header smartDeltaHeader
+	if err := json.Unmarshal(metadata, &header); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse smart delta metadata: %w", err)
+	}
+	if header.Type != "psd_smart_delta" || header.FilePath == "" {
+		return nil, nil, fmt.Errorf("unsupported smart delta type %q", header.Type)
+	}
+	
+	marker := make([]byte, len("\nDATA:\n"))
+	if _, err := io.ReadFull(reader, marker); err != nil || string(marker) != "\nDATA:\n" {
+		return nil, nil, fmt.Errorf("smart delta %s has no data section", filepath.Base(deltaFile))
+	}
+	data, err := io.ReadAll(lz4.NewReader(reader))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decompress smart delta: %w", err)
+	}
+	if header.OriginalSize > 0 && int64(len(data)) != header.OriginalSize {
+		return nil, nil, fmt.Errorf("smart delta for %s holds %d bytes, expected %d", header.FilePath, len(data), header.OriginalSize)
+	}
+	return &header, data, nil
+}
+
 // applySmartDelta applies smart delta to create new file (design-specific)
-// TODO: Implement comprehensive smart delta application
+// Copies every entry of the base snapshot and replaces the PSD the delta carries
 func (rm *RestoreManager) applySmartDelta(baseFile, deltaFile, newFile string) error {
-	// TODO: Implement smart delta application logic
-	// For now, just copy the base file as placeholder
-	return rm.copyFile(baseFile, newFile)
+	header, data, err := readSmartDelta(deltaFile)
+	if err != nil {
+		return err
+	}
+	
+	base, err := zip.OpenReader(baseFile)
+	if err != nil {
+		return fmt.Errorf("failed to open base snapshot: %w", err)
+	}
+	defer base.Close()
+	
+	out, err := os.Create(newFile)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	zipWriter := zip.NewWriter(out)
+	
+	target := filepath.ToSlash(header.FilePath)
+	for _, f := range base.File {
+		if strings.ReplaceAll(f.Name, "\\", "/") == target {
+			continue
+		}
+		if err := zipWriter.Copy(f); err != nil {
+			return fmt.Errorf("failed to copy %s from base snapshot: %w", f.Name, err)
+		}
+	}
+	entry, err := zipWriter.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(data); err != nil {
+		return err
+	}
+	return zipWriter.Close()
 }
 
 // calculateSpeedImprovement calculates speed improvement based on restore method
@@ -779,7 +1401,7 @@ func (rm *RestoreManager) displayUltraFastRestoreResults(result *RestoreResult,
 		// Show method-specific information with performance metrics
 		switch result.RestoreMethod {
 		case "hot_cache":
-			fmt.Printf("Hot cache (LZ4) restoration - %.1fx faster than traditional!\n", result.SpeedImprovement)
+			fmt.Printf("Hot cache restoration - %.1fx faster than traditional!\n", result.SpeedImprovement)
 			fmt.Printf("Data transferred: %.2f KB from hot cache\n", float64(result.DataTransferred)/1024)
 		case "warm_cache":
 			fmt.Printf("Warm cache (Zstd) restoration - %.1fx faster than traditional!\n", result.SpeedImprovement)
@@ -816,8 +1438,23 @@ func (rm *RestoreManager) displayUltraFastRestoreResults(result *RestoreResult,
 		fmt.Println("No files found matching the specified criteria.")
 	}
 	
+	if policy := result.DeltaPolicy; policy != nil && policy.Exceeded {
+		if policy.Fallback != "" {
+			fmt.Printf("Delta policy: %s; restored from a full snapshot (%s)\n", policy.Reason, policy.Fallback)
+		} else {
+			fmt.Printf("Delta policy: %s\n", policy.Reason)
+		}
+	}
+	
+	if result.FilteredFiles > 0 {
+		fmt.Printf("Skipped %d file(s) outside the type and size filters\n", result.FilteredFiles)
+	}
+	
 	fmt.Printf("\nUltra-fast restoration from commit %s (v%d) completed!\n", commitRef, version)
 	fmt.Printf("Cache performance: %s cache hit\n", result.CacheHitLevel)
+	if len(result.ProbeOrder) > 0 && strings.Join(result.ProbeOrder, ",") != strings.Join(DefaultReadOrder, ",") {
+		fmt.Printf("Read order: %s\n", strings.Join(result.ProbeOrder, " → "))
+	}
 }
 
 // RestorationStep represents a single step in restoration process
@@ -889,6 +1526,57 @@ func (rm *RestoreManager) getFileTypeIndicator(filePath string) string {
 	}
 }
 
+// getWorkDir returns the directory restored files are written into
+// Falls back to the working tree root, which committed paths are relative to, when WorkDir is not set
+func (rm *RestoreManager) getWorkDir() (string, error) {
+	if rm.WorkDir != "" {
+		return rm.WorkDir, nil
+	}
+	dgitDir, err := filepath.Abs(rm.DgitDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(dgitDir), nil
+}
+
+// storedPath maps a path recorded in a commit or snapshot to its repo-relative form
+// Commits made before paths were normalized may record absolute paths
+func (rm *RestoreManager) storedPath(stored string) string {
+	dgitDir, _ := filepath.Abs(rm.DgitDir)
+	path, _ := log.RepoPath(filepath.Dir(dgitDir), stored)
+	return path
+}
+
+// workTreePath resolves a stored path inside the work tree
+// Commit metadata and frame headers from pulled or cloned repositories are untrusted, so absolute
+// paths and paths that climb out of the work tree are rejected rather than written
+func workTreePath(workDir, stored string) (string, error) {
+	clean := path.Clean(filepath.ToSlash(stored))
+	if stored == "" || path.IsAbs(clean) || filepath.IsAbs(stored) || filepath.VolumeName(stored) != "" ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("refusing to restore %q outside the work tree", stored)
+	}
+	return filepath.Join(workDir, filepath.FromSlash(clean)), nil
+}
+
+// trashIfDifferent moves an existing working tree file to the trash before it is overwritten
+// Files with identical content, and restores outside this repository's work tree, are left alone
+func (rm *RestoreManager) trashIfDifferent(targetPath string, newCRC uint32) error {
+	trashManager := trash.NewTrashManager(rm.DgitDir)
+	if !rm.fileExists(targetPath) || !trashManager.Covers(targetPath) {
+		return nil
+	}
+	if fileCRC32(targetPath) == newCRC {
+		return nil
+	}
+	rm.trashMu.Lock()
+	defer rm.trashMu.Unlock()
+	if _, err := trashManager.Trash(targetPath, "restore"); err != nil {
+		return fmt.Errorf("failed to keep a copy of %s: %w", targetPath, err)
+	}
+	return nil
+}
+
 // fileExists checks if a file exists on the filesystem
 // Simple utility function used throughout cache and restoration operations
 func (rm *RestoreManager) fileExists(path string) bool {
@@ -943,8 +1631,8 @@ func (rm *RestoreManager) extractFilesFromZip(zipPath string, filesToRestore []s
 	}
 	defer r.Close()
 
-	// Get current working directory for file restoration
-	currentWorkDir, err := os.Getwd()
+	// Get target working directory for file restoration
+	currentWorkDir, err := rm.getWorkDir()
 	if err != nil {
 		return result, fmt.Errorf("failed to get current working directory: %w", err)
 	}
@@ -956,9 +1644,10 @@ func (rm *RestoreManager) extractFilesFromZip(zipPath string, filesToRestore []s
 	}
 
 	// Process each file in the ZIP archive
+	var jobs []*extractJob
 	for _, f := range r.File {
 		// Normalize file path in ZIP for consistent comparison
-		filePathInZip := strings.ReplaceAll(f.Name, "\\", "/")
+		filePathInZip := rm.storedPath(f.Name)
 		
 		// Check if this file should be restored based on user criteria
 		if len(filesToRestore) > 0 {
@@ -973,19 +1662,85 @@ func (rm *RestoreManager) extractFilesFromZip(zipPath string, filesToRestore []s
 			continue
 		}
 
-		// Restore the individual file
-		if err := rm.restoreFile(f, filePathInZip, currentWorkDir); err != nil {
-			result.ErrorFiles[filePathInZip] = err
-			continue
-		}
-
-		result.RestoredFiles = append(result.RestoredFiles, filePathInZip)
+		jobs = append(jobs, &extractJob{file: f, path: filePathInZip, requested: isExplicitTarget(filePathInZip, normalizedTargets)})
 	}
+
+	// Explicitly named files first, then largest first so big files do not finish last on one worker
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].requested != jobs[j].requested {
+			return jobs[i].requested
+		}
+		return jobs[i].file.UncompressedSize64 > jobs[j].file.UncompressedSize64
+	})
+	rm.runExtractJobs(jobs, currentWorkDir, result)
 	
 	result.TotalFilesCount = len(r.File)
 	return result, nil
 }
 
+// extractJob is one archive entry waiting to be written to the working tree
+type extractJob struct {
+	file      *zip.File
+	path      string
+	requested bool
+}
+
+// runExtractJobs restores archive entries with a pool of workers, reporting each finished file
+// Jobs are started in slice order, so earlier jobs finish first when workers are scarce
+func (rm *RestoreManager) runExtractJobs(jobs []*extractJob, currentWorkDir string, result *RestoreResult) {
+	workers := rm.limits.Workers(len(jobs))
+
+	queue := make(chan *extractJob)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := 0
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				err := rm.restoreFile(job.file, job.path, currentWorkDir)
+				if err == nil && rm.Journal != nil {
+					rm.Journal.complete(job.path, job.file.CRC32)
+				}
+
+				mu.Lock()
+				done++
+				if err != nil {
+					result.ErrorFiles[job.path] = err
+				} else {
+					result.RestoredFiles = append(result.RestoredFiles, job.path)
+				}
+				if rm.Progress != nil && len(jobs) > 1 {
+					status := "ok"
+					if err != nil {
+						status = "failed"
+					}
+					fmt.Fprintf(rm.Progress, "  [%d/%d] %s (%.1f KB) %s\n", done, len(jobs), job.path,
+						float64(job.file.UncompressedSize64)/1024, status)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+	sort.Strings(result.RestoredFiles)
+}
+
+// isExplicitTarget reports whether a path was named directly (full path or file name) rather than via a folder or substring
+func isExplicitTarget(path string, normalizedTargets []string) bool {
+	for _, target := range normalizedTargets {
+		if path == target || filepath.Base(path) == filepath.Base(target) {
+			return true
+		}
+	}
+	return false
+}
+
 // shouldRestoreFile determines if a file should be restored based on target patterns
 // Enhanced pattern matching with multiple matching strategies for user convenience
 func (rm *RestoreManager) shouldRestoreFile(filePathInZip string, normalizedTargets []string) bool {
@@ -1018,13 +1773,21 @@ func (rm *RestoreManager) shouldRestoreFile(filePathInZip string, normalizedTarg
 // Enhanced with better error handling and directory creation
 func (rm *RestoreManager) restoreFile(f *zip.File, filePathInZip, currentWorkDir string) error {
 	// Determine final target path for the restored file
-	targetPath := filepath.Join(currentWorkDir, filePathInZip)
+	targetPath, err := workTreePath(currentWorkDir, filePathInZip)
+	if err != nil {
+		return err
+	}
 
 	// Create target directory structure if it doesn't exist
 	if err := os.MkdirAll(filepath.Dir(targetPath), os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", targetPath, err)
 	}
 
+	// Keep the working copy recoverable if it is about to change
+	if err := rm.trashIfDifferent(targetPath, f.CRC32); err != nil {
+		return err
+	}
+
 	// Open file within ZIP archive
 	rc, err := f.Open()
 	if err != nil {
@@ -0,0 +1,246 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// Review states
+const (
+	StatusOpen   = "open"
+	StatusClosed = "closed"
+)
+
+// Comment is a single message in a review thread
+type Comment struct {
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	File      string    `json:"file,omitempty"` // Optional design file the comment refers to
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Review is a feedback thread attached to a specific commit
+// Each review is stored as its own JSON file so it can travel with the repository
+type Review struct {
+	ID         int        `json:"id"`
+	Version    int        `json:"version"`
+	CommitHash string     `json:"commit_hash"`
+	Title      string     `json:"title"`
+	Author     string     `json:"author"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	Comments   []*Comment `json:"comments"`
+}
+
+// ReviewManager stores review threads under .dgit/reviews
+type ReviewManager struct {
+	DgitDir    string
+	ReviewsDir string
+}
+
+// NewReviewManager creates a new review manager for the given .dgit directory
+func NewReviewManager(dgitDir string) *ReviewManager {
+	return &ReviewManager{
+		DgitDir:    dgitDir,
+		ReviewsDir: filepath.Join(dgitDir, "reviews"),
+	}
+}
+
+// Open starts a new review thread on a commit
+func (rm *ReviewManager) Open(commit *log.Commit, title, author string) (*Review, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("review message cannot be empty")
+	}
+
+	id, err := rm.nextID()
+	if err != nil {
+		return nil, err
+	}
+
+	review := &Review{
+		ID:         id,
+		Version:    commit.Version,
+		CommitHash: commit.Hash,
+		Title:      title,
+		Author:     author,
+		Status:     StatusOpen,
+		CreatedAt:  time.Now(),
+		Comments:   []*Comment{},
+	}
+	if err := rm.save(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Comment appends a comment to an open review
+func (rm *ReviewManager) Comment(id int, author, message, file string) (*Review, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("comment cannot be empty")
+	}
+
+	review, err := rm.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != StatusOpen {
+		return nil, fmt.Errorf("review #%d is closed", id)
+	}
+
+	review.Comments = append(review.Comments, &Comment{
+		Author:    author,
+		Message:   message,
+		File:      file,
+		Timestamp: time.Now(),
+	})
+	if err := rm.save(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Close resolves a review, optionally recording a closing comment
+func (rm *ReviewManager) Close(id int, author, message string) (*Review, error) {
+	review, err := rm.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == StatusClosed {
+		return nil, fmt.Errorf("review #%d is already closed", id)
+	}
+
+	now := time.Now()
+	if strings.TrimSpace(message) != "" {
+		review.Comments = append(review.Comments, &Comment{
+			Author:    author,
+			Message:   message,
+			Timestamp: now,
+		})
+	}
+	review.Status = StatusClosed
+	review.ClosedAt = &now
+
+	if err := rm.save(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// Get loads a single review by ID
+func (rm *ReviewManager) Get(id int) (*Review, error) {
+	data, err := os.ReadFile(rm.reviewPath(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("review #%d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review #%d: %w", id, err)
+	}
+
+	var review Review
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("failed to parse review #%d: %w", id, err)
+	}
+	return &review, nil
+}
+
+// List returns reviews sorted by ID, optionally including closed ones
+func (rm *ReviewManager) List(includeClosed bool) ([]*Review, error) {
+	entries, err := os.ReadDir(rm.ReviewsDir)
+	if os.IsNotExist(err) {
+		return []*Review{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reviews directory: %w", err)
+	}
+
+	var reviews []*Review
+	for _, entry := range entries {
+		id, ok := parseReviewFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		review, err := rm.Get(id)
+		if err != nil {
+			continue // Skip unreadable reviews but keep listing the rest
+		}
+		if review.Status == StatusClosed && !includeClosed {
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].ID < reviews[j].ID })
+	return reviews, nil
+}
+
+// ForVersion returns all reviews attached to a specific version
+func (rm *ReviewManager) ForVersion(version int) ([]*Review, error) {
+	all, err := rm.List(true)
+	if err != nil {
+		return nil, err
+	}
+	var reviews []*Review
+	for _, review := range all {
+		if review.Version == version {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+// nextID returns the next free review ID
+func (rm *ReviewManager) nextID() (int, error) {
+	entries, err := os.ReadDir(rm.ReviewsDir)
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read reviews directory: %w", err)
+	}
+
+	maxID := 0
+	for _, entry := range entries {
+		if id, ok := parseReviewFileName(entry.Name()); ok && id > maxID {
+			maxID = id
+		}
+	}
+	return maxID + 1, nil
+}
+
+// save writes a review to its JSON file
+func (rm *ReviewManager) save(review *Review) error {
+	if err := os.MkdirAll(rm.ReviewsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create reviews directory: %w", err)
+	}
+	data, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal review: %w", err)
+	}
+	if err := os.WriteFile(rm.reviewPath(review.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write review #%d: %w", review.ID, err)
+	}
+	return nil
+}
+
+// reviewPath returns the file path of a review
+func (rm *ReviewManager) reviewPath(id int) string {
+	return filepath.Join(rm.ReviewsDir, fmt.Sprintf("%d.json", id))
+}
+
+// parseReviewFileName extracts the review ID from a file name like "12.json"
+func parseReviewFileName(name string) (int, bool) {
+	if !strings.HasSuffix(name, ".json") {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimSuffix(name, ".json"))
+	return id, err == nil && id > 0
+}
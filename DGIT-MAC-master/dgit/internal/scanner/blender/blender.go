@@ -0,0 +1,303 @@
+package blender
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// BlendInfo contains the metadata read from a Blender .blend file's header and block directory
+type BlendInfo struct {
+	Version         string   // Blender version that saved the file, e.g. "4.2"
+	PointerSize     int      // 4 or 8 bytes
+	BigEndian       bool
+	Compressed      string   // "gzip", "zstd" or "" for uncompressed files
+	SceneCount      int
+	SceneNames      []string
+	ObjectCount     int
+	CollectionCount int
+	CollectionNames []string
+	MeshCount       int
+	MaterialCount   int
+	Width           int      // Render resolution of the first scene, when the SDNA describes it
+	Height          int
+}
+
+// Block codes of the ID blocks BlendInfo counts
+const (
+	codeScene      = "SC"
+	codeObject     = "OB"
+	codeCollection = "GR"
+	codeMesh       = "ME"
+	codeMaterial   = "MA"
+	codeDNA        = "DNA1"
+	codeEnd        = "ENDB"
+)
+
+// keepBytes is how much of an object or collection block is kept to read its ID name
+// Scene blocks are kept whole so the render resolution can be read
+const keepBytes = 512
+
+// maxSceneBlock bounds a kept scene block
+const maxSceneBlock = 16 << 20
+
+// maxDNABlock bounds the SDNA block; real files describe their structs in well under 1 MB
+const maxDNABlock = 64 << 20
+
+// header is the decoded file header
+type header struct {
+	pointerSize int
+	order       binary.ByteOrder
+	version     int
+	large       bool // Blender 5.0+ header with 64-bit block lengths
+}
+
+// block is one block of the directory, with its data when kept
+type block struct {
+	code string
+	data []byte
+}
+
+// GetBlendInfo reads a .blend file: the header gives version, pointer size and endianness,
+// the block directory gives scene, object and collection counts, and the SDNA block at the end
+// describes the structs needed to read names and render resolution
+func GetBlendInfo(filePath string) (*BlendInfo, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Blender file: %w", err)
+	}
+	defer file.Close()
+
+	reader, compressed, closeReader, err := openBlend(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader()
+	info := &BlendInfo{Compressed: compressed}
+
+	// Uncompressed files bound every block length by what is left of the file
+	var fileSize int64 = -1
+	if stat, err := file.Stat(); err == nil && compressed == "" {
+		fileSize = stat.Size()
+	}
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	info.PointerSize = h.pointerSize
+	info.BigEndian = h.order == binary.BigEndian
+	info.Version = fmt.Sprintf("%d.%d", h.version/100, h.version%100)
+
+	var kept []*block
+	var dna []byte
+	for {
+		b, size, err := readBlockHeader(reader, h)
+		if err == io.EOF || (err == nil && b.code == codeEnd) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Blender block directory: %w", err)
+		}
+
+		limit := int64(0)
+		switch b.code {
+		case codeScene:
+			info.SceneCount++
+			limit = min(size, maxSceneBlock)
+		case codeObject:
+			info.ObjectCount++
+			limit = min(size, keepBytes)
+		case codeCollection:
+			info.CollectionCount++
+			limit = min(size, keepBytes)
+		case codeMesh:
+			info.MeshCount++
+		case codeMaterial:
+			info.MaterialCount++
+		case codeDNA:
+			if size > maxDNABlock {
+				return nil, fmt.Errorf("SDNA block of %d bytes exceeds the %d byte limit", size, maxDNABlock)
+			}
+			limit = size
+		}
+		if fileSize >= 0 {
+			offset, err := file.Seek(0, io.SeekCurrent)
+			if err == nil && size > fileSize-(offset-int64(reader.Buffered())) {
+				return nil, fmt.Errorf("truncated Blender file: %s block of %d bytes runs past the end", b.code, size)
+			}
+		}
+		if limit > 0 {
+			b.data = make([]byte, limit)
+			if _, err := io.ReadFull(reader, b.data); err != nil {
+				return nil, fmt.Errorf("truncated Blender file: %w", err)
+			}
+		}
+		if _, err := reader.Discard(int(size - limit)); err != nil {
+			return nil, fmt.Errorf("truncated Blender file: %w", err)
+		}
+		if b.code == codeDNA {
+			dna = b.data
+		} else if b.data != nil {
+			kept = append(kept, b)
+		}
+	}
+
+	// Names and resolution need the SDNA; without it the counts still stand
+	if dna == nil {
+		return info, nil
+	}
+	sdna, err := parseSDNA(dna, h)
+	if err != nil {
+		return info, nil
+	}
+	nameOffset, nameOK := sdna.fieldOffset("ID", "name")
+	for _, b := range kept {
+		name := ""
+		if nameOK {
+			name = idName(b.data, nameOffset)
+		}
+		switch b.code {
+		case codeScene:
+			if name != "" {
+				info.SceneNames = append(info.SceneNames, name)
+			}
+			if info.Width == 0 {
+				info.Width, info.Height = sdna.renderSize(b.data, h.order)
+			}
+		case codeCollection:
+			if name != "" {
+				info.CollectionNames = append(info.CollectionNames, name)
+			}
+		}
+	}
+	return info, nil
+}
+
+// openBlend returns a reader over the uncompressed file: Blender writes gzip (before 3.0) or Zstd when "Compress" is on
+func openBlend(file *os.File) (*bufio.Reader, string, func(), error) {
+	reader := bufio.NewReaderSize(file, 64*1024)
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to decompress Blender file: %w", err)
+		}
+		return bufio.NewReaderSize(gz, 64*1024), "gzip", func() { gz.Close() }, nil
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to decompress Blender file: %w", err)
+		}
+		return bufio.NewReaderSize(zr, 64*1024), "zstd", zr.Close, nil
+	}
+	return reader, "", func() {}, nil
+}
+
+// readHeader reads "BLENDER_v279" (pointer size, endianness, 3-digit version) or,
+// from Blender 5.0, "BLENDER17-01v0500" (header size, format version, endianness, 4-digit version)
+func readHeader(r *bufio.Reader) (*header, error) {
+	head, err := r.Peek(12)
+	if err != nil || !bytes.HasPrefix(head, []byte("BLENDER")) {
+		return nil, fmt.Errorf("not a Blender file")
+	}
+	h := &header{}
+	if head[7] >= '0' && head[7] <= '9' {
+		size, err := strconv.Atoi(string(head[7:9]))
+		if err != nil || size < 17 {
+			return nil, fmt.Errorf("unsupported Blender header")
+		}
+		full, err := r.Peek(size)
+		if err != nil {
+			return nil, fmt.Errorf("truncated Blender header")
+		}
+		if full[9] != '-' || string(full[10:12]) != "01" {
+			return nil, fmt.Errorf("unsupported Blender file format %q", full[10:12])
+		}
+		h.pointerSize, h.large = 8, true
+		if h.order, err = byteOrder(full[12]); err != nil {
+			return nil, err
+		}
+		if h.version, err = strconv.Atoi(string(full[13:17])); err != nil {
+			return nil, fmt.Errorf("invalid Blender version %q", full[13:17])
+		}
+		_, err = r.Discard(size)
+		return h, err
+	}
+
+	switch head[7] {
+	case '_':
+		h.pointerSize = 4
+	case '-':
+		h.pointerSize = 8
+	default:
+		return nil, fmt.Errorf("invalid Blender pointer size %q", head[7])
+	}
+	if h.order, err = byteOrder(head[8]); err != nil {
+		return nil, err
+	}
+	if h.version, err = strconv.Atoi(string(head[9:12])); err != nil {
+		return nil, fmt.Errorf("invalid Blender version %q", head[9:12])
+	}
+	_, err = r.Discard(12)
+	return h, err
+}
+
+// byteOrder maps the header's endianness character
+func byteOrder(c byte) (binary.ByteOrder, error) {
+	switch c {
+	case 'v':
+		return binary.LittleEndian, nil
+	case 'V':
+		return binary.BigEndian, nil
+	}
+	return nil, fmt.Errorf("invalid Blender endianness %q", c)
+}
+
+// readBlockHeader reads one block header and returns the block and its data length
+// Classic: code[4] len:int32 oldptr sdna:int32 nr:int32; Blender 5.0: code[4] sdna:int32 oldptr:uint64 len:int64 nr:int64
+func readBlockHeader(r *bufio.Reader, h *header) (*block, int64, error) {
+	size := 16 + h.pointerSize
+	if h.large {
+		size = 32
+	}
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, err
+		}
+		return nil, 0, io.EOF
+	}
+	b := &block{code: strings.TrimRight(string(raw[:4]), "\x00")}
+	var length int64
+	if h.large {
+		length = int64(h.order.Uint64(raw[16:24]))
+	} else {
+		length = int64(int32(h.order.Uint32(raw[4:8])))
+	}
+	if length < 0 {
+		return nil, 0, fmt.Errorf("negative block length in %s", b.code)
+	}
+	return b, length, nil
+}
+
+// idName reads an ID block's name, dropping the two-letter type prefix ("OBCube" → "Cube")
+func idName(data []byte, offset int) string {
+	if offset < 0 || offset+2 >= len(data) {
+		return ""
+	}
+	name := data[offset+2:]
+	if end := bytes.IndexByte(name, 0); end >= 0 {
+		name = name[:end]
+	}
+	return string(name)
+}
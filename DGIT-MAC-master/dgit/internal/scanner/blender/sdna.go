@@ -0,0 +1,232 @@
+package blender
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SDNA is the struct catalogue Blender writes into every file (the DNA1 block):
+// field names, type names with their sizes, and each struct as a list of (type, name) pairs.
+// Field offsets are not stored; they follow from walking a struct's fields in order
+
+// sdnaField is one field of an SDNA struct
+type sdnaField struct {
+	typ  int
+	name string // Raw name, e.g. "*next", "name[66]" or "(*func)()"
+}
+
+// sdnaStruct is one struct of the catalogue
+type sdnaStruct struct {
+	typ    int
+	fields []sdnaField
+}
+
+// sdna is a decoded DNA1 block
+type sdna struct {
+	pointerSize int
+	types       []string
+	lengths     []int
+	structs     []sdnaStruct
+	byType      map[string]int // Struct index by type name
+}
+
+// sdnaReader reads the DNA1 block's sections
+type sdnaReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// parseSDNA decodes the "SDNA" "NAME" "TYPE" "TLEN" "STRC" sections, each aligned to 4 bytes
+func parseSDNA(data []byte, h *header) (*sdna, error) {
+	r := &sdnaReader{data: data, order: h.order}
+	if err := r.expect("SDNA"); err != nil {
+		return nil, err
+	}
+	if err := r.expect("NAME"); err != nil {
+		return nil, err
+	}
+	names, err := r.strings()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.expect("TYPE"); err != nil {
+		return nil, err
+	}
+	types, err := r.strings()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.expect("TLEN"); err != nil {
+		return nil, err
+	}
+	s := &sdna{pointerSize: h.pointerSize, types: types, lengths: make([]int, len(types)), byType: make(map[string]int)}
+	for i := range types {
+		length, err := r.uint16()
+		if err != nil {
+			return nil, err
+		}
+		s.lengths[i] = int(length)
+	}
+	r.align()
+	if err := r.expect("STRC"); err != nil {
+		return nil, err
+	}
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		typ, err := r.uint16()
+		if err != nil {
+			return nil, err
+		}
+		fieldCount, err := r.uint16()
+		if err != nil {
+			return nil, err
+		}
+		if int(typ) >= len(types) {
+			return nil, fmt.Errorf("struct %d has unknown type %d", i, typ)
+		}
+		st := sdnaStruct{typ: int(typ), fields: make([]sdnaField, fieldCount)}
+		for j := range st.fields {
+			fieldType, err := r.uint16()
+			if err != nil {
+				return nil, err
+			}
+			fieldName, err := r.uint16()
+			if err != nil {
+				return nil, err
+			}
+			if int(fieldType) >= len(types) || int(fieldName) >= len(names) {
+				return nil, fmt.Errorf("struct %s has an invalid field", types[typ])
+			}
+			st.fields[j] = sdnaField{typ: int(fieldType), name: names[fieldName]}
+		}
+		s.byType[types[typ]] = len(s.structs)
+		s.structs = append(s.structs, st)
+	}
+	return s, nil
+}
+
+// fieldOffset returns the byte offset of a field within a struct
+func (s *sdna) fieldOffset(structName, fieldName string) (int, bool) {
+	index, ok := s.byType[structName]
+	if !ok {
+		return 0, false
+	}
+	offset := 0
+	for _, f := range s.structs[index].fields {
+		if baseName(f.name) == fieldName {
+			return offset, true
+		}
+		offset += s.fieldSize(f)
+	}
+	return 0, false
+}
+
+// fieldSize is a field's size: pointers take the file's pointer size, arrays multiply by their dimensions
+func (s *sdna) fieldSize(f sdnaField) int {
+	size := s.lengths[f.typ]
+	if strings.HasPrefix(f.name, "*") || strings.HasPrefix(f.name, "(") {
+		size = s.pointerSize
+	}
+	rest := f.name
+	for {
+		open := strings.IndexByte(rest, '[')
+		if open < 0 {
+			return size
+		}
+		end := strings.IndexByte(rest[open:], ']')
+		if end < 0 {
+			return size
+		}
+		if n, err := strconv.Atoi(rest[open+1 : open+end]); err == nil {
+			size *= n
+		}
+		rest = rest[open+end+1:]
+	}
+}
+
+// renderSize reads Scene.r.xsch and Scene.r.ysch from a scene block
+func (s *sdna) renderSize(scene []byte, order binary.ByteOrder) (int, int) {
+	render, ok := s.fieldOffset("Scene", "r")
+	if !ok {
+		return 0, 0
+	}
+	x, okX := s.fieldOffset("RenderData", "xsch")
+	y, okY := s.fieldOffset("RenderData", "ysch")
+	if !okX || !okY || render+max(x, y)+4 > len(scene) {
+		return 0, 0
+	}
+	return int(int32(order.Uint32(scene[render+x:]))), int(int32(order.Uint32(scene[render+y:])))
+}
+
+// baseName strips pointer stars, function-pointer parentheses and array dimensions: "*name[66]" → "name"
+func baseName(name string) string {
+	name = strings.TrimLeft(name, "*(")
+	if end := strings.IndexAny(name, "[)"); end >= 0 {
+		name = name[:end]
+	}
+	return name
+}
+
+// expect reads a 4-byte section tag
+func (r *sdnaReader) expect(tag string) error {
+	if r.pos+4 > len(r.data) || string(r.data[r.pos:r.pos+4]) != tag {
+		return fmt.Errorf("SDNA is missing %s", tag)
+	}
+	r.pos += 4
+	return nil
+}
+
+// count reads a section's int32 entry count
+func (r *sdnaReader) count() (int, error) {
+	if r.pos+4 > len(r.data) {
+		return 0, errTruncatedSDNA
+	}
+	n := int(int32(r.order.Uint32(r.data[r.pos:])))
+	r.pos += 4
+	if n < 0 {
+		return 0, fmt.Errorf("negative SDNA count")
+	}
+	return n, nil
+}
+
+// strings reads a counted list of null-terminated strings and aligns
+func (r *sdnaReader) strings() ([]string, error) {
+	n, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]string, 0, min(n, 1<<16))
+	for i := 0; i < n; i++ {
+		end := bytes.IndexByte(r.data[r.pos:], 0)
+		if end < 0 {
+			return nil, errTruncatedSDNA
+		}
+		list = append(list, string(r.data[r.pos:r.pos+end]))
+		r.pos += end + 1
+	}
+	r.align()
+	return list, nil
+}
+
+func (r *sdnaReader) uint16() (uint16, error) {
+	if r.pos+2 > len(r.data) {
+		return 0, errTruncatedSDNA
+	}
+	v := r.order.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v, nil
+}
+
+// align moves to the next 4-byte boundary
+func (r *sdnaReader) align() {
+	r.pos = (r.pos + 3) &^ 3
+}
+
+var errTruncatedSDNA = fmt.Errorf("truncated SDNA")
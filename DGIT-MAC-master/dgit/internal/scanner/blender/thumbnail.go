@@ -0,0 +1,78 @@
+package blender
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+// Blender saves its file browser preview in a TEST block written right after the render info:
+// width and height as int32, then width*height RGBA pixels with the bottom row first
+const codeThumbnail = "TEST"
+
+// codeGlobal is the block that follows the preview; a file without TEST before it has none
+const codeGlobal = "GLOB"
+
+// maxThumbnailSide bounds the preview size read from a block header
+const maxThumbnailSide = 4096
+
+// ErrNoThumbnail is returned for files saved without a preview
+var ErrNoThumbnail = fmt.Errorf("no preview stored in this Blender file")
+
+// GetBlendThumbnail returns the preview image Blender stored in a .blend file
+func GetBlendThumbnail(filePath string) (*image.NRGBA, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Blender file: %w", err)
+	}
+	defer file.Close()
+
+	reader, _, closeReader, err := openBlend(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader()
+
+	h, err := readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		b, size, err := readBlockHeader(reader, h)
+		if err == io.EOF || (err == nil && (b.code == codeEnd || b.code == codeGlobal)) {
+			return nil, ErrNoThumbnail
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Blender block directory: %w", err)
+		}
+		if b.code != codeThumbnail {
+			if _, err := reader.Discard(int(size)); err != nil {
+				return nil, fmt.Errorf("truncated Blender file: %w", err)
+			}
+			continue
+		}
+
+		dims := make([]byte, 8)
+		if size < 8 {
+			return nil, ErrNoThumbnail
+		}
+		if _, err := io.ReadFull(reader, dims); err != nil {
+			return nil, fmt.Errorf("truncated Blender preview: %w", err)
+		}
+		width, height := int(int32(h.order.Uint32(dims))), int(int32(h.order.Uint32(dims[4:])))
+		if width <= 0 || height <= 0 || width > maxThumbnailSide || height > maxThumbnailSide ||
+			int64(width)*int64(height)*4 > size-8 {
+			return nil, fmt.Errorf("invalid Blender preview size %dx%d", width, height)
+		}
+
+		img := image.NewNRGBA(image.Rect(0, 0, width, height))
+		row := width * 4
+		for y := height - 1; y >= 0; y-- {
+			if _, err := io.ReadFull(reader, img.Pix[y*img.Stride:y*img.Stride+row]); err != nil {
+				return nil, fmt.Errorf("truncated Blender preview: %w", err)
+			}
+		}
+		return img, nil
+	}
+}
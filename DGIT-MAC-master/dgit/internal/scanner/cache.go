@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+)
+
+// NewCachedFileScanner creates a scanner that reuses scan results stored in the repository
+// Results are keyed by file hash under .dgit/cache/hot/metadata, so unchanged files skip parsing
+func NewCachedFileScanner(dgitDir string) *FileScanner {
+	scanner := NewFileScanner()
+	scanner.metadataCacheDir = filepath.Join(dgitDir, "cache", "hot", "metadata")
+	scanner.limits = perf.Load(dgitDir)
+	return scanner
+}
+
+// loadCachedScan returns the cached scan result for a file hash, or nil on a miss
+// A changed file produces a new hash, so stale entries are never returned
+func (fs *FileScanner) loadCachedScan(hash string) *DesignFile {
+	if fs.metadataCacheDir == "" || hash == "" {
+		return nil
+	}
+	data, err := os.ReadFile(fs.cachePath(hash))
+	if err != nil {
+		return nil
+	}
+	var designFile DesignFile
+	if err := json.Unmarshal(data, &designFile); err != nil || designFile.Hash != hash {
+		return nil
+	}
+	return &designFile
+}
+
+// saveCachedScan stores a scan result for later reuse (failures are ignored - the cache is optional)
+func (fs *FileScanner) saveCachedScan(designFile *DesignFile) {
+	if fs.metadataCacheDir == "" || designFile == nil || designFile.Hash == "" {
+		return
+	}
+	data, err := json.Marshal(designFile)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(fs.metadataCacheDir, 0755); err != nil {
+		return
+	}
+
+	// Write then rename so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(fs.metadataCacheDir, ".scan-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), fs.cachePath(designFile.Hash)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// scanCacheFormat names cache entries; bump it when an analyzer starts extracting more,
+// so results written by the previous analyzers are not served again
+const scanCacheFormat = "4" // 2: .fig files parsed instead of placeholder values, 3: .xd packages parsed, 4: .blend files parsed
+
+// cachePath returns the cache file for a file hash
+func (fs *FileScanner) cachePath(hash string) string {
+	return filepath.Join(fs.metadataCacheDir, hash+".v"+scanCacheFormat+".json")
+}
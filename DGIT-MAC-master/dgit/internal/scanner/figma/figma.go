@@ -0,0 +1,293 @@
+package figma
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// FigInfo contains the document structure read from a local Figma .fig file
+type FigInfo struct {
+	Name          string   // Document name from meta.json, or the document node
+	Kind          string   // "Figma", "FigJam" or "Figma Slides", from the container prefix
+	FormatVersion int      // fig-kiwi container version
+	PageCount     int      // Pages, not counting Figma's internal component page
+	PageNames     []string
+	LayerCount    int      // Layers placed directly on the pages
+	LayerNames    []string
+	FrameCount    int      // Top-level frames and components (artboards)
+	ObjectCount   int      // Every node below the pages
+	Width         int      // Size of the largest top-level frame in pixels
+	Height        int
+}
+
+// maxChunkSize bounds a decompressed chunk so a corrupt length cannot exhaust memory
+const maxChunkSize = 1 << 30
+
+// containerKinds maps the 8-byte fig-kiwi prefixes to the editor that wrote them
+var containerKinds = map[string]string{
+	"fig-kiwi": "Figma",
+	"fig-jam.": "FigJam",
+	"fig-deck": "Figma Slides",
+}
+
+// frameTypes are the node types counted as artboards when placed directly on a page
+var frameTypes = map[string]bool{"FRAME": true, "SYMBOL": true, "SECTION": true}
+
+// GetFigInfo reads a .fig file: either the ZIP written by "Save local copy" (canvas.fig plus meta.json)
+// or a bare fig-kiwi container. The container holds a kiwi schema and a message encoded with it,
+// both compressed; the message's nodeChanges list every node of the document
+func GetFigInfo(filePath string) (*FigInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Figma file: %w", err)
+	}
+
+	name := ""
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		data, name, err = readFigArchive(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) < 12 {
+		return nil, fmt.Errorf("not a Figma file: too short")
+	}
+	kind, ok := containerKinds[string(data[:8])]
+	if !ok {
+		return nil, fmt.Errorf("not a Figma file: unknown header %q", data[:8])
+	}
+	info := &FigInfo{Kind: kind, FormatVersion: int(binary.LittleEndian.Uint32(data[8:12]))}
+
+	chunks, err := readChunks(data[12:])
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) < 2 {
+		return nil, fmt.Errorf("Figma file has no document data")
+	}
+	schemaData, err := decompress(chunks[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress Figma schema: %w", err)
+	}
+	schema, err := decodeSchema(schemaData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Figma schema: %w", err)
+	}
+	messageData, err := decompress(chunks[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress Figma document: %w", err)
+	}
+	nodes, err := schema.decodeNodes(messageData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Figma document: %w", err)
+	}
+
+	info.summarize(nodes)
+	if name != "" {
+		info.Name = name
+	}
+	return info, nil
+}
+
+// readFigArchive returns canvas.fig and the document name from a local-copy ZIP
+func readFigArchive(data []byte) ([]byte, string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open Figma archive: %w", err)
+	}
+	var canvas []byte
+	name := ""
+	for _, f := range archive.File {
+		switch f.Name {
+		case "canvas.fig":
+			canvas, err = readZipEntry(f)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to read canvas.fig: %w", err)
+			}
+		case "meta.json":
+			if raw, err := readZipEntry(f); err == nil {
+				var meta struct {
+					FileName string `json:"file_name"`
+				}
+				if json.Unmarshal(raw, &meta) == nil {
+					name = meta.FileName
+				}
+			}
+		}
+	}
+	if canvas == nil {
+		return nil, "", fmt.Errorf("Figma archive has no canvas.fig")
+	}
+	return canvas, name, nil
+}
+
+// readZipEntry reads one archive entry up to maxChunkSize
+func readZipEntry(f *zip.File) ([]byte, error) {
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, maxChunkSize))
+}
+
+// readChunks splits the container body into its length-prefixed chunks
+func readChunks(data []byte) ([][]byte, error) {
+	var chunks [][]byte
+	for len(data) >= 4 {
+		size := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(size) > uint64(len(data)) {
+			return nil, fmt.Errorf("truncated Figma file")
+		}
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return chunks, nil
+}
+
+// decompress inflates a chunk: Zstd in recent files, raw deflate (or zlib) in older ones
+func decompress(chunk []byte) ([]byte, error) {
+	var reader io.Reader
+	switch {
+	case bytes.HasPrefix(chunk, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		decoder, err := zstd.NewReader(bytes.NewReader(chunk), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		reader = decoder
+	case len(chunk) >= 2 && chunk[0] == 0x78 && (uint16(chunk[0])<<8|uint16(chunk[1]))%31 == 0:
+		zr, err := zlib.NewReader(bytes.NewReader(chunk))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		reader = zr
+	default:
+		fr := flate.NewReader(bytes.NewReader(chunk))
+		defer fr.Close()
+		reader = fr
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxChunkSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxChunkSize {
+		return nil, fmt.Errorf("chunk larger than %d MB", maxChunkSize>>20)
+	}
+	return data, nil
+}
+
+// node is the part of a Figma NodeChange the summary needs
+type node struct {
+	id       string
+	parent   string
+	position string // Fractional index ordering siblings
+	kind     string
+	name     string
+	internal bool
+	width    float32
+	height   float32
+}
+
+// summarize counts pages, top-level layers, frames and objects
+// Nodes under Figma's internal-only page (component storage) are left out
+func (info *FigInfo) summarize(nodes []*node) {
+	byID := make(map[string]*node, len(nodes))
+	for _, n := range nodes {
+		byID[n.id] = n
+	}
+
+	var pages []*node
+	var topLevel []*node
+	for _, n := range nodes {
+		switch n.kind {
+		case "DOCUMENT":
+			if info.Name == "" {
+				info.Name = n.name
+			}
+		case "CANVAS":
+			if !n.internal {
+				pages = append(pages, n)
+			}
+		}
+	}
+	sort.SliceStable(pages, func(i, j int) bool { return pages[i].position < pages[j].position })
+	for _, page := range pages {
+		info.PageNames = append(info.PageNames, page.name)
+	}
+	info.PageCount = len(pages)
+
+	// Resolve each node's page once; depth is bounded so cyclic parents cannot loop forever
+	pageOf := make(map[string]*node)
+	var findPage func(n *node, depth int) *node
+	findPage = func(n *node, depth int) *node {
+		if n == nil || depth > 4096 {
+			return nil
+		}
+		if n.kind == "CANVAS" {
+			return n
+		}
+		if page, ok := pageOf[n.id]; ok {
+			return page
+		}
+		page := findPage(byID[n.parent], depth+1)
+		pageOf[n.id] = page
+		return page
+	}
+
+	var largest float32
+	for _, n := range nodes {
+		if n.kind == "DOCUMENT" || n.kind == "CANVAS" {
+			continue
+		}
+		page := findPage(n, 0)
+		if page == nil || page.internal {
+			continue
+		}
+		info.ObjectCount++
+		if n.parent != page.id {
+			continue
+		}
+		topLevel = append(topLevel, n)
+		if frameTypes[n.kind] {
+			info.FrameCount++
+			if area := n.width * n.height; area > largest {
+				largest = area
+				info.Width, info.Height = int(n.width+0.5), int(n.height+0.5)
+			}
+		}
+	}
+	pageIndex := make(map[string]int, len(pages))
+	for i, page := range pages {
+		pageIndex[page.id] = i
+	}
+	sort.SliceStable(topLevel, func(i, j int) bool {
+		if a, b := pageIndex[topLevel[i].parent], pageIndex[topLevel[j].parent]; a != b {
+			return a < b
+		}
+		return topLevel[i].position < topLevel[j].position
+	})
+	for _, n := range topLevel {
+		info.LayerNames = append(info.LayerNames, n.name)
+	}
+	info.LayerCount = len(topLevel)
+}
+
+// DocumentKind returns the editor name and format version for display, e.g. "Figma (format v48)"
+func (info *FigInfo) DocumentKind() string {
+	return fmt.Sprintf("%s (format v%d)", strings.TrimSpace(info.Kind), info.FormatVersion)
+}
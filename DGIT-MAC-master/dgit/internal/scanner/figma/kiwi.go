@@ -0,0 +1,414 @@
+package figma
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Kiwi is the binary serialization format Figma stores documents in. A file carries its own schema:
+// a list of enum, struct and message definitions. Structs encode every field in order; messages
+// encode (field id, value) pairs ending with id 0. Integers are LEB128 varints (zigzag when signed)
+
+// Kiwi definition kinds
+const (
+	kindEnum    = 0
+	kindStruct  = 1
+	kindMessage = 2
+)
+
+// Built-in kiwi types, stored in the schema as negative type numbers (-1 = bool … -8 = uint64)
+const (
+	typeBool = iota
+	typeByte
+	typeInt
+	typeUint
+	typeFloat
+	typeString
+	typeInt64
+	typeUint64
+)
+
+var errTruncated = errors.New("unexpected end of data")
+
+// field is one field of a kiwi definition
+type field struct {
+	name    string
+	typ     int // Definition index when >= 0, else ^typ is a built-in type
+	isArray bool
+	value   uint32 // Message field id or enum value
+}
+
+// definition is one enum, struct or message of a kiwi schema
+type definition struct {
+	name    string
+	kind    byte
+	fields  []field
+	byValue map[uint32]*field // Message fields by id, enum names by value
+}
+
+// schema is a decoded kiwi schema
+type schema struct {
+	definitions []*definition
+	byName      map[string]int
+}
+
+// buffer reads kiwi primitives
+type buffer struct {
+	data []byte
+	pos  int
+}
+
+func (b *buffer) readByte() (byte, error) {
+	if b.pos >= len(b.data) {
+		return 0, errTruncated
+	}
+	c := b.data[b.pos]
+	b.pos++
+	return c, nil
+}
+
+func (b *buffer) readVarUint() (uint32, error) {
+	var value uint32
+	for shift := 0; ; shift += 7 {
+		c, err := b.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(c&127) << shift
+		if c&128 == 0 || shift >= 28 {
+			return value, nil
+		}
+	}
+}
+
+func (b *buffer) readVarInt() (int32, error) {
+	value, err := b.readVarUint()
+	if value&1 != 0 {
+		return int32(^(value >> 1)), err
+	}
+	return int32(value >> 1), err
+}
+
+func (b *buffer) readVarUint64() (uint64, error) {
+	var value uint64
+	for shift := 0; ; shift += 7 {
+		c, err := b.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if shift >= 56 {
+			return value | uint64(c)<<shift, nil // The ninth byte carries all eight bits
+		}
+		value |= uint64(c&127) << shift
+		if c&128 == 0 {
+			return value, nil
+		}
+	}
+}
+
+// readVarFloat reads kiwi's float: 0 as one zero byte, else the IEEE bits rotated so the exponent comes first
+func (b *buffer) readVarFloat() (float32, error) {
+	first, err := b.readByte()
+	if err != nil || first == 0 {
+		return 0, err
+	}
+	if b.pos+3 > len(b.data) {
+		return 0, errTruncated
+	}
+	bits := uint32(first) | uint32(b.data[b.pos])<<8 | uint32(b.data[b.pos+1])<<16 | uint32(b.data[b.pos+2])<<24
+	b.pos += 3
+	return math.Float32frombits(bits<<23 | bits>>9), nil
+}
+
+// readString reads a null-terminated UTF-8 string
+func (b *buffer) readString() (string, error) {
+	start := b.pos
+	for b.pos < len(b.data) {
+		if b.data[b.pos] == 0 {
+			s := string(b.data[start:b.pos])
+			b.pos++
+			return s, nil
+		}
+		b.pos++
+	}
+	return "", errTruncated
+}
+
+// decodeSchema reads a binary kiwi schema
+func decodeSchema(data []byte) (*schema, error) {
+	b := &buffer{data: data}
+	count, err := b.readVarUint()
+	if err != nil {
+		return nil, err
+	}
+	s := &schema{byName: make(map[string]int)}
+	for i := uint32(0); i < count; i++ {
+		def := &definition{byValue: make(map[uint32]*field)}
+		if def.name, err = b.readString(); err != nil {
+			return nil, err
+		}
+		if def.kind, err = b.readByte(); err != nil {
+			return nil, err
+		}
+		fieldCount, err := b.readVarUint()
+		if err != nil {
+			return nil, err
+		}
+		for j := uint32(0); j < fieldCount; j++ {
+			var f field
+			if f.name, err = b.readString(); err != nil {
+				return nil, err
+			}
+			typ, err := b.readVarInt()
+			if err != nil {
+				return nil, err
+			}
+			f.typ = int(typ)
+			flags, err := b.readByte()
+			if err != nil {
+				return nil, err
+			}
+			f.isArray = flags&1 != 0
+			if f.value, err = b.readVarUint(); err != nil {
+				return nil, err
+			}
+			def.fields = append(def.fields, f)
+		}
+		for j := range def.fields {
+			def.byValue[def.fields[j].value] = &def.fields[j]
+		}
+		s.byName[def.name] = len(s.definitions)
+		s.definitions = append(s.definitions, def)
+	}
+	for _, def := range s.definitions {
+		for _, f := range def.fields {
+			if def.kind != kindEnum && f.typ >= len(s.definitions) || f.typ < -8 {
+				return nil, fmt.Errorf("field %s.%s has unknown type %d", def.name, f.name, f.typ)
+			}
+		}
+	}
+	return s, nil
+}
+
+// decodeNodes decodes a Message and returns its nodeChanges
+// Every other field is skipped without being kept, so image blobs never become Go values
+func (s *schema) decodeNodes(data []byte) ([]*node, error) {
+	root, ok := s.byName["Message"]
+	if !ok {
+		return nil, fmt.Errorf("schema has no Message definition")
+	}
+	d := &decoder{schema: s, buffer: buffer{data: data}}
+	var nodes []*node
+	err := d.eachField(s.definitions[root], func(f *field) (bool, error) {
+		if f.name != "nodeChanges" || !f.isArray || f.typ < 0 {
+			return false, nil
+		}
+		count, err := d.readVarUint()
+		if err != nil {
+			return true, err
+		}
+		for i := uint32(0); i < count; i++ {
+			n, err := d.decodeNode(s.definitions[f.typ])
+			if err != nil {
+				return true, err
+			}
+			nodes = append(nodes, n)
+		}
+		return true, nil
+	})
+	return nodes, err
+}
+
+// decoder walks a message with a schema
+type decoder struct {
+	schema *schema
+	buffer
+}
+
+// eachField calls fn for each field of a message or struct; fn returns true when it consumed the value
+func (d *decoder) eachField(def *definition, fn func(*field) (bool, error)) error {
+	visit := func(f *field) error {
+		done, err := fn(f)
+		if err != nil || done {
+			return err
+		}
+		return d.skip(f.typ, f.isArray)
+	}
+	if def.kind == kindStruct {
+		for i := range def.fields {
+			if err := visit(&def.fields[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		id, err := d.readVarUint()
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			return nil
+		}
+		f, ok := def.byValue[id]
+		if !ok {
+			return fmt.Errorf("unknown field %d in %s", id, def.name)
+		}
+		if err := visit(f); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeNode reads the NodeChange fields the summary uses
+func (d *decoder) decodeNode(def *definition) (*node, error) {
+	n := &node{}
+	err := d.eachField(def, func(f *field) (bool, error) {
+		if f.isArray {
+			return false, nil
+		}
+		switch f.name {
+		case "guid":
+			id, err := d.decodeGUID(f)
+			n.id = id
+			return true, err
+		case "parentIndex":
+			if f.typ < 0 {
+				return false, nil
+			}
+			return true, d.eachField(d.schema.definitions[f.typ], func(pf *field) (bool, error) {
+				switch {
+				case pf.name == "guid" && !pf.isArray:
+					id, err := d.decodeGUID(pf)
+					n.parent = id
+					return true, err
+				case pf.name == "position" && pf.typ == ^typeString && !pf.isArray:
+					position, err := d.readString()
+					n.position = position
+					return true, err
+				}
+				return false, nil
+			})
+		case "type":
+			name, err := d.enumName(f)
+			n.kind = name
+			return true, err
+		case "name":
+			if f.typ != ^typeString {
+				return false, nil
+			}
+			name, err := d.readString()
+			n.name = name
+			return true, err
+		case "internalOnly":
+			if f.typ != ^typeBool {
+				return false, nil
+			}
+			c, err := d.readByte()
+			n.internal = c != 0
+			return true, err
+		case "size":
+			if f.typ < 0 {
+				return false, nil
+			}
+			return true, d.eachField(d.schema.definitions[f.typ], func(vf *field) (bool, error) {
+				if vf.typ != ^typeFloat || vf.isArray {
+					return false, nil
+				}
+				value, err := d.readVarFloat()
+				switch vf.name {
+				case "x":
+					n.width = value
+				case "y":
+					n.height = value
+				}
+				return true, err
+			})
+		}
+		return false, nil
+	})
+	return n, err
+}
+
+// decodeGUID reads a GUID struct as "sessionID:localID"
+func (d *decoder) decodeGUID(f *field) (string, error) {
+	if f.typ < 0 {
+		return "", d.skip(f.typ, f.isArray)
+	}
+	var session, local uint32
+	err := d.eachField(d.schema.definitions[f.typ], func(gf *field) (bool, error) {
+		if gf.typ != ^typeUint || gf.isArray {
+			return false, nil
+		}
+		value, err := d.readVarUint()
+		switch gf.name {
+		case "sessionID":
+			session = value
+		case "localID":
+			local = value
+		}
+		return true, err
+	})
+	return fmt.Sprintf("%d:%d", session, local), err
+}
+
+// enumName reads an enum value and returns its name
+func (d *decoder) enumName(f *field) (string, error) {
+	if f.typ < 0 || d.schema.definitions[f.typ].kind != kindEnum {
+		return "", d.skip(f.typ, f.isArray)
+	}
+	value, err := d.readVarUint()
+	if err != nil {
+		return "", err
+	}
+	if e, ok := d.schema.definitions[f.typ].byValue[value]; ok {
+		return e.name, nil
+	}
+	return fmt.Sprintf("%d", value), nil
+}
+
+// skip reads past a value without keeping it
+func (d *decoder) skip(typ int, isArray bool) error {
+	if isArray {
+		count, err := d.readVarUint()
+		if err != nil {
+			return err
+		}
+		if typ == ^typeByte {
+			if d.pos+int(count) > len(d.data) {
+				return errTruncated
+			}
+			d.pos += int(count)
+			return nil
+		}
+		for i := uint32(0); i < count; i++ {
+			if err := d.skip(typ, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch typ {
+	case ^typeBool, ^typeByte:
+		_, err = d.readByte()
+	case ^typeInt, ^typeUint:
+		_, err = d.readVarUint()
+	case ^typeFloat:
+		_, err = d.readVarFloat()
+	case ^typeString:
+		_, err = d.readString()
+	case ^typeInt64, ^typeUint64:
+		_, err = d.readVarUint64()
+	default:
+		def := d.schema.definitions[typ]
+		if def.kind == kindEnum {
+			_, err = d.readVarUint()
+		} else {
+			err = d.eachField(def, func(*field) (bool, error) { return false, nil })
+		}
+	}
+	return err
+}
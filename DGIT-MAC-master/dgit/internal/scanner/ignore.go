@@ -0,0 +1,217 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// IgnoreFile is the name of the pattern files that keep paths out of staging, scans and status
+const IgnoreFile = ".dgitignore"
+
+// ignoreRule is one line of a .dgitignore file
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool // "!pattern" re-includes what an earlier rule ignored
+	dirOnly bool // "pattern/" only matches folders
+}
+
+// IgnoreMatcher applies the .dgitignore files of a working tree with gitignore semantics:
+// a file's patterns are relative to its folder, later rules override earlier ones and deeper
+// files override shallower ones, and nothing inside an ignored folder can be re-included
+type IgnoreMatcher struct {
+	RootDir string
+	mu      sync.Mutex
+	rules   map[string][]*ignoreRule // Folder relative to RootDir → rules of its .dgitignore
+	fixed   bool                     // Rules were given up front; no .dgitignore files are read
+}
+
+// NewIgnoreMatcher creates a matcher for the working tree at rootDir
+// Pattern files are read lazily, the first time a path below their folder is checked
+func NewIgnoreMatcher(rootDir string) *IgnoreMatcher {
+	if abs, err := filepath.Abs(rootDir); err == nil {
+		rootDir = abs
+	}
+	return &IgnoreMatcher{RootDir: rootDir, rules: make(map[string][]*ignoreRule)}
+}
+
+// NewPatternMatcher creates a matcher from patterns given on the command line, such as add --exclude
+// The patterns use .dgitignore syntax and are relative to baseDir
+func NewPatternMatcher(baseDir string, patterns []string) *IgnoreMatcher {
+	im := NewIgnoreMatcher(baseDir)
+	im.fixed = true
+	for _, pattern := range patterns {
+		if rule := parseIgnoreLine(pattern); rule != nil {
+			im.rules[""] = append(im.rules[""], rule)
+		}
+	}
+	return im
+}
+
+// GlobPattern compiles a glob with ** support to a regular expression matching slash-separated paths
+func GlobPattern(glob string) (*regexp.Regexp, error) {
+	return regexp.Compile("^" + globToRegexp(strings.TrimPrefix(filepath.ToSlash(glob), "./")) + "$")
+}
+
+// IgnoreRootFor returns the working tree root above path (the folder holding .dgit), or path itself outside a repository
+func IgnoreRootFor(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(filepath.Join(dir, ".dgit")); err == nil && info.IsDir() {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return abs
+		}
+	}
+}
+
+// Ignored reports whether a path, or a folder above it, is excluded by a .dgitignore file
+// Paths outside the working tree are never ignored
+func (im *IgnoreMatcher) Ignored(path string, isDir bool) bool {
+	if im == nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(im.RootDir, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i < len(parts); i++ {
+		if im.match(parts[:i], true) {
+			return true
+		}
+	}
+	return im.match(parts, isDir)
+}
+
+// match applies the rules of every folder from the root down to the path's parent; the last match wins
+func (im *IgnoreMatcher) match(parts []string, isDir bool) bool {
+	ignored := false
+	for depth := 0; depth < len(parts); depth++ {
+		dir := strings.Join(parts[:depth], "/")
+		rel := strings.Join(parts[depth:], "/")
+		for _, rule := range im.rulesFor(dir) {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.pattern.MatchString(rel) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// rulesFor loads and caches the rules of a folder's .dgitignore
+func (im *IgnoreMatcher) rulesFor(dir string) []*ignoreRule {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if rules, ok := im.rules[dir]; ok || im.fixed {
+		return rules
+	}
+	rules := loadIgnoreFile(filepath.Join(im.RootDir, filepath.FromSlash(dir), IgnoreFile))
+	im.rules[dir] = rules
+	return rules
+}
+
+// loadIgnoreFile parses a .dgitignore file; a missing file has no rules
+func loadIgnoreFile(path string) []*ignoreRule {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []*ignoreRule
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		if rule := parseIgnoreLine(lines.Text()); rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine compiles one pattern line; blank lines and comments return nil
+func parseIgnoreLine(line string) *ignoreRule {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	rule := &ignoreRule{}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\") // "\#name" and "\!name" match literally
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil
+	}
+
+	// Patterns without an inner slash match a name at any depth; others are anchored to the file's folder
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	pattern, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil
+	}
+	rule.pattern = pattern
+	return rule
+}
+
+// globToRegexp translates gitignore glob syntax (*, ?, [...], **) to a regular expression
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			expr.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
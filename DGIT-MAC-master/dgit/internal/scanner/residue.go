@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ResiduePattern describes autosave, lock or temp files a design application leaves in the work tree
+// Patterns use filepath.Match syntax against the file name; Dir patterns match whole folders
+type ResiduePattern struct {
+	App     string
+	Pattern string
+	Dir     bool
+	Reason  string
+}
+
+// ResiduePatterns are the built-in residue rules, checked in order
+var ResiduePatterns = []ResiduePattern{
+	// Adobe Photoshop
+	{App: "Photoshop", Pattern: "Photoshop Temp*", Reason: "scratch file"},
+	{App: "Photoshop", Pattern: "*_autosave*.ps[db]", Reason: "autosave"},
+	{App: "Photoshop", Pattern: "PSAutoRecover", Dir: true, Reason: "auto-recovery folder"},
+	{App: "Photoshop", Pattern: "*.ps[db]~", Reason: "backup copy"},
+
+	// Adobe Illustrator
+	{App: "Illustrator", Pattern: "~ai-*.tmp", Reason: "temp save"},
+	{App: "Illustrator", Pattern: "*_AutoSaved.ai", Reason: "autosave"},
+	{App: "Illustrator", Pattern: "DataRecovery", Dir: true, Reason: "auto-recovery folder"},
+	{App: "Illustrator", Pattern: "*.ai~", Reason: "backup copy"},
+
+	// Adobe XD and InDesign
+	{App: "XD", Pattern: "*.xd.tmp", Reason: "temp save"},
+	{App: "InDesign", Pattern: "~*.idlk", Reason: "lock file"},
+
+	// Sketch, Affinity and Blender
+	{App: "Sketch", Pattern: "*.sketch.tmp", Reason: "temp save"},
+	{App: "Affinity", Pattern: "*.af~lock~", Reason: "lock file"},
+	{App: "Blender", Pattern: "*.blend[0-9]", Reason: "backup copy"},
+	{App: "Blender", Pattern: "*.blend[0-9][0-9]", Reason: "backup copy"},
+	{App: "Blender", Pattern: "*.blend@", Reason: "temp save"},
+	{App: "Blender", Pattern: "*_autosave.blend", Reason: "autosave"},
+
+	// Office-style lock files and operating system clutter
+	{App: "System", Pattern: "~$*", Reason: "lock file"},
+	{App: "System", Pattern: "._*", Reason: "macOS resource fork"},
+	{App: "System", Pattern: ".DS_Store", Reason: "Finder metadata"},
+	{App: "System", Pattern: "Thumbs.db", Reason: "Explorer thumbnail cache"},
+}
+
+// MatchResidue returns the rule a file or folder name matches, or nil for regular files
+func MatchResidue(path string, isDir bool) *ResiduePattern {
+	name := filepath.Base(path)
+	for i := range ResiduePatterns {
+		rule := &ResiduePatterns[i]
+		if rule.Dir != isDir {
+			continue
+		}
+		if matched, _ := filepath.Match(rule.Pattern, name); matched {
+			return rule
+		}
+	}
+	return nil
+}
+
+// IsResidue reports whether a file is application residue, either by name or by living in a residue folder
+func IsResidue(path string) bool {
+	if MatchResidue(path, false) != nil {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir != "." && dir != "" && MatchResidue(dir, true) != nil {
+			return true
+		}
+	}
+	return false
+}
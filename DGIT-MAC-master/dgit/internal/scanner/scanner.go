@@ -6,10 +6,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"dgit/internal/scanner/illustrator"
-	"dgit/internal/scanner/photoshop"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/blender"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/figma"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/illustrator"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/photoshop"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/xd"
 )
 
 // DesignFile contains comprehensive metadata for detected design files
@@ -23,6 +28,8 @@ type DesignFile struct {
 	Version     string   `json:"version"`      // Application version: "CC 2025 (29.x)"
 	Layers      int      `json:"layers"`       // Number of layers in document
 	Artboards   int      `json:"artboards"`    // Number of artboards/pages
+	Pages       int      `json:"pages,omitempty"` // Pages holding the artboards (Figma)
+	ArtboardNames []string `json:"artboard_names,omitempty"` // Artboard names, where the format names them (XD artboards, Blender scenes)
 	Objects     int      `json:"objects"`      // Estimated object count
 	LayerNames  []string `json:"layer_names"`  // Names of all layers
 	FileSize    int64    `json:"file_size"`    // File size in bytes
@@ -85,6 +92,8 @@ type FileScanner struct {
 	// Ultra-Fast Optimization Settings for performance tuning
 	enableFastScan    bool  // Enable fast scanning mode for large files
 	metadataThreshold int64 // File size threshold for metadata extraction (bytes)
+	metadataCacheDir  string // Scan result cache directory (empty disables caching)
+	limits            perf.Limits // Bounds the files ScanDirectory parses concurrently
 }
 
 // NewFileScanner creates a new standard FileScanner with comprehensive format support
@@ -96,6 +105,7 @@ func NewFileScanner() *FileScanner {
 			".psd":       true, // Adobe Photoshop
 			".sketch":    true, // Sketch App
 			".fig":       true, // Figma (local files)
+			".figma":     true, // Figma cloud document (REST API JSON)
 			".xd":        true, // Adobe XD
 			".afdesign":  true, // Affinity Designer
 			".afphoto":   true, // Affinity Photo
@@ -106,9 +116,11 @@ func NewFileScanner() *FileScanner {
 			".ma":        true, // Maya ASCII
 			".fbx":       true, // FBX
 			".obj":       true, // OBJ
+		".exr":       true, // OpenEXR render output
 		},
 		enableFastScan:    true,
 		metadataThreshold: 500 * 1024 * 1024, // 500MB threshold for full analysis
+		limits:            perf.Load(""),
 	}
 }
 
@@ -135,7 +147,13 @@ func (fs *FileScanner) ScanDirectory(folderPath string) (*ScanResult, error) {
 		MetadataStats: &MetadataStats{},
 	}
 
-	// Recursively walk directory tree
+	// Recursively walk directory tree, leaving out what .dgitignore excludes
+	type candidate struct {
+		path string
+		info os.FileInfo
+	}
+	var candidates []candidate
+	ignore := NewIgnoreMatcher(IgnoreRootFor(folderPath))
 	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			result.ErrorFiles[path] = err
@@ -147,44 +165,72 @@ func (fs *FileScanner) ScanDirectory(folderPath string) (*ScanResult, error) {
 			if info.Name() == ".git" || info.Name() == ".dgit" {
 				return filepath.SkipDir
 			}
+			if path != folderPath && ignore.Ignored(path, true) {
+				return filepath.SkipDir
+			}
+			// Skip application auto-recovery folders
+			if MatchResidue(path, true) != nil {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		// Process design files only
-		if IsDesignFile(path) {
-			result.TotalFiles++
-			result.TotalSize += info.Size()
-			
-			fileType := strings.ToLower(filepath.Ext(path)[1:])
-			result.TypeCounts[fileType]++
-			
-			// Ultra-fast file scanning with comprehensive performance tracking
-			designFile, scanErr := fs.ScanFileWithPerformanceTracking(path, info)
-			if scanErr != nil {
-				result.ErrorFiles[path] = scanErr
-				result.MetadataStats.FailedExtracts++
-				// Create basic file info even if detailed scanning fails
-				designFile = &DesignFile{
-					Path:     path,
-					FileName: info.Name(),
-					Type:     fileType,
-					FileSize: info.Size(),
-					Hash:     fs.generateQuickHash(path, info),
-				}
-			}
-			
-			// Update comprehensive performance statistics
-			fs.updateScanStats(designFile, result.CacheStats, result.MetadataStats)
-			
-			result.DesignFiles = append(result.DesignFiles, *designFile)
+		// Process design files only, leaving out autosave/temp residue
+		if IsDesignFile(path) && MatchResidue(path, false) == nil && !ignore.Ignored(path, false) {
+			candidates = append(candidates, candidate{path: path, info: info})
 		}
 		return nil
 	})
-
 	if err != nil {
 		return nil, fmt.Errorf("error walking directory: %w", err)
 	}
 
+	// Parse files on a bounded pool of workers (performance.max_workers), keeping walk order
+	scanned := make([]*DesignFile, len(candidates))
+	scanErrs := make([]error, len(candidates))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < fs.limits.Workers(len(candidates)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				scanned[i], scanErrs[i] = fs.ScanFileWithPerformanceTracking(candidates[i].path, candidates[i].info)
+			}
+		}()
+	}
+	for i := range candidates {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	for i, c := range candidates {
+		result.TotalFiles++
+		result.TotalSize += c.info.Size()
+
+		fileType := strings.ToLower(filepath.Ext(c.path)[1:])
+		result.TypeCounts[fileType]++
+
+		designFile := scanned[i]
+		if scanErrs[i] != nil {
+			result.ErrorFiles[c.path] = scanErrs[i]
+			result.MetadataStats.FailedExtracts++
+			// Create basic file info even if detailed scanning fails
+			designFile = &DesignFile{
+				Path:     c.path,
+				FileName: c.info.Name(),
+				Type:     fileType,
+				FileSize: c.info.Size(),
+				Hash:     fs.generateQuickHash(c.path, c.info),
+			}
+		}
+
+		// Update comprehensive performance statistics
+		fs.updateScanStats(designFile, result.CacheStats, result.MetadataStats)
+		result.DesignFiles = append(result.DesignFiles, *designFile)
+	}
+
 	result.ScanTime = time.Since(startTime)
 	return result, nil
 }
@@ -239,6 +285,13 @@ func (fs *FileScanner) ScanFile(filePath string) (*DesignFile, error) {
 		designFile.Hash = fs.generateFileHash(filePath, info)
 	}
 
+	// Unchanged files are served from the scan cache without re-parsing
+	if cached := fs.loadCachedScan(designFile.Hash); cached != nil {
+		cached.Path = filePath
+		cached.FileName = fileName
+		return cached, nil
+	}
+
 	// Ultra-fast scanning optimization: Skip heavy metadata extraction for large files
 	if fs.enableFastScan && designFile.FileSize > fs.metadataThreshold {
 		designFile.Metadata = &FileMetadata{
@@ -251,21 +304,29 @@ func (fs *FileScanner) ScanFile(filePath string) (*DesignFile, error) {
 	}
 
 	// Perform detailed analysis based on file type
+	var err error
 	switch fileType {
 	case "ai":
-		return fs.analyzeAIFileWithCaching(filePath, designFile)
+		designFile, err = fs.analyzeAIFileWithCaching(filePath, designFile)
 	case "psd":
-		return fs.analyzePSDFileWithCaching(filePath, designFile)
+		designFile, err = fs.analyzePSDFileWithCaching(filePath, designFile)
 	case "sketch":
-		return fs.analyzeSketchFile(filePath, designFile)
+		designFile, err = fs.analyzeSketchFile(filePath, designFile)
 	case "fig":
-		return fs.analyzeFigmaFile(filePath, designFile)
+		designFile, err = fs.analyzeFigmaFile(filePath, designFile)
 	case "xd":
-		return fs.analyzeXDFile(filePath, designFile)
+		designFile, err = fs.analyzeXDFile(filePath, designFile)
+	case "blend":
+		designFile, err = fs.analyzeBlendFile(filePath, designFile)
 	default:
 		// Unsupported file types return basic information only
-		return designFile, nil
 	}
+
+	// Only successful scans are cached so failures are retried next time
+	if err == nil {
+		fs.saveCachedScan(designFile)
+	}
+	return designFile, err
 }
 
 // analyzeAIFileWithCaching performs cache-friendly Adobe Illustrator file analysis
@@ -351,47 +412,97 @@ func (fs *FileScanner) analyzeSketchFile(filePath string, designFile *DesignFile
 	return designFile, nil
 }
 
-// analyzeFigmaFile performs optimized Figma file analysis
-// Ultra-fast mode with basic information for local Figma files
+// analyzeFigmaFile reads a local .fig file's pages, top-level frames and layers
+// The document is decoded from its kiwi-encoded node list (see scanner/figma)
 func (fs *FileScanner) analyzeFigmaFile(filePath string, designFile *DesignFile) (*DesignFile, error) {
-	designFile.Dimensions = "Unknown"
-	designFile.ColorMode = "RGB"
-	designFile.Version = "Figma"
-	designFile.Layers = 1
-	designFile.LayerNames = []string{"Figma Frame"}
-	
-	// Create basic metadata for caching optimization
+	figInfo, err := figma.GetFigInfo(filePath)
+	if err != nil {
+		return designFile, err
+	}
+
+	if figInfo.Width > 0 && figInfo.Height > 0 {
+		designFile.Dimensions = fmt.Sprintf("%dx%d px", figInfo.Width, figInfo.Height)
+	}
+	designFile.ColorMode = "RGB" // Figma documents are always RGB
+	designFile.Version = figInfo.DocumentKind()
+	designFile.Pages = figInfo.PageCount
+	designFile.Artboards = figInfo.FrameCount
+	designFile.Layers = figInfo.LayerCount
+	designFile.LayerNames = append([]string{}, figInfo.LayerNames...)
+	designFile.Objects = figInfo.ObjectCount
+
 	designFile.Metadata = &FileMetadata{
-		Dimensions:   "Unknown",
-		ColorMode:    "RGB",
-		Resolution:   72,
-		LayerCount:   1,
-		FileVersion:  "Figma",
-		ExtractedAt:  time.Now(),
+		Dimensions:  designFile.Dimensions,
+		ColorMode:   designFile.ColorMode,
+		Resolution:  72,
+		LayerCount:  figInfo.LayerCount,
+		FileVersion: designFile.Version,
+		ExtractedAt: time.Now(),
 	}
-	
+
 	return designFile, nil
 }
 
-// analyzeXDFile performs optimized Adobe XD file analysis
-// Ultra-fast mode with basic information extraction
+// analyzeXDFile reads an Adobe XD package's artboards and layers
+// Artboard names and bounds come from the manifest, layers from each artboard's artwork document
 func (fs *FileScanner) analyzeXDFile(filePath string, designFile *DesignFile) (*DesignFile, error) {
-	designFile.Dimensions = "Unknown"
-	designFile.ColorMode = "RGB"
+	xdInfo, err := xd.GetXDInfo(filePath)
+	if err != nil {
+		return designFile, err
+	}
+
+	if xdInfo.Width > 0 && xdInfo.Height > 0 {
+		designFile.Dimensions = fmt.Sprintf("%dx%d px", xdInfo.Width, xdInfo.Height)
+	}
+	designFile.ColorMode = "RGB" // XD documents are always RGB
 	designFile.Version = "Adobe XD"
-	designFile.Layers = 1
-	designFile.LayerNames = []string{"XD Artboard"}
-	
-	// Create basic metadata for caching optimization
+	if xdInfo.Version != "" {
+		designFile.Version = fmt.Sprintf("Adobe XD (%s)", xdInfo.Version)
+	}
+	designFile.Artboards = xdInfo.ArtboardCount
+	designFile.Layers = xdInfo.LayerCount
+	designFile.ArtboardNames = xdInfo.ArtboardNames
+	designFile.LayerNames = append([]string{}, xdInfo.LayerNames...)
+	designFile.Objects = xdInfo.ObjectCount
+
 	designFile.Metadata = &FileMetadata{
-		Dimensions:   "Unknown",
-		ColorMode:    "RGB",
-		Resolution:   72,
-		LayerCount:   1,
-		FileVersion:  "Adobe XD",
-		ExtractedAt:  time.Now(),
+		Dimensions:  designFile.Dimensions,
+		ColorMode:   designFile.ColorMode,
+		Resolution:  72,
+		LayerCount:  xdInfo.LayerCount,
+		FileVersion: designFile.Version,
+		ExtractedAt: time.Now(),
 	}
-	
+
+	return designFile, nil
+}
+
+// analyzeBlendFile reads a Blender file's version, scenes, collections and objects
+// Scenes count as artboards and collections as layers, so 3D files fit the same summaries
+func (fs *FileScanner) analyzeBlendFile(filePath string, designFile *DesignFile) (*DesignFile, error) {
+	blendInfo, err := blender.GetBlendInfo(filePath)
+	if err != nil {
+		return designFile, err
+	}
+
+	if blendInfo.Width > 0 && blendInfo.Height > 0 {
+		designFile.Dimensions = fmt.Sprintf("%dx%d px", blendInfo.Width, blendInfo.Height)
+	}
+	designFile.Version = fmt.Sprintf("Blender %s", blendInfo.Version)
+	designFile.Artboards = blendInfo.SceneCount
+	designFile.ArtboardNames = blendInfo.SceneNames
+	designFile.Layers = blendInfo.CollectionCount
+	designFile.LayerNames = append([]string{}, blendInfo.CollectionNames...)
+	designFile.Objects = blendInfo.ObjectCount
+
+	designFile.Metadata = &FileMetadata{
+		Dimensions:  designFile.Dimensions,
+		ColorMode:   designFile.ColorMode,
+		LayerCount:  blendInfo.CollectionCount,
+		FileVersion: designFile.Version,
+		ExtractedAt: time.Now(),
+	}
+
 	return designFile, nil
 }
 
@@ -489,6 +600,7 @@ func IsDesignFile(filePath string) bool {
 		".psd":       true, // Adobe Photoshop
 		".sketch":    true, // Sketch App
 		".fig":       true, // Figma
+		".figma":     true, // Figma cloud document (REST API JSON, see 'dgit figma')
 		".xd":        true, // Adobe XD
 		".afdesign":  true, // Affinity Designer
 		".afphoto":   true, // Affinity Photo
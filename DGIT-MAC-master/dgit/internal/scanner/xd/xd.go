@@ -0,0 +1,163 @@
+package xd
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// XDInfo contains the document structure read from an Adobe XD package
+type XDInfo struct {
+	Name          string   // Document name from the manifest
+	Version       string   // XD version that saved the file, when recorded
+	ArtboardCount int
+	ArtboardNames []string
+	Width         int      // Size of the largest artboard
+	Height        int
+	LayerCount    int      // Layers placed directly on the artboards
+	LayerNames    []string
+	ObjectCount   int      // Every element on the artboards, nested ones included
+}
+
+// maxJSONSize bounds the manifest and artwork documents read from a package
+const maxJSONSize = 256 << 20
+
+// manifestNode is one entry of the package manifest tree
+type manifestNode struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Children []*manifestNode `json:"children"`
+	Bounds   *struct {
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	} `json:"uxdesign#bounds"`
+}
+
+// manifest is the top of the package's "manifest" file
+type manifest struct {
+	Name     string          `json:"name"`
+	Version  string          `json:"uxdesign#version"`
+	Children []*manifestNode `json:"children"`
+}
+
+// GetXDInfo reads an .xd file, a ZIP package holding a JSON manifest that lists the artboards
+// and one artwork document (graphicContent.agc) per artboard holding its layers
+func GetXDInfo(filePath string) (*XDInfo, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XD package: %w", err)
+	}
+	defer archive.Close()
+
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+	manifestFile := entries["manifest"]
+	if manifestFile == nil {
+		return nil, fmt.Errorf("not an XD package: no manifest")
+	}
+	var m manifest
+	if err := readJSON(manifestFile, &m); err != nil {
+		return nil, fmt.Errorf("invalid XD manifest: %w", err)
+	}
+
+	info := &XDInfo{Name: m.Name, Version: m.Version}
+	var largest float64
+	for _, artboard := range artboards(&m) {
+		info.ArtboardCount++
+		info.ArtboardNames = append(info.ArtboardNames, artboard.Name)
+		if artboard.Bounds != nil {
+			if area := artboard.Bounds.Width * artboard.Bounds.Height; area > largest {
+				largest = area
+				info.Width, info.Height = int(artboard.Bounds.Width+0.5), int(artboard.Bounds.Height+0.5)
+			}
+		}
+
+		// Layers are optional: packages from old XD versions keep artwork elsewhere
+		agc := entries[path.Join("artwork", artboard.Path, "graphics", "graphicContent.agc")]
+		if agc == nil {
+			continue
+		}
+		var content map[string]interface{}
+		if err := readJSON(agc, &content); err != nil {
+			return nil, fmt.Errorf("invalid artwork for %s: %w", artboard.Name, err)
+		}
+		for _, layer := range artboardLayers(content) {
+			info.LayerCount++
+			if name, _ := layer["name"].(string); name != "" {
+				info.LayerNames = append(info.LayerNames, name)
+			}
+			info.ObjectCount += countElements(layer)
+		}
+	}
+	return info, nil
+}
+
+// artboards returns the artwork entries of a manifest, leaving out the pasteboard
+func artboards(m *manifest) []*manifestNode {
+	var result []*manifestNode
+	for _, top := range m.Children {
+		if top.Path != "artwork" && top.Name != "artwork" {
+			continue
+		}
+		for _, child := range top.Children {
+			if child.Name == "pasteboard" || child.Path == "pasteboard" {
+				continue
+			}
+			if child.Bounds != nil || strings.HasPrefix(child.Path, "artboard-") {
+				result = append(result, child)
+			}
+		}
+	}
+	return result
+}
+
+// artboardLayers returns the elements placed directly on an artboard document:
+// children[0].artboard.children, the artboard node wrapping its layers
+func artboardLayers(content map[string]interface{}) []map[string]interface{} {
+	for _, child := range objects(content["children"]) {
+		if artboard, ok := child["artboard"].(map[string]interface{}); ok {
+			return objects(artboard["children"])
+		}
+	}
+	return nil
+}
+
+// countElements counts an element and everything nested in it (groups keep theirs under group.children)
+func countElements(element map[string]interface{}) int {
+	count := 1
+	for _, container := range []string{"group", "syncRef", "shape"} {
+		if nested, ok := element[container].(map[string]interface{}); ok {
+			for _, child := range objects(nested["children"]) {
+				count += countElements(child)
+			}
+		}
+	}
+	return count
+}
+
+// objects returns the JSON objects of an array, skipping anything else
+func objects(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			result = append(result, object)
+		}
+	}
+	return result
+}
+
+// readJSON decodes one package entry
+func readJSON(f *zip.File, v interface{}) error {
+	reader, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(io.LimitReader(reader, maxJSONSize)).Decode(v)
+}
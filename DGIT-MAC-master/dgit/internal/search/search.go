@@ -0,0 +1,376 @@
+package search
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/approval"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/review"
+)
+
+// Indexed fields
+const (
+	FieldMessage  = "message"
+	FieldFile     = "file"
+	FieldLayer    = "layer"
+	FieldApproval = "approval"
+	FieldReview   = "review"
+)
+
+// IndexDBFile holds the index of repositories on the JSON metadata backend, relative to .dgit
+// Repositories on the SQLite backend keep the index tables in the metadata database instead
+var IndexDBFile = filepath.Join("index", "search.db")
+
+// indexSchema stores one FTS5 row per piece of text and the change stamp of every indexed source
+const indexSchema = `
+CREATE TABLE IF NOT EXISTS search_sources (
+	key     TEXT PRIMARY KEY,
+	stamp   TEXT NOT NULL,
+	version INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS search_entries USING fts5(
+	text, field UNINDEXED, context UNINDEXED, source UNINDEXED, version UNINDEXED,
+	tokenize = 'unicode61'
+);
+`
+
+// Entry is one piece of searchable text belonging to a source
+type Entry struct {
+	Field   string
+	Context string // e.g. the file a layer belongs to
+	Text    string
+}
+
+// Source is an indexed document: a commit, the approval notes of a version, or a review thread
+type Source struct {
+	Version int
+	Entries []*Entry
+}
+
+// Hit is a search match
+type Hit struct {
+	Version int
+	Source  string
+	Entry   *Entry
+	Rank    float64 // FTS5 bm25 rank; lower is more relevant
+}
+
+// SearchIndex maintains the SQLite FTS5 search index over repository history
+type SearchIndex struct {
+	DgitDir string
+}
+
+// NewSearchIndex creates a new search index manager for the given .dgit directory
+func NewSearchIndex(dgitDir string) *SearchIndex {
+	return &SearchIndex{DgitDir: dgitDir}
+}
+
+// open returns the database holding the index: the metadata database when the repository has one
+func (si *SearchIndex) open() (*sql.DB, error) {
+	path := filepath.Join(si.DgitDir, log.MetadataDBFile)
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(si.DgitDir, IndexDBFile)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create index directory: %w", err)
+		}
+	}
+	db, err := log.OpenDatabase(path, indexSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	os.Remove(filepath.Join(si.DgitDir, "index", "search.json")) // JSON index of earlier releases
+	return db, nil
+}
+
+// Update brings the index up to date, re-reading only commits and notes whose files changed
+// Returns the number of sources that were (re)indexed
+func (si *SearchIndex) Update() (int, error) {
+	db, err := si.open()
+	if err != nil {
+		return 0, err
+	}
+	indexed, err := indexedStamps(db)
+	if err != nil {
+		return 0, err
+	}
+	current := si.collectStamps()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to update search index: %w", err)
+	}
+	defer tx.Rollback()
+
+	changed := 0
+	// Drop sources whose files disappeared
+	for key := range indexed {
+		if _, ok := current[key]; !ok {
+			if err := dropSource(tx, key); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+	for key, stamp := range current {
+		if indexed[key] == stamp.stamp {
+			continue
+		}
+		if err := dropSource(tx, key); err != nil {
+			return changed, err
+		}
+		source, err := si.readSource(key, stamp.path)
+		if err != nil {
+			continue // Unreadable now - will be retried once the file changes
+		}
+		if err := addSource(tx, key, stamp.stamp, source); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	if err := tx.Commit(); err != nil {
+		return changed, fmt.Errorf("failed to save search index: %w", err)
+	}
+	return changed, nil
+}
+
+// Rebuild discards the index and indexes the whole repository again
+func (si *SearchIndex) Rebuild() (int, error) {
+	db, err := si.open()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := db.Exec(`DELETE FROM search_entries; DELETE FROM search_sources;`); err != nil {
+		return 0, fmt.Errorf("failed to clear search index: %w", err)
+	}
+	return si.Update()
+}
+
+// Search returns matches for a query, newest versions first
+// Every word must match (the last word may be a prefix); quoted queries match as a phrase
+func (si *SearchIndex) Search(query string, fields []string) ([]*Hit, error) {
+	if _, err := si.Update(); err != nil {
+		return nil, err
+	}
+	db, err := si.open()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.TrimSpace(query)
+	exactPhrase := len(query) > 1 && strings.HasPrefix(query, "\"") && strings.HasSuffix(query, "\"")
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	// Tokens hold only letters and digits, so quoting them keeps FTS5 query syntax out
+	var match string
+	if exactPhrase {
+		match = `"` + strings.Join(terms, " ") + `"`
+	} else {
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + term + `"`
+		}
+		match = strings.Join(quoted, " ") + "*"
+	}
+
+	statement := `SELECT source, version, field, context, text, rank FROM search_entries WHERE search_entries MATCH ?`
+	args := []interface{}{match}
+	if len(fields) > 0 {
+		statement += ` AND field IN (?` + strings.Repeat(`, ?`, len(fields)-1) + `)`
+		for _, field := range fields {
+			args = append(args, field)
+		}
+	}
+	statement += ` ORDER BY version DESC, rank, text`
+
+	rows, err := db.Query(statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+	var hits []*Hit
+	for rows.Next() {
+		hit := &Hit{Entry: &Entry{}}
+		if err := rows.Scan(&hit.Source, &hit.Version, &hit.Entry.Field, &hit.Entry.Context, &hit.Entry.Text, &hit.Rank); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// indexedStamps returns the change stamp of every indexed source
+func indexedStamps(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query(`SELECT key, stamp FROM search_sources`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+	defer rows.Close()
+	stamps := make(map[string]string)
+	for rows.Next() {
+		var key, stamp string
+		if err := rows.Scan(&key, &stamp); err != nil {
+			return nil, err
+		}
+		stamps[key] = stamp
+	}
+	return stamps, rows.Err()
+}
+
+// dropSource removes a source and its text from the index
+func dropSource(tx *sql.Tx, key string) error {
+	if _, err := tx.Exec(`DELETE FROM search_entries WHERE source = ?`, key); err != nil {
+		return fmt.Errorf("failed to drop %s from search index: %w", key, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM search_sources WHERE key = ?`, key); err != nil {
+		return fmt.Errorf("failed to drop %s from search index: %w", key, err)
+	}
+	return nil
+}
+
+// addSource indexes a source's text under its change stamp
+func addSource(tx *sql.Tx, key, stamp string, source *Source) error {
+	for _, entry := range source.Entries {
+		if _, err := tx.Exec(`INSERT INTO search_entries (text, field, context, source, version) VALUES (?, ?, ?, ?, ?)`,
+			entry.Text, entry.Field, entry.Context, key, source.Version); err != nil {
+			return fmt.Errorf("failed to index %s: %w", key, err)
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO search_sources (key, stamp, version) VALUES (?, ?, ?)`, key, stamp, source.Version); err != nil {
+		return fmt.Errorf("failed to index %s: %w", key, err)
+	}
+	return nil
+}
+
+// sourceStamp locates the file a source is indexed from (commits are read from the metadata store)
+type sourceStamp struct {
+	path  string
+	stamp string
+}
+
+// collectStamps lists every indexable source with a cheap change stamp (no contents are read)
+func (si *SearchIndex) collectStamps() map[string]sourceStamp {
+	stamps := make(map[string]sourceStamp)
+	add := func(dir, prefix string) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			id := strings.TrimSuffix(name, ".json")
+			if prefix != "review/" && !strings.HasPrefix(id, "v") {
+				continue
+			}
+			stamps[prefix+id] = sourceStamp{
+				path:  filepath.Join(dir, name),
+				stamp: fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano()),
+			}
+		}
+	}
+
+	if commitStamps, err := log.NewLogManager(si.DgitDir).GetCommitStamps(); err == nil {
+		for version, stamp := range commitStamps {
+			stamps[fmt.Sprintf("commit/v%d", version)] = sourceStamp{stamp: stamp}
+		}
+	}
+	add(approval.NewApprovalManager(si.DgitDir).NotesDir, "approval/")
+	add(review.NewReviewManager(si.DgitDir).ReviewsDir, "review/")
+	return stamps
+}
+
+// readSource loads and extracts the searchable text of one source
+func (si *SearchIndex) readSource(key, path string) (*Source, error) {
+	var data []byte
+	var err error
+	if version, ok := strings.CutPrefix(key, "commit/v"); ok {
+		n, _ := strconv.Atoi(version)
+		data, err = log.NewLogManager(si.DgitDir).LoadCommitData(n)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasPrefix(key, "commit/"):
+		var c log.Commit
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, err
+		}
+		source := &Source{Version: c.Version}
+		source.Entries = append(source.Entries, &Entry{Field: FieldMessage, Text: c.Message})
+		for fileName, metadata := range c.Metadata {
+			source.Entries = append(source.Entries, &Entry{Field: FieldFile, Text: fileName})
+			md, ok := metadata.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			layerNames, _ := md["layer_names"].([]interface{})
+			for _, layer := range layerNames {
+				if name, ok := layer.(string); ok && name != "" {
+					source.Entries = append(source.Entries, &Entry{Field: FieldLayer, Context: fileName, Text: name})
+				}
+			}
+		}
+		return source, nil
+
+	case strings.HasPrefix(key, "approval/"):
+		var notes []*approval.Note
+		if err := json.Unmarshal(data, &notes); err != nil {
+			return nil, err
+		}
+		version, _ := strconv.Atoi(strings.TrimPrefix(key, "approval/v"))
+		source := &Source{Version: version}
+		for _, note := range notes {
+			text := strings.TrimSpace(note.Message)
+			if text == "" {
+				continue
+			}
+			source.Entries = append(source.Entries, &Entry{
+				Field: FieldApproval, Context: fmt.Sprintf("%s by %s", note.State, note.By), Text: text,
+			})
+		}
+		return source, nil
+
+	case strings.HasPrefix(key, "review/"):
+		var r review.Review
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		context := fmt.Sprintf("review #%d", r.ID)
+		source := &Source{Version: r.Version}
+		source.Entries = append(source.Entries, &Entry{Field: FieldReview, Context: context, Text: r.Title})
+		for _, comment := range r.Comments {
+			source.Entries = append(source.Entries, &Entry{
+				Field: FieldReview, Context: fmt.Sprintf("%s, %s", context, comment.Author), Text: comment.Message,
+			})
+		}
+		return source, nil
+	}
+	return nil, fmt.Errorf("unknown source %s", key)
+}
+
+// tokenize lowercases text and splits it into words on anything but letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,287 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// Share modes
+const (
+	ModeDownload = "download" // Served as an attachment
+	ModePreview  = "preview"  // Served inline for viewing in the browser
+)
+
+// Link is a time-limited, signed URL for a single file of a single version
+// Anyone holding the URL can download that file until it expires, without repository access
+type Link struct {
+	Version   int       `json:"version"`
+	File      string    `json:"file"`
+	Mode      string    `json:"mode"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+}
+
+// ShareManager issues and verifies presigned share links
+type ShareManager struct {
+	DgitDir    string
+	KeyFile    string
+	SharesFile string
+	TempDir    string
+}
+
+// NewShareManager creates a new share manager for the given .dgit directory
+func NewShareManager(dgitDir string) *ShareManager {
+	return &ShareManager{
+		DgitDir:    dgitDir,
+		KeyFile:    filepath.Join(dgitDir, "keys", "share.key"),
+		SharesFile: filepath.Join(dgitDir, "shares.json"),
+		TempDir:    filepath.Join(dgitDir, "temp"),
+	}
+}
+
+// Create issues a signed share link for a file in a commit
+func (sm *ShareManager) Create(commit *log.Commit, file string, expires time.Duration, mode string) (*Link, error) {
+	if expires <= 0 {
+		return nil, fmt.Errorf("expiry must be positive")
+	}
+	if mode != ModeDownload && mode != ModePreview {
+		return nil, fmt.Errorf("unknown share mode %q", mode)
+	}
+
+	fileName, ok := findCommitFile(commit, file)
+	if !ok {
+		if candidates := sameBaseName(commit, file); len(candidates) > 0 {
+			return nil, fmt.Errorf("%s not found in v%d; links need the full path, e.g. %s", file, commit.Version, strings.Join(candidates, ", "))
+		}
+		return nil, fmt.Errorf("%s not found in v%d", file, commit.Version)
+	}
+
+	key, err := sm.loadOrCreateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	link := &Link{
+		Version:   commit.Version,
+		File:      fileName,
+		Mode:      mode,
+		ExpiresAt: now.Add(expires).Truncate(time.Second),
+		CreatedAt: now,
+	}
+	link.Signature = sign(key, link)
+
+	if err := sm.record(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// URL builds the public URL of a share link for the given server base URL
+func (sm *ShareManager) URL(baseURL string, link *Link) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(link.ExpiresAt.Unix(), 10))
+	query.Set("mode", link.Mode)
+	query.Set("sig", link.Signature)
+	return fmt.Sprintf("%s/share/v%d/%s?%s", strings.TrimSuffix(baseURL, "/"),
+		link.Version, escapePath(link.File), query.Encode())
+}
+
+// Verify checks a share request's signature and expiry and returns the link it describes
+func (sm *ShareManager) Verify(version int, file, mode, expires, signature string) (*Link, error) {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiry")
+	}
+
+	key, err := sm.loadKey()
+	if err != nil {
+		return nil, fmt.Errorf("sharing is not enabled for this repository")
+	}
+
+	link := &Link{
+		Version:   version,
+		File:      file,
+		Mode:      mode,
+		ExpiresAt: time.Unix(expiresUnix, 0),
+	}
+	if !hmac.Equal([]byte(signature), []byte(sign(key, link))) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if time.Now().After(link.ExpiresAt) {
+		return nil, fmt.Errorf("link expired on %s", link.ExpiresAt.Format("2006-01-02 15:04"))
+	}
+	link.Signature = signature
+	return link, nil
+}
+
+// Extract restores the shared file into a scratch directory and returns its path
+// The caller must remove the returned directory when done
+func (sm *ShareManager) Extract(link *Link) (string, string, error) {
+	commit, err := log.NewLogManager(sm.DgitDir).GetCommit(link.Version)
+	if err != nil {
+		return "", "", fmt.Errorf("version v%d not available: %w", link.Version, err)
+	}
+	if _, ok := findCommitFile(commit, link.File); !ok {
+		return "", "", fmt.Errorf("v%d did not contain %s", link.Version, link.File)
+	}
+
+	if err := os.MkdirAll(sm.TempDir, 0755); err != nil {
+		return "", "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	scratchDir, err := os.MkdirTemp(sm.TempDir, "share-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+
+	restoreManager := restore.NewRestoreManager(sm.DgitDir)
+	restoreManager.WorkDir = scratchDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", link.Version), []string{link.File}, commit); err != nil {
+		os.RemoveAll(scratchDir)
+		return "", "", fmt.Errorf("failed to extract %s: %w", link.File, err)
+	}
+
+	filePath := filepath.Join(scratchDir, filepath.FromSlash(link.File))
+	if _, err := os.Stat(filePath); err != nil {
+		os.RemoveAll(scratchDir)
+		return "", "", fmt.Errorf("v%d did not contain %s", link.Version, link.File)
+	}
+	return scratchDir, filePath, nil
+}
+
+// GetShares returns all issued share links, optionally only those still valid
+func (sm *ShareManager) GetShares(activeOnly bool) ([]*Link, error) {
+	links := []*Link{}
+
+	data, err := os.ReadFile(sm.SharesFile)
+	if os.IsNotExist(err) {
+		return links, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shares file: %w", err)
+	}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, fmt.Errorf("failed to parse shares file: %w", err)
+	}
+
+	if !activeOnly {
+		return links, nil
+	}
+	now := time.Now()
+	active := []*Link{}
+	for _, link := range links {
+		if now.Before(link.ExpiresAt) {
+			active = append(active, link)
+		}
+	}
+	return active, nil
+}
+
+// record appends an issued link to the share log, dropping long-expired entries
+func (sm *ShareManager) record(link *Link) error {
+	links, err := sm.GetShares(false)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	kept := []*Link{}
+	for _, existing := range links {
+		if existing.ExpiresAt.After(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, link)
+
+	data, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shares: %w", err)
+	}
+	if err := os.WriteFile(sm.SharesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write shares file: %w", err)
+	}
+	return nil
+}
+
+// loadKey reads the repository share signing key
+func (sm *ShareManager) loadKey() ([]byte, error) {
+	data, err := os.ReadFile(sm.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read share key: %w", err)
+	}
+	return hex.DecodeString(strings.TrimSpace(string(data)))
+}
+
+// loadOrCreateKey reads the share signing key, generating one on first use
+func (sm *ShareManager) loadOrCreateKey() ([]byte, error) {
+	if key, err := sm.loadKey(); err == nil {
+		return key, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate share key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(sm.KeyFile), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	if err := os.WriteFile(sm.KeyFile, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write share key: %w", err)
+	}
+	return key, nil
+}
+
+// sign computes the HMAC signature of a link's version, file, mode, and expiry
+func sign(key []byte, link *Link) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%d\n%s\n%s\n%d", link.Version, link.File, link.Mode, link.ExpiresAt.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// findCommitFile locates a file in a commit by its exact path
+// Links are signed for one path, so a file elsewhere with the same name never stands in for it
+func findCommitFile(commit *log.Commit, file string) (string, bool) {
+	target := filepath.ToSlash(filepath.Clean(file))
+	for fileName := range commit.Metadata {
+		if filepath.ToSlash(fileName) == target {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// sameBaseName lists the files of a commit named like file, for suggesting the full path
+func sameBaseName(commit *log.Commit, file string) []string {
+	var matches []string
+	for fileName := range commit.Metadata {
+		if filepath.Base(fileName) == filepath.Base(file) {
+			matches = append(matches, filepath.ToSlash(fileName))
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// escapePath URL-escapes each segment of a slash-separated path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
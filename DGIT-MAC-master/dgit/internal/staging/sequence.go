@@ -0,0 +1,135 @@
+package staging
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MinSequenceFrames is the number of numbered files needed before they are treated as one sequence
+const MinSequenceFrames = 3
+
+// framePattern splits a path into prefix, frame number and extension (renders/shot_0001.psd)
+var framePattern = regexp.MustCompile(`^(.*?)(\d+)(\.[^./\\]+)$`)
+
+// Sequence groups numbered frames (shot_0001.psd … shot_0240.psd) into one logical asset
+type Sequence struct {
+	Pattern string `json:"pattern"` // Display name with # per digit, e.g. renders/shot_####.psd
+	Prefix  string `json:"prefix"`  // Path up to the frame number
+	Suffix  string `json:"suffix"`  // Extension after the frame number
+	Padding int    `json:"padding"` // Digits per frame number, 0 when numbers are not zero-padded
+	First   int    `json:"first"`
+	Last    int    `json:"last"`
+	Frames  int    `json:"frames"`
+	Missing []int  `json:"missing,omitempty"` // Frame numbers absent between First and Last
+}
+
+// Matches reports whether a path is a frame of this sequence
+func (s *Sequence) Matches(path string) bool {
+	parts := framePattern.FindStringSubmatch(path)
+	if parts == nil || parts[1] != s.Prefix || parts[3] != s.Suffix {
+		return false
+	}
+	if s.Padding > 0 {
+		return len(parts[2]) == s.Padding
+	}
+	return parts[2] == "0" || !strings.HasPrefix(parts[2], "0")
+}
+
+// FrameRange formats the frame numbers as they appear in file names, e.g. 0001-0240
+func (s *Sequence) FrameRange() string {
+	return fmt.Sprintf("%0*d-%0*d", s.Padding, s.First, s.Padding, s.Last)
+}
+
+// DetectSequences groups numbered files into sequences; paths that are not part of one are returned as singles
+func DetectSequences(paths []string) ([]*Sequence, []string) {
+	type frame struct {
+		path   string
+		number int
+		digits string
+	}
+	groups := make(map[string][]frame)
+	var singles []string
+	for _, path := range paths {
+		parts := framePattern.FindStringSubmatch(path)
+		if parts == nil {
+			singles = append(singles, path)
+			continue
+		}
+		number, err := strconv.Atoi(parts[2])
+		if err != nil {
+			singles = append(singles, path)
+			continue
+		}
+		key := parts[1] + "\x00" + parts[3]
+		groups[key] = append(groups[key], frame{path: path, number: number, digits: parts[2]})
+	}
+
+	var sequences []*Sequence
+	for key, frames := range groups {
+		if len(frames) < MinSequenceFrames {
+			for _, f := range frames {
+				singles = append(singles, f.path)
+			}
+			continue
+		}
+		prefix, suffix, _ := strings.Cut(key, "\x00")
+
+		// Zero-padded sequences share one width; otherwise numbers are written plainly
+		padding := len(frames[0].digits)
+		for _, f := range frames[1:] {
+			if len(f.digits) != padding {
+				padding = 0
+				break
+			}
+		}
+
+		sort.Slice(frames, func(i, j int) bool { return frames[i].number < frames[j].number })
+		seq := &Sequence{
+			Prefix:  prefix,
+			Suffix:  suffix,
+			Padding: padding,
+			First:   frames[0].number,
+			Last:    frames[len(frames)-1].number,
+			Frames:  len(frames),
+		}
+		hashes := "#"
+		if padding > 0 {
+			hashes = strings.Repeat("#", padding)
+		}
+		seq.Pattern = prefix + hashes + suffix
+
+		present := make(map[int]bool, len(frames))
+		for _, f := range frames {
+			present[f.number] = true
+		}
+		for n := seq.First; n <= seq.Last; n++ {
+			if !present[n] {
+				seq.Missing = append(seq.Missing, n)
+			}
+		}
+		sequences = append(sequences, seq)
+	}
+
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i].Pattern < sequences[j].Pattern })
+	sort.Strings(singles)
+	return sequences, singles
+}
+
+// GetSequences groups the staged files into frame sequences and single files
+func (s *StagingArea) GetSequences() ([]*Sequence, []*StagedFile) {
+	var paths []string
+	byPath := make(map[string]*StagedFile)
+	for _, file := range s.GetStagedFiles() {
+		paths = append(paths, file.Path)
+		byPath[file.Path] = file
+	}
+	sequences, singlePaths := DetectSequences(paths)
+	var singles []*StagedFile
+	for _, path := range singlePaths {
+		singles = append(singles, byPath[path])
+	}
+	return sequences, singles
+}
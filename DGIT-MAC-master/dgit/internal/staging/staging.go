@@ -8,9 +8,15 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/iosched"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/repolock"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/submodule"
+
 	"github.com/pierrec/lz4/v4"
 )
 
@@ -28,6 +34,9 @@ type StagedFile struct {
 	CacheLevel    string        `json:"cache_level"`    // hot/warm/cold
 	PreCompressed bool          `json:"pre_compressed"` // LZ4 pre-compression status
 	Metadata      *FileMetadata `json:"metadata,omitempty"` // Pre-extracted metadata
+	
+	// Set by 'dgit mv': the path this file had in history, so history can follow it
+	RenamedFrom   string        `json:"renamed_from,omitempty"`
 }
 
 // FileMetadata contains pre-extracted design file metadata for ultra-fast commits
@@ -44,11 +53,16 @@ type FileMetadata struct {
 type AddResult struct {
 	AddedFiles     []string
 	FailedFiles    map[string]error
+	SkippedResidue []string // Autosave/temp files excluded by default
+	SkippedIgnored []string // Files excluded by .dgitignore
+	SkippedExcluded []string // Files matching an Exclude pattern
+	ReusedFiles    []string // Files whose content was already in a cache tier
 	CacheStats     *CacheStats
 	ProcessingTime time.Duration
 }
 
 // CacheStats tracks ultra-fast cache performance
+// Counts accumulate in the staging area until it is cleared
 type CacheStats struct {
 	HotCacheHits      int `json:"hot_cache_hits"`
 	WarmCacheHits     int `json:"warm_cache_hits"`
@@ -62,6 +76,7 @@ type CacheStats struct {
 type StagingArea struct {
 	DgitDir     string
 	StagingFile string
+	StatsFile   string
 	files       map[string]*StagedFile
 	
 	// Cache directories
@@ -69,6 +84,17 @@ type StagingArea struct {
 	warmCacheDir string
 	coldCacheDir string
 	cacheStats   *CacheStats
+
+	// Ignore excludes paths listed in .dgitignore files from AddPattern; nil stages everything
+	Ignore *scanner.IgnoreMatcher
+	// Exclude holds extra patterns layered over Ignore, e.g. from 'dgit add --exclude'; nil excludes nothing
+	Exclude *scanner.IgnoreMatcher
+
+	// Lock is the repository lock the caller already holds; nil makes SaveStaging take its own
+	Lock *repolock.Lock
+
+	// io paces pre-compression reads to performance.io_throttle_mbps
+	io *iosched.Scheduler
 }
 
 // NewStagingArea creates a new ultra-fast staging area manager with 3-tier cache
@@ -88,16 +114,23 @@ func NewStagingArea(dgitDir string) *StagingArea {
 	return &StagingArea{
 		DgitDir:      dgitDir,
 		StagingFile:  filepath.Join(stagingDir, "staged.json"),
+		StatsFile:    filepath.Join(stagingDir, "cache_stats.json"),
 		files:        make(map[string]*StagedFile),
 		hotCacheDir:  hotCache,
 		warmCacheDir: warmCache,
 		coldCacheDir: coldCache,
 		cacheStats:   &CacheStats{},
+		Ignore:       scanner.NewIgnoreMatcher(filepath.Dir(dgitDir)),
+		io:           iosched.NewScheduler(dgitDir, iosched.Foreground),
 	}
 }
 
 // LoadStaging loads the current staging area from disk with cache validation
 func (s *StagingArea) LoadStaging() error {
+	if data, err := os.ReadFile(s.StatsFile); err == nil {
+		json.Unmarshal(data, s.cacheStats)
+	}
+
 	if _, err := os.Stat(s.StagingFile); os.IsNotExist(err) {
 		return nil // No staging file exists yet
 	}
@@ -132,7 +165,14 @@ func (s *StagingArea) validateCacheIntegrity() {
 }
 
 // SaveStaging saves the current staging area to disk with cache optimization
+// Holds the repository lock so a commit or another terminal never reads a half-written file
 func (s *StagingArea) SaveStaging() error {
+	lock, err := repolock.Reuse(s.Lock, s.DgitDir, "staging", repolock.DefaultWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	data, err := json.MarshalIndent(s.files, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal staging data: %w", err)
@@ -142,6 +182,14 @@ func (s *StagingArea) SaveStaging() error {
 		return fmt.Errorf("failed to write staging file: %w", err)
 	}
 
+	stats, err := json.MarshalIndent(s.cacheStats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache stats: %w", err)
+	}
+	if err := os.WriteFile(s.StatsFile, stats, 0644); err != nil {
+		return fmt.Errorf("failed to write cache stats: %w", err)
+	}
+
 	return nil
 }
 
@@ -165,12 +213,14 @@ func (s *StagingArea) AddFile(path string) error {
 	if !isDesignFile(absPath) {
 		return fmt.Errorf("not a design file: %s (supported: .ai, .psd, .sketch, .fig, .xd, .blend)", path)
 	}
+	if rule := scanner.MatchResidue(absPath, false); rule != nil {
+		return fmt.Errorf("%s is a %s %s, not a design source (see 'dgit clean')", path, rule.App, rule.Reason)
+	}
 
-	// Get relative path from current directory
-	currentDir, _ := os.Getwd()
-	relPath, err := filepath.Rel(currentDir, absPath)
+	// Paths are stored relative to the working tree root so history matches on every machine
+	relPath, err := s.repoRelative(absPath)
 	if err != nil {
-		relPath = absPath
+		return err
 	}
 
 	// Generate file hash for cache key
@@ -179,8 +229,12 @@ func (s *StagingArea) AddFile(path string) error {
 		return fmt.Errorf("failed to generate file hash: %w", err)
 	}
 
-	// Determine cache level based on file characteristics
-	cacheLevel := s.determineCacheLevel(absPath, fileInfo.Size())
+	// Content already cached by an earlier add is reused instead of being cached again
+	cacheLevel, reused := s.cachedLevel(hash)
+	if !reused {
+		// Determine cache level based on file characteristics
+		cacheLevel = s.determineCacheLevel(absPath, fileInfo.Size())
+	}
 	
 	// Create staged file entry with ultra-fast cache integration
 	stagedFile := &StagedFile{
@@ -192,23 +246,72 @@ func (s *StagingArea) AddFile(path string) error {
 		AddedAt:       time.Now(),
 		Hash:          hash,
 		CacheLevel:    cacheLevel,
-		PreCompressed: false,
+		PreCompressed: reused && cacheLevel == "hot",
 	}
 
-	// Pre-process for ultra-fast commits
-	if err := s.preprocessFile(stagedFile); err != nil {
-		fmt.Printf("Warning: failed to preprocess %s: %v\n", path, err)
+	if reused {
+		s.recordCacheHit(cacheLevel)
+		s.extractMetadata(stagedFile)
+	} else {
+		s.cacheStats.NewFiles++
+		// Pre-process for ultra-fast commits
+		if err := s.preprocessFile(stagedFile); err != nil {
+			fmt.Printf("Warning: failed to preprocess %s: %v\n", path, err)
+		}
 	}
 
 	s.files[absPath] = stagedFile
 	
 	processingTime := time.Since(startTime)
+	if reused {
+		fmt.Printf("Added %s, reused cached copy from %s cache (processed in %v)\n",
+			filepath.Base(path), cacheLevel, processingTime)
+		return nil
+	}
 	fmt.Printf("Added %s to %s cache (processed in %v)\n", 
 		filepath.Base(path), cacheLevel, processingTime)
 	
 	return nil
 }
 
+// cachedLevel returns the tier that already holds content with this hash, checking hot first
+// Warm and cold entries are symlinks to the source file, so they only count while it still has that content
+func (s *StagingArea) cachedLevel(hash string) (string, bool) {
+	for _, level := range []string{"hot", "warm", "cold"} {
+		cachePath := s.getCachePath(hash, level)
+		info, err := os.Lstat(cachePath)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if current, err := s.generateFileHash(cachePath); err != nil || current != hash {
+				continue
+			}
+		} else if info.Size() == 0 {
+			continue
+		}
+		return level, true
+	}
+	return "", false
+}
+
+// recordCacheHit counts a reused cache entry against its tier
+func (s *StagingArea) recordCacheHit(level string) {
+	switch level {
+	case "hot":
+		s.cacheStats.HotCacheHits++
+	case "warm":
+		s.cacheStats.WarmCacheHits++
+	case "cold":
+		s.cacheStats.ColdCacheHits++
+	}
+}
+
+// Hits returns the number of adds served from any cache tier
+func (c *CacheStats) Hits() int {
+	return c.HotCacheHits + c.WarmCacheHits + c.ColdCacheHits
+}
+
 // preprocessFile performs ultra-fast preprocessing for 0.2s commits
 func (s *StagingArea) preprocessFile(file *StagedFile) error {
 	// LZ4 Pre-compression for hot cache
@@ -221,16 +324,21 @@ func (s *StagingArea) preprocessFile(file *StagedFile) error {
 	}
 
 	// Extract metadata for instant commit info
+	s.extractMetadata(file)
+
+	// Cache file in appropriate tier
+	return s.cacheFileInTier(file)
+}
+
+// extractMetadata attaches pre-extracted design metadata to a staged file
+func (s *StagingArea) extractMetadata(file *StagedFile) {
 	metadata, err := s.extractDesignFileMetadata(file.AbsolutePath, file.FileType)
 	if err != nil {
 		fmt.Printf("Warning: failed to extract metadata from %s: %v\n", file.Path, err)
-	} else {
-		file.Metadata = metadata
-		s.cacheStats.MetadataExtracted++
+		return
 	}
-
-	// Cache file in appropriate tier
-	return s.cacheFileInTier(file)
+	file.Metadata = metadata
+	s.cacheStats.MetadataExtracted++
 }
 
 // createLZ4PrecompressedCache creates LZ4 compressed cache for 0.2s access
@@ -255,7 +363,7 @@ func (s *StagingArea) createLZ4PrecompressedCache(file *StagedFile) error {
 	lz4Writer.Apply(lz4.CompressionLevelOption(lz4.Level1))
 	
 	// Stream copy with proper error handling
-	written, err := io.Copy(lz4Writer, srcFile)
+	written, err := io.Copy(lz4Writer, s.io.Reader(srcFile))
 	if err != nil {
 		lz4Writer.Close()
 		os.Remove(cachePath)
@@ -464,12 +572,13 @@ func (s *StagingArea) generateFileHash(path string) (string, error) {
 }
 
 // AddPattern adds files matching a pattern to staging area with ultra-fast processing
+// The pattern is a file, a folder (added recursively) or a glob; "**" matches any number of folders
 func (s *StagingArea) AddPattern(pattern string) (*AddResult, error) {
 	startTime := time.Now()
 	
-	if pattern == "." {
-		// Add all design files in current directory
-		result, err := s.addAllDesignFiles(".")
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		// Add all design files in the folder
+		result, err := s.addAllDesignFiles(pattern, nil)
 		if result != nil {
 			result.ProcessingTime = time.Since(startTime)
 			result.CacheStats = s.cacheStats
@@ -477,6 +586,25 @@ func (s *StagingArea) AddPattern(pattern string) (*AddResult, error) {
 		return result, err
 	}
 
+	if strings.Contains(pattern, "**") {
+		// filepath.Glob has no "**"; walk from the folder before the first wildcard instead
+		glob, err := scanner.GlobPattern(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		result, err := s.addAllDesignFiles(globBase(pattern), func(path string) bool {
+			return glob.MatchString(filepath.ToSlash(path))
+		})
+		if result != nil {
+			result.ProcessingTime = time.Since(startTime)
+			result.CacheStats = s.cacheStats
+		}
+		if err != nil {
+			return nil, fmt.Errorf("no design files found matching pattern: %s", pattern)
+		}
+		return result, nil
+	}
+
 	// Handle glob patterns
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
@@ -494,17 +622,32 @@ func (s *StagingArea) AddPattern(pattern string) (*AddResult, error) {
 	}
 
 	for _, match := range matches {
+		if isDesignFile(match) && s.Ignore.Ignored(match, false) {
+			result.SkippedIgnored = append(result.SkippedIgnored, match)
+			continue
+		}
+		if isDesignFile(match) && s.Exclude.Ignored(match, false) {
+			result.SkippedExcluded = append(result.SkippedExcluded, match)
+			continue
+		}
+		if isDesignFile(match) && scanner.IsResidue(match) {
+			result.SkippedResidue = append(result.SkippedResidue, match)
+			continue
+		}
 		if isDesignFile(match) {
+			hits := s.cacheStats.Hits()
 			if err := s.AddFile(match); err != nil {
 				result.FailedFiles[match] = err
 			} else {
 				result.AddedFiles = append(result.AddedFiles, match)
-				s.cacheStats.NewFiles++
+				if s.cacheStats.Hits() > hits {
+					result.ReusedFiles = append(result.ReusedFiles, match)
+				}
 			}
 		}
 	}
 
-	if len(result.AddedFiles) == 0 {
+	if result.empty() {
 		return nil, fmt.Errorf("no design files found matching pattern: %s", pattern)
 	}
 
@@ -513,12 +656,14 @@ func (s *StagingArea) AddPattern(pattern string) (*AddResult, error) {
 }
 
 // addAllDesignFiles recursively adds all design files with ultra-fast processing
-func (s *StagingArea) addAllDesignFiles(dir string) (*AddResult, error) {
+// match, when set, limits the walk to files whose path it accepts
+func (s *StagingArea) addAllDesignFiles(dir string, match func(path string) bool) (*AddResult, error) {
 	result := &AddResult{
 		AddedFiles:  []string{},
 		FailedFiles: make(map[string]error),
 		CacheStats:  s.cacheStats,
 	}
+	moduleDirs := submodule.NewSubmoduleManager(s.DgitDir).ModuleDirs()
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -533,12 +678,58 @@ func (s *StagingArea) addAllDesignFiles(dir string) (*AddResult, error) {
 			return nil
 		}
 
+		// Skip nested repositories (submodules) - they are versioned on their own
+		if info.IsDir() && path != dir {
+			if absPath, err := filepath.Abs(path); err == nil && moduleDirs[absPath] {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, ".dgit")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		if !info.IsDir() && match != nil && !match(path) {
+			return nil
+		}
+
+		// Paths listed in .dgitignore stay out of the staging area
+		if path != dir && s.Ignore.Ignored(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			if isDesignFile(path) {
+				result.SkippedIgnored = append(result.SkippedIgnored, path)
+			}
+			return nil
+		}
+		if path != dir && s.Exclude.Ignored(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			if isDesignFile(path) {
+				result.SkippedExcluded = append(result.SkippedExcluded, path)
+			}
+			return nil
+		}
+
+		// Leave application autosave/temp residue out of the staging area
+		if info.IsDir() && path != dir && scanner.MatchResidue(path, true) != nil {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && isDesignFile(path) && scanner.MatchResidue(path, false) != nil {
+			result.SkippedResidue = append(result.SkippedResidue, path)
+			return nil
+		}
+
 		if !info.IsDir() && isDesignFile(path) {
+			hits := s.cacheStats.Hits()
 			if err := s.AddFile(path); err != nil {
 				result.FailedFiles[path] = err
 			} else {
 				result.AddedFiles = append(result.AddedFiles, path)
-				s.cacheStats.NewFiles++
+				if s.cacheStats.Hits() > hits {
+					result.ReusedFiles = append(result.ReusedFiles, path)
+				}
 			}
 		}
 		return nil
@@ -548,13 +739,37 @@ func (s *StagingArea) addAllDesignFiles(dir string) (*AddResult, error) {
 		return nil, err
 	}
 
-	if len(result.AddedFiles) == 0 {
+	if result.empty() {
 		return nil, fmt.Errorf("no design files found in directory: %s", dir)
 	}
 
 	return result, nil
 }
 
+// empty reports whether an add neither staged nor deliberately skipped anything
+func (r *AddResult) empty() bool {
+	return len(r.AddedFiles) == 0 && len(r.SkippedResidue) == 0 && len(r.SkippedIgnored) == 0 && len(r.SkippedExcluded) == 0
+}
+
+// globBase returns the folder part of a glob before its first wildcard, "." when the glob starts with one
+func globBase(pattern string) string {
+	base := "."
+	for _, part := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if strings.ContainsAny(part, "*?[") {
+			break
+		}
+		if base == "." {
+			base = part
+			if part == "" {
+				base = "/"
+			}
+			continue
+		}
+		base = filepath.Join(base, part)
+	}
+	return base
+}
+
 // GetCacheStats returns current cache performance statistics
 func (s *StagingArea) GetCacheStats() *CacheStats {
 	return s.cacheStats
@@ -572,16 +787,188 @@ func (s *StagingArea) RemoveFile(path string) error {
 		return fmt.Errorf("file not in staging area: %s", path)
 	}
 
-	// Remove from cache
-	if file.Hash != "" {
+	delete(s.files, absPath)
+
+	// Remove from cache unless another staged file reuses the entry
+	if file.Hash != "" && !s.hashStaged(file.Hash) {
 		cachePath := s.getCachePath(file.Hash, file.CacheLevel)
 		os.Remove(cachePath) // Ignore errors for cache cleanup
 	}
+	return nil
+}
 
-	delete(s.files, absPath)
+// UnstagePattern removes staged files matching a path, folder or glob pattern, leaving the working files alone
+// Globs are matched against staged paths, so files deleted since they were added can still be unstaged
+// Returns the repo-relative paths that were unstaged
+func (s *StagingArea) UnstagePattern(pattern string) ([]string, error) {
+	matched, err := s.matchStaged(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	unstaged := make([]string, 0, len(matched))
+	for _, absPath := range matched {
+		path := s.files[absPath].Path
+		if err := s.RemoveFile(absPath); err != nil {
+			return unstaged, err
+		}
+		unstaged = append(unstaged, path)
+	}
+	return unstaged, nil
+}
+
+// SelectPattern returns the staged files matching a path, folder or glob pattern without unstaging them
+// Used by 'dgit commit --only' to commit a subset of the staging area
+func (s *StagingArea) SelectPattern(pattern string) ([]*StagedFile, error) {
+	matched, err := s.matchStaged(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*StagedFile, 0, len(matched))
+	for _, absPath := range matched {
+		files = append(files, s.files[absPath])
+	}
+	return files, nil
+}
+
+// matchStaged returns the sorted absolute paths of staged files matching a path, folder or glob pattern
+func (s *StagingArea) matchStaged(pattern string) ([]string, error) {
+	absPattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	var matched []string
+	for absPath := range s.files {
+		globbed, _ := filepath.Match(absPattern, absPath)
+		if absPath == absPattern || globbed || strings.HasPrefix(absPath, absPattern+string(filepath.Separator)) {
+			matched = append(matched, absPath)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no staged files match: %s", pattern)
+	}
+	sort.Strings(matched)
+	return matched, nil
+}
+
+// RefreshFiles brings the staged entries for paths back in line with their working files after a restore rewrote them
+// Entries whose file is gone or now matches committed (repo-relative path -> SHA256) are dropped; other changed files are staged again
+// Returns the repo-relative paths that were re-staged and dropped
+func (s *StagingArea) RefreshFiles(paths []string, committed map[string]string) (restaged, dropped []string, err error) {
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return restaged, dropped, fmt.Errorf("failed to get absolute path: %w", err)
+		}
+		staged, ok := s.files[absPath]
+		if !ok {
+			continue
+		}
+
+		info, statErr := os.Stat(absPath)
+		if statErr != nil {
+			dropped = append(dropped, staged.Path)
+			if err := s.RemoveFile(absPath); err != nil {
+				return restaged, dropped, err
+			}
+			continue
+		}
+		if info.Size() == staged.Size && info.ModTime().Equal(staged.ModTime) {
+			continue // Untouched since it was staged
+		}
+
+		fullHash, err := fullFileHash(absPath)
+		if err != nil {
+			return restaged, dropped, fmt.Errorf("failed to hash %s: %w", staged.Path, err)
+		}
+		renamedFrom := staged.RenamedFrom
+		if err := s.RemoveFile(absPath); err != nil {
+			return restaged, dropped, err
+		}
+		if committed[staged.Path] == fullHash && renamedFrom == "" {
+			dropped = append(dropped, staged.Path)
+			continue
+		}
+		if err := s.AddFile(absPath); err != nil {
+			return restaged, dropped, err
+		}
+		s.files[absPath].RenamedFrom = renamedFrom
+		restaged = append(restaged, staged.Path)
+	}
+	return restaged, dropped, nil
+}
+
+// fullFileHash returns the SHA256 of a whole file, the hash commits record
+// generateFileHash only reads the first 64KB, so it cannot be compared with committed hashes
+func fullFileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashStaged reports whether any staged file has this content hash
+func (s *StagingArea) hashStaged(hash string) bool {
+	for _, file := range s.files {
+		if file.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// StageRename stages a file that was moved from oldPath to newPath in the working tree
+// A staged entry for the old path is dropped; chained moves keep the original history path
+func (s *StagingArea) StageRename(oldPath, newPath string) error {
+	oldAbs, err := filepath.Abs(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	newAbs, err := filepath.Abs(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	renamedFrom, err := s.repoRelative(oldAbs)
+	if err != nil {
+		return err
+	}
+	if staged, ok := s.files[oldAbs]; ok {
+		if staged.RenamedFrom != "" {
+			renamedFrom = staged.RenamedFrom
+		}
+		delete(s.files, oldAbs)
+	}
+
+	if err := s.AddFile(newAbs); err != nil {
+		return err
+	}
+	s.files[newAbs].RenamedFrom = renamedFrom
 	return nil
 }
 
+// repoRelative converts an absolute path to the slash-separated path relative to the working tree root
+func (s *StagingArea) repoRelative(absPath string) (string, error) {
+	dgitDir, err := filepath.Abs(s.DgitDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	relPath, err := filepath.Rel(filepath.Dir(dgitDir), absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) || relPath == ".." {
+		return "", fmt.Errorf("%s is outside the repository", absPath)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
 // GetStagedFiles returns all files in the staging area
 func (s *StagingArea) GetStagedFiles() []*StagedFile {
 	files := make([]*StagedFile, 0, len(s.files))
@@ -630,9 +1017,10 @@ func (s *StagingArea) HasFile(path string) bool {
 func isDesignFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	supportedExts := []string{
-		".ai", ".psd", ".sketch", ".fig", ".xd",
+		".ai", ".psd", ".sketch", ".fig", ".figma", ".xd",
 		".afdesign", ".afphoto", ".blend", ".c4d",
 		".max", ".mb", ".ma", ".fbx", ".obj",
+		".exr",
 	}
 	
 	for _, supportedExt := range supportedExts {
@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// ForecastWindow is how far back commit cadence is measured
+const ForecastWindow = 90 * 24 * time.Hour
+
+// ForecastHorizons are the default projection distances in days
+var ForecastHorizons = []int{30, 90, 365}
+
+// Projection is the expected repository size some days from now
+type Projection struct {
+	Days int
+	Size int64
+}
+
+// Forecast projects repository growth from recent commit history
+type Forecast struct {
+	CurrentSize     int64
+	WindowStart     time.Time // Oldest commit used to measure cadence
+	WindowCommits   int
+	CommitsPerDay   float64
+	BytesPerCommit  int64 // Average stored (compressed) size of a commit
+	BytesPerDay     float64
+	Projections     []*Projection
+	Budget          int64 // 0 when no budget was given
+	DaysUntilBudget int   // -1 when the budget is never reached at this cadence
+	Suggestions     []string
+}
+
+// StatsManager computes repository statistics and reports
+type StatsManager struct {
+	DgitDir string
+}
+
+// NewStatsManager creates a new statistics manager for the given .dgit directory
+func NewStatsManager(dgitDir string) *StatsManager {
+	return &StatsManager{DgitDir: dgitDir}
+}
+
+// Forecast projects repository size over the given horizons at the recent commit cadence
+// With a budget, it also estimates when the budget is reached and which settings would keep within it
+func (sm *StatsManager) Forecast(horizons []int, budget int64) (*Forecast, error) {
+	logManager := log.NewLogManager(sm.DgitDir)
+	breakdown, err := logManager.GetRepositorySizeBreakdown()
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure repository size: %w", err)
+	}
+
+	forecast := &Forecast{CurrentSize: breakdown.Total, Budget: budget, DaysUntilBudget: -1}
+
+	// Measure cadence over the recent window (or the whole history if younger)
+	it, err := logManager.Iterate(log.CommitFilter{Since: time.Now().Add(-ForecastWindow)})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var windowBytes int64
+	for it.Next() {
+		c := it.Commit()
+		forecast.WindowCommits++
+		windowBytes += storedSize(c)
+		if forecast.WindowStart.IsZero() || c.Timestamp.Before(forecast.WindowStart) {
+			forecast.WindowStart = c.Timestamp
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	if forecast.WindowCommits > 0 {
+		days := math.Max(time.Since(forecast.WindowStart).Hours()/24, 1)
+		forecast.CommitsPerDay = float64(forecast.WindowCommits) / days
+		forecast.BytesPerCommit = windowBytes / int64(forecast.WindowCommits)
+		forecast.BytesPerDay = float64(windowBytes) / days
+	}
+
+	for _, days := range horizons {
+		forecast.Projections = append(forecast.Projections, &Projection{
+			Days: days,
+			Size: forecast.CurrentSize + int64(forecast.BytesPerDay*float64(days)),
+		})
+	}
+
+	if budget > 0 {
+		if forecast.CurrentSize >= budget {
+			forecast.DaysUntilBudget = 0
+		} else if forecast.BytesPerDay > 0 {
+			forecast.DaysUntilBudget = int(float64(budget-forecast.CurrentSize) / forecast.BytesPerDay)
+		}
+		forecast.Suggestions = sm.suggestRetention(forecast, breakdown)
+	}
+	return forecast, nil
+}
+
+// suggestRetention proposes cache retention and archive settings that keep growth within the budget
+func (sm *StatsManager) suggestRetention(forecast *Forecast, breakdown *log.SizeBreakdown) []string {
+	var suggestions []string
+	if forecast.DaysUntilBudget == -1 {
+		return append(suggestions, "At the current cadence the repository stays within budget; no changes needed")
+	}
+
+	config, err := initializer.GetRepositoryConfig(sm.DgitDir)
+	if err != nil {
+		return suggestions
+	}
+
+	// Days of new history that fit in the budget once the existing data is accounted for
+	headroomDays := forecast.DaysUntilBudget
+	archiveAfter := config.Compression.ArchiveConfig.ArchiveAfterDays
+	if !config.Compression.ArchiveConfig.Enabled {
+		suggestions = append(suggestions, "Enable archive_stage so old versions are recompressed at maximum zstd level")
+	}
+	if headroomDays < 365 && (archiveAfter == 0 || archiveAfter > max(headroomDays/2, 1)) {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"Lower archive_stage.archive_after_days from %d to %d so versions are archived before the budget is reached",
+			archiveAfter, max(headroomDays/2, 1)))
+	}
+
+	retentionHours := config.Compression.LZ4Config.CacheRetention
+	if breakdown.HotCache > forecast.Budget/4 && retentionHours > 12 {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"Hot cache holds %.1f MB; lower lz4_stage.cache_retention from %dh to 12h to move versions to the warm tier sooner",
+			float64(breakdown.HotCache)/(1024*1024), retentionHours))
+	}
+
+	if forecast.DaysUntilBudget == 0 {
+		suggestions = append(suggestions, "The repository is already over budget; archive or remove old versions, or raise the budget")
+	} else if forecast.DaysUntilBudget < 30 {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"Only %d days of headroom remain; consider moving history older than %d days to external storage",
+			forecast.DaysUntilBudget, max(forecast.DaysUntilBudget, 7)))
+	}
+	return suggestions
+}
+
+// storedSize returns the bytes a commit added to storage, falling back to its file sizes
+func storedSize(c *log.Commit) int64 {
+	if c.CompressionInfo != nil && c.CompressionInfo.CompressedSize > 0 {
+		return c.CompressionInfo.CompressedSize
+	}
+	var total int64
+	for _, metadata := range c.Metadata {
+		if metaMap, ok := metadata.(map[string]interface{}); ok {
+			if size, ok := metaMap["size"].(float64); ok {
+				total += int64(size)
+			}
+		}
+	}
+	return total
+}
+
+// ParseSize parses a human-readable size such as "500MB", "10 GB" or "2048"
+func ParseSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"T", 1 << 40},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	scale := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			scale = unit.scale
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (use e.g. 500MB or 10GB)", value)
+	}
+	return int64(number * scale), nil
+}
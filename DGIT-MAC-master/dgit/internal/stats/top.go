@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+)
+
+// storageVersionPattern extracts the version from storage file names such as v12.lz4 or v12_from_v10.bsdiff
+var storageVersionPattern = regexp.MustCompile(`^v(\d+)[._]`)
+
+// ObjectSize is one stored file
+type ObjectSize struct {
+	Path    string // Relative to .dgit
+	Size    int64
+	Version int // 0 when the file does not belong to a single version
+}
+
+// FileActivity counts how often a design file was committed
+type FileActivity struct {
+	Path        string
+	Commits     int
+	LastVersion int
+	LatestSize  int64
+}
+
+// FileCompression is how well a design file compresses across its commits
+type FileCompression struct {
+	Path           string
+	Commits        int
+	OriginalSize   int64
+	CompressedSize int64
+}
+
+// Ratio returns compressed/original size (1.0 means no savings)
+func (fc *FileCompression) Ratio() float64 {
+	if fc.OriginalSize == 0 {
+		return 1
+	}
+	return float64(fc.CompressedSize) / float64(fc.OriginalSize)
+}
+
+// TopReport lists what takes the most space and churn in a repository
+type TopReport struct {
+	LargestObjects  []*ObjectSize
+	MostModified    []*FileActivity
+	WorstCompressed []*FileCompression
+}
+
+// Top builds the largest-object and hot-file report, keeping n entries per list
+// Compression is recorded per commit, so each file is credited with its commit's ratio weighted by its size
+func (sm *StatsManager) Top(n int) (*TopReport, error) {
+	report := &TopReport{LargestObjects: sm.largestObjects()}
+
+	it, err := log.NewLogManager(sm.DgitDir).Iterate(log.CommitFilter{})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	activity := make(map[string]*FileActivity)
+	compression := make(map[string]*FileCompression)
+	for it.Next() {
+		c := it.Commit()
+		for path, metadata := range c.Metadata {
+			size := int64(0)
+			if metaMap, ok := metadata.(map[string]interface{}); ok {
+				if value, ok := metaMap["size"].(float64); ok {
+					size = int64(value)
+				}
+			}
+
+			a := activity[path]
+			if a == nil {
+				// Iteration runs from the newest version, so the first sighting is the latest
+				a = &FileActivity{Path: path, LastVersion: c.Version, LatestSize: size}
+				activity[path] = a
+			}
+			a.Commits++
+
+			if c.CompressionInfo == nil || c.CompressionInfo.OriginalSize == 0 || size == 0 {
+				continue
+			}
+			fc := compression[path]
+			if fc == nil {
+				fc = &FileCompression{Path: path}
+				compression[path] = fc
+			}
+			fc.Commits++
+			fc.OriginalSize += size
+			fc.CompressedSize += int64(float64(size) * c.CompressionInfo.CompressionRatio)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, a := range activity {
+		report.MostModified = append(report.MostModified, a)
+	}
+	sort.Slice(report.MostModified, func(i, j int) bool {
+		if report.MostModified[i].Commits != report.MostModified[j].Commits {
+			return report.MostModified[i].Commits > report.MostModified[j].Commits
+		}
+		return report.MostModified[i].Path < report.MostModified[j].Path
+	})
+
+	for _, fc := range compression {
+		report.WorstCompressed = append(report.WorstCompressed, fc)
+	}
+	sort.Slice(report.WorstCompressed, func(i, j int) bool {
+		if report.WorstCompressed[i].Ratio() != report.WorstCompressed[j].Ratio() {
+			return report.WorstCompressed[i].Ratio() > report.WorstCompressed[j].Ratio()
+		}
+		return report.WorstCompressed[i].OriginalSize > report.WorstCompressed[j].OriginalSize
+	})
+
+	report.LargestObjects = truncate(report.LargestObjects, n)
+	report.MostModified = truncate(report.MostModified, n)
+	report.WorstCompressed = truncate(report.WorstCompressed, n)
+	return report, nil
+}
+
+// largestObjects lists stored snapshot, delta and cache files, largest first
+func (sm *StatsManager) largestObjects() []*ObjectSize {
+	var objects []*ObjectSize
+	for _, dir := range []string{"objects", "cache"} {
+		root := filepath.Join(sm.DgitDir, dir)
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || filepath.Ext(path) == ".json" {
+				return nil
+			}
+			rel, _ := filepath.Rel(sm.DgitDir, path)
+			object := &ObjectSize{Path: rel, Size: info.Size()}
+			if match := storageVersionPattern.FindStringSubmatch(info.Name()); match != nil {
+				object.Version, _ = strconv.Atoi(match[1])
+			}
+			objects = append(objects, object)
+			return nil
+		})
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Size != objects[j].Size {
+			return objects[i].Size > objects[j].Size
+		}
+		return objects[i].Path < objects[j].Path
+	})
+	return objects
+}
+
+// truncate keeps at most n entries (n <= 0 keeps all)
+func truncate[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -2,13 +2,16 @@ package status
 
 import (
 	"archive/zip"
+	"bytes"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
 	"github.com/kr/binarydist"
 )
 
@@ -30,7 +33,23 @@ func NewStatusManager(dgitDir string) *StatusManager {
 }
 
 // GetSnapshotFileHashes loads a commit's files and returns a map of file paths to their SHA256 hashes
+// Paths are repo-relative even when an older commit recorded them in another form
 func (sm *StatusManager) GetSnapshotFileHashes(commitVersion int) (map[string]string, error) {
+	hashes, err := sm.snapshotFileHashes(commitVersion)
+	if err != nil {
+		return nil, err
+	}
+	dgitDir, _ := filepath.Abs(sm.DgitDir)
+	normalized := make(map[string]string, len(hashes))
+	for stored, hash := range hashes {
+		path, _ := log.RepoPath(filepath.Dir(dgitDir), stored)
+		normalized[path] = hash
+	}
+	return normalized, nil
+}
+
+// snapshotFileHashes reads the file hashes of a commit with the paths its snapshot recorded
+func (sm *StatusManager) snapshotFileHashes(commitVersion int) (map[string]string, error) {
 	// Load commit information to determine storage method
 	logManager := log.NewLogManager(sm.DgitDir)
 	commit, err := logManager.GetCommit(commitVersion)
@@ -44,9 +63,19 @@ func (sm *StatusManager) GetSnapshotFileHashes(commitVersion int) (map[string]st
 		case "zip":
 			// Direct ZIP extraction
 			return sm.extractHashesFromZip(commit.CompressionInfo.OutputFile)
-		case "bsdiff", "xdelta3":
+		case "bsdiff":
 			// Delta chain restoration
 			return sm.extractHashesFromDeltaChain(commitVersion)
+		case "xdelta3":
+			// VCDIFF deltas rebuild the whole snapshot stream
+			return sm.extractHashesFromXdelta(commitVersion)
+		case "content":
+			// Object store commits record each file's hash directly
+			fileHashes := make(map[string]string, len(commit.Blobs))
+			for path, ref := range commit.Blobs {
+				fileHashes[path] = ref.Hash
+			}
+			return fileHashes, nil
 		}
 	}
 	
@@ -112,6 +141,28 @@ func (sm *StatusManager) extractHashesFromDeltaChain(targetVersion int) (map[str
 	return sm.extractHashesFromTempZip(tempFile)
 }
 
+// extractHashesFromXdelta rebuilds an xdelta3 version's snapshot stream and hashes each file in it
+func (sm *StatusManager) extractHashesFromXdelta(version int) (map[string]string, error) {
+	stream, err := restore.NewRestoreManager(sm.DgitDir).SnapshotStream(version)
+	if err != nil {
+		return make(map[string]string), fmt.Errorf("failed to rebuild v%d: %w", version, err)
+	}
+	
+	fileHashes := make(map[string]string)
+	err = codec.EachFrame(bytes.NewReader(stream), func(header *codec.FrameHeader, data io.Reader) error {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, data); err != nil {
+			return err
+		}
+		fileHashes[header.Path] = fmt.Sprintf("%x", hash.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return make(map[string]string), fmt.Errorf("failed to read v%d snapshot: %w", version, err)
+	}
+	return fileHashes, nil
+}
+
 // findRestorationPath finds the sequence of operations to restore a version
 func (sm *StatusManager) findRestorationPath(targetVersion int) ([]RestorationStep, error) {
 	var path []RestorationStep
@@ -275,7 +326,7 @@ func (sm *StatusManager) extractHashesFromTempZip(tempZipPath string) (map[strin
 
 // RestorationStep represents a single step in restoration process
 type RestorationStep struct {
-	Type    string // "zip", "bsdiff", "xdelta3"
+	Type    string // "zip", "bsdiff"
 	File    string
 	Version int
 }
@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// ErrNotFound is returned when a backend does not hold a key
+var ErrNotFound = errors.New("object not found in storage")
+
+// Backend keeps repository files somewhere other than this machine
+// Keys are slash-separated paths relative to .dgit, e.g. "cache/cold/v3.archive.zstd"
+type Backend interface {
+	Name() string
+	Describe() string // Human-readable location, e.g. "s3://bucket/prefix"
+	Put(key string, r io.Reader, size int64) error
+	Get(key string) (io.ReadCloser, error)
+	Stat(key string) (int64, error) // Size of a stored key, or ErrNotFound
+	Delete(key string) error
+}
+
+// Factory builds a backend from the repository's storage settings
+type Factory func(dgitDir string, config initializer.StorageConfig) (Backend, error)
+
+var registry = map[string]Factory{}
+
+// Register makes a backend available to the storage.backend setting
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Available returns the registered backend names in sorted order
+func Available() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("dir", newDirBackend)
+	Register("s3", newS3Backend)
+}
+
+// Open returns the backend configured for a repository, or nil when everything stays local
+func Open(dgitDir string) (Backend, error) {
+	config, err := GetConfig(dgitDir)
+	if err != nil || config.Backend == "" {
+		return nil, err
+	}
+	return New(dgitDir, config)
+}
+
+// New builds the backend a storage config names
+func New(dgitDir string, config initializer.StorageConfig) (Backend, error) {
+	factory, ok := registry[config.Backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q (available: %s)", config.Backend, strings.Join(Available(), ", "))
+	}
+	return factory(dgitDir, config)
+}
+
+// GetConfig returns the repository's storage settings
+func GetConfig(dgitDir string) (initializer.StorageConfig, error) {
+	config, err := initializer.GetRepositoryConfig(dgitDir)
+	if err != nil {
+		return initializer.StorageConfig{}, err
+	}
+	return config.Storage, nil
+}
+
+// SetConfig validates and stores the storage settings; an empty backend turns remote storage off
+func SetConfig(dgitDir string, storage initializer.StorageConfig) error {
+	if storage.Backend != "" {
+		if _, err := New(dgitDir, storage); err != nil {
+			return err
+		}
+	}
+	config, err := initializer.GetRepositoryConfig(dgitDir)
+	if err != nil {
+		return err
+	}
+	config.Storage = storage
+	return initializer.UpdateRepositoryConfig(dgitDir, config)
+}
+
+// Key returns the backend key of a path relative to .dgit
+func Key(rel string) string {
+	return filepath.ToSlash(rel)
+}
+
+// Upload copies a file under .dgit to the backend, skipping it when the backend already holds the same size
+// Returns the bytes sent
+func Upload(backend Backend, dgitDir, rel string) (int64, error) {
+	path := filepath.Join(dgitDir, rel)
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", rel, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if size, err := backend.Stat(Key(rel)); err == nil && size == info.Size() {
+		return 0, nil
+	}
+	if err := backend.Put(Key(rel), file, info.Size()); err != nil {
+		return 0, fmt.Errorf("failed to upload %s to %s: %w", rel, backend.Describe(), err)
+	}
+	return info.Size(), nil
+}
+
+// Download fetches a key into its place under .dgit through a temporary file, so readers never see part of it
+// Returns the bytes received
+func Download(backend Backend, dgitDir, rel string) (int64, error) {
+	reader, err := backend.Get(Key(rel))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s from %s: %w", rel, backend.Describe(), err)
+	}
+	defer reader.Close()
+
+	target := filepath.Join(dgitDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", filepath.Dir(rel), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".incoming-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	size, err := io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch %s from %s: %w", rel, backend.Describe(), err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return 0, fmt.Errorf("failed to store %s: %w", rel, err)
+	}
+	return size, nil
+}
+
+// dirBackend stores files in a folder, typically a mounted NAS share
+type dirBackend struct {
+	root string
+}
+
+// newDirBackend creates a dir backend rooted at storage.path
+func newDirBackend(dgitDir string, config initializer.StorageConfig) (Backend, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("the dir storage backend needs a path")
+	}
+	root, err := filepath.Abs(config.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &dirBackend{root: root}, nil
+}
+
+func (d *dirBackend) Name() string     { return "dir" }
+func (d *dirBackend) Describe() string { return d.root }
+
+func (d *dirBackend) Put(key string, r io.Reader, size int64) error {
+	target := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".incoming-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("wrote %d of %d bytes", written, size)
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (d *dirBackend) Get(key string) (io.ReadCloser, error) {
+	file, err := os.Open(d.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (d *dirBackend) Stat(key string) (int64, error) {
+	info, err := os.Stat(d.path(key))
+	if os.IsNotExist(err) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (d *dirBackend) Delete(key string) error {
+	if err := os.Remove(d.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path maps a key to its file under the root
+func (d *dirBackend) path(key string) string {
+	return filepath.Join(d.root, filepath.FromSlash(key))
+}
@@ -0,0 +1,336 @@
+package submodule
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// ModulesFile is the name of the file pinning nested repositories
+// Lives in the working tree root next to the .dgit directory, like Git's .gitmodules
+const ModulesFile = ".dgitmodules"
+
+// Module represents a nested DGit repository pinned to a specific version
+// Used to embed shared asset libraries inside larger design projects
+type Module struct {
+	Path    string `json:"path"`    // Path of the module relative to the working tree root
+	URL     string `json:"url"`     // Location of the source DGit repository (working tree path)
+	Version int    `json:"version"` // Pinned version of the source repository
+}
+
+// ModulesConfig represents the contents of the .dgitmodules file
+type ModulesConfig struct {
+	Modules []*Module `json:"modules"`
+}
+
+// CheckoutState records which version of each module is currently in the working tree
+// Stored inside .dgit so it never travels with the pinned configuration
+type CheckoutState struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ModuleStatus describes the state of a single module for status reporting
+type ModuleStatus struct {
+	Module          *Module
+	CheckedOut      int      // Version currently checked out (0 if never updated)
+	MissingFiles    []string // Files from the pinned version missing in the working tree
+	ModifiedFiles   []string // Files whose size differs from the pinned version
+	SourceAvailable bool     // Whether the source repository could be opened
+}
+
+// IsUpToDate reports whether the module working tree matches its pinned version
+func (ms *ModuleStatus) IsUpToDate() bool {
+	return ms.SourceAvailable && ms.CheckedOut == ms.Module.Version &&
+		len(ms.MissingFiles) == 0 && len(ms.ModifiedFiles) == 0
+}
+
+// SubmoduleManager handles nested repository references for a DGit repository
+type SubmoduleManager struct {
+	DgitDir     string
+	RootDir     string
+	ModulesPath string
+	StatePath   string
+}
+
+// NewSubmoduleManager creates a new submodule manager for the given .dgit directory
+func NewSubmoduleManager(dgitDir string) *SubmoduleManager {
+	rootDir := filepath.Dir(dgitDir)
+	return &SubmoduleManager{
+		DgitDir:     dgitDir,
+		RootDir:     rootDir,
+		ModulesPath: filepath.Join(rootDir, ModulesFile),
+		StatePath:   filepath.Join(dgitDir, "modules", "state.json"),
+	}
+}
+
+// LoadModules reads the .dgitmodules file (returns an empty config if absent)
+func (sm *SubmoduleManager) LoadModules() (*ModulesConfig, error) {
+	config := &ModulesConfig{Modules: []*Module{}}
+
+	data, err := os.ReadFile(sm.ModulesPath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ModulesFile, err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ModulesFile, err)
+	}
+	return config, nil
+}
+
+// SaveModules writes the .dgitmodules file with modules sorted by path
+func (sm *SubmoduleManager) SaveModules(config *ModulesConfig) error {
+	sort.Slice(config.Modules, func(i, j int) bool {
+		return config.Modules[i].Path < config.Modules[j].Path
+	})
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", ModulesFile, err)
+	}
+	if err := os.WriteFile(sm.ModulesPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ModulesFile, err)
+	}
+	return nil
+}
+
+// AddModule pins a source repository at the given path
+// A version of 0 pins the latest version of the source repository
+func (sm *SubmoduleManager) AddModule(url, path string, version int) (*Module, error) {
+	absURL, err := filepath.Abs(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository path: %w", err)
+	}
+
+	sourceDgit := filepath.Join(absURL, ".dgit")
+	if info, err := os.Stat(sourceDgit); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a dgit repository: %s", url)
+	}
+
+	if version == 0 {
+		version = log.NewLogManager(sourceDgit).GetCurrentVersion()
+		if version == 0 {
+			return nil, fmt.Errorf("repository %s has no commits", url)
+		}
+	} else if _, err := log.NewLogManager(sourceDgit).GetCommit(version); err != nil {
+		return nil, fmt.Errorf("version %d not found in %s", version, url)
+	}
+
+	relPath, err := sm.relativeToRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := sm.LoadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range config.Modules {
+		if m.Path == relPath {
+			return nil, fmt.Errorf("submodule already exists at %s", relPath)
+		}
+	}
+
+	module := &Module{Path: relPath, URL: absURL, Version: version}
+	config.Modules = append(config.Modules, module)
+
+	if err := sm.SaveModules(config); err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// SetVersion re-pins an existing module to a different source version
+func (sm *SubmoduleManager) SetVersion(path string, version int) (*Module, error) {
+	relPath, err := sm.relativeToRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := sm.LoadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range config.Modules {
+		if m.Path != relPath {
+			continue
+		}
+		if _, err := log.NewLogManager(filepath.Join(m.URL, ".dgit")).GetCommit(version); err != nil {
+			return nil, fmt.Errorf("version %d not found in %s", version, m.URL)
+		}
+		m.Version = version
+		return m, sm.SaveModules(config)
+	}
+	return nil, fmt.Errorf("no submodule at %s", relPath)
+}
+
+// Update checks out the pinned version of the module into its path
+// Files are restored from the source repository using its own restore pipeline
+func (sm *SubmoduleManager) Update(module *Module) error {
+	sourceDgit := filepath.Join(module.URL, ".dgit")
+	if _, err := os.Stat(sourceDgit); err != nil {
+		return fmt.Errorf("source repository not available: %s", module.URL)
+	}
+
+	targetDir := filepath.Join(sm.RootDir, module.Path)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", module.Path, err)
+	}
+
+	commit, err := log.NewLogManager(sourceDgit).GetCommit(module.Version)
+	if err != nil {
+		return fmt.Errorf("version %d not found in %s: %w", module.Version, module.URL, err)
+	}
+
+	restoreManager := restore.NewRestoreManager(sourceDgit)
+	restoreManager.WorkDir = targetDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", module.Version), nil, commit); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", module.Path, err)
+	}
+
+	return sm.recordCheckout(module.Path, module.Version)
+}
+
+// UpdateAll checks out every module, collecting per-module errors
+func (sm *SubmoduleManager) UpdateAll() (map[string]error, error) {
+	config, err := sm.LoadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string]error)
+	for _, module := range config.Modules {
+		if err := sm.Update(module); err != nil {
+			failures[module.Path] = err
+		}
+	}
+	return failures, nil
+}
+
+// GetStatus compares each module's working tree against its pinned version
+func (sm *SubmoduleManager) GetStatus() ([]*ModuleStatus, error) {
+	config, err := sm.LoadModules()
+	if err != nil {
+		return nil, err
+	}
+
+	state := sm.loadState()
+	var statuses []*ModuleStatus
+
+	for _, module := range config.Modules {
+		ms := &ModuleStatus{Module: module}
+		if checkout, ok := state[module.Path]; ok {
+			ms.CheckedOut = checkout.Version
+		}
+
+		commit, err := log.NewLogManager(filepath.Join(module.URL, ".dgit")).GetCommit(module.Version)
+		if err != nil {
+			statuses = append(statuses, ms)
+			continue
+		}
+		ms.SourceAvailable = true
+
+		// Compare pinned file list and sizes with the module working tree
+		for fileName, metadata := range commit.Metadata {
+			info, err := os.Stat(filepath.Join(sm.RootDir, module.Path, fileName))
+			if err != nil {
+				ms.MissingFiles = append(ms.MissingFiles, fileName)
+				continue
+			}
+			if metaMap, ok := metadata.(map[string]interface{}); ok {
+				if size, ok := metaMap["size"].(float64); ok && int64(size) != info.Size() {
+					ms.ModifiedFiles = append(ms.ModifiedFiles, fileName)
+				}
+			}
+		}
+		sort.Strings(ms.MissingFiles)
+		sort.Strings(ms.ModifiedFiles)
+
+		statuses = append(statuses, ms)
+	}
+
+	return statuses, nil
+}
+
+// ModuleDirs returns the absolute directories of all configured modules
+// Used by status and staging to leave module contents to the nested repository
+func (sm *SubmoduleManager) ModuleDirs() map[string]bool {
+	dirs := make(map[string]bool)
+	config, err := sm.LoadModules()
+	if err != nil {
+		return dirs
+	}
+	for _, m := range config.Modules {
+		dirs[filepath.Join(sm.RootDir, filepath.FromSlash(m.Path))] = true
+	}
+	return dirs
+}
+
+// FindModule returns the module registered at the given path
+func (sm *SubmoduleManager) FindModule(path string) (*Module, error) {
+	relPath, err := sm.relativeToRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := sm.LoadModules()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range config.Modules {
+		if m.Path == relPath {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no submodule at %s", relPath)
+}
+
+// relativeToRoot converts a user-supplied path to a slash-separated path relative to the working tree root
+func (sm *SubmoduleManager) relativeToRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(sm.RootDir, absPath)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", fmt.Errorf("submodule path must be inside the repository: %s", path)
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// loadState reads the checkout state of all modules
+func (sm *SubmoduleManager) loadState() map[string]*CheckoutState {
+	state := make(map[string]*CheckoutState)
+	if data, err := os.ReadFile(sm.StatePath); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// recordCheckout stores the version checked out for a module
+func (sm *SubmoduleManager) recordCheckout(path string, version int) error {
+	state := sm.loadState()
+	state[path] = &CheckoutState{Version: version, UpdatedAt: time.Now()}
+
+	if err := os.MkdirAll(filepath.Dir(sm.StatePath), 0755); err != nil {
+		return fmt.Errorf("failed to create modules directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal submodule state: %w", err)
+	}
+	return os.WriteFile(sm.StatePath, data, 0644)
+}
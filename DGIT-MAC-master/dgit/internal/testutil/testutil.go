@@ -0,0 +1,604 @@
+// Package testutil builds synthetic DGit repositories for regression tests
+// Repositories are deterministic: file contents are generated from seeds, so a failing
+// restore/commit/gc scenario can be rebuilt byte-for-byte from its Spec
+package testutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/vcdiff"
+
+	"github.com/kr/binarydist"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Storage strategies a version can be forced into after it is committed
+const (
+	StrategyContent = objstore.Strategy // What commit produces by default (object store blobs)
+	StrategyLZ4     = codec.LZ4         // Hot cache snapshot, as committed with compression.object_store off
+	StrategyZIP     = "zip"             // Plain ZIP object, as written by older releases
+
+	// Delta strategies build on the previous version, whose full snapshot is added to the hot cache if needed
+	StrategyBsdiff   = "bsdiff"          // Binary patch of the previous version's ZIP snapshot
+	StrategyXdelta3  = "xdelta3"         // VCDIFF delta of the previous version's snapshot stream
+	StrategyPSDDelta = "psd_smart_delta" // The version's single changed file in full; the rest comes from its base
+)
+
+// Strategies lists every strategy ForceStrategy can produce
+var Strategies = []string{StrategyContent, StrategyLZ4, StrategyZIP, StrategyBsdiff, StrategyXdelta3, StrategyPSDDelta}
+
+// Corruption is a kind of damage injected into a version's storage object (for object store
+// versions, the blob of its first file)
+type Corruption int
+
+const (
+	CorruptFlipByte Corruption = iota // Invert one byte in the middle of the object
+	CorruptTruncate                   // Cut the object to half its size
+	CorruptMissing                    // Delete the object
+)
+
+// Spec describes a synthetic repository
+type Spec struct {
+	Versions   int            // Number of commits to create
+	Files      int            // Files per version (default 1)
+	FileSize   int            // Bytes per file (default 4096)
+	Ext        string         // File extension (default ".psd")
+	Seed       int64          // Base seed for file contents
+	Strategies map[int]string // Version → strategy to force after committing (a psd_smart_delta version rewrites only its first file)
+}
+
+// fileRecipe records how a committed file's content was generated
+type fileRecipe struct {
+	Size int
+	Seed int64
+}
+
+// Repo is a synthetic repository on disk
+// Every committed file's expected content is remembered so restores can be checked exactly
+type Repo struct {
+	Root    string
+	DgitDir string
+
+	versions map[int]map[string]fileRecipe // Version → path → content recipe
+	working  map[string]fileRecipe         // Current work tree contents written by WriteFile
+}
+
+// NewRepo initializes an empty repository in dir
+func NewRepo(dir string) (*Repo, error) {
+	if err := initializer.NewRepositoryInitializer().InitializeRepository(dir); err != nil {
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	return &Repo{
+		Root:     dir,
+		DgitDir:  filepath.Join(dir, initializer.DGitDir),
+		versions: make(map[int]map[string]fileRecipe),
+		working:  make(map[string]fileRecipe),
+	}, nil
+}
+
+// Build creates a repository in dir following spec
+// Version v rewrites every file with content seeded by (Seed, v, file index), so all versions differ
+func Build(dir string, spec Spec) (*Repo, error) {
+	if spec.Files <= 0 {
+		spec.Files = 1
+	}
+	if spec.FileSize <= 0 {
+		spec.FileSize = 4096
+	}
+	if spec.Ext == "" {
+		spec.Ext = ".psd"
+	}
+
+	repo, err := NewRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	for v := 1; v <= spec.Versions; v++ {
+		var paths []string
+		files := spec.Files
+		if spec.Strategies[v] == StrategyPSDDelta {
+			files = 1 // A smart delta carries one file
+		}
+		for i := 0; i < files; i++ {
+			path := fmt.Sprintf("file_%03d%s", i, spec.Ext)
+			seed := spec.Seed*1000003 + int64(v)*1009 + int64(i)
+			if err := repo.WriteFile(path, spec.FileSize, seed); err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+		if _, err := repo.Commit(fmt.Sprintf("synthetic v%d", v), paths...); err != nil {
+			return nil, err
+		}
+		if strategy, ok := spec.Strategies[v]; ok {
+			if err := repo.ForceStrategy(v, strategy); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return repo, nil
+}
+
+// Content generates the deterministic bytes for a size and seed
+func Content(size int, seed int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(seed)).Read(data)
+	return data
+}
+
+// WriteFile writes generated content to a path relative to the repository root
+func (r *Repo) WriteFile(rel string, size int, seed int64) error {
+	path := filepath.Join(r.Root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, Content(size, seed), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	r.working[filepath.ToSlash(rel)] = fileRecipe{Size: size, Seed: seed}
+	return nil
+}
+
+// Commit stages the given root-relative paths and commits them
+// Staged paths are recorded relative to the working directory, so the commit runs from the root
+func (r *Repo) Commit(message string, paths ...string) (*commit.Commit, error) {
+	previous, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(r.Root); err != nil {
+		return nil, fmt.Errorf("failed to enter repository root: %w", err)
+	}
+	defer os.Chdir(previous)
+
+	stagingArea := staging.NewStagingArea(r.DgitDir)
+	for _, path := range paths {
+		if err := stagingArea.AddFile(filepath.FromSlash(path)); err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	}
+	newCommit, err := commit.NewCommitManager(r.DgitDir).CreateCommit(message, stagingArea.GetStagedFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make(map[string]fileRecipe, len(paths))
+	for _, path := range paths {
+		committed[filepath.ToSlash(path)] = r.working[filepath.ToSlash(path)]
+	}
+	r.versions[newCommit.Version] = committed
+	return newCommit, nil
+}
+
+// Expected returns the SHA256 of every file committed in a version
+func (r *Repo) Expected(version int) map[string]string {
+	expected := make(map[string]string)
+	for path, recipe := range r.versions[version] {
+		expected[path] = fmt.Sprintf("%x", sha256.Sum256(Content(recipe.Size, recipe.Seed)))
+	}
+	return expected
+}
+
+// ForceStrategy rewrites a committed version's storage into the given strategy
+// The commit record is updated to match, exactly as if the version had been written that way
+func (r *Repo) ForceStrategy(version int, strategy string) error {
+	logManager := log.NewLogManager(r.DgitDir)
+	c, err := logManager.GetCommit(version)
+	if err != nil {
+		return fmt.Errorf("version v%d not found: %w", version, err)
+	}
+
+	if c.CompressionInfo != nil && c.CompressionInfo.Strategy == strategy {
+		return nil
+	}
+	var original int64
+	for _, recipe := range r.versions[version] {
+		original += int64(recipe.Size)
+	}
+
+	var info *log.CompressionResult
+	var blobs map[string]*log.BlobRef
+	snapshotZip := ""
+	switch strategy {
+	case StrategyContent:
+		if blobs, err = r.putBlobs(version); err != nil {
+			return err
+		}
+		info = &log.CompressionResult{Strategy: StrategyContent, CacheLevel: "hot"}
+		for _, ref := range blobs {
+			if path := objstore.NewObjectStore(r.DgitDir).Find(ref.Hash); path != "" {
+				if stat, err := os.Stat(path); err == nil {
+					info.CompressedSize += stat.Size()
+				}
+			}
+		}
+	case StrategyLZ4:
+		name := fmt.Sprintf("v%d.lz4", version)
+		path := filepath.Join(r.DgitDir, "cache", "hot", name)
+		if err := r.writeLZ4(path, version); err != nil {
+			return err
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		info = &log.CompressionResult{Strategy: StrategyLZ4, OutputFile: name, CompressedSize: stat.Size(), CacheLevel: "hot"}
+	case StrategyZIP:
+		name := fmt.Sprintf("v%d.zip", version)
+		path := filepath.Join(r.DgitDir, "objects", name)
+		if err := r.writeZip(path, version); err != nil {
+			return err
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		info = &log.CompressionResult{Strategy: StrategyZIP, OutputFile: name, CompressedSize: stat.Size()}
+		snapshotZip = name
+	case StrategyBsdiff, StrategyXdelta3, StrategyPSDDelta:
+		if info, err = r.writeDelta(version, strategy); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", strategy)
+	}
+	if err := r.dropStorage(c); err != nil {
+		return err
+	}
+	info.OriginalSize, info.CreatedAt = original, c.Timestamp
+	c.CompressionInfo, c.Blobs, c.SnapshotZip = info, blobs, snapshotZip
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal commit: %w", err)
+	}
+	return logManager.SaveCommitData(data)
+}
+
+// Corrupt damages the storage object of a version
+func (r *Repo) Corrupt(version int, kind Corruption) error {
+	path, err := r.ObjectPath(version)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case CorruptMissing:
+		return os.Remove(path)
+	case CorruptTruncate:
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		return os.Truncate(path, info.Size()/2)
+	case CorruptFlipByte:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("object of v%d is empty", version)
+		}
+		data[len(data)/2] ^= 0xFF
+		return os.WriteFile(path, data, 0644)
+	}
+	return fmt.Errorf("unknown corruption %d", kind)
+}
+
+// ObjectPath returns where a version's storage object lives
+// Object store versions have no object of their own; the blob of their first file is returned
+func (r *Repo) ObjectPath(version int) (string, error) {
+	c, err := log.NewLogManager(r.DgitDir).GetCommit(version)
+	if err != nil {
+		return "", fmt.Errorf("version v%d not found: %w", version, err)
+	}
+	if c.CompressionInfo == nil {
+		return "", fmt.Errorf("v%d has no storage information", version)
+	}
+	if c.CompressionInfo.Strategy == StrategyContent {
+		paths := make([]string, 0, len(c.Blobs))
+		for path := range c.Blobs {
+			paths = append(paths, path)
+		}
+		if len(paths) == 0 {
+			return "", fmt.Errorf("v%d references no blobs", version)
+		}
+		sort.Strings(paths)
+		hash := c.Blobs[paths[0]].Hash
+		if path := objstore.NewObjectStore(r.DgitDir).Find(hash); path != "" {
+			return path, nil
+		}
+		return "", fmt.Errorf("blob %s of v%d not found", hash[:12], version)
+	}
+	for _, dir := range []string{
+		filepath.Join(r.DgitDir, "cache", "hot"),
+		filepath.Join(r.DgitDir, "cache", "warm"),
+		filepath.Join(r.DgitDir, "cache", "cold"),
+		filepath.Join(r.DgitDir, "objects"),
+		filepath.Join(r.DgitDir, "objects", "deltas"),
+	} {
+		path := filepath.Join(dir, c.CompressionInfo.OutputFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("storage object %s of v%d not found", c.CompressionInfo.OutputFile, version)
+}
+
+// Restore restores a whole version into dir through the normal restoration path
+func (r *Repo) Restore(version int, dir string) error {
+	c, err := log.NewLogManager(r.DgitDir).GetCommit(version)
+	if err != nil {
+		return fmt.Errorf("version v%d not found: %w", version, err)
+	}
+	restoreManager := restore.NewRestoreManager(r.DgitDir)
+	restoreManager.WorkDir = dir
+	return restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", version), nil, c)
+}
+
+// CheckRestore restores a version into dir and returns every path whose content is wrong or missing
+func (r *Repo) CheckRestore(version int, dir string) ([]string, error) {
+	if err := r.Restore(version, dir); err != nil {
+		return nil, err
+	}
+	var mismatched []string
+	for path, want := range r.Expected(version) {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		if err != nil || fmt.Sprintf("%x", sha256.Sum256(data)) != want {
+			mismatched = append(mismatched, path)
+		}
+	}
+	sort.Strings(mismatched)
+	return mismatched, nil
+}
+
+// dropStorage removes a version's current storage before it is rewritten in another strategy
+// Blobs other versions still reference are kept
+func (r *Repo) dropStorage(c *log.Commit) error {
+	if info := c.CompressionInfo; info != nil && info.OutputFile != "" {
+		switch info.Strategy {
+		case StrategyZIP:
+			os.Remove(filepath.Join(r.DgitDir, "objects", info.OutputFile))
+		case StrategyBsdiff, StrategyXdelta3:
+			os.Remove(filepath.Join(r.DgitDir, "objects", "deltas", info.OutputFile))
+		default:
+			os.Remove(filepath.Join(r.DgitDir, "cache", "hot", info.OutputFile))
+		}
+	}
+	if len(c.Blobs) == 0 {
+		return nil
+	}
+	history, err := log.NewLogManager(r.DgitDir).GetCommitHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load commit history: %w", err)
+	}
+	shared := make(map[string]bool)
+	for _, other := range history {
+		if other.Version == c.Version {
+			continue
+		}
+		for _, ref := range other.Blobs {
+			if ref != nil {
+				shared[ref.Hash] = true
+			}
+		}
+	}
+	store := objstore.NewObjectStore(r.DgitDir)
+	for _, ref := range c.Blobs {
+		if ref != nil && !shared[ref.Hash] {
+			if path := store.Find(ref.Hash); path != "" {
+				os.Remove(path)
+			}
+		}
+	}
+	return nil
+}
+
+// putBlobs adds a version's committed files to the object store
+func (r *Repo) putBlobs(version int) (map[string]*log.BlobRef, error) {
+	tmp, err := os.CreateTemp(r.DgitDir, "testutil-*")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	store := objstore.NewObjectStore(r.DgitDir)
+	blobs := make(map[string]*log.BlobRef, len(r.versions[version]))
+	for path, recipe := range r.versions[version] {
+		if err := os.WriteFile(tmp.Name(), Content(recipe.Size, recipe.Seed), 0644); err != nil {
+			return nil, err
+		}
+		put, err := store.Put(tmp.Name(), codec.LZ4)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store %s: %w", path, err)
+		}
+		blobs[path] = put.Ref
+	}
+	return blobs, nil
+}
+
+// writeLZ4 stores a version's committed files in a framed LZ4 hot cache object
+func (r *Repo) writeLZ4(path string, version int) error {
+	stream, err := r.framedStream(version)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	defer out.Close()
+
+	lz4Codec, err := codec.Get(codec.LZ4)
+	if err != nil {
+		return err
+	}
+	writer, err := lz4Codec.NewWriter(out)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(stream); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// framedStream returns a version's committed files as an uncompressed snapshot stream
+func (r *Repo) framedStream(version int) ([]byte, error) {
+	var stream bytes.Buffer
+	frames, err := codec.NewFrameWriter(&stream)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range r.sortedPaths(version) {
+		recipe := r.versions[version][p]
+		header := codec.FrameHeader{Path: p, Size: int64(recipe.Size), Mode: 0644}
+		if _, err := frames.WriteFile(header, bytes.NewReader(Content(recipe.Size, recipe.Seed))); err != nil {
+			return nil, err
+		}
+	}
+	if err := frames.Close(); err != nil {
+		return nil, err
+	}
+	return stream.Bytes(), nil
+}
+
+// writeDelta stores a version as a delta against the previous version
+// The base gets a hot cache snapshot first when it has none, since restores rebuild deltas from cached snapshots
+func (r *Repo) writeDelta(version int, strategy string) (*log.CompressionResult, error) {
+	base := version - 1
+	if base < 1 {
+		return nil, fmt.Errorf("v%d has no previous version to delta against", version)
+	}
+	if err := r.ensureSnapshot(base); err != nil {
+		return nil, err
+	}
+
+	var name, dir string
+	var data []byte
+	switch strategy {
+	case StrategyBsdiff:
+		name, dir = fmt.Sprintf("v%d_from_v%d.bsdiff", version, base), filepath.Join(r.DgitDir, "objects", "deltas")
+		var patch bytes.Buffer
+		if err := binarydist.Diff(bytes.NewReader(r.zipBytes(base)), bytes.NewReader(r.zipBytes(version)), &patch); err != nil {
+			return nil, fmt.Errorf("bsdiff failed: %w", err)
+		}
+		data = patch.Bytes()
+	case StrategyXdelta3:
+		name, dir = fmt.Sprintf("v%d_from_v%d%s", version, base, vcdiff.Ext), filepath.Join(r.DgitDir, "objects", "deltas")
+		baseStream, err := r.framedStream(base)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := r.framedStream(version)
+		if err != nil {
+			return nil, err
+		}
+		data = vcdiff.Encode(baseStream, stream)
+	case StrategyPSDDelta:
+		name, dir = fmt.Sprintf("v%d_from_v%d.psd_delta", version, base), filepath.Join(r.DgitDir, "cache", "hot")
+		var err error
+		if data, err = r.smartDelta(version, base); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return &log.CompressionResult{Strategy: strategy, OutputFile: name, CompressedSize: int64(len(data)),
+		BaseVersion: base, CacheLevel: "hot"}, nil
+}
+
+// smartDelta encodes a one-file version in the psd_smart_delta layout: "METADATA:<n>\n", the JSON header,
+// "\nDATA:\n" and the file compressed with LZ4
+func (r *Repo) smartDelta(version, base int) ([]byte, error) {
+	paths := r.sortedPaths(version)
+	if len(paths) != 1 {
+		return nil, fmt.Errorf("a smart delta carries one file; v%d has %d", version, len(paths))
+	}
+	recipe := r.versions[version][paths[0]]
+	header, err := json.Marshal(map[string]interface{}{
+		"type": StrategyPSDDelta, "from_version": base, "to_version": version,
+		"file_path": paths[0], "original_size": recipe.Size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "METADATA:%d\n%s\nDATA:\n", len(header), header)
+	writer := lz4.NewWriter(&out)
+	if _, err := writer.Write(Content(recipe.Size, recipe.Seed)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// ensureSnapshot gives a version a hot cache snapshot unless the hot or warm cache already holds one
+func (r *Repo) ensureSnapshot(version int) error {
+	for _, name := range []string{
+		filepath.Join("cache", "hot", fmt.Sprintf("v%d.lz4", version)),
+		filepath.Join("cache", "hot", fmt.Sprintf("v%d.store", version)),
+		filepath.Join("cache", "warm", fmt.Sprintf("v%d.zstd", version)),
+	} {
+		if _, err := os.Stat(filepath.Join(r.DgitDir, name)); err == nil {
+			return nil
+		}
+	}
+	return r.writeLZ4(filepath.Join(r.DgitDir, "cache", "hot", fmt.Sprintf("v%d.lz4", version)), version)
+}
+
+// sortedPaths lists a version's committed files in order
+func (r *Repo) sortedPaths(version int) []string {
+	paths := make([]string, 0, len(r.versions[version]))
+	for p := range r.versions[version] {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// writeZip stores a version's committed files in a ZIP object
+func (r *Repo) writeZip(path string, version int) error {
+	if err := os.WriteFile(path, r.zipBytes(version), 0644); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// zipBytes builds a ZIP of a version's committed files, entry for entry as restore converts a snapshot stream
+func (r *Repo) zipBytes(version int) []byte {
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	for _, p := range r.sortedPaths(version) {
+		recipe := r.versions[version][p]
+		if entry, err := writer.Create(p); err == nil {
+			entry.Write(Content(recipe.Size, recipe.Seed))
+		}
+	}
+	writer.Close()
+	return out.Bytes()
+}
@@ -0,0 +1,248 @@
+package thumbnail
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner/blender"
+)
+
+// maxThumbnailSize bounds an embedded preview so a corrupt length cannot exhaust memory
+const maxThumbnailSize = 32 << 20
+
+// maxXMPScan is how far into an Illustrator file the XMP packet is looked for
+const maxXMPScan = 64 << 20
+
+// Photoshop image resources holding the JPEG thumbnail of the composite (1033 is the Photoshop 4 form)
+const (
+	psdThumbnailResource    = 1036
+	psdOldThumbnailResource = 1033
+)
+
+// extractor reads the embedded preview of one file type and returns it with its image format
+type extractor func(path string) ([]byte, string, error)
+
+// extractors by file extension
+var extractors = map[string]extractor{
+	".psd":    extractPSD,
+	".psb":    extractPSD,
+	".ai":     extractXMP,
+	".sketch": zipEntry("previews/preview.png"),
+	".fig":    zipEntry("thumbnail.png"),
+	".xd":     zipEntry("preview.png", "thumbnail.png"),
+	".blend":  extractBlend,
+}
+
+// Extract returns the preview embedded in a design file and its format ("png" or "jpg")
+func Extract(path string) ([]byte, string, error) {
+	extract, ok := extractors[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unsupported file type", ErrNoThumbnail)
+	}
+	return extract(path)
+}
+
+// extractPSD reads the JPEG thumbnail from a PSD/PSB image resource section
+func extractPSD(path string) ([]byte, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	// Header (26 bytes), then the color mode data section
+	header := make([]byte, 26)
+	if _, err := io.ReadFull(file, header); err != nil || string(header[:4]) != "8BPS" {
+		return nil, "", fmt.Errorf("%s is not a Photoshop document", filepath.Base(path))
+	}
+	var length uint32
+	if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+		return nil, "", fmt.Errorf("truncated Photoshop document")
+	}
+	if _, err := file.Seek(int64(length), io.SeekCurrent); err != nil {
+		return nil, "", fmt.Errorf("truncated Photoshop document")
+	}
+
+	// Image resources: 8BIM, id, padded Pascal name, size, padded data
+	if err := binary.Read(file, binary.BigEndian, &length); err != nil {
+		return nil, "", fmt.Errorf("truncated Photoshop document")
+	}
+	if length > maxThumbnailSize*4 {
+		return nil, "", fmt.Errorf("image resource section too large (%d bytes)", length)
+	}
+	resources := make([]byte, length)
+	if _, err := io.ReadFull(file, resources); err != nil {
+		return nil, "", fmt.Errorf("truncated image resources")
+	}
+	for pos := 0; pos+12 <= len(resources); {
+		if !bytes.Equal(resources[pos:pos+4], []byte("8BIM")) {
+			break
+		}
+		id := binary.BigEndian.Uint16(resources[pos+4:])
+		nameLen := int(resources[pos+6]) + 1
+		nameLen += nameLen % 2
+		sizePos := pos + 6 + nameLen
+		if sizePos+4 > len(resources) {
+			break
+		}
+		size := int(binary.BigEndian.Uint32(resources[sizePos:]))
+		data := sizePos + 4
+		if data+size > len(resources) {
+			break
+		}
+		// The thumbnail carries a 28-byte header before the JFIF data
+		if (id == psdThumbnailResource || id == psdOldThumbnailResource) && size > 28 {
+			return append([]byte(nil), resources[data+28:data+size]...), "jpg", nil
+		}
+		pos = data + size + size%2
+	}
+	return nil, "", fmt.Errorf("%w in %s (saved without \"Maximize Compatibility\"?)", ErrNoThumbnail, filepath.Base(path))
+}
+
+// extractXMP reads the base64 JPEG Illustrator writes into its XMP packet (xmpGImg:image, xapGImg:image in old files)
+func extractXMP(path string) ([]byte, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(io.LimitReader(file, maxXMPScan), 1<<20)
+	for _, prefix := range []string{"xmpGImg", "xapGImg"} {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, "", err
+		}
+		reader.Reset(io.LimitReader(file, maxXMPScan))
+		encoded, err := readBetween(reader, "<"+prefix+":image>", "</"+prefix+":image>")
+		if err != nil {
+			return nil, "", err
+		}
+		if encoded == nil {
+			continue
+		}
+		// XMP wraps the base64 text with escaped line feeds
+		encoded = bytes.ReplaceAll(encoded, []byte("&#xA;"), nil)
+		encoded = bytes.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' || r == ' ' || r == '\t' {
+				return -1
+			}
+			return r
+		}, encoded)
+		data := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+		n, err := base64.StdEncoding.Decode(data, encoded)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid XMP thumbnail: %w", err)
+		}
+		return data[:n], "jpg", nil
+	}
+	return nil, "", fmt.Errorf("%w in %s", ErrNoThumbnail, filepath.Base(path))
+}
+
+// readBetween returns the bytes between the first start tag and the following end tag, or nil when absent
+func readBetween(reader *bufio.Reader, start, end string) ([]byte, error) {
+	if found, err := skipPast(reader, []byte(start), nil); err != nil || !found {
+		return nil, err
+	}
+	var content bytes.Buffer
+	found, err := skipPast(reader, []byte(end), &content)
+	if err != nil || !found {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// skipPast reads until just after marker, copying what precedes it into keep when given
+func skipPast(reader *bufio.Reader, marker []byte, keep *bytes.Buffer) (bool, error) {
+	matched := 0
+	for {
+		c, err := reader.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if keep != nil {
+			if keep.Len() > maxThumbnailSize*2 {
+				return false, fmt.Errorf("XMP thumbnail too large")
+			}
+			keep.WriteByte(c)
+		}
+		switch {
+		case c == marker[matched]:
+			matched++
+		case c == marker[0]:
+			matched = 1
+		default:
+			matched = 0
+		}
+		if matched == len(marker) {
+			if keep != nil {
+				keep.Truncate(keep.Len() - len(marker))
+			}
+			return true, nil
+		}
+	}
+}
+
+// zipEntry reads the first present preview image from a ZIP-based document (Sketch, Figma local copy, XD)
+func zipEntry(names ...string) extractor {
+	return func(path string) ([]byte, string, error) {
+		archive, err := zip.OpenReader(path)
+		if errors.Is(err, zip.ErrFormat) {
+			return nil, "", fmt.Errorf("%w in %s (not a ZIP package)", ErrNoThumbnail, filepath.Base(path))
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		defer archive.Close()
+
+		for _, name := range names {
+			for _, f := range archive.File {
+				if f.Name != name {
+					continue
+				}
+				reader, err := f.Open()
+				if err != nil {
+					return nil, "", fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				data, err := io.ReadAll(io.LimitReader(reader, maxThumbnailSize+1))
+				reader.Close()
+				if err != nil {
+					return nil, "", fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				if len(data) > maxThumbnailSize {
+					return nil, "", fmt.Errorf("%s is larger than %d MB", name, maxThumbnailSize>>20)
+				}
+				return data, strings.TrimPrefix(filepath.Ext(name), "."), nil
+			}
+		}
+		return nil, "", fmt.Errorf("%w in %s", ErrNoThumbnail, filepath.Base(path))
+	}
+}
+
+// extractBlend encodes Blender's raw RGBA preview as PNG
+func extractBlend(path string) ([]byte, string, error) {
+	img, err := blender.GetBlendThumbnail(path)
+	if errors.Is(err, blender.ErrNoThumbnail) {
+		return nil, "", fmt.Errorf("%w in %s", ErrNoThumbnail, filepath.Base(path))
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("failed to encode Blender preview: %w", err)
+	}
+	return buf.Bytes(), "png", nil
+}
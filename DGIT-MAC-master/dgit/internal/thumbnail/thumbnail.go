@@ -0,0 +1,146 @@
+package thumbnail
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+)
+
+// ErrNoThumbnail is returned when a file type has no embedded preview, or this file was saved without one
+var ErrNoThumbnail = errors.New("no embedded thumbnail")
+
+// Formats in which thumbnails are stored, in lookup order
+var Formats = []string{"png", "jpg"}
+
+// Thumbnail is the embedded preview of one file in one version
+type Thumbnail struct {
+	Version int
+	Source  string // Repository path of the design file
+	Path    string // Absolute path of the image in the thumbnail store
+	Format  string // "png" or "jpg"
+	Size    int64
+}
+
+// ThumbnailManager extracts the previews design applications embed in their files and keeps them
+// in .dgit/objects/previews as v<N>/<repository path>.<format>, so a version can be looked at without restoring it
+type ThumbnailManager struct {
+	DgitDir       string
+	ThumbnailsDir string
+}
+
+// NewThumbnailManager creates a new thumbnail manager for the given .dgit directory
+func NewThumbnailManager(dgitDir string) *ThumbnailManager {
+	return &ThumbnailManager{
+		DgitDir:       dgitDir,
+		ThumbnailsDir: filepath.Join(dgitDir, "objects", "previews"),
+	}
+}
+
+// ExtractAll stores the thumbnails of a committed version's files
+// files maps repository paths to the on-disk copy holding that version's content;
+// files without an embedded preview are skipped, other failures are returned per file
+func (tm *ThumbnailManager) ExtractAll(version int, files map[string]string) ([]*Thumbnail, map[string]error) {
+	var stored []*Thumbnail
+	failed := make(map[string]error)
+	for source, path := range files {
+		data, format, err := Extract(path)
+		if errors.Is(err, ErrNoThumbnail) {
+			continue
+		}
+		if err != nil {
+			failed[source] = err
+			continue
+		}
+		thumbnail, err := tm.Save(version, source, format, data)
+		if err != nil {
+			failed[source] = err
+			continue
+		}
+		stored = append(stored, thumbnail)
+	}
+	return stored, failed
+}
+
+// Save writes a thumbnail to the store, replacing one in another format
+func (tm *ThumbnailManager) Save(version int, source, format string, data []byte) (*Thumbnail, error) {
+	output := tm.thumbnailPath(version, source, format)
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create thumbnail directory: %w", err)
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write thumbnail: %w", err)
+	}
+	for _, other := range Formats {
+		if other != format {
+			os.Remove(tm.thumbnailPath(version, source, other))
+		}
+	}
+	return &Thumbnail{Version: version, Source: source, Path: output, Format: format, Size: int64(len(data))}, nil
+}
+
+// Get returns the stored thumbnail of a file in a version
+func (tm *ThumbnailManager) Get(version int, source string) (*Thumbnail, error) {
+	for _, format := range Formats {
+		path := tm.thumbnailPath(version, source, format)
+		if info, err := os.Stat(path); err == nil {
+			return &Thumbnail{Version: version, Source: source, Path: path, Format: format, Size: info.Size()}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w stored for %s in v%d", ErrNoThumbnail, source, version)
+}
+
+// thumbnailPath is where the thumbnail of a file in a version is stored
+func (tm *ThumbnailManager) thumbnailPath(version int, source, format string) string {
+	return filepath.Join(tm.ThumbnailsDir, fmt.Sprintf("v%d", version), filepath.FromSlash(source)+"."+format)
+}
+
+// Supported reports whether thumbnails can be extracted from files with this path's extension
+func Supported(path string) bool {
+	_, ok := extractors[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// ForVersion returns the stored thumbnail of a file in a version, extracting it from the
+// committed content when the version predates thumbnail extraction
+func (tm *ThumbnailManager) ForVersion(version int, source string) (*Thumbnail, error) {
+	if stored, err := tm.Get(version, source); err == nil {
+		return stored, nil
+	}
+	if !Supported(source) {
+		return nil, fmt.Errorf("%w: %s files carry no preview", ErrNoThumbnail, filepath.Ext(source))
+	}
+
+	content, err := restore.NewRestoreManager(tm.DgitDir).OpenFile(version, source)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	// The copy keeps the file's name so extraction errors name the right file
+	tempDir, err := os.MkdirTemp("", "dgit-thumbnail-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary folder: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+	temp, err := os.Create(filepath.Join(tempDir, filepath.Base(filepath.FromSlash(source))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	_, err = io.Copy(temp, content)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from v%d: %w", source, version, err)
+	}
+
+	data, format, err := Extract(temp.Name())
+	if err != nil {
+		return nil, err
+	}
+	return tm.Save(version, source, format, data)
+}
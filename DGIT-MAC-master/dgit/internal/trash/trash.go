@@ -0,0 +1,271 @@
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// DefaultRetentionDays is how long trashed files are kept when the config does not say otherwise
+const DefaultRetentionDays = 30
+
+// Entry is one file or folder moved to the trash
+type Entry struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`      // Original location relative to the working tree root
+	Operation string    `json:"operation"` // Command that replaced or removed it (restore, clean, link)
+	TrashedAt time.Time `json:"trashed_at"`
+	Size      int64     `json:"size"`
+	Dir       bool      `json:"dir,omitempty"`
+}
+
+// TrashManager moves user files into .dgit/trash instead of deleting them
+// Each entry lives in .dgit/trash/<id>/ and is listed in .dgit/trash/index.json
+type TrashManager struct {
+	DgitDir   string
+	RootDir   string
+	TrashDir  string
+	IndexFile string
+}
+
+// NewTrashManager creates a new trash manager for the given .dgit directory
+func NewTrashManager(dgitDir string) *TrashManager {
+	trashDir := filepath.Join(dgitDir, "trash")
+	return &TrashManager{
+		DgitDir:   dgitDir,
+		RootDir:   filepath.Dir(dgitDir),
+		TrashDir:  trashDir,
+		IndexFile: filepath.Join(trashDir, "index.json"),
+	}
+}
+
+// Covers reports whether a path is a user file of this repository (inside the work tree, outside .dgit)
+func (tm *TrashManager) Covers(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(tm.RootDir, absPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	return rel != ".dgit" && !strings.HasPrefix(filepath.ToSlash(rel), ".dgit/")
+}
+
+// Trash moves a file or folder out of the work tree, recording which operation displaced it
+// Entries older than the retention period are purged on the way
+func (tm *TrashManager) Trash(path, operation string) (*Entry, error) {
+	if !tm.Covers(path) {
+		return nil, fmt.Errorf("%s is not inside the working tree", path)
+	}
+	absPath, _ := filepath.Abs(path)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	rel, _ := filepath.Rel(tm.RootDir, absPath)
+
+	entries, err := tm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 36),
+		Path:      filepath.ToSlash(rel),
+		Operation: operation,
+		TrashedAt: time.Now(),
+		Size:      info.Size(),
+		Dir:       info.IsDir(),
+	}
+	if info.IsDir() {
+		entry.Size = util.DirSize(absPath)
+	}
+
+	dest := tm.entryPath(entry)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := moveFile(absPath, dest); err != nil {
+		return nil, fmt.Errorf("failed to move %s to trash: %w", path, err)
+	}
+
+	entries = append(entries, entry)
+	entries = tm.purgeExpired(entries)
+	if err := tm.saveIndex(entries); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// List returns trashed entries, newest first
+func (tm *TrashManager) List() ([]*Entry, error) {
+	entries := []*Entry{}
+
+	data, err := os.ReadFile(tm.IndexFile)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash index: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse trash index: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].TrashedAt.After(entries[j].TrashedAt) })
+	return entries, nil
+}
+
+// Restore moves an entry back to its original location, selected by ID or by original path (newest wins)
+// An existing file at that location is refused unless force is set, in which case it is trashed first
+func (tm *TrashManager) Restore(ref string, force bool) (*Entry, error) {
+	entries, err := tm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	refPath := filepath.ToSlash(filepath.Clean(ref))
+	for i, entry := range entries {
+		if entry.ID == ref || entry.Path == refPath {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, fmt.Errorf("no trash entry matches %s", ref)
+	}
+	entry := entries[index]
+
+	target := filepath.Join(tm.RootDir, filepath.FromSlash(entry.Path))
+	if _, err := os.Stat(target); err == nil {
+		if !force {
+			return nil, fmt.Errorf("%s already exists", entry.Path)
+		}
+		displaced, err := tm.Trash(target, "trash-restore")
+		if err != nil {
+			return nil, err
+		}
+		entries = append([]*Entry{displaced}, entries...)
+		index++
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", entry.Path, err)
+	}
+	if err := moveFile(tm.entryPath(entry), target); err != nil {
+		return nil, fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+	}
+	os.RemoveAll(filepath.Join(tm.TrashDir, entry.ID))
+
+	entries = append(entries[:index], entries[index+1:]...)
+	if err := tm.saveIndex(entries); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Empty permanently deletes entries trashed more than olderThan ago (0 deletes everything)
+// Returns the number of entries removed
+func (tm *TrashManager) Empty(olderThan time.Duration) (int, error) {
+	entries, err := tm.List()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-olderThan)
+	var kept []*Entry
+	for _, entry := range entries {
+		if olderThan > 0 && entry.TrashedAt.After(cutoff) {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(tm.TrashDir, entry.ID)); err != nil {
+			return 0, fmt.Errorf("failed to remove trash entry %s: %w", entry.ID, err)
+		}
+	}
+	if err := tm.saveIndex(kept); err != nil {
+		return 0, err
+	}
+	return len(entries) - len(kept), nil
+}
+
+// RetentionDays returns the configured retention period
+func (tm *TrashManager) RetentionDays() int {
+	if config, err := initializer.GetRepositoryConfig(tm.DgitDir); err == nil && config.Trash.RetentionDays > 0 {
+		return config.Trash.RetentionDays
+	}
+	return DefaultRetentionDays
+}
+
+// purgeExpired deletes entries past the retention period and returns the rest
+func (tm *TrashManager) purgeExpired(entries []*Entry) []*Entry {
+	cutoff := time.Now().AddDate(0, 0, -tm.RetentionDays())
+	var kept []*Entry
+	for _, entry := range entries {
+		if entry.TrashedAt.Before(cutoff) {
+			os.RemoveAll(filepath.Join(tm.TrashDir, entry.ID))
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// saveIndex writes the trash index to disk
+func (tm *TrashManager) saveIndex(entries []*Entry) error {
+	if entries == nil {
+		entries = []*Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trash index: %w", err)
+	}
+	if err := os.MkdirAll(tm.TrashDir, 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := os.WriteFile(tm.IndexFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write trash index: %w", err)
+	}
+	return nil
+}
+
+// entryPath is where an entry's content is kept inside the trash
+func (tm *TrashManager) entryPath(entry *Entry) string {
+	return filepath.Join(tm.TrashDir, entry.ID, filepath.Base(filepath.FromSlash(entry.Path)))
+}
+
+// moveFile renames src to dst, falling back to copy and delete for single files across filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		in.Close()
+		return err
+	}
+	_, err = io.Copy(out, in)
+	in.Close()
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
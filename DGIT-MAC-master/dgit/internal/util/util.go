@@ -0,0 +1,82 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyFile copies src to dst, creating parent directories as needed
+func CopyFile(src, dst string) error {
+	return copyTo(src, dst, io.Discard)
+}
+
+// CopyFileSHA256 copies src to dst like CopyFile and returns the SHA256 of the content
+func CopyFileSHA256(src, dst string) (string, error) {
+	hash := sha256.New()
+	if err := copyTo(src, dst, hash); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyTo copies src to dst while also feeding the content to extra
+func copyTo(src, dst string, extra io.Writer) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.MultiWriter(out, extra), in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ShortHash abbreviates a commit or content hash for messages
+func ShortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+// FormatBytes formats a byte count for display (e.g. "14.2 MB"); negative counts show their magnitude
+func FormatBytes(size int64) string {
+	value := float64(size)
+	if value < 0 {
+		value = -value
+	}
+	switch {
+	case value >= 1<<30:
+		return fmt.Sprintf("%.1f GB", value/(1<<30))
+	case value >= 1<<20:
+		return fmt.Sprintf("%.1f MB", value/(1<<20))
+	case value >= 1<<10:
+		return fmt.Sprintf("%.1f KB", value/(1<<10))
+	}
+	return fmt.Sprintf("%d B", int64(value))
+}
+
+// DirSize sums the sizes of all files below a directory
+func DirSize(dir string) int64 {
+	var total int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,602 @@
+package vcdiff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/adler32"
+)
+
+// VCDIFF (RFC 3284) is the delta format written by xdelta3
+// Deltas made here carry xdelta3's per-window Adler-32 checksum and decode with 'xdelta3 -d -s <base>';
+// xdelta3 deltas decode here when made without secondary compression ('xdelta3 -S none')
+
+// Ext is the file extension of deltas in the repository
+const Ext = ".xdelta3"
+
+// WindowSize is the most target bytes encoded per window, xdelta3's default
+const WindowSize = 8 << 20
+
+// blockSize is the length of the source blocks indexed for matching
+const blockSize = 16
+
+// minRun is the shortest repeated byte sequence encoded as RUN instead of ADD
+const minRun = 8
+
+var magic = []byte{0xD6, 0xC3, 0xC4, 0x00}
+
+// Header indicator bits
+const (
+	hdrDecompress = 0x01
+	hdrCodeTable  = 0x02
+	hdrAppHeader  = 0x04 // xdelta3 extension
+)
+
+// Window indicator bits
+const (
+	winSource  = 0x01
+	winTarget  = 0x02
+	winAdler32 = 0x04 // xdelta3 extension
+)
+
+// Instruction types
+const (
+	opNoop = iota
+	opAdd
+	opRun
+	opCopy
+)
+
+// Address cache sizes of the default code table
+const (
+	nearSize = 4
+	sameSize = 3
+)
+
+// ErrCorrupt is returned for deltas that do not decode
+var ErrCorrupt = errors.New("corrupt VCDIFF delta")
+
+type instruction struct {
+	op   byte
+	size int
+	mode int
+}
+
+type codeEntry struct {
+	first, second instruction
+}
+
+// defaultTable is the RFC 3284 default instruction code table
+var defaultTable = buildDefaultTable()
+
+func buildDefaultTable() [256]codeEntry {
+	var table [256]codeEntry
+	i := 0
+	next := func(first, second instruction) {
+		table[i] = codeEntry{first, second}
+		i++
+	}
+	next(instruction{op: opRun}, instruction{})
+	for size := 0; size <= 17; size++ {
+		next(instruction{op: opAdd, size: size}, instruction{})
+	}
+	for mode := 0; mode < 2+nearSize+sameSize; mode++ {
+		next(instruction{op: opCopy, mode: mode}, instruction{})
+		for size := 4; size <= 18; size++ {
+			next(instruction{op: opCopy, size: size, mode: mode}, instruction{})
+		}
+	}
+	for mode := 0; mode < 2+nearSize; mode++ {
+		for addSize := 1; addSize <= 4; addSize++ {
+			for copySize := 4; copySize <= 6; copySize++ {
+				next(instruction{op: opAdd, size: addSize}, instruction{op: opCopy, size: copySize, mode: mode})
+			}
+		}
+	}
+	for mode := 2 + nearSize; mode < 2+nearSize+sameSize; mode++ {
+		for addSize := 1; addSize <= 4; addSize++ {
+			next(instruction{op: opAdd, size: addSize}, instruction{op: opCopy, size: 4, mode: mode})
+		}
+	}
+	for mode := 0; mode < 2+nearSize+sameSize; mode++ {
+		next(instruction{op: opCopy, size: 4, mode: mode}, instruction{op: opAdd, size: 1})
+	}
+	return table
+}
+
+// addressCache tracks recent COPY addresses so they can be encoded relative to each other
+type addressCache struct {
+	near     [nearSize]int
+	nextSlot int
+	same     [sameSize * 256]int
+}
+
+func (c *addressCache) update(addr int) {
+	c.near[c.nextSlot] = addr
+	c.nextSlot = (c.nextSlot + 1) % nearSize
+	c.same[addr%(sameSize*256)] = addr
+}
+
+// decode reads a COPY address in the given mode; here is the current position in the window's address space
+func (c *addressCache) decode(r *reader, here, mode int) (int, error) {
+	var addr int
+	switch {
+	case mode == 0:
+		v, err := r.varint()
+		if err != nil {
+			return 0, err
+		}
+		addr = v
+	case mode == 1:
+		v, err := r.varint()
+		if err != nil {
+			return 0, err
+		}
+		addr = here - v
+	case mode < 2+nearSize:
+		v, err := r.varint()
+		if err != nil {
+			return 0, err
+		}
+		addr = c.near[mode-2] + v
+	case mode < 2+nearSize+sameSize:
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		addr = c.same[(mode-2-nearSize)*256+int(b)]
+	default:
+		return 0, fmt.Errorf("%w: invalid address mode %d", ErrCorrupt, mode)
+	}
+	if addr < 0 || addr >= here {
+		return 0, fmt.Errorf("%w: copy address %d out of range", ErrCorrupt, addr)
+	}
+	c.update(addr)
+	return addr, nil
+}
+
+// encode picks the cheapest mode for an address and appends its encoding to addrs
+func (c *addressCache) encode(addrs *bytes.Buffer, addr, here int) int {
+	if c.same[addr%(sameSize*256)] == addr {
+		slot := addr % (sameSize * 256)
+		addrs.WriteByte(byte(slot % 256))
+		c.update(addr)
+		return 2 + nearSize + slot/256
+	}
+	mode, value := 0, addr
+	if d := here - addr; varintLen(d) < varintLen(value) {
+		mode, value = 1, d
+	}
+	for i, near := range c.near {
+		if d := addr - near; d >= 0 && varintLen(d) < varintLen(value) {
+			mode, value = 2+i, d
+		}
+	}
+	addrs.Write(appendVarint(nil, value))
+	c.update(addr)
+	return mode
+}
+
+// Encode returns a delta that rebuilds target from source
+func Encode(source, target []byte) []byte {
+	index := newBlockIndex(source)
+	out := bytes.NewBuffer(append([]byte(nil), magic...))
+	out.WriteByte(0) // No secondary compression, default code table, no application header
+
+	for start := 0; start < len(target) || start == 0; start += WindowSize {
+		end := start + WindowSize
+		if end > len(target) {
+			end = len(target)
+		}
+		writeWindow(out, source, target[start:end], index)
+		if end == len(target) {
+			break
+		}
+	}
+	return out.Bytes()
+}
+
+// writeWindow encodes one target window against the whole source
+func writeWindow(out *bytes.Buffer, source, window []byte, index *blockIndex) {
+	var data, insts, addrs bytes.Buffer
+	cache := &addressCache{}
+	emitter := &instructionWriter{data: &data, insts: &insts}
+
+	literal := 0
+	pos := 0
+	var h uint64
+	if len(window) >= blockSize {
+		h = hashBlock(window[:blockSize])
+	}
+	for pos+blockSize <= len(window) {
+		if src, ok := index.lookup(h, source, window[pos:pos+blockSize]); ok {
+			back := 0
+			for pos-back > literal && src-back > 0 && source[src-back-1] == window[pos-back-1] {
+				back++
+			}
+			length := blockSize
+			for pos+length < len(window) && src+length < len(source) && source[src+length] == window[pos+length] {
+				length++
+			}
+			start := pos - back
+			emitter.literal(window[literal:start])
+			here := len(source) + start
+			mode := cache.encode(&addrs, src-back, here)
+			emitter.copy(back+length, mode)
+
+			pos = start + back + length
+			literal = pos
+			if pos+blockSize <= len(window) {
+				h = hashBlock(window[pos : pos+blockSize])
+			}
+			continue
+		}
+		if pos+blockSize < len(window) {
+			h = rollHash(h, window[pos], window[pos+blockSize])
+		}
+		pos++
+	}
+	emitter.literal(window[literal:])
+
+	indicator := byte(winAdler32)
+	if len(source) > 0 {
+		indicator |= winSource
+	}
+	out.WriteByte(indicator)
+	if len(source) > 0 {
+		out.Write(appendVarint(nil, len(source)))
+		out.Write(appendVarint(nil, 0))
+	}
+
+	var body []byte
+	body = appendVarint(body, len(window))
+	body = append(body, 0) // Delta indicator: sections are not compressed
+	body = appendVarint(body, data.Len())
+	body = appendVarint(body, insts.Len())
+	body = appendVarint(body, addrs.Len())
+	body = binary.BigEndian.AppendUint32(body, adler32.Checksum(window))
+	body = append(body, data.Bytes()...)
+	body = append(body, insts.Bytes()...)
+	body = append(body, addrs.Bytes()...)
+
+	out.Write(appendVarint(nil, len(body)))
+	out.Write(body)
+}
+
+// instructionWriter appends single instructions using the default code table
+type instructionWriter struct {
+	data  *bytes.Buffer
+	insts *bytes.Buffer
+}
+
+// literal emits bytes not found in the source, using RUN for long repeats
+func (w *instructionWriter) literal(b []byte) {
+	start := 0
+	for i := 0; i < len(b); {
+		j := i + 1
+		for j < len(b) && b[j] == b[i] {
+			j++
+		}
+		if j-i >= minRun {
+			w.add(b[start:i])
+			w.insts.WriteByte(0)
+			w.insts.Write(appendVarint(nil, j-i))
+			w.data.WriteByte(b[i])
+			start = j
+		}
+		i = j
+	}
+	w.add(b[start:])
+}
+
+func (w *instructionWriter) add(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	if len(b) <= 17 {
+		w.insts.WriteByte(byte(1 + len(b)))
+	} else {
+		w.insts.WriteByte(1)
+		w.insts.Write(appendVarint(nil, len(b)))
+	}
+	w.data.Write(b)
+}
+
+func (w *instructionWriter) copy(size, mode int) {
+	base := 19 + mode*16
+	if size >= 4 && size <= 18 {
+		w.insts.WriteByte(byte(base + size - 3))
+		return
+	}
+	w.insts.WriteByte(byte(base))
+	w.insts.Write(appendVarint(nil, size))
+}
+
+// Decode rebuilds the target a delta was made for from its source
+func Decode(source, delta []byte) ([]byte, error) {
+	r := &reader{data: delta}
+	head, err := r.take(len(magic))
+	if err != nil || !bytes.Equal(head[:3], magic[:3]) {
+		return nil, fmt.Errorf("%w: not a VCDIFF delta", ErrCorrupt)
+	}
+	indicator, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if indicator&hdrDecompress != 0 {
+		return nil, fmt.Errorf("secondary compression is not supported (create the delta with 'xdelta3 -S none')")
+	}
+	if indicator&hdrCodeTable != 0 {
+		return nil, fmt.Errorf("custom code tables are not supported")
+	}
+	if indicator&hdrAppHeader != 0 {
+		length, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.take(length); err != nil {
+			return nil, err
+		}
+	}
+
+	var target []byte
+	for !r.done() {
+		if target, err = decodeWindow(r, source, target); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+// decodeWindow decodes one window and appends it to target
+func decodeWindow(r *reader, source, target []byte) ([]byte, error) {
+	indicator, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	var segment []byte
+	if indicator&(winSource|winTarget) == winSource|winTarget {
+		return nil, fmt.Errorf("%w: window uses both source and target segments", ErrCorrupt)
+	}
+	if indicator&(winSource|winTarget) != 0 {
+		length, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		position, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		from := source
+		if indicator&winTarget != 0 {
+			from = target
+		}
+		if position+length > len(from) {
+			return nil, fmt.Errorf("%w: segment %d+%d exceeds %d-byte input (wrong base?)", ErrCorrupt, position, length, len(from))
+		}
+		segment = from[position : position+length]
+	}
+
+	deltaLength, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	bodyStart := r.pos
+	windowLength, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if compressed != 0 {
+		return nil, fmt.Errorf("secondary compression is not supported (create the delta with 'xdelta3 -S none')")
+	}
+	var lengths [3]int
+	for i := range lengths {
+		if lengths[i], err = r.varint(); err != nil {
+			return nil, err
+		}
+	}
+	var checksum uint32
+	if indicator&winAdler32 != 0 {
+		b, err := r.take(4)
+		if err != nil {
+			return nil, err
+		}
+		checksum = binary.BigEndian.Uint32(b)
+	}
+	data, err := r.take(lengths[0])
+	if err != nil {
+		return nil, err
+	}
+	insts, err := r.take(lengths[1])
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := r.take(lengths[2])
+	if err != nil {
+		return nil, err
+	}
+	if r.pos-bodyStart != deltaLength {
+		return nil, fmt.Errorf("%w: window length mismatch", ErrCorrupt)
+	}
+
+	window := make([]byte, 0, windowLength)
+	dataReader, instReader, addrReader := &reader{data: data}, &reader{data: insts}, &reader{data: addrs}
+	cache := &addressCache{}
+	for !instReader.done() {
+		code, _ := instReader.byte()
+		entry := defaultTable[code]
+		for _, inst := range []instruction{entry.first, entry.second} {
+			if inst.op == opNoop {
+				continue
+			}
+			size := inst.size
+			if size == 0 {
+				if size, err = instReader.varint(); err != nil {
+					return nil, err
+				}
+			}
+			if len(window)+size > windowLength {
+				return nil, fmt.Errorf("%w: instructions overrun the window", ErrCorrupt)
+			}
+			switch inst.op {
+			case opAdd:
+				b, err := dataReader.take(size)
+				if err != nil {
+					return nil, err
+				}
+				window = append(window, b...)
+			case opRun:
+				b, err := dataReader.byte()
+				if err != nil {
+					return nil, err
+				}
+				for i := 0; i < size; i++ {
+					window = append(window, b)
+				}
+			case opCopy:
+				here := len(segment) + len(window)
+				addr, err := cache.decode(addrReader, here, inst.mode)
+				if err != nil {
+					return nil, err
+				}
+				// Copies from the target may overlap what they produce, so go byte by byte
+				for i := 0; i < size; i++ {
+					if p := addr + i; p < len(segment) {
+						window = append(window, segment[p])
+					} else {
+						window = append(window, window[p-len(segment)])
+					}
+				}
+			}
+		}
+	}
+	if len(window) != windowLength {
+		return nil, fmt.Errorf("%w: window produced %d of %d bytes", ErrCorrupt, len(window), windowLength)
+	}
+	if indicator&winAdler32 != 0 && adler32.Checksum(window) != checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch (wrong base?)", ErrCorrupt)
+	}
+	return append(target, window...), nil
+}
+
+// blockIndex maps hashes of the source's aligned blocks to block numbers
+type blockIndex struct {
+	slots []uint32 // Block number + 1; 0 is empty
+	shift uint
+}
+
+func newBlockIndex(source []byte) *blockIndex {
+	blocks := len(source) / blockSize
+	bits := uint(10)
+	for 1<<bits < blocks && bits < 30 {
+		bits++
+	}
+	index := &blockIndex{slots: make([]uint32, 1<<bits), shift: 64 - bits}
+	for b := 0; b < blocks; b++ {
+		index.slots[index.slot(hashBlock(source[b*blockSize:(b+1)*blockSize]))] = uint32(b + 1)
+	}
+	return index
+}
+
+func (bi *blockIndex) slot(h uint64) uint64 {
+	return (h * 0x9E3779B97F4A7C15) >> bi.shift
+}
+
+// lookup returns the source offset of a block matching the given bytes
+func (bi *blockIndex) lookup(h uint64, source, block []byte) (int, bool) {
+	entry := bi.slots[bi.slot(h)]
+	if entry == 0 {
+		return 0, false
+	}
+	offset := int(entry-1) * blockSize
+	return offset, bytes.Equal(source[offset:offset+blockSize], block)
+}
+
+// Rolling polynomial hash over blockSize bytes
+const hashBase = 0x100000001B3
+
+var hashDrop = func() uint64 {
+	p := uint64(1)
+	for i := 0; i < blockSize-1; i++ {
+		p *= hashBase
+	}
+	return p
+}()
+
+func hashBlock(b []byte) uint64 {
+	var h uint64
+	for _, c := range b {
+		h = h*hashBase + uint64(c)
+	}
+	return h
+}
+
+func rollHash(h uint64, out, in byte) uint64 {
+	return (h-uint64(out)*hashDrop)*hashBase + uint64(in)
+}
+
+// reader reads VCDIFF sections
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) done() bool { return r.pos >= len(r.data) }
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) take(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data)-r.pos {
+		return nil, fmt.Errorf("%w: unexpected end of data", ErrCorrupt)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// varint reads a base-128 integer, most significant digit first
+func (r *reader) varint() (int, error) {
+	var v int
+	for i := 0; i < 9; i++ {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<7 | int(b&0x7F)
+		if b&0x80 == 0 {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: integer too large", ErrCorrupt)
+}
+
+func appendVarint(b []byte, v int) []byte {
+	var digits [10]byte
+	n := len(digits) - 1
+	digits[n] = byte(v & 0x7F)
+	for v >>= 7; v > 0; v >>= 7 {
+		n--
+		digits[n] = byte(v&0x7F) | 0x80
+	}
+	return append(b, digits[n:]...)
+}
+
+func varintLen(v int) int {
+	n := 1
+	for v >>= 7; v > 0; v >>= 7 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,257 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/util"
+)
+
+// Fsck issue severities
+const (
+	FsckError   = "error"
+	FsckWarning = "warning"
+)
+
+// FsckIssue is one problem found by Fsck, with how to repair it
+type FsckIssue struct {
+	Severity string `json:"severity"`
+	Version  int    `json:"version,omitempty"` // 0 when the issue is not tied to a version
+	Object   string `json:"object,omitempty"`  // File relative to .dgit, or a ref name
+	Problem  string `json:"problem"`
+	Repair   string `json:"repair"`
+}
+
+// FsckReport is the outcome of a full repository check
+type FsckReport struct {
+	Versions int          `json:"versions"`
+	Deep     bool         `json:"deep"`
+	Issues   []*FsckIssue `json:"issues"`
+}
+
+// Errors counts the issues that leave data unreadable
+func (fr *FsckReport) Errors() int {
+	n := 0
+	for _, issue := range fr.Issues {
+		if issue.Severity == FsckError {
+			n++
+		}
+	}
+	return n
+}
+
+// Warnings counts the issues that waste space or need tidying but lose nothing
+func (fr *FsckReport) Warnings() int {
+	return len(fr.Issues) - fr.Errors()
+}
+
+// fsckVersionName matches per-version storage files such as v3.lz4 or v4_from_v3.xdelta3
+var fsckVersionName = regexp.MustCompile(`^v(\d+)(?:_from_v\d+)?\.`)
+
+// fsckTempName matches leftovers of interrupted restores, status checks, commits and pulls
+var fsckTempName = regexp.MustCompile(`^(?:temp_(?:restore|status)_.*|.*\.part\d+|\.incoming-.*)$`)
+
+// Fsck walks every commit and checks refs, parents, storage objects, their checksums and delta chains,
+// then looks for storage nothing references. With deep, each version is also restored and hashed
+func (vm *VerifyManager) Fsck(deep bool) (*FsckReport, error) {
+	logManager := log.NewLogManager(vm.DgitDir)
+	history, err := logManager.GetCommitHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit history: %w", err)
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Version < history[j].Version })
+	report := &FsckReport{Versions: len(history), Deep: deep}
+	add := func(severity string, version int, object, problem, repair string) {
+		report.Issues = append(report.Issues, &FsckIssue{
+			Severity: severity, Version: version, Object: object, Problem: problem, Repair: repair,
+		})
+	}
+
+	byVersion := make(map[int]*log.Commit, len(history))
+	byHash := make(map[string]*log.Commit, len(history))
+	for _, commit := range history {
+		byVersion[commit.Version] = commit
+		byHash[commit.Hash] = commit
+	}
+
+	// Refs and parents must point at commits that exist
+	if head := logManager.HeadHash(); head != "" && byHash[head] == nil {
+		add(FsckError, 0, "HEAD", fmt.Sprintf("points at unknown commit %s", util.ShortHash(head)),
+			"Run 'dgit checkout <branch>' to move HEAD to an existing commit")
+	}
+	if refs, err := logManager.ListRefs(); err == nil {
+		names := make([]string, 0, len(refs))
+		for name := range refs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if hash := refs[name]; hash != "" && byHash[hash] == nil {
+				add(FsckError, 0, "refs/heads/"+name, fmt.Sprintf("points at unknown commit %s", util.ShortHash(hash)),
+					fmt.Sprintf("Run 'dgit pull' to fetch it, or 'dgit branch -d %s' if the branch is abandoned", name))
+			}
+		}
+	}
+	for _, commit := range history {
+		if commit.ParentHash != "" && byHash[commit.ParentHash] == nil {
+			add(FsckError, commit.Version, "", fmt.Sprintf("parent commit %s is missing", util.ShortHash(commit.ParentHash)),
+				"Run 'dgit pull' or restore .dgit/commits from a backup")
+		}
+	}
+
+	// Storage objects exist and decode with valid checksums
+	broken := make(map[int]bool)
+	run, err := vm.CheckStorageParallel(history, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range run.Results {
+		for _, problem := range result.Problems {
+			broken[result.Version] = true
+			add(FsckError, result.Version, storageObject(byVersion[result.Version]), problem,
+				"Restore the object from 'dgit backup' or a remote with 'dgit pull'")
+		}
+	}
+
+	// Every delta chain must reach a full snapshot through intact bases
+	for _, commit := range history {
+		if broken[commit.Version] {
+			continue
+		}
+		if at, problem := deltaChainBreak(commit, byVersion, broken); problem != "" {
+			broken[commit.Version] = true
+			add(FsckError, commit.Version, storageObject(commit), problem,
+				fmt.Sprintf("v%d cannot be rebuilt until v%d is repaired", commit.Version, at))
+		}
+	}
+
+	if deep {
+		scratchDir, err := os.MkdirTemp("", "dgit-fsck-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+		}
+		defer os.RemoveAll(scratchDir)
+		for _, commit := range history {
+			if broken[commit.Version] {
+				continue
+			}
+			for _, problem := range vm.DeepVerify(commit, scratchDir).Problems {
+				add(FsckError, commit.Version, storageObject(commit), problem,
+					"Restore the object from 'dgit backup' or a remote with 'dgit pull'")
+			}
+		}
+	}
+
+	vm.findUnreferenced(history, byVersion, add)
+	return report, nil
+}
+
+// deltaChainBreak follows a version's delta bases down to a full snapshot
+// It returns the version where the chain breaks and why, or "" when the chain is intact
+func deltaChainBreak(commit *log.Commit, byVersion map[int]*log.Commit, broken map[int]bool) (int, string) {
+	seen := map[int]bool{commit.Version: true}
+	current := commit
+	for current.CompressionInfo != nil && current.CompressionInfo.BaseVersion > 0 {
+		base := current.CompressionInfo.BaseVersion
+		if seen[base] {
+			return base, fmt.Sprintf("delta chain loops back to v%d", base)
+		}
+		seen[base] = true
+		next := byVersion[base]
+		if next == nil {
+			return base, fmt.Sprintf("delta chain is broken: base v%d does not exist", base)
+		}
+		if broken[base] {
+			return base, fmt.Sprintf("delta chain is broken: base v%d is damaged", base)
+		}
+		current = next
+	}
+	return 0, ""
+}
+
+// findUnreferenced reports storage files no commit uses and temporary files left by interrupted operations
+func (vm *VerifyManager) findUnreferenced(history []*log.Commit, byVersion map[int]*log.Commit, add func(string, int, string, string, string)) {
+	for _, dir := range []string{
+		vm.DgitDir,
+		vm.ObjectsDir,
+		vm.DeltaDir,
+		filepath.Join(vm.CacheDir, "hot"),
+		filepath.Join(vm.CacheDir, "warm"),
+		filepath.Join(vm.CacheDir, "cold"),
+	} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			rel, _ := filepath.Rel(vm.DgitDir, filepath.Join(dir, entry.Name()))
+			rel = filepath.ToSlash(rel)
+			if fsckTempName.MatchString(entry.Name()) {
+				add(FsckWarning, 0, rel, "leftover of an interrupted operation", "Safe to delete")
+				continue
+			}
+			if dir == vm.DgitDir || strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			m := fsckVersionName.FindStringSubmatch(entry.Name())
+			if m == nil {
+				continue
+			}
+			if version, _ := strconv.Atoi(m[1]); byVersion[version] == nil {
+				add(FsckWarning, 0, rel, fmt.Sprintf("belongs to v%d, which has no commit", version),
+					"Not referenced by any commit; safe to delete")
+			}
+		}
+	}
+
+	// Content store blobs shared between versions
+	referenced := make(map[string]bool)
+	for _, commit := range history {
+		for _, ref := range commit.Blobs {
+			if ref != nil {
+				referenced[ref.Hash] = true
+			}
+		}
+	}
+	blobsDir := filepath.Join(vm.DgitDir, objstore.BlobDir)
+	filepath.WalkDir(blobsDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(vm.DgitDir, path)
+		rel = filepath.ToSlash(rel)
+		if fsckTempName.MatchString(entry.Name()) {
+			add(FsckWarning, 0, rel, "leftover of an interrupted operation", "Safe to delete")
+			return nil
+		}
+		hash := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if !referenced[hash] {
+			add(FsckWarning, 0, rel, "blob is not referenced by any commit", "Safe to delete")
+		}
+		return nil
+	})
+}
+
+// storageObject names the object a version's data lives in, for the report
+func storageObject(commit *log.Commit) string {
+	if commit == nil {
+		return ""
+	}
+	if commit.CompressionInfo != nil {
+		if commit.CompressionInfo.Strategy == objstore.Strategy {
+			return objstore.BlobDir
+		}
+		return commit.CompressionInfo.OutputFile
+	}
+	return commit.SnapshotZip
+}
@@ -0,0 +1,568 @@
+package verify
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/archive"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/status"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// VersionResult reports the verification of one version
+type VersionResult struct {
+	Version  int
+	Strategy string
+	Files    int      // Files checked
+	Hashed   int      // Files compared byte-for-byte against their committed SHA256
+	Problems []string // Empty when the version verified cleanly
+	Duration time.Duration
+	Bytes    int64 // Storage object bytes read
+	Skipped  bool  // Unchanged since it last verified cleanly (incremental mode)
+	Offline  bool  // Archived to external storage; its blob is not on this machine
+}
+
+// StorageRun is the outcome of a parallel storage check
+type StorageRun struct {
+	Results []*VersionResult // Sorted by version
+	Workers int
+	Checked int
+	Skipped int
+	Bytes   int64
+	Elapsed time.Duration
+}
+
+// Throughput returns the bytes verified per second
+func (sr *StorageRun) Throughput() float64 {
+	if sr.Elapsed <= 0 {
+		return 0
+	}
+	return float64(sr.Bytes) / sr.Elapsed.Seconds()
+}
+
+// objectStamp identifies the object file that last verified cleanly
+type objectStamp struct {
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mod_time"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// OK reports whether the version verified without problems
+func (vr *VersionResult) OK() bool {
+	return len(vr.Problems) == 0
+}
+
+// RunRecord summarizes the most recent verify run, for the status health line
+type RunRecord struct {
+	Time     time.Time `json:"time"`
+	Deep     bool      `json:"deep"`
+	Versions int       `json:"versions"`
+	Failed   int       `json:"failed"`
+}
+
+// VerifyManager checks that committed versions can still be read back
+// The storage check decodes every object; the deep check reconstructs versions through the real restore paths
+type VerifyManager struct {
+	DgitDir    string
+	ObjectsDir string
+	DeltaDir   string
+	CacheDir   string
+	RunFile    string
+	StateFile  string // Per-object stamps for incremental runs
+}
+
+// NewVerifyManager creates a new verify manager for the given .dgit directory
+func NewVerifyManager(dgitDir string) *VerifyManager {
+	objectsDir := filepath.Join(dgitDir, "objects")
+	return &VerifyManager{
+		DgitDir:    dgitDir,
+		ObjectsDir: objectsDir,
+		DeltaDir:   filepath.Join(objectsDir, "deltas"),
+		CacheDir:   filepath.Join(dgitDir, "cache"),
+		RunFile:    filepath.Join(dgitDir, "metrics", "last-verify.json"),
+		StateFile:  filepath.Join(dgitDir, "metrics", "verify-state.json"),
+	}
+}
+
+// Sample picks up to n versions at random (all of them when n <= 0 or n >= len(commits))
+// The result is sorted by version so delta chains are verified bottom-up
+func Sample(commits []*log.Commit, n int, seed int64) []*log.Commit {
+	picked := append([]*log.Commit(nil), commits...)
+	if n > 0 && n < len(picked) {
+		r := rand.New(rand.NewSource(seed))
+		r.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+		picked = picked[:n]
+	}
+	sort.Slice(picked, func(i, j int) bool { return picked[i].Version < picked[j].Version })
+	return picked
+}
+
+// CheckStorage verifies a version's storage object exists and decodes without checksum errors
+// ZIP entries are checked against their CRC32 and LZ4/Zstd frames against their frame checksums
+func (vm *VerifyManager) CheckStorage(commit *log.Commit) *VersionResult {
+	result, _, _ := vm.checkStorage(commit, nil, nil)
+	return result
+}
+
+// CheckStorageParallel checks many versions with a bounded pool of workers, one per core (up to performance.max_workers) by default
+// In incremental mode objects whose size and modification time match their last clean check are skipped
+func (vm *VerifyManager) CheckStorageParallel(commits []*log.Commit, workers int, incremental bool) (*StorageRun, error) {
+	if workers <= 0 {
+		workers = perf.Load(vm.DgitDir).Workers(len(commits))
+	}
+	if workers > len(commits) {
+		workers = max(len(commits), 1)
+	}
+	stamps, err := vm.loadStamps()
+	if err != nil {
+		return nil, err
+	}
+	skipUnchanged := stamps
+	if !incremental {
+		skipUnchanged = nil
+	}
+	known := make(map[int]bool)
+	if history, err := log.NewLogManager(vm.DgitDir).GetCommitHistory(); err == nil {
+		for _, c := range history {
+			known[c.Version] = true
+		}
+	}
+
+	started := time.Now()
+	run := &StorageRun{Workers: workers, Results: make([]*VersionResult, len(commits))}
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, key, stamp := vm.checkStorage(commits[i], known, skipUnchanged)
+				mu.Lock()
+				run.Results[i] = result
+				if key != "" && stamp != nil {
+					stamps[key] = stamp
+				} else if key != "" && !result.OK() {
+					delete(stamps, key)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range commits {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	run.Elapsed = time.Since(started)
+
+	for _, result := range run.Results {
+		if result.Skipped {
+			run.Skipped++
+			continue
+		}
+		run.Checked++
+		run.Bytes += result.Bytes
+	}
+	sort.Slice(run.Results, func(i, j int) bool { return run.Results[i].Version < run.Results[j].Version })
+	return run, vm.saveStamps(stamps)
+}
+
+// checkStorage verifies one version's object, returning its stamp key and, when it verified cleanly, a new stamp
+// known lists existing versions for the delta base check (nil looks them up); skip holds stamps to skip unchanged objects
+func (vm *VerifyManager) checkStorage(commit *log.Commit, known map[int]bool, skip map[string]*objectStamp) (*VersionResult, string, *objectStamp) {
+	started := time.Now()
+	result := &VersionResult{Version: commit.Version, Files: len(commit.Metadata)}
+	defer func() { result.Duration = time.Since(started) }()
+
+	info := commit.CompressionInfo
+	if info == nil {
+		if commit.SnapshotZip == "" {
+			result.Problems = append(result.Problems, "no storage information recorded")
+			return result, "", nil
+		}
+		info = &log.CompressionResult{Strategy: "zip", OutputFile: commit.SnapshotZip}
+	}
+	result.Strategy = info.Strategy
+	if info.Strategy == objstore.Strategy {
+		vm.checkBlobs(commit, result)
+		return result, "", nil
+	}
+
+	objectPath := vm.findObject(info.OutputFile)
+	if objectPath == "" && (info.Strategy == codec.LZ4 || info.Strategy == codec.Store) {
+		// Snapshots evicted from the hot cache live on as the warm Zstd copy
+		objectPath = vm.findObject(fmt.Sprintf("v%d.zstd", commit.Version))
+	}
+	if objectPath == "" {
+		if record, ok := archive.NewArchiveManager(vm.DgitDir).Get(commit.Version); ok {
+			if record.Offline {
+				result.Offline = true
+				return result, "", nil
+			}
+			objectPath = filepath.Join(vm.DgitDir, filepath.FromSlash(record.Blob))
+		}
+	}
+	if objectPath == "" {
+		result.Problems = append(result.Problems, fmt.Sprintf("storage object %s is missing", info.OutputFile))
+		return result, "", nil
+	}
+	key, _ := filepath.Rel(vm.DgitDir, objectPath)
+	key = filepath.ToSlash(key)
+	stat, err := os.Stat(objectPath)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", info.OutputFile, err))
+		return result, key, nil
+	}
+	if last, ok := skip[key]; ok && last.Size == stat.Size() && last.ModTime.Equal(stat.ModTime()) {
+		result.Skipped = true
+		return result, key, last
+	}
+
+	if info.BaseVersion > 0 {
+		exists := known[info.BaseVersion]
+		if known == nil {
+			_, err := log.NewLogManager(vm.DgitDir).GetCommit(info.BaseVersion)
+			exists = err == nil
+		}
+		if !exists {
+			result.Problems = append(result.Problems, fmt.Sprintf("delta base v%d is missing", info.BaseVersion))
+		}
+	}
+	result.Bytes = stat.Size()
+	if err := decodeObject(objectPath); err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", info.OutputFile, err))
+	}
+	if !result.OK() {
+		return result, key, nil
+	}
+	return result, key, &objectStamp{Size: stat.Size(), ModTime: stat.ModTime(), VerifiedAt: time.Now()}
+}
+
+// checkBlobs decompresses and hashes every object store blob a version references
+// Blobs shared with other versions are checked once per version; they are not stamped
+// Blobs that left the store when the version was archived are checked in its cold blob
+func (vm *VerifyManager) checkBlobs(commit *log.Commit, result *VersionResult) {
+	store := objstore.NewObjectStore(vm.DgitDir)
+	record, archived := archive.NewArchiveManager(vm.DgitDir).Get(commit.Version)
+	var packed []string
+	checked := make(map[string]bool)
+	paths := make([]string, 0, len(commit.Blobs))
+	for path := range commit.Blobs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		ref := commit.Blobs[path]
+		if checked[ref.Hash] {
+			continue
+		}
+		checked[ref.Hash] = true
+		blob := store.Find(ref.Hash)
+		if blob == "" && archived {
+			if i := sort.SearchStrings(record.Files, path); i < len(record.Files) && record.Files[i] == path {
+				packed = append(packed, path)
+				continue
+			}
+		}
+		if blob != "" {
+			if stat, err := os.Stat(blob); err == nil {
+				result.Bytes += stat.Size()
+			}
+		}
+		if err := store.Verify(ref.Hash); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	if len(packed) > 0 {
+		vm.checkColdBlobs(commit, record, packed, result)
+	}
+}
+
+// checkColdBlobs hashes the files an archived object store version packed into its cold blob
+func (vm *VerifyManager) checkColdBlobs(commit *log.Commit, record *archive.Record, paths []string, result *VersionResult) {
+	if record.Offline {
+		result.Offline = true
+		return
+	}
+	blobPath := filepath.Join(vm.DgitDir, filepath.FromSlash(record.Blob))
+	file, err := os.Open(blobPath)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("cold blob %s: %v", record.Blob, err))
+		return
+	}
+	defer file.Close()
+	if stat, err := file.Stat(); err == nil {
+		result.Bytes += stat.Size()
+	}
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("cold blob %s: %v", record.Blob, err))
+		return
+	}
+	defer decoder.Close()
+
+	found := make(map[string]bool)
+	err = codec.EachFrame(decoder, func(header *codec.FrameHeader, data io.Reader) error {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, data); err != nil {
+			return err
+		}
+		found[header.Path] = true
+		if ref := commit.Blobs[header.Path]; ref == nil || hex.EncodeToString(hash.Sum(nil)) != ref.Hash {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: archived content does not match its committed hash", header.Path))
+		}
+		return nil
+	})
+	if err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("cold blob %s: %v", record.Blob, err))
+		return
+	}
+	for _, path := range paths {
+		if !found[path] {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: missing from cold blob %s", path, record.Blob))
+		}
+	}
+}
+
+// loadStamps reads the per-object stamps of earlier clean checks
+func (vm *VerifyManager) loadStamps() (map[string]*objectStamp, error) {
+	stamps := make(map[string]*objectStamp)
+	data, err := os.ReadFile(vm.StateFile)
+	if os.IsNotExist(err) {
+		return stamps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verify state: %w", err)
+	}
+	if err := json.Unmarshal(data, &stamps); err != nil {
+		return nil, fmt.Errorf("failed to parse verify state: %w", err)
+	}
+	return stamps, nil
+}
+
+// saveStamps writes the per-object stamps
+func (vm *VerifyManager) saveStamps(stamps map[string]*objectStamp) error {
+	data, err := json.MarshalIndent(stamps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verify state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(vm.StateFile), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	if err := os.WriteFile(vm.StateFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write verify state: %w", err)
+	}
+	return nil
+}
+
+// DeepVerify restores a version into a scratch directory through its normal restoration path
+// and compares every file with the size and SHA256 recorded at commit time
+func (vm *VerifyManager) DeepVerify(commit *log.Commit, scratchDir string) *VersionResult {
+	started := time.Now()
+	result := &VersionResult{Version: commit.Version, Files: len(commit.Metadata)}
+	defer func() { result.Duration = time.Since(started) }()
+	if commit.CompressionInfo != nil {
+		result.Strategy = commit.CompressionInfo.Strategy
+	}
+
+	workDir := filepath.Join(scratchDir, fmt.Sprintf("v%d", commit.Version))
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("failed to create scratch directory: %v", err))
+		return result
+	}
+	defer os.RemoveAll(workDir)
+
+	restoreManager := restore.NewRestoreManager(vm.DgitDir)
+	restoreManager.WorkDir = workDir
+	if err := restoreManager.RestoreFilesFromCommit(fmt.Sprintf("v%d", commit.Version), nil, commit); err != nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("restore failed: %v", err))
+		return result
+	}
+
+	paths := make([]string, 0, len(commit.Metadata))
+	for path := range commit.Metadata {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		restored := filepath.Join(workDir, filepath.FromSlash(path))
+		stat, err := os.Stat(restored)
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s was not restored", path))
+			continue
+		}
+		meta, _ := commit.Metadata[path].(map[string]interface{})
+		if size, ok := meta["size"].(float64); ok && int64(size) != stat.Size() {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: size %d, committed %d", path, stat.Size(), int64(size)))
+			continue
+		}
+		expected, _ := meta["sha256"].(string)
+		if expected == "" {
+			continue // Committed before content hashes were recorded; size check only
+		}
+		actual, err := status.CalculateFileHash(restored)
+		if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if actual != expected {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: content differs from the committed file", path))
+			continue
+		}
+		result.Hashed++
+	}
+	return result
+}
+
+// RecordRun stores the outcome of a verify run
+func (vm *VerifyManager) RecordRun(results []*VersionResult, deep bool) error {
+	record := RunRecord{Time: time.Now(), Deep: deep, Versions: len(results)}
+	for _, result := range results {
+		if !result.OK() {
+			record.Failed++
+		}
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verify record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(vm.RunFile), 0755); err != nil {
+		return fmt.Errorf("failed to create metrics directory: %w", err)
+	}
+	if err := os.WriteFile(vm.RunFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write verify record: %w", err)
+	}
+	return nil
+}
+
+// LastRun returns the most recent verify run, or nil if verify has never run
+func (vm *VerifyManager) LastRun() (*RunRecord, error) {
+	data, err := os.ReadFile(vm.RunFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verify record: %w", err)
+	}
+	var record RunRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse verify record: %w", err)
+	}
+	return &record, nil
+}
+
+// findObject looks for a storage object in every tier it may live in
+func (vm *VerifyManager) findObject(name string) string {
+	if name == "" {
+		return ""
+	}
+	for _, dir := range []string{
+		filepath.Join(vm.CacheDir, "hot"),
+		filepath.Join(vm.CacheDir, "warm"),
+		filepath.Join(vm.CacheDir, "cold"),
+		vm.ObjectsDir,
+		vm.DeltaDir,
+	} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// decodeObject reads a storage object to the end so its format's checksums are validated
+func decodeObject(path string) error {
+	switch filepath.Ext(path) {
+	case ".zip":
+		reader, err := zip.OpenReader(path)
+		if err != nil {
+			return fmt.Errorf("unreadable archive: %w", err)
+		}
+		defer reader.Close()
+		for _, entry := range reader.File {
+			rc, err := entry.Open()
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.Name, err)
+			}
+			_, err = io.Copy(io.Discard, rc)
+			rc.Close()
+			if err != nil {
+				return fmt.Errorf("%s: %w", entry.Name, err)
+			}
+		}
+		return nil
+	case ".lz4":
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := drain(codec.NewLZ4Reader(file)); err != nil {
+			return fmt.Errorf("corrupt LZ4 stream: %w", err)
+		}
+		return nil
+	case ".zstd":
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer decoder.Close()
+		if err := drain(decoder); err != nil {
+			return fmt.Errorf("corrupt Zstd stream: %w", err)
+		}
+		return nil
+	case ".store":
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		store, _ := codec.Get(codec.Store)
+		reader, err := store.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("corrupt store object: %w", err)
+		}
+		if err := drain(reader); err != nil {
+			return fmt.Errorf("corrupt store object: %w", err)
+		}
+		return nil
+	}
+	// Delta formats carry their own headers; they are exercised by the deep check
+	return nil
+}
+
+// drain reads a decoded stream to the end; framed containers also have each file's checksum checked
+func drain(r io.Reader) error {
+	buffered := bufio.NewReader(r)
+	if head, _ := buffered.Peek(len(codec.FramedMagic)); codec.IsFramed(head) {
+		return codec.EachFrame(buffered, func(*codec.FrameHeader, io.Reader) error { return nil })
+	}
+	_, err := io.Copy(io.Discard, buffered)
+	return err
+}
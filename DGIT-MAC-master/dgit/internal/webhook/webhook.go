@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+)
+
+// Actions an external system may trigger
+const (
+	ActionIngest = "ingest" // Process the watch-folder ingest rules and commit what settled
+	ActionVerify = "verify" // Check the storage of every version
+	ActionSync   = "sync"   // Fetch linked assets and update submodules
+	ActionFigma  = "figma"  // Commit new named versions of the synced Figma files
+)
+
+// Actions lists every action the endpoint knows
+var Actions = []string{ActionIngest, ActionVerify, ActionSync, ActionFigma}
+
+// WebhookManager guards the inbound webhook endpoint with a bearer token and an action allowlist
+type WebhookManager struct {
+	DgitDir string
+	KeyFile string
+}
+
+// NewWebhookManager creates a new webhook manager for the given .dgit directory
+func NewWebhookManager(dgitDir string) *WebhookManager {
+	return &WebhookManager{
+		DgitDir: dgitDir,
+		KeyFile: filepath.Join(dgitDir, "keys", "webhook.key"),
+	}
+}
+
+// IsAction reports whether an action name is known
+func IsAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Token returns the webhook token, generating one on first use
+func (wm *WebhookManager) Token() (string, error) {
+	if data, err := os.ReadFile(wm.KeyFile); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+	return wm.RotateToken()
+}
+
+// RotateToken replaces the webhook token; callers holding the old one are rejected from then on
+func (wm *WebhookManager) RotateToken() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(wm.KeyFile), 0700); err != nil {
+		return "", fmt.Errorf("failed to create keys directory: %w", err)
+	}
+	token := hex.EncodeToString(key)
+	if err := os.WriteFile(wm.KeyFile, []byte(token), 0600); err != nil {
+		return "", fmt.Errorf("failed to write webhook token: %w", err)
+	}
+	return token, nil
+}
+
+// CheckToken compares a presented token with the repository's in constant time
+// There is no token until one is generated, so an unconfigured repository rejects every call
+func (wm *WebhookManager) CheckToken(presented string) error {
+	data, err := os.ReadFile(wm.KeyFile)
+	if err != nil {
+		return fmt.Errorf("webhooks are not configured")
+	}
+	token := strings.TrimSpace(string(data))
+	if presented == "" || token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+		return fmt.Errorf("invalid token")
+	}
+	return nil
+}
+
+// GetAllowed returns the allowlisted actions
+func (wm *WebhookManager) GetAllowed() ([]string, error) {
+	config, err := initializer.GetRepositoryConfig(wm.DgitDir)
+	if err != nil {
+		return nil, err
+	}
+	return config.Webhooks.AllowedActions, nil
+}
+
+// IsAllowed reports whether an action is on the allowlist
+func (wm *WebhookManager) IsAllowed(action string) (bool, error) {
+	allowed, err := wm.GetAllowed()
+	if err != nil {
+		return false, err
+	}
+	for _, a := range allowed {
+		if a == action {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetAllowed adds actions to or removes them from the allowlist
+func (wm *WebhookManager) SetAllowed(actions []string, allow bool) error {
+	for _, action := range actions {
+		if !IsAction(action) {
+			return fmt.Errorf("unknown webhook action %q (valid: %s)", action, strings.Join(Actions, ", "))
+		}
+	}
+	config, err := initializer.GetRepositoryConfig(wm.DgitDir)
+	if err != nil {
+		return err
+	}
+	set := make(map[string]bool)
+	for _, a := range config.Webhooks.AllowedActions {
+		set[a] = true
+	}
+	for _, action := range actions {
+		set[action] = allow
+	}
+	var allowed []string
+	for action, ok := range set {
+		if ok {
+			allowed = append(allowed, action)
+		}
+	}
+	sort.Strings(allowed)
+	config.Webhooks.AllowedActions = allowed
+	return initializer.UpdateRepositoryConfig(wm.DgitDir, config)
+}
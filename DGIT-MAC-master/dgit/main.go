@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 
-	"dgit/cmd"
+	"github.com/3pxTeam/DGIT-MAC/dgit/cmd"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/perf"
+	"github.com/3pxTeam/DGIT-MAC/dgit/pkg/repo"
 	
 	"github.com/spf13/cobra"
 )
@@ -22,9 +24,24 @@ Figma, and other design file formats with metadata-aware versioning.
 - Visual diff for design changes with layer/artboard tracking
 - Team collaboration optimized for creative workflows
 - Git-like interface with design-specific enhancements`,
+	Version:          repo.Version,
+	PersistentPreRun: applyPerformanceFlags,
+}
+
+// applyPerformanceFlags lets --max-workers, --max-memory and --io-throttle override
+// the repository's performance settings for this one command (-1 lifts a limit)
+func applyPerformanceFlags(cmd *cobra.Command, args []string) {
+	workers, _ := cmd.Flags().GetInt("max-workers")
+	memory, _ := cmd.Flags().GetInt("max-memory")
+	throttle, _ := cmd.Flags().GetInt("io-throttle")
+	perf.SetOverrides(perf.Limits{MaxWorkers: workers, MaxMemoryMB: memory, IOThrottleMBps: throttle})
 }
 
 func init() {
+	rootCmd.PersistentFlags().Int("max-workers", 0, "Most worker goroutines a command may use (default: performance.max_workers, else one per CPU core)")
+	rootCmd.PersistentFlags().Int("max-memory", 0, "Decompression memory budget in MB (default: performance.max_memory_mb, else unlimited)")
+	rootCmd.PersistentFlags().Int("io-throttle", 0, "Foreground disk bandwidth cap in MB/s (default: performance.io_throttle_mbps, else unlimited)")
+
 	// Add all commands from cmd package
 	rootCmd.AddCommand(cmd.InitCmd)
 	rootCmd.AddCommand(cmd.ScanCmd)
@@ -33,6 +50,57 @@ func init() {
 	rootCmd.AddCommand(cmd.StatusCmd)
 	rootCmd.AddCommand(cmd.LogCmd)
 	rootCmd.AddCommand(cmd.RestoreCmd)
+	rootCmd.AddCommand(cmd.SubmoduleCmd)
+	rootCmd.AddCommand(cmd.LinkCmd)
+	rootCmd.AddCommand(cmd.ApproveCmd)
+	rootCmd.AddCommand(cmd.ReviewCmd)
+	rootCmd.AddCommand(cmd.ShareCmd)
+	rootCmd.AddCommand(cmd.ServeCmd)
+	rootCmd.AddCommand(cmd.DeliverCmd)
+	rootCmd.AddCommand(cmd.MergeRepoCmd)
+	rootCmd.AddCommand(cmd.BundleCmd)
+	rootCmd.AddCommand(cmd.UnbundleCmd)
+	rootCmd.AddCommand(cmd.SearchCmd)
+	rootCmd.AddCommand(cmd.MetadataCmd)
+	rootCmd.AddCommand(cmd.StatsCmd)
+	rootCmd.AddCommand(cmd.AccountingCmd)
+	rootCmd.AddCommand(cmd.GroupCmd)
+	rootCmd.AddCommand(cmd.CleanCmd)
+	rootCmd.AddCommand(cmd.TrashCmd)
+	rootCmd.AddCommand(cmd.PinCmd)
+	rootCmd.AddCommand(cmd.UnpinCmd)
+	rootCmd.AddCommand(cmd.IngestCmd)
+	rootCmd.AddCommand(cmd.DaemonCmd)
+	rootCmd.AddCommand(cmd.PreviewCmd)
+	rootCmd.AddCommand(cmd.VerifyCmd)
+	rootCmd.AddCommand(cmd.MvCmd)
+	rootCmd.AddCommand(cmd.HooksCmd)
+	rootCmd.AddCommand(cmd.RecompressCmd)
+	rootCmd.AddCommand(cmd.GraphCmd)
+	rootCmd.AddCommand(cmd.FigmaCmd)
+	rootCmd.AddCommand(cmd.CCLibCmd)
+	rootCmd.AddCommand(cmd.APICmd)
+	rootCmd.AddCommand(cmd.AuditCmd)
+	rootCmd.AddCommand(cmd.BranchCmd)
+	rootCmd.AddCommand(cmd.CheckoutCmd)
+	rootCmd.AddCommand(cmd.BackupCmd)
+	rootCmd.AddCommand(cmd.ArchiveCmd)
+	rootCmd.AddCommand(cmd.DiffCmd)
+	rootCmd.AddCommand(cmd.RemoteCmd)
+	rootCmd.AddCommand(cmd.PushCmd)
+	rootCmd.AddCommand(cmd.PullCmd)
+	rootCmd.AddCommand(cmd.CloneCmd)
+	rootCmd.AddCommand(cmd.WhoamiCmd)
+	rootCmd.AddCommand(cmd.FsckCmd)
+	rootCmd.AddCommand(cmd.UnstageCmd)
+	rootCmd.AddCommand(cmd.RmCmd)
+	rootCmd.AddCommand(cmd.LockCmd)
+	rootCmd.AddCommand(cmd.UnlockCmd)
+	rootCmd.AddCommand(cmd.StorageCmd)
+	rootCmd.AddCommand(cmd.ExportCmd)
+	rootCmd.AddCommand(cmd.ShowCmd)
+	rootCmd.AddCommand(cmd.CacheCmd)
+	rootCmd.AddCommand(cmd.OptimizeCmd)
 }
 
 func main() {
@@ -0,0 +1,60 @@
+// Package objects reads and writes a repository's content-addressed blobs
+// Blobs are keyed by the SHA-256 of the file content. Put writes them LZ4-compressed or stored as-is,
+// and 'dgit optimize' recompresses idle ones to Zstd; Read decodes all three, so callers always get the content
+package objects
+
+import (
+	"io"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/codec"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/objstore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/pkg/repo"
+)
+
+// Store is the object store of one repository
+type Store struct {
+	store *objstore.ObjectStore
+}
+
+// Open returns the object store of a repository
+func Open(r *repo.Repository) *Store {
+	return &Store{store: objstore.NewObjectStore(r.DgitDir)}
+}
+
+// Has reports whether a blob is stored locally
+func (s *Store) Has(hash string) bool {
+	return s.store.Has(hash)
+}
+
+// Read returns a blob's content; reading to the end verifies it against its hash
+// Blobs evicted to the configured storage backend are fetched back first
+func (s *Store) Read(hash string) (io.ReadCloser, error) {
+	return s.store.Open(hash)
+}
+
+// Put stores a file's content, compressed unless compress is false, and returns its reference
+// Content that is already stored is referenced without being written again
+func (s *Store) Put(path string, compress bool) (*repo.BlobRef, error) {
+	codecName := codec.LZ4
+	if !compress {
+		codecName = codec.Store
+	}
+	result, err := s.store.Put(path, codecName)
+	if err != nil {
+		return nil, err
+	}
+	return result.Ref, nil
+}
+
+// Verify reads a blob to the end and checks it against its hash
+func (s *Store) Verify(hash string) error {
+	return s.store.Verify(hash)
+}
+
+// Blobs returns the path → blob map of a version, nil when the version was not committed to the object store
+func Blobs(c *repo.Commit) map[string]*repo.BlobRef {
+	if c.CompressionInfo == nil || c.CompressionInfo.Strategy != objstore.Strategy {
+		return nil
+	}
+	return c.Blobs
+}
@@ -0,0 +1,151 @@
+// Package repo opens DGit repositories from other Go programs
+//
+// The pkg/ packages are the module's public API and follow semantic versioning
+// (module tags dgit/vX.Y.Z); everything under internal/ may change between releases
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	initializer "github.com/3pxTeam/DGIT-MAC/dgit/internal/init"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/commit"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/log"
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/staging"
+)
+
+// Version is the semantic version of the public API
+const Version = "1.0.0"
+
+// ErrNotRepository is returned when no .dgit directory is found
+var ErrNotRepository = errors.New("not a dgit repository (or any of the parent directories)")
+
+// Commit is one committed version with its per-file design metadata
+type Commit = log.Commit
+
+// BlobRef points a committed file at its content in the object store
+type BlobRef = log.BlobRef
+
+// StagedFile is a file waiting in the staging area
+type StagedFile = staging.StagedFile
+
+// Config is the repository configuration stored in .dgit/config
+type Config = initializer.RepositoryConfig
+
+// Repository is an opened DGit repository
+type Repository struct {
+	Root    string // Work tree holding the design files
+	DgitDir string // Root/.dgit
+}
+
+// Init creates a repository in dir and opens it
+func Init(dir string) (*Repository, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := initializer.InitRepository(root); err != nil {
+		return nil, err
+	}
+	return &Repository{Root: root, DgitDir: filepath.Join(root, initializer.DGitDir)}, nil
+}
+
+// Open finds the repository containing path, searching parent directories like the CLI does
+func Open(path string) (*Repository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		dgitDir := filepath.Join(dir, initializer.DGitDir)
+		if info, err := os.Stat(dgitDir); err == nil && info.IsDir() {
+			return &Repository{Root: dir, DgitDir: dgitDir}, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+// Config returns the repository configuration
+func (r *Repository) Config() (*Config, error) {
+	return initializer.GetRepositoryConfig(r.DgitDir)
+}
+
+// Head returns the version HEAD points at, 0 before the first commit
+func (r *Repository) Head() int {
+	return log.NewLogManager(r.DgitDir).GetHeadVersion()
+}
+
+// Version loads one committed version
+func (r *Repository) Version(version int) (*Commit, error) {
+	return log.NewLogManager(r.DgitDir).GetCommit(version)
+}
+
+// Log returns every commit, oldest first
+func (r *Repository) Log() ([]*Commit, error) {
+	return log.NewLogManager(r.DgitDir).GetCommitHistory()
+}
+
+// Add stages files, folders or glob patterns the way 'dgit add' does; relative patterns start at Root
+// Returns the number of files staged
+func (r *Repository) Add(patterns ...string) (int, error) {
+	area := staging.NewStagingArea(r.DgitDir)
+	if err := area.LoadStaging(); err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(r.Root, pattern)
+		}
+		result, err := area.AddPattern(pattern)
+		if err != nil {
+			return added, fmt.Errorf("failed to stage %s: %w", pattern, err)
+		}
+		for path, err := range result.FailedFiles {
+			return added, fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+		added += len(result.AddedFiles)
+	}
+	if err := area.SaveStaging(); err != nil {
+		return added, err
+	}
+	return added, nil
+}
+
+// Staged returns the files in the staging area
+func (r *Repository) Staged() ([]*StagedFile, error) {
+	area := staging.NewStagingArea(r.DgitDir)
+	if err := area.LoadStaging(); err != nil {
+		return nil, err
+	}
+	return area.GetStagedFiles(), nil
+}
+
+// CommitStaged commits everything in the staging area and clears it
+// author and email may be empty to use the configured identity
+func (r *Repository) CommitStaged(message, author, email string) (*Commit, error) {
+	area := staging.NewStagingArea(r.DgitDir)
+	if err := area.LoadStaging(); err != nil {
+		return nil, err
+	}
+	if area.IsEmpty() {
+		return nil, fmt.Errorf("no files staged for commit")
+	}
+	manager := commit.NewCommitManager(r.DgitDir)
+	manager.Author = author
+	manager.Email = email
+	created, err := manager.CreateCommit(message, area.GetStagedFiles())
+	if err != nil {
+		return nil, err
+	}
+	if err := area.ClearStaging(); err != nil {
+		return nil, err
+	}
+	return r.Version(created.Version)
+}
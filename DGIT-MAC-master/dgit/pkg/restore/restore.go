@@ -0,0 +1,34 @@
+// Package restore reads committed versions back out of a repository
+// Open streams a single file without touching the work tree; Restore writes files like 'dgit restore'
+package restore
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/restore"
+	"github.com/3pxTeam/DGIT-MAC/dgit/pkg/repo"
+)
+
+// Options control Restore
+type Options struct {
+	Files []string // Repo-relative paths to restore; empty restores the whole version
+	Dir   string   // Directory to write into; empty writes into the repository's work tree
+}
+
+// Open returns one file of a committed version as a stream
+// Reading to the end verifies the file's checksum where the storage format records one
+func Open(r *repo.Repository, version int, path string) (io.ReadCloser, error) {
+	return restore.NewRestoreManager(r.DgitDir).OpenFile(version, path)
+}
+
+// Restore writes the files of a committed version to disk
+// Existing work tree files it replaces are kept in the repository trash
+func Restore(r *repo.Repository, version int, opts Options) error {
+	manager := restore.NewRestoreManager(r.DgitDir)
+	manager.WorkDir = opts.Dir
+	if manager.WorkDir == "" {
+		manager.WorkDir = r.Root
+	}
+	return manager.RestoreFilesFromCommit(fmt.Sprintf("v%d", version), opts.Files, nil)
+}
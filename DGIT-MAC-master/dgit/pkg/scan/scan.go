@@ -0,0 +1,34 @@
+// Package scan reads design metadata (dimensions, color mode, layers, artboards) from design files
+package scan
+
+import (
+	"github.com/3pxTeam/DGIT-MAC/dgit/internal/scanner"
+	"github.com/3pxTeam/DGIT-MAC/dgit/pkg/repo"
+)
+
+// DesignFile is the metadata of one design file
+type DesignFile = scanner.DesignFile
+
+// Result is the outcome of scanning a directory
+type Result = scanner.ScanResult
+
+// IsDesignFile reports whether a path has a supported design file extension
+func IsDesignFile(path string) bool {
+	return scanner.IsDesignFile(path)
+}
+
+// File reads one design file's metadata
+func File(path string) (*DesignFile, error) {
+	return scanner.NewFileScanner().ScanFile(path)
+}
+
+// Directory scans every design file under dir
+func Directory(dir string) (*Result, error) {
+	return scanner.NewFileScanner().ScanDirectory(dir)
+}
+
+// RepositoryDirectory scans dir with a repository's scan cache and performance limits
+// Files unchanged since the last scan are answered from the cache
+func RepositoryDirectory(r *repo.Repository, dir string) (*Result, error) {
+	return scanner.NewCachedFileScanner(r.DgitDir).ScanDirectory(dir)
+}